@@ -2,30 +2,83 @@ package api
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
+	"mime/multipart"
 	"net/http"
+	"net/url"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/constants"
 	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/data"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/data/db"
 	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/inference"
 )
 
 // APIs api 핸들러
 type APIs struct {
-	I *inference.Inference
+	I inference.Inferencer
 	M *data.Manager
+	// URL 이미지를 가져올 때 사용하며, nil이면 기본값으로 생성
+	F *ImageFetcher
+	// 서버 디렉토리 일괄 추론에서 접근할 수 있는 경로 (빈 값이면 ZIP 파일만 허용)
+	BulkRoot string
+	// multipart로 업로드하는 이미지의 최대 크기 (byte, 0이면 제한 없음)
+	MaxImageSize int64
+
+	// 비동기 추론 작업 (처음 사용할 때 생성)
+	jobsOnce sync.Once
+	jobs     *jobStore
 }
 
+// 사용량 리포트에서 요청자를 구분하는 헤더
+const apiKeyHeader = "X-API-Key"
+
+// 사용량 리포트 형식
+const (
+	reportJSON = "json"
+	reportCSV  = "csv"
+)
+
 // ListModels 추론 모델 목록 반환
+//
+// tags(`,`로 구분), classification, status querystring으로 모델을 선택하고 sort로 정렬.
+// detail querystring이 있으면 page 단위로 모델 정보를 반환
 func (a *APIs) ListModels(c *gin.Context) {
-	models := a.I.GetModels()
+	filter := inference.ModelFilter{
+		Tags:           queryTags(c),
+		Classification: c.Query("classification"),
+		Status:         c.Query("status"),
+		Sort:           c.Query("sort"),
+	}
+	if err := filter.Validate(); err != nil {
+		Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, detail := c.GetQuery("detail"); detail {
+		page, size, err := parsePage(c)
+		if err != nil {
+			Error(c, http.StatusBadRequest, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, a.I.GetModelInfos(c.Request.Context(), filter, page, size))
+		return
+	}
+
+	models := a.I.GetModels(c.Request.Context(), filter)
 	c.JSON(http.StatusOK, gin.H{
 		"models": models,
 	})
@@ -36,13 +89,49 @@ func (a *APIs) ShowModel(c *gin.Context) {
 	model := c.Param("model")
 	_, verbose := c.GetQuery("verbose")
 
-	if info := a.I.GetModel(model, verbose); info != nil {
+	if info, err := a.I.GetModel(c.Request.Context(), model, verbose); err == nil {
 		c.JSON(http.StatusOK, info)
 	} else {
-		Error(c, http.StatusBadRequest, fmt.Errorf("Cannot find model info: %s", model))
+		Error(c, errorStatus(err, http.StatusInternalServerError), err)
 	}
 }
 
+// ShowCache 추론 결과 캐시 사용 현황 반환
+func (a *APIs) ShowCache(c *gin.Context) {
+	c.JSON(http.StatusOK, a.I.CacheStats())
+}
+
+// ShowModelGraph 로드 된 모델의 graph 요약을 JSON 또는 DOT 형식으로 반환
+func (a *APIs) ShowModelGraph(c *gin.Context) {
+	model := c.Param("model")
+	_, verbose := c.GetQuery("verbose")
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "dot" {
+		Error(c, http.StatusBadRequest, fmt.Errorf("Invalid `format`: %q (json, dot)", format))
+		return
+	}
+
+	// DOT은 operation 연결이 필요하므로 항상 전체 graph를 사용
+	graph, err := a.I.GetModelGraph(c.Request.Context(), model, verbose || format == "dot")
+	if err != nil {
+		Error(c, errorStatus(err, http.StatusInternalServerError), err)
+		return
+	}
+
+	if format == "dot" {
+		var b bytes.Buffer
+		if err := graph.WriteDOT(&b); err != nil {
+			Error(c, http.StatusInternalServerError, err)
+			return
+		}
+		c.Data(http.StatusOK, "text/vnd.graphviz; charset=utf-8", b.Bytes())
+		return
+	}
+
+	c.JSON(http.StatusOK, graph)
+}
+
 // InferDefault 기본 모델을 이용한 추론
 func (a *APIs) InferDefault(c *gin.Context) {
 	a.infer(c, constants.DefaultModelName)
@@ -54,51 +143,705 @@ func (a *APIs) InferWithModel(c *gin.Context) {
 	a.infer(c, model)
 }
 
-func (a *APIs) infer(c *gin.Context, model string) {
-	file, header, err := c.Request.FormFile("image")
+func (a *APIs) infer(c *gin.Context, model string) {
+	if c.ContentType() == gin.MIMEJSON {
+		a.inferJSON(c, model)
+		return
+	}
+
+	image, fileName, format, err := a.inferImage(c)
+	if err != nil {
+		Error(c, errorStatus(err, http.StatusBadRequest), err)
+		return
+	}
+
+	topK, threshold, err := inferParams(c)
+	if err != nil {
+		Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	// 활성화 함수를 적용하기 전의 모델 출력도 반환
+	_, raw := c.GetQuery("raw")
+	// 추론 단계별 소요 시간도 반환
+	_, timing := c.GetQuery("timing")
+
+	a.runInfer(c, model, image, fileName, format, topK, threshold, raw, timing)
+}
+
+// inferParams k, threshold querystring 반환
+func inferParams(c *gin.Context) (int, float32, error) {
+	// 지정하지 않으면 모델 config의 defaultTopK를 사용
+	topK, err := strconv.Atoi(c.Query("k"))
+	if err != nil || topK < 0 {
+		topK = 0
+	}
+
+	// binary 모델의 판단 기준이며, 지정하지 않으면 모델 설정값을 사용
+	var threshold float32
+	if t, ok := c.GetQuery("threshold"); ok {
+		v, err := strconv.ParseFloat(t, 32)
+		if err != nil || v <= 0 || v >= 1 {
+			return 0, 0, fmt.Errorf("Invalid `threshold`: %q (0 < threshold < 1)", t)
+		}
+		threshold = float32(v)
+	}
+
+	return topK, threshold, nil
+}
+
+// json 추론 요청의 최대 크기 (base64로 인코딩 된 이미지 포함)
+const maxInferJSONSize = 12 << 20
+
+// inferRequest json 추론 요청
+//
+// multipart 요청을 만들기 어려운 브라우저나 serverless 클라이언트를 위한 형식
+type inferRequest struct {
+	// 지정하면 URL의 모델 대신 사용
+	Model string `json:"model"`
+	// base64로 인코딩 된 이미지 (data URL 형식 가능)
+	Image string `json:"image"`
+	// image 대신 추론할 이미지의 URL
+	URL    string `json:"url"`
+	Format string `json:"format"`
+	K      int    `json:"k"`
+	// binary 모델의 판단 기준 (0이면 모델 설정값)
+	Threshold float32 `json:"threshold"`
+	// 활성화 함수를 적용하기 전의 모델 출력도 반환
+	Raw bool `json:"raw"`
+	// 추론 단계별 소요 시간도 반환
+	Timing bool `json:"timing"`
+	// 결과에서 제외할 클래스 이름 (querystring의 exclude와 함께 적용)
+	Exclude []string `json:"exclude"`
+}
+
+// inferJSON base64 이미지를 담은 json 요청으로 추론
+func (a *APIs) inferJSON(c *gin.Context, model string) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxInferJSONSize)
+
+	var req inferRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		Error(c, http.StatusBadRequest, fmt.Errorf("Invalid json request: %w", err))
+		return
+	}
+
+	if req.Model != "" {
+		model = req.Model
+	}
+	if req.K < 0 {
+		req.K = 0
+	}
+	if req.Threshold < 0 || req.Threshold >= 1 {
+		Error(c, http.StatusBadRequest, fmt.Errorf("Invalid `threshold`: %v (0 < threshold < 1)", req.Threshold))
+		return
+	}
+
+	var (
+		image  []byte
+		format string
+		err    error
+	)
+	if req.Image == "" && req.URL != "" {
+		image, format, err = a.fetcher().Fetch(c.Request.Context(), req.URL)
+	} else {
+		image, format, err = decodeBase64Image(req.Image)
+	}
+	if err != nil {
+		Error(c, errorStatus(err, http.StatusBadRequest), err)
+		return
+	}
+	if req.Format != "" {
+		format = req.Format
+	}
+	if len(req.Exclude) > 0 {
+		ctx := c.Request.Context()
+		c.Request = c.Request.WithContext(inference.ContextWithExcludedLabels(ctx, append(excludedLabels(c), req.Exclude...)...))
+	}
+
+	a.runInfer(c, model, image, "", format, req.K, req.Threshold, req.Raw, req.Timing)
+}
+
+// inferTensorRequest 전처리 된 입력의 json 추론 요청
+type inferTensorRequest struct {
+	// batch를 제외한 모델 입력 shape (모델의 inputLayout 순서)
+	Shape []int `json:"shape"`
+	// shape 순서로 펼친 입력값
+	Data []float32 `json:"data"`
+	K    int       `json:"k"`
+	// binary 모델의 판단 기준 (0이면 모델 설정값)
+	Threshold float32 `json:"threshold"`
+}
+
+// InferTensor 이미지 대신 클라이언트에서 전처리 한 입력으로 추론
+func (a *APIs) InferTensor(c *gin.Context) {
+	model := c.Param("model")
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxInferJSONSize)
+
+	var req inferTensorRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		Error(c, http.StatusBadRequest, fmt.Errorf("Invalid json request: %w", err))
+		return
+	}
+
+	if req.K < 0 {
+		req.K = 0
+	}
+	if req.Threshold < 0 || req.Threshold >= 1 {
+		Error(c, http.StatusBadRequest, fmt.Errorf("Invalid `threshold`: %v (0 < threshold < 1)", req.Threshold))
+		return
+	}
+
+	t0 := time.Now()
+	infers, err := a.I.InferTensor(c.Request.Context(), model, req.Data, req.Shape, req.K, req.Threshold)
+	if err != nil {
+		Error(c, errorStatus(err, http.StatusBadRequest), err)
+		return
+	}
+	a.recordInference(c, model, 1)
+
+	c.JSON(http.StatusOK, gin.H{
+		"shape":       req.Shape,
+		"inference":   infers,
+		"served":      servedModels(c),
+		"elapsed(ms)": time.Since(t0).Milliseconds(),
+	})
+}
+
+// decodeBase64Image base64 이미지와 data URL에 있는 이미지 형식 반환
+//
+//	data:image/png;base64,iVBORw0KGgo...
+func decodeBase64Image(s string) ([]byte, string, error) {
+	if s == "" {
+		return nil, "", errors.New("`image` is required")
+	}
+
+	var format string
+	if strings.HasPrefix(s, "data:") {
+		idx := strings.Index(s, ",")
+		if idx < 0 || !strings.HasSuffix(s[:idx], ";base64") {
+			return nil, "", errors.New("Invalid data URL in `image`")
+		}
+		format = strings.TrimPrefix(strings.TrimSuffix(s[len("data:"):idx], ";base64"), "image/")
+		s = s[idx+1:]
+	}
+
+	image, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		// padding이 없는 base64도 허용
+		if image, err = base64.RawStdEncoding.DecodeString(s); err != nil {
+			return nil, "", fmt.Errorf("Invalid base64 `image`: %w", err)
+		}
+	}
+
+	return image, format, nil
+}
+
+// runInfer 추론 후 결과 응답
+//
+// raw면 결과에 모델 출력(outputs), 출력 순서의 클래스 이름(labels), 활성화 함수(activation)와 제외 된 클래스 수(filtered)를,
+// timing이면 전처리, 모델 실행과 후처리 단계별 소요 시간(timing)을 추가
+func (a *APIs) runInfer(c *gin.Context, model string, image []byte, fileName, format string, k int, threshold float32, raw, timing bool) {
+	t0 := time.Now()
+
+	ctx := c.Request.Context()
+	var timings *inference.Timings
+	if timing {
+		timings = &inference.Timings{}
+		ctx = inference.ContextWithTimings(ctx, timings)
+	}
+
+	var (
+		result *inference.RawInference
+		err    error
+	)
+	if raw {
+		result, err = a.I.InferRaw(ctx, model, image, format, k, threshold)
+	} else {
+		result = &inference.RawInference{}
+		result.Inference, err = a.I.Infer(ctx, model, image, format, k, threshold)
+	}
+	if err != nil {
+		Error(c, errorStatus(err, http.StatusBadRequest), err)
+		return
+	}
+
+	elapsed := time.Since(t0)
+	a.recordInference(c, model, 1)
+
+	res := gin.H{
+		"file":        fileName,
+		"format":      format,
+		"bytes":       len(image),
+		"inference":   result.Inference,
+		"served":      servedModels(c),
+		"elapsed(ms)": elapsed.Milliseconds(),
+	}
+	if raw {
+		res["outputs"] = result.Outputs
+		res["labels"] = result.Labels
+		res["activation"] = result.Activation
+		if result.Temperature != 0 {
+			res["temperature"] = result.Temperature
+		}
+		if result.Filtered != nil {
+			res["filtered"] = result.Filtered
+		}
+	}
+	if timing {
+		res["timing"] = timings
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// inferImage 추론할 이미지, 파일 이름과 이미지 형식 반환
+//
+// subject, category, filename querystring이 있으면 업로드 된 파일 대신 저장 된 이미지를,
+// url querystring이 있으면 URL에서 가져온 이미지를 사용
+func (a *APIs) inferImage(c *gin.Context) ([]byte, string, string, error) {
+	if rawURL := c.Query("url"); rawURL != "" {
+		image, format, err := a.fetcher().Fetch(c.Request.Context(), rawURL)
+		if err != nil {
+			return nil, "", "", err
+		}
+		if f := c.Query("format"); f != "" {
+			format = f
+		}
+
+		return image, rawURL, format, nil
+	}
+
+	if fileName := c.Query("filename"); fileName != "" {
+		if a.M == nil {
+			return nil, "", "", errStoredImageUnavailable
+		}
+
+		image, format, err := a.M.ReadImage(c.Query("subject"), c.Query("category"), fileName)
+		if err != nil {
+			return nil, "", "", err
+		}
+
+		return image, fileName, format, nil
+	}
+
+	image, fileName, err := readUploadedImage(c.Request, a.MaxImageSize)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	// 확장자가 없으면 추론시 이미지 내용으로 형식을 판단
+	format := c.Query("format")
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(fileName), ".")
+	}
+
+	return image, fileName, format, nil
+}
+
+// readUploadedImage multipart 요청의 image 파일과 파일 이름 반환
+//
+// multipart form 전체를 memory나 임시 파일에 저장하지 않고 image part만 최대 maxSize byte까지 읽음
+func readUploadedImage(req *http.Request, maxSize int64) ([]byte, string, error) {
+	part, err := uploadedPart(req, "image")
+	if err != nil {
+		return nil, "", err
+	}
+
+	image, err := readImagePart(part, maxSize)
+	part.Close()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return image, part.FileName(), nil
+}
+
+// readImagePart 업로드 된 이미지를 최대 maxSize byte까지 읽음 (maxSize가 0이면 제한 없음)
+//
+// 최대 크기를 넘으면 추론시 이미지 크기 제한과 같은 *inference.ImageTooLargeError 반환
+func readImagePart(r io.Reader, maxSize int64) ([]byte, error) {
+	if maxSize <= 0 {
+		return ioutil.ReadAll(r)
+	}
+
+	image, err := ioutil.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(image)) > maxSize {
+		return nil, &inference.ImageTooLargeError{Bytes: int64(len(image)), Limits: inference.ImageLimits{MaxBytes: maxSize}}
+	}
+
+	return image, nil
+}
+
+// readUploadedFile multipart 요청의 name 파일과 파일 이름 반환 (maxSize가 0보다 크면 최대 maxSize byte)
+func readUploadedFile(req *http.Request, name string, maxSize int64) ([]byte, string, error) {
+	part, err := uploadedPart(req, name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var b []byte
+	if maxSize > 0 {
+		b, err = readAllLimited(part, maxSize)
+	} else {
+		b, err = ioutil.ReadAll(part)
+	}
+	part.Close()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return b, part.FileName(), nil
+}
+
+// uploadedPart multipart 요청에서 name 파일의 part 반환 (읽은 후 Close 호출)
+func uploadedPart(req *http.Request, name string) (*multipart.Part, error) {
+	reader, err := req.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, http.ErrMissingFile
+		} else if err != nil {
+			return nil, err
+		}
+
+		if part.FormName() == name && part.FileName() != "" {
+			return part, nil
+		}
+		part.Close()
+	}
+}
+
+// defaultFetcher APIs.F가 없을 때 사용하는 ImageFetcher
+var defaultFetcher = &ImageFetcher{}
+
+// fetcher URL 이미지를 가져오는 ImageFetcher
+func (a *APIs) fetcher() *ImageFetcher {
+	if a.F == nil {
+		return defaultFetcher
+	}
+
+	return a.F
+}
+
+// Compare 하나의 이미지를 여러 모델로 추론하여 라벨별 확률과 차이를 반환
+//
+// models는 `,`로 구분하며 첫 번째 모델이 비교 기준
+func (a *APIs) Compare(c *gin.Context) {
+	models, err := parseModels(c)
+	if err != nil {
+		Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	image, fileName, format, err := a.inferImage(c)
+	if err != nil {
+		Error(c, errorStatus(err, http.StatusBadRequest), err)
+		return
+	}
+
+	t0 := time.Now()
+	comparison, err := a.I.Compare(c.Request.Context(), models, image, format)
+	if err != nil {
+		Error(c, errorStatus(err, http.StatusBadRequest), err)
+		return
+	}
+	elapsed := time.Since(t0)
+
+	for _, model := range models {
+		a.recordInference(c, model, 1)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"file":        fileName,
+		"format":      format,
+		"bytes":       len(image),
+		"comparison":  comparison,
+		"served":      servedModels(c),
+		"elapsed(ms)": elapsed.Milliseconds(),
+	})
+}
+
+// queryTags `,`로 구분 된 tags querystring
+func queryTags(c *gin.Context) []string {
+	var tags []string
+	for _, tag := range strings.Split(c.Query("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// InferAll 하나의 이미지를 로드 된 모든 모델로 추론하여 모델별 결과를 반환
+//
+// tags는 `,`로 구분하며, 지정하면 모델 config의 modelTags에 tags가 모두 있는 모델만 추론
+func (a *APIs) InferAll(c *gin.Context) {
+	tags := queryTags(c)
+
+	image, fileName, format, err := a.inferImage(c)
+	if err != nil {
+		Error(c, errorStatus(err, http.StatusBadRequest), err)
+		return
+	}
+
+	topK, threshold, err := inferParams(c)
+	if err != nil {
+		Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	t0 := time.Now()
+	results, err := a.I.InferAll(c.Request.Context(), tags, image, format, topK, threshold)
+	if err != nil {
+		Error(c, errorStatus(err, http.StatusBadRequest), err)
+		return
+	}
+	elapsed := time.Since(t0)
+
+	for _, result := range results {
+		if result.Error == "" {
+			a.recordInference(c, result.Model, 1)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"file":        fileName,
+		"format":      format,
+		"bytes":       len(image),
+		"results":     results,
+		"served":      servedModels(c),
+		"elapsed(ms)": elapsed.Milliseconds(),
+	})
+}
+
+// InferPipeline pipeline의 gate 모델을 통과한 이미지만 다음 단계의 모델로 추론
+func (a *APIs) InferPipeline(c *gin.Context) {
+	pipeline := c.Param("pipeline")
+
+	image, fileName, format, err := a.inferImage(c)
+	if err != nil {
+		Error(c, errorStatus(err, http.StatusBadRequest), err)
+		return
+	}
+
+	topK, threshold, err := inferParams(c)
+	if err != nil {
+		Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	t0 := time.Now()
+	result, err := a.I.InferPipeline(c.Request.Context(), pipeline, image, format, topK, threshold)
+	if err != nil {
+		Error(c, errorStatus(err, http.StatusBadRequest), err)
+		return
+	}
+	elapsed := time.Since(t0)
+
+	for _, stage := range result.Stages {
+		a.recordInference(c, stage.Model, 1)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"file":        fileName,
+		"format":      format,
+		"bytes":       len(image),
+		"pipeline":    result.Pipeline,
+		"passed":      result.Passed,
+		"inference":   result.Inference,
+		"stages":      result.Stages,
+		"served":      servedModels(c),
+		"elapsed(ms)": elapsed.Milliseconds(),
+	})
+}
+
+// InferVideo 동영상에서 초당 fps개의 frame을 추출하여 frame별 결과와 다수결, 평균 반환
+func (a *APIs) InferVideo(c *gin.Context) {
+	model := c.Param("model")
+
+	fps := constants.DefaultVideoFPS
+	if s := c.Query("fps"); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil || v <= 0 || v > constants.MaxVideoFPS {
+			Error(c, http.StatusBadRequest, fmt.Errorf("Invalid `fps`: %q (0 ~ %v)", s, constants.MaxVideoFPS))
+			return
+		}
+		fps = v
+	}
+
+	topK, threshold, err := inferParams(c)
+	if err != nil {
+		Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	video, fileName, err := readUploadedFile(c.Request, "video", int64(constants.MaxVideoMB)<<20)
+	if err != nil {
+		Error(c, errorStatus(err, http.StatusBadRequest), err)
+		return
+	}
+
+	t0 := time.Now()
+	result, err := a.I.InferVideo(c.Request.Context(), model, video, fps, topK, threshold)
+	if err != nil {
+		Error(c, errorStatus(err, http.StatusBadRequest), err)
+		return
+	}
+	elapsed := time.Since(t0)
+
+	a.recordInference(c, model, len(result.Frames))
+
+	c.JSON(http.StatusOK, gin.H{
+		"file":        fileName,
+		"bytes":       len(video),
+		"fps":         result.FPS,
+		"frames":      result.Frames,
+		"majority":    result.Majority,
+		"average":     result.Average,
+		"served":      servedModels(c),
+		"elapsed(ms)": elapsed.Milliseconds(),
+	})
+}
+
+// Detect detection 모델로 이미지의 대상과 위치를 검출
+func (a *APIs) Detect(c *gin.Context) {
+	model := c.Param("model")
+
+	image, fileName, format, err := a.inferImage(c)
+	if err != nil {
+		Error(c, errorStatus(err, http.StatusBadRequest), err)
+		return
+	}
+
+	topK, threshold, err := inferParams(c)
+	if err != nil {
+		Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	t0 := time.Now()
+	detections, err := a.I.Detect(c.Request.Context(), model, image, format, topK, threshold)
+	if err != nil {
+		Error(c, errorStatus(err, http.StatusBadRequest), err)
+		return
+	}
+	elapsed := time.Since(t0)
+	a.recordInference(c, model, 1)
+
+	c.JSON(http.StatusOK, gin.H{
+		"file":        fileName,
+		"format":      format,
+		"bytes":       len(image),
+		"detections":  detections,
+		"served":      servedModels(c),
+		"elapsed(ms)": elapsed.Milliseconds(),
+	})
+}
+
+// Segment segmentation 모델로 이미지의 픽셀별 클래스를 mask로 반환
+func (a *APIs) Segment(c *gin.Context) {
+	model := c.Param("model")
+
+	image, fileName, format, err := a.inferImage(c)
+	if err != nil {
+		Error(c, errorStatus(err, http.StatusBadRequest), err)
+		return
+	}
+
+	_, threshold, err := inferParams(c)
+	if err != nil {
+		Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	t0 := time.Now()
+	result, err := a.I.Segment(c.Request.Context(), model, image, format, threshold, c.Query("encoding"))
 	if err != nil {
-		Error(c, http.StatusBadRequest, err)
+		Error(c, errorStatus(err, http.StatusBadRequest), err)
 		return
 	}
-	defer file.Close()
+	elapsed := time.Since(t0)
+	a.recordInference(c, model, 1)
 
-	var (
-		image bytes.Buffer
-		bytes int64
-		n     int64
-	)
+	c.JSON(http.StatusOK, gin.H{
+		"file":         fileName,
+		"format":       format,
+		"bytes":        len(image),
+		"segmentation": result,
+		"served":       servedModels(c),
+		"elapsed(ms)":  elapsed.Milliseconds(),
+	})
+}
 
-	if n, err = io.Copy(&image, file); err != nil {
-		Error(c, http.StatusBadRequest, err)
+// Explain 추론 결과의 클래스에 영향을 준 이미지 영역을 Grad-CAM heatmap으로 반환
+//
+// 기본값은 heatmap을 원본 이미지 위에 겹친 PNG이며, `output=json`이면 heatmap 값과 함께 json으로 반환
+func (a *APIs) Explain(c *gin.Context) {
+	model := c.Param("model")
+
+	output := c.DefaultQuery("output", "png")
+	if output != "png" && output != "json" {
+		Error(c, http.StatusBadRequest, fmt.Errorf("Invalid `output`: %q (png, json)", output))
 		return
 	}
-	bytes = n
 
-	format := strings.Split(header.Filename, ".")[1]
-
-	k := c.Query("k")
-	topK, err := strconv.Atoi(k)
+	image, fileName, format, err := a.inferImage(c)
 	if err != nil {
-		topK = constants.DefaultMultiClassMax
+		Error(c, errorStatus(err, http.StatusBadRequest), err)
+		return
 	}
 
 	t0 := time.Now()
-	if infers, err := a.I.Infer(model, image.String(), format, topK); err == nil {
-		elapsed := time.Since(t0)
-		c.JSON(http.StatusOK, gin.H{
-			"file":        header.Filename,
-			"format":      format,
-			"bytes":       bytes,
-			"inference":   infers,
-			"elapsed(ms)": elapsed.Milliseconds(),
-		})
-	} else {
-		Error(c, http.StatusBadRequest, err)
+	explanation, err := a.I.Explain(c.Request.Context(), model, image, format, c.Query("label"))
+	if err != nil {
+		Error(c, errorStatus(err, http.StatusBadRequest), err)
+		return
 	}
+	elapsed := time.Since(t0)
+	a.recordInference(c, model, 1)
+
+	if output == "png" {
+		c.Header("X-Explain-Label", url.QueryEscape(explanation.Label))
+		c.Header("X-Explain-Probability", strconv.FormatFloat(float64(explanation.Probability), 'f', -1, 32))
+		c.Header("X-Explain-Method", explanation.Method)
+		for _, served := range servedModels(c) {
+			c.Header("X-Explain-Model", url.QueryEscape(served.Model))
+			c.Header("X-Explain-Version", strconv.Itoa(served.Version))
+		}
+		c.Header("X-Explain-Elapsed-Ms", strconv.FormatInt(elapsed.Milliseconds(), 10))
+		c.Data(http.StatusOK, "image/png", explanation.Overlay)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"file":        fileName,
+		"format":      format,
+		"bytes":       len(image),
+		"explanation": explanation,
+		"served":      servedModels(c),
+		"elapsed(ms)": elapsed.Milliseconds(),
+	})
 }
 
 // CreateModel model 생성
 func (a *APIs) CreateModel(c *gin.Context) {
+	a.train(c, a.I.CreateModel)
+}
+
+// CreateVersion 사용 중인 버전으로 계속 추론하면서 model의 새 버전 학습
+func (a *APIs) CreateVersion(c *gin.Context) {
+	a.train(c, a.I.CreateVersion)
+}
+
+// train 요청의 학습 조건으로 create를 호출하여 model 학습
+func (a *APIs) train(c *gin.Context, create func(ctx context.Context, model, subject, desc string, epochs int, trial bool) (*inference.CreateResult, error)) {
 	model := c.Param("model")
 	if model == "" {
 		Error(c, http.StatusBadRequest, errors.New("Empty model name"))
@@ -114,8 +857,75 @@ func (a *APIs) CreateModel(c *gin.Context) {
 		nrEpochs = constants.TrainEpochs
 	}
 
-	if res, err := a.I.CreateModel(model, subject, desc, nrEpochs, trial); err != nil {
-		Error(c, http.StatusInternalServerError, err)
+	if res, err := create(c.Request.Context(), model, subject, desc, nrEpochs, trial); err != nil {
+		Error(c, errorStatus(err, http.StatusInternalServerError), err)
+	} else {
+		a.recordTraining(c, model)
+		// 학습에 사용 된 이미지 표시
+		if subject != "" && a.M != nil {
+			if err := a.M.MarkTrained(subject); err != nil {
+				log.Printf("Fail to mark trained images(%s): %s", subject, err)
+			}
+		}
+		c.JSON(http.StatusOK, res)
+	}
+}
+
+// ImportModel 외부에서 학습한 모델의 tar.gz 파일(multipart의 archive 또는 요청 body)을 model로 등록
+func (a *APIs) ImportModel(c *gin.Context) {
+	model := c.Param("model")
+
+	var archive io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		part, err := uploadedPart(c.Request, "archive")
+		if err != nil {
+			Error(c, http.StatusBadRequest, err)
+			return
+		}
+		defer part.Close()
+		archive = part
+	}
+
+	if res, err := a.I.ImportModel(c.Request.Context(), model, archive); err != nil {
+		Error(c, errorStatus(err, http.StatusInternalServerError), err)
+	} else {
+		c.JSON(http.StatusOK, res)
+	}
+}
+
+// ExportModel model의 모델 파일을 tar.gz로 전송
+//
+// 전송을 시작한 후의 에러는 응답을 중단하므로 클라이언트는 불완전한 파일을 받음
+func (a *APIs) ExportModel(c *gin.Context) {
+	model := c.Param("model")
+
+	name := strings.NewReplacer(inference.VersionSeparator, "-v").Replace(model)
+	c.Header("Content-Type", "application/gzip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".tar.gz"))
+
+	if err := a.I.ExportModel(c.Request.Context(), model, c.Writer); err != nil {
+		if c.Writer.Written() {
+			log.Printf("Fail to export model(%s): %s", model, err)
+			c.Abort()
+			return
+		}
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		Error(c, errorStatus(err, http.StatusInternalServerError), err)
+	}
+}
+
+// CloneModel model의 파일을 복사해 name 쿼리의 새 모델로 등록
+func (a *APIs) CloneModel(c *gin.Context) {
+	model := c.Param("model")
+	name := c.Query("name")
+	if name == "" {
+		Error(c, http.StatusBadRequest, errors.New("Empty model name"))
+		return
+	}
+
+	if res, err := a.I.CloneModel(c.Request.Context(), model, name); err != nil {
+		Error(c, errorStatus(err, http.StatusInternalServerError), err)
 	} else {
 		c.JSON(http.StatusOK, res)
 	}
@@ -132,14 +942,14 @@ func (a *APIs) OperateModel(c *gin.Context) {
 		return
 	}
 
-	if err := a.I.OperateModel(model, params.ModelPath); err != nil {
-		Error(c, http.StatusInternalServerError, err)
+	if err := a.I.OperateModel(c.Request.Context(), model, params.ModelPath); err != nil {
+		Error(c, errorStatus(err, http.StatusInternalServerError), err)
 	} else {
 		c.String(http.StatusOK, "OK")
 	}
 }
 
-// DeleteModel model 생성
+// DeleteModel model 삭제 (force를 지정하면 진행 중인 추론이 끝나기를 기다려 삭제)
 func (a *APIs) DeleteModel(c *gin.Context) {
 	model := c.Param("model")
 	if model == "" {
@@ -147,8 +957,82 @@ func (a *APIs) DeleteModel(c *gin.Context) {
 		return
 	}
 
-	if err := a.I.DeleteModel(model); err != nil {
-		Error(c, http.StatusInternalServerError, err)
+	var err error
+	if _, force := c.GetQuery("force"); force {
+		var timeout time.Duration
+		if timeout, err = parseDrainTimeout(c); err != nil {
+			Error(c, http.StatusBadRequest, err)
+			return
+		}
+		err = a.I.ForceDeleteModel(c.Request.Context(), model, timeout)
+	} else {
+		err = a.I.DeleteModel(c.Request.Context(), model)
+	}
+
+	if err != nil {
+		Error(c, errorStatus(err, http.StatusInternalServerError), err)
+	} else {
+		c.JSON(http.StatusOK, gin.H{
+			"model": model,
+		})
+	}
+}
+
+// UpdateModel model의 설명, modelTags와 기본 threshold 변경
+func (a *APIs) UpdateModel(c *gin.Context) {
+	model := c.Param("model")
+
+	var update inference.ModelUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := a.I.UpdateModel(c.Request.Context(), model, update); err != nil {
+		Error(c, errorStatus(err, http.StatusInternalServerError), err)
+	} else {
+		c.JSON(http.StatusOK, gin.H{
+			"model": model,
+		})
+	}
+}
+
+// DisableModel model의 추론을 중지
+func (a *APIs) DisableModel(c *gin.Context) {
+	a.setModelDisabled(c, true)
+}
+
+// EnableModel 추론을 중지한 model의 추론을 다시 시작
+func (a *APIs) EnableModel(c *gin.Context) {
+	a.setModelDisabled(c, false)
+}
+
+func (a *APIs) setModelDisabled(c *gin.Context, disabled bool) {
+	model := c.Param("model")
+
+	var err error
+	if disabled {
+		err = a.I.DisableModel(c.Request.Context(), model)
+	} else {
+		err = a.I.EnableModel(c.Request.Context(), model)
+	}
+
+	if err != nil {
+		Error(c, errorStatus(err, http.StatusInternalServerError), err)
+	} else {
+		c.JSON(http.StatusOK, gin.H{
+			"model":    model,
+			"disabled": disabled,
+		})
+	}
+}
+
+// ReloadModel model의 config, labels와 SavedModel을 다시 로드
+func (a *APIs) ReloadModel(c *gin.Context) {
+	model := c.Param("model")
+
+	if err := a.I.ReloadModel(c.Request.Context(), model); err != nil {
+		Error(c, errorStatus(err, http.StatusInternalServerError), err)
 	} else {
 		c.JSON(http.StatusOK, gin.H{
 			"model": model,
@@ -156,6 +1040,121 @@ func (a *APIs) DeleteModel(c *gin.Context) {
 	}
 }
 
+// RollbackModel model의 사용 중인 버전을 이전 버전으로 되돌림
+func (a *APIs) RollbackModel(c *gin.Context) {
+	model := c.Param("model")
+
+	if version, err := a.I.RollbackModel(c.Request.Context(), model); err != nil {
+		Error(c, errorStatus(err, http.StatusInternalServerError), err)
+	} else {
+		c.JSON(http.StatusOK, gin.H{
+			"model":   model,
+			"version": version,
+		})
+	}
+}
+
+// ListTenants 사용자 목록 반환
+func (a *APIs) ListTenants(c *gin.Context) {
+	tenants := a.I.GetTenants(c.Request.Context())
+	c.JSON(http.StatusOK, gin.H{
+		"tenants": tenants,
+	})
+}
+
+// ShowTenant 사용자 정보 반환
+func (a *APIs) ShowTenant(c *gin.Context) {
+	tenant := c.Param("tenant")
+
+	if info, err := a.I.GetTenant(c.Request.Context(), tenant); err != nil {
+		Error(c, errorStatus(err, http.StatusInternalServerError), err)
+	} else {
+		c.JSON(http.StatusOK, info)
+	}
+}
+
+// CreateTenant 사용자 생성
+func (a *APIs) CreateTenant(c *gin.Context) {
+	tenant := c.Param("tenant")
+
+	// quota는 MB 단위
+	var quota int64
+	if q, ok := c.GetQuery("quota"); ok {
+		v, err := strconv.ParseInt(q, 10, 64)
+		if err != nil || v <= 0 {
+			Error(c, http.StatusBadRequest, fmt.Errorf("Invalid `quota`: %q", q))
+			return
+		}
+		quota = v << 20
+	}
+
+	if err := a.I.CreateTenant(c.Request.Context(), tenant, quota); err != nil {
+		Error(c, errorStatus(err, http.StatusInternalServerError), err)
+	} else {
+		c.JSON(http.StatusOK, gin.H{
+			"tenant": tenant,
+		})
+	}
+}
+
+// DeleteTenant 사용자와 사용자의 모든 모델 삭제
+func (a *APIs) DeleteTenant(c *gin.Context) {
+	tenant := c.Param("tenant")
+
+	if err := a.I.DeleteTenant(c.Request.Context(), tenant); err != nil {
+		Error(c, errorStatus(err, http.StatusInternalServerError), err)
+	} else {
+		c.JSON(http.StatusOK, gin.H{
+			"tenant": tenant,
+		})
+	}
+}
+
+// ListAliases 모델 별칭과 대상 모델 반환
+func (a *APIs) ListAliases(c *gin.Context) {
+	aliases := a.I.GetAliases(c.Request.Context())
+	c.JSON(http.StatusOK, gin.H{
+		"aliases": aliases,
+	})
+}
+
+// SetAlias 모델 별칭이 `model`(`version`이 있으면 해당 버전)을 가리키도록 지정
+func (a *APIs) SetAlias(c *gin.Context) {
+	alias := c.Param("alias")
+
+	model := c.Query("model")
+	if model == "" {
+		Error(c, http.StatusBadRequest, errors.New("Empty `model`"))
+		return
+	}
+	if version := c.Query("version"); version != "" {
+		model += inference.VersionSeparator + version
+	}
+
+	if previous, err := a.I.SetAlias(c.Request.Context(), alias, model); err != nil {
+		Error(c, errorStatus(err, http.StatusInternalServerError), err)
+	} else {
+		c.JSON(http.StatusOK, gin.H{
+			"alias":    alias,
+			"model":    model,
+			"previous": previous,
+		})
+	}
+}
+
+// DeleteAlias 모델 별칭 삭제
+func (a *APIs) DeleteAlias(c *gin.Context) {
+	alias := c.Param("alias")
+
+	if err := a.I.DeleteAlias(c.Request.Context(), alias); err != nil {
+		Error(c, errorStatus(err, http.StatusInternalServerError), err)
+	} else {
+		c.JSON(http.StatusOK, gin.H{
+			"alias": alias,
+		})
+	}
+}
+
 // UploadImages image 업로드
 func (a *APIs) UploadImages(c *gin.Context) {
 	var (
@@ -170,6 +1169,13 @@ func (a *APIs) UploadImages(c *gin.Context) {
 		Error(c, http.StatusBadRequest, errors.New("Empty `category`"))
 		return
 	}
+	// subject와 category는 이미지 저장 경로로 사용
+	for _, name := range []string{subject, category} {
+		if err := inference.ValidateName(name); err != nil {
+			Error(c, http.StatusBadRequest, err)
+			return
+		}
+	}
 
 	form, err := c.MultipartForm()
 	if err != nil {
@@ -213,14 +1219,225 @@ func (a *APIs) ListImages(c *gin.Context) {
 	}
 }
 
-// HTTPError api 에러 메시지
-type HTTPError struct {
-	Error string `json:"error"`
+// ListCategories 이미지 그룹의 카테고리별 이미지 수 반환
+func (a *APIs) ListCategories(c *gin.Context) {
+	subject := c.Param("subject")
+
+	if result, err := a.M.ListCategories(subject); err != nil {
+		Error(c, http.StatusInternalServerError, err)
+	} else {
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// BrowseImages 카테고리의 이미지 정보를 page 단위로 반환
+func (a *APIs) BrowseImages(c *gin.Context) {
+	subject := c.Param("subject")
+	category := c.Param("category")
+
+	page, size, err := parsePage(c)
+	if err != nil {
+		Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	preview := func(item db.Item) string {
+		return fmt.Sprintf("/datasets/%s/%s/%s",
+			url.PathEscape(item.Subject), url.PathEscape(item.Category), url.PathEscape(item.Filename))
+	}
+
+	if result, err := a.M.BrowseImages(subject, category, page, size, preview); err != nil {
+		Error(c, http.StatusInternalServerError, err)
+	} else {
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// ShowImage 저장 된 이미지 또는 썸네일 반환
+func (a *APIs) ShowImage(c *gin.Context) {
+	subject := c.Param("subject")
+	category := c.Param("category")
+	fileName := c.Param("filename")
+
+	var (
+		file string
+		err  error
+	)
+	if t, ok := c.GetQuery("thumbnail"); ok {
+		size, convErr := strconv.Atoi(t)
+		if convErr != nil {
+			Error(c, http.StatusBadRequest, fmt.Errorf("Invalid `thumbnail`: %q", t))
+			return
+		}
+		file, err = a.M.Thumbnail(subject, category, fileName, size)
+	} else {
+		file, err = a.M.ImageFile(subject, category, fileName)
+	}
+
+	if err != nil {
+		Error(c, errorStatus(err, http.StatusInternalServerError), err)
+		return
+	}
+
+	c.File(file)
+}
+
+// parsePage page와 size querystring 반환
+// ReportDuplicates subject의 중복 의심 이미지 반환
+func (a *APIs) ReportDuplicates(c *gin.Context) {
+	subject := c.Param("subject")
+
+	distance, err := parseDistance(c)
+	if err != nil {
+		Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if result, err := a.M.DuplicateReport(subject, distance); err != nil {
+		Error(c, http.StatusInternalServerError, err)
+	} else {
+		c.JSON(http.StatusOK, result)
+	}
 }
 
-// Error api 에러를 담은 json 응답 생성
-func Error(c *gin.Context, status int, err error) {
-	c.JSON(status, HTTPError{
-		Error: err.Error(),
+// ReportUsage 기간 동안의 모델 또는 API key별 사용량 반환
+func (a *APIs) ReportUsage(c *gin.Context) {
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	by := c.DefaultQuery("by", db.UsageByModel)
+	if by != db.UsageByModel && by != db.UsageByAPIKey {
+		Error(c, http.StatusBadRequest, fmt.Errorf("Invalid `by`: %q (%s or %s)", by, db.UsageByModel, db.UsageByAPIKey))
+		return
+	}
+
+	format := c.DefaultQuery("format", reportJSON)
+	if format != reportJSON && format != reportCSV {
+		Error(c, http.StatusBadRequest, fmt.Errorf("Invalid `format`: %q (%s or %s)", format, reportJSON, reportCSV))
+		return
+	}
+
+	usages, err := a.M.UsageReport(from, to, by)
+	if err != nil {
+		Error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	var rows []usageRow
+	if by == db.UsageByModel {
+		rows = a.modelUsageRows(c.Request.Context(), usages)
+	} else {
+		for _, u := range usages {
+			rows = append(rows, usageRow{Usage: u})
+		}
+	}
+
+	if format == reportCSV {
+		var b bytes.Buffer
+		if err := writeUsageCSV(&b, by, rows); err != nil {
+			Error(c, http.StatusInternalServerError, err)
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=usage-%s-%s.csv",
+			from.Format(dateLayout), to.Format(dateLayout)))
+		c.Data(http.StatusOK, "text/csv", b.Bytes())
+		return
+	}
+
+	if rows == nil {
+		rows = make([]usageRow, 0)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"from":   from.Format(dateLayout),
+		"to":     to.Format(dateLayout),
+		"by":     by,
+		"usages": rows,
 	})
 }
+
+// parseModels 비교할 모델 목록 (2개 이상 MaxCompareModels 이하, 중복 불가)
+func parseModels(c *gin.Context) ([]string, error) {
+	var models []string
+	seen := make(map[string]bool)
+	for _, model := range strings.Split(c.Query("models"), ",") {
+		if model = strings.TrimSpace(model); model == "" {
+			continue
+		}
+		if seen[model] {
+			return nil, fmt.Errorf("Duplicated model in `models`: %s", model)
+		}
+		seen[model] = true
+		models = append(models, model)
+	}
+
+	if len(models) < 2 || len(models) > constants.MaxCompareModels {
+		return nil, fmt.Errorf("Invalid `models`: %q (2 ~ %d models)", c.Query("models"), constants.MaxCompareModels)
+	}
+
+	return models, nil
+}
+
+func parseDistance(c *gin.Context) (int, error) {
+	d, ok := c.GetQuery("distance")
+	if !ok {
+		return constants.DefaultDuplicateDistance, nil
+	}
+
+	distance, err := strconv.Atoi(d)
+	if err != nil || distance < 0 || distance > constants.MaxDuplicateDistance {
+		return 0, fmt.Errorf("Invalid `distance`: %q (0 ~ %d)", d, constants.MaxDuplicateDistance)
+	}
+
+	return distance, nil
+}
+
+func parseDrainTimeout(c *gin.Context) (time.Duration, error) {
+	t, ok := c.GetQuery("timeout")
+	if !ok {
+		return time.Duration(constants.DefaultDrainSeconds) * time.Second, nil
+	}
+
+	seconds, err := strconv.Atoi(t)
+	if err != nil || seconds < 0 || seconds > constants.MaxDrainSeconds {
+		return 0, fmt.Errorf("Invalid `timeout`: %q (0 ~ %d)", t, constants.MaxDrainSeconds)
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
+
+func parsePage(c *gin.Context) (int, int, error) {
+	page, size := 1, constants.DefaultPageSize
+
+	if p, ok := c.GetQuery("page"); ok {
+		v, err := strconv.Atoi(p)
+		if err != nil || v < 1 {
+			return 0, 0, fmt.Errorf("Invalid `page`: %q", p)
+		}
+		page = v
+	}
+
+	if s, ok := c.GetQuery("size"); ok {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 1 || v > constants.MaxPageSize {
+			return 0, 0, fmt.Errorf("Invalid `size`: %q (1 ~ %d)", s, constants.MaxPageSize)
+		}
+		size = v
+	}
+
+	return page, size, nil
+}
+
+func (a *APIs) recordInference(c *gin.Context, model string, n int) {
+	if a.M != nil {
+		a.M.RecordInference(model, c.GetHeader(apiKeyHeader), n)
+	}
+}
+
+func (a *APIs) recordTraining(c *gin.Context, model string) {
+	if a.M != nil {
+		a.M.RecordTraining(model, c.GetHeader(apiKeyHeader))
+	}
+}
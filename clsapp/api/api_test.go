@@ -0,0 +1,1264 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/constants"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/data/db"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/inference"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/inference/mock"
+)
+
+func newTestRouter(i inference.Inferencer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	return NewRouter(&APIs{I: i})
+}
+
+func newImageRequest(url, fileName string, image []byte) *http.Request {
+	var body bytes.Buffer
+
+	w := multipart.NewWriter(&body)
+	fw, _ := w.CreateFormFile("image", fileName)
+	fw.Write(image)
+	w.Close()
+
+	req := httptest.NewRequest(http.MethodPost, url, &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	return req
+}
+
+func TestListModels(t *testing.T) {
+	m := &mock.Inference{
+		GetModelsFunc: func(ctx context.Context, filter inference.ModelFilter) []string {
+			return []string{"default", "flowers"}
+		},
+	}
+
+	w := httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/models", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status: %d", w.Code)
+	}
+
+	var res struct {
+		Models []string `json:"models"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Models) != 2 {
+		t.Fatalf("Unexpected models: %v", res.Models)
+	}
+}
+
+func TestListModelsFilter(t *testing.T) {
+	var filter inference.ModelFilter
+	m := &mock.Inference{
+		GetModelsFunc: func(ctx context.Context, f inference.ModelFilter) []string {
+			filter = f
+			return []string{"flowers"}
+		},
+	}
+	router := newTestRouter(m)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/models?tags=plant,%20v2&classification=multi&status=run&sort=-updated", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status: %d", w.Code)
+	}
+	expected := inference.ModelFilter{Tags: []string{"plant", "v2"}, Classification: "multi", Status: "run", Sort: "-updated"}
+	if !reflect.DeepEqual(filter, expected) {
+		t.Fatalf("Unexpected filter: %+v", filter)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/models?sort=size", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Unknown sort order should fail: %d", w.Code)
+	}
+}
+
+func TestShowModelNotFound(t *testing.T) {
+	m := &mock.Inference{
+		GetModelFunc: func(ctx context.Context, model string, verbose bool) (*inference.ModelInfo, error) {
+			return nil, fmt.Errorf("%w: %s", inference.ErrModelNotFound, model)
+		},
+	}
+
+	w := httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/models/none", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("Unexpected status: %d", w.Code)
+	}
+}
+
+func TestShowModelGraph(t *testing.T) {
+	var gotVerbose bool
+	m := &mock.Inference{
+		GetModelGraphFunc: func(ctx context.Context, model string, verbose bool) (*inference.GraphSummary, error) {
+			gotVerbose = verbose
+			return &inference.GraphSummary{
+				Model: model,
+				Operations: []inference.GraphOp{
+					{Name: "input", Type: "Placeholder"},
+					{Name: "output", Type: "Softmax", Inputs: []string{"input"}},
+				},
+			}, nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/models/flowers/graph?format=dot", nil))
+
+	if w.Code != http.StatusOK || !gotVerbose {
+		t.Fatalf("Unexpected status: %d (verbose %v)", w.Code, gotVerbose)
+	}
+	if !strings.Contains(w.Body.String(), `"input" -> "output"`) {
+		t.Fatalf("Unexpected body: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/models/flowers/graph?format=svg", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Unexpected status: %d", w.Code)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	var got []string
+	m := &mock.Inference{
+		CompareFunc: func(ctx context.Context, models []string, image []byte, format string) (*inference.Comparison, error) {
+			got = models
+			return &inference.Comparison{Agree: true}, nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, newImageRequest("/compare?models=flowers,%20flowers2", "roses.jpg", []byte("image")))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status: %d", w.Code)
+	}
+	if len(got) != 2 || got[0] != "flowers" || got[1] != "flowers2" {
+		t.Fatalf("Unexpected models: %v", got)
+	}
+
+	for _, models := range []string{"", "flowers", "flowers,flowers", "a,b,c,d,e,f"} {
+		w := httptest.NewRecorder()
+		newTestRouter(m).ServeHTTP(w, newImageRequest("/compare?models="+models, "roses.jpg", []byte("image")))
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("Unexpected status for %q: %d", models, w.Code)
+		}
+	}
+}
+
+func TestInferPipeline(t *testing.T) {
+	var gotPipeline string
+	m := &mock.Inference{
+		InferPipelineFunc: func(ctx context.Context, pipeline string, image []byte, format string, k int, threshold float32) (*inference.PipelineResult, error) {
+			gotPipeline = pipeline
+			if pipeline != "products" {
+				return nil, fmt.Errorf("%w: %s", inference.ErrPipelineNotFound, pipeline)
+			}
+			return &inference.PipelineResult{
+				Pipeline:  pipeline,
+				Stages:    []inference.StageResult{{Model: "product-gate", Inference: []inference.InferLabel{{Label: "other", Prob: 0.8}}}},
+				Inference: []inference.InferLabel{{Label: "other", Prob: 0.8}},
+			}, nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, newImageRequest("/pipelines/products", "roses.jpg", []byte("image")))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status: %d %s", w.Code, w.Body.String())
+	}
+	if gotPipeline != "products" {
+		t.Fatalf("Unexpected pipeline: %s", gotPipeline)
+	}
+
+	var res struct {
+		Passed    bool                    `json:"passed"`
+		Inference []inference.InferLabel  `json:"inference"`
+		Stages    []inference.StageResult `json:"stages"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Passed || len(res.Stages) != 1 || res.Inference[0].Label != "other" {
+		t.Fatalf("Unexpected pipeline result: %+v", res)
+	}
+
+	w = httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, newImageRequest("/pipelines/unknown", "roses.jpg", []byte("image")))
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), CodePipelineNotFound) {
+		t.Fatalf("Unexpected response for unknown pipeline: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestInferJob(t *testing.T) {
+	m := &mock.Inference{
+		InferBatchFunc: func(ctx context.Context, model string, images [][]byte, format string, k int, threshold float32) ([][]inference.InferLabel, error) {
+			infers := make([][]inference.InferLabel, len(images))
+			for idx := range images {
+				infers[idx] = []inference.InferLabel{{Label: string(images[idx]), Prob: 0.9}}
+			}
+			return infers, nil
+		},
+	}
+	r := newTestRouter(m)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, name := range []string{"roses", "tulips"} {
+		fw, _ := mw.CreateFormFile("image", name+".jpg")
+		fw.Write([]byte(name))
+	}
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/inference/flowers/jobs", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Unexpected status: %d %s", w.Code, w.Body.String())
+	}
+
+	var submitted struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &submitted); err != nil || submitted.ID == "" {
+		t.Fatalf("Unexpected job: %s %v", w.Body.String(), err)
+	}
+
+	var job struct {
+		Status string                   `json:"status"`
+		Images int                      `json:"images"`
+		Result [][]inference.InferLabel `json:"result"`
+	}
+	for retry := 0; retry < 100; retry++ {
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/jobs/"+submitted.ID, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Unexpected status: %d %s", w.Code, w.Body.String())
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+			t.Fatal(err)
+		}
+		if job.Status == jobSucceeded || job.Status == jobFailed {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if job.Status != jobSucceeded || job.Images != 2 || len(job.Result) != 2 || job.Result[1][0].Label != "tulips" {
+		t.Fatalf("Unexpected job result: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/jobs/"+submitted.ID, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/jobs/"+submitted.ID, nil))
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), CodeJobNotFound) {
+		t.Fatalf("Unexpected response for deleted job: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, newImageRequest("/inference/flowers/jobs?callback=file:///etc/passwd", "roses.jpg", []byte("roses")))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Unexpected response for invalid callback: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestInferBulk(t *testing.T) {
+	m := &mock.Inference{
+		GetModelFunc: func(ctx context.Context, model string, verbose bool) (*inference.ModelInfo, error) {
+			return &inference.ModelInfo{Model: model}, nil
+		},
+		InferFunc: func(ctx context.Context, model string, image []byte, format string, k int, threshold float32) ([]inference.InferLabel, error) {
+			if format != "jpg" {
+				return nil, fmt.Errorf("%w: %s", inference.ErrUnsupportedFormat, format)
+			}
+			return []inference.InferLabel{{Label: string(image), Prob: 0.9}}, nil
+		},
+	}
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for _, name := range []string{"roses/1.jpg", "__MACOSX/roses/._1.jpg", "tulips/2.jpg", "labels.txt"} {
+		fw, _ := zw.Create(name)
+		fw.Write([]byte(path.Base(path.Dir(name))))
+	}
+	zw.Close()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, _ := mw.CreateFormFile("archive", "flowers.zip")
+	fw.Write(archive.Bytes())
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/inference/flowers/bulk", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != mimeJSONLines {
+		t.Fatalf("Unexpected response: %d %s", w.Code, w.Body.String())
+	}
+
+	type result struct {
+		File      string                 `json:"file"`
+		Inference []inference.InferLabel `json:"inference"`
+		Error     *HTTPError             `json:"error"`
+	}
+	var results []result
+	dec := json.NewDecoder(w.Body)
+	for dec.More() {
+		var r result
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, r)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+	if results[1].File != "tulips/2.jpg" || results[1].Inference[0].Label != "tulips" {
+		t.Fatalf("Unexpected result: %+v", results[1])
+	}
+	if results[2].File != "labels.txt" || results[2].Error == nil || results[2].Error.Code != CodeUnsupportedFormat {
+		t.Fatalf("Unexpected error result: %+v", results[2])
+	}
+
+	w = httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/inference/flowers/bulk?dir=flowers", nil))
+	if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), CodeDirectoryForbidden) {
+		t.Fatalf("Unexpected response for disabled directory: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestBulkDir(t *testing.T) {
+	root, err := ioutil.TempDir("", "bulk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	os.MkdirAll(filepath.Join(root, "flowers", "roses"), 0755)
+	os.MkdirAll(filepath.Join(root, "flowers", ".thumbnails"), 0755)
+	ioutil.WriteFile(filepath.Join(root, "flowers", "roses", "1.jpg"), []byte("roses"), 0644)
+	ioutil.WriteFile(filepath.Join(root, "flowers", ".thumbnails", "1.jpg"), []byte("roses"), 0644)
+	ioutil.WriteFile(filepath.Join(root, "flowers", "2.jpg"), []byte("flowers"), 0644)
+
+	a := &APIs{BulkRoot: root}
+	for _, dir := range []string{"../", "flowers/../../etc"} {
+		if _, err := a.bulkDir(dir); !errors.Is(err, errDirForbidden) {
+			t.Fatalf("Directory %s should be forbidden: %v", dir, err)
+		}
+	}
+
+	dir, err := a.bulkDir("flowers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := dirFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, f := range files {
+		names = append(names, f.name)
+	}
+	if strings.Join(names, ",") != "2.jpg,roses/1.jpg" {
+		t.Fatalf("Unexpected files: %v", names)
+	}
+	if image, err := files[1].read(); err != nil || string(image) != "roses" {
+		t.Fatalf("Unexpected image: %q %v", image, err)
+	}
+}
+
+func TestInferVideo(t *testing.T) {
+	var gotFPS float64
+	m := &mock.Inference{
+		InferVideoFunc: func(ctx context.Context, model string, video []byte, fps float64, k int, threshold float32) (*inference.VideoResult, error) {
+			gotFPS = fps
+			return &inference.VideoResult{
+				FPS:      fps,
+				Frames:   []inference.VideoFrame{{Index: 0, Inference: []inference.InferLabel{{Label: "roses", Prob: 0.9}}}},
+				Majority: []inference.VideoVote{{Label: "roses", Frames: 1, Ratio: 1}},
+				Average:  []inference.InferLabel{{Label: "roses", Prob: 0.9}},
+			}, nil
+		},
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, _ := mw.CreateFormFile("video", "roses.mp4")
+	fw.Write([]byte("video"))
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/inference/flowers/video?fps=2", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || gotFPS != 2 {
+		t.Fatalf("Unexpected response: %d %v %s", w.Code, gotFPS, w.Body.String())
+	}
+
+	var res struct {
+		Majority []inference.VideoVote `json:"majority"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Majority) != 1 || res.Majority[0].Label != "roses" {
+		t.Fatalf("Unexpected majority: %+v", res.Majority)
+	}
+
+	w = httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, newImageRequest("/inference/flowers/video?fps=100", "roses.mp4", []byte("video")))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Unexpected response for invalid fps: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestInferAll(t *testing.T) {
+	var gotTags []string
+	m := &mock.Inference{
+		InferAllFunc: func(ctx context.Context, tags []string, image []byte, format string, k int, threshold float32) ([]inference.ModelResult, error) {
+			gotTags = tags
+			return []inference.ModelResult{
+				{Model: "flowers", Inference: []inference.InferLabel{{Label: "roses", Prob: 0.9}}},
+				{Model: "pets", Error: "Corrupt image"},
+			}, nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, newImageRequest("/infer-all?tags=plants,%20outdoor", "roses.jpg", []byte("image")))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status: %d %s", w.Code, w.Body.String())
+	}
+	if len(gotTags) != 2 || gotTags[0] != "plants" || gotTags[1] != "outdoor" {
+		t.Fatalf("Unexpected tags: %v", gotTags)
+	}
+
+	var res struct {
+		Results []inference.ModelResult `json:"results"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Results) != 2 || res.Results[1].Error == "" {
+		t.Fatalf("Unexpected results: %+v", res.Results)
+	}
+
+	w = httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, newImageRequest("/infer-all?threshold=2", "roses.jpg", []byte("image")))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Invalid threshold should fail: %d", w.Code)
+	}
+}
+
+func TestInferRaw(t *testing.T) {
+	m := &mock.Inference{
+		InferFunc: func(ctx context.Context, model string, image []byte, format string, k int, threshold float32) ([]inference.InferLabel, error) {
+			return []inference.InferLabel{{Label: "roses", Prob: 0.9}}, nil
+		},
+		InferRawFunc: func(ctx context.Context, model string, image []byte, format string, k int, threshold float32) (*inference.RawInference, error) {
+			return &inference.RawInference{
+				Inference:  []inference.InferLabel{{Label: "roses", Prob: 0.9}},
+				Outputs:    [][]float32{{0.1, 0.9}},
+				Labels:     []string{"daisy", "roses"},
+				Activation: "none",
+			}, nil
+		},
+	}
+
+	var res struct {
+		Inference  []inference.InferLabel `json:"inference"`
+		Outputs    [][]float32            `json:"outputs"`
+		Labels     []string               `json:"labels"`
+		Activation string                 `json:"activation"`
+	}
+
+	w := httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, newImageRequest("/inference/flowers", "roses.jpg", []byte("image")))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status: %d %s", w.Code, w.Body.String())
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Inference) != 1 || res.Outputs != nil || res.Labels != nil {
+		t.Fatalf("Raw outputs should not be returned by default: %+v", res)
+	}
+
+	w = httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, newImageRequest("/inference/flowers?raw", "roses.jpg", []byte("image")))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status: %d %s", w.Code, w.Body.String())
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Outputs) != 1 || len(res.Labels) != 2 || res.Activation != "none" || len(res.Inference) != 1 {
+		t.Fatalf("Unexpected raw response: %+v", res)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	m := &mock.Inference{
+		DetectFunc: func(ctx context.Context, model string, image []byte, format string, k int, threshold float32) ([]inference.DetectionResult, error) {
+			if model != "coco" {
+				return nil, fmt.Errorf("%w: %s is not a detection model", inference.ErrModelTypeMismatch, model)
+			}
+			return []inference.DetectionResult{
+				{Label: "person", Score: 0.9, Box: inference.Box{YMin: 0.1, XMin: 0.2, YMax: 0.8, XMax: 0.5}},
+			}, nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, newImageRequest("/inference/coco/detection?threshold=0.3", "street.jpg", []byte("image")))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status: %d %s", w.Code, w.Body.String())
+	}
+
+	var res struct {
+		Detections []inference.DetectionResult `json:"detections"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Detections) != 1 || res.Detections[0].Box.XMax != 0.5 {
+		t.Fatalf("Unexpected detections: %+v", res.Detections)
+	}
+
+	w = httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, newImageRequest("/inference/flowers/detection", "roses.jpg", []byte("image")))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), CodeModelTypeMismatch) {
+		t.Fatalf("Classification model should fail: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSegment(t *testing.T) {
+	var gotEncoding string
+	m := &mock.Inference{
+		SegmentFunc: func(ctx context.Context, model string, image []byte, format string, threshold float32, encoding string) (*inference.SegmentationResult, error) {
+			gotEncoding = encoding
+			return &inference.SegmentationResult{
+				Height:   1,
+				Width:    2,
+				Encoding: inference.MaskRLE,
+				RLE:      []int{1, 2},
+				Labels:   []inference.SegmentLabel{{Index: 1, Label: "road", Ratio: 1}},
+			}, nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, newImageRequest("/inference/roads/segmentation?encoding=rle", "street.jpg", []byte("image")))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status: %d %s", w.Code, w.Body.String())
+	}
+	if gotEncoding != inference.MaskRLE {
+		t.Fatalf("Unexpected encoding: %q", gotEncoding)
+	}
+
+	var res struct {
+		Segmentation inference.SegmentationResult `json:"segmentation"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Segmentation.RLE) != 2 || res.Segmentation.Labels[0].Label != "road" {
+		t.Fatalf("Unexpected segmentation: %+v", res.Segmentation)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	var gotLabel string
+	m := &mock.Inference{
+		ExplainFunc: func(ctx context.Context, model string, image []byte, format, label string) (*inference.Explanation, error) {
+			gotLabel = label
+			return &inference.Explanation{
+				Label:       "dog",
+				Probability: 0.5,
+				Method:      inference.ExplainGradCAM,
+				Height:      1,
+				Width:       1,
+				Heatmap:     []float32{1},
+				Overlay:     []byte("png"),
+			}, nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, newImageRequest("/inference/pets/explain?label=dog", "pet.jpg", []byte("image")))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status: %d %s", w.Code, w.Body.String())
+	}
+	if gotLabel != "dog" || w.Header().Get("Content-Type") != "image/png" || w.Body.String() != "png" {
+		t.Fatalf("Unexpected png response: %q %q", gotLabel, w.Header().Get("Content-Type"))
+	}
+	if w.Header().Get("X-Explain-Label") != "dog" || w.Header().Get("X-Explain-Method") != inference.ExplainGradCAM {
+		t.Fatalf("Unexpected headers: %v", w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, newImageRequest("/inference/pets/explain?output=json", "pet.jpg", []byte("image")))
+	var res struct {
+		Explanation inference.Explanation `json:"explanation"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if string(res.Explanation.Overlay) != "png" || len(res.Explanation.Heatmap) != 1 {
+		t.Fatalf("Unexpected explanation: %+v", res.Explanation)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	var gotN int
+	m := &mock.Inference{
+		SearchFunc: func(ctx context.Context, model string, image []byte, format string, n int) ([]inference.SearchResult, error) {
+			gotN = n
+			return []inference.SearchResult{{ID: "shoes/1.jpg", Distance: 0.1}}, nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, newImageRequest("/inference/products/search?n=3", "shoe.jpg", []byte("image")))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status: %d %s", w.Code, w.Body.String())
+	}
+	if gotN != 3 {
+		t.Fatalf("Unexpected n: %d", gotN)
+	}
+
+	var res struct {
+		Results []inference.SearchResult `json:"results"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Results) != 1 || res.Results[0].ID != "shoes/1.jpg" {
+		t.Fatalf("Unexpected results: %+v", res.Results)
+	}
+
+	w = httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, newImageRequest("/inference/products/search?n=0", "shoe.jpg", []byte("image")))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Unexpected status for invalid n: %d", w.Code)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	var gotID string
+	m := &mock.Inference{
+		IndexImageFunc: func(ctx context.Context, model, id string, image []byte, format string) error {
+			gotID = id
+			return nil
+		},
+		RemoveIndexedImageFunc: func(ctx context.Context, model, id string) error {
+			return fmt.Errorf("%w: %s", inference.ErrIndexItemNotFound, id)
+		},
+	}
+
+	w := httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, newImageRequest("/inference/products/index?id=shoe-1", "shoe.jpg", []byte("image")))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status: %d %s", w.Code, w.Body.String())
+	}
+	if gotID != "shoe-1" {
+		t.Fatalf("Unexpected id: %q", gotID)
+	}
+
+	// 데이터셋 이미지는 이미지 데이터 관리가 있어야 색인 할 수 있음
+	w = httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/inference/products/index?subject=shoes", nil))
+	if w.Code == http.StatusOK {
+		t.Fatalf("Unexpected status without data manager: %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/inference/products/index?id=shoe-2", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("Unexpected status: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestInferFormatMismatch(t *testing.T) {
+	var gotFormat string
+	m := &mock.Inference{
+		InferFunc: func(ctx context.Context, model string, image []byte, format string, k int, threshold float32) ([]inference.InferLabel, error) {
+			gotFormat = format
+			if format == "" {
+				return []inference.InferLabel{}, nil
+			}
+			return nil, &inference.FormatMismatchError{Declared: format, Detected: "png"}
+		},
+	}
+
+	// 확장자가 없는 파일은 형식을 지정하지 않고 추론
+	w := httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, newImageRequest("/inference/flowers", "roses", []byte("image")))
+
+	if w.Code != http.StatusOK || gotFormat != "" {
+		t.Fatalf("Unexpected status: %d, format %q", w.Code, gotFormat)
+	}
+
+	w = httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, newImageRequest("/inference/flowers", "roses.jpg", []byte("image")))
+
+	var res HTTPError
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusBadRequest || res.Code != CodeFormatMismatch ||
+		res.DeclaredFormat != "jpg" || res.DetectedFormat != "png" {
+		t.Fatalf("Unexpected response: %d %+v", w.Code, res)
+	}
+}
+
+func TestInferCorruptImage(t *testing.T) {
+	m := &mock.Inference{
+		InferFunc: func(ctx context.Context, model string, image []byte, format string, k int, threshold float32) ([]inference.InferLabel, error) {
+			return nil, &inference.CorruptImageError{Format: "png", Offset: 33, Reason: `chunk "IDAT" CRC mismatch`}
+		},
+	}
+
+	w := httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, newImageRequest("/inference/flowers", "roses.png", []byte("image")))
+
+	var res HTTPError
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusBadRequest || res.Code != CodeCorruptImage ||
+		res.Offset == nil || *res.Offset != 33 || res.Reason == "" {
+		t.Fatalf("Unexpected response: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestInferWithModel(t *testing.T) {
+	var (
+		gotModel  string
+		gotFormat string
+		gotK      int
+	)
+
+	m := &mock.Inference{
+		InferFunc: func(ctx context.Context, model string, image []byte, format string, k int, threshold float32) ([]inference.InferLabel, error) {
+			gotModel, gotFormat, gotK = model, format, k
+			return []inference.InferLabel{{Prob: 0.9, Label: "roses"}}, nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, newImageRequest("/inference/flowers?k=3", "roses.jpg", []byte("image")))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status: %d, %s", w.Code, w.Body.String())
+	}
+	if gotModel != "flowers" || gotFormat != "jpg" || gotK != 3 {
+		t.Fatalf("Unexpected arguments: %s, %s, %d", gotModel, gotFormat, gotK)
+	}
+
+	// 추론한 모델(mock은 기록하지 않음)과 소요 시간은 항상 반환
+	var res map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if served, ok := res["served"].([]interface{}); !ok || len(served) != 0 {
+		t.Fatalf("Unexpected served models: %s", w.Body.String())
+	}
+	if _, ok := res["elapsed(ms)"]; !ok {
+		t.Fatalf("Missing elapsed time: %s", w.Body.String())
+	}
+}
+
+func TestInferThreshold(t *testing.T) {
+	var gotThreshold float32
+
+	m := &mock.Inference{
+		InferFunc: func(ctx context.Context, model string, image []byte, format string, k int, threshold float32) ([]inference.InferLabel, error) {
+			gotThreshold = threshold
+			return []inference.InferLabel{{Prob: 0.7, Label: "dog"}}, nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, newImageRequest("/inference/pets?threshold=0.7", "dog.jpg", []byte("image")))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status: %d, %s", w.Code, w.Body.String())
+	}
+	if gotThreshold != 0.7 {
+		t.Fatalf("Unexpected threshold: %f", gotThreshold)
+	}
+
+	for _, threshold := range []string{"abc", "1.5", "-1", "0", "1", ""} {
+		w := httptest.NewRecorder()
+		newTestRouter(m).ServeHTTP(w, newImageRequest("/inference/pets?threshold="+threshold, "dog.jpg", []byte("image")))
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("Unexpected status for threshold %q: %d", threshold, w.Code)
+		}
+	}
+
+	if calls := m.Calls(); len(calls) != 1 {
+		t.Fatalf("Invalid threshold should not infer: %v", calls)
+	}
+}
+
+func TestInferError(t *testing.T) {
+	m := &mock.Inference{
+		InferFunc: func(ctx context.Context, model string, image []byte, format string, k int, threshold float32) ([]inference.InferLabel, error) {
+			return nil, errors.New("Not ready yet")
+		},
+	}
+
+	w := httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, newImageRequest("/inference", "roses.jpg", []byte("image")))
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Unexpected status: %d", w.Code)
+	}
+	if calls := m.Calls(); len(calls) != 1 || calls[0] != "Infer" {
+		t.Fatalf("Unexpected calls: %v", calls)
+	}
+}
+
+func TestInferStoredImageUnavailable(t *testing.T) {
+	m := &mock.Inference{}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/inference?subject=flowers&category=roses&filename=1a2b3c4d-roses.jpg", nil)
+	newTestRouter(m).ServeHTTP(w, req)
+
+	// 이미지 데이터 관리 없이 실행 중이면 저장 된 이미지로 추론할 수 없음
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Unexpected status: %d", w.Code)
+	}
+	if calls := m.Calls(); len(calls) != 0 {
+		t.Fatalf("Unexpected calls: %v", calls)
+	}
+}
+
+func TestInferErrorStatus(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{fmt.Errorf("%w: none", inference.ErrModelNotFound), http.StatusNotFound},
+		{fmt.Errorf("%w: flowers", inference.ErrModelNotReady), http.StatusServiceUnavailable},
+		{fmt.Errorf("%w: gif", inference.ErrUnsupportedFormat), http.StatusUnsupportedMediaType},
+		{fmt.Errorf("%w: unknown classification", inference.ErrInvalidConfig), http.StatusInternalServerError},
+		{fmt.Errorf("%w: alice", inference.ErrTenantNotFound), http.StatusNotFound},
+		{fmt.Errorf("%w: alice", inference.ErrDuplicateTenant), http.StatusConflict},
+		{fmt.Errorf("%w: alice", inference.ErrQuotaExceeded), http.StatusInsufficientStorage},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout},
+		{context.Canceled, statusClientClosedRequest},
+	}
+
+	for _, test := range tests {
+		err := test.err
+		m := &mock.Inference{
+			InferFunc: func(ctx context.Context, model string, image []byte, format string, k int, threshold float32) ([]inference.InferLabel, error) {
+				return nil, err
+			},
+		}
+
+		w := httptest.NewRecorder()
+		newTestRouter(m).ServeHTTP(w, newImageRequest("/inference/flowers", "roses.jpg", []byte("image")))
+
+		if w.Code != test.status {
+			t.Fatalf("Unexpected status for %s: %d", err, w.Code)
+		}
+	}
+}
+
+func TestCreateModelInvalidName(t *testing.T) {
+	m := &mock.Inference{
+		CreateModelFunc: func(ctx context.Context, newModel, subject, desc string, epochs int, trial bool) (*inference.CreateResult, error) {
+			return nil, inference.ValidateName(newModel)
+		},
+	}
+
+	w := httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/models/.hidden", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Unexpected status: %d", w.Code)
+	}
+}
+
+func TestForceDeleteModel(t *testing.T) {
+	var timeout time.Duration
+	m := &mock.Inference{
+		ForceDeleteModelFunc: func(ctx context.Context, model string, d time.Duration) error {
+			timeout = d
+			return fmt.Errorf("%w: %s", inference.ErrModelInUse, model)
+		},
+	}
+
+	w := httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/models/pets?force&timeout=5", nil))
+
+	if w.Code != http.StatusConflict || timeout != 5*time.Second {
+		t.Fatalf("Unexpected status: %d (%s)", w.Code, timeout)
+	}
+	if calls := m.Calls(); !reflect.DeepEqual(calls, []string{"ForceDeleteModel"}) {
+		t.Fatalf("Unexpected calls: %v", calls)
+	}
+
+	w = httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/models/pets?force&timeout=-1", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Invalid timeout should fail: %d", w.Code)
+	}
+}
+
+func TestParsePage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		query string
+		page  int
+		size  int
+		fail  bool
+	}{
+		{"", 1, constants.DefaultPageSize, false},
+		{"page=3&size=50", 3, 50, false},
+		{"page=0", 0, 0, true},
+		{"page=abc", 0, 0, true},
+		{"size=0", 0, 0, true},
+		{fmt.Sprintf("size=%d", constants.MaxPageSize+1), 0, 0, true},
+	}
+
+	for _, test := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/datasets/flowers/roses?"+test.query, nil)
+
+		page, size, err := parsePage(c)
+		if test.fail {
+			if err == nil {
+				t.Fatalf("Invalid query should fail: %s", test.query)
+			}
+			continue
+		}
+		if err != nil || page != test.page || size != test.size {
+			t.Fatalf("Unexpected page for %q: %d, %d, %v", test.query, page, size, err)
+		}
+	}
+}
+
+func TestParseDistance(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		query    string
+		distance int
+		fail     bool
+	}{
+		{"", constants.DefaultDuplicateDistance, false},
+		{"distance=0", 0, false},
+		{"distance=10", 10, false},
+		{"distance=-1", 0, true},
+		{"distance=abc", 0, true},
+		{fmt.Sprintf("distance=%d", constants.MaxDuplicateDistance+1), 0, true},
+	}
+
+	for _, test := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/reports/duplicates/flowers?"+test.query, nil)
+
+		distance, err := parseDistance(c)
+		if test.fail {
+			if err == nil {
+				t.Fatalf("Invalid query should fail: %s", test.query)
+			}
+			continue
+		}
+		if err != nil || distance != test.distance {
+			t.Fatalf("Unexpected distance for %q: %d, %v", test.query, distance, err)
+		}
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		query string
+		from  string
+		to    string
+		fail  bool
+	}{
+		{"from=2020-10-01&to=2020-10-31", "2020-10-01", "2020-10-31", false},
+		{"to=2020-10-31", "2020-10-02", "2020-10-31", false},
+		{"from=2020-10-31&to=2020-10-31", "2020-10-31", "2020-10-31", false},
+		{"from=2020-11-01&to=2020-10-31", "", "", true},
+		{"from=20201001", "", "", true},
+		{"to=yesterday", "", "", true},
+	}
+
+	for _, test := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/reports/usage?"+test.query, nil)
+
+		from, to, err := parseDateRange(c)
+		if test.fail {
+			if err == nil {
+				t.Fatalf("Invalid query should fail: %s", test.query)
+			}
+			continue
+		}
+		if err != nil || from.Format(dateLayout) != test.from || to.Format(dateLayout) != test.to {
+			t.Fatalf("Unexpected range for %q: %s, %s, %v", test.query, from, to, err)
+		}
+	}
+}
+
+func TestWriteUsageCSV(t *testing.T) {
+	rows := []usageRow{
+		{Usage: db.Usage{Model: "default", Inferences: 10, Trainings: 1}, Storage: 2048},
+		{Usage: db.Usage{Model: "pets@alice", Inferences: 3}},
+	}
+
+	var b bytes.Buffer
+	if err := writeUsageCSV(&b, db.UsageByModel, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "model,inferences,trainings,storage\ndefault,10,1,2048\npets@alice,3,0,0\n"
+	if b.String() != expected {
+		t.Fatalf("Unexpected csv:\n%s", b.String())
+	}
+
+	b.Reset()
+	if err := writeUsageCSV(&b, db.UsageByAPIKey, []usageRow{{Usage: db.Usage{APIKey: "anonymous", Inferences: 5}}}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "apiKey,inferences,trainings\nanonymous,5,0\n"; b.String() != expected {
+		t.Fatalf("Unexpected csv:\n%s", b.String())
+	}
+}
+
+func TestModelUsageRows(t *testing.T) {
+	m := &mock.Inference{
+		GetModelsFunc: func(ctx context.Context, filter inference.ModelFilter) []string {
+			return []string{"flowers", "default"}
+		},
+		GetModelFunc: func(ctx context.Context, model string, verbose bool) (*inference.ModelInfo, error) {
+			return &inference.ModelInfo{Model: model, Storage: int64(len(model))}, nil
+		},
+	}
+
+	rows := (&APIs{I: m}).modelUsageRows(context.Background(), []db.Usage{{Model: "default", Inferences: 7}})
+
+	// 사용량이 없는 모델도 저장 공간과 함께 포함
+	if len(rows) != 2 ||
+		rows[0].Model != "default" || rows[0].Inferences != 7 || rows[0].Storage != 7 ||
+		rows[1].Model != "flowers" || rows[1].Inferences != 0 || rows[1].Storage != 7 {
+		t.Fatalf("Unexpected rows: %+v", rows)
+	}
+}
+
+func TestReadUploadedImage(t *testing.T) {
+	var body bytes.Buffer
+
+	w := multipart.NewWriter(&body)
+	w.WriteField("comment", "roses")
+	fw, _ := w.CreateFormFile("image", "roses.png")
+	fw.Write([]byte("image"))
+	w.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/inference", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	image, fileName, err := readUploadedImage(req, 5)
+	if err != nil || string(image) != "image" || fileName != "roses.png" {
+		t.Fatalf("Unexpected upload: %q %s %v", image, fileName, err)
+	}
+
+	req = newImageRequest("/inference", "roses.png", []byte("image"))
+	if _, _, err := readUploadedImage(req, 4); !errors.Is(err, inference.ErrImageTooLarge) {
+		t.Fatalf("Unexpected error for too large image: %v", err)
+	}
+
+	req = newImageRequest("/inference", "roses.png", nil)
+	req.Header.Set("Content-Type", "image/png")
+	if _, _, err := readUploadedImage(req, 0); err == nil {
+		t.Fatal("Non-multipart request should fail")
+	}
+}
+
+func TestReadUploadedImages(t *testing.T) {
+	newRequest := func(images ...string) *http.Request {
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		for idx, image := range images {
+			fw, _ := w.CreateFormFile("image", fmt.Sprintf("%d.jpg", idx))
+			fw.Write([]byte(image))
+		}
+		w.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/inference/flowers/jobs", &body)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		return req
+	}
+
+	images, fileName, err := readUploadedImages(newRequest("roses", "tulip"), 2, 5)
+	if err != nil || len(images) != 2 || string(images[1]) != "tulip" || fileName != "0.jpg" {
+		t.Fatalf("Unexpected upload: %q %s %v", images, fileName, err)
+	}
+
+	if _, _, err := readUploadedImages(newRequest("roses", "tulips"), 2, 5); !errors.Is(err, inference.ErrImageTooLarge) {
+		t.Fatalf("Unexpected error for too large image: %v", err)
+	}
+	if _, _, err := readUploadedImages(newRequest("roses", "tulip", "daisy"), 2, 5); err == nil {
+		t.Fatal("Too many images should fail")
+	}
+}
+
+func TestInferJSON(t *testing.T) {
+	var (
+		gotModel, gotFormat string
+		gotImage            []byte
+		gotK                int
+	)
+	m := &mock.Inference{
+		InferFunc: func(ctx context.Context, model string, image []byte, format string, k int, threshold float32) ([]inference.InferLabel, error) {
+			gotModel, gotImage, gotFormat, gotK = model, image, format, k
+			return []inference.InferLabel{}, nil
+		},
+	}
+
+	body := `{"model": "flowers", "image": "data:image/png;base64,aW1hZ2U=", "k": 3}`
+	req := httptest.NewRequest(http.MethodPost, "/inference", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status: %d %s", w.Code, w.Body.String())
+	}
+	if gotModel != "flowers" || string(gotImage) != "image" || gotFormat != "png" || gotK != 3 {
+		t.Fatalf("Unexpected request: %s %q %s %d", gotModel, gotImage, gotFormat, gotK)
+	}
+
+	for _, body := range []string{
+		`{"image": "not base64!"}`,
+		`{"image": ""}`,
+		`{"image": "aW1hZ2U=", "threshold": 1.5}`,
+		`{"image": `,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/inference/flowers", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		newTestRouter(m).ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("Unexpected status for %s: %d", body, w.Code)
+		}
+	}
+}
+
+func TestDecodeBase64Image(t *testing.T) {
+	tests := []struct {
+		image  string
+		format string
+		err    bool
+	}{
+		{"aW1hZ2U=", "", false},
+		{"aW1hZ2U", "", false},
+		{"data:image/jpeg;base64,aW1hZ2U=", "jpeg", false},
+		{"data:image/jpeg,aW1hZ2U=", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		image, format, err := decodeBase64Image(tt.image)
+		if (err != nil) != tt.err || format != tt.format || (err == nil && string(image) != "image") {
+			t.Errorf("decodeBase64Image(%q) = %q, %q, %v", tt.image, image, format, err)
+		}
+	}
+}
+
+func TestInferTensor(t *testing.T) {
+	var (
+		gotModel string
+		gotData  []float32
+		gotShape []int
+	)
+	m := &mock.Inference{
+		InferTensorFunc: func(ctx context.Context, model string, data []float32, shape []int, k int, threshold float32) ([]inference.InferLabel, error) {
+			gotModel, gotData, gotShape = model, data, shape
+			if len(shape) != 3 {
+				return nil, fmt.Errorf("%w: shape %v", inference.ErrInvalidTensor, shape)
+			}
+			return []inference.InferLabel{}, nil
+		},
+	}
+
+	body := `{"shape": [1, 2, 1], "data": [-1, 0.5]}`
+	req := httptest.NewRequest(http.MethodPost, "/inference/flowers/tensor", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	newTestRouter(m).ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Unexpected status: %d %s", w.Code, w.Body.String())
+	}
+	if gotModel != "flowers" || len(gotData) != 2 || gotData[1] != 0.5 || len(gotShape) != 3 {
+		t.Fatalf("Unexpected request: %s %v %v", gotModel, gotData, gotShape)
+	}
+
+	for _, body := range []string{
+		`{"shape": [2], "data": [0, 0]}`,
+		`{"shape": [1, 2, 1], "data": ["a"]}`,
+		`{"shape": [1, 2, 1], "data": [0, 0], "threshold": 1.5}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/inference/flowers/tensor", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		newTestRouter(m).ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("Unexpected status for %s: %d", body, w.Code)
+		}
+	}
+}
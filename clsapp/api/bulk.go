@@ -0,0 +1,313 @@
+package api
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/constants"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/inference"
+)
+
+// mimeJSONLines 일괄 추론 결과 형식 (한 줄에 파일 하나의 json)
+const mimeJSONLines = "application/x-ndjson"
+
+// bulkFile 일괄 추론할 파일
+type bulkFile struct {
+	// ZIP 또는 디렉토리 안의 경로 (`/`로 구분)
+	name string
+	read func() ([]byte, error)
+}
+
+// bulkResult 일괄 추론의 파일별 결과 (JSON Lines의 한 줄)
+type bulkResult struct {
+	File      string      `json:"file"`
+	Format    string      `json:"format,omitempty"`
+	Bytes     int         `json:"bytes,omitempty"`
+	Inference interface{} `json:"inference,omitempty"`
+	Error     *HTTPError  `json:"error,omitempty"`
+	// 파일을 추론한 모델과 버전, 소요 시간
+	Served  []inference.ServedModel `json:"served,omitempty"`
+	Elapsed int64                   `json:"elapsed(ms)"`
+}
+
+// skipBulkFile 숨김 파일과 macOS ZIP의 리소스 포크(__MACOSX)는 추론하지 않음
+func skipBulkFile(name string) bool {
+	for _, elem := range strings.Split(name, "/") {
+		if strings.HasPrefix(elem, ".") || elem == "__MACOSX" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readAllLimited r을 최대 max byte까지 읽음
+func readAllLimited(r io.Reader, max int64) ([]byte, error) {
+	b, err := ioutil.ReadAll(io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > max {
+		return nil, fmt.Errorf("%w: more than %d MB", errFileTooLarge, max>>20)
+	}
+
+	return b, nil
+}
+
+// zipFiles ZIP 파일의 이미지 목록 (ZIP에 저장된 순서)
+func zipFiles(zr *zip.Reader) ([]bulkFile, error) {
+	maxSize := int64(constants.MaxBulkImageMB) << 20
+
+	var files []bulkFile
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || skipBulkFile(f.Name) {
+			continue
+		}
+		if len(files) == constants.MaxBulkFiles {
+			return nil, fmt.Errorf("Too many files: more than %d", constants.MaxBulkFiles)
+		}
+
+		f := f
+		files = append(files, bulkFile{
+			name: f.Name,
+			read: func() ([]byte, error) {
+				// 압축을 풀기 전에 크기를 확인하고, 실제 크기가 다르면 zip 패키지가 에러를 반환
+				if f.UncompressedSize64 > uint64(maxSize) {
+					return nil, fmt.Errorf("%w: more than %d MB", errFileTooLarge, constants.MaxBulkImageMB)
+				}
+
+				rc, err := f.Open()
+				if err != nil {
+					return nil, err
+				}
+				defer rc.Close()
+
+				return readAllLimited(rc, maxSize)
+			},
+		})
+	}
+
+	return files, nil
+}
+
+// dirFiles 서버 디렉토리의 이미지 목록 (하위 디렉토리 포함, 경로 순서)
+func dirFiles(dir string) ([]bulkFile, error) {
+	maxSize := int64(constants.MaxBulkImageMB) << 20
+
+	var files []bulkFile
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if p != dir && skipBulkFile(name) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if len(files) == constants.MaxBulkFiles {
+			return fmt.Errorf("Too many files: more than %d", constants.MaxBulkFiles)
+		}
+
+		files = append(files, bulkFile{
+			name: name,
+			read: func() ([]byte, error) {
+				f, err := os.Open(p)
+				if err != nil {
+					return nil, err
+				}
+				defer f.Close()
+
+				return readAllLimited(f, maxSize)
+			},
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// bulkDir dir querystring을 a.BulkRoot 안의 디렉토리 경로로 변환
+func (a *APIs) bulkDir(dir string) (string, error) {
+	if a.BulkRoot == "" {
+		return "", fmt.Errorf("%w: server directory is disabled", errDirForbidden)
+	}
+
+	root := filepath.Clean(a.BulkRoot)
+	p := filepath.Join(root, filepath.FromSlash(dir))
+	if p != root && !strings.HasPrefix(p, root+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", errDirForbidden, dir)
+	}
+
+	if info, err := os.Stat(p); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("No such directory: %s", dir)
+	}
+
+	return p, nil
+}
+
+// bulkArchive multipart 요청의 archive ZIP 파일을 임시 파일로 저장
+//
+// 반환 된 함수로 임시 파일을 닫고 삭제
+func bulkArchive(req *http.Request) (*zip.Reader, func(), error) {
+	reader, err := req.MultipartReader()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, nil, http.ErrMissingFile
+		} else if err != nil {
+			return nil, nil, err
+		}
+
+		if part.FormName() != "archive" || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+		defer part.Close()
+
+		tmp, err := ioutil.TempFile("", "bulk-*.zip")
+		if err != nil {
+			return nil, nil, err
+		}
+		cleanup := func() {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+
+		maxSize := int64(constants.MaxBulkArchiveMB) << 20
+		n, err := io.Copy(tmp, io.LimitReader(part, maxSize+1))
+		if err == nil && n > maxSize {
+			err = fmt.Errorf("%w: more than %d MB", errFileTooLarge, constants.MaxBulkArchiveMB)
+		}
+		if err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+
+		zr, err := zip.NewReader(tmp, n)
+		if err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("Invalid ZIP archive: %s", err)
+		}
+
+		return zr, cleanup, nil
+	}
+}
+
+// InferBulk ZIP 파일(archive) 또는 서버 디렉토리(dir)의 이미지를 하나씩 추론하여 파일별 결과를 JSON Lines로 전송
+//
+// 파일별 추론 에러는 해당 줄의 error로 전달하며, 요청이 취소되면 남은 파일은 추론하지 않음
+func (a *APIs) InferBulk(c *gin.Context) {
+	model := c.Param("model")
+	ctx := c.Request.Context()
+
+	k, threshold, err := inferParams(c)
+	if err != nil {
+		Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	// 모든 줄에 같은 에러를 보내지 않도록 모델을 먼저 확인
+	if _, err := a.I.GetModel(ctx, model, false); err != nil {
+		Error(c, errorStatus(err, http.StatusBadRequest), err)
+		return
+	}
+
+	var files []bulkFile
+	if dir := c.Query("dir"); dir != "" {
+		p, err := a.bulkDir(dir)
+		if err != nil {
+			Error(c, errorStatus(err, http.StatusBadRequest), err)
+			return
+		}
+		if files, err = dirFiles(p); err != nil {
+			Error(c, errorStatus(err, http.StatusBadRequest), err)
+			return
+		}
+	} else {
+		zr, cleanup, err := bulkArchive(c.Request)
+		if err != nil {
+			Error(c, errorStatus(err, http.StatusBadRequest), err)
+			return
+		}
+		defer cleanup()
+
+		if files, err = zipFiles(zr); err != nil {
+			Error(c, errorStatus(err, http.StatusBadRequest), err)
+			return
+		}
+	}
+
+	lang := messageLanguage(c)
+	format := c.Query("format")
+
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", mimeJSONLines)
+	enc := json.NewEncoder(c.Writer)
+
+	var inferred int
+	for _, f := range files {
+		if ctx.Err() != nil {
+			break
+		}
+
+		result := bulkResult{File: f.name}
+		t0 := time.Now()
+
+		image, err := f.read()
+		if err == nil {
+			result.Format = format
+			if result.Format == "" {
+				result.Format = strings.ToLower(strings.TrimPrefix(path.Ext(f.name), "."))
+			}
+			result.Bytes = len(image)
+
+			served := &inference.Serving{}
+			result.Inference, err = a.I.Infer(inference.ContextWithServing(ctx, served), model, image, result.Format, k, threshold)
+			result.Served = served.Models()
+		}
+		result.Elapsed = time.Since(t0).Milliseconds()
+		if err != nil {
+			httpErr := newHTTPError(lang, errorStatus(err, http.StatusBadRequest), err)
+			result.Inference = nil
+			result.Error = &httpErr
+		} else {
+			inferred++
+		}
+
+		if err := enc.Encode(result); err != nil {
+			break
+		}
+		c.Writer.Flush()
+	}
+
+	if inferred > 0 {
+		a.recordInference(c, model, inferred)
+	}
+}
@@ -0,0 +1,429 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/data"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/inference"
+)
+
+// 에러 코드
+//
+// 클라이언트가 에러를 구분할 수 있도록 응답의 `code`로 전달되며, 한번 정한 값은 바꾸지 않음
+const (
+	CodeInvalidRequest       = "INVALID_REQUEST"
+	CodeNotFound             = "NOT_FOUND"
+	CodeInternal             = "INTERNAL_ERROR"
+	CodeModelNotFound        = "MODEL_NOT_FOUND"
+	CodeModelNotReady        = "MODEL_NOT_READY"
+	CodeModelInUse           = "MODEL_IN_USE"
+	CodeModelDisabled        = "MODEL_DISABLED"
+	CodeDuplicateModel       = "DUPLICATE_MODEL"
+	CodeUnsupportedFormat    = "UNSUPPORTED_FORMAT"
+	CodeFormatMismatch       = "FORMAT_MISMATCH"
+	CodeInvalidConfig        = "INVALID_MODEL_CONFIG"
+	CodeInvalidName          = "INVALID_NAME"
+	CodeInvalidModelPath     = "INVALID_MODEL_PATH"
+	CodeInvalidArchive       = "INVALID_MODEL_ARCHIVE"
+	CodeInvalidUpdate        = "INVALID_MODEL_UPDATE"
+	CodeChecksumMismatch     = "MODEL_CHECKSUM_MISMATCH"
+	CodeBackendUnavailable   = "MODEL_BACKEND_UNAVAILABLE"
+	CodeTenantNotFound       = "TENANT_NOT_FOUND"
+	CodeAliasNotFound        = "ALIAS_NOT_FOUND"
+	CodeDuplicateTenant      = "DUPLICATE_TENANT"
+	CodeQuotaExceeded        = "QUOTA_EXCEEDED"
+	CodeMemoryExceeded       = "MEMORY_BUDGET_EXCEEDED"
+	CodePermissionDenied     = "PERMISSION_DENIED"
+	CodeImageNotFound        = "IMAGE_NOT_FOUND"
+	CodeInvalidThumbnailSize = "INVALID_THUMBNAIL_SIZE"
+	CodeFetchForbidden       = "FETCH_FORBIDDEN"
+	CodeFetchFailed          = "FETCH_FAILED"
+	CodeImageTooLarge        = "IMAGE_TOO_LARGE"
+	CodeInvalidTensor        = "INVALID_TENSOR"
+	CodeCorruptImage         = "CORRUPT_IMAGE"
+	CodeImageConversion      = "IMAGE_CONVERSION_FAILED"
+	CodeModelTypeMismatch    = "MODEL_TYPE_MISMATCH"
+	CodeIndexItemNotFound    = "INDEX_ITEM_NOT_FOUND"
+	CodePipelineNotFound     = "PIPELINE_NOT_FOUND"
+	CodeJobNotFound          = "JOB_NOT_FOUND"
+	CodeJobQueueFull         = "JOB_QUEUE_FULL"
+	CodeFileTooLarge         = "FILE_TOO_LARGE"
+	CodeDirectoryForbidden   = "DIRECTORY_FORBIDDEN"
+	CodeTimeout              = "TIMEOUT"
+	CodeCanceled             = "CANCELED"
+)
+
+// errStoredImageUnavailable 이미지 데이터 관리 없이 실행 중이라 저장 된 이미지를 사용할 수 없음
+var errStoredImageUnavailable = errors.New("Stored images are not available")
+
+// URL 이미지 가져오기 에러
+var (
+	// errFetchForbidden 내부 네트워크 주소로의 요청
+	errFetchForbidden = errors.New("URL host is forbidden")
+	// errFetchFailed 연결 실패 또는 200이 아닌 응답
+	errFetchFailed = errors.New("Fail to fetch image")
+	// errFetchTimeout 제한 시간 안에 이미지를 받지 못함
+	errFetchTimeout = errors.New("Fetching image timed out")
+)
+
+// 비동기 추론 작업 에러
+var (
+	// errJobNotFound 없거나 보관 기간이 지나 제거 된 작업
+	errJobNotFound = errors.New("No such job")
+	// errJobQueueFull 보관 중인 작업 수 초과
+	errJobQueueFull = errors.New("Too many jobs")
+)
+
+// 일괄 추론 에러
+var (
+	// errFileTooLarge 최대 크기를 넘는 ZIP 파일 또는 ZIP, 디렉토리 안의 파일
+	errFileTooLarge = errors.New("File is too large")
+	// errDirForbidden 일괄 추론 경로(APIs.BulkRoot) 밖이거나 서버 디렉토리 추론을 허용하지 않음
+	errDirForbidden = errors.New("Directory is forbidden")
+)
+
+// statusClientClosedRequest 클라이언트가 응답 전에 요청을 취소한 경우의 상태 코드 (nginx 관례)
+const statusClientClosedRequest = 499
+
+// errorKinds 에러별 HTTP 상태 코드와 에러 코드 (위에서부터 errors.Is로 비교)
+var errorKinds = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{inference.ErrModelNotFound, http.StatusNotFound, CodeModelNotFound},
+	{inference.ErrTenantNotFound, http.StatusNotFound, CodeTenantNotFound},
+	{inference.ErrAliasNotFound, http.StatusNotFound, CodeAliasNotFound},
+	{inference.ErrModelNotReady, http.StatusServiceUnavailable, CodeModelNotReady},
+	{inference.ErrModelInUse, http.StatusConflict, CodeModelInUse},
+	{inference.ErrDuplicateModel, http.StatusConflict, CodeDuplicateModel},
+	{inference.ErrDuplicateTenant, http.StatusConflict, CodeDuplicateTenant},
+	{inference.ErrQuotaExceeded, http.StatusInsufficientStorage, CodeQuotaExceeded},
+	{inference.ErrMemoryBudgetExceeded, http.StatusServiceUnavailable, CodeMemoryExceeded},
+	{inference.ErrModelDisabled, http.StatusServiceUnavailable, CodeModelDisabled},
+	{inference.ErrBackendUnavailable, http.StatusServiceUnavailable, CodeBackendUnavailable},
+	{inference.ErrPermissionDenied, http.StatusForbidden, CodePermissionDenied},
+	{inference.ErrUnsupportedFormat, http.StatusUnsupportedMediaType, CodeUnsupportedFormat},
+	{inference.ErrFormatMismatch, http.StatusBadRequest, CodeFormatMismatch},
+	{inference.ErrImageTooLarge, http.StatusRequestEntityTooLarge, CodeImageTooLarge},
+	{inference.ErrInvalidTensor, http.StatusBadRequest, CodeInvalidTensor},
+	{inference.ErrCorruptImage, http.StatusBadRequest, CodeCorruptImage},
+	{inference.ErrImageConversion, http.StatusUnprocessableEntity, CodeImageConversion},
+	{inference.ErrModelTypeMismatch, http.StatusBadRequest, CodeModelTypeMismatch},
+	{inference.ErrIndexItemNotFound, http.StatusNotFound, CodeIndexItemNotFound},
+	{inference.ErrPipelineNotFound, http.StatusNotFound, CodePipelineNotFound},
+	{inference.ErrInvalidConfig, http.StatusInternalServerError, CodeInvalidConfig},
+	{inference.ErrChecksumMismatch, http.StatusInternalServerError, CodeChecksumMismatch},
+	{inference.ErrInvalidName, http.StatusBadRequest, CodeInvalidName},
+	{inference.ErrInvalidModelPath, http.StatusBadRequest, CodeInvalidModelPath},
+	{inference.ErrInvalidArchive, http.StatusBadRequest, CodeInvalidArchive},
+	{inference.ErrInvalidUpdate, http.StatusBadRequest, CodeInvalidUpdate},
+	{data.ErrImageNotFound, http.StatusNotFound, CodeImageNotFound},
+	{data.ErrInvalidThumbnailSize, http.StatusBadRequest, CodeInvalidThumbnailSize},
+	{errJobNotFound, http.StatusNotFound, CodeJobNotFound},
+	{errJobQueueFull, http.StatusServiceUnavailable, CodeJobQueueFull},
+	{errFileTooLarge, http.StatusRequestEntityTooLarge, CodeFileTooLarge},
+	{errDirForbidden, http.StatusForbidden, CodeDirectoryForbidden},
+	{errFetchForbidden, http.StatusForbidden, CodeFetchForbidden},
+	{errFetchFailed, http.StatusBadGateway, CodeFetchFailed},
+	{errFetchTimeout, http.StatusGatewayTimeout, CodeTimeout},
+	{context.DeadlineExceeded, http.StatusGatewayTimeout, CodeTimeout},
+	{context.Canceled, statusClientClosedRequest, CodeCanceled},
+}
+
+// errorStatus 에러에 해당하는 HTTP 상태 코드 반환
+// 해당하지 않는 에러는 status를 반환
+func errorStatus(err error, status int) int {
+	for _, kind := range errorKinds {
+		if errors.Is(err, kind.err) {
+			return kind.status
+		}
+	}
+
+	return status
+}
+
+// errorCode 에러에 해당하는 에러 코드 반환
+// 해당하지 않는 에러는 HTTP 상태 코드로 구분
+func errorCode(err error, status int) string {
+	for _, kind := range errorKinds {
+		if errors.Is(err, kind.err) {
+			return kind.code
+		}
+	}
+
+	switch {
+	case status == http.StatusNotFound:
+		return CodeNotFound
+	case status >= 400 && status < 500:
+		return CodeInvalidRequest
+	}
+
+	return CodeInternal
+}
+
+// 지원하는 메시지 언어
+var messageLanguages = []string{"ko", "en"}
+
+// messages 에러 코드별 사용자에게 보여줄 메시지
+var messages = map[string]map[string]string{
+	CodeInvalidRequest: {
+		"ko": "요청이 올바르지 않습니다.",
+		"en": "The request is invalid.",
+	},
+	CodeNotFound: {
+		"ko": "요청한 항목을 찾을 수 없습니다.",
+		"en": "The requested resource was not found.",
+	},
+	CodeInternal: {
+		"ko": "서버에서 요청을 처리하지 못했습니다.",
+		"en": "The server failed to process the request.",
+	},
+	CodeModelNotFound: {
+		"ko": "모델을 찾을 수 없습니다.",
+		"en": "The model was not found.",
+	},
+	CodeModelNotReady: {
+		"ko": "모델이 아직 준비되지 않았습니다. 잠시 후 다시 시도해 주세요.",
+		"en": "The model is not ready yet. Please try again later.",
+	},
+	CodeModelInUse: {
+		"ko": "모델이 사용 중입니다.",
+		"en": "The model is currently in use.",
+	},
+	CodeModelDisabled: {
+		"ko": "추론이 중지된 모델입니다.",
+		"en": "The model is disabled.",
+	},
+	CodeDuplicateModel: {
+		"ko": "같은 이름의 모델이 이미 있습니다.",
+		"en": "A model with the same name already exists.",
+	},
+	CodeUnsupportedFormat: {
+		"ko": "지원하지 않는 이미지 형식입니다.",
+		"en": "The image format is not supported.",
+	},
+	CodeFormatMismatch: {
+		"ko": "이미지 형식이 파일 내용과 다릅니다.",
+		"en": "The image format does not match the file content.",
+	},
+	CodeInvalidConfig: {
+		"ko": "모델 설정이 올바르지 않습니다.",
+		"en": "The model configuration is invalid.",
+	},
+	CodeInvalidName: {
+		"ko": "사용할 수 없는 이름입니다.",
+		"en": "The name is not allowed.",
+	},
+	CodeInvalidModelPath: {
+		"ko": "모델 경로가 올바르지 않습니다.",
+		"en": "The model path is invalid.",
+	},
+	CodeInvalidArchive: {
+		"ko": "모델 파일이 올바르지 않습니다.",
+		"en": "The model archive is invalid.",
+	},
+	CodeInvalidUpdate: {
+		"ko": "모델 설정 값이 올바르지 않습니다.",
+		"en": "The model setting is invalid.",
+	},
+	CodeChecksumMismatch: {
+		"ko": "모델 파일이 손상 되었거나 변경 되었습니다.",
+		"en": "The model files are corrupted or modified.",
+	},
+	CodeBackendUnavailable: {
+		"ko": "모델을 실행하는 추론 서버에 연결할 수 없습니다.",
+		"en": "The inference server running the model is unavailable.",
+	},
+	CodeTenantNotFound: {
+		"ko": "사용자를 찾을 수 없습니다.",
+		"en": "The tenant was not found.",
+	},
+	CodeDuplicateTenant: {
+		"ko": "같은 이름의 사용자가 이미 있습니다.",
+		"en": "A tenant with the same name already exists.",
+	},
+	CodeAliasNotFound: {
+		"ko": "모델 별칭을 찾을 수 없습니다.",
+		"en": "The model alias was not found.",
+	},
+	CodeQuotaExceeded: {
+		"ko": "모델 저장 공간이 부족합니다.",
+		"en": "The model storage quota has been exceeded.",
+	},
+	CodeMemoryExceeded: {
+		"ko": "모델을 로드할 메모리가 부족합니다.",
+		"en": "Not enough memory budget to load the model.",
+	},
+	CodePermissionDenied: {
+		"ko": "요청에 대한 권한이 없습니다.",
+		"en": "You do not have permission for the request.",
+	},
+	CodeImageNotFound: {
+		"ko": "이미지를 찾을 수 없습니다.",
+		"en": "The image was not found.",
+	},
+	CodeInvalidThumbnailSize: {
+		"ko": "지원하지 않는 썸네일 크기입니다.",
+		"en": "The thumbnail size is not supported.",
+	},
+	CodeFetchForbidden: {
+		"ko": "이미지 URL의 주소에 접근할 수 없습니다.",
+		"en": "The image URL host is not allowed.",
+	},
+	CodeFetchFailed: {
+		"ko": "이미지 URL에서 이미지를 가져오지 못했습니다.",
+		"en": "Failed to fetch the image from the URL.",
+	},
+	CodeImageTooLarge: {
+		"ko": "이미지 크기가 너무 큽니다.",
+		"en": "The image is too large.",
+	},
+	CodeInvalidTensor: {
+		"ko": "입력 tensor가 모델 입력과 맞지 않습니다.",
+		"en": "The input tensor does not match the model input.",
+	},
+	CodeCorruptImage: {
+		"ko": "이미지 파일이 손상되었습니다.",
+		"en": "The image file is corrupt.",
+	},
+	CodeImageConversion: {
+		"ko": "이미지를 변환하지 못했습니다.",
+		"en": "Failed to convert the image.",
+	},
+	CodeModelTypeMismatch: {
+		"ko": "모델이 지원하지 않는 추론입니다.",
+		"en": "The model does not support this inference.",
+	},
+	CodeIndexItemNotFound: {
+		"ko": "검색 색인에 없는 이미지입니다.",
+		"en": "The image is not in the search index.",
+	},
+	CodePipelineNotFound: {
+		"ko": "존재하지 않는 pipeline입니다.",
+		"en": "The pipeline does not exist.",
+	},
+	CodeJobNotFound: {
+		"ko": "존재하지 않거나 보관 기간이 지난 작업입니다.",
+		"en": "The job does not exist or has expired.",
+	},
+	CodeJobQueueFull: {
+		"ko": "등록된 작업이 너무 많습니다. 잠시 후 다시 시도해 주세요.",
+		"en": "Too many jobs are queued. Please try again later.",
+	},
+	CodeFileTooLarge: {
+		"ko": "파일이 너무 큽니다.",
+		"en": "The file is too large.",
+	},
+	CodeDirectoryForbidden: {
+		"ko": "접근할 수 없는 디렉토리입니다.",
+		"en": "The directory is not accessible.",
+	},
+	CodeTimeout: {
+		"ko": "요청 처리 시간이 초과되었습니다.",
+		"en": "The request timed out.",
+	},
+	CodeCanceled: {
+		"ko": "요청이 취소되었습니다.",
+		"en": "The request was canceled.",
+	},
+}
+
+// requestLanguages 요청의 `lang` querystring과 Accept-Language 헤더의 언어 태그를 선호 순서로 반환
+func requestLanguages(c *gin.Context) []string {
+	var tags []string
+	if lang := c.Query("lang"); lang != "" {
+		tags = append(tags, lang)
+	}
+	// 예: ko-KR,ko;q=0.9,en;q=0.8 (선호 순서로 나열된 것으로 간주)
+	for _, tag := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		if tag = strings.TrimSpace(strings.SplitN(tag, ";", 2)[0]); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// messageLanguage 요청의 `lang` querystring 또는 Accept-Language 헤더에서 지원하는 언어 반환
+//
+// 지원하는 언어가 없으면 빈 값을 반환하며, 응답에 메시지를 포함하지 않음
+func messageLanguage(c *gin.Context) string {
+	for _, tag := range requestLanguages(c) {
+		primary := strings.ToLower(strings.TrimSpace(strings.SplitN(tag, "-", 2)[0]))
+		for _, lang := range messageLanguages {
+			if primary == lang {
+				return lang
+			}
+		}
+	}
+
+	return ""
+}
+
+// HTTPError api 에러 메시지
+type HTTPError struct {
+	// 에러 코드 (Code 상수)
+	Code string `json:"code"`
+	// 요청한 언어의 사용자 메시지
+	Message string `json:"message,omitempty"`
+	// 개발자를 위한 상세 에러
+	Error string `json:"error"`
+	// 모델 config 검사에서 발견 된 위반 사항
+	Violations []string `json:"violations,omitempty"`
+	// 요청한 이미지 형식과 이미지 내용으로 판단한 형식 (FORMAT_MISMATCH인 경우)
+	DeclaredFormat string `json:"declaredFormat,omitempty"`
+	DetectedFormat string `json:"detectedFormat,omitempty"`
+	// 손상 된 이미지에서 문제가 발견 된 위치(byte)와 원인 (CORRUPT_IMAGE인 경우, 위치를 알 수 없으면 offset 없음)
+	Offset *int64 `json:"offset,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	// checksum이 다르거나 없어진 모델 파일 (MODEL_CHECKSUM_MISMATCH인 경우)
+	File string `json:"file,omitempty"`
+}
+
+// Error api 에러를 담은 json 응답 생성
+func Error(c *gin.Context, status int, err error) {
+	c.JSON(status, newHTTPError(messageLanguage(c), status, err))
+}
+
+// newHTTPError 에러의 코드와 상세 정보, lang의 메시지(lang이 빈 값이면 제외)를 담은 HTTPError 생성
+func newHTTPError(lang string, status int, err error) HTTPError {
+	httpErr := HTTPError{
+		Code:  errorCode(err, status),
+		Error: err.Error(),
+	}
+
+	if lang != "" {
+		httpErr.Message = messages[httpErr.Code][lang]
+	}
+
+	var cfgErr *inference.ConfigError
+	if errors.As(err, &cfgErr) {
+		httpErr.Violations = cfgErr.Violations
+	}
+
+	var formatErr *inference.FormatMismatchError
+	if errors.As(err, &formatErr) {
+		httpErr.DeclaredFormat = formatErr.Declared
+		httpErr.DetectedFormat = formatErr.Detected
+	}
+
+	var corruptErr *inference.CorruptImageError
+	if errors.As(err, &corruptErr) {
+		if corruptErr.Offset >= 0 {
+			httpErr.Offset = &corruptErr.Offset
+		}
+		httpErr.Reason = corruptErr.Reason
+	}
+
+	var checksumErr *inference.ChecksumError
+	if errors.As(err, &checksumErr) {
+		httpErr.File = checksumErr.File
+	}
+
+	return httpErr
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/inference"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/inference/mock"
+)
+
+func TestErrorResponse(t *testing.T) {
+	m := &mock.Inference{
+		GetModelFunc: func(ctx context.Context, model string, verbose bool) (*inference.ModelInfo, error) {
+			return nil, fmt.Errorf("%w: %s", inference.ErrModelNotFound, model)
+		},
+	}
+
+	tests := []struct {
+		url            string
+		acceptLanguage string
+		message        string
+	}{
+		{"/models/none", "", ""},
+		{"/models/none", "ko-KR,ko;q=0.9,en;q=0.8", messages[CodeModelNotFound]["ko"]},
+		{"/models/none", "fr-FR,en;q=0.5", messages[CodeModelNotFound]["en"]},
+		{"/models/none?lang=en", "ko-KR", messages[CodeModelNotFound]["en"]},
+		{"/models/none", "fr-FR", ""},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, test.url, nil)
+		if test.acceptLanguage != "" {
+			req.Header.Set("Accept-Language", test.acceptLanguage)
+		}
+
+		w := httptest.NewRecorder()
+		newTestRouter(m).ServeHTTP(w, req)
+
+		var res HTTPError
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatal(err)
+		}
+		if res.Code != CodeModelNotFound || res.Message != test.message || res.Error == "" {
+			t.Fatalf("Unexpected error for %q, %q: %+v", test.url, test.acceptLanguage, res)
+		}
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{fmt.Errorf("%w: none", inference.ErrModelNotFound), http.StatusInternalServerError, CodeModelNotFound},
+		{&inference.ConfigError{File: "config.yaml"}, http.StatusInternalServerError, CodeInvalidConfig},
+		{fmt.Errorf("Fail to reload: %w", &inference.ChecksumError{File: "saved_model.pb"}), http.StatusInternalServerError, CodeChecksumMismatch},
+		{fmt.Errorf("Fail to infer: %w", inference.ErrBackendUnavailable), http.StatusInternalServerError, CodeBackendUnavailable},
+		{context.Canceled, http.StatusInternalServerError, CodeCanceled},
+		{fmt.Errorf("%w: infer flowers", inference.ErrPermissionDenied), http.StatusInternalServerError, CodePermissionDenied},
+		{fmt.Errorf("Image 1: %w", &inference.ImageTooLargeError{Bytes: 1 << 30}), http.StatusBadRequest, CodeImageTooLarge},
+		{errors.New("Invalid `page`"), http.StatusBadRequest, CodeInvalidRequest},
+		{errors.New("no route"), http.StatusNotFound, CodeNotFound},
+		{errors.New("db closed"), http.StatusInternalServerError, CodeInternal},
+	}
+
+	for _, test := range tests {
+		if code := errorCode(test.err, test.status); code != test.code {
+			t.Errorf("errorCode(%v, %d) = %s, want %s", test.err, test.status, code, test.code)
+		}
+	}
+}
+
+func TestMessages(t *testing.T) {
+	codes := []string{CodeInvalidRequest, CodeNotFound, CodeInternal}
+	for _, kind := range errorKinds {
+		codes = append(codes, kind.code)
+	}
+
+	// 모든 에러 코드는 지원하는 모든 언어의 메시지가 있어야 함
+	for _, code := range codes {
+		for _, lang := range messageLanguages {
+			if messages[code][lang] == "" {
+				t.Errorf("No %s message for %s", lang, code)
+			}
+		}
+	}
+}
+
+func TestMessageLanguage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		query          string
+		acceptLanguage string
+		lang           string
+	}{
+		{"", "", ""},
+		{"", "ko", "ko"},
+		{"", "EN-us", "en"},
+		{"", "ja-JP, ko;q=0.7", "ko"},
+		{"lang=ko", "en", "ko"},
+		{"lang=fr", "en", "en"},
+	}
+
+	for _, test := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/models?"+test.query, nil)
+		c.Request.Header.Set("Accept-Language", test.acceptLanguage)
+
+		if lang := messageLanguage(c); lang != test.lang {
+			t.Errorf("messageLanguage(%q, %q) = %q, want %q", test.query, test.acceptLanguage, lang, test.lang)
+		}
+	}
+}
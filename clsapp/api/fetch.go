@@ -0,0 +1,188 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/inference"
+)
+
+// URL 이미지 가져오기 기본값
+const (
+	defaultFetchMaxSize   = 10 << 20
+	defaultFetchTimeout   = 10 * time.Second
+	defaultFetchRedirects = 3
+)
+
+// 내부 네트워크 주소 (loopback, link-local은 net.IP 메소드로 확인)
+var privateNetworks = func() []*net.IPNet {
+	var nets []*net.IPNet
+	for _, cidr := range []string{
+		"10.0.0.0/8",
+		"172.16.0.0/12",
+		"192.168.0.0/16",
+		"100.64.0.0/10",
+		"fc00::/7",
+	} {
+		_, n, _ := net.ParseCIDR(cidr)
+		nets = append(nets, n)
+	}
+	return nets
+}()
+
+// ImageFetcher 추론할 이미지를 URL에서 가져옴
+//
+// 0인 값은 기본값을 사용
+type ImageFetcher struct {
+	// 이미지 최대 크기 (byte)
+	MaxSize int64
+	// 요청부터 이미지를 모두 받기까지의 최대 시간
+	Timeout time.Duration
+	// 내부 네트워크(loopback, private, link-local) 주소 허용
+	AllowPrivate bool
+
+	once   sync.Once
+	client *http.Client
+}
+
+func (f *ImageFetcher) maxSize() int64 {
+	if f.MaxSize <= 0 {
+		return defaultFetchMaxSize
+	}
+
+	return f.MaxSize
+}
+
+func (f *ImageFetcher) httpClient() *http.Client {
+	f.once.Do(f.initClient)
+	return f.client
+}
+
+func (f *ImageFetcher) initClient() {
+	timeout := f.Timeout
+	if timeout <= 0 {
+		timeout = defaultFetchTimeout
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	if !f.AllowPrivate {
+		// redirect를 포함한 모든 연결에서 실제 접속하는 주소를 확인
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isPrivateIP(ip) {
+				return fmt.Errorf("%w: %s", errFetchForbidden, host)
+			}
+			return nil
+		}
+	}
+
+	f.client = &http.Client{
+		Timeout: timeout,
+		// proxy를 사용하면 접속 주소를 확인할 수 없으므로 직접 연결
+		Transport: &http.Transport{
+			DialContext: dialer.DialContext,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= defaultFetchRedirects {
+				return fmt.Errorf("Too many redirects: %d", len(via))
+			}
+			return nil
+		},
+	}
+}
+
+func isPrivateIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return true
+	}
+
+	for _, n := range privateNetworks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Fetch URL의 이미지와 이미지 형식 반환
+//
+// http, https URL만 허용하며, Content-Type이 image/*가 아니면 ErrUnsupportedFormat으로 실패.
+// 서버가 알려주는 형식(image/x-ms-bmp 등)은 일정하지 않으므로 형식은 이미지 내용으로 판단하고,
+// 판단할 수 없으면 Content-Type의 형식을 사용
+func (f *ImageFetcher) Fetch(ctx context.Context, rawURL string) ([]byte, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, "", fmt.Errorf("Invalid `url`: %q (http or https)", rawURL)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "image/*")
+
+	resp, err := f.httpClient().Do(req)
+	if err != nil {
+		return nil, "", fetchError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("%w: %s", errFetchFailed, resp.Status)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !strings.HasPrefix(mediaType, "image/") {
+		return nil, "", fmt.Errorf("%w: Content-Type %q", inference.ErrUnsupportedFormat, mediaType)
+	}
+
+	maxSize := f.maxSize()
+	if resp.ContentLength > maxSize {
+		return nil, "", &inference.ImageTooLargeError{Bytes: resp.ContentLength, Limits: inference.ImageLimits{MaxBytes: maxSize}}
+	}
+
+	image, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, "", fetchError(err)
+	}
+	if int64(len(image)) > maxSize {
+		return nil, "", &inference.ImageTooLargeError{Bytes: int64(len(image)), Limits: inference.ImageLimits{MaxBytes: maxSize}}
+	}
+
+	format := inference.DetectFormat(image)
+	if format == "" {
+		format = strings.TrimPrefix(mediaType, "image/")
+	}
+
+	return image, format, nil
+}
+
+// fetchError 이미지를 가져오지 못한 원인에 맞는 에러로 변환
+func fetchError(err error) error {
+	if errors.Is(err, errFetchForbidden) || errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Errorf("%w: %s", errFetchTimeout, err)
+	}
+
+	return fmt.Errorf("%w: %s", errFetchFailed, err)
+}
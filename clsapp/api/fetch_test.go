@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/inference"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/inference/mock"
+)
+
+// 형식 판단에 필요한 PNG signature
+const pngImage = "\x89PNG\r\n\x1a\nimage"
+
+func newImageServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/roses.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte(pngImage))
+	})
+	mux.HandleFunc("/roses.bmp", func(w http.ResponseWriter, r *http.Request) {
+		// 이미지 내용으로 판단할 수 없으면 Content-Type의 형식을 사용
+		w.Header().Set("Content-Type", "image/x-ms-bmp")
+		w.Write([]byte("image"))
+	})
+	mux.HandleFunc("/large.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte(pngImage + strings.Repeat("x", 64)))
+	})
+	mux.HandleFunc("/page.html", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html></html>"))
+	})
+	mux.HandleFunc("/slow.png", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte(pngImage))
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/roses.png", http.StatusFound)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestImageFetcher(t *testing.T) {
+	server := newImageServer()
+	defer server.Close()
+
+	f := &ImageFetcher{
+		MaxSize:      32,
+		Timeout:      100 * time.Millisecond,
+		AllowPrivate: true,
+	}
+
+	tests := []struct {
+		url    string
+		format string
+		err    error
+	}{
+		{server.URL + "/roses.png", "png", nil},
+		{server.URL + "/redirect", "png", nil},
+		{server.URL + "/roses.bmp", "x-ms-bmp", nil},
+		{server.URL + "/large.png", "", inference.ErrImageTooLarge},
+		{server.URL + "/page.html", "", inference.ErrUnsupportedFormat},
+		{server.URL + "/missing.png", "", errFetchFailed},
+		{server.URL + "/slow.png", "", errFetchTimeout},
+	}
+
+	for _, tt := range tests {
+		_, format, err := f.Fetch(context.Background(), tt.url)
+		if format != tt.format || !errors.Is(err, tt.err) {
+			t.Errorf("Fetch(%s) = %q, %v", tt.url, format, err)
+		}
+	}
+
+	for _, rawURL := range []string{"file:///etc/passwd", "ftp://example.com/a.png", "roses.png"} {
+		if _, _, err := f.Fetch(context.Background(), rawURL); err == nil {
+			t.Errorf("Fetch(%s) must fail", rawURL)
+		}
+	}
+}
+
+func TestImageFetcherForbidden(t *testing.T) {
+	server := newImageServer()
+	defer server.Close()
+
+	_, _, err := (&ImageFetcher{}).Fetch(context.Background(), server.URL+"/roses.png")
+	if !errors.Is(err, errFetchForbidden) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if errorStatus(err, http.StatusBadRequest) != http.StatusForbidden {
+		t.Fatalf("Unexpected status: %d", errorStatus(err, http.StatusBadRequest))
+	}
+
+	for _, addr := range []string{"127.0.0.1", "10.1.2.3", "172.20.0.1", "192.168.0.1", "169.254.169.254", "::1", "fd00::1"} {
+		if !isPrivateIP(net.ParseIP(addr)) {
+			t.Errorf("%s must be private", addr)
+		}
+	}
+	for _, addr := range []string{"8.8.8.8", "172.32.0.1", "2001:4860:4860::8888"} {
+		if isPrivateIP(net.ParseIP(addr)) {
+			t.Errorf("%s must not be private", addr)
+		}
+	}
+}
+
+func TestInferURL(t *testing.T) {
+	server := newImageServer()
+	defer server.Close()
+
+	var (
+		gotImage  []byte
+		gotFormat string
+	)
+	m := &mock.Inference{
+		InferFunc: func(ctx context.Context, model string, image []byte, format string, k int, threshold float32) ([]inference.InferLabel, error) {
+			gotImage, gotFormat = image, format
+			return []inference.InferLabel{}, nil
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := NewRouter(&APIs{I: m, F: &ImageFetcher{AllowPrivate: true}})
+
+	req := httptest.NewRequest(http.MethodPost, "/inference/flowers?url="+server.URL+"/roses.png", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || string(gotImage) != pngImage || gotFormat != "png" {
+		t.Fatalf("Unexpected response: %d %s (%q)", w.Code, w.Body.String(), gotFormat)
+	}
+
+	body := `{"url": "` + server.URL + `/page.html"}`
+	req = httptest.NewRequest(http.MethodPost, "/inference/flowers", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("Unexpected status: %d %s", w.Code, w.Body.String())
+	}
+}
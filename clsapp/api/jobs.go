@@ -0,0 +1,442 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/constants"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/inference"
+)
+
+// 비동기 추론 작업 종류
+const (
+	jobInfer        = "infer"
+	jobDetection    = "detection"
+	jobSegmentation = "segmentation"
+)
+
+// 비동기 추론 작업 상태
+const (
+	jobPending   = "pending"
+	jobRunning   = "running"
+	jobSucceeded = "succeeded"
+	jobFailed    = "failed"
+)
+
+// Job 비동기 추론 작업
+type Job struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Model  string `json:"model"`
+	Status string `json:"status"`
+	// 추론할 이미지 수
+	Images     int        `json:"images"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	// 성공한 작업의 결과 (infer는 이미지별 추론 결과, detection은 검출 결과, segmentation은 mask)
+	Result interface{} `json:"result,omitempty"`
+	// 실패한 작업의 에러
+	Error *HTTPError `json:"error,omitempty"`
+	// 작업을 처리한 모델과 버전, 실행 시간 (대기 시간 제외)
+	Served  []inference.ServedModel `json:"served,omitempty"`
+	Elapsed int64                   `json:"elapsed(ms)"`
+
+	cancel context.CancelFunc
+}
+
+// jobStore 메모리에 보관하는 비동기 추론 작업
+//
+// 끝난 작업은 constants.JobRetentionMinutes 동안 보관
+type jobStore struct {
+	mutex sync.Mutex
+	jobs  map[string]*Job
+	// 동시에 실행하는 작업 수 제한
+	slots chan struct{}
+}
+
+func newJobStore() *jobStore {
+	return &jobStore{
+		jobs:  make(map[string]*Job),
+		slots: make(chan struct{}, constants.MaxRunningJobs),
+	}
+}
+
+// add 보관 기간이 지난 작업을 정리하고 작업 추가
+func (s *jobStore) add(job *Job) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	expired := time.Now().Add(-time.Duration(constants.JobRetentionMinutes) * time.Minute)
+	for id, j := range s.jobs {
+		if j.FinishedAt != nil && j.FinishedAt.Before(expired) {
+			delete(s.jobs, id)
+		}
+	}
+
+	if len(s.jobs) >= constants.MaxJobs {
+		return fmt.Errorf("%w: %d", errJobQueueFull, constants.MaxJobs)
+	}
+	s.jobs[job.ID] = job
+
+	return nil
+}
+
+// get 작업의 현재 상태 복사본 반환
+func (s *jobStore) get(id string) (Job, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+
+	return *job, true
+}
+
+// remove 작업을 취소하고 제거
+func (s *jobStore) remove(id string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return false
+	}
+	job.cancel()
+	delete(s.jobs, id)
+
+	return true
+}
+
+func (s *jobStore) update(job *Job, f func(job *Job)) Job {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	f(job)
+
+	return *job
+}
+
+// detachedContext 요청의 값(권한 확인 정보, 언어 등)은 유지하고, 요청의 취소와 deadline은 따르지 않는 ctx
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+// jobRequest 작업 실행에 필요한 요청 값 (작업은 요청이 끝난 후 실행되므로 gin.Context를 사용하지 않음)
+type jobRequest struct {
+	images    [][]byte
+	format    string
+	k         int
+	threshold float32
+	encoding  string
+	callback  string
+	lang      string
+	apiKey    string
+}
+
+// readUploadedImages multipart 요청의 모든 image 파일과 첫 번째 파일 이름 반환 (각 파일은 최대 maxSize byte)
+func readUploadedImages(req *http.Request, max int, maxSize int64) ([][]byte, string, error) {
+	reader, err := req.MultipartReader()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var (
+		images   [][]byte
+		fileName string
+	)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, "", err
+		}
+
+		if part.FormName() != "image" || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+		if len(images) == max {
+			part.Close()
+			return nil, "", fmt.Errorf("Too many images: more than %d", max)
+		}
+
+		image, err := readImagePart(part, maxSize)
+		part.Close()
+		if err != nil {
+			return nil, "", err
+		}
+		if fileName == "" {
+			fileName = part.FileName()
+		}
+		images = append(images, image)
+	}
+
+	if len(images) == 0 {
+		return nil, "", http.ErrMissingFile
+	}
+
+	return images, fileName, nil
+}
+
+// jobImages 작업으로 추론할 이미지와 형식 반환
+//
+// url 또는 저장 된 이미지는 하나, multipart 요청은 여러 image 파일을 batch로 추론
+func (a *APIs) jobImages(c *gin.Context) ([][]byte, string, error) {
+	if c.Query("url") != "" || c.Query("filename") != "" {
+		image, _, format, err := a.inferImage(c)
+		if err != nil {
+			return nil, "", err
+		}
+		return [][]byte{image}, format, nil
+	}
+
+	images, fileName, err := readUploadedImages(c.Request, constants.MaxJobImages, a.MaxImageSize)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// batch 이미지는 하나의 형식으로 추론하므로, 지정하지 않은 여러 이미지는 이미지 내용으로 판단
+	format := c.Query("format")
+	if format == "" && len(images) == 1 {
+		format = strings.TrimPrefix(filepath.Ext(fileName), ".")
+	}
+
+	return images, format, nil
+}
+
+// parseCallback 작업이 끝나면 결과를 POST 할 URL 검사
+func parseCallback(callback string) error {
+	if callback == "" {
+		return nil
+	}
+
+	u, err := url.Parse(callback)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("Invalid `callback`: %q (http or https URL)", callback)
+	}
+
+	return nil
+}
+
+func (a *APIs) jobStore() *jobStore {
+	a.jobsOnce.Do(func() {
+		a.jobs = newJobStore()
+	})
+
+	return a.jobs
+}
+
+// SubmitJob 이미지(또는 여러 이미지)의 추론 작업을 등록하고 작업 id를 바로 반환
+//
+// 결과는 GET /jobs/:id로 확인하거나, `callback`을 지정하면 작업이 끝난 후 작업 json을 POST로 받음
+func (a *APIs) SubmitJob(c *gin.Context) {
+	model := c.Param("model")
+
+	jobType := c.DefaultQuery("type", jobInfer)
+	switch jobType {
+	case jobInfer, jobDetection, jobSegmentation:
+	default:
+		Error(c, http.StatusBadRequest, fmt.Errorf("Invalid `type`: %q (%s, %s or %s)", jobType, jobInfer, jobDetection, jobSegmentation))
+		return
+	}
+
+	k, threshold, err := inferParams(c)
+	if err != nil {
+		Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	callback := c.Query("callback")
+	if err := parseCallback(callback); err != nil {
+		Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	images, format, err := a.jobImages(c)
+	if err != nil {
+		Error(c, errorStatus(err, http.StatusBadRequest), err)
+		return
+	}
+	if jobType != jobInfer && len(images) > 1 {
+		Error(c, http.StatusBadRequest, fmt.Errorf("%s job must have one image", jobType))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(detachedContext{c.Request.Context()}, time.Duration(constants.JobTimeoutMinutes)*time.Minute)
+	job := &Job{
+		ID:        uuid.New().String(),
+		Type:      jobType,
+		Model:     model,
+		Status:    jobPending,
+		Images:    len(images),
+		CreatedAt: time.Now(),
+		cancel:    cancel,
+	}
+	if err := a.jobStore().add(job); err != nil {
+		cancel()
+		Error(c, errorStatus(err, http.StatusServiceUnavailable), err)
+		return
+	}
+
+	go a.runJob(ctx, job, jobRequest{
+		images:    images,
+		format:    format,
+		k:         k,
+		threshold: threshold,
+		encoding:  c.Query("encoding"),
+		callback:  callback,
+		lang:      messageLanguage(c),
+		apiKey:    c.GetHeader(apiKeyHeader),
+	})
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"id":     job.ID,
+		"status": job.Status,
+	})
+}
+
+// runJob 실행 가능한 작업 수 안에서 작업을 실행하고 결과를 기록
+func (a *APIs) runJob(ctx context.Context, job *Job, req jobRequest) {
+	s := a.jobStore()
+	defer job.cancel()
+
+	var (
+		result  interface{}
+		err     error
+		elapsed time.Duration
+		served  = &inference.Serving{}
+	)
+	select {
+	case s.slots <- struct{}{}:
+		s.update(job, func(job *Job) {
+			job.Status = jobRunning
+		})
+		t0 := time.Now()
+		result, err = a.execJob(inference.ContextWithServing(ctx, served), job, req)
+		elapsed = time.Since(t0)
+		<-s.slots
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err == nil && a.M != nil {
+		a.M.RecordInference(job.Model, req.apiKey, len(req.images))
+	}
+
+	finished := s.update(job, func(job *Job) {
+		now := time.Now()
+		job.FinishedAt = &now
+		job.Elapsed = elapsed.Milliseconds()
+		if models := served.Models(); len(models) > 0 {
+			job.Served = models
+		}
+		if err != nil {
+			httpErr := newHTTPError(req.lang, errorStatus(err, http.StatusBadRequest), err)
+			job.Status = jobFailed
+			job.Error = &httpErr
+			return
+		}
+		job.Status = jobSucceeded
+		job.Result = result
+	})
+
+	if req.callback != "" {
+		a.postCallback(req.callback, finished)
+	}
+}
+
+// execJob 작업 종류에 따라 추론
+func (a *APIs) execJob(ctx context.Context, job *Job, req jobRequest) (interface{}, error) {
+	switch job.Type {
+	case jobDetection:
+		return a.I.Detect(ctx, job.Model, req.images[0], req.format, req.k, req.threshold)
+	case jobSegmentation:
+		return a.I.Segment(ctx, job.Model, req.images[0], req.format, req.threshold, req.encoding)
+	}
+
+	if len(req.images) == 1 {
+		infers, err := a.I.Infer(ctx, job.Model, req.images[0], req.format, req.k, req.threshold)
+		if err != nil {
+			return nil, err
+		}
+		return [][]inference.InferLabel{infers}, nil
+	}
+
+	return a.I.InferBatch(ctx, job.Model, req.images, req.format, req.k, req.threshold)
+}
+
+// postCallback 끝난 작업의 json을 callback URL로 POST
+//
+// URL 이미지와 같은 ImageFetcher의 client를 사용하므로 내부 네트워크 주소 제한과 제한 시간이 적용 됨
+func (a *APIs) postCallback(callback string, job Job) {
+	b, err := json.Marshal(job)
+	if err != nil {
+		log.Printf("Fail to encode job %s: %s", job.ID, err)
+		return
+	}
+
+	res, err := a.fetcher().httpClient().Post(callback, gin.MIMEJSON, bytes.NewReader(b))
+	if err != nil {
+		log.Printf("Fail to post job %s to callback: %s", job.ID, err)
+		return
+	}
+	res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+		log.Printf("Fail to post job %s to callback: %s", job.ID, res.Status)
+	}
+}
+
+// ShowJob 비동기 추론 작업의 상태와 결과 반환
+func (a *APIs) ShowJob(c *gin.Context) {
+	id := c.Param("id")
+
+	job, ok := a.jobStore().get(id)
+	if !ok {
+		Error(c, http.StatusNotFound, fmt.Errorf("%w: %s", errJobNotFound, id))
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// DeleteJob 비동기 추론 작업을 취소하고 제거
+func (a *APIs) DeleteJob(c *gin.Context) {
+	id := c.Param("id")
+
+	if !a.jobStore().remove(id) {
+		Error(c, http.StatusNotFound, fmt.Errorf("%w: %s", errJobNotFound, id))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id": id,
+	})
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/data/db"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/inference"
+)
+
+const (
+	dateLayout = "2006-01-02"
+
+	// from을 지정하지 않은 경우 to를 포함한 조회 기간 (일)
+	defaultReportDays = 30
+)
+
+// usageRow 사용량 리포트 항목
+type usageRow struct {
+	db.Usage
+	// 모델 파일 크기 (byte, 모델별 리포트에만 포함)
+	Storage int64 `json:"storage,omitempty"`
+}
+
+// parseDateRange from, to 날짜(YYYY-MM-DD) 반환
+//
+// to의 기본값은 오늘이며, from의 기본값은 to를 포함한 최근 30일의 시작일
+func parseDateRange(c *gin.Context) (time.Time, time.Time, error) {
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if s, ok := c.GetQuery("to"); ok {
+		v, err := time.ParseInLocation(dateLayout, s, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("Invalid `to`: %q (%s)", s, dateLayout)
+		}
+		to = v
+	}
+
+	from := to.AddDate(0, 0, 1-defaultReportDays)
+	if s, ok := c.GetQuery("from"); ok {
+		v, err := time.ParseInLocation(dateLayout, s, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("Invalid `from`: %q (%s)", s, dateLayout)
+		}
+		from = v
+	}
+
+	if from.After(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("`from`(%s) is after `to`(%s)", from.Format(dateLayout), to.Format(dateLayout))
+	}
+
+	return from, to, nil
+}
+
+// modelUsageRows 모델별 사용량에 모델 파일 크기를 추가
+//
+// 사용량이 없는 모델도 저장 공간 확인을 위해 포함
+func (a *APIs) modelUsageRows(ctx context.Context, usages []db.Usage) []usageRow {
+	rows := make(map[string]*usageRow)
+	for _, u := range usages {
+		rows[u.Model] = &usageRow{Usage: u}
+	}
+
+	for _, model := range a.I.GetModels(ctx, inference.ModelFilter{}) {
+		// 버전별 모델은 같은 모델 디렉토리를 사용하므로 모델 파일 크기는 모델 이름에만 포함
+		if strings.Contains(model, inference.VersionSeparator) {
+			continue
+		}
+
+		row, ok := rows[model]
+		if !ok {
+			row = &usageRow{Usage: db.Usage{Model: model}}
+			rows[model] = row
+		}
+
+		info, err := a.I.GetModel(ctx, model, false)
+		if err != nil {
+			continue
+		}
+		row.Storage = info.Storage
+	}
+
+	result := make([]usageRow, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, *row)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Model < result[j].Model
+	})
+
+	return result
+}
+
+// writeUsageCSV 사용량 리포트를 CSV로 작성
+func writeUsageCSV(w io.Writer, by string, rows []usageRow) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{"apiKey", "inferences", "trainings"}
+	if by == db.UsageByModel {
+		header = []string{"model", "inferences", "trainings", "storage"}
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		record := []string{
+			row.APIKey,
+			strconv.FormatInt(row.Inferences, 10),
+			strconv.FormatInt(row.Trainings, 10),
+		}
+		if by == db.UsageByModel {
+			record[0] = row.Model
+			record = append(record, strconv.FormatInt(row.Storage, 10))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/inference"
+)
+
+// labelLanguages 요청의 언어를 추론 ctx에 지정하여, 모델에 언어별 labels가 있으면 결과의 label을 바꿈
+func labelLanguages(c *gin.Context) {
+	if languages := requestLanguages(c); len(languages) > 0 {
+		c.Request = c.Request.WithContext(inference.ContextWithLanguages(c.Request.Context(), languages...))
+	}
+}
+
+// excludedLabels 요청의 exclude querystring(`,`로 구분)
+func excludedLabels(c *gin.Context) []string {
+	var labels []string
+	for _, exclude := range c.QueryArray("exclude") {
+		labels = append(labels, strings.Split(exclude, ",")...)
+	}
+
+	return labels
+}
+
+// excludeLabels 요청에서 제외할 클래스를 추론 ctx에 지정하여, 결과와 상위 k개에서 제외
+func excludeLabels(c *gin.Context) {
+	if labels := excludedLabels(c); len(labels) > 0 {
+		c.Request = c.Request.WithContext(inference.ContextWithExcludedLabels(c.Request.Context(), labels...))
+	}
+}
+
+// modelVersion version querystring이 있으면 경로의 모델 이름을 `<모델>/<버전>`으로 바꿔 해당 버전을 사용
+func modelVersion(c *gin.Context) {
+	version := c.Query("version")
+	if version == "" {
+		return
+	}
+
+	for idx, param := range c.Params {
+		if param.Key == "model" {
+			c.Params[idx].Value = param.Value + inference.VersionSeparator + version
+		}
+	}
+}
+
+// servingKey 요청을 처리한 모델을 기록하는 *inference.Serving의 gin.Context key
+const servingKey = "serving"
+
+// servingModels 추론 ctx에 요청을 처리한 모델을 기록하도록 지정
+func servingModels(c *gin.Context) {
+	s := &inference.Serving{}
+	c.Set(servingKey, s)
+	c.Request = c.Request.WithContext(inference.ContextWithServing(c.Request.Context(), s))
+}
+
+// servedModels 요청을 처리한 모델과 버전 (A/B 비교 등에서 결과를 낸 모델을 구분)
+func servedModels(c *gin.Context) []inference.ServedModel {
+	if s, ok := c.Get(servingKey); ok {
+		return s.(*inference.Serving).Models()
+	}
+
+	return []inference.ServedModel{}
+}
+
+// NewRouter api 핸들러를 등록한 router 생성
+//
+// 이미지, 데이터셋과 리포트 API는 a.M이 지정된 경우에만 등록 됨
+func NewRouter(a *APIs) *gin.Engine {
+	r := gin.Default()
+	r.MaxMultipartMemory = 8 << 20
+
+	inferenceGroup := r.Group("/inference", modelVersion, labelLanguages, excludeLabels, servingModels)
+	{
+		inferenceGroup.POST("", a.InferDefault)
+		inferenceGroup.POST(":model", a.InferWithModel)
+		inferenceGroup.POST(":model/tensor", a.InferTensor)
+		inferenceGroup.POST(":model/detection", a.Detect)
+		inferenceGroup.POST(":model/segmentation", a.Segment)
+		inferenceGroup.POST(":model/explain", a.Explain)
+		inferenceGroup.POST(":model/search", a.Search)
+		inferenceGroup.POST(":model/index", a.IndexImages)
+		inferenceGroup.DELETE(":model/index", a.DeleteIndex)
+		inferenceGroup.POST(":model/jobs", a.SubmitJob)
+		inferenceGroup.POST(":model/bulk", a.InferBulk)
+		inferenceGroup.POST(":model/video", a.InferVideo)
+	}
+
+	jobsGroup := r.Group("/jobs")
+	{
+		jobsGroup.GET(":id", a.ShowJob)
+		jobsGroup.DELETE(":id", a.DeleteJob)
+	}
+
+	r.POST("/compare", labelLanguages, servingModels, a.Compare)
+	r.POST("/infer-all", labelLanguages, servingModels, a.InferAll)
+	r.POST("/pipelines/:pipeline", labelLanguages, servingModels, a.InferPipeline)
+
+	modelsGroup := r.Group("/models")
+	{
+		modelsGroup.GET("", a.ListModels)
+		modelsGroup.GET(":model", modelVersion, a.ShowModel)
+		modelsGroup.GET(":model/graph", modelVersion, a.ShowModelGraph)
+		modelsGroup.POST(":model", a.CreateModel)
+		modelsGroup.POST(":model/versions", a.CreateVersion)
+		modelsGroup.POST(":model/import", a.ImportModel)
+		modelsGroup.GET(":model/export", modelVersion, a.ExportModel)
+		modelsGroup.POST(":model/clone", modelVersion, a.CloneModel)
+		modelsGroup.PUT(":model", a.OperateModel)
+		modelsGroup.PATCH(":model", modelVersion, a.UpdateModel)
+		modelsGroup.DELETE(":model", modelVersion, a.DeleteModel)
+		modelsGroup.POST(":model/reload", modelVersion, a.ReloadModel)
+		modelsGroup.POST(":model/rollback", modelVersion, a.RollbackModel)
+		modelsGroup.POST(":model/disable", a.DisableModel)
+		modelsGroup.POST(":model/enable", a.EnableModel)
+	}
+
+	r.GET("/cache", a.ShowCache)
+
+	tenantsGroup := r.Group("/tenants")
+	{
+		tenantsGroup.GET("", a.ListTenants)
+		tenantsGroup.GET(":tenant", a.ShowTenant)
+		tenantsGroup.POST(":tenant", a.CreateTenant)
+		tenantsGroup.DELETE(":tenant", a.DeleteTenant)
+	}
+
+	aliasesGroup := r.Group("/aliases")
+	{
+		aliasesGroup.GET("", a.ListAliases)
+		aliasesGroup.PUT(":alias", a.SetAlias)
+		aliasesGroup.DELETE(":alias", a.DeleteAlias)
+	}
+
+	if a.M != nil {
+		imagesGroup := r.Group("/images")
+		{
+			imagesGroup.GET("", a.ListImages)
+			imagesGroup.POST("", a.UploadImages)
+			imagesGroup.DELETE("", a.DeleteImages)
+		}
+
+		datasetsGroup := r.Group("/datasets")
+		{
+			datasetsGroup.GET(":subject", a.ListCategories)
+			datasetsGroup.GET(":subject/:category", a.BrowseImages)
+			datasetsGroup.GET(":subject/:category/:filename", a.ShowImage)
+		}
+
+		reportsGroup := r.Group("/reports")
+		{
+			reportsGroup.GET("duplicates/:subject", a.ReportDuplicates)
+			reportsGroup.GET("usage", a.ReportUsage)
+		}
+	}
+
+	return r
+}
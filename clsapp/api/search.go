@@ -0,0 +1,179 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/constants"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/inference"
+)
+
+// abortIndexing 이미지가 아닌 모델 또는 요청의 문제로, 나머지 이미지도 실패하는 에러
+func abortIndexing(err error) bool {
+	for _, target := range []error{
+		inference.ErrModelNotFound,
+		inference.ErrModelNotReady,
+		inference.ErrModelTypeMismatch,
+		inference.ErrPermissionDenied,
+		context.Canceled,
+		context.DeadlineExceeded,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// indexFailure 색인하지 못한 데이터셋 이미지
+type indexFailure struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+func parseSearchResults(c *gin.Context) (int, error) {
+	s, ok := c.GetQuery("n")
+	if !ok {
+		return constants.DefaultSearchResults, nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > constants.MaxSearchResults {
+		return 0, fmt.Errorf("Invalid `n`: %q (1 ~ %d)", s, constants.MaxSearchResults)
+	}
+
+	return n, nil
+}
+
+// Search 모델의 검색 색인에서 이미지와 가까운 순서로 n개의 이미지 반환
+func (a *APIs) Search(c *gin.Context) {
+	model := c.Param("model")
+
+	n, err := parseSearchResults(c)
+	if err != nil {
+		Error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	image, fileName, format, err := a.inferImage(c)
+	if err != nil {
+		Error(c, errorStatus(err, http.StatusBadRequest), err)
+		return
+	}
+
+	t0 := time.Now()
+	results, err := a.I.Search(c.Request.Context(), model, image, format, n)
+	if err != nil {
+		Error(c, errorStatus(err, http.StatusBadRequest), err)
+		return
+	}
+	elapsed := time.Since(t0)
+	a.recordInference(c, model, 1)
+
+	c.JSON(http.StatusOK, gin.H{
+		"file":        fileName,
+		"format":      format,
+		"bytes":       len(image),
+		"results":     results,
+		"served":      servedModels(c),
+		"elapsed(ms)": elapsed.Milliseconds(),
+	})
+}
+
+// IndexImages 모델의 검색 색인에 이미지 추가
+//
+// `id`를 지정하면 요청의 이미지 하나를, `subject`(와 `category`)를 지정하면 데이터셋의 이미지를
+// `<subject>/<category>/<filename>` id로 색인
+func (a *APIs) IndexImages(c *gin.Context) {
+	model := c.Param("model")
+	subject := c.Query("subject")
+	id := c.Query("id")
+
+	if id != "" {
+		image, _, format, err := a.inferImage(c)
+		if err != nil {
+			Error(c, errorStatus(err, http.StatusBadRequest), err)
+			return
+		}
+
+		if err := a.I.IndexImage(c.Request.Context(), model, id, image, format); err != nil {
+			Error(c, errorStatus(err, http.StatusBadRequest), err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"model":   model,
+			"indexed": 1,
+			"failed":  []indexFailure{},
+		})
+		return
+	}
+
+	if subject == "" {
+		Error(c, http.StatusBadRequest, errors.New("Empty `id` or `subject`"))
+		return
+	}
+	if a.M == nil {
+		Error(c, errorStatus(errStoredImageUnavailable, http.StatusBadRequest), errStoredImageUnavailable)
+		return
+	}
+
+	items, err := a.M.Images(subject, c.Query("category"))
+	if err != nil {
+		Error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	var (
+		indexed int
+		failed  = []indexFailure{}
+	)
+	for _, item := range items {
+		itemID := fmt.Sprintf("%s/%s/%s", item.Subject, item.Category, item.Filename)
+		image, format, err := a.M.ReadImage(item.Subject, item.Category, item.Filename)
+		if err == nil {
+			err = a.I.IndexImage(c.Request.Context(), model, itemID, image, format)
+		}
+		if err != nil {
+			if abortIndexing(err) {
+				Error(c, errorStatus(err, http.StatusInternalServerError), err)
+				return
+			}
+			failed = append(failed, indexFailure{ID: itemID, Error: err.Error()})
+			continue
+		}
+		indexed++
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"model":   model,
+		"indexed": indexed,
+		"failed":  failed,
+	})
+}
+
+// DeleteIndex 모델의 검색 색인에서 `id`의 이미지를 제거 (지정하지 않으면 모든 이미지)
+func (a *APIs) DeleteIndex(c *gin.Context) {
+	model := c.Param("model")
+
+	var err error
+	if id := c.Query("id"); id != "" {
+		err = a.I.RemoveIndexedImage(c.Request.Context(), model, id)
+	} else {
+		err = a.I.ClearIndex(c.Request.Context(), model)
+	}
+	if err != nil {
+		Error(c, errorStatus(err, http.StatusBadRequest), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"model": model,
+	})
+}
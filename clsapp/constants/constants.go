@@ -3,9 +3,43 @@ package constants
 const (
 	DefaultModelName string = "default"
 
-	ModelsPath string = "/cls/models"
-	ImagesPath string = "/cls/images"
+	ModelsPath        string = "/cls/models"
+	ImagesPath        string = "/cls/images"
+	FallbackModelPath string = "/cls/fallback"
 
 	DefaultMultiClassMax int = 5
 	TrainEpochs          int = 10
+
+	DefaultBinaryThreshold float32 = 0.5
+	DefaultUnknownLabel    string  = "unknown"
+
+	DefaultPageSize int = 20
+	MaxPageSize     int = 100
+
+	DefaultDuplicateDistance int = 5
+	MaxDuplicateDistance     int = 16
+
+	MaxCompareModels int = 5
+
+	DefaultSearchResults int = 10
+	MaxSearchResults     int = 100
+
+	MaxJobs             int = 1000
+	MaxRunningJobs      int = 4
+	MaxJobImages        int = 100
+	JobTimeoutMinutes   int = 30
+	JobRetentionMinutes int = 60
+
+	MaxBulkFiles     int = 10000
+	MaxBulkArchiveMB int = 1024
+	MaxBulkImageMB   int = 50
+
+	DefaultVideoFPS float64 = 1
+	MaxVideoFPS     float64 = 10
+	MaxVideoMB      int     = 100
+
+	MaxImportModelMB int = 2048
+
+	DefaultDrainSeconds int = 30
+	MaxDrainSeconds     int = 300
 )
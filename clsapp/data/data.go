@@ -1,12 +1,14 @@
 package data
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"mime/multipart"
 	"os"
-	"path"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -21,9 +23,21 @@ const (
 	connInfo   string = "user1:password1@tcp(db:3306)/cls_image_db?parseTime=true"
 )
 
+var (
+	// ErrImageNotFound 존재하지 않는 이미지
+	ErrImageNotFound = errors.New("No such image")
+	// ErrInvalidThumbnailSize 지원하지 않는 썸네일 크기
+	ErrInvalidThumbnailSize = errors.New("Invalid thumbnail size")
+)
+
 // Manager 이미지 데이터를 관리
 type Manager struct {
 	Conn *db.DBconn
+
+	// 생성 가능한 썸네일 크기 (기본값: DefaultThumbnailSizes)
+	ThumbnailSizes []int
+
+	usage usageRecorder
 }
 
 type saveFunc func(*multipart.FileHeader, string) error
@@ -48,7 +62,7 @@ func saveImage(file *multipart.FileHeader, dst string) error {
 
 // SaveImages image 저장
 func (dm *Manager) SaveImages(subject, category string, images []*multipart.FileHeader, f saveFunc, verbose bool) (interface{}, error) {
-	fileDir := path.Join(constants.ImagesPath, subject, category)
+	fileDir := filepath.Join(constants.ImagesPath, subject, category)
 	if err := os.MkdirAll(fileDir, os.ModePerm); err != nil {
 		return nil, err
 	}
@@ -70,7 +84,7 @@ func (dm *Manager) SaveImages(subject, category string, images []*multipart.File
 		orgFileName := image.Filename
 		fileName := fmt.Sprintf("%s-%s", uuid.New().String()[:8], orgFileName)
 		fileFormat := strings.ToLower(strings.Split(orgFileName, ".")[1])
-		filePath := path.Join(fileDir, fileName)
+		filePath := filepath.Join(fileDir, fileName)
 
 		item := db.Item{
 			Subject:     subject,
@@ -165,6 +179,7 @@ func (dm *Manager) DeleteImages(subject, category, fileName, orgFileName string,
 	// 빈 디렉토리를 삭제하기 위해, subject와 category 목록을 저장
 	scMap := make(map[string]map[string]int)
 	for _, item := range items.([]db.Item) {
+		removeThumbnails(item.Subject, item.Category, item.Filename)
 		if err := os.Remove(item.FilePath); err != nil {
 			if verbose {
 				errors = append(errors, map[string]interface{}{
@@ -192,12 +207,12 @@ func (dm *Manager) DeleteImages(subject, category, fileName, orgFileName string,
 
 	for subject := range scMap {
 		for category := range scMap[subject] {
-			categoryDir := path.Join(constants.ImagesPath, subject, category)
+			categoryDir := filepath.Join(constants.ImagesPath, subject, category)
 			// "directory not empty" 에러는 무시
 			os.Remove(categoryDir)
 		}
 
-		subjectDir := path.Join(constants.ImagesPath, subject)
+		subjectDir := filepath.Join(constants.ImagesPath, subject)
 		// "directory not empty" 에러는 무시
 		os.Remove(subjectDir)
 	}
@@ -242,8 +257,119 @@ func (dm *Manager) ListImages(subject, category string) (interface{}, error) {
 	return result, nil
 }
 
+// Images subject(category를 지정하면 category)의 저장 된 이미지 목록 반환
+func (dm *Manager) Images(subject, category string) ([]db.Item, error) {
+	_, items, err := dm.Conn.Get(db.Item{
+		Subject:  subject,
+		Category: category,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return items.([]db.Item), nil
+}
+
+// ListCategories subject의 카테고리별 이미지 수 반환
+func (dm *Manager) ListCategories(subject string) (interface{}, error) {
+	categories, err := dm.Conn.Categories(subject)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"subject":    subject,
+		"categories": categories,
+	}
+
+	return result, nil
+}
+
+// BrowseImages subject와 category의 이미지를 page 단위로 반환
+//
+// page는 1부터 시작하며, preview는 각 이미지의 미리보기 URL을 생성.
+// 썸네일 URL은 미리보기 URL에 가장 작은 썸네일 크기를 지정
+func (dm *Manager) BrowseImages(subject, category string, page, size int, preview func(db.Item) string) (interface{}, error) {
+	total, items, err := dm.Conn.Browse(subject, category, (page-1)*size, size)
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		image := map[string]interface{}{
+			"orgfilename": item.OrgFilename,
+			"filename":    item.Filename,
+			"format":      item.FileFormat,
+			"createAt":    item.CreateAt,
+			"source":      item.Source,
+			"trained":     item.Trained,
+		}
+		if preview != nil {
+			image["preview"] = preview(item)
+			image["thumbnail"] = fmt.Sprintf("%s?thumbnail=%d", preview(item), dm.thumbnailSizes()[0])
+		}
+		images = append(images, image)
+	}
+
+	result := map[string]interface{}{
+		"subject":  subject,
+		"category": category,
+		"page":     page,
+		"size":     size,
+		"total":    total,
+		"images":   images,
+	}
+
+	return result, nil
+}
+
+// ImageFile 저장 된 이미지의 파일 경로 반환
+//
+// DB에 등록 된 이미지만 반환하므로 요청 값이 파일 경로로 직접 사용되지 않음
+func (dm *Manager) ImageFile(subject, category, fileName string) (string, error) {
+	_, items, err := dm.Conn.Get(db.Item{
+		Subject:  subject,
+		Category: category,
+		Filename: fileName,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	found := items.([]db.Item)
+	if len(found) == 0 {
+		return "", fmt.Errorf("%w: %s/%s/%s", ErrImageNotFound, subject, category, fileName)
+	}
+
+	return found[0].FilePath, nil
+}
+
+// ReadImage 저장 된 이미지의 내용과 이미지 형식 반환
+func (dm *Manager) ReadImage(subject, category, fileName string) ([]byte, string, error) {
+	file, err := dm.ImageFile(subject, category, fileName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	image, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return image, strings.ToLower(strings.TrimPrefix(filepath.Ext(fileName), ".")), nil
+}
+
+// MarkTrained subject의 이미지를 모델 학습에 사용 된 것으로 표시
+func (dm *Manager) MarkTrained(subject string) error {
+	_, err := dm.Conn.MarkTrained(subject)
+	return err
+}
+
 // Destroy Data manager 해제
 func (dm *Manager) Destroy() {
+	dm.stopUsageFlush()
+
 	if err := dm.Conn.Destroy(); err != nil {
 		log.Printf("DB %s close failed: %s", dm.Conn.TableName, err)
 	} else {
@@ -266,6 +392,7 @@ func New() (*Manager, error) {
 	dm := &Manager{
 		Conn: conn,
 	}
+	dm.startUsageFlush()
 
 	return dm, nil
 }
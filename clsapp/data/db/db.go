@@ -41,6 +41,11 @@ func (nt *NullTime) MarshalJSON() ([]byte, error) {
 	return []byte(val), nil
 }
 
+// 이미지 출처
+const (
+	SourceUpload = "upload"
+)
+
 // Item 데이터 항목
 type Item struct {
 	Subject     string    `json:"subject"`
@@ -50,6 +55,25 @@ type Item struct {
 	FileFormat  string    `json:"-"`
 	FilePath    string    `json:"-"`
 	CreateAt    time.Time `json:"createAt"`
+	// 이미지 출처 (기본값: SourceUpload)
+	Source string `json:"source"`
+	// 모델 학습에 사용 된 이미지
+	Trained bool `json:"trained"`
+}
+
+// ImageHash 이미지의 perceptual hash
+type ImageHash struct {
+	Item
+	Hash int64
+	// 아직 hash가 계산되지 않은 경우 false
+	Valid bool
+}
+
+// CategoryCount 카테고리별 이미지 수
+type CategoryCount struct {
+	Category string `json:"category"`
+	Count    int64  `json:"count"`
+	Trained  int64  `json:"trained"`
 }
 
 func (conn *DBconn) createTable() error {
@@ -60,13 +84,48 @@ func (conn *DBconn) createTable() error {
 		filename Char(60) NOT NULL,
 		format Char(10) NOT NULL,
 		path VARCHAR(80) NOT NULL,
-		createAt DATETIME NOT NULL);`, conn.TableName)); err != nil {
+		createAt DATETIME NOT NULL,
+		source CHAR(20) NOT NULL DEFAULT '%s',
+		trained BOOLEAN NOT NULL DEFAULT FALSE,
+		phash BIGINT NULL);`, conn.TableName, SourceUpload)); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// addColumns 이전 버전에서 생성 된 테이블에 없는 컬럼 추가
+func (conn *DBconn) addColumns() error {
+	columns := []struct {
+		name       string
+		definition string
+	}{
+		{"source", fmt.Sprintf("CHAR(20) NOT NULL DEFAULT '%s'", SourceUpload)},
+		{"trained", "BOOLEAN NOT NULL DEFAULT FALSE"},
+		{"phash", "BIGINT NULL"},
+	}
+
+	for _, column := range columns {
+		var count int
+		if err := conn.db.QueryRow(`SELECT COUNT(*) FROM information_schema.COLUMNS
+			WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = ?`,
+			conn.TableName, column.name).Scan(&count); err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		log.Printf("Add column %s to DB table: %s", column.name, conn.TableName)
+		if _, err := conn.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s",
+			conn.TableName, column.name, column.definition)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (conn *DBconn) existsTable() bool {
 	if _, err := conn.db.Query(fmt.Sprintf("SELECT * FROM %s;", conn.TableName)); err != nil {
 		return false
@@ -76,18 +135,27 @@ func (conn *DBconn) existsTable() bool {
 }
 
 func (conn *DBconn) initTable() error {
+	if err := conn.initUsageTable(); err != nil {
+		return err
+	}
+
 	if !conn.existsTable() {
 		log.Printf("Create DB table: %s", conn.TableName)
 		return conn.createTable()
 	}
 
-	return nil
+	return conn.addColumns()
 }
 
 // Insert entry 삽입
 func (conn *DBconn) Insert(item Item) error {
 	createAt := item.CreateAt.Format(time.RFC3339)
 
+	source := item.Source
+	if source == "" {
+		source = SourceUpload
+	}
+
 	_, err := conn.db.Exec(fmt.Sprintf(`INSERT INTO %s (
 		subject,
 		category,
@@ -95,9 +163,11 @@ func (conn *DBconn) Insert(item Item) error {
 		filename,
 		format,
 		path,
-		createAt) value (?, ?, ?, ?, ?, ?, ?);`, conn.TableName),
+		createAt,
+		source,
+		trained) value (?, ?, ?, ?, ?, ?, ?, ?, ?);`, conn.TableName),
 		item.Subject, item.Category, item.OrgFilename, item.Filename,
-		item.FileFormat, item.FilePath, createAt,
+		item.FileFormat, item.FilePath, createAt, source, item.Trained,
 	)
 
 	return err
@@ -137,7 +207,7 @@ func (conn *DBconn) Get(param Item) (interface{}, interface{}, error) {
 	where = appendWhere(where, param.OrgFilename, "orgfilename")
 	where = appendWhere(where, param.Filename, "filename")
 
-	columns := "subject,category,filename,orgfilename,path,createAt"
+	columns := "subject,category,filename,orgfilename,path,createAt,source,trained"
 
 	var query string
 	if len(where) == 0 {
@@ -169,7 +239,9 @@ func (conn *DBconn) Get(param Item) (interface{}, interface{}, error) {
 			&item.Filename,
 			&item.OrgFilename,
 			&item.FilePath,
-			&item.CreateAt); err != nil {
+			&item.CreateAt,
+			&item.Source,
+			&item.Trained); err != nil {
 			failed++
 			log.Print(err)
 			continue
@@ -188,6 +260,128 @@ func (conn *DBconn) Get(param Item) (interface{}, interface{}, error) {
 	return infos, items, nil
 }
 
+// Browse subject와 category의 entry를 생성 순서대로 offset부터 limit개 반환
+//
+// 전체 entry 수를 함께 반환
+func (conn *DBconn) Browse(subject, category string, offset, limit int) (int64, []Item, error) {
+	var total int64
+	if err := conn.db.QueryRow(fmt.Sprintf(
+		"SELECT COUNT(*) FROM %s WHERE subject = ? AND category = ?", conn.TableName),
+		subject, category).Scan(&total); err != nil {
+		return 0, nil, err
+	}
+
+	rows, err := conn.db.Query(fmt.Sprintf(`SELECT
+		subject,category,filename,orgfilename,format,path,createAt,source,trained
+		FROM %s WHERE subject = ? AND category = ?
+		ORDER BY createAt, filename LIMIT ? OFFSET ?`, conn.TableName),
+		subject, category, limit, offset)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	items := make([]Item, 0)
+	for rows.Next() {
+		var item Item
+		if err := rows.Scan(
+			&item.Subject,
+			&item.Category,
+			&item.Filename,
+			&item.OrgFilename,
+			&item.FileFormat,
+			&item.FilePath,
+			&item.CreateAt,
+			&item.Source,
+			&item.Trained); err != nil {
+			return 0, nil, err
+		}
+		items = append(items, item)
+	}
+
+	return total, items, rows.Err()
+}
+
+// Categories subject의 카테고리별 이미지 수 반환
+func (conn *DBconn) Categories(subject string) ([]CategoryCount, error) {
+	rows, err := conn.db.Query(fmt.Sprintf(`SELECT category, COUNT(*), SUM(trained)
+		FROM %s WHERE subject = ? GROUP BY category ORDER BY category`, conn.TableName),
+		subject)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make([]CategoryCount, 0)
+	for rows.Next() {
+		var count CategoryCount
+		if err := rows.Scan(&count.Category, &count.Count, &count.Trained); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
+
+// MarkTrained subject의 모든 entry를 학습에 사용 된 것으로 표시
+func (conn *DBconn) MarkTrained(subject string) (int64, error) {
+	result, err := conn.db.Exec(fmt.Sprintf(
+		"UPDATE %s SET trained = TRUE WHERE subject = ?", conn.TableName), subject)
+	if err != nil {
+		return -1, err
+	}
+	rows, _ := result.RowsAffected()
+
+	return rows, nil
+}
+
+// Hashes subject의 모든 entry와 perceptual hash 반환
+func (conn *DBconn) Hashes(subject string) ([]ImageHash, error) {
+	rows, err := conn.db.Query(fmt.Sprintf(`SELECT
+		subject,category,filename,orgfilename,format,path,createAt,source,trained,phash
+		FROM %s WHERE subject = ? ORDER BY category, createAt, filename`, conn.TableName),
+		subject)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hashes := make([]ImageHash, 0)
+	for rows.Next() {
+		var (
+			h    ImageHash
+			hash sql.NullInt64
+		)
+		if err := rows.Scan(
+			&h.Subject,
+			&h.Category,
+			&h.Filename,
+			&h.OrgFilename,
+			&h.FileFormat,
+			&h.FilePath,
+			&h.CreateAt,
+			&h.Source,
+			&h.Trained,
+			&hash); err != nil {
+			return nil, err
+		}
+		h.Hash, h.Valid = hash.Int64, hash.Valid
+		hashes = append(hashes, h)
+	}
+
+	return hashes, rows.Err()
+}
+
+// SetHash entry의 perceptual hash 저장
+func (conn *DBconn) SetHash(item Item, hash int64) error {
+	_, err := conn.db.Exec(fmt.Sprintf(
+		"UPDATE %s SET phash = ? WHERE subject = ? AND category = ? AND filename = ?", conn.TableName),
+		hash, item.Subject, item.Category, item.Filename)
+
+	return err
+}
+
 func appendWhere(l []string, val, col string) []string {
 	if val != "" {
 		return append(l, fmt.Sprintf("%s='%s'", col, val))
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+const usageTableName = "usage_tab"
+
+// 사용량 집계 기준
+const (
+	UsageByModel  = "model"
+	UsageByAPIKey = "apikey"
+)
+
+// Usage 일별 모델, API key의 사용량
+type Usage struct {
+	Day        time.Time `json:"-"`
+	Model      string    `json:"model,omitempty"`
+	APIKey     string    `json:"apiKey,omitempty"`
+	Inferences int64     `json:"inferences"`
+	Trainings  int64     `json:"trainings"`
+}
+
+func (conn *DBconn) initUsageTable() error {
+	_, err := conn.db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		day DATE NOT NULL,
+		model VARCHAR(120) NOT NULL,
+		apikey CHAR(16) NOT NULL,
+		inferences BIGINT NOT NULL DEFAULT 0,
+		trainings BIGINT NOT NULL DEFAULT 0,
+		PRIMARY KEY (day, model, apikey));`, usageTableName))
+
+	return err
+}
+
+// AddUsage 일별 사용량에 u를 더함
+func (conn *DBconn) AddUsage(u Usage) error {
+	_, err := conn.db.Exec(fmt.Sprintf(`INSERT INTO %s (day, model, apikey, inferences, trainings)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+		inferences = inferences + VALUES(inferences),
+		trainings = trainings + VALUES(trainings)`, usageTableName),
+		u.Day.Format("2006-01-02"), u.Model, u.APIKey, u.Inferences, u.Trainings)
+
+	return err
+}
+
+// UsageReport from부터 to까지(포함) by 기준으로 합산한 사용량 반환
+func (conn *DBconn) UsageReport(from, to time.Time, by string) ([]Usage, error) {
+	if by != UsageByModel && by != UsageByAPIKey {
+		return nil, fmt.Errorf("Unknown usage group: %s", by)
+	}
+
+	rows, err := conn.db.Query(fmt.Sprintf(`SELECT %s, SUM(inferences), SUM(trainings)
+		FROM %s WHERE day BETWEEN ? AND ? GROUP BY %s ORDER BY %s`, by, usageTableName, by, by),
+		from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usages := make([]Usage, 0)
+	for rows.Next() {
+		var (
+			u   Usage
+			key string
+		)
+		if err := rows.Scan(&key, &u.Inferences, &u.Trainings); err != nil {
+			return nil, err
+		}
+		if by == UsageByModel {
+			u.Model = key
+		} else {
+			u.APIKey = key
+		}
+		usages = append(usages, u)
+	}
+
+	return usages, rows.Err()
+}
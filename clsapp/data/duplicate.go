@@ -0,0 +1,132 @@
+package data
+
+import (
+	"image"
+	"log"
+	"math/bits"
+	"os"
+
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/data/db"
+)
+
+// Duplicate perceptual hash 거리가 가까운 두 이미지
+type Duplicate struct {
+	Images   [2]db.Item `json:"images"`
+	Distance int        `json:"distance"`
+	// 두 이미지의 카테고리가 다르면 true (label 충돌)
+	CrossCategory bool `json:"crossCategory"`
+}
+
+// DuplicateReport subject의 이미지 중 hash 거리가 maxDistance 이하인 쌍을 반환
+//
+// hash가 없는 이미지는 계산 후 DB에 저장하며, 읽을 수 없는 이미지는 목록에서 제외
+func (dm *Manager) DuplicateReport(subject string, maxDistance int) (interface{}, error) {
+	hashes, err := dm.Conn.Hashes(subject)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		hashed   []db.ImageHash
+		skipped  []db.Item
+		crossCnt int
+	)
+	for _, h := range hashes {
+		if !h.Valid {
+			hash, err := fileHash(h.FilePath)
+			if err != nil {
+				log.Printf("Fail to hash %s: %s", h.FilePath, err)
+				skipped = append(skipped, h.Item)
+				continue
+			}
+
+			if err := dm.Conn.SetHash(h.Item, int64(hash)); err != nil {
+				log.Print(err)
+			}
+			h.Hash, h.Valid = int64(hash), true
+		}
+		hashed = append(hashed, h)
+	}
+
+	duplicates := make([]Duplicate, 0)
+	for i := 0; i < len(hashed); i++ {
+		for j := i + 1; j < len(hashed); j++ {
+			distance := hashDistance(uint64(hashed[i].Hash), uint64(hashed[j].Hash))
+			if distance > maxDistance {
+				continue
+			}
+
+			cross := hashed[i].Category != hashed[j].Category
+			if cross {
+				crossCnt++
+			}
+			duplicates = append(duplicates, Duplicate{
+				Images:        [2]db.Item{hashed[i].Item, hashed[j].Item},
+				Distance:      distance,
+				CrossCategory: cross,
+			})
+		}
+	}
+
+	result := map[string]interface{}{
+		"subject":     subject,
+		"maxDistance": maxDistance,
+		"infos": map[string]int{
+			"total":         len(hashes),
+			"skipped":       len(skipped),
+			"duplicates":    len(duplicates),
+			"crossCategory": crossCnt,
+		},
+		"duplicates": duplicates,
+	}
+	if len(skipped) > 0 {
+		result["skipped"] = skipped
+	}
+
+	return result, nil
+}
+
+func fileHash(path string) (uint64, error) {
+	fp, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer fp.Close()
+
+	img, _, err := image.Decode(fp)
+	if err != nil {
+		return 0, err
+	}
+
+	return dHash(img), nil
+}
+
+// dHash 이미지의 difference hash 반환
+//
+// 9x8 흑백으로 축소한 후 각 행에서 인접한 픽셀의 밝기 변화를 1비트로 표현.
+// 크기 변경, 압축률 변경 등에도 비슷한 값을 가짐
+func dHash(img image.Image) uint64 {
+	small := resample(img, 9, 8)
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if luminance(small, x, y) < luminance(small, x+1, y) {
+				hash |= 1
+			}
+		}
+	}
+
+	return hash
+}
+
+func luminance(img *image.RGBA, x, y int) uint32 {
+	r, g, b, _ := img.At(x, y).RGBA()
+	return (299*r + 587*g + 114*b) / 1000
+}
+
+// hashDistance 두 hash의 hamming distance
+func hashDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
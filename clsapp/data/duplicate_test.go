@@ -0,0 +1,55 @@
+package data
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// gradient 가로 방향으로 밝기가 변하는 테스트 이미지
+func gradient(w, h int, reverse bool) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8(x * 255 / w)
+			if reverse {
+				v = 255 - v
+			}
+			img.Set(x, y, color.RGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+
+	return img
+}
+
+func TestDHash(t *testing.T) {
+	original := dHash(gradient(320, 240, false))
+
+	// 크기만 다른 이미지는 같은 hash
+	if resized := dHash(gradient(160, 120, false)); hashDistance(original, resized) != 0 {
+		t.Fatalf("Resized image distance: %d", hashDistance(original, resized))
+	}
+
+	// 밝기 변화가 반대인 이미지는 모든 비트가 다름
+	if reversed := dHash(gradient(320, 240, true)); hashDistance(original, reversed) != 64 {
+		t.Fatalf("Reversed image distance: %d", hashDistance(original, reversed))
+	}
+}
+
+func TestHashDistance(t *testing.T) {
+	tests := []struct {
+		a, b     uint64
+		distance int
+	}{
+		{0, 0, 0},
+		{0, 1, 1},
+		{0xff, 0x0f, 4},
+		{0, ^uint64(0), 64},
+	}
+
+	for _, tt := range tests {
+		if d := hashDistance(tt.a, tt.b); d != tt.distance {
+			t.Errorf("hashDistance(%x, %x) = %d, want %d", tt.a, tt.b, d, tt.distance)
+		}
+	}
+}
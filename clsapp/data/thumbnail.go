@@ -0,0 +1,186 @@
+package data
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	// 썸네일 생성시 디코딩 할 이미지 형식
+	_ "image/gif"
+	_ "image/png"
+
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/constants"
+)
+
+// DefaultThumbnailSizes 기본 썸네일 크기 (가로, 세로 중 긴 쪽의 픽셀 수)
+var DefaultThumbnailSizes = []int{128, 256}
+
+// 썸네일 저장 경로
+//
+// 이미지 그룹 이름은 `.`으로 시작할 수 없으므로 이미지 저장 경로 하위에 두어도 충돌하지 않음
+var thumbnailsPath = filepath.Join(constants.ImagesPath, ".thumbnails")
+
+// Thumbnail 저장 된 이미지의 썸네일 파일 경로 반환
+//
+// 썸네일이 없으면 생성 후 저장하며, 이후 요청은 저장 된 썸네일을 사용
+func (dm *Manager) Thumbnail(subject, category, fileName string, size int) (string, error) {
+	if !dm.validThumbnailSize(size) {
+		return "", fmt.Errorf("%w: %d (%v)", ErrInvalidThumbnailSize, size, dm.thumbnailSizes())
+	}
+
+	file, err := dm.ImageFile(subject, category, fileName)
+	if err != nil {
+		return "", err
+	}
+
+	thumbnail := thumbnailFile(subject, category, fileName, size)
+	if _, err := os.Stat(thumbnail); err == nil {
+		return thumbnail, nil
+	}
+
+	if err := makeThumbnail(file, thumbnail, size); err != nil {
+		return "", err
+	}
+
+	return thumbnail, nil
+}
+
+func (dm *Manager) thumbnailSizes() []int {
+	if len(dm.ThumbnailSizes) == 0 {
+		return DefaultThumbnailSizes
+	}
+
+	return dm.ThumbnailSizes
+}
+
+func (dm *Manager) validThumbnailSize(size int) bool {
+	for _, s := range dm.thumbnailSizes() {
+		if s == size {
+			return true
+		}
+	}
+
+	return false
+}
+
+func thumbnailFile(subject, category, fileName string, size int) string {
+	return filepath.Join(thumbnailsPath, strconv.Itoa(size), subject, category, fileName+".jpg")
+}
+
+// removeThumbnails 이미지의 모든 크기의 썸네일 삭제
+func removeThumbnails(subject, category, fileName string) {
+	dirs, _ := ioutil.ReadDir(thumbnailsPath)
+	for _, dir := range dirs {
+		size, err := strconv.Atoi(dir.Name())
+		if err != nil {
+			continue
+		}
+		os.Remove(thumbnailFile(subject, category, fileName, size))
+	}
+}
+
+// makeThumbnail src 이미지를 size 안에 들어가도록 비율을 유지하며 축소해 jpeg로 저장
+func makeThumbnail(src, dst string, size int) error {
+	fp, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+
+	img, _, err := image.Decode(fp)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+
+	// 동시에 같은 썸네일을 생성해도 완성 된 파일만 보이도록 임시 파일에 쓴 후 rename
+	tmp, err := ioutil.TempFile(filepath.Dir(dst), filepath.Base(dst)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := jpeg.Encode(tmp, downscale(img, size), &jpeg.Options{Quality: 85}); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), dst)
+}
+
+// downscale 긴 쪽이 size가 되도록 영역 평균으로 축소
+//
+// 이미 size보다 작은 이미지는 크기를 유지
+func downscale(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	dstW, dstH := srcW, srcH
+	if srcW > size || srcH > size {
+		if srcW >= srcH {
+			dstW, dstH = size, srcH*size/srcW
+		} else {
+			dstW, dstH = srcW*size/srcH, size
+		}
+	}
+	if dstW < 1 {
+		dstW = 1
+	}
+	if dstH < 1 {
+		dstH = 1
+	}
+
+	return resample(img, dstW, dstH)
+}
+
+// resample img를 dstW x dstH 크기로 영역 평균하여 변환
+func resample(img image.Image, dstW, dstH int) *image.RGBA {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0 := bounds.Min.Y + y*srcH/dstH
+		y1 := bounds.Min.Y + (y+1)*srcH/dstH
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+
+		for x := 0; x < dstW; x++ {
+			x0 := bounds.Min.X + x*srcW/dstW
+			x1 := bounds.Min.X + (x+1)*srcW/dstW
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(b / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+
+	return dst
+}
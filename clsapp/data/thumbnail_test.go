@@ -0,0 +1,99 @@
+package data
+
+import (
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDownscale(t *testing.T) {
+	tests := []struct {
+		w, h, size int
+		dstW, dstH int
+	}{
+		{400, 200, 128, 128, 64},
+		{200, 400, 128, 64, 128},
+		{300, 300, 256, 256, 256},
+		{100, 50, 128, 100, 50},
+		{1000, 1, 128, 128, 1},
+	}
+
+	for _, tt := range tests {
+		img := image.NewRGBA(image.Rect(0, 0, tt.w, tt.h))
+		bounds := downscale(img, tt.size).Bounds()
+		if bounds.Dx() != tt.dstW || bounds.Dy() != tt.dstH {
+			t.Errorf("downscale(%dx%d, %d) = %dx%d, want %dx%d",
+				tt.w, tt.h, tt.size, bounds.Dx(), bounds.Dy(), tt.dstW, tt.dstH)
+		}
+	}
+}
+
+func TestMakeThumbnail(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clsapp-thumbnail-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	img := image.NewRGBA(image.Rect(0, 0, 300, 150))
+	for y := 0; y < 150; y++ {
+		for x := 0; x < 300; x++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+
+	src := filepath.Join(dir, "image.png")
+	fp, err := os.Create(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(fp, img); err != nil {
+		t.Fatal(err)
+	}
+	fp.Close()
+
+	dst := filepath.Join(dir, "128", "pets", "cat", "image.png.jpg")
+	if err := makeThumbnail(src, dst, 128); err != nil {
+		t.Fatal(err)
+	}
+
+	fp, err = os.Open(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fp.Close()
+
+	thumbnail, err := jpeg.Decode(fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bounds := thumbnail.Bounds(); bounds.Dx() != 128 || bounds.Dy() != 64 {
+		t.Fatalf("Unexpected thumbnail size: %dx%d", bounds.Dx(), bounds.Dy())
+	}
+
+	// 임시 파일은 남지 않음
+	files, err := ioutil.ReadDir(filepath.Dir(dst))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("Unexpected files: %d", len(files))
+	}
+}
+
+func TestValidThumbnailSize(t *testing.T) {
+	dm := &Manager{}
+	if !dm.validThumbnailSize(128) || dm.validThumbnailSize(64) {
+		t.Fatal("Unexpected default thumbnail sizes")
+	}
+
+	dm.ThumbnailSizes = []int{64}
+	if !dm.validThumbnailSize(64) || dm.validThumbnailSize(128) {
+		t.Fatal("Unexpected configured thumbnail sizes")
+	}
+}
@@ -0,0 +1,158 @@
+package data
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/data/db"
+)
+
+// 사용량을 DB에 저장하는 주기
+const usageFlushInterval = time.Minute
+
+type usageKey struct {
+	day    string
+	model  string
+	apiKey string
+}
+
+// usageRecorder 요청마다 DB에 쓰지 않도록 사용량을 모아 주기적으로 저장
+type usageRecorder struct {
+	mutex  sync.Mutex
+	counts map[usageKey]*db.Usage
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// UsageKeyID API key를 사용량에 기록하는 식별자로 변환
+//
+// 리포트에 API key가 노출되지 않도록 SHA-256의 앞 16자리를 사용하며, 빈 key는 "anonymous"
+func UsageKeyID(apiKey string) string {
+	if apiKey == "" {
+		return "anonymous"
+	}
+
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+func (r *usageRecorder) add(u db.Usage) {
+	key := usageKey{
+		day:    u.Day.Format("2006-01-02"),
+		model:  u.Model,
+		apiKey: u.APIKey,
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.counts == nil {
+		r.counts = make(map[usageKey]*db.Usage)
+	}
+	if c, ok := r.counts[key]; ok {
+		c.Inferences += u.Inferences
+		c.Trainings += u.Trainings
+	} else {
+		r.counts[key] = &u
+	}
+}
+
+func (r *usageRecorder) take() map[usageKey]*db.Usage {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	counts := r.counts
+	r.counts = nil
+
+	return counts
+}
+
+// RecordInference 모델의 추론 횟수 기록
+func (dm *Manager) RecordInference(model, apiKey string, n int) {
+	dm.usage.add(db.Usage{
+		Day:        time.Now(),
+		Model:      model,
+		APIKey:     UsageKeyID(apiKey),
+		Inferences: int64(n),
+	})
+}
+
+// RecordTraining 모델의 학습 요청 기록
+func (dm *Manager) RecordTraining(model, apiKey string) {
+	dm.usage.add(db.Usage{
+		Day:       time.Now(),
+		Model:     model,
+		APIKey:    UsageKeyID(apiKey),
+		Trainings: 1,
+	})
+}
+
+// FlushUsage 모아둔 사용량을 DB에 저장
+//
+// 저장에 실패한 사용량은 다시 모아 다음 저장시 재시도
+func (dm *Manager) FlushUsage() error {
+	var firstErr error
+
+	for _, u := range dm.usage.take() {
+		if err := dm.Conn.AddUsage(*u); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			dm.usage.add(*u)
+		}
+	}
+
+	return firstErr
+}
+
+// UsageReport from부터 to까지(포함) by 기준으로 합산한 사용량 반환
+func (dm *Manager) UsageReport(from, to time.Time, by string) ([]db.Usage, error) {
+	// 아직 저장하지 않은 사용량도 포함
+	if err := dm.FlushUsage(); err != nil {
+		return nil, err
+	}
+
+	return dm.Conn.UsageReport(from, to, by)
+}
+
+func (dm *Manager) startUsageFlush() {
+	r := &dm.usage
+	r.done = make(chan struct{})
+	r.wg.Add(1)
+
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(usageFlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.done:
+				return
+			case <-ticker.C:
+				if err := dm.FlushUsage(); err != nil {
+					log.Printf("Fail to save usage: %s", err)
+				}
+			}
+		}
+	}()
+}
+
+func (dm *Manager) stopUsageFlush() {
+	r := &dm.usage
+	if r.done == nil {
+		return
+	}
+
+	close(r.done)
+	r.wg.Wait()
+
+	if err := dm.FlushUsage(); err != nil {
+		log.Printf("Fail to save usage: %s", err)
+	}
+}
@@ -0,0 +1,52 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/data/db"
+)
+
+func TestUsageKeyID(t *testing.T) {
+	if id := UsageKeyID(""); id != "anonymous" {
+		t.Fatalf("Unexpected empty key id: %s", id)
+	}
+
+	id := UsageKeyID("secret-key")
+	if len(id) != 16 || id == "secret-key" || id != UsageKeyID("secret-key") {
+		t.Fatalf("Unexpected key id: %s", id)
+	}
+	if id == UsageKeyID("other-key") {
+		t.Fatal("Different keys should have different ids")
+	}
+}
+
+func TestUsageRecorder(t *testing.T) {
+	dm := &Manager{}
+	dm.RecordInference("default", "", 2)
+	dm.RecordInference("default", "", 3)
+	dm.RecordTraining("default", "")
+	dm.RecordInference("default", "secret-key", 1)
+
+	counts := dm.usage.take()
+	if len(counts) != 2 {
+		t.Fatalf("Unexpected usage: %v", counts)
+	}
+
+	day := time.Now().Format("2006-01-02")
+	u := counts[usageKey{day: day, model: "default", apiKey: "anonymous"}]
+	if u == nil || u.Inferences != 5 || u.Trainings != 1 {
+		t.Fatalf("Unexpected anonymous usage: %+v", u)
+	}
+
+	if counts := dm.usage.take(); len(counts) != 0 {
+		t.Fatalf("Usage should be taken: %v", counts)
+	}
+
+	// 저장에 실패해 다시 모은 사용량은 새 사용량과 합산
+	dm.usage.add(*u)
+	dm.usage.add(db.Usage{Day: time.Now(), Model: "default", APIKey: "anonymous", Inferences: 1})
+	if u := dm.usage.take()[usageKey{day: day, model: "default", apiKey: "anonymous"}]; u.Inferences != 6 {
+		t.Fatalf("Unexpected merged usage: %+v", u)
+	}
+}
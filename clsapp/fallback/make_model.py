@@ -0,0 +1,54 @@
+"""이미지 빌드시 fallback 기본 모델 생성
+
+learnapp의 base 모델(ImageNet으로 학습한 MobileNetV2)과 같은 SavedModel, labels, config.yaml을
+지정한 디렉토리에 저장하며, learnapp에 연결할 수 없을 때 clsapp이 기본 모델로 사용
+
+    python3 make_model.py <model path>
+"""
+import os
+import sys
+
+import tensorflow as tf
+
+LABELS_FILE = "labels"
+CONFIG_FILE = "config.yaml"
+
+
+def make_model(model_path):
+    model = tf.keras.applications.MobileNetV2(weights="imagenet")
+    model.save(model_path)
+
+    labels_path = tf.keras.utils.get_file(
+        "ImageNetLabels.txt",
+        "https://storage.googleapis.com/download.tensorflow.org/data/ImageNetLabels.txt",
+    )
+    # 불필요한 첫번째 label(`background`)를 제거
+    with open(labels_path) as ifp:
+        labels = ifp.readlines()
+    with open(os.path.join(model_path, LABELS_FILE), "w") as ofp:
+        ofp.writelines(labels[1:])
+
+    input_name = (
+        f"{tf.saved_model.DEFAULT_SERVING_SIGNATURE_DEF_KEY}_{model.input_names[0]}"
+    )
+    input_shape = ", ".join(str(d) for d in model.input_shape[1:])
+
+    # PyYAML이 없는 이미지에서도 실행할 수 있도록 직접 작성
+    with open(os.path.join(model_path, CONFIG_FILE), "w") as fp:
+        fp.write(
+            "name: default\n"
+            "type: base\n"
+            f"tags: [{tf.saved_model.SERVING}]\n"
+            "classification: multi\n"
+            f"inputShape: [{input_shape}]\n"
+            f"inputOperationName: {input_name}\n"
+            "outputOperationName: StatefulPartitionedCall\n"
+            f"labelsFile: {LABELS_FILE}\n"
+            "description: Fallback base model\n"
+        )
+
+
+if __name__ == "__main__":
+    if len(sys.argv) != 2:
+        sys.exit(f"Usage: {sys.argv[0]} <model path>")
+    make_model(sys.argv[1])
@@ -3,11 +3,12 @@ module github.com/harrison-roh/image-classification-with-transfer-learning/clsap
 go 1.14
 
 require (
+	github.com/fsnotify/fsnotify v1.4.9
 	github.com/gin-gonic/gin v1.6.3
 	github.com/go-sql-driver/mysql v1.5.0
 	github.com/golang/protobuf v1.4.2 // indirect
 	github.com/google/uuid v1.1.2
-	github.com/harrison-roh/cleanuphttp v0.0.0-20200828151304-375cfcf61c2e // indirect
+	github.com/harrison-roh/cleanuphttp v0.0.0-20200828151304-375cfcf61c2e
 	github.com/tensorflow/tensorflow v1.12.0 // manually modifed
 	gopkg.in/yaml.v2 v2.4.0
 )
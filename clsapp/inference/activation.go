@@ -0,0 +1,127 @@
+package inference
+
+import (
+	"fmt"
+	"math"
+)
+
+// 모델 출력에 적용하는 활성화 함수
+const (
+	// 모델이 확률을 출력하는 경우 (기본값)
+	activationNone = "none"
+	// 다중 분류 모델이 logits를 출력하는 경우
+	activationSoftmax = "softmax"
+	// binary 모델이 logit을 출력하는 경우
+	activationSigmoid = "sigmoid"
+)
+
+// activate 모델 출력을 확률로 변환
+//
+// outputs를 변경하지 않고 새로운 slice를 반환
+func activate(activation string, outputs []float32) []float32 {
+	probs := make([]float32, len(outputs))
+
+	switch activation {
+	case activationSoftmax:
+		// overflow를 막기 위해 최대값을 뺀 후 계산
+		maxOutput := math.Inf(-1)
+		for _, output := range outputs {
+			maxOutput = math.Max(maxOutput, float64(output))
+		}
+
+		var sum float64
+		exps := make([]float64, len(outputs))
+		for idx, output := range outputs {
+			exps[idx] = math.Exp(float64(output) - maxOutput)
+			sum += exps[idx]
+		}
+		for idx := range exps {
+			probs[idx] = float32(exps[idx] / sum)
+		}
+	case activationSigmoid:
+		for idx, output := range outputs {
+			probs[idx] = float32(1 / (1 + math.Exp(-float64(output))))
+		}
+	default:
+		copy(probs, outputs)
+	}
+
+	return probs
+}
+
+// activateMean 이미지(TTA의 원본과 변형 이미지)별 모델 출력을 각각 확률로 변환한 후 평균
+func activateMean(activation string, outputs [][]float32) []float32 {
+	if len(outputs) == 1 {
+		return activate(activation, outputs[0])
+	}
+
+	mean := make([]float32, len(outputs[0]))
+	for _, output := range outputs {
+		for idx, prob := range activate(activation, output) {
+			mean[idx] += prob / float32(len(outputs))
+		}
+	}
+
+	return mean
+}
+
+// 확률을 logit으로 바꿀 때 0과 1을 피하기 위한 값
+const probEpsilon = 1e-7
+
+// scaleTemperature 활성화 함수를 적용하기 전의 모델 출력을 temperature로 나눔 (temperature scaling)
+//
+// 활성화 함수가 none이면 출력을 확률로 보고 logit(다중 분류 모델은 log)으로 바꿔 나눈 후 다시 확률로 변환.
+// temperature가 0(지정 안함) 또는 1이면 그대로 반환
+func (cfg *modelConfig) scaleTemperature(outputs []float32) []float32 {
+	t := float64(cfg.Temperature)
+	if t == 0 || t == 1 {
+		return outputs
+	}
+
+	scaled := make([]float32, len(outputs))
+	switch {
+	case cfg.OutputActivation == activationSoftmax || cfg.OutputActivation == activationSigmoid:
+		for idx, output := range outputs {
+			scaled[idx] = float32(float64(output) / t)
+		}
+	case len(outputs) == 1 || cfg.Classification == multiLabelClass:
+		// 클래스별 독립인 확률은 sigmoid의 역함수로 logit을 구함
+		for idx, output := range outputs {
+			p := math.Min(math.Max(float64(output), probEpsilon), 1-probEpsilon)
+			scaled[idx] = float32(1 / (1 + math.Exp(-math.Log(p/(1-p))/t)))
+		}
+	default:
+		// softmax의 logits는 log 확률과 상수 차이이므로 log 확률을 나눠 다시 softmax 적용
+		logs := make([]float32, len(outputs))
+		for idx, output := range outputs {
+			logs[idx] = float32(math.Log(math.Max(float64(output), probEpsilon)) / t)
+		}
+		scaled = activate(activationSoftmax, logs)
+	}
+
+	return scaled
+}
+
+// probabilities 이미지별 모델 출력에 temperature와 활성화 함수를 적용하여 평균한 확률
+func (cfg *modelConfig) probabilities(outputs [][]float32) []float32 {
+	scaled := make([][]float32, len(outputs))
+	for idx, output := range outputs {
+		scaled[idx] = cfg.scaleTemperature(output)
+	}
+
+	return activateMean(cfg.OutputActivation, scaled)
+}
+
+// positiveProb binary 모델의 positive 클래스(labels[1]) 확률
+//
+// 모델 출력은 positive 클래스의 값 하나 또는 두 클래스의 값(softmax를 적용하는 logits 등)
+func positiveProb(activation string, probs []float32) (float32, error) {
+	switch {
+	case len(probs) == 2:
+		return probs[1], nil
+	case len(probs) == 1 && activation != activationSoftmax:
+		return probs[0], nil
+	}
+
+	return 0, fmt.Errorf("%w: invalid number of binary outputs(%d) for %q activation", ErrInvalidConfig, len(probs), activation)
+}
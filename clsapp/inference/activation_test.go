@@ -0,0 +1,113 @@
+package inference
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestActivate(t *testing.T) {
+	logits := []float32{2, 1, 0.1}
+
+	probs := activate(activationSoftmax, logits)
+	var sum float32
+	for _, prob := range probs {
+		sum += prob
+	}
+	if math.Abs(float64(sum-1)) > 1e-5 || !(probs[0] > probs[1] && probs[1] > probs[2]) {
+		t.Fatalf("Unexpected softmax: %v", probs)
+	}
+	if math.Abs(float64(probs[0])-0.659) > 1e-3 {
+		t.Fatalf("Unexpected softmax: %v", probs)
+	}
+
+	// 큰 logit에서도 overflow 없이 계산
+	probs = activate(activationSoftmax, []float32{1000, 1000})
+	if probs[0] != 0.5 || probs[1] != 0.5 {
+		t.Fatalf("Unexpected softmax with large logits: %v", probs)
+	}
+
+	probs = activate(activationSigmoid, []float32{0, 100, -100})
+	if probs[0] != 0.5 || probs[1] < 0.999 || probs[2] > 0.001 {
+		t.Fatalf("Unexpected sigmoid: %v", probs)
+	}
+
+	for _, activation := range []string{"", activationNone} {
+		probs = activate(activation, logits)
+		for idx := range logits {
+			if probs[idx] != logits[idx] {
+				t.Fatalf("Unexpected %q activation: %v", activation, probs)
+			}
+		}
+	}
+}
+
+func TestPositiveProb(t *testing.T) {
+	if prob, err := positiveProb(activationSigmoid, []float32{0.7}); err != nil || prob != 0.7 {
+		t.Fatalf("Unexpected single output: %v, %v", prob, err)
+	}
+
+	// 두 클래스의 logits에 softmax를 적용한 경우 labels[1]의 확률
+	probs := activate(activationSoftmax, []float32{0, 2})
+	if prob, err := positiveProb(activationSoftmax, probs); err != nil || math.Abs(float64(prob)-0.881) > 1e-3 {
+		t.Fatalf("Unexpected softmax output: %v, %v", prob, err)
+	}
+
+	// softmax를 적용한 출력 하나는 항상 1
+	if _, err := positiveProb(activationSoftmax, []float32{1}); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Single softmax output should fail: %v", err)
+	}
+	if _, err := positiveProb(activationNone, []float32{0.1, 0.2, 0.7}); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Three outputs should fail: %v", err)
+	}
+}
+
+func TestActivateMean(t *testing.T) {
+	probs := activateMean(activationNone, [][]float32{{0.8, 0.2}, {0.4, 0.6}})
+	if math.Abs(float64(probs[0])-0.6) > 1e-6 || math.Abs(float64(probs[1])-0.4) > 1e-6 {
+		t.Fatalf("Unexpected mean: %v", probs)
+	}
+
+	// 활성화 함수는 평균 전에 이미지별로 적용
+	probs = activateMean(activationSoftmax, [][]float32{{1000, 0}, {0, 1000}})
+	if probs[0] != 0.5 || probs[1] != 0.5 {
+		t.Fatalf("Unexpected softmax mean: %v", probs)
+	}
+}
+
+func TestScaleTemperature(t *testing.T) {
+	near := func(a, b float32) bool {
+		return math.Abs(float64(a-b)) < 1e-4
+	}
+
+	// logits는 나눈 후 활성화 함수 적용
+	cfg := modelConfig{Classification: multiClass, OutputActivation: activationSoftmax, Temperature: 2}
+	probs := cfg.probabilities([][]float32{{2, 0}})
+	if want := activate(activationSoftmax, []float32{1, 0}); !near(probs[0], want[0]) {
+		t.Fatalf("Unexpected scaled softmax: %v, %v", probs, want)
+	}
+
+	// 확률을 출력하는 다중 분류 모델은 p^(1/T)를 정규화 한 값
+	cfg = modelConfig{Classification: multiClass, Temperature: 2}
+	probs = cfg.probabilities([][]float32{{0.8, 0.2}})
+	if !near(probs[0], 2.0/3) || !near(probs[1], 1.0/3) {
+		t.Fatalf("Unexpected scaled probabilities: %v", probs)
+	}
+
+	// 확률 하나를 출력하는 binary 모델은 logit을 나눔 (logit(0.9) / 2 = log(3))
+	cfg = modelConfig{Classification: binaryClass, Temperature: 2}
+	if probs = cfg.probabilities([][]float32{{0.9}}); !near(probs[0], 0.75) {
+		t.Fatalf("Unexpected scaled binary probability: %v", probs)
+	}
+
+	// temperature가 1 미만이면 확률이 높아짐
+	cfg.Temperature = 0.5
+	if probs = cfg.probabilities([][]float32{{0.75}}); !near(probs[0], 0.9) {
+		t.Fatalf("Unexpected sharpened probability: %v", probs)
+	}
+
+	cfg.Temperature = 0
+	if probs = cfg.probabilities([][]float32{{0.9}}); probs[0] != 0.9 {
+		t.Fatalf("Unset temperature should not change probabilities: %v", probs)
+	}
+}
@@ -0,0 +1,190 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+
+	"gopkg.in/yaml.v2"
+)
+
+// aliasesFile 모델 별칭(별칭 이름: 모델 또는 `<model>/<version>`)을 저장하는 파일
+//
+// 모델 이름은 `.`으로 시작할 수 없으므로 모델 디렉토리와 충돌하지 않음
+const aliasesFile = ".aliases.yaml"
+
+func (i *Inference) aliasesPath() string {
+	return filepath.Join(i.modelsPath, aliasesFile)
+}
+
+// loadAliases 저장 된 모델 별칭 로드
+//
+// 대상 모델이 없는 별칭도 유지하며(추론은 ErrModelNotFound), 모델과 이름이 같은 별칭은 무시
+func (i *Inference) loadAliases() {
+	b, err := ioutil.ReadFile(i.aliasesPath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Fail to load aliases(%s): %s", i.aliasesPath(), err)
+		}
+		return
+	}
+
+	var aliases map[string]string
+	if err := yaml.Unmarshal(b, &aliases); err != nil {
+		log.Printf("Fail to load aliases(%s): %s", i.aliasesPath(), err)
+		return
+	}
+
+	for alias := range aliases {
+		if _, ok := i.models[alias]; ok {
+			log.Printf("Skip alias with the same name as the model: %s", alias)
+			delete(aliases, alias)
+		}
+	}
+	i.aliases = aliases
+}
+
+// writeAliases aliases를 저장한 후 사용하는 별칭으로 바꿈
+//
+// 저장에 실패하면 이전 별칭을 계속 사용. i.rwMutex를 잡은 상태에서 호출
+func (i *Inference) writeAliases(aliases map[string]string) error {
+	b, err := yaml.Marshal(aliases)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(i.aliasesPath(), b); err != nil {
+		return err
+	}
+
+	i.aliases = aliases
+	return nil
+}
+
+// resolveAlias name이 별칭이면 대상 모델 이름, 아니면 name 반환
+//
+// i.rwMutex를 잡은 상태에서 호출
+func (i *Inference) resolveAlias(name string) string {
+	if target, ok := i.aliases[name]; ok {
+		return target
+	}
+
+	return name
+}
+
+// modelAliases model(version이 0이면 모든 버전)을 가리키는 별칭 (이름 순서)
+//
+// i.rwMutex를 잡은 상태에서 호출
+func (i *Inference) modelAliases(model string, version int) []string {
+	var aliases []string
+	for alias, target := range i.aliases {
+		m, v, err := splitModelVersion(target)
+		if err == nil && m == model && (version == 0 || v == version) {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+
+	return aliases
+}
+
+// SetAlias 별칭이 target 모델(`<model>/<version>`이면 해당 버전)을 가리키도록 지정하고 이전 대상을 반환
+//
+// 별칭의 대상은 한번에 바뀌므로 별칭으로 추론하는 요청은 이전 또는 새 대상 중 하나로 처리 됨
+func (i *Inference) SetAlias(ctx context.Context, alias, target string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if err := i.authorize(ctx, ActionManageAlias, alias); err != nil {
+		return "", err
+	}
+	if err := ValidateName(alias); err != nil {
+		return "", err
+	}
+
+	i.rwMutex.Lock()
+	defer i.rwMutex.Unlock()
+
+	if _, ok := i.models[alias]; ok {
+		return "", fmt.Errorf("%w: %s", ErrDuplicateModel, alias)
+	}
+	if _, ok := i.aliases[target]; ok {
+		return "", fmt.Errorf("%w: alias of alias: %s", ErrInvalidName, target)
+	}
+
+	m := i.lookupModel(target)
+	if m == nil {
+		return "", fmt.Errorf("%w: %s", ErrModelNotFound, target)
+	}
+	// 사용자 모델은 다른 사용자가 별칭으로 추론할 수 없도록 별칭을 지정할 수 없음
+	if m.tenant != "" {
+		return "", fmt.Errorf("%w: alias of tenant model: %s", ErrInvalidName, target)
+	}
+	if atomic.LoadInt32(&m.status) != modelStatusRun {
+		return "", fmt.Errorf("%w: %s", ErrModelNotReady, target)
+	}
+
+	aliases := make(map[string]string, len(i.aliases)+1)
+	for name, t := range i.aliases {
+		aliases[name] = t
+	}
+	aliases[alias] = target
+
+	previous := i.aliases[alias]
+	if err := i.writeAliases(aliases); err != nil {
+		return "", err
+	}
+
+	log.Printf("Set alias(%s): %s -> %s", alias, previous, target)
+	return previous, nil
+}
+
+// DeleteAlias 별칭 삭제 (대상 모델은 삭제하지 않음)
+func (i *Inference) DeleteAlias(ctx context.Context, alias string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := i.authorize(ctx, ActionManageAlias, alias); err != nil {
+		return err
+	}
+
+	i.rwMutex.Lock()
+	defer i.rwMutex.Unlock()
+
+	if _, ok := i.aliases[alias]; !ok {
+		return fmt.Errorf("%w: %s", ErrAliasNotFound, alias)
+	}
+
+	aliases := make(map[string]string, len(i.aliases))
+	for name, target := range i.aliases {
+		if name != alias {
+			aliases[name] = target
+		}
+	}
+
+	return i.writeAliases(aliases)
+}
+
+// GetAliases 별칭과 대상 모델 반환
+//
+// 조회 권한이 없는 별칭은 제외
+func (i *Inference) GetAliases(ctx context.Context) map[string]string {
+	i.rwMutex.RLock()
+	aliases := make(map[string]string, len(i.aliases))
+	for alias, target := range i.aliases {
+		aliases[alias] = target
+	}
+	i.rwMutex.RUnlock()
+
+	for alias := range aliases {
+		if i.authorize(ctx, ActionReadModel, alias) != nil {
+			delete(aliases, alias)
+		}
+	}
+
+	return aliases
+}
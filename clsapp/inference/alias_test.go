@@ -0,0 +1,83 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestAliases(t *testing.T) {
+	modelsPath := newModelsPath(t)
+	defer os.RemoveAll(modelsPath)
+
+	current := &iModel{name: "pets", version: 2, status: modelStatusRun}
+	previous := &iModel{name: "pets", version: 1, status: modelStatusRun}
+	i := &Inference{
+		models: map[string]*iModel{
+			"pets":       current,
+			"pets@alice": {name: "pets@alice", tenant: "alice", status: modelStatusRun},
+		},
+		modelsPath: modelsPath,
+	}
+	i.putVersion(previous)
+
+	ctx := context.Background()
+	if _, err := i.SetAlias(ctx, "production", "pets/1"); err != nil {
+		t.Fatal(err)
+	}
+	if i.lookupModel("production") != previous {
+		t.Fatal("Alias should point to the version")
+	}
+
+	// 이전 대상을 반환하여 되돌릴 수 있음
+	if previous, err := i.SetAlias(ctx, "production", "pets"); err != nil || previous != "pets/1" {
+		t.Fatalf("Unexpected previous target: %q, %v", previous, err)
+	}
+	if i.lookupModel("production") != current {
+		t.Fatal("Alias should point to the current version")
+	}
+
+	for _, tt := range []struct {
+		alias, target string
+		err           error
+	}{
+		{"pets", "pets/1", ErrDuplicateModel},
+		{"canary", "production", ErrInvalidName},
+		{"canary", "pets@alice", ErrInvalidName},
+		{"canary", "none", ErrModelNotFound},
+		{"a/b", "pets", ErrInvalidName},
+	} {
+		if _, err := i.SetAlias(ctx, tt.alias, tt.target); !errors.Is(err, tt.err) {
+			t.Errorf("SetAlias(%q, %q) = %v", tt.alias, tt.target, err)
+		}
+	}
+
+	// 별칭이 가리키는 모델은 삭제 할 수 없음
+	if err := i.delModel("pets"); !errors.Is(err, ErrModelInUse) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := i.delModel("production"); !errors.Is(err, ErrInvalidName) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := i.addModel(&iModel{name: "production"}); !errors.Is(err, ErrDuplicateModel) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// 저장 된 별칭을 다시 로드
+	loaded := &Inference{models: map[string]*iModel{}, modelsPath: modelsPath}
+	loaded.loadAliases()
+	if aliases := loaded.GetAliases(ctx); len(aliases) != 1 || aliases["production"] != "pets" {
+		t.Fatalf("Unexpected aliases: %v", aliases)
+	}
+
+	if err := i.DeleteAlias(ctx, "production"); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.DeleteAlias(ctx, "production"); !errors.Is(err, ErrAliasNotFound) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if i.lookupModel("production") != nil {
+		t.Fatal("Deleted alias should not be found")
+	}
+}
@@ -0,0 +1,86 @@
+package inference
+
+import (
+	"fmt"
+)
+
+// 투명(alpha 채널) 이미지의 처리 방식
+const (
+	// alpha 채널을 버림 (기본값, 투명한 부분은 이미지에 저장 된 색, 보통 검은색)
+	alphaStrip = "strip"
+	// alphaBackground 색 위에 alpha 값으로 합성
+	alphaComposite = "composite"
+)
+
+// 합성할 배경색의 기본값 (흰색)
+const defaultAlphaBackground = 255
+
+// alpha 투명 이미지의 처리 방식
+func (cfg *modelConfig) alpha() string {
+	if cfg.Alpha == "" {
+		return alphaStrip
+	}
+
+	return cfg.Alpha
+}
+
+// alphaBackground 합성할 RGB 배경색 (합성하지 않으면 nil)
+func (cfg *modelConfig) alphaBackground() []float32 {
+	if cfg.alpha() != alphaComposite {
+		return nil
+	}
+
+	switch len(cfg.AlphaBackground) {
+	case 0:
+		return []float32{defaultAlphaBackground, defaultAlphaBackground, defaultAlphaBackground}
+	case 1:
+		bg := cfg.AlphaBackground[0]
+		return []float32{bg, bg, bg}
+	}
+
+	return cfg.AlphaBackground
+}
+
+// alphaName 전처리 graph 저장 파일에 사용하는 alpha 처리 방식 이름 (예: strip, composite255_255_255)
+func (cfg *modelConfig) alphaName() string {
+	bg := cfg.alphaBackground()
+	if bg == nil {
+		return alphaStrip
+	}
+
+	return alphaComposite + channelValues(bg).join()
+}
+
+// validateAlpha alpha 처리 방식과 배경색 검사
+func (cfg *modelConfig) validateAlpha() []string {
+	var violations []string
+
+	switch cfg.alpha() {
+	case alphaStrip:
+		if len(cfg.AlphaBackground) > 0 {
+			violations = append(violations, fmt.Sprintf("`alphaBackground` must not be set for %s", alphaStrip))
+		}
+	case alphaComposite:
+	default:
+		violations = append(violations, fmt.Sprintf("`alpha` must be %s or %s: %q", alphaStrip, alphaComposite, cfg.Alpha))
+	}
+
+	if len(cfg.AlphaBackground) > 1 && len(cfg.AlphaBackground) != 3 {
+		violations = append(violations, fmt.Sprintf("`alphaBackground` must have 1 or 3(RGB) values: %v", cfg.AlphaBackground))
+	}
+	for _, v := range cfg.AlphaBackground {
+		if v < 0 || v > 255 {
+			violations = append(violations, fmt.Sprintf("`alphaBackground` must be in [0, 255]: %v", cfg.AlphaBackground))
+			break
+		}
+	}
+
+	return violations
+}
+
+// compositeAlpha alpha 값(0 ~ 255)으로 색을 배경색 위에 합성
+func compositeAlpha(c, a uint8, bg float32) uint8 {
+	v := (float32(c)*float32(a) + bg*float32(255-a)) / 255
+
+	return uint8(v + 0.5)
+}
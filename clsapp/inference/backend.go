@@ -0,0 +1,315 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"log"
+	"sync"
+)
+
+// modelBackend 로드 된 모델의 실행 엔진
+//
+// 기본값은 TensorFlow SavedModel 또는 frozen graph를 실행하는 backend이며 (fake build tag에서는 가짜 결과를 반환),
+// 그 밖의 형식은 Go에서 전처리한 입력으로 BackendModel을 실행하는 runtimeBackend 사용
+type modelBackend interface {
+	// runImages 이미지들을 전처리하여 모델을 실행하고 이미지별 출력 반환
+	runImages(ctx context.Context, inputs []imageInput) ([][][]float32, error)
+	// runTensor 전처리 된 입력 하나로 모델을 실행
+	runTensor(ctx context.Context, data []float32) ([]float32, error)
+	runDetection(ctx context.Context, input imageInput) ([]rawDetection, error)
+	runSegmentation(ctx context.Context, input imageInput, threshold float32) (*segmentMap, error)
+	runEmbedding(ctx context.Context, input imageInput) ([]float32, error)
+	runCAM(ctx context.Context, input imageInput, class int) ([][][]float32, [][][]float32, error)
+	// graph 모델 graph의 operation 목록 (graph가 없으면 nil)
+	graph() []GraphOp
+	close(name string)
+}
+
+// BackendConfig Backend가 모델을 로드할 때 사용하는 모델 config 항목
+type BackendConfig struct {
+	// Name 모델 이름
+	Name string
+	// Format config의 `format`
+	Format string
+	// InputShape batch를 제외한 [height, width, channels]
+	InputShape []int32
+	// InputDType float32 또는 uint8
+	InputDType string
+	// InputLayout NHWC 또는 NCHW
+	InputLayout string
+	// InputOperationName, OutputOperationName config에 지정한 경우 모델의 입력과 출력 이름
+	InputOperationName  string
+	OutputOperationName string
+}
+
+// BackendModel Backend로 로드 된 모델
+//
+// 모델의 maxConcurrentRuns 이내에서 여러 goroutine이 동시에 호출 할 수 있음
+type BackendModel interface {
+	// Infer 모델 입력 하나(batch 제외, InputLayout 순서로 펼친 값)를 실행하여 출력 반환
+	//
+	// uint8 입력 모델의 값은 [0, 255]의 정수
+	Infer(ctx context.Context, input []float32) ([]float32, error)
+	// Close 모델 해제
+	Close() error
+}
+
+// Backend TensorFlow 대신 모델을 실행하는 엔진 (예: ONNX Runtime, 원격 추론 서버)
+//
+// 모델 config의 `format`에 등록한 이름을 지정하며, 이미지 디코딩, EXIF orientation, 크기 조정과 정규화는 Go에서 처리한 후
+// 이미지별로 Infer를 호출하므로 `binary`, `multi`, `multilabel` 분류 모델만 지원
+type Backend interface {
+	// LoadModel 모델 버전 디렉토리의 모델을 로드
+	LoadModel(modelPath string, cfg BackendConfig) (BackendModel, error)
+}
+
+// BackendFunc 함수를 Backend로 사용
+type BackendFunc func(modelPath string, cfg BackendConfig) (BackendModel, error)
+
+// LoadModel f(modelPath, cfg) 호출
+func (f BackendFunc) LoadModel(modelPath string, cfg BackendConfig) (BackendModel, error) {
+	return f(modelPath, cfg)
+}
+
+var (
+	backends      = make(map[string]Backend)
+	backendsMutex sync.RWMutex
+)
+
+// RegisterBackend format으로 Backend 등록
+//
+// 모델 로드 전에 등록해야 하며, 내장 형식(saved_model, frozen_graph, tflite, tf_serving, triton)은 등록해도 사용하지 않음
+func RegisterBackend(format string, b Backend) {
+	backendsMutex.Lock()
+	defer backendsMutex.Unlock()
+
+	backends[format] = b
+}
+
+func lookupBackend(format string) (Backend, bool) {
+	backendsMutex.RLock()
+	defer backendsMutex.RUnlock()
+
+	b, ok := backends[format]
+	return b, ok
+}
+
+// runtimeFormat TensorFlow graph 대신 Go에서 전처리한 입력으로 실행하는 형식 (tflite, tf_serving, triton 또는 등록 된 Backend)
+func (cfg *modelConfig) runtimeFormat() bool {
+	switch cfg.modelFormat() {
+	case formatSavedModel, formatFrozenGraph:
+		return false
+	default:
+		return true
+	}
+}
+
+// validateRuntime Go에서 전처리하여 하나의 출력을 사용하는 분류 모델만 지원
+func (cfg *modelConfig) validateRuntime() []string {
+	var violations []string
+
+	format := cfg.modelFormat()
+	switch cfg.Classification {
+	case binaryClass, multiClass, multiLabelClass:
+	default:
+		violations = append(violations, fmt.Sprintf("%s model must be %s, %s or %s: %q",
+			format, binaryClass, multiClass, multiLabelClass, cfg.Classification))
+	}
+	if cfg.resizeMode() != resizeStretch {
+		violations = append(violations, fmt.Sprintf("`resizeMode` of %s model must be %s: %q", format, resizeStretch, cfg.ResizeMode))
+	}
+	if len(cfg.TTA) > 0 || cfg.MultiCrop > 0 {
+		violations = append(violations, fmt.Sprintf("`tta` and `multiCrop` must not be used with %s model", format))
+	}
+	if cfg.channels() != 3 {
+		violations = append(violations, fmt.Sprintf("`channels` of %s model must be 3: %d", format, cfg.channels()))
+	}
+	if cfg.EmbeddingOperationName != "" || cfg.CAMOperationName != "" {
+		violations = append(violations, fmt.Sprintf("`embeddingOperationName` and `camOperationName` must not be used with %s model", format))
+	}
+
+	return violations
+}
+
+// backendConfig Backend에 전달하는 config
+func (cfg *modelConfig) backendConfig() BackendConfig {
+	return BackendConfig{
+		Name:                cfg.Name,
+		Format:              cfg.modelFormat(),
+		InputShape:          cfg.InputShape,
+		InputDType:          cfg.inputDType(),
+		InputLayout:         cfg.inputLayout(),
+		InputOperationName:  cfg.InputOperationName,
+		OutputOperationName: cfg.OutputOperationName,
+	}
+}
+
+// openModelBackend config의 format에 맞는 실행 엔진으로 모델 버전 디렉토리의 모델을 로드
+//
+// graphCachePath는 TensorFlow 모델의 전처리 graph 저장 경로 (빈 값이면 저장하지 않음)
+func openModelBackend(modelPath string, cfg modelConfig, tfliteRuntime TFLiteRuntime, graphCachePath string) (modelBackend, error) {
+	var b Backend
+	switch format := cfg.modelFormat(); format {
+	case formatSavedModel, formatFrozenGraph:
+		tb, err := openBackend(modelPath, cfg, graphCachePath)
+		if err != nil {
+			return nil, err
+		}
+		return tb, nil
+	case formatTFLite:
+		if tfliteRuntime == nil {
+			return nil, errNoTFLiteRuntime
+		}
+		b = tfliteBackend{runtime: tfliteRuntime}
+	case formatTFServing:
+		b = tfServingBackend{cfg: *cfg.TFServing}
+	case formatTriton:
+		b = tritonBackend{cfg: *cfg.Triton}
+	default:
+		var ok bool
+		if b, ok = lookupBackend(format); !ok {
+			return nil, fmt.Errorf("Backend is not registered: %s", format)
+		}
+	}
+
+	model, err := b.LoadModel(modelPath, cfg.backendConfig())
+	if err != nil {
+		return nil, fmt.Errorf("Fail to load %s model: %w", cfg.modelFormat(), err)
+	}
+
+	return &runtimeBackend{
+		cfg:   cfg,
+		model: model,
+		runs:  newRunLimiter(cfg.MaxConcurrentRuns),
+	}, nil
+}
+
+// runtimeBackend Go에서 전처리한 입력으로 Backend가 로드한 모델을 실행하는 엔진
+type runtimeBackend struct {
+	cfg   modelConfig
+	model BackendModel
+
+	// 동시 실행 수 제한
+	runs runLimiter
+}
+
+// runImages 이미지별로 Go에서 전처리하여 모델을 실행
+func (b *runtimeBackend) runImages(ctx context.Context, inputs []imageInput) ([][][]float32, error) {
+	results := make([][][]float32, len(inputs))
+	for idx, input := range inputs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		data, err := b.cfg.runtimeInput(input)
+		if err != nil {
+			return nil, batchImageError(inputs, idx, err)
+		}
+
+		output, err := b.runTensor(ctx, data)
+		if err != nil {
+			return nil, batchImageError(inputs, idx, err)
+		}
+		results[idx] = [][]float32{output}
+	}
+
+	return results, nil
+}
+
+// runTensor 동시 실행 수 제한 안에서 전처리 된 입력 하나를 실행
+func (b *runtimeBackend) runTensor(ctx context.Context, data []float32) ([]float32, error) {
+	release, err := b.runs.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	defer timeStage(ctx, stageRun)()
+
+	return b.model.Infer(ctx, data)
+}
+
+// runDetection 등 분류가 아닌 모델은 config 검사에서 거부 됨
+func (b *runtimeBackend) runDetection(ctx context.Context, input imageInput) ([]rawDetection, error) {
+	return nil, b.unsupported("detection")
+}
+
+func (b *runtimeBackend) runSegmentation(ctx context.Context, input imageInput, threshold float32) (*segmentMap, error) {
+	return nil, b.unsupported("segmentation")
+}
+
+func (b *runtimeBackend) runEmbedding(ctx context.Context, input imageInput) ([]float32, error) {
+	return nil, b.unsupported("embedding")
+}
+
+func (b *runtimeBackend) runCAM(ctx context.Context, input imageInput, class int) ([][][]float32, [][][]float32, error) {
+	return nil, nil, b.unsupported("CAM")
+}
+
+func (b *runtimeBackend) unsupported(what string) error {
+	return fmt.Errorf("%s is not supported by %s model", what, b.cfg.modelFormat())
+}
+
+// graph TensorFlow graph가 없음
+func (b *runtimeBackend) graph() []GraphOp {
+	return nil
+}
+
+func (b *runtimeBackend) close(name string) {
+	if err := b.model.Close(); err != nil {
+		log.Printf("%s %s model close failed: %s", name, b.cfg.modelFormat(), err)
+	} else {
+		log.Printf("%s %s model successfully closed", name, b.cfg.modelFormat())
+	}
+}
+
+// runtimeInput 이미지를 디코딩하고 EXIF orientation, 크기 조정(stretch), 정규화를 Go에서 적용한 모델 입력
+func (cfg *modelConfig) runtimeInput(input imageInput) ([]float32, error) {
+	pix, h, w := input.pix, input.height, input.width
+	if pix == nil {
+		format, err := preprocessFormat(input.format)
+		if err != nil {
+			return nil, err
+		}
+		if pix, h, w, err = decodePixels(input.image, format, cfg.alphaBackground()); err != nil {
+			return nil, err
+		}
+	}
+	pix, h, w = orientPixels(pix, h, w, input.orientation)
+
+	height, width := int(cfg.InputShape[0]), int(cfg.InputShape[1])
+	if h != height || w != width {
+		pix = imagePixels(rgbImage(pix, h, w), nil, height, width)
+	}
+
+	uint8Input := cfg.inputDType() == inputUint8
+	n := cfg.normalization()
+	data := make([]float32, len(pix))
+	for idx, v := range pix {
+		// pix는 [height, width, 3] 순서
+		c := idx % 3
+		dst := idx
+		if cfg.inputLayout() == layoutNCHW {
+			dst = c*height*width + idx/3
+		}
+
+		if uint8Input {
+			data[dst] = float32(v)
+		} else {
+			data[dst] = (float32(v) - n.mean.value(c)) / n.std.value(c)
+		}
+	}
+
+	return data, nil
+}
+
+// rgbImage [h, w, 3] RGB 픽셀을 크기 조정에 사용할 수 있는 이미지로 변환
+func rgbImage(pix []byte, h, w int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for idx := 0; idx < h*w; idx++ {
+		copy(img.Pix[idx*4:], pix[idx*3:idx*3+3])
+		img.Pix[idx*4+3] = 0xff
+	}
+
+	return img
+}
@@ -0,0 +1,302 @@
+//go:build fake
+// +build fake
+
+package inference
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"log"
+	"math"
+	"path/filepath"
+	"sort"
+)
+
+// backend 테스트용 가짜 실행 엔진
+//
+// `-tags fake`로 빌드하면 TensorFlow C 라이브러리 없이 동작하며,
+// SavedModel 대신 config와 labels만 읽어 이미지 내용에 따라 항상 같은 결과를 반환
+type backend struct {
+	cfg       modelConfig
+	nrOutputs int
+	runs      runLimiter
+}
+
+func openBackend(modelPath string, cfg modelConfig, graphCachePath string) (*backend, error) {
+	labels, err := loadLabels(filepath.Join(modelPath, cfg.LabelsFile))
+	if err != nil {
+		return nil, err
+	}
+
+	nrOutputs := len(labels)
+	if cfg.Classification == binaryClass {
+		nrOutputs = 1
+	}
+
+	if nrOutputs == 0 {
+		return nil, errors.New("Empty labels")
+	}
+
+	return &backend{
+		cfg:       cfg,
+		nrOutputs: nrOutputs,
+		runs:      newRunLimiter(cfg.MaxConcurrentRuns),
+	}, nil
+}
+
+// runImages 이미지별로 이미지(줄였으면 픽셀) 내용에 따라 항상 같은 결과를 반환
+func (b *backend) runImages(ctx context.Context, inputs []imageInput) ([][][]float32, error) {
+	results := make([][][]float32, len(inputs))
+	for idx, input := range inputs {
+		outputs, err := b.run(ctx, input)
+		if err != nil {
+			return nil, batchImageError(inputs, idx, err)
+		}
+		results[idx] = outputs
+	}
+
+	return results, nil
+}
+
+func (b *backend) run(ctx context.Context, input imageInput) ([][]float32, error) {
+	release, err := b.runs.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	defer timeStage(ctx, stageRun)()
+
+	if input.pix != nil {
+		if len(input.pix) != input.height*input.width*3 {
+			return nil, errors.New("Invalid pixels")
+		}
+		return b.outputs(input.pix), nil
+	}
+
+	format, err := preprocessFormat(input.format)
+	if err != nil {
+		return nil, err
+	}
+
+	// Go에서 디코딩하는 형식은 실제 엔진과 같이 디코딩 할 수 있는지 확인
+	if goDecodedFormats[format] {
+		if _, _, _, err := decodePixels(input.image, format, b.cfg.alphaBackground()); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(input.image) == 0 {
+		return nil, errors.New("Empty image")
+	}
+
+	return b.outputs(input.image), nil
+}
+
+// outputs 입력 bytes의 해시로 정해지는 이미지별 출력
+func (b *backend) outputs(data []byte) [][]float32 {
+	h := fnv.New32a()
+	h.Write(data)
+
+	// TTA의 변형 이미지는 원본과 같은 결과
+	probs := b.probabilities(h.Sum32())
+	outputs := make([][]float32, b.cfg.ttaVariants())
+	for idx := range outputs {
+		outputs[idx] = probs
+	}
+
+	return outputs
+}
+
+// runDetection 이미지 내용에 따라 정해지는 가짜 검출 결과 반환
+//
+// 클래스별 확률을 점수로, 이미지를 클래스 수만큼 세로로 나눈 영역을 박스로 사용
+func (b *backend) runDetection(ctx context.Context, input imageInput) ([]rawDetection, error) {
+	outputs, err := b.run(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	detections := make([]rawDetection, b.nrOutputs)
+	for idx, score := range outputs[0] {
+		detections[idx] = rawDetection{
+			box:   [4]float32{0, float32(idx) / float32(b.nrOutputs), 1, float32(idx+1) / float32(b.nrOutputs)},
+			score: score,
+			class: idx + b.cfg.ClassOffset,
+		}
+	}
+
+	return detections, nil
+}
+
+// runSegmentation 이미지 내용에 따라 정해지는 가짜 mask 반환
+//
+// 입력 크기의 이미지를 클래스 수만큼 세로로 나눈 영역을 이미지 해시로 정해지는 순서의 클래스로 채움
+func (b *backend) runSegmentation(ctx context.Context, input imageInput, threshold float32) (*segmentMap, error) {
+	outputs, err := b.run(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	// 가장 확률이 높은 클래스부터 왼쪽 영역을 차지
+	order := make([]int, b.nrOutputs)
+	for idx := range order {
+		order[idx] = idx
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return outputs[0][order[i]] > outputs[0][order[j]]
+	})
+
+	h, w := int(b.cfg.InputShape[0]), int(b.cfg.InputShape[1])
+	seg := &segmentMap{height: h, width: w, classes: make([]uint8, h*w)}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			seg.classes[y*w+x] = uint8(order[x*b.nrOutputs/w])
+		}
+	}
+
+	return seg, nil
+}
+
+// runEmbedding 이미지 내용에 따라 정해지는 가짜 embedding 반환
+//
+// 클래스별 확률 뒤에 이미지 해시로 정해지는 값을 붙여 같은 이미지는 같은 embedding을 가짐
+func (b *backend) runEmbedding(ctx context.Context, input imageInput) ([]float32, error) {
+	outputs, err := b.run(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	h := fnv.New32a()
+	if input.pix != nil {
+		h.Write(input.pix)
+	} else {
+		h.Write(input.image)
+	}
+	seed := h.Sum32()
+
+	embedding := append([]float32{}, outputs[0]...)
+	for idx := 0; idx < 4; idx++ {
+		embedding = append(embedding, float32(seed>>(idx*8)&0xff)/255)
+	}
+
+	return embedding, nil
+}
+
+// runCAM 이미지 내용과 class에 따라 정해지는 위치에서 가장 큰 가짜 conv layer 출력 반환
+//
+// conv layer 출력은 입력의 1/32 크기이며, gradient는 camGradientOperationName이 있는 경우에만 반환
+func (b *backend) runCAM(ctx context.Context, input imageInput, class int) ([][][]float32, [][][]float32, error) {
+	if _, err := b.run(ctx, input); err != nil {
+		return nil, nil, err
+	}
+
+	h := fnv.New32a()
+	if input.pix != nil {
+		h.Write(input.pix)
+	} else {
+		h.Write(input.image)
+	}
+	seed := h.Sum32() + uint32(class)
+
+	fh, fw := int(b.cfg.InputShape[0])/32, int(b.cfg.InputShape[1])/32
+	if fh < 1 {
+		fh = 1
+	}
+	if fw < 1 {
+		fw = 1
+	}
+	cy, cx := int(seed%uint32(fh)), int(seed/uint32(fh)%uint32(fw))
+
+	features := make([][][]float32, fh)
+	gradients := make([][][]float32, fh)
+	for y := range features {
+		features[y] = make([][]float32, fw)
+		gradients[y] = make([][]float32, fw)
+		for x := range features[y] {
+			distance := math.Abs(float64(y-cy)) + math.Abs(float64(x-cx))
+			features[y][x] = []float32{float32(1 / (1 + distance)), 0.1}
+			gradients[y][x] = []float32{1, -1}
+		}
+	}
+
+	if b.cfg.CAMGradientOperationName == "" {
+		return features, nil, nil
+	}
+
+	return features, gradients, nil
+}
+
+// runTensor 입력값에 따라 항상 같은 결과를 반환
+func (b *backend) runTensor(ctx context.Context, data []float32) ([]float32, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	h := fnv.New32a()
+	buf := make([]byte, 4)
+	for _, v := range data {
+		binary.LittleEndian.PutUint32(buf, math.Float32bits(v))
+		h.Write(buf)
+	}
+
+	return b.probabilities(h.Sum32()), nil
+}
+
+// probabilities 입력의 해시(seed)로 정해지는 가짜 확률
+func (b *backend) probabilities(seed uint32) []float32 {
+	// binary 모델은 positive 클래스의 확률 하나만 반환
+	if b.nrOutputs == 1 {
+		return []float32{float32(seed%1000) / 1000}
+	}
+
+	// 이미지 해시에 따라 정해지는 클래스부터 1, 1/2, 1/3, ... 비율의 확률을 부여
+	var (
+		probs = make([]float32, b.nrOutputs)
+		sum   float32
+	)
+	for idx := range probs {
+		rank := (idx + b.nrOutputs - int(seed%uint32(b.nrOutputs))) % b.nrOutputs
+		probs[idx] = 1 / float32(rank+1)
+		sum += probs[idx]
+	}
+	for idx := range probs {
+		probs[idx] /= sum
+	}
+
+	return probs
+}
+
+// graph 입력과 출력 operation만 있는 가짜 graph 반환
+func (b *backend) graph() []GraphOp {
+	shape := []int64{-1}
+	for _, d := range b.cfg.InputShape {
+		shape = append(shape, int64(d))
+	}
+	if b.cfg.inputLayout() == layoutNCHW {
+		shape = []int64{-1, shape[3], shape[1], shape[2]}
+	}
+
+	ops := []GraphOp{
+		{
+			Name:    b.cfg.InputOperationName,
+			Type:    "Placeholder",
+			Outputs: []GraphTensor{{DataType: b.cfg.inputDType(), Shape: shape}},
+		},
+	}
+	for _, name := range append(b.cfg.outputOperations(), b.cfg.auxOperations()...) {
+		ops = append(ops, GraphOp{
+			Name:    name,
+			Type:    "Identity",
+			Inputs:  []string{b.cfg.InputOperationName},
+			Outputs: []GraphTensor{{DataType: "float32", Shape: []int64{-1, int64(b.nrOutputs)}}},
+		})
+	}
+
+	return ops
+}
+
+func (b *backend) close(name string) {
+	log.Printf("%s fake model closed", name)
+}
@@ -0,0 +1,214 @@
+//go:build fake
+// +build fake
+
+package inference
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFakeBackendDetection(t *testing.T) {
+	b := &backend{
+		cfg:       modelConfig{Classification: detectionClass, ClassOffset: 1},
+		nrOutputs: 3,
+	}
+
+	input := imageInput{image: []byte("image"), format: "jpeg", orientation: 1}
+	detections, err := b.runDetection(context.Background(), input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(detections) != 3 || detections[0].class != 1 || detections[2].box[3] != 1 {
+		t.Fatalf("Unexpected detections: %v", detections)
+	}
+}
+
+func TestFakeBackendSegmentation(t *testing.T) {
+	b := &backend{
+		cfg:       modelConfig{Classification: segmentationClass, InputShape: []int32{4, 6, 3}},
+		nrOutputs: 3,
+	}
+
+	input := imageInput{image: []byte("image"), format: "jpeg", orientation: 1}
+	seg, err := b.runSegmentation(context.Background(), input, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seg.height != 4 || seg.width != 6 || len(seg.classes) != 24 {
+		t.Fatalf("Unexpected segment map: %+v", seg)
+	}
+
+	seen := make(map[uint8]bool)
+	for _, class := range seg.classes {
+		seen[class] = true
+	}
+	if len(seen) != 3 {
+		t.Fatalf("Every class should be in the mask: %v", seg.classes)
+	}
+}
+
+func TestFakeBackendEmbedding(t *testing.T) {
+	b := &backend{
+		cfg:       modelConfig{Classification: multiClass, EmbeddingOperationName: "embedding"},
+		nrOutputs: 3,
+	}
+
+	embed := func(image string) []float32 {
+		input := imageInput{image: []byte(image), format: "jpeg", orientation: 1}
+		embedding, err := b.runEmbedding(context.Background(), input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		normalized, err := normalizeEmbedding(embedding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return normalized
+	}
+
+	x := newEmbeddingIndex()
+	for _, image := range []string{"first", "second", "third"} {
+		if err := x.add(image, embed(image)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := x.search(embed("second"), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].ID != "second" || results[0].Distance > 1e-6 {
+		t.Fatalf("The same image should be the nearest: %+v", results)
+	}
+}
+
+func TestFakeBackendCAM(t *testing.T) {
+	b := &backend{
+		cfg:       modelConfig{Classification: multiClass, InputShape: []int32{224, 224, 3}, CAMOperationName: "conv"},
+		nrOutputs: 3,
+	}
+
+	input := imageInput{image: []byte("image"), format: "jpeg", orientation: 1}
+	features, gradients, err := b.runCAM(context.Background(), input, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(features) != 7 || len(features[0]) != 7 || gradients != nil {
+		t.Fatalf("Unexpected conv layer output: %d, %v", len(features), gradients)
+	}
+
+	b.cfg.CAMGradientOperationName = "gradient"
+	if _, gradients, err = b.runCAM(context.Background(), input, 1); err != nil || len(gradients) != 7 {
+		t.Fatalf("Unexpected gradient: %v", err)
+	}
+}
+
+func TestFakeBackendOcclusion(t *testing.T) {
+	labels := []labelInfo{{Name: "cat"}, {Name: "dog"}, {Name: "bird"}}
+	m := &iModel{
+		cfg:        modelConfig{Classification: multiClass, InputShape: []int32{224, 224, 3}},
+		nrLables:   len(labels),
+		labels:     labelNames(labels),
+		labelInfos: labels,
+		backend: &backend{
+			cfg:       modelConfig{Classification: multiClass},
+			nrOutputs: 3,
+		},
+	}
+
+	pix := make([]byte, 28*21*3)
+	for idx := range pix {
+		pix[idx] = byte(idx)
+	}
+
+	heat, err := m.occlusionMap(context.Background(), pix, 28, 21, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(heat) != occlusionGrid*occlusionGrid {
+		t.Fatalf("Unexpected heatmap size: %d", len(heat))
+	}
+
+	// 원본 확률이 1이면 가린 이미지의 확률은 모두 줄어듦
+	var maxHeat float32
+	for _, v := range heat {
+		if v < 0 || v > 1 {
+			t.Fatalf("Heatmap should be normalized: %v", heat)
+		}
+		if v > maxHeat {
+			maxHeat = v
+		}
+	}
+	if maxHeat != 1 {
+		t.Fatalf("Unexpected max heat: %v", heat)
+	}
+}
+
+func TestFakeBackendDeterministic(t *testing.T) {
+	b := &backend{
+		cfg:       modelConfig{Classification: multiClass},
+		nrOutputs: 4,
+	}
+
+	input := imageInput{image: []byte("image"), format: "jpeg", orientation: 1}
+
+	first, err := b.run(context.Background(), input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := b.run(context.Background(), input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sum float32
+	if len(first) != 1 {
+		t.Fatalf("Unexpected outputs: %v", first)
+	}
+	for idx := range first[0] {
+		if first[0][idx] != second[0][idx] {
+			t.Fatalf("Not deterministic: %v, %v", first, second)
+		}
+		sum += first[0][idx]
+	}
+
+	if sum < 0.999 || sum > 1.001 {
+		t.Fatalf("Invalid sum of probabilities: %f", sum)
+	}
+
+	// multi-crop은 crop별 출력
+	b.cfg.MultiCrop = multiCrop10
+	if outputs, err := b.run(context.Background(), input); err != nil || len(outputs) != multiCrop10 {
+		t.Fatalf("Unexpected multi-crop outputs: %d, %v", len(outputs), err)
+	}
+
+	// batch의 이미지별 출력과 실패한 이미지의 순서
+	results, err := b.runImages(context.Background(), []imageInput{input, input})
+	if err != nil || len(results) != 2 || len(results[1]) != multiCrop10 {
+		t.Fatalf("Unexpected batch outputs: %d, %v", len(results), err)
+	}
+
+	svg := imageInput{image: []byte("image"), format: "svg", orientation: 1}
+	if _, err := b.runImages(context.Background(), []imageInput{input, svg}); err == nil || !strings.HasPrefix(err.Error(), "Image 1: ") {
+		t.Fatalf("Unsupported format should fail: %v", err)
+	}
+}
+
+func TestFakeBackendWarmup(t *testing.T) {
+	for _, classification := range []string{multiClass, detectionClass, segmentationClass} {
+		cfg := modelConfig{Classification: classification, InputShape: []int32{8, 8, 3}}
+		m := &iModel{
+			name:       classification,
+			cfg:        cfg,
+			backend:    &backend{cfg: cfg, nrOutputs: 3},
+			inputShape: cfg.InputShape[:2],
+			warmupRuns: 2,
+		}
+		if err := m.warmup(context.Background()); err != nil {
+			t.Fatalf("Fail to warmup %s model: %s", classification, err)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type fakeTFLiteModel struct {
+	inputs [][]float32
+	closed bool
+}
+
+func (m *fakeTFLiteModel) Run(ctx context.Context, input []float32) ([]float32, error) {
+	m.inputs = append(m.inputs, input)
+	return []float32{0.25, 0.75}, nil
+}
+
+func (m *fakeTFLiteModel) Close() error {
+	m.closed = true
+	return nil
+}
+
+func TestRuntimeInput(t *testing.T) {
+	// [1, 2, 3] RGB 픽셀
+	input := imageInput{pix: []byte{0, 10, 20, 30, 40, 50}, height: 1, width: 2, orientation: 1}
+
+	cfg := modelConfig{InputShape: []int32{1, 2, 3}, Mean: channelValues{10}, Std: channelValues{10}}
+	data, err := cfg.runtimeInput(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []float32{-1, 0, 1, 2, 3, 4}
+	for idx := range expected {
+		if data[idx] != expected[idx] {
+			t.Fatalf("Unexpected NHWC input: %v", data)
+		}
+	}
+
+	cfg.InputLayout = layoutNCHW
+	cfg.Mean = channelValues{0, 10, 20}
+	cfg.Std = channelValues{1}
+	if data, err = cfg.runtimeInput(input); err != nil {
+		t.Fatal(err)
+	}
+	expected = []float32{0, 30, 0, 30, 0, 30}
+	for idx := range expected {
+		if data[idx] != expected[idx] {
+			t.Fatalf("Unexpected NCHW input: %v", data)
+		}
+	}
+
+	// uint8 입력은 정규화 하지 않음
+	cfg = modelConfig{InputShape: []int32{1, 2, 3}, InputDType: inputUint8}
+	if data, err = cfg.runtimeInput(input); err != nil {
+		t.Fatal(err)
+	}
+	if data[1] != 10 || data[5] != 50 {
+		t.Fatalf("Unexpected uint8 input: %v", data)
+	}
+
+	// 모델 입력 크기로 조정
+	cfg.InputShape = []int32{2, 4, 3}
+	if data, err = cfg.runtimeInput(input); err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 2*4*3 {
+		t.Fatalf("Unexpected resized input: %d", len(data))
+	}
+}
+
+func TestTFLiteBackend(t *testing.T) {
+	cfg := modelConfig{Format: formatTFLite, InputShape: []int32{1, 2, 3}}
+	if _, err := openModelBackend("model", cfg, nil, ""); !errors.Is(err, errNoTFLiteRuntime) {
+		t.Fatalf("Backend without runtime should fail: %v", err)
+	}
+
+	lite := &fakeTFLiteModel{}
+	var loaded string
+	runtime := TFLiteRuntimeFunc(func(modelFile string) (TFLiteModel, error) {
+		loaded = modelFile
+		return lite, nil
+	})
+
+	b, err := openModelBackend("model", cfg, runtime, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded != filepath.Join("model", tfliteModelFile) {
+		t.Fatalf("Unexpected model file: %s", loaded)
+	}
+	if b.graph() != nil {
+		t.Fatalf("TFLite model should not have graph")
+	}
+
+	inputs := []imageInput{
+		{pix: []byte{0, 10, 20, 30, 40, 50}, height: 1, width: 2, orientation: 1},
+		{pix: []byte{50, 40, 30, 20, 10, 0}, height: 1, width: 2, orientation: 1},
+	}
+	results, err := b.runImages(context.Background(), inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || len(lite.inputs) != 2 || results[1][0][1] != 0.75 {
+		t.Fatalf("Unexpected results: %v", results)
+	}
+
+	b.close("tflite")
+	if !lite.closed {
+		t.Fatalf("TFLite model should be closed")
+	}
+}
+
+func TestRegisterBackend(t *testing.T) {
+	cfg := modelConfig{Name: "pets", Format: "test-remote", Classification: multiClass, InputShape: []int32{1, 2, 3}, InputLayout: layoutNCHW}
+	if _, err := openModelBackend("model", cfg, nil, ""); err == nil {
+		t.Fatalf("Unregistered backend should fail")
+	}
+	if violations := cfg.validateFormat("model"); len(violations) != 1 {
+		t.Fatalf("Unregistered format should be invalid: %v", violations)
+	}
+
+	model := &fakeTFLiteModel{}
+	var loaded BackendConfig
+	RegisterBackend("test-remote", BackendFunc(func(modelPath string, cfg BackendConfig) (BackendModel, error) {
+		loaded = cfg
+		return tfliteModel{model}, nil
+	}))
+
+	if violations := cfg.validateFormat("model"); len(violations) != 0 {
+		t.Fatalf("Registered format should be valid: %v", violations)
+	}
+	cfg.TTA = []string{"flip"}
+	if violations := cfg.validateFormat("model"); len(violations) != 1 {
+		t.Fatalf("TTA should not be used with registered backend: %v", violations)
+	}
+	cfg.TTA = nil
+
+	b, err := openModelBackend("model", cfg, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Name != "pets" || loaded.InputLayout != layoutNCHW || loaded.InputDType != inputFloat32 {
+		t.Fatalf("Unexpected backend config: %+v", loaded)
+	}
+
+	output, err := b.runTensor(context.Background(), make([]float32, 6))
+	if err != nil || len(output) != 2 {
+		t.Fatalf("Unexpected output: %v %v", output, err)
+	}
+	if _, err := b.runEmbedding(context.Background(), imageInput{}); err == nil {
+		t.Fatalf("Embedding should not be supported")
+	}
+}
+
+func TestLoadModelsWithoutTFLiteRuntime(t *testing.T) {
+	modelsPath := newModelsPath(t)
+	defer os.RemoveAll(modelsPath)
+
+	modelPath := filepath.Join(modelsPath, "pets")
+	writeV1Model(t, modelPath, "pets")
+	writeConfig(t, modelPath, "name: pets\ntype: trial\nformat: tflite\nclassification: binary\ninputShape: [224, 224, 3]\nlabelsFile: lables\n")
+	if err := ioutil.WriteFile(filepath.Join(modelPath, tfliteModelFile), []byte("tflite"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// TFLite 실행 엔진이 없으면 config 에러로 로드하지 않지만 삭제하지 않음
+	i := &Inference{
+		modelsPath: modelsPath,
+		models:     make(map[string]*iModel),
+		versions:   make(map[string]map[int]*iModel),
+	}
+	if err := i.loadModels(); err != nil {
+		t.Fatal(err)
+	}
+	if len(i.models) != 0 {
+		t.Fatalf("TFLite model should not be loaded without runtime: %v", i.models)
+	}
+	if _, err := os.Stat(filepath.Join(versionPath(modelPath, 1), tfliteModelFile)); err != nil {
+		t.Fatalf("TFLite model should not be removed: %v", err)
+	}
+}
+
+func TestLoadModelValidatesLabelsBeforeBackend(t *testing.T) {
+	modelsPath := newModelsPath(t)
+	defer os.RemoveAll(modelsPath)
+
+	modelPath := filepath.Join(modelsPath, "pets")
+	writeV1Model(t, modelPath, "pets")
+	writeConfig(t, modelPath, "name: pets\ntype: trial\nformat: tflite\nclassification: binary\ninputShape: [224, 224, 3]\nlabelsFile: lables\n")
+	if err := ioutil.WriteFile(filepath.Join(modelPath, tfliteModelFile), []byte("tflite"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(modelPath, "lables"), []byte("cat\ndog\nbird\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var opened []*fakeTFLiteModel
+	newInference := func() *Inference {
+		return &Inference{
+			modelsPath: modelsPath,
+			models:     make(map[string]*iModel),
+			versions:   make(map[string]map[int]*iModel),
+			tfliteRuntime: TFLiteRuntimeFunc(func(modelFile string) (TFLiteModel, error) {
+				lite := &fakeTFLiteModel{}
+				opened = append(opened, lite)
+				return lite, nil
+			}),
+		}
+	}
+
+	// labels가 잘못된 모델은 실행 엔진을 열지 않음
+	i := newInference()
+	if err := i.loadModels(); err != nil {
+		t.Fatal(err)
+	}
+	if len(i.models) != 0 || len(opened) != 0 {
+		t.Fatalf("Model with invalid labels should not open backend: %v %d", i.models, len(opened))
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(versionPath(modelPath, 1), "lables"), []byte("cat\ndog\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	i = newInference()
+	if err := i.loadModels(); err != nil {
+		t.Fatal(err)
+	}
+	if len(i.models) != 1 || len(opened) != 1 {
+		t.Fatalf("Unexpected models: %v %d", i.models, len(opened))
+	}
+}
@@ -0,0 +1,925 @@
+//go:build !fake
+// +build !fake
+
+package inference
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	tf "github.com/tensorflow/tensorflow/tensorflow/go"
+	"github.com/tensorflow/tensorflow/tensorflow/go/op"
+)
+
+// backend TensorFlow SavedModel 또는 frozen graph 실행 엔진
+type backend struct {
+	tfModel *tf.SavedModel
+	cfg     modelConfig
+	// 전처리 graph 저장 경로 (빈 값이면 저장하지 않음)
+	graphCachePath string
+
+	imageDecoder map[string]imageDecode
+	mutex        sync.RWMutex
+
+	// 동시 실행 수 제한
+	runs runLimiter
+}
+
+// 모델 로드시 미리 생성하는 디코더의 형식
+//
+// 대부분의 요청이 사용하는 형식으로, 첫 요청에서 graph를 생성하는 지연을 없앰
+var eagerDecoderFormats = []string{"jpeg", "png"}
+
+// 이미지 타입의 디코더
+type imageDecode struct {
+	graph   *tf.Graph
+	session *tf.Session
+	input   tf.Output
+	output  tf.Output
+}
+
+// run 이미지 tensor를 전처리하여 모델 입력 tensor 반환
+func (d imageDecode) run(image *tf.Tensor) (*tf.Tensor, error) {
+	norms, err := d.session.Run(
+		map[tf.Output]*tf.Tensor{
+			d.input: image,
+		},
+		[]tf.Output{
+			d.output,
+		},
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return norms[0], nil
+}
+
+func openBackend(modelPath string, cfg modelConfig, graphCachePath string) (*backend, error) {
+	var (
+		tfModel *tf.SavedModel
+		err     error
+	)
+	if cfg.modelFormat() == formatFrozenGraph {
+		tfModel, err = loadFrozenGraph(filepath.Join(modelPath, frozenGraphFile))
+	} else {
+		tfModel, err = tf.LoadSavedModel(modelPath, cfg.Tags, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// config의 operation 이름이 graph에 없으면 추론시 panic이 발생하므로 로드시 확인
+	var violations []string
+	ops := append([]string{cfg.InputOperationName}, cfg.outputOperations()...)
+	for _, name := range append(ops, cfg.auxOperations()...) {
+		if _, ok := graphOutput(tfModel.Graph, name); !ok {
+			violations = append(violations, fmt.Sprintf("Operation is not in the graph: %s", name))
+		}
+	}
+	if len(violations) > 0 {
+		tfModel.Session.Close()
+		return nil, &ConfigError{File: filepath.Join(modelPath, configFile), Violations: violations}
+	}
+
+	b := &backend{
+		tfModel:        tfModel,
+		cfg:            cfg,
+		graphCachePath: graphCachePath,
+		imageDecoder:   make(map[string]imageDecode),
+		runs:           newRunLimiter(cfg.MaxConcurrentRuns),
+	}
+	if err := b.preloadImageDecoders(); err != nil {
+		b.close(modelPath)
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// loadFrozenGraph frozen graph(GraphDef) 파일을 import한 graph와 session을 SavedModel처럼 사용
+//
+// 변수가 모두 상수로 바뀐 graph이므로 복원할 variables가 없음
+func loadFrozenGraph(graphFile string) (*tf.SavedModel, error) {
+	def, err := ioutil.ReadFile(graphFile)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := tf.NewGraph()
+	if err := graph.Import(def, ""); err != nil {
+		return nil, fmt.Errorf("Fail to import frozen graph(%s): %s", graphFile, err)
+	}
+
+	session, err := tf.NewSession(graph, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tf.SavedModel{Session: session, Graph: graph}, nil
+}
+
+// runImages 이미지들을 전처리하여 하나의 batch로 모델을 실행하고 이미지별 출력 반환
+//
+// 이미지별 출력은 TTA를 사용하면 원본과 변형 이미지별 출력
+func (b *backend) runImages(ctx context.Context, inputs []imageInput) ([][][]float32, error) {
+	var (
+		batch *tf.Tensor
+		buf   bytes.Buffer
+		shape []int64
+	)
+
+	for idx, input := range inputs {
+		// TF session 실행은 중단할 수 없기 때문에, 각 단계에 들어가기 전에 취소 여부를 확인
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		norm, err := b.normInputImage(ctx, input)
+		if err != nil {
+			return nil, batchImageError(inputs, idx, err)
+		}
+		if len(inputs) == 1 {
+			batch = norm
+			break
+		}
+
+		// 전처리 된 [variants, ...] tensor를 batch 차원으로 이어 붙임
+		if shape == nil {
+			shape = norm.Shape()
+		} else {
+			shape[0] += norm.Shape()[0]
+		}
+		if _, err := norm.WriteContentsTo(&buf); err != nil {
+			return nil, err
+		}
+		if idx == len(inputs)-1 {
+			if batch, err = tf.ReadTensor(norm.DataType(), shape, &buf); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	outputs, err := b.runInput(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+
+	variants := len(outputs) / len(inputs)
+	results := make([][][]float32, len(inputs))
+	for idx := range results {
+		results[idx] = outputs[idx*variants : (idx+1)*variants]
+	}
+
+	return results, nil
+}
+
+// runTensor 전처리 된 입력(checkTensor로 검사 된 값)으로 모델 실행
+func (b *backend) runTensor(ctx context.Context, data []float32) ([]float32, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	input, err := tf.NewTensor(inputTensorValue(data, b.cfg.tensorShape(), b.cfg.inputDType()))
+	if err != nil {
+		return nil, err
+	}
+
+	outputs, err := b.runInput(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return outputs[0], nil
+}
+
+// runInput 모델 입력 tensor로 모델 실행하여 batch의 이미지별 출력 반환
+func (b *backend) runInput(ctx context.Context, input *tf.Tensor) ([][]float32, error) {
+	results, err := b.runOutputs(ctx, input, b.cfg.outputOperations())
+	if err != nil {
+		return nil, err
+	}
+
+	return results[0].Value().([][]float32), nil
+}
+
+// runDetection 이미지를 전처리하여 detection 모델을 실행하고 검출 결과 반환
+func (b *backend) runDetection(ctx context.Context, input imageInput) ([]rawDetection, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	norm, err := b.normInputImage(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := b.runOutputs(ctx, norm, b.cfg.outputOperations())
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(results))
+	for idx, result := range results {
+		values[idx] = result.Value()
+	}
+
+	return parseDetections(values)
+}
+
+// runSegmentation 이미지를 전처리하여 segmentation 모델을 실행하고 픽셀별 클래스 반환
+func (b *backend) runSegmentation(ctx context.Context, input imageInput, threshold float32) (*segmentMap, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	norm, err := b.normInputImage(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := b.runOutputs(ctx, norm, b.cfg.outputOperations())
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSegmentation(results[0].Value(), b.cfg.OutputActivation, threshold)
+}
+
+// runEmbedding 이미지를 전처리하여 모델을 실행하고 embedding 출력 반환
+func (b *backend) runEmbedding(ctx context.Context, input imageInput) ([]float32, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	norm, err := b.normInputImage(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := b.runOutputs(ctx, norm, []string{b.cfg.EmbeddingOperationName})
+	if err != nil {
+		return nil, err
+	}
+
+	return meanEmbedding(results[0].Value())
+}
+
+// runCAM 이미지를 전처리하여 모델을 실행하고 class의 conv layer 출력과 gradient 반환
+//
+// camGradientOperationName이 없으면 gradient는 nil
+func (b *backend) runCAM(ctx context.Context, input imageInput, class int) ([][][]float32, [][][]float32, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	norm, err := b.normInputImage(ctx, input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	inputOp, _ := graphOutput(b.tfModel.Graph, b.cfg.InputOperationName)
+	feeds := map[tf.Output]*tf.Tensor{
+		inputOp: norm,
+	}
+	if b.cfg.CAMClassOperationName != "" {
+		classOp, _ := graphOutput(b.tfModel.Graph, b.cfg.CAMClassOperationName)
+		classTensor, err := tf.NewTensor([]int32{int32(class)})
+		if err != nil {
+			return nil, nil, err
+		}
+		feeds[classOp] = classTensor
+	}
+
+	var fetches []tf.Output
+	for _, name := range b.cfg.camOperations() {
+		output, _ := graphOutput(b.tfModel.Graph, name)
+		fetches = append(fetches, output)
+	}
+
+	release, err := b.runs.acquire(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	stop := timeStage(ctx, stageRun)
+	results, err := b.tfModel.Session.Run(feeds, fetches, nil)
+	stop()
+	release()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	features, err := parseFeatureMap(results[0].Value())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var gradients [][][]float32
+	if len(results) > 1 {
+		if gradients, err = parseFeatureMap(results[1].Value()); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return features, gradients, nil
+}
+
+// runOutputs 모델 입력 tensor로 모델을 실행하여 names 출력 operation 순서로 출력 반환
+func (b *backend) runOutputs(ctx context.Context, input *tf.Tensor, names []string) ([]*tf.Tensor, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	inputOp, _ := graphOutput(b.tfModel.Graph, b.cfg.InputOperationName)
+
+	var fetches []tf.Output
+	for _, name := range names {
+		output, _ := graphOutput(b.tfModel.Graph, name)
+		fetches = append(fetches, output)
+	}
+
+	release, err := b.runs.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	defer timeStage(ctx, stageRun)()
+	return b.tfModel.Session.Run(
+		map[tf.Output]*tf.Tensor{
+			inputOp: input,
+		},
+		fetches,
+		nil,
+	)
+}
+
+// graphOutput `<operation>` 또는 `<operation>:<출력 index>` 이름의 operation 출력
+func graphOutput(graph *tf.Graph, name string) (tf.Output, bool) {
+	opName, idx := inputOpName(name)
+
+	op := graph.Operation(opName)
+	if op == nil || idx >= op.NumOutputs() {
+		return tf.Output{}, false
+	}
+
+	return op.Output(idx), true
+}
+
+// inputTensorValue 펼친 입력값을 tf.NewTensor에 전달할 [1][d0][d1][d2] slice로 변환
+func inputTensorValue(data []float32, shape []int, dtype string) interface{} {
+	d0, d1, d2 := shape[0], shape[1], shape[2]
+
+	if dtype == inputUint8 {
+		value := make([][][]uint8, d0)
+		for i := range value {
+			value[i] = make([][]uint8, d1)
+			for j := range value[i] {
+				value[i][j] = make([]uint8, d2)
+				for k := range value[i][j] {
+					value[i][j][k] = uint8(data[(i*d1+j)*d2+k])
+				}
+			}
+		}
+		return [][][][]uint8{value}
+	}
+
+	value := make([][][]float32, d0)
+	for i := range value {
+		value[i] = make([][]float32, d1)
+		for j := range value[i] {
+			value[i][j] = data[(i*d1+j)*d2 : (i*d1+j+1)*d2]
+		}
+	}
+
+	return [][][][]float32{value}
+}
+
+// normInputImage 이미지를 전처리 graph로 디코딩하고 정규화하여 [variants, ...] 모델 입력 tensor 반환
+func (b *backend) normInputImage(ctx context.Context, input imageInput) (*tf.Tensor, error) {
+	defer timeStage(ctx, stagePreprocess)()
+
+	var (
+		decoder     imageDecode
+		imageTensor *tf.Tensor
+		err         error
+	)
+
+	format := input.format
+	pix, h, w := input.pix, input.height, input.width
+	if pix == nil && goDecodedFormats[format] {
+		// TensorFlow에 디코더가 없는 형식은 Go에서 디코딩한 픽셀을 전달
+		if pix, h, w, err = decodePixels(input.image, format, b.cfg.alphaBackground()); err != nil {
+			return nil, err
+		}
+	}
+	if pix != nil {
+		format = preprocessRaw
+	}
+
+	if decoder, err = b.getImageDecoder(format, input.orientation); err != nil {
+		return nil, err
+	}
+
+	if pix != nil {
+		imageTensor, err = tf.ReadTensor(tf.Uint8, []int64{int64(h), int64(w), 3}, bytes.NewReader(pix))
+	} else {
+		imageTensor, err = tf.NewTensor(string(input.image))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return decoder.run(imageTensor)
+}
+
+func (b *backend) getImageDecoder(format string, orientation int) (imageDecode, error) {
+	var (
+		decoder imageDecode
+		ok      bool
+		err     error
+	)
+
+	// Go에서 디코딩한 픽셀은 형식과 관계없이 preprocessRaw graph를 사용
+	if format != preprocessRaw {
+		if format, err = preprocessFormat(format); err != nil {
+			return decoder, err
+		}
+		format = preprocessGraphFormat(format)
+	}
+	name := orientedGraphFormat(format, orientation)
+
+	// 생성 된 디코더는 공용으로 사용되기 때문에,
+	// 조회는 read lock으로 하고 최초 생성시에만 lock을 잡음
+	b.mutex.RLock()
+	decoder, ok = b.imageDecoder[name]
+	b.mutex.RUnlock()
+	if ok {
+		return decoder, nil
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	decoder, ok = b.imageDecoder[name]
+	if ok {
+		return decoder, nil
+	}
+
+	if decoder, err = b.newImageDecoder(format, orientation, true); err != nil {
+		return decoder, err
+	}
+	b.imageDecoder[name] = decoder
+
+	return decoder, nil
+}
+
+// preloadImageDecoders 모델 로드시 디코더를 미리 생성
+//
+// eagerDecoderFormats는 저장 된 graph가 없으면 새로 생성하며 실패하면 에러를 반환하고,
+// 나머지 형식은 저장 된 전처리 graph가 있는 경우에만 생성
+func (b *backend) preloadImageDecoders() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for _, format := range eagerDecoderFormats {
+		decoder, err := b.newImageDecoder(format, 1, true)
+		if err != nil {
+			return fmt.Errorf("Fail to build %s image decoder: %w", format, err)
+		}
+		b.imageDecoder[format] = decoder
+	}
+
+	for _, format := range []string{"bmp", preprocessRaw} {
+		file := preprocessGraphFile(b.graphCachePath, format, &b.cfg)
+		if file == "" || !isFile(file) {
+			continue
+		}
+
+		decoder, err := b.newImageDecoder(format, 1, false)
+		if err != nil {
+			log.Printf("Fail to preload %s image decoder: %s", format, err)
+			continue
+		}
+		b.imageDecoder[format] = decoder
+	}
+
+	return nil
+}
+
+// newImageDecoder 저장 된 전처리 graph로 디코더 생성
+//
+// 저장 된 graph가 없거나 읽을 수 없으면 build가 true인 경우에만 새로 생성하여 저장
+func (b *backend) newImageDecoder(format string, orientation int, build bool) (imageDecode, error) {
+	var (
+		decoder imageDecode
+		graph   *tf.Graph
+		session *tf.Session
+		err     error
+	)
+
+	file := preprocessGraphFile(b.graphCachePath, orientedGraphFormat(format, orientation), &b.cfg)
+	if graph, err = loadPreprocessGraph(file); err != nil {
+		if !build {
+			return decoder, err
+		}
+		log.Printf("Rebuild preprocessing graph(%s): %s", file, err)
+	}
+
+	if graph == nil {
+		if !build {
+			return decoder, fmt.Errorf("No preprocessing graph: %s", file)
+		}
+		if graph, err = buildPreprocessGraph(format, orientation, &b.cfg); err != nil {
+			return decoder, err
+		}
+		if file != "" {
+			if err := savePreprocessGraph(file, graph); err != nil {
+				log.Printf("Fail to save preprocessing graph(%s): %s", file, err)
+			}
+		}
+	}
+
+	if session, err = tf.NewSession(graph, nil); err != nil {
+		return decoder, err
+	}
+
+	decoder = imageDecode{
+		graph:   graph,
+		input:   graph.Operation(preprocessInputOp).Output(0),
+		output:  graph.Operation(preprocessOutputOp).Output(0),
+		session: session,
+	}
+
+	return decoder, nil
+}
+
+// buildPreprocessGraph 이미지 디코딩과 정규화 graph 생성
+//
+// orientation이 EXIF 회전/반전 값이면 디코딩 된 이미지를 바로 세운 후 크기를 조정.
+// 입력 크기와 채널 수(1이면 grayscale로 변환), 정규화, 크기 조정 방식은 모델 config를 따름
+func buildPreprocessGraph(format string, orientation int, cfg *modelConfig) (*tf.Graph, error) {
+	var (
+		decode tf.Output
+		// 디코더가 grayscale로 디코딩 할 수 없어 RGB를 변환해야 하는지 여부
+		rgb bool
+		// 배경색 위에 합성할 RGBA로 디코딩 했는지 여부
+		rgba bool
+	)
+
+	scope := op.NewScope()
+	inputShape := cfg.InputShape
+	channels := int64(inputShape[2])
+	norm := cfg.normalization()
+
+	switch format {
+	case "jpeg":
+		input := op.Placeholder(scope.SubScope("input"), tf.String)
+		decode = op.DecodeJpeg(scope, input, op.DecodeJpegChannels(channels))
+	case "png":
+		input := op.Placeholder(scope.SubScope("input"), tf.String)
+		if cfg.alphaBackground() != nil {
+			// alpha 채널이 없는 이미지는 불투명(255)한 alpha 채널이 추가 됨
+			decode = op.DecodePng(scope, input, op.DecodePngChannels(4))
+			rgba = true
+		} else {
+			decode = op.DecodePng(scope, input, op.DecodePngChannels(channels))
+		}
+	case "bmp":
+		// DecodeBmp는 1채널 디코딩을 지원하지 않음
+		input := op.Placeholder(scope.SubScope("input"), tf.String)
+		decode = op.DecodeBmp(scope, input, op.DecodeBmpChannels(3))
+		rgb = true
+	case preprocessRaw:
+		// Go에서 디코딩 된 [height, width, 3] RGB 픽셀
+		decode = op.Placeholder(scope.SubScope("input"), tf.Uint8, op.PlaceholderShape(tf.MakeShape(-1, -1, 3)))
+		rgb = true
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+
+	if ops, ok := exifOrientations[orientation]; ok {
+		if ops.transpose {
+			decode = op.Transpose(scope, decode, op.Const(scope.SubScope("perm"), []int32{1, 0, 2}))
+		}
+		if len(ops.reverse) > 0 {
+			decode = op.ReverseV2(scope, decode, op.Const(scope.SubScope("axis"), ops.reverse))
+		}
+	}
+
+	image := op.Cast(scope, decode, tf.Float)
+	if rgba {
+		// color * alpha + background * (1 - alpha), alpha는 [0, 1]
+		alpha := op.Div(scope,
+			op.Slice(scope, image, op.Const(scope.SubScope("alphaBegin"), []int32{0, 0, 3}), op.Const(scope.SubScope("alphaSize"), []int32{-1, -1, 1})),
+			op.Const(scope.SubScope("alphaMax"), float32(255)))
+		color := op.Slice(scope, image, op.Const(scope.SubScope("colorBegin"), []int32{0, 0, 0}), op.Const(scope.SubScope("colorSize"), []int32{-1, -1, 3}))
+		image = op.Add(scope,
+			op.Mul(scope, color, alpha),
+			op.Mul(scope,
+				channelConst(scope.SubScope("background"), cfg.alphaBackground()),
+				op.Sub(scope, op.Const(scope.SubScope("opaque"), float32(1)), alpha)))
+		rgb = true
+	}
+	if rgb && channels == 1 {
+		// tf.image.rgb_to_grayscale과 같은 ITU-R BT.601 가중치
+		image = op.Sum(scope,
+			op.Mul(scope, image, op.Const(scope.SubScope("grayscale"), []float32{0.2989, 0.587, 0.114})),
+			op.Const(scope.SubScope("channel"), int32(-1)),
+			op.SumKeepDims(true))
+	}
+
+	// (image - mean) / std, 채널별 값은 마지막 차원(channels)으로 broadcast
+	// uint8 입력 모델은 [0, 255]의 값을 그대로 사용
+	normalizer := image
+	if cfg.inputDType() != inputUint8 {
+		normalizer = op.Div(scope,
+			op.Sub(scope, image, channelConst(scope.SubScope("mean"), norm.mean)),
+			channelConst(scope.SubScope("std"), norm.std))
+	}
+
+	// 임의의 크기(height, width) 이미지를 입력 크기(inputShape[:2])로 조정
+	batch := op.ExpandDims(scope, normalizer, op.Const(scope.SubScope("batch"), int32(0)))
+	size := op.Const(scope.SubScope("resize"), inputShape[:2])
+
+	var resize tf.Output
+	switch resizeMethod := cfg.resizeMethod(); {
+	case cfg.MultiCrop > 0:
+		// 이미지의 가운데와 네 모서리를 잘라 입력 크기로 조정한 batch (10-crop은 좌우 반전을 추가)
+		resize = op.CropAndResize(scope, batch,
+			op.Const(scope.SubScope("cropBoxes"), multiCropBoxes),
+			op.Const(scope.SubScope("cropIndex"), make([]int32, len(multiCropBoxes))),
+			size,
+			op.CropAndResizeMethod(resizeMethod))
+		if cfg.MultiCrop == multiCrop10 {
+			resize = op.ConcatV2(scope,
+				[]tf.Output{resize, op.ReverseV2(scope, resize, op.Const(scope.SubScope("flip"), []int32{2}))},
+				op.Const(scope.SubScope("crops"), int32(0)))
+		}
+	case cfg.resizeMode() == resizeCenterCrop, cfg.resizeMode() == resizeLetterbox:
+		resize = op.CropAndResize(scope, batch,
+			aspectBox(scope.SubScope("box"), normalizer, inputShape, cfg.resizeMode()),
+			op.Const(scope.SubScope("boxIndex"), []int32{0}),
+			size,
+			op.CropAndResizeMethod(resizeMethod),
+			op.CropAndResizeExtrapolationValue(0))
+	default:
+		switch resizeMethod {
+		case resizeBicubic:
+			resize = op.ResizeBicubic(scope, batch, size)
+		case resizeNearest:
+			resize = op.ResizeNearestNeighbor(scope, batch, size)
+		case resizeArea:
+			resize = op.ResizeArea(scope, batch, size)
+		default:
+			resize = op.ResizeBilinear(scope, batch, size)
+		}
+	}
+
+	// test-time augmentation: 원본과 변형 이미지를 하나의 batch로 모델에 전달
+	if len(cfg.TTA) > 0 {
+		cropMethod := cfg.resizeMethod()
+		if !cropResizeMethods[cropMethod] {
+			cropMethod = resizeBilinear
+		}
+
+		variants := []tf.Output{resize}
+		for _, a := range cfg.TTA {
+			switch a {
+			case ttaFlip:
+				variants = append(variants, op.ReverseV2(scope, resize, op.Const(scope.SubScope("flip"), []int32{2})))
+			case ttaCrop:
+				variants = append(variants, op.CropAndResize(scope, resize,
+					op.Const(scope.SubScope("cropBoxes"), ttaCropBoxes),
+					op.Const(scope.SubScope("cropIndex"), make([]int32, len(ttaCropBoxes))),
+					size,
+					op.CropAndResizeMethod(cropMethod)))
+			}
+		}
+		resize = op.ConcatV2(scope, variants, op.Const(scope.SubScope("variants"), int32(0)))
+	}
+
+	// 크기 조정 결과는 float이므로 반올림 후 uint8로 변환 (bicubic은 범위를 벗어날 수 있음)
+	if cfg.inputDType() == inputUint8 {
+		resize = op.Cast(scope,
+			op.ClipByValue(scope, op.Round(scope, resize),
+				op.Const(scope.SubScope("min"), float32(0)), op.Const(scope.SubScope("max"), float32(255))),
+			tf.Uint8)
+	}
+
+	// [1, height, width, channels]를 모델 입력 layout으로 변환
+	if cfg.inputLayout() == layoutNCHW {
+		resize = op.Transpose(scope, resize, op.Const(scope.SubScope("layout"), []int32{0, 3, 1, 2}))
+	}
+
+	// 저장 된 graph에서 찾을 수 있도록 이름이 정해진 출력 operation
+	op.Identity(scope.SubScope("output"), resize)
+
+	return scope.Finalize()
+}
+
+// aspectBox 이미지 비율을 유지하여 입력 크기로 조정할 영역 [[y1, x1, y2, x2]] (이미지 크기 기준 0 ~ 1)
+//
+// a = (width / height) / (입력 width / 입력 height)일 때 영역의 높이와 너비 비율은
+// center-crop이면 (min(1, a), min(1, 1/a)), letterbox면 (max(1, a), max(1, 1/a))이며,
+// letterbox에서 이미지 밖의 영역은 CropAndResize의 extrapolation 값으로 채워짐
+func aspectBox(scope *op.Scope, image tf.Output, inputShape []int32, resizeMode string) tf.Output {
+	dims := op.Unpack(scope, op.Cast(scope, op.Shape(scope, image), tf.Float), 3)
+	aspect := op.Mul(scope,
+		op.Div(scope, dims[1], dims[0]),
+		op.Const(scope.SubScope("inputAspect"), float32(inputShape[0])/float32(inputShape[1])))
+
+	one := op.Const(scope.SubScope("one"), float32(1))
+	height, width := aspect, op.Reciprocal(scope, aspect)
+	if resizeMode == resizeCenterCrop {
+		height, width = op.Minimum(scope, one, height), op.Minimum(scope, one, width)
+	} else {
+		height, width = op.Maximum(scope, one, height), op.Maximum(scope, one, width)
+	}
+
+	// 가운데 기준: 0.5 -+ 비율 / 2
+	center := op.Const(scope.SubScope("center"), float32(0.5))
+	half := op.Const(scope.SubScope("half"), float32(0.5))
+	halfHeight, halfWidth := op.Mul(scope, height, half), op.Mul(scope, width, half)
+
+	box := op.Pack(scope, []tf.Output{
+		op.Sub(scope, center, halfHeight),
+		op.Sub(scope, center, halfWidth),
+		op.Add(scope, center, halfHeight),
+		op.Add(scope, center, halfWidth),
+	})
+
+	return op.ExpandDims(scope, box, op.Const(scope.SubScope("boxes"), int32(0)))
+}
+
+// channelConst 값이 하나면 scalar, 여러 개면 채널별 상수
+func channelConst(scope *op.Scope, values channelValues) tf.Output {
+	if len(values) == 1 {
+		return op.Const(scope, values[0])
+	}
+
+	return op.Const(scope, []float32(values))
+}
+
+// loadPreprocessGraph 저장 된 전처리 graph 반환 (저장 된 graph가 없으면 nil)
+func loadPreprocessGraph(file string) (*tf.Graph, error) {
+	if file == "" {
+		return nil, nil
+	}
+
+	def, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	graph := tf.NewGraph()
+	if err := graph.Import(def, ""); err != nil {
+		return nil, err
+	}
+
+	for _, name := range []string{preprocessInputOp, preprocessOutputOp} {
+		if graph.Operation(name) == nil {
+			return nil, fmt.Errorf("No operation %s in %s", name, file)
+		}
+	}
+
+	return graph, nil
+}
+
+// savePreprocessGraph 전처리 graph를 GraphDef로 저장
+//
+// 여러 인스턴스가 동시에 저장해도 완성 된 파일만 보이도록 임시 파일에 쓴 후 rename
+func savePreprocessGraph(file string, graph *tf.Graph) error {
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := graph.WriteTo(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), file)
+}
+
+// graph 모델 graph의 operation 목록 반환
+//
+// TF Go API는 operation의 입력을 제공하지 않으므로 각 출력의 consumer로 연결을 구성
+func (b *backend) graph() []GraphOp {
+	tfOps := b.tfModel.Graph.Operations()
+
+	inputs := make(map[string][]string, len(tfOps))
+	for idx := range tfOps {
+		tfOp := &tfOps[idx]
+		if n := tfOp.NumInputs(); n > 0 {
+			inputs[tfOp.Name()] = make([]string, n)
+		}
+	}
+
+	ops := make([]GraphOp, 0, len(tfOps))
+	for idx := range tfOps {
+		tfOp := &tfOps[idx]
+		op := GraphOp{
+			Name: tfOp.Name(),
+			Type: tfOp.Type(),
+		}
+
+		for n := 0; n < tfOp.NumOutputs(); n++ {
+			output := tfOp.Output(n)
+			op.Outputs = append(op.Outputs, GraphTensor{
+				DataType: dataTypeName(output.DataType()),
+				Shape:    shapeDims(output.Shape()),
+			})
+
+			name := op.Name
+			if n > 0 {
+				name = fmt.Sprintf("%s:%d", op.Name, n)
+			}
+			for _, c := range output.Consumers() {
+				if in := inputs[c.Op.Name()]; c.Index < len(in) {
+					in[c.Index] = name
+				}
+			}
+		}
+
+		ops = append(ops, op)
+	}
+
+	for idx := range ops {
+		ops[idx].Inputs = inputs[ops[idx].Name]
+	}
+
+	return ops
+}
+
+// shapeDims tf.Shape을 차원 목록으로 변환 (rank를 알 수 없으면 nil)
+func shapeDims(shape tf.Shape) []int64 {
+	if shape.NumDimensions() < 0 {
+		return nil
+	}
+
+	dims, err := shape.ToSlice()
+	if err != nil {
+		return nil
+	}
+
+	return dims
+}
+
+func dataTypeName(dt tf.DataType) string {
+	switch dt {
+	case tf.Float:
+		return "float32"
+	case tf.Double:
+		return "float64"
+	case tf.Int32:
+		return "int32"
+	case tf.Int64:
+		return "int64"
+	case tf.Uint8:
+		return "uint8"
+	case tf.String:
+		return "string"
+	case tf.Bool:
+		return "bool"
+	}
+
+	return fmt.Sprintf("dtype(%d)", dt)
+}
+
+func (b *backend) close(name string) {
+	b.mutex.Lock()
+	for format, decoder := range b.imageDecoder {
+		if err := decoder.session.Close(); err != nil {
+			log.Printf("%s image decoder session close failed: %s", format, err)
+		} else {
+			log.Printf("%s image decoder session successfully closed", format)
+		}
+	}
+	b.mutex.Unlock()
+
+	if err := b.tfModel.Session.Close(); err != nil {
+		log.Printf("%s model session close failed: %s", name, err)
+	} else {
+		log.Printf("%s model session successfully closed", name)
+	}
+}
@@ -0,0 +1,108 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+)
+
+// imageInput 형식을 확인하고 손상 여부를 검사한, 모델로 실행할 이미지
+type imageInput struct {
+	image  []byte
+	format string
+	// JPEG의 EXIF orientation (다른 형식은 1)
+	orientation int
+
+	// preDownscale로 줄인 [height, width, 3] RGB 픽셀 (줄이지 않았으면 nil)
+	pix           []byte
+	height, width int
+}
+
+// prepareImage Hook의 PreProcess 이후의 이미지를 모델로 실행할 수 있도록 확인
+//
+// HEIC는 JPEG로 변환하고, 형식과 손상 여부를 확인한 후 preDownscale 크기보다 크면 줄임
+func (m *iModel) prepareImage(ctx context.Context, image []byte, format string) (imageInput, error) {
+	defer timeStage(ctx, stagePreprocess)()
+
+	var (
+		input imageInput
+		err   error
+	)
+
+	if image, format, err = m.convertHEIC(ctx, image, format); err != nil {
+		return input, err
+	}
+	if format, err = resolveFormat(image, format); err != nil {
+		return input, err
+	}
+	if err = checkImage(image, format, m.probeDecode); err != nil {
+		return input, err
+	}
+
+	input = imageInput{image: image, format: format, orientation: 1}
+	// 모바일 사진의 EXIF 회전 정보는 DecodeJpeg가 반영하지 않으므로 전처리 graph에서 바로 세움
+	if format == "jpeg" {
+		input.orientation = jpegOrientation(image)
+	}
+
+	if input.pix, input.height, input.width, err = m.downscale(image, format, input.orientation); err != nil {
+		return input, err
+	}
+
+	return input, nil
+}
+
+// batchImageError batch의 idx 번째 이미지의 에러 (이미지가 하나면 그대로 반환)
+func batchImageError(inputs []imageInput, idx int, err error) error {
+	if len(inputs) == 1 {
+		return err
+	}
+
+	return fmt.Errorf("Image %d: %w", idx, err)
+}
+
+// inferBatch 이미지들을 하나의 batch로 모델 실행하여 이미지별 추론 결과 반환
+//
+// Preprocessor를 사용하는 모델은 이미지별로 실행
+func (m *iModel) inferBatch(ctx context.Context, images [][]byte, format string, k int, threshold float32) ([][]InferLabel, error) {
+	if err := m.checkClassifier(); err != nil {
+		return nil, err
+	}
+
+	results := make([][]InferLabel, len(images))
+	if m.preprocessor != nil {
+		for idx, image := range images {
+			infers, err := m.infer(ctx, image, format, k, threshold)
+			if err != nil {
+				return nil, fmt.Errorf("Image %d: %w", idx, err)
+			}
+			results[idx] = infers
+		}
+		return results, nil
+	}
+
+	inputs := make([]imageInput, len(images))
+	for idx, image := range images {
+		image, f, err := m.preProcess(ctx, image, format)
+		if err == nil {
+			inputs[idx], err = m.prepareImage(ctx, image, f)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Image %d: %w", idx, err)
+		}
+	}
+
+	outputs, err := m.backend.runImages(ctx, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx, output := range outputs {
+		infers, err := m.classify(ctx, output, k, threshold)
+		if err != nil {
+			return nil, fmt.Errorf("Image %d: %w", idx, err)
+		}
+		results[idx] = infers
+	}
+
+	return results, nil
+}
@@ -0,0 +1,185 @@
+package inference
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// checksumFile 모델 버전 파일의 SHA-256을 저장하는 파일
+//
+//	{
+//	  "algorithm": "sha256",
+//	  "files": {"saved_model.pb": "...", "variables/variables.index": "...", "config.yaml": "...", "labels.txt": "..."}
+//	}
+const checksumFile = "checksums.json"
+
+const checksumAlgorithm = "sha256"
+
+// modelChecksums 모델 버전 디렉토리에 대한 상대 경로별 checksum
+type modelChecksums struct {
+	Algorithm string            `json:"algorithm"`
+	Files     map[string]string `json:"files"`
+}
+
+// checksumTargets checksum을 구할 모델(SavedModel, frozen graph 또는 TensorFlow Lite), config와 labels 파일 (모델 버전 디렉토리에 대한 상대 경로)
+func checksumTargets(vPath string, cfg modelConfig) ([]string, error) {
+	var files []string
+	for _, file := range modelFiles {
+		root := filepath.Join(vPath, file)
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			continue
+		}
+
+		err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+
+			rel, err := filepath.Rel(vPath, p)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(rel))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	files = append(files, configFile)
+	if cfg.LabelsFile != "" {
+		files = append(files, filepath.ToSlash(cfg.LabelsFile))
+	}
+	for _, file := range cfg.LocalizedLabelsFiles {
+		files = append(files, filepath.ToSlash(file))
+	}
+
+	return files, nil
+}
+
+// fileChecksum 파일의 SHA-256 (16진수)
+func fileChecksum(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeChecksums 모델 버전의 SavedModel, config와 labels 파일의 checksum 저장
+//
+// 생성, 가져오기, 복제가 끝난 모델을 로드한 후 호출하며 이후 로드할 때마다 verifyChecksums로 확인
+func writeChecksums(vPath string, cfg modelConfig) error {
+	files, err := checksumTargets(vPath, cfg)
+	if err != nil {
+		return err
+	}
+
+	checksums := modelChecksums{
+		Algorithm: checksumAlgorithm,
+		Files:     make(map[string]string, len(files)),
+	}
+	for _, file := range files {
+		if checksums.Files[file], err = fileChecksum(filepath.Join(vPath, filepath.FromSlash(file))); err != nil {
+			return err
+		}
+	}
+
+	return saveChecksums(vPath, checksums)
+}
+
+func saveChecksums(vPath string, checksums modelChecksums) error {
+	b, err := json.MarshalIndent(checksums, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(filepath.Join(vPath, checksumFile), b)
+}
+
+// readChecksums checksum 파일이 없으면 nil 반환
+func readChecksums(vPath string) (*modelChecksums, error) {
+	b, err := ioutil.ReadFile(filepath.Join(vPath, checksumFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var checksums modelChecksums
+	if err := json.Unmarshal(b, &checksums); err != nil {
+		return nil, fmt.Errorf("Fail to read %s: %s", checksumFile, err)
+	}
+	if checksums.Algorithm != checksumAlgorithm {
+		return nil, fmt.Errorf("Unknown checksum algorithm(%s): %s", checksums.Algorithm, vPath)
+	}
+	if checksums.Files == nil {
+		checksums.Files = map[string]string{}
+	}
+
+	return &checksums, nil
+}
+
+// verifyChecksums 저장 된 checksum과 모델 버전 파일을 비교하여 다르거나 없는 파일이 있으면 ChecksumError 반환
+//
+// checksum 파일이 없는 모델(이전에 생성했거나 직접 복사한 모델)은 확인하지 않음
+func verifyChecksums(vPath string) error {
+	checksums, err := readChecksums(vPath)
+	if err != nil || checksums == nil {
+		return err
+	}
+
+	files := make([]string, 0, len(checksums.Files))
+	for file := range checksums.Files {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		expected := checksums.Files[file]
+		actual, err := fileChecksum(filepath.Join(vPath, filepath.FromSlash(file)))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if actual != expected {
+			return &ChecksumError{File: file, Expected: expected, Actual: actual}
+		}
+	}
+
+	return nil
+}
+
+// updateChecksum 서버가 다시 쓴 파일(예: config 변경)의 checksum만 바꿈
+//
+// checksum 파일이 없으면 아무것도 하지 않음
+func updateChecksum(vPath, file string) error {
+	checksums, err := readChecksums(vPath)
+	if err != nil || checksums == nil {
+		return err
+	}
+
+	if checksums.Files[file], err = fileChecksum(filepath.Join(vPath, filepath.FromSlash(file))); err != nil {
+		return err
+	}
+
+	return saveChecksums(vPath, *checksums)
+}
@@ -0,0 +1,101 @@
+package inference
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestChecksums(t *testing.T) {
+	vPath, err := ioutil.TempDir("", "checksum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(vPath)
+
+	files := map[string]string{
+		"saved_model.pb":                      "graph",
+		"variables/variables.index":           "index",
+		"variables/variables.data-00000-of-1": "data",
+		"config.yaml":                         "name: pets\nlabelsFile: labels.txt\n",
+		"labels.txt":                          "cat\ndog\n",
+	}
+	for file, content := range files {
+		p := filepath.Join(vPath, filepath.FromSlash(file))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// checksum 파일이 없으면 확인하지 않음
+	if err := verifyChecksums(vPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeChecksums(vPath, modelConfig{Name: "pets", LabelsFile: "labels.txt"}); err != nil {
+		t.Fatal(err)
+	}
+	checksums, err := readChecksums(vPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checksums.Files) != len(files) {
+		t.Fatalf("Unexpected checksums: %v", checksums.Files)
+	}
+	if err := verifyChecksums(vPath); err != nil {
+		t.Fatal(err)
+	}
+
+	// 서버가 바꾼 config는 checksum도 바뀜
+	if err := updateConfig(vPath, yaml.MapSlice{{Key: "description", Value: "pets"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyChecksums(vPath); err != nil {
+		t.Fatal(err)
+	}
+
+	ioutil.WriteFile(filepath.Join(vPath, "labels.txt"), []byte("cat\nfox\n"), 0644)
+	var checksumErr *ChecksumError
+	if err := verifyChecksums(vPath); !errors.As(err, &checksumErr) || checksumErr.File != "labels.txt" || !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ioutil.WriteFile(filepath.Join(vPath, "labels.txt"), []byte(files["labels.txt"]), 0644)
+
+	os.Remove(filepath.Join(vPath, "variables", "variables.index"))
+	if err := verifyChecksums(vPath); !errors.As(err, &checksumErr) || checksumErr.File != "variables/variables.index" || checksumErr.Actual != "" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestLoadModelsChecksumMismatch(t *testing.T) {
+	modelsPath := newModelsPath(t)
+	defer os.RemoveAll(modelsPath)
+
+	modelPath := filepath.Join(modelsPath, "pets")
+	writeV1Model(t, modelPath, "pets")
+	checksums := modelChecksums{Algorithm: checksumAlgorithm, Files: map[string]string{configFile: "modified"}}
+	if err := saveChecksums(modelPath, checksums); err != nil {
+		t.Fatal(err)
+	}
+
+	// checksum이 다른 모델은 로드하지 않지만 확인할 수 있도록 삭제하지 않음
+	i := &Inference{
+		modelsPath: modelsPath,
+		models:     make(map[string]*iModel),
+		versions:   make(map[string]map[int]*iModel),
+	}
+	if err := i.loadModels(); err != nil {
+		t.Fatal(err)
+	}
+	if len(i.models) != 0 {
+		t.Fatalf("Mismatched model should not be loaded: %v", i.models)
+	}
+	if _, err := os.Stat(filepath.Join(versionPath(modelPath, 1), checksumFile)); err != nil {
+		t.Fatalf("Mismatched model should not be removed: %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// CloneModel src 모델(`<model>/<version>`이면 해당 버전)의 파일을 복사해 dst 모델로 등록
+//
+// 복제한 모델은 새 모델 디렉토리의 첫 버전이 되며 config의 이름만 dst로 바뀜.
+// 서비스 중인 모델에 영향 없이 labels나 config를 바꿔 시험 할 때 사용
+func (i *Inference) CloneModel(ctx context.Context, src, dst string) (*CreateResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := i.authorize(ctx, ActionReadModel, src); err != nil {
+		return nil, err
+	}
+	if err := i.authorize(ctx, ActionCreateModel, dst); err != nil {
+		return nil, err
+	}
+
+	i.rwMutex.RLock()
+	srcM := i.getModel(src)
+	i.rwMutex.RUnlock()
+
+	if srcM == nil {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, src)
+	}
+	defer i.putModel(srcM)
+
+	if atomic.LoadInt32(&srcM.status) != modelStatusRun {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotReady, src)
+	}
+
+	m, err := i.reserveModel(dst)
+	if err != nil {
+		return nil, err
+	}
+	defer i.putModel(m)
+
+	newM, err := i.cloneModel(ctx, srcM, m)
+	if err != nil {
+		i.rwMutex.Lock()
+		i.delModelUncond(m)
+		i.rwMutex.Unlock()
+		return nil, err
+	}
+
+	if err := i.replaceReserved(m, newM); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Clone model(%s/%d -> %s): %s", srcM.name, srcM.version, newM.name, newM.versionPath)
+
+	return &CreateResult{
+		Model:   newM.name,
+		Type:    newM.cfg.Type,
+		Version: newM.version,
+	}, nil
+}
+
+// cloneModel srcM의 버전 디렉토리를 m의 버전 디렉토리에 복사한 후 로드한 모델 반환
+func (i *Inference) cloneModel(ctx context.Context, srcM, m *iModel) (*iModel, error) {
+	if err := copyDir(ctx, srcM.versionPath, m.versionPath); err != nil {
+		return nil, err
+	}
+
+	if m.tenant != "" {
+		i.rwMutex.RLock()
+		t, err := i.getTenant(m.tenant)
+		i.rwMutex.RUnlock()
+		if err == nil {
+			err = t.checkQuota()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 사용자 모델의 config에는 사용자를 뺀 이름을 사용
+	name, _ := splitModelName(m.name)
+	if err := updateConfig(m.versionPath, yaml.MapSlice{{Key: "name", Value: name}}); err != nil {
+		return nil, err
+	}
+
+	newM := getNewModel("", m.modelPath)
+	newM.version = m.version
+	newM.versionPath = m.versionPath
+	if err := i.load(newM); err != nil {
+		return nil, err
+	}
+	newM.name = m.name
+	newM.tenant = m.tenant
+
+	metadata := modelMetadata{
+		Name:        m.name,
+		Version:     m.version,
+		Source:      sourceCloned,
+		CreatedAt:   time.Now(),
+		Description: fmt.Sprintf("Cloned from %s/%d", srcM.name, srcM.version),
+	}
+	if err := writeMetadata(m.versionPath, metadata); err != nil {
+		log.Printf("Fail to write metadata(%s): %s", m.versionPath, err)
+	}
+	if err := writeChecksums(m.versionPath, newM.cfg); err != nil {
+		log.Printf("Fail to write checksums(%s): %s", m.versionPath, err)
+	}
+
+	return newM, nil
+}
+
+// copyDir src 디렉토리의 파일을 dst에 복사
+//
+// `.`으로 시작하는 파일과 디렉토리, 링크는 제외
+func copyDir(ctx context.Context, src, dst string) error {
+	return filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if rel != "." && strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, os.ModePerm)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		return copyFile(p, target)
+	})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
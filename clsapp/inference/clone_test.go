@@ -0,0 +1,65 @@
+package inference
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestCloneFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "dst")
+	files := map[string]string{
+		configFile:                 "name: pets\ntype: multi\nlabelsFile: labels.txt\n",
+		"labels.txt":               "cat\ndog\n",
+		"variables/variables.data": "data",
+		".graphs/graph.pb":         "graph",
+	}
+	for name, content := range files {
+		p := filepath.Join(src, name)
+		if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := copyDir(context.Background(), src, dst); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"labels.txt", "variables/variables.data"} {
+		if b, err := ioutil.ReadFile(filepath.Join(dst, name)); err != nil || string(b) != files[name] {
+			t.Fatalf("Unexpected %s: %q %v", name, b, err)
+		}
+	}
+	if isFile(filepath.Join(dst, ".graphs", "graph.pb")) {
+		t.Fatal("Hidden directory should not be copied")
+	}
+
+	// 이름 외의 항목은 순서까지 유지
+	if err := updateConfig(dst, yaml.MapSlice{{Key: "name", Value: "sandbox"}}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dst, configFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := strings.Replace(files[configFile], "pets", "sandbox", 1); string(b) != expected {
+		t.Fatalf("Unexpected configuration: %q", b)
+	}
+	if b, _ := ioutil.ReadFile(filepath.Join(src, configFile)); string(b) != files[configFile] {
+		t.Fatalf("Source configuration should not be changed: %q", b)
+	}
+}
@@ -0,0 +1,174 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// ModelResult 비교한 모델의 추론 결과
+type ModelResult struct {
+	Model     string       `json:"model"`
+	Version   int          `json:"version"`
+	Inference []InferLabel `json:"inference"`
+	// InferAll에서 추론에 실패한 모델의 에러
+	Error string `json:"error,omitempty"`
+}
+
+// LabelComparison 라벨별 모델의 확률
+//
+// Probs와 Deltas는 Comparison.Models 순서이며, 모델의 결과에 라벨이 없으면 null
+type LabelComparison struct {
+	Label string     `json:"label"`
+	Probs []*float32 `json:"probabilities"`
+	// 첫 번째(기준) 모델 대비 확률 차이
+	Deltas []*float32 `json:"deltas"`
+}
+
+// Comparison 하나의 이미지를 여러 모델로 추론한 결과
+type Comparison struct {
+	Models []ModelResult     `json:"models"`
+	Labels []LabelComparison `json:"labels"`
+	// 모든 모델의 가장 높은 확률의 라벨이 같으면 true
+	Agree bool `json:"agree"`
+}
+
+// Compare 하나의 이미지를 여러 모델로 추론하여 라벨별로 정렬 된 결과 반환
+//
+// 첫 번째 모델을 기준으로 확률 차이를 계산하며, 모든 라벨을 비교하기 위해 top-k 제한 없이 추론
+func (i *Inference) Compare(ctx context.Context, models []string, image []byte, format string) (*Comparison, error) {
+	if len(models) == 0 {
+		return nil, errors.New("No models to compare")
+	}
+	for _, model := range models {
+		if err := i.authorize(ctx, ActionInfer, model); err != nil {
+			return nil, err
+		}
+	}
+	if err := i.imageLimits.check(image); err != nil {
+		return nil, err
+	}
+
+	// 비교 중 모델이 바뀌지 않도록 모든 모델을 먼저 가져옴
+	ms := make([]*iModel, 0, len(models))
+	defer func() {
+		for _, m := range ms {
+			i.putModel(m)
+		}
+	}()
+
+	i.rwMutex.RLock()
+	for _, model := range models {
+		m := i.getModel(model)
+		if m == nil {
+			i.rwMutex.RUnlock()
+			return nil, fmt.Errorf("%w: %s", ErrModelNotFound, model)
+		}
+		ms = append(ms, m)
+	}
+	i.rwMutex.RUnlock()
+
+	results := make([]ModelResult, len(ms))
+	for idx, m := range ms {
+		if atomic.LoadInt32(&m.status) != modelStatusRun {
+			return nil, fmt.Errorf("%w: %s", ErrModelNotReady, m.name)
+		}
+		if err := i.checkEnabled(m); err != nil {
+			return nil, err
+		}
+		recordServed(ctx, m)
+
+		t0 := time.Now()
+		infers, err := m.infer(ctx, image, format, m.nrLables, 0)
+		i.observeInference(m.name, 1, t0, err)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", m.name, err)
+		}
+		results[idx] = ModelResult{
+			Model:     m.name,
+			Version:   m.version,
+			Inference: infers,
+		}
+	}
+
+	return compareResults(results), nil
+}
+
+// compareResults 모델별 추론 결과를 라벨 기준으로 정렬
+//
+// 기준 모델의 확률 순서로 정렬하고, 기준 모델에 없는 라벨은 다른 모델의 최대 확률 순서로 뒤에 둠
+func compareResults(results []ModelResult) *Comparison {
+	var (
+		labels []string
+		index  = make(map[string]int)
+		probs  [][]*float32
+	)
+	for idx, result := range results {
+		for _, infer := range result.Inference {
+			n, ok := index[infer.Label]
+			if !ok {
+				n = len(labels)
+				index[infer.Label] = n
+				labels = append(labels, infer.Label)
+				probs = append(probs, make([]*float32, len(results)))
+			}
+			prob := infer.Prob
+			probs[n][idx] = &prob
+		}
+	}
+
+	c := &Comparison{
+		Models: results,
+		Labels: make([]LabelComparison, len(labels)),
+		Agree:  true,
+	}
+	for n, label := range labels {
+		deltas := make([]*float32, len(results))
+		for idx, prob := range probs[n] {
+			if prob != nil && probs[n][0] != nil {
+				delta := *prob - *probs[n][0]
+				deltas[idx] = &delta
+			}
+		}
+		c.Labels[n] = LabelComparison{
+			Label:  label,
+			Probs:  probs[n],
+			Deltas: deltas,
+		}
+	}
+
+	sort.SliceStable(c.Labels, func(a, b int) bool {
+		pa, pb := c.Labels[a].Probs[0], c.Labels[b].Probs[0]
+		if pa != nil && pb != nil {
+			return *pa > *pb
+		}
+		if pa != nil || pb != nil {
+			return pa != nil
+		}
+		return maxProb(c.Labels[a].Probs) > maxProb(c.Labels[b].Probs)
+	})
+
+	for _, result := range results {
+		if len(result.Inference) == 0 || len(results[0].Inference) == 0 ||
+			result.Inference[0].Label != results[0].Inference[0].Label {
+			c.Agree = false
+			break
+		}
+	}
+
+	return c
+}
+
+func maxProb(probs []*float32) float32 {
+	var p float32
+	for _, prob := range probs {
+		if prob != nil && *prob > p {
+			p = *prob
+		}
+	}
+
+	return p
+}
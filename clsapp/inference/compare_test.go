@@ -0,0 +1,45 @@
+package inference
+
+import "testing"
+
+func TestCompareResults(t *testing.T) {
+	c := compareResults([]ModelResult{
+		{Model: "flowers", Inference: []InferLabel{{Label: "roses", Prob: 0.6}, {Label: "tulips", Prob: 0.3}}},
+		{Model: "flowers2", Inference: []InferLabel{{Label: "roses", Prob: 0.5}, {Label: "daisy", Prob: 0.4}}},
+	})
+
+	if !c.Agree {
+		t.Fatal("Expected agreement on top-1 label")
+	}
+	if len(c.Labels) != 3 {
+		t.Fatalf("Unexpected labels: %v", c.Labels)
+	}
+
+	want := []string{"roses", "tulips", "daisy"}
+	for idx, label := range c.Labels {
+		if label.Label != want[idx] {
+			t.Fatalf("Unexpected label order: %d %s", idx, label.Label)
+		}
+	}
+
+	roses := c.Labels[0]
+	if *roses.Deltas[0] != 0 || *roses.Deltas[1] > -0.099 || *roses.Deltas[1] < -0.101 {
+		t.Fatalf("Unexpected deltas: %v %v", *roses.Deltas[0], *roses.Deltas[1])
+	}
+
+	// 한 모델에만 있는 라벨은 다른 모델의 확률과 차이가 null
+	if tulips := c.Labels[1]; tulips.Probs[1] != nil || tulips.Deltas[1] != nil {
+		t.Fatalf("Unexpected tulips: %v", tulips)
+	}
+	if daisy := c.Labels[2]; daisy.Probs[0] != nil || daisy.Deltas[1] != nil || *daisy.Probs[1] != 0.4 {
+		t.Fatalf("Unexpected daisy: %v", daisy)
+	}
+
+	c = compareResults([]ModelResult{
+		{Model: "flowers", Inference: []InferLabel{{Label: "roses", Prob: 0.6}}},
+		{Model: "flowers2", Inference: []InferLabel{{Label: "daisy", Prob: 0.7}}},
+	})
+	if c.Agree {
+		t.Fatal("Unexpected agreement")
+	}
+}
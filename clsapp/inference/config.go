@@ -0,0 +1,311 @@
+package inference
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// loadConfig 모델 디렉토리의 config 파일을 읽고 검사
+//
+// 정의되지 않은 항목이나 잘못된 값이 있으면 모든 위반 사항을 담은 *ConfigError를 반환
+func loadConfig(modelPath string) (modelConfig, error) {
+	var cfg modelConfig
+
+	cfgFile := filepath.Join(modelPath, configFile)
+	b, err := ioutil.ReadFile(cfgFile)
+	if err != nil {
+		return cfg, err
+	}
+
+	var violations []string
+	if err := yaml.UnmarshalStrict(b, &cfg); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return cfg, &ConfigError{File: cfgFile, Violations: []string{err.Error()}}
+		}
+		// 타입이 맞지 않거나 정의되지 않은 항목을 제외한 나머지는 읽힌 상태
+		violations = append(violations, typeErr.Errors...)
+	}
+
+	violations = append(violations, cfg.resolveOutputs()...)
+	violations = append(violations, cfg.validate(modelPath)...)
+	if len(violations) > 0 {
+		return cfg, &ConfigError{File: cfgFile, Violations: violations}
+	}
+
+	return cfg, nil
+}
+
+// updateConfig dir의 config 파일에서 values의 항목만 바꿈
+//
+// 없는 항목은 끝에 추가하며 다른 항목과 순서는 유지 (주석은 유지되지 않음)
+func updateConfig(dir string, values yaml.MapSlice) error {
+	cfgFile := filepath.Join(dir, configFile)
+	b, err := ioutil.ReadFile(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	var cfg yaml.MapSlice
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return err
+	}
+	for _, value := range values {
+		found := false
+		for idx := range cfg {
+			if cfg[idx].Key == value.Key {
+				cfg[idx].Value = value.Value
+				found = true
+			}
+		}
+		if !found {
+			cfg = append(cfg, value)
+		}
+	}
+
+	if b, err = yaml.Marshal(cfg); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(cfgFile, b); err != nil {
+		return err
+	}
+
+	// 서버가 바꾼 config는 변조가 아니므로 checksum도 바꿈
+	return updateChecksum(dir, configFile)
+}
+
+// validate config 값 검사 후 위반 사항 목록 반환
+func (cfg *modelConfig) validate(modelPath string) []string {
+	var violations []string
+
+	required := []struct {
+		field string
+		value string
+	}{
+		{"name", cfg.Name},
+		{"inputOperationName", cfg.InputOperationName},
+		{"outputOperationName", cfg.OutputOperationName},
+		{"labelsFile", cfg.LabelsFile},
+	}
+	for _, r := range required {
+		// detection 모델의 출력 operation은 validateDetection에서 검사
+		if r.field == "outputOperationName" && cfg.Classification == detectionClass {
+			continue
+		}
+		// Go에서 전처리하는 형식은 operation 이름 없이 모델의 입력과 출력을 사용 (Triton의 입력 이름은 validateTriton에서 검사)
+		if (r.field == "inputOperationName" || r.field == "outputOperationName") && cfg.runtimeFormat() {
+			continue
+		}
+		if r.value == "" {
+			violations = append(violations, fmt.Sprintf("`%s` is required", r.field))
+		}
+	}
+
+	// frozen graph는 SavedModel tags를 사용하지 않음
+	if len(cfg.Tags) == 0 && cfg.modelFormat() == formatSavedModel {
+		violations = append(violations, "`tags` is required")
+	}
+	violations = append(violations, cfg.validateFormat(modelPath)...)
+	violations = append(violations, cfg.validateTFServing()...)
+	violations = append(violations, cfg.validateTriton()...)
+
+	switch cfg.Classification {
+	case binaryClass, multiClass, multiLabelClass, detectionClass, segmentationClass:
+	default:
+		violations = append(violations, fmt.Sprintf("`classification` must be %s, %s, %s, %s or %s: %q",
+			binaryClass, multiClass, multiLabelClass, detectionClass, segmentationClass, cfg.Classification))
+	}
+
+	if len(cfg.InputShape) != 3 {
+		violations = append(violations, fmt.Sprintf("`inputShape` must be [height, width, channels]: %v", cfg.InputShape))
+	} else {
+		for _, dim := range cfg.InputShape {
+			if dim <= 0 {
+				violations = append(violations, fmt.Sprintf("`inputShape` must be positive: %v", cfg.InputShape))
+				break
+			}
+		}
+	}
+
+	if cfg.Channels != 0 && cfg.Channels != 1 && cfg.Channels != 3 {
+		violations = append(violations, fmt.Sprintf("`channels` must be 1 or 3: %d", cfg.Channels))
+	} else if len(cfg.InputShape) == 3 && int(cfg.InputShape[2]) != cfg.channels() {
+		violations = append(violations, fmt.Sprintf("`inputShape` channels(%d) must be equal to `channels`(%d)", cfg.InputShape[2], cfg.channels()))
+	}
+
+	violations = append(violations, cfg.validateNormalization()...)
+	violations = append(violations, cfg.validateAlpha()...)
+	violations = append(violations, cfg.validateTTA()...)
+	violations = append(violations, cfg.validateDetection()...)
+	if cfg.Classification == segmentationClass {
+		violations = append(violations, cfg.validateWholeImage()...)
+	}
+	if cfg.EmbeddingOperationName != "" && cfg.Preprocessor != "" {
+		violations = append(violations, "`embeddingOperationName` must not be used with `preprocessor`")
+	}
+	violations = append(violations, cfg.validateCAM()...)
+	if len(cfg.ExcludeLabels) > 0 && cfg.Classification != multiClass && cfg.Classification != multiLabelClass {
+		violations = append(violations, fmt.Sprintf("`excludeLabels` must not be used with %s model", cfg.Classification))
+	}
+	violations = append(violations, cfg.validateLocalizedLabels(modelPath)...)
+
+	switch cfg.resizeMode() {
+	case resizeStretch, resizeCenterCrop, resizeLetterbox:
+	default:
+		violations = append(violations, fmt.Sprintf("`resizeMode` must be %s, %s or %s: %q", resizeStretch, resizeCenterCrop, resizeLetterbox, cfg.ResizeMode))
+	}
+
+	switch cfg.resizeMethod() {
+	case resizeBilinear, resizeBicubic, resizeNearest, resizeArea:
+		if cfg.resizeMode() != resizeStretch && !cropResizeMethods[cfg.resizeMethod()] {
+			violations = append(violations, fmt.Sprintf("`resizeMethod` of %s must be %s or %s: %q", cfg.resizeMode(), resizeBilinear, resizeNearest, cfg.ResizeMethod))
+		}
+	default:
+		violations = append(violations, fmt.Sprintf("`resizeMethod` must be %s, %s, %s or %s: %q", resizeBilinear, resizeBicubic, resizeNearest, resizeArea, cfg.ResizeMethod))
+	}
+
+	switch cfg.OutputActivation {
+	case "", activationNone, activationSigmoid:
+	case activationSoftmax:
+		// multilabel 모델의 클래스별 확률은 서로 독립이므로 합이 1이 되는 softmax를 적용할 수 없음
+		if cfg.Classification == multiLabelClass {
+			violations = append(violations, "`outputActivation` of multilabel model must be none or sigmoid")
+		}
+	default:
+		violations = append(violations, fmt.Sprintf("`outputActivation` must be none, softmax or sigmoid: %q", cfg.OutputActivation))
+	}
+
+	if cfg.Temperature < 0 || math.IsNaN(float64(cfg.Temperature)) || math.IsInf(float64(cfg.Temperature), 0) {
+		violations = append(violations, fmt.Sprintf("`temperature` must be a positive finite number: %v", cfg.Temperature))
+	} else if cfg.Temperature != 0 && (cfg.Classification == detectionClass || cfg.Classification == segmentationClass) {
+		violations = append(violations, fmt.Sprintf("`temperature` must not be used with %s model", cfg.Classification))
+	}
+
+	if cfg.Threshold != 0 && !validThreshold(cfg.Threshold) {
+		violations = append(violations, fmt.Sprintf("`threshold` must be between 0 and 1: %v", cfg.Threshold))
+	}
+	if cfg.MaxConcurrentRuns < 0 {
+		violations = append(violations, fmt.Sprintf("`maxConcurrentRuns` must not be negative: %d", cfg.MaxConcurrentRuns))
+	}
+	if cfg.DefaultTopK < 0 {
+		violations = append(violations, fmt.Sprintf("`defaultTopK` must not be negative: %d", cfg.DefaultTopK))
+	}
+	if cfg.MinProbability < 0 || cfg.MinProbability >= 1 {
+		violations = append(violations, fmt.Sprintf("`minProbability` must be in [0, 1): %v", cfg.MinProbability))
+	}
+	if cfg.UnknownThreshold < 0 || cfg.UnknownThreshold >= 1 {
+		violations = append(violations, fmt.Sprintf("`unknownThreshold` must be in [0, 1): %v", cfg.UnknownThreshold))
+	}
+	// 합이 1인 클래스별 확률만 상위 카테고리로 합산 할 수 있음
+	if cfg.LabelHierarchy && cfg.Classification != multiClass {
+		violations = append(violations, fmt.Sprintf("`labelHierarchy` must be used with %s model", multiClass))
+	}
+	violations = append(violations, cfg.validateLabelMap()...)
+
+	if cfg.LabelsFile != "" {
+		labelsFile := filepath.Join(modelPath, cfg.LabelsFile)
+		if !withinPath(modelPath, labelsFile) {
+			violations = append(violations, fmt.Sprintf("`labelsFile` must be in the model directory: %s", cfg.LabelsFile))
+		} else if !isFile(labelsFile) {
+			violations = append(violations, fmt.Sprintf("`labelsFile` does not exist: %s", cfg.LabelsFile))
+		}
+	}
+
+	return violations
+}
+
+// validateLabels 클래스 분류 방식과 labels 수 검사
+func (cfg *modelConfig) validateLabels(labels []labelInfo) []string {
+	if len(labels) == 0 {
+		return []string{"Empty labels"}
+	}
+
+	if cfg.Classification == binaryClass && len(labels) != 2 {
+		return []string{fmt.Sprintf("The number of binary labels(%d) is not 2", len(labels))}
+	}
+
+	// mask는 픽셀값이 labels의 index인 8bit 이미지
+	if cfg.Classification == segmentationClass && len(labels) > maxSegmentationLabels {
+		return []string{fmt.Sprintf("The number of segmentation labels(%d) exceeds %d", len(labels), maxSegmentationLabels)}
+	}
+
+	var violations []string
+	if cfg.LabelHierarchy {
+		for _, label := range labels {
+			if !validLabelPath(label.Name) {
+				violations = append(violations, fmt.Sprintf("Empty category in hierarchical label: %q", label.Name))
+			}
+		}
+	}
+
+	if len(cfg.LabelMap) > 0 {
+		names := make(map[string]bool, len(labels))
+		for _, label := range labels {
+			names[label.Name] = true
+		}
+		for _, from := range sortedKeys(cfg.LabelMap) {
+			if !names[from] {
+				violations = append(violations, fmt.Sprintf("No such label in `labelMap`: %q", from))
+			}
+		}
+	}
+
+	if len(cfg.ExcludeLabels) > 0 {
+		names := make(map[string]bool, len(labels))
+		for _, label := range labels {
+			names[label.Name] = true
+		}
+		for _, to := range cfg.LabelMap {
+			names[to] = true
+		}
+		for _, label := range cfg.ExcludeLabels {
+			if !names[label] {
+				violations = append(violations, fmt.Sprintf("No such label in `excludeLabels`: %q", label))
+			}
+		}
+	}
+
+	return violations
+}
+
+// validateLabelMap labelMap을 사용할 수 있는 모델인지 검사
+func (cfg *modelConfig) validateLabelMap() []string {
+	if len(cfg.LabelMap) == 0 {
+		return nil
+	}
+
+	var violations []string
+	switch cfg.Classification {
+	case binaryClass, multiClass, multiLabelClass:
+	default:
+		violations = append(violations, fmt.Sprintf("`labelMap` must not be used with %s model", cfg.Classification))
+	}
+	// 상위 카테고리는 labels 파일의 클래스 이름으로 합산
+	if cfg.LabelHierarchy {
+		violations = append(violations, "`labelMap` must not be used with `labelHierarchy`")
+	}
+	for _, from := range sortedKeys(cfg.LabelMap) {
+		if cfg.LabelMap[from] == "" {
+			violations = append(violations, fmt.Sprintf("Empty new label in `labelMap`: %q", from))
+		}
+	}
+
+	return violations
+}
+
+// sortedKeys 검사 결과의 순서가 일정하도록 정렬 된 map의 key
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
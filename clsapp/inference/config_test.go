@@ -0,0 +1,371 @@
+package inference
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const validConfig = `name: pets
+type: trial
+tags:
+- serve
+classification: binary
+inputShape: [224, 224, 3]
+inputOperationName: input
+outputOperationName: output
+labelsFile: lables
+threshold: 0.7
+`
+
+func writeConfig(t *testing.T, modelPath, config string) {
+	if err := ioutil.WriteFile(filepath.Join(modelPath, configFile), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	modelPath, err := ioutil.TempDir("", "clsapp-model-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(modelPath)
+
+	if err := ioutil.WriteFile(filepath.Join(modelPath, "lables"), []byte("cat\ndog\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	writeConfig(t, modelPath, validConfig)
+	cfg, err := loadConfig(modelPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Name != "pets" || cfg.threshold(0) != 0.7 {
+		t.Fatalf("Unexpected config: %+v", cfg)
+	}
+
+	// outputs의 이름으로 여러 출력 중 하나를 참조
+	writeConfig(t, modelPath, strings.Replace(validConfig, "outputOperationName: output", "outputOperationName: probs\nembeddingOperationName: embeddings\noutputs:\n  probs: model\n  embeddings: model:1", 1))
+	if cfg, err = loadConfig(modelPath); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.OutputOperationName != "model" || cfg.EmbeddingOperationName != "model:1" {
+		t.Fatalf("Unexpected outputs: %+v", cfg)
+	}
+
+	writeConfig(t, modelPath, validConfig+"outputIndex: 2\n")
+	if cfg, err = loadConfig(modelPath); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.OutputOperationName != "output:2" {
+		t.Fatalf("Unexpected output operation: %s", cfg.OutputOperationName)
+	}
+
+	// frozen graph는 tags 없이 graph.pb로 로드
+	frozenConfig := strings.Replace(validConfig, "tags:\n- serve\n", "format: frozen_graph\n", 1)
+	if err := ioutil.WriteFile(filepath.Join(modelPath, frozenGraphFile), []byte("graph"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, modelPath, frozenConfig)
+	if cfg, err = loadConfig(modelPath); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.modelFormat() != formatFrozenGraph {
+		t.Fatalf("Unexpected format: %s", cfg.modelFormat())
+	}
+	os.Remove(filepath.Join(modelPath, frozenGraphFile))
+
+	// TensorFlow Lite 모델은 tags와 operation 이름 없이 model.tflite로 로드
+	tfliteConfig := "name: pets\ntype: trial\nformat: tflite\nclassification: binary\ninputShape: [224, 224, 3]\nlabelsFile: lables\n"
+	if err := ioutil.WriteFile(filepath.Join(modelPath, tfliteModelFile), []byte("tflite"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, modelPath, tfliteConfig)
+	if cfg, err = loadConfig(modelPath); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.modelFormat() != formatTFLite {
+		t.Fatalf("Unexpected format: %s", cfg.modelFormat())
+	}
+	os.Remove(filepath.Join(modelPath, tfliteModelFile))
+
+	tests := []struct {
+		config     string
+		violations []string
+	}{
+		{
+			strings.Replace(validConfig, "name: pets\n", "", 1),
+			[]string{"`name`"},
+		},
+		{
+			strings.Replace(validConfig, "classification: binary", "classification: multiple", 1),
+			[]string{"`classification`"},
+		},
+		{
+			strings.Replace(validConfig, "[224, 224, 3]", "[224, 0]", 1),
+			[]string{"`inputShape`"},
+		},
+		{
+			strings.Replace(validConfig, "[224, 224, 3]", "[224, -1, 3]", 1),
+			[]string{"`inputShape`"},
+		},
+		{
+			strings.Replace(validConfig, "labelsFile: lables", "labelsFile: labels.txt", 1),
+			[]string{"`labelsFile`"},
+		},
+		{
+			strings.Replace(validConfig, "labelsFile: lables", "labelsFile: ../lables", 1),
+			[]string{"`labelsFile`"},
+		},
+		{
+			strings.Replace(validConfig, "threshold: 0.7", "threshold: 1.5", 1),
+			[]string{"`threshold`"},
+		},
+		{
+			validConfig + "defaultTopK: -1\n",
+			[]string{"`defaultTopK`"},
+		},
+		{
+			validConfig + "maxConcurrentRuns: -1\n",
+			[]string{"`maxConcurrentRuns`"},
+		},
+		{
+			validConfig + "excludeLabels: [cat]\n",
+			[]string{"`excludeLabels`"},
+		},
+		{
+			validConfig + "outputIndex: -1\n",
+			[]string{"`outputIndex`"},
+		},
+		{
+			strings.Replace(validConfig, "outputOperationName: output", "outputOperationName: output:1\noutputIndex: 1", 1),
+			[]string{"`outputIndex`"},
+		},
+		{
+			validConfig + "outputs:\n  \"probs:0\": output\n  embeddings: \"\"\n",
+			[]string{"`outputs` operation", "`outputs` name"},
+		},
+		{
+			validConfig + "minProbability: 1\n",
+			[]string{"`minProbability`"},
+		},
+		{
+			validConfig + "unknownThreshold: -0.1\n",
+			[]string{"`unknownThreshold`"},
+		},
+		{
+			// detection 모델은 outputOperationName 대신 박스, 점수, 클래스 출력이 필요
+			strings.Replace(validConfig, "classification: binary", "classification: detection", 1) +
+				"boxesOperationName: boxes\nresizeMode: letterbox\nclassOffset: -1\n",
+			[]string{"`scoresOperationName`", "`classesOperationName`", "`resizeMode`", "`classOffset`"},
+		},
+		{
+			strings.Replace(validConfig, "classification: binary", "classification: segmentation", 1) + "tta: [flip]\n",
+			[]string{"`tta` and `multiCrop`"},
+		},
+		{
+			validConfig + "channels: 2\n",
+			[]string{"`channels`"},
+		},
+		{
+			validConfig + "channels: 1\n",
+			[]string{"`inputShape`"},
+		},
+		{
+			validConfig + "mean: [123.675, 116.28]\n",
+			[]string{"`mean`"},
+		},
+		{
+			validConfig + "std: [58.395, 0, 57.375]\n",
+			[]string{"`std`"},
+		},
+		{
+			validConfig + "resizeMode: crop\n",
+			[]string{"`resizeMode`"},
+		},
+		{
+			validConfig + "resizeMethod: lanczos\n",
+			[]string{"`resizeMethod`"},
+		},
+		{
+			validConfig + "resizeMode: letterbox\nresizeMethod: bicubic\n",
+			[]string{"`resizeMethod`"},
+		},
+		{
+			validConfig + "inputDtype: int8\n",
+			[]string{"`inputDtype`"},
+		},
+		{
+			validConfig + "inputDtype: uint8\nmean: 0\n",
+			[]string{"`mean` and `std`"},
+		},
+		{
+			validConfig + "inputLayout: CHW\n",
+			[]string{"`inputLayout`"},
+		},
+		{
+			validConfig + "tta: [flip, rotate, flip]\n",
+			[]string{"`tta` must be", "`tta` has duplicated"},
+		},
+		{
+			validConfig + "multiCrop: 3\n",
+			[]string{"`multiCrop` must be"},
+		},
+		{
+			validConfig + "multiCrop: 10\nresizeMode: center-crop\ntta: [flip]\n",
+			[]string{"`resizeMode` center-crop", "`tta`"},
+		},
+		{
+			validConfig + "alpha: remove\n",
+			[]string{"`alpha`"},
+		},
+		{
+			validConfig + "alphaBackground: 255\n",
+			[]string{"`alphaBackground` must not be set"},
+		},
+		{
+			validConfig + "alpha: composite\nalphaBackground: [255, 300, 0]\n",
+			[]string{"`alphaBackground` must be in"},
+		},
+		{
+			validConfig + "mean: imagenet\n",
+			[]string{"must be a number"},
+		},
+		{
+			strings.Replace(validConfig, "classification: binary", "classification: multilabel", 1) + "outputActivation: softmax\n",
+			[]string{"`outputActivation`"},
+		},
+		{
+			validConfig + "localizedLabelsFiles:\n  ko-KR: labels.ko.txt\n",
+			[]string{"`localizedLabelsFiles` language", "`localizedLabelsFiles` does not exist"},
+		},
+		{
+			validConfig + "labelMap:\n  cat: \"\"\n",
+			[]string{"Empty new label in `labelMap`"},
+		},
+		{
+			validConfig + "labelHierarchy: true\n",
+			[]string{"`labelHierarchy`"},
+		},
+		{
+			validConfig + "temperature: -1\n",
+			[]string{"`temperature`"},
+		},
+		{
+			validConfig + "outputActivation: relu\n",
+			[]string{"`outputActivation`"},
+		},
+		{
+			validConfig + "camClassOperationName: class\n",
+			[]string{"`camGradientOperationName` and `camClassOperationName` require `camOperationName`"},
+		},
+		{
+			validConfig + "camOperationName: conv\nresizeMode: letterbox\ntta: [flip]\n",
+			[]string{"`resizeMode`", "`tta`"},
+		},
+		{
+			validConfig + "embeddingOperationName: embedding\npreprocessor: custom\n",
+			[]string{"`embeddingOperationName`"},
+		},
+		{
+			frozenConfig,
+			[]string{"`graph.pb` does not exist"},
+		},
+		{
+			strings.Replace(tfliteConfig, "224, 3]", "224, 1]", 1) + "resizeMode: letterbox\nchannels: 1\n",
+			[]string{"`resizeMode` of tflite", "`channels` of tflite", "`model.tflite` does not exist"},
+		},
+		{
+			strings.Replace(tfliteConfig, "tflite", "tf_serving", 1) + "tfServing:\n  modelName: flowers\n  version: -1\n",
+			[]string{"`tfServing.host`", "`inputOperationName` is required for tf_serving grpc", "`tfServing.version`"},
+		},
+		{
+			validConfig + "tfServing:\n  host: gpu:8501\n  modelName: flowers\n",
+			[]string{"`tfServing` must be used with tf_serving"},
+		},
+		{
+			strings.Replace(tfliteConfig, "tflite", "triton", 1) + "triton:\n  host: gpu:8000\n  protocol: rest\n",
+			[]string{"`triton.protocol`", "`triton.modelName`", "`inputOperationName` is required for triton"},
+		},
+		{
+			validConfig + "format: onnx\n",
+			[]string{"`format`"},
+		},
+		{
+			validConfig + "inputshape: [224, 224, 3]\n",
+			[]string{"inputshape"},
+		},
+		{
+			// 여러 위반 사항을 한번에 반환
+			"tags: []\nclassification: binary\ninputShape: [224, 224]\n",
+			[]string{"`name`", "`inputOperationName`", "`outputOperationName`", "`labelsFile`", "`tags`", "`inputShape`"},
+		},
+	}
+
+	for _, test := range tests {
+		writeConfig(t, modelPath, test.config)
+
+		_, err := loadConfig(modelPath)
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("Invalid config should fail: %v\n%s", err, test.config)
+		}
+
+		var cfgErr *ConfigError
+		if !errors.As(err, &cfgErr) || len(cfgErr.Violations) != len(test.violations) {
+			t.Fatalf("Unexpected violations: %v\n%s", err, test.config)
+		}
+		for idx, violation := range test.violations {
+			if !strings.Contains(cfgErr.Violations[idx], violation) {
+				t.Fatalf("Unexpected violation: %s, %s", cfgErr.Violations[idx], violation)
+			}
+		}
+	}
+}
+
+func TestValidateLabels(t *testing.T) {
+	cfg := modelConfig{Classification: binaryClass}
+
+	if violations := cfg.validateLabels([]labelInfo{{Name: "cat"}, {Name: "dog"}}); len(violations) != 0 {
+		t.Fatalf("Unexpected violations: %v", violations)
+	}
+	if violations := cfg.validateLabels([]labelInfo{{Name: "cat"}}); len(violations) != 1 {
+		t.Fatalf("Binary model with one label should fail: %v", violations)
+	}
+	if violations := cfg.validateLabels(nil); len(violations) != 1 {
+		t.Fatalf("Empty labels should fail: %v", violations)
+	}
+
+	// excludeLabels는 labelMap을 적용한 이름도 사용 가능
+	cfg = modelConfig{Classification: multiClass, LabelMap: map[string]string{"cat": "pet"}, ExcludeLabels: []string{"pet", "bird"}}
+	if violations := cfg.validateLabels([]labelInfo{{Name: "cat"}, {Name: "dog"}}); len(violations) != 1 {
+		t.Fatalf("Unknown label in excludeLabels should fail: %v", violations)
+	}
+}
+
+func TestLoadModelsInvalidConfig(t *testing.T) {
+	modelsPath := newModelsPath(t)
+	defer os.RemoveAll(modelsPath)
+
+	modelPath := filepath.Join(modelsPath, "pets")
+	writeV1Model(t, modelPath, "pets")
+	ioutil.WriteFile(filepath.Join(modelPath, configFile), []byte("name: pets\nlabelsFile: lables\nlabelsfile: lables\n"), 0644)
+
+	// 시작시 config가 잘못된 모델은 로드하지 않지만 고칠 수 있도록 삭제하지 않음
+	i := &Inference{
+		modelsPath: modelsPath,
+		models:     make(map[string]*iModel),
+		versions:   make(map[string]map[int]*iModel),
+	}
+	if err := i.loadModels(); err != nil {
+		t.Fatal(err)
+	}
+	if len(i.models) != 0 {
+		t.Fatalf("Invalid model should not be loaded: %v", i.models)
+	}
+	if _, err := os.Stat(filepath.Join(versionPath(modelPath, 1), configFile)); err != nil {
+		t.Fatalf("Invalid model should not be removed: %v", err)
+	}
+}
@@ -0,0 +1,290 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// 이미지의 대상과 위치를 검출하는 모델로, 박스, 점수, 클래스 출력을 사용
+const detectionClass = "detection"
+
+// Box 이미지의 높이와 너비를 1로 정규화 한 검출 영역
+type Box struct {
+	YMin float32 `json:"ymin"`
+	XMin float32 `json:"xmin"`
+	YMax float32 `json:"ymax"`
+	XMax float32 `json:"xmax"`
+}
+
+// DetectionResult 이미지에서 검출 된 대상
+type DetectionResult struct {
+	Label string  `json:"label"`
+	Group string  `json:"group,omitempty"`
+	Score float32 `json:"score"`
+	Box   Box     `json:"box"`
+}
+
+// rawDetection 모델이 출력한 검출 결과 (class는 classOffset을 빼기 전의 값)
+type rawDetection struct {
+	box   [4]float32
+	score float32
+	class int
+}
+
+// outputOperations 모델 실행시 가져오는 출력 operation 이름
+func (cfg *modelConfig) outputOperations() []string {
+	if cfg.Classification != detectionClass {
+		return []string{cfg.OutputOperationName}
+	}
+
+	ops := []string{cfg.BoxesOperationName, cfg.ScoresOperationName, cfg.ClassesOperationName}
+	if cfg.NumDetectionsOperationName != "" {
+		ops = append(ops, cfg.NumDetectionsOperationName)
+	}
+
+	return ops
+}
+
+// validateDetection detection 모델의 출력 operation과 함께 사용할 수 없는 설정 검사
+func (cfg *modelConfig) validateDetection() []string {
+	if cfg.Classification != detectionClass {
+		return nil
+	}
+
+	var violations []string
+	for _, r := range []struct {
+		field string
+		value string
+	}{
+		{"boxesOperationName", cfg.BoxesOperationName},
+		{"scoresOperationName", cfg.ScoresOperationName},
+		{"classesOperationName", cfg.ClassesOperationName},
+	} {
+		if r.value == "" {
+			violations = append(violations, fmt.Sprintf("`%s` is required for detection model", r.field))
+		}
+	}
+
+	violations = append(violations, cfg.validateWholeImage()...)
+	if cfg.OutputActivation != "" && cfg.OutputActivation != activationNone {
+		violations = append(violations, fmt.Sprintf("`outputActivation` of detection model must be none: %q", cfg.OutputActivation))
+	}
+	if cfg.ClassOffset < 0 {
+		violations = append(violations, fmt.Sprintf("`classOffset` must not be negative: %d", cfg.ClassOffset))
+	}
+
+	return violations
+}
+
+// validateWholeImage 이미지 전체 기준의 위치를 출력하는 모델(detection, segmentation)과 함께 사용할 수 없는 설정 검사
+//
+// 출력 위치는 입력 이미지 전체 기준으로 정규화 된 값이므로 원본 이미지의 비율을 유지하는 전처리를 사용할 수 없음
+func (cfg *modelConfig) validateWholeImage() []string {
+	var violations []string
+
+	if cfg.resizeMode() != resizeStretch {
+		violations = append(violations, fmt.Sprintf("`resizeMode` of %s model must be %s: %q", cfg.Classification, resizeStretch, cfg.ResizeMode))
+	}
+	if len(cfg.TTA) > 0 || cfg.MultiCrop > 0 {
+		violations = append(violations, fmt.Sprintf("`tta` and `multiCrop` must not be used with %s model", cfg.Classification))
+	}
+	if cfg.Preprocessor != "" {
+		violations = append(violations, fmt.Sprintf("`preprocessor` must not be used with %s model", cfg.Classification))
+	}
+
+	return violations
+}
+
+// checkClassifier 분류 모델인지 확인 (detection 모델은 Detect로, segmentation 모델은 Segment로 추론)
+func (m *iModel) checkClassifier() error {
+	switch m.cfg.Classification {
+	case detectionClass, segmentationClass:
+		return fmt.Errorf("%w: %s is a %s model", ErrModelTypeMismatch, m.name, m.cfg.Classification)
+	}
+
+	return nil
+}
+
+// parseDetections 박스([1, N, 4]), 점수([1, N]), 클래스([1, N]), 검출 수([1], 선택) 출력을 검출 결과로 변환
+//
+// 클래스는 float32(TF Object Detection API) 또는 정수 tensor
+func parseDetections(values []interface{}) ([]rawDetection, error) {
+	if len(values) < 3 {
+		return nil, fmt.Errorf("%w: detection model requires boxes, scores and classes outputs", ErrInvalidConfig)
+	}
+
+	boxes, ok := values[0].([][][]float32)
+	if !ok || len(boxes) == 0 {
+		return nil, fmt.Errorf("%w: boxes output must be [1, N, 4] float32: %T", ErrInvalidConfig, values[0])
+	}
+	scores, ok := values[1].([][]float32)
+	if !ok || len(scores) == 0 {
+		return nil, fmt.Errorf("%w: scores output must be [1, N] float32: %T", ErrInvalidConfig, values[1])
+	}
+
+	var classes []int
+	switch v := values[2].(type) {
+	case [][]float32:
+		if len(v) > 0 {
+			for _, c := range v[0] {
+				classes = append(classes, int(c))
+			}
+		}
+	case [][]int64:
+		if len(v) > 0 {
+			for _, c := range v[0] {
+				classes = append(classes, int(c))
+			}
+		}
+	case [][]int32:
+		if len(v) > 0 {
+			for _, c := range v[0] {
+				classes = append(classes, int(c))
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w: classes output must be [1, N] float32 or integer: %T", ErrInvalidConfig, values[2])
+	}
+
+	n := len(scores[0])
+	if len(boxes[0]) != n || len(classes) != n {
+		return nil, fmt.Errorf("%w: the number of boxes(%d), scores(%d) and classes(%d) does not match",
+			ErrInvalidConfig, len(boxes[0]), n, len(classes))
+	}
+
+	if len(values) > 3 {
+		num, ok := values[3].([]float32)
+		if !ok || len(num) == 0 {
+			return nil, fmt.Errorf("%w: num detections output must be [1] float32: %T", ErrInvalidConfig, values[3])
+		}
+		if int(num[0]) < n {
+			n = int(num[0])
+		}
+	}
+
+	detections := make([]rawDetection, 0, n)
+	for idx := 0; idx < n; idx++ {
+		box := boxes[0][idx]
+		if len(box) != 4 {
+			return nil, fmt.Errorf("%w: box must be [ymin, xmin, ymax, xmax]: %v", ErrInvalidConfig, box)
+		}
+		detections = append(detections, rawDetection{
+			box:   [4]float32{box[0], box[1], box[2], box[3]},
+			score: scores[0][idx],
+			class: classes[idx],
+		})
+	}
+
+	return detections, nil
+}
+
+// detections 점수가 threshold 이상인 검출 결과를 점수 순서로 반환
+//
+// k를 요청한 경우에만 상위 k개로 제한
+func (m *iModel) detections(raws []rawDetection, k int, threshold float32) ([]DetectionResult, error) {
+	results := []DetectionResult{}
+	for _, raw := range raws {
+		idx := raw.class - m.cfg.ClassOffset
+		if idx < 0 || idx >= m.nrLables {
+			return nil, fmt.Errorf("%w: class %d is out of labels(%d, classOffset %d)", ErrInvalidConfig, raw.class, m.nrLables, m.cfg.ClassOffset)
+		}
+		if raw.score < threshold || !m.labelInfos[idx].visible(raw.score) {
+			continue
+		}
+
+		results = append(results, DetectionResult{
+			Label: m.labels[idx],
+			Group: m.labelInfos[idx].Group,
+			Score: raw.score,
+			Box: Box{
+				YMin: clamp01(raw.box[0]),
+				XMin: clamp01(raw.box[1]),
+				YMax: clamp01(raw.box[2]),
+				XMax: clamp01(raw.box[3]),
+			},
+		})
+	}
+	sort.SliceStable(results, func(a, b int) bool {
+		return results[a].Score > results[b].Score
+	})
+
+	if k > 0 && k < len(results) {
+		results = results[:k]
+	}
+
+	return results, nil
+}
+
+func clamp01(v float32) float32 {
+	if v < 0 {
+		return 0
+	} else if v > 1 {
+		return 1
+	}
+
+	return v
+}
+
+// detect 이미지에서 대상을 검출
+func (m *iModel) detect(ctx context.Context, image []byte, format string, k int, threshold float32) ([]DetectionResult, error) {
+	if m.cfg.Classification != detectionClass {
+		return nil, fmt.Errorf("%w: %s is not a detection model", ErrModelTypeMismatch, m.name)
+	}
+
+	image, format, err := m.preProcess(ctx, image, format)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := m.prepareImage(ctx, image, format)
+	if err != nil {
+		return nil, err
+	}
+
+	raws, err := m.backend.runDetection(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.detections(raws, k, m.cfg.threshold(threshold))
+}
+
+// Detect detection 모델로 이미지의 대상과 위치를 검출
+//
+// 결과는 점수 순서이며, threshold(기본값: 모델 config의 threshold 또는 0.5) 미만의 검출은 제외
+func (i *Inference) Detect(ctx context.Context, model string, image []byte, format string, k int, threshold float32) (results []DetectionResult, err error) {
+	if err := i.authorize(ctx, ActionInfer, model); err != nil {
+		return nil, err
+	}
+
+	t0 := time.Now()
+	defer func() {
+		i.observeInference(model, 1, t0, err)
+	}()
+
+	if err := i.imageLimits.check(image); err != nil {
+		return nil, err
+	}
+
+	i.rwMutex.RLock()
+	m := i.getModel(model)
+	i.rwMutex.RUnlock()
+
+	if m == nil {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, model)
+	}
+	defer i.putModel(m)
+
+	if atomic.LoadInt32(&m.status) != modelStatusRun {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotReady, model)
+	}
+	if err := i.checkEnabled(m); err != nil {
+		return nil, err
+	}
+	recordServed(ctx, m)
+
+	return m.detect(ctx, image, format, k, threshold)
+}
@@ -0,0 +1,86 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseDetections(t *testing.T) {
+	boxes := [][][]float32{{{0.1, 0.2, 0.5, 0.6}, {0, 0, 1, 1}, {0.3, 0.3, 0.4, 0.4}}}
+	scores := [][]float32{{0.9, 0.6, 0.1}}
+
+	detections, err := parseDetections([]interface{}{boxes, scores, [][]float32{{1, 3, 2}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(detections) != 3 || detections[1].class != 3 || detections[0].box[3] != 0.6 {
+		t.Fatalf("Unexpected detections: %v", detections)
+	}
+
+	// 검출 수 출력이 있으면 앞에서부터 검출 수만큼 사용
+	detections, err = parseDetections([]interface{}{boxes, scores, [][]int64{{1, 3, 2}}, []float32{2}})
+	if err != nil || len(detections) != 2 {
+		t.Fatalf("Unexpected detections with num detections: %v, %v", detections, err)
+	}
+
+	if _, err := parseDetections([]interface{}{boxes, [][]float32{{0.9}}, [][]float32{{1, 3, 2}}}); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Mismatched outputs should fail: %v", err)
+	}
+	if _, err := parseDetections([]interface{}{scores, boxes, [][]float32{{1, 3, 2}}}); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Invalid boxes should fail: %v", err)
+	}
+}
+
+func TestDetections(t *testing.T) {
+	labels := []labelInfo{
+		{Name: "person"},
+		{Name: "car", Group: "vehicles"},
+		{Name: "background", Hidden: true},
+	}
+	m := &iModel{
+		cfg:        modelConfig{Classification: detectionClass, ClassOffset: 1},
+		nrLables:   len(labels),
+		labels:     labelNames(labels),
+		labelInfos: labels,
+	}
+
+	raws := []rawDetection{
+		{box: [4]float32{0.1, 0.2, 0.5, 0.6}, score: 0.7, class: 1},
+		{box: [4]float32{-0.1, 0, 1.2, 1}, score: 0.9, class: 2},
+		{box: [4]float32{0, 0, 1, 1}, score: 0.95, class: 3},
+		{box: [4]float32{0.3, 0.3, 0.4, 0.4}, score: 0.2, class: 1},
+	}
+
+	// 숨김 클래스와 threshold 미만은 제외하고 점수 순서로 반환
+	results, err := m.detections(raws, 0, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Label != "car" || results[0].Group != "vehicles" || results[1].Label != "person" {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+	if results[0].Box != (Box{YMin: 0, XMin: 0, YMax: 1, XMax: 1}) {
+		t.Fatalf("Box should be clamped to [0, 1]: %+v", results[0].Box)
+	}
+
+	if results, err := m.detections(raws, 1, 0.5); err != nil || len(results) != 1 {
+		t.Fatalf("Unexpected top-1 results: %+v, %v", results, err)
+	}
+
+	if _, err := m.detections([]rawDetection{{class: 0}}, 0, 0.5); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Class out of labels should fail: %v", err)
+	}
+}
+
+func TestModelTypeMismatch(t *testing.T) {
+	m := &iModel{name: "coco", cfg: modelConfig{Classification: detectionClass}}
+	if err := m.checkClassifier(); !errors.Is(err, ErrModelTypeMismatch) {
+		t.Fatalf("Detection model should not classify: %v", err)
+	}
+
+	m = &iModel{name: "pets", cfg: modelConfig{Classification: binaryClass}}
+	if _, err := m.detect(context.Background(), nil, "", 0, 0); !errors.Is(err, ErrModelTypeMismatch) {
+		t.Fatalf("Classification model should not detect: %v", err)
+	}
+}
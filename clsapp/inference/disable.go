@@ -0,0 +1,149 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// disabledFile 추론을 중지한 모델 이름 목록을 저장하는 파일
+const disabledFile = ".disabled.yaml"
+
+func (i *Inference) disabledPath() string {
+	return filepath.Join(i.modelsPath, disabledFile)
+}
+
+// loadDisabled 저장 된 추론 중지 모델 목록 로드
+//
+// 로드 되지 않은 모델도 유지하여 다시 추가 되면 중지 된 상태로 사용
+func (i *Inference) loadDisabled() {
+	b, err := ioutil.ReadFile(i.disabledPath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Fail to load disabled models(%s): %s", i.disabledPath(), err)
+		}
+		return
+	}
+
+	var models []string
+	if err := yaml.Unmarshal(b, &models); err != nil {
+		log.Printf("Fail to load disabled models(%s): %s", i.disabledPath(), err)
+		return
+	}
+
+	disabled := make(map[string]bool, len(models))
+	for _, model := range models {
+		disabled[model] = true
+	}
+	i.disabled = disabled
+}
+
+// writeDisabled disabled를 저장한 후 사용하는 목록으로 바꿈
+//
+// i.rwMutex를 잡은 상태에서 호출
+func (i *Inference) writeDisabled(disabled map[string]bool) error {
+	models := make([]string, 0, len(disabled))
+	for model := range disabled {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+
+	b, err := yaml.Marshal(models)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(i.disabledPath(), b); err != nil {
+		return err
+	}
+
+	i.disabled = disabled
+	return nil
+}
+
+// checkEnabled m의 모델이 추론을 중지한 모델이면 ErrModelDisabled 반환
+func (i *Inference) checkEnabled(m *iModel) error {
+	i.rwMutex.RLock()
+	disabled := i.disabled[m.name]
+	i.rwMutex.RUnlock()
+
+	if disabled {
+		return fmt.Errorf("%w: %s", ErrModelDisabled, m.name)
+	}
+
+	return nil
+}
+
+// DisableModel 모델(모든 버전)의 추론을 중지
+//
+// 모델은 로드 된 상태와 파일을 유지하므로 EnableModel로 바로 다시 사용할 수 있으며, 재시작 후에도 유지 됨.
+// 모델 정보 조회, 다시 로드 등 관리 요청은 그대로 처리
+func (i *Inference) DisableModel(ctx context.Context, model string) error {
+	return i.setDisabled(ctx, model, true)
+}
+
+// EnableModel DisableModel로 중지한 모델의 추론을 다시 시작
+func (i *Inference) EnableModel(ctx context.Context, model string) error {
+	return i.setDisabled(ctx, model, false)
+}
+
+func (i *Inference) setDisabled(ctx context.Context, model string, disable bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := i.authorize(ctx, ActionDisableModel, model); err != nil {
+		return err
+	}
+
+	i.rwMutex.Lock()
+	defer i.rwMutex.Unlock()
+
+	// 별칭이나 버전이 아닌 모델 이름만 사용
+	if _, ok := i.models[model]; !ok {
+		return fmt.Errorf("%w: %s", ErrModelNotFound, model)
+	}
+	if i.disabled[model] == disable {
+		return nil
+	}
+
+	disabled := make(map[string]bool, len(i.disabled)+1)
+	for name := range i.disabled {
+		if name != model {
+			disabled[name] = true
+		}
+	}
+	if disable {
+		disabled[model] = true
+	}
+
+	if err := i.writeDisabled(disabled); err != nil {
+		return err
+	}
+
+	log.Printf("Set model(%s) disabled: %t", model, disable)
+	return nil
+}
+
+// dropDisabled 삭제 된 모델을 추론 중지 목록에서 제거
+//
+// 같은 이름으로 새로 생성한 모델이 중지 된 상태가 되지 않도록 함. i.rwMutex를 잡은 상태에서 호출
+func (i *Inference) dropDisabled(model string) {
+	if !i.disabled[model] {
+		return
+	}
+
+	disabled := make(map[string]bool, len(i.disabled))
+	for name := range i.disabled {
+		if name != model {
+			disabled[name] = true
+		}
+	}
+	if err := i.writeDisabled(disabled); err != nil {
+		log.Printf("Fail to write disabled models(%s): %s", i.disabledPath(), err)
+	}
+}
@@ -0,0 +1,54 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestDisableModel(t *testing.T) {
+	modelsPath := newModelsPath(t)
+	defer os.RemoveAll(modelsPath)
+
+	m := &iModel{name: "pets", version: 1, status: modelStatusRun}
+	i := &Inference{
+		models:     map[string]*iModel{"pets": m},
+		modelsPath: modelsPath,
+	}
+
+	ctx := context.Background()
+	if err := i.DisableModel(ctx, "pets"); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.checkEnabled(m); !errors.Is(err, ErrModelDisabled) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := i.DisableModel(ctx, "pets/1"); !errors.Is(err, ErrModelNotFound) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// 저장 된 목록을 다시 로드
+	loaded := &Inference{models: map[string]*iModel{}, modelsPath: modelsPath}
+	loaded.loadDisabled()
+	if !loaded.disabled["pets"] {
+		t.Fatalf("Unexpected disabled models: %v", loaded.disabled)
+	}
+
+	if err := i.EnableModel(ctx, "pets"); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.checkEnabled(m); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// 삭제 된 모델은 목록에서 제거
+	if err := i.DisableModel(ctx, "pets"); err != nil {
+		t.Fatal(err)
+	}
+	i.dropDisabled("pets")
+	loaded.loadDisabled()
+	if len(loaded.disabled) != 0 {
+		t.Fatalf("Deleted model should be dropped: %v", loaded.disabled)
+	}
+}
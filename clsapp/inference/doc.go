@@ -0,0 +1,25 @@
+// Package inference 이미지 분류 모델의 관리와 추론 엔진
+//
+// HTTP 서비스(clsapp) 없이 다른 Go 서비스에 추론 엔진을 포함해 사용할 수 있음.
+// New에 With로 시작하는 Option을 전달해 생성하며, Inferencer 인터페이스의 메소드는
+// 여러 goroutine에서 동시에 호출 할 수 있음
+//
+//	i, err := inference.New(
+//		inference.WithModelsPath("/cls/models"),
+//		inference.WithLearner("learnapp:18090"),
+//		inference.WithAuthHook(func(ctx context.Context, action inference.Action, target string) error {
+//			if !allowed(ctx, action, target) {
+//				return errors.New("forbidden")
+//			}
+//			return nil
+//		}),
+//	)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer i.Destroy(context.Background())
+//
+//	labels, err := i.Infer(ctx, "default", string(image), "jpg", 5, 0)
+//
+// 반환 된 에러는 ErrModelNotFound 등의 에러를 감싸고 있으므로 errors.Is로 구분
+package inference
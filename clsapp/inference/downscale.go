@@ -0,0 +1,55 @@
+package inference
+
+import (
+	"errors"
+	"math"
+
+	// JPEG, PNG 원본을 Go에서 줄일 수 있도록 디코더 등록
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// downscale 이미지가 모델 입력 크기의 preDownscale 배보다 크면 디코딩하여 줄인 RGB 픽셀과 크기 반환
+//
+// 줄일 필요가 없거나 Go에 디코더가 없는 형식이면 nil을 반환하며, 이미지는 TensorFlow 디코더가 처리
+func (m *iModel) downscale(image []byte, format string, orientation int) ([]byte, int, int, error) {
+	if m.preDownscale <= 0 {
+		return nil, 0, 0, nil
+	}
+
+	w, h, ok := imageDimensions(image)
+	if !ok {
+		return nil, 0, 0, nil
+	}
+	// 90도 회전하는 orientation은 원본의 너비와 높이가 바뀜
+	targetH, targetW := float64(m.cfg.InputShape[0]), float64(m.cfg.InputShape[1])
+	if exifOrientations[orientation].transpose {
+		targetH, targetW = targetW, targetH
+	}
+
+	dh, dw, ok := downscaleSize(h, w, targetH*m.preDownscale, targetW*m.preDownscale)
+	if !ok {
+		return nil, 0, 0, nil
+	}
+
+	img, _, err := decodeImage(image, format)
+	if errors.Is(err, ErrUnsupportedFormat) {
+		return nil, 0, 0, nil
+	} else if err != nil {
+		return nil, 0, 0, err
+	}
+
+	return imagePixels(img, m.cfg.alphaBackground(), dh, dw), dh, dw, nil
+}
+
+// downscaleSize 비율을 유지하면서 높이와 너비가 모두 target 이상인 가장 작은 크기
+//
+// 이미지가 이미 target 이하이면 ok가 false
+func downscaleSize(h, w int, targetH, targetW float64) (int, int, bool) {
+	scale := math.Min(float64(h)/targetH, float64(w)/targetW)
+	if scale <= 1 {
+		return h, w, false
+	}
+
+	return int(math.Round(float64(h) / scale)), int(math.Round(float64(w) / scale)), true
+}
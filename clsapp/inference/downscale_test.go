@@ -0,0 +1,68 @@
+package inference
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestDownscale(t *testing.T) {
+	// 왼쪽 절반은 검은색, 오른쪽 절반은 흰색인 64x32 이미지
+	img := image.NewNRGBA(image.Rect(0, 0, 64, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 64; x++ {
+			v := uint8(0)
+			if x >= 32 {
+				v = 255
+			}
+			img.Set(x, y, color.NRGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &iModel{cfg: modelConfig{InputShape: []int32{8, 8, 3}}}
+	if pix, _, _, err := m.downscale(b.Bytes(), "png", 1); err != nil || pix != nil {
+		t.Fatalf("Downscale should be disabled: %v", err)
+	}
+
+	// 높이와 너비가 모두 입력 크기의 2배(16) 이상이 되도록 비율을 유지하여 줄임
+	m.preDownscale = 2
+	pix, h, w, err := m.downscale(b.Bytes(), "png", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h != 16 || w != 32 || len(pix) != h*w*3 {
+		t.Fatalf("Unexpected size: %dx%d (%d)", h, w, len(pix))
+	}
+	if pix[0] != 0 || pix[len(pix)-1] != 255 {
+		t.Fatalf("Unexpected pixels: %d, %d", pix[0], pix[len(pix)-1])
+	}
+
+	// 입력 크기의 4배(32) 이하인 이미지는 줄이지 않음
+	m.preDownscale = 4
+	if pix, _, _, err := m.downscale(b.Bytes(), "png", 1); err != nil || pix != nil {
+		t.Fatalf("Small image should not be downscaled: %v", err)
+	}
+}
+
+func TestImagePixelsArea(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.NRGBA{R: 0, G: 0, B: 0, A: 255})
+	img.Set(1, 0, color.NRGBA{R: 100, G: 0, B: 0, A: 255})
+	img.Set(0, 1, color.NRGBA{R: 200, G: 0, B: 0, A: 255})
+	img.Set(1, 1, color.NRGBA{R: 255, G: 0, B: 255, A: 255})
+
+	if pix := imagePixels(img, nil, 1, 1); !bytes.Equal(pix, []byte{139, 0, 64}) {
+		t.Fatalf("Unexpected area average: %v", pix)
+	}
+
+	if h, w, ok := downscaleSize(3000, 4000, 448, 448); !ok || h != 448 || w != 597 {
+		t.Fatalf("Unexpected downscale size: %dx%d %v", h, w, ok)
+	}
+}
@@ -0,0 +1,113 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// ForceDeleteModel 사용 중인 모델을 진행 중인 추론이 끝날 때까지 최대 timeout 동안 기다린 후 삭제
+//
+// 기다리는 동안 모델(`<model>/<version>`이 아니면 모든 버전)을 새로 사용하지 않으며,
+// 시간 안에 사용이 끝나지 않으면 삭제하지 않고 다시 사용할 수 있게 한 후 ErrModelInUse 반환.
+// 삭제한 모델은 TensorFlow session을 닫아 해제
+func (i *Inference) ForceDeleteModel(ctx context.Context, model string, timeout time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := i.authorize(ctx, ActionDeleteModel, model); err != nil {
+		return err
+	}
+
+	i.rwMutex.Lock()
+	m, ms, err := i.drainTargets(model)
+	if err == nil && atomic.LoadInt32(&m.draining) != 0 {
+		// 다른 요청이 삭제를 기다리는 중
+		err = fmt.Errorf("%w: %s", ErrModelNotFound, model)
+	}
+	if err != nil {
+		i.rwMutex.Unlock()
+		return err
+	}
+	for _, dm := range ms {
+		atomic.StoreInt32(&dm.draining, 1)
+	}
+	i.rwMutex.Unlock()
+
+	log.Printf("Drain model(%s): %d models", model, len(ms))
+
+	undrain := func() {
+		for _, dm := range ms {
+			atomic.StoreInt32(&dm.draining, 0)
+		}
+	}
+
+	drainCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for _, dm := range ms {
+		if err := dm.drain(drainCtx); err != nil {
+			undrain()
+			return fmt.Errorf("%w: %s is still in use after %s (%d)", ErrModelInUse, model, timeout, atomic.LoadInt32(&dm.refCount))
+		}
+	}
+
+	i.rwMutex.Lock()
+	defer i.rwMutex.Unlock()
+
+	// 기다리는 동안 다시 로드 등으로 교체 되었으면 삭제하지 않음
+	if i.lookupModel(model) != m {
+		undrain()
+		return fmt.Errorf("%w: %s is replaced while draining", ErrModelInUse, model)
+	}
+	// 기다리는 동안 생성 된 버전도 함께 해제
+	if _, ms, err = i.drainTargets(model); err != nil {
+		undrain()
+		return err
+	}
+
+	if err := i.delModel(model); err != nil {
+		undrain()
+		return err
+	}
+	for _, dm := range ms {
+		dm.destroy()
+	}
+
+	log.Printf("Force delete model(%s)", model)
+	return nil
+}
+
+// drainTargets model과 삭제시 함께 해제할 모델 (모델 이름이면 모든 버전 포함)
+//
+// i.rwMutex를 잡은 상태에서 호출
+func (i *Inference) drainTargets(model string) (*iModel, []*iModel, error) {
+	m, err := i.deletableModel(model)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ms := []*iModel{m}
+	if i.models[m.name] == m {
+		ms = append(ms, i.modelVersions(m.name)...)
+	}
+
+	return m, ms, nil
+}
+
+// drain 사용이 끝날 때까지 기다리며, ctx가 먼저 종료되면 ctx의 에러 반환
+func (m *iModel) drain(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for atomic.LoadInt32(&m.refCount) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
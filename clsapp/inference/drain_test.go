@@ -0,0 +1,52 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestForceDeleteModel(t *testing.T) {
+	m := &iModel{name: "pets", status: modelStatusRun, readOnly: true}
+	i := &Inference{models: map[string]*iModel{"pets": m}}
+	ctx := context.Background()
+
+	// 진행 중인 추론
+	inFlight := i.getModel("pets")
+
+	// 시간 안에 사용이 끝나지 않으면 삭제하지 않고 다시 사용
+	if err := i.ForceDeleteModel(ctx, "pets", 20*time.Millisecond); !errors.Is(err, ErrModelInUse) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if atomic.LoadInt32(&m.draining) != 0 || i.models["pets"] != m {
+		t.Fatal("Model should be usable after timeout")
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- i.ForceDeleteModel(ctx, "pets", 5*time.Second)
+	}()
+
+	for atomic.LoadInt32(&m.draining) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	// 삭제를 기다리는 동안 새로 사용하지 않음
+	if i.rwMutex.RLock(); i.getModel("pets") != nil {
+		t.Fatal("Draining model should not be acquired")
+	}
+	i.rwMutex.RUnlock()
+
+	i.putModel(inFlight)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := i.models["pets"]; ok {
+		t.Fatal("Model should be deleted")
+	}
+
+	if err := i.ForceDeleteModel(ctx, "pets", time.Second); !errors.Is(err, ErrModelNotFound) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,328 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SearchResult 유사 이미지 검색 결과
+type SearchResult struct {
+	ID string `json:"id"`
+	// cosine distance (1 - cosine similarity, 0이면 같은 방향)
+	Distance float32 `json:"distance"`
+}
+
+// indexItem 색인 된 이미지의 정규화 된 embedding
+type indexItem struct {
+	id     string
+	vector []float32
+}
+
+// embeddingIndex 모델별 메모리 embedding 색인
+//
+// 모델을 로드할 때 비어 있는 색인으로 만들어지므로, 모델을 다시 로드하면 다시 색인해야 함
+type embeddingIndex struct {
+	mutex sync.RWMutex
+	// id → items의 index
+	ids   map[string]int
+	items []indexItem
+}
+
+func newEmbeddingIndex() *embeddingIndex {
+	return &embeddingIndex{
+		ids: make(map[string]int),
+	}
+}
+
+// add id의 embedding을 색인 (같은 id가 있으면 교체)
+func (x *embeddingIndex) add(id string, vector []float32) error {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	if len(x.items) > 0 && len(x.items[0].vector) != len(vector) {
+		return fmt.Errorf("%w: embedding dimension %d is different from the index(%d)", ErrInvalidConfig, len(vector), len(x.items[0].vector))
+	}
+
+	if idx, ok := x.ids[id]; ok {
+		x.items[idx].vector = vector
+		return nil
+	}
+
+	x.ids[id] = len(x.items)
+	x.items = append(x.items, indexItem{id: id, vector: vector})
+
+	return nil
+}
+
+// remove id를 색인에서 제거
+func (x *embeddingIndex) remove(id string) bool {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	idx, ok := x.ids[id]
+	if !ok {
+		return false
+	}
+
+	// 마지막 항목을 제거한 자리로 옮김
+	last := len(x.items) - 1
+	if idx != last {
+		x.items[idx] = x.items[last]
+		x.ids[x.items[idx].id] = idx
+	}
+	x.items = x.items[:last]
+	delete(x.ids, id)
+
+	return true
+}
+
+func (x *embeddingIndex) clear() {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	x.ids = make(map[string]int)
+	x.items = nil
+}
+
+func (x *embeddingIndex) len() int {
+	x.mutex.RLock()
+	defer x.mutex.RUnlock()
+
+	return len(x.items)
+}
+
+// search vector와 가까운 순서로 n개의 색인 된 이미지 반환
+func (x *embeddingIndex) search(vector []float32, n int) ([]SearchResult, error) {
+	x.mutex.RLock()
+	defer x.mutex.RUnlock()
+
+	results := make([]SearchResult, 0, len(x.items))
+	for _, item := range x.items {
+		if len(item.vector) != len(vector) {
+			return nil, fmt.Errorf("%w: embedding dimension %d is different from the index(%d)", ErrInvalidConfig, len(vector), len(item.vector))
+		}
+
+		var dot float32
+		for idx, v := range item.vector {
+			dot += v * vector[idx]
+		}
+		results = append(results, SearchResult{ID: item.id, Distance: 1 - dot})
+	}
+	sort.SliceStable(results, func(a, b int) bool {
+		return results[a].Distance < results[b].Distance
+	})
+
+	if n < len(results) {
+		results = results[:n]
+	}
+
+	return results, nil
+}
+
+// normalizeEmbedding L2 norm이 1이 되도록 정규화 (cosine similarity를 내적으로 계산)
+func normalizeEmbedding(vector []float32) ([]float32, error) {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 || math.IsNaN(sum) || math.IsInf(sum, 0) {
+		return nil, errors.New("Invalid embedding: zero or non-finite vector")
+	}
+
+	norm := float32(math.Sqrt(sum))
+	normalized := make([]float32, len(vector))
+	for idx, v := range vector {
+		normalized[idx] = v / norm
+	}
+
+	return normalized, nil
+}
+
+// meanEmbedding embedding 출력([N, D] 또는 [N, 1, 1, D])을 하나의 embedding으로 변환
+//
+// TTA 또는 multi-crop의 변형 이미지별 embedding은 평균
+func meanEmbedding(value interface{}) ([]float32, error) {
+	var rows [][]float32
+	switch v := value.(type) {
+	case [][]float32:
+		rows = v
+	case [][][][]float32:
+		for _, r := range v {
+			if len(r) != 1 || len(r[0]) != 1 {
+				return nil, fmt.Errorf("%w: embedding output must be [N, D] or [N, 1, 1, D] float32", ErrInvalidConfig)
+			}
+			rows = append(rows, r[0][0])
+		}
+	default:
+		return nil, fmt.Errorf("%w: embedding output must be [N, D] or [N, 1, 1, D] float32: %T", ErrInvalidConfig, value)
+	}
+
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return nil, fmt.Errorf("%w: empty embedding output", ErrInvalidConfig)
+	}
+
+	embedding := make([]float32, len(rows[0]))
+	for _, row := range rows {
+		if len(row) != len(embedding) {
+			return nil, fmt.Errorf("%w: embedding outputs have different dimensions", ErrInvalidConfig)
+		}
+		for idx, v := range row {
+			embedding[idx] += v / float32(len(rows))
+		}
+	}
+
+	return embedding, nil
+}
+
+// embed 이미지의 정규화 된 embedding
+func (m *iModel) embed(ctx context.Context, image []byte, format string) ([]float32, error) {
+	if m.index == nil {
+		return nil, fmt.Errorf("%w: %s has no embedding output (embeddingOperationName)", ErrModelTypeMismatch, m.name)
+	}
+
+	image, format, err := m.preProcess(ctx, image, format)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := m.prepareImage(ctx, image, format)
+	if err != nil {
+		return nil, err
+	}
+
+	embedding, err := m.backend.runEmbedding(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return normalizeEmbedding(embedding)
+}
+
+// embeddingModel 실행 중인 모델을 가져옴 (사용 후 putModel 필요)
+func (i *Inference) embeddingModel(model string) (*iModel, error) {
+	i.rwMutex.RLock()
+	m := i.getModel(model)
+	i.rwMutex.RUnlock()
+
+	if m == nil {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, model)
+	}
+
+	if atomic.LoadInt32(&m.status) != modelStatusRun {
+		i.putModel(m)
+		return nil, fmt.Errorf("%w: %s", ErrModelNotReady, model)
+	}
+
+	return m, nil
+}
+
+// IndexImage 이미지의 embedding을 id로 모델의 검색 색인에 추가 (같은 id가 있으면 교체)
+func (i *Inference) IndexImage(ctx context.Context, model, id string, image []byte, format string) error {
+	if id == "" {
+		return errors.New("Empty index id")
+	}
+
+	if err := i.authorize(ctx, ActionIndexModel, model); err != nil {
+		return err
+	}
+
+	if err := i.imageLimits.check(image); err != nil {
+		return err
+	}
+
+	m, err := i.embeddingModel(model)
+	if err != nil {
+		return err
+	}
+	defer i.putModel(m)
+
+	embedding, err := m.embed(ctx, image, format)
+	if err != nil {
+		return err
+	}
+
+	return m.index.add(id, embedding)
+}
+
+// RemoveIndexedImage id를 모델의 검색 색인에서 제거
+func (i *Inference) RemoveIndexedImage(ctx context.Context, model, id string) error {
+	if err := i.authorize(ctx, ActionIndexModel, model); err != nil {
+		return err
+	}
+
+	m, err := i.embeddingModel(model)
+	if err != nil {
+		return err
+	}
+	defer i.putModel(m)
+
+	if m.index == nil {
+		return fmt.Errorf("%w: %s has no embedding output (embeddingOperationName)", ErrModelTypeMismatch, m.name)
+	}
+
+	if !m.index.remove(id) {
+		return fmt.Errorf("%w: %s", ErrIndexItemNotFound, id)
+	}
+
+	return nil
+}
+
+// ClearIndex 모델의 검색 색인을 비움
+func (i *Inference) ClearIndex(ctx context.Context, model string) error {
+	if err := i.authorize(ctx, ActionIndexModel, model); err != nil {
+		return err
+	}
+
+	m, err := i.embeddingModel(model)
+	if err != nil {
+		return err
+	}
+	defer i.putModel(m)
+
+	if m.index == nil {
+		return fmt.Errorf("%w: %s has no embedding output (embeddingOperationName)", ErrModelTypeMismatch, m.name)
+	}
+
+	m.index.clear()
+
+	return nil
+}
+
+// Search 모델의 검색 색인에서 이미지와 embedding이 가까운 순서로 n개의 이미지 반환
+func (i *Inference) Search(ctx context.Context, model string, image []byte, format string, n int) (results []SearchResult, err error) {
+	if err := i.authorize(ctx, ActionInfer, model); err != nil {
+		return nil, err
+	}
+
+	t0 := time.Now()
+	defer func() {
+		i.observeInference(model, 1, t0, err)
+	}()
+
+	if err := i.imageLimits.check(image); err != nil {
+		return nil, err
+	}
+
+	m, err := i.embeddingModel(model)
+	if err != nil {
+		return nil, err
+	}
+	defer i.putModel(m)
+	if err := i.checkEnabled(m); err != nil {
+		return nil, err
+	}
+	recordServed(ctx, m)
+
+	embedding, err := m.embed(ctx, image, format)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.index.search(embedding, n)
+}
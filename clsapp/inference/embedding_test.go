@@ -0,0 +1,78 @@
+package inference
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEmbeddingIndex(t *testing.T) {
+	x := newEmbeddingIndex()
+	for _, item := range []struct {
+		id     string
+		vector []float32
+	}{
+		{"a", []float32{1, 0}},
+		{"b", []float32{0, 1}},
+		{"c", []float32{1, 1}},
+	} {
+		vector, err := normalizeEmbedding(item.vector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := x.add(item.id, vector); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	query, _ := normalizeEmbedding([]float32{2, 0.1})
+	results, err := x.search(query, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].ID != "a" || results[1].ID != "c" {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+	if results[0].Distance > results[1].Distance {
+		t.Fatalf("Results should be sorted by distance: %+v", results)
+	}
+
+	if err := x.add("d", []float32{1, 0, 0}); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Different dimension should fail: %v", err)
+	}
+
+	// 같은 id는 교체하며, 제거한 자리에는 마지막 항목을 옮김
+	if err := x.add("b", []float32{1, 0}); err != nil || x.len() != 3 {
+		t.Fatalf("Unexpected index after replacing: %d, %v", x.len(), err)
+	}
+	if !x.remove("a") || x.remove("a") || x.len() != 2 {
+		t.Fatalf("Unexpected index after removing: %d", x.len())
+	}
+	results, _ = x.search(query, 10)
+	if len(results) != 2 || results[0].ID != "b" {
+		t.Fatalf("Unexpected results after removing: %+v", results)
+	}
+
+	x.clear()
+	if results, _ := x.search(query, 10); len(results) != 0 {
+		t.Fatalf("Cleared index should be empty: %+v", results)
+	}
+}
+
+func TestMeanEmbedding(t *testing.T) {
+	embedding, err := meanEmbedding([][]float32{{1, 2}, {3, 4}})
+	if err != nil || embedding[0] != 2 || embedding[1] != 3 {
+		t.Fatalf("Unexpected embedding: %v, %v", embedding, err)
+	}
+
+	embedding, err = meanEmbedding([][][][]float32{{{{1, 2}}}})
+	if err != nil || len(embedding) != 2 {
+		t.Fatalf("Unexpected pooled embedding: %v, %v", embedding, err)
+	}
+
+	if _, err := meanEmbedding([][][]float32{{{1}}}); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Invalid output should fail: %v", err)
+	}
+	if _, err := normalizeEmbedding([]float32{0, 0}); err == nil {
+		t.Fatal("Zero vector should fail")
+	}
+}
@@ -0,0 +1,167 @@
+package inference
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 추론 모델 관리 에러
+//
+// 반환 된 에러는 상세 정보와 함께 감싸져 있으므로 errors.Is로 비교
+var (
+	// ErrModelNotFound 존재하지 않는 모델
+	ErrModelNotFound = errors.New("No such model")
+	// ErrModelNotReady 생성 중이거나 로드되지 않은 모델
+	ErrModelNotReady = errors.New("Not ready yet")
+	// ErrModelInUse 사용 중인 모델
+	ErrModelInUse = errors.New("Currently in use")
+	// ErrUnsupportedFormat 지원하지 않는 이미지 형식
+	ErrUnsupportedFormat = errors.New("Unsupported image format")
+	// ErrDuplicateModel 이미 존재하는 모델 이름 또는 경로
+	ErrDuplicateModel = errors.New("Duplicated model")
+	// ErrInvalidName 파일 경로로 사용할 수 없는 모델 또는 이미지 그룹 이름
+	ErrInvalidName = errors.New("Invalid name")
+	// ErrInvalidModelPath 모델에 할당된 경로와 다른 경로
+	ErrInvalidModelPath = errors.New("Invalid model path")
+	// ErrInvalidConfig 모델 config 또는 labels가 추론 결과와 맞지 않음
+	ErrInvalidConfig = errors.New("Invalid model configuration")
+	// ErrTenantNotFound 존재하지 않는 사용자
+	ErrTenantNotFound = errors.New("No such tenant")
+	// ErrDuplicateTenant 이미 존재하는 사용자
+	ErrDuplicateTenant = errors.New("Duplicated tenant")
+	// ErrQuotaExceeded 사용자 모델 저장 공간 초과
+	ErrQuotaExceeded = errors.New("Quota exceeded")
+	// ErrFormatMismatch 요청한 이미지 형식과 이미지 내용이 다름
+	ErrFormatMismatch = errors.New("Image format mismatch")
+	// ErrPermissionDenied AuthHook이 허용하지 않은 요청
+	ErrPermissionDenied = errors.New("Permission denied")
+	// ErrImageTooLarge 이미지가 최대 크기를 넘음
+	ErrImageTooLarge = errors.New("Image too large")
+	// ErrInvalidTensor 전처리 된 입력의 shape 또는 값이 모델 입력과 맞지 않음
+	ErrInvalidTensor = errors.New("Invalid input tensor")
+	// ErrCorruptImage 구조가 손상 되었거나 디코딩 할 수 없는 이미지
+	ErrCorruptImage = errors.New("Corrupt image")
+	// ErrImageConversion HEICConverter가 이미지를 변환하지 못함
+	ErrImageConversion = errors.New("Fail to convert image")
+	// ErrModelTypeMismatch 모델의 classification이 지원하지 않는 추론 (예: detection 모델로 분류)
+	ErrModelTypeMismatch = errors.New("Model type mismatch")
+	// ErrIndexItemNotFound 검색 색인에 없는 id
+	ErrIndexItemNotFound = errors.New("No such index item")
+	// ErrPipelineNotFound 설정 되지 않은 pipeline
+	ErrPipelineNotFound = errors.New("No such pipeline")
+	// ErrAliasNotFound 존재하지 않는 모델 별칭
+	ErrAliasNotFound = errors.New("No such alias")
+	// ErrInvalidArchive 가져올 수 없는 모델 tar.gz 파일 (형식, 경로, 크기 또는 포함된 모델이 올바르지 않음)
+	ErrInvalidArchive = errors.New("Invalid model archive")
+	// ErrInvalidUpdate 모델 config에 적용할 수 없는 값
+	ErrInvalidUpdate = errors.New("Invalid model update")
+	// ErrMemoryBudgetExceeded 모델을 로드하면 예상 메모리 합계가 제한을 넘음
+	ErrMemoryBudgetExceeded = errors.New("Memory budget exceeded")
+	// ErrModelDisabled DisableModel로 추론을 중지한 모델
+	ErrModelDisabled = errors.New("Model disabled")
+	// ErrChecksumMismatch 모델 파일이 생성시 저장한 checksum과 다름 (손상 또는 변조)
+	ErrChecksumMismatch = errors.New("Model checksum mismatch")
+	// ErrBackendUnavailable 원격 추론 서버(TensorFlow Serving, Triton)에 연결할 수 없거나 모델이 준비되지 않음
+	ErrBackendUnavailable = errors.New("Model backend unavailable")
+)
+
+// ConfigError 모델 config 검사에서 발견 된 위반 사항
+//
+// errors.Is(err, ErrInvalidConfig)로 비교 할 수 있음
+type ConfigError struct {
+	File       string
+	Violations []string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("%s(%s): %s", ErrInvalidConfig, e.File, strings.Join(e.Violations, "; "))
+}
+
+// Unwrap ErrInvalidConfig 반환
+func (e *ConfigError) Unwrap() error {
+	return ErrInvalidConfig
+}
+
+// ChecksumError checksum이 다르거나 없어진 모델 파일
+//
+// errors.Is(err, ErrChecksumMismatch)로 비교 할 수 있으며, 파일이 없으면 Actual은 빈 값
+type ChecksumError struct {
+	File     string
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumError) Error() string {
+	if e.Actual == "" {
+		return fmt.Sprintf("%s: %s is missing", ErrChecksumMismatch, e.File)
+	}
+	return fmt.Sprintf("%s: %s (expected %s, actual %s)", ErrChecksumMismatch, e.File, e.Expected, e.Actual)
+}
+
+// Unwrap ErrChecksumMismatch 반환
+func (e *ChecksumError) Unwrap() error {
+	return ErrChecksumMismatch
+}
+
+// FormatMismatchError 요청한 형식과 이미지 내용으로 판단한 형식
+//
+// errors.Is(err, ErrFormatMismatch)로 비교 할 수 있음
+type FormatMismatchError struct {
+	Declared string
+	Detected string
+}
+
+func (e *FormatMismatchError) Error() string {
+	return fmt.Sprintf("%s: declared %s, detected %s", ErrFormatMismatch, e.Declared, e.Detected)
+}
+
+// Unwrap ErrFormatMismatch 반환
+func (e *FormatMismatchError) Unwrap() error {
+	return ErrFormatMismatch
+}
+
+// ImageTooLargeError 최대 크기를 넘은 이미지의 크기와 제한
+//
+// errors.Is(err, ErrImageTooLarge)로 비교 할 수 있으며, 너비와 높이를 확인하기 전이면 0
+type ImageTooLargeError struct {
+	Bytes  int64
+	Width  int
+	Height int
+	Limits ImageLimits
+}
+
+func (e *ImageTooLargeError) Error() string {
+	if e.Width == 0 && e.Height == 0 {
+		return fmt.Sprintf("%s: %d bytes (max %d)", ErrImageTooLarge, e.Bytes, e.Limits.MaxBytes)
+	}
+
+	return fmt.Sprintf("%s: %dx%d (max %dx%d)", ErrImageTooLarge, e.Width, e.Height, e.Limits.MaxWidth, e.Limits.MaxHeight)
+}
+
+// Unwrap ErrImageTooLarge 반환
+func (e *ImageTooLargeError) Unwrap() error {
+	return ErrImageTooLarge
+}
+
+// CorruptImageError 손상 된 이미지의 형식과 문제가 발견 된 위치
+//
+// errors.Is(err, ErrCorruptImage)로 비교 할 수 있으며, 위치를 알 수 없으면(디코더 에러) Offset은 -1
+type CorruptImageError struct {
+	Format string
+	Offset int64
+	Reason string
+}
+
+func (e *CorruptImageError) Error() string {
+	if e.Offset < 0 {
+		return fmt.Sprintf("%s(%s): %s", ErrCorruptImage, e.Format, e.Reason)
+	}
+
+	return fmt.Sprintf("%s(%s) at offset %d: %s", ErrCorruptImage, e.Format, e.Offset, e.Reason)
+}
+
+// Unwrap ErrCorruptImage 반환
+func (e *CorruptImageError) Unwrap() error {
+	return ErrCorruptImage
+}
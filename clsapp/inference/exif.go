@@ -0,0 +1,163 @@
+package inference
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// EXIF orientation 값별 이미지를 바로 세우는 연산
+//
+// 디코딩 된 [height, width, 3] 이미지를 transpose(height와 width 교환)한 후
+// reverse의 축(0: 상하, 1: 좌우)을 뒤집음
+type orientationOps struct {
+	transpose bool
+	reverse   []int32
+}
+
+// 1(정상) 또는 알 수 없는 값은 변환하지 않음
+var exifOrientations = map[int]orientationOps{
+	2: {reverse: []int32{1}},                     // 좌우 반전
+	3: {reverse: []int32{0, 1}},                  // 180도 회전
+	4: {reverse: []int32{0}},                     // 상하 반전
+	5: {transpose: true},                         // transpose
+	6: {transpose: true, reverse: []int32{1}},    // 시계 방향 90도 회전
+	7: {transpose: true, reverse: []int32{0, 1}}, // transverse
+	8: {transpose: true, reverse: []int32{0}},    // 반시계 방향 90도 회전
+}
+
+const (
+	exifOrientationTag = 0x0112
+	exifTypeShort      = 3
+)
+
+// orientedGraphFormat 전처리 graph 형식에 EXIF orientation을 붙인 이름 (예: jpeg-o6)
+func orientedGraphFormat(format string, orientation int) string {
+	if _, ok := exifOrientations[orientation]; !ok {
+		return format
+	}
+
+	return fmt.Sprintf("%s-o%d", format, orientation)
+}
+
+// orientPixels [h, w, 3] RGB 픽셀을 EXIF orientation에 따라 바로 세운 픽셀과 크기
+func orientPixels(pix []byte, h, w, orientation int) ([]byte, int, int) {
+	ops, ok := exifOrientations[orientation]
+	if !ok {
+		return pix, h, w
+	}
+
+	oh, ow := h, w
+	if ops.transpose {
+		oh, ow = w, h
+	}
+	var flipY, flipX bool
+	for _, axis := range ops.reverse {
+		if axis == 0 {
+			flipY = true
+		} else {
+			flipX = true
+		}
+	}
+
+	oriented := make([]byte, len(pix))
+	for y := 0; y < oh; y++ {
+		for x := 0; x < ow; x++ {
+			sy, sx := y, x
+			if flipY {
+				sy = oh - 1 - y
+			}
+			if flipX {
+				sx = ow - 1 - x
+			}
+			if ops.transpose {
+				sy, sx = sx, sy
+			}
+			copy(oriented[(y*ow+x)*3:(y*ow+x)*3+3], pix[(sy*w+sx)*3:(sy*w+sx)*3+3])
+		}
+	}
+
+	return oriented, oh, ow
+}
+
+// jpegOrientation JPEG의 EXIF orientation 값 반환 (없거나 읽을 수 없으면 1)
+//
+// APP1 Exif segment의 첫 번째 IFD(IFD0)에서 orientation tag만 찾음
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return 1
+	}
+
+	for pos := 2; pos+4 <= len(data); {
+		if data[pos] != 0xff {
+			return 1
+		}
+		marker := data[pos+1]
+		// marker 앞의 0xff padding
+		if marker == 0xff {
+			pos++
+			continue
+		}
+		// 이미지 데이터(SOS) 이후에는 EXIF가 없음
+		if marker == 0xda || marker == 0xd9 {
+			return 1
+		}
+
+		size := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		if size < 2 || pos+2+size > len(data) {
+			return 1
+		}
+
+		if marker == 0xe1 {
+			if o, ok := exifOrientation(data[pos+4 : pos+2+size]); ok {
+				return o
+			}
+		}
+		pos += 2 + size
+	}
+
+	return 1
+}
+
+// exifOrientation APP1 segment에서 orientation 값 반환
+func exifOrientation(seg []byte) (int, bool) {
+	const header = "Exif\x00\x00"
+	if len(seg) < len(header)+8 || string(seg[:len(header)]) != header {
+		return 0, false
+	}
+	tiff := seg[len(header):]
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0, false
+	}
+	if order.Uint16(tiff[2:4]) != 0x2a {
+		return 0, false
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0, false
+	}
+
+	n := int(order.Uint16(tiff[ifd : ifd+2]))
+	for idx := 0; idx < n; idx++ {
+		entry := ifd + 2 + idx*12
+		if entry+12 > len(tiff) {
+			return 0, false
+		}
+		if order.Uint16(tiff[entry:entry+2]) != exifOrientationTag {
+			continue
+		}
+		if order.Uint16(tiff[entry+2:entry+4]) != exifTypeShort {
+			return 0, false
+		}
+		return int(order.Uint16(tiff[entry+8 : entry+10])), true
+	}
+
+	return 0, false
+}
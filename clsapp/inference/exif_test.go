@@ -0,0 +1,173 @@
+package inference
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"testing"
+)
+
+// exifJPEG orientation tag가 있는 APP1 segment를 SOI 바로 뒤에 넣은 JPEG
+func exifJPEG(t *testing.T, order binary.ByteOrder, orientation uint16) string {
+	var img bytes.Buffer
+	if err := jpeg.Encode(&img, image.NewRGBA(image.Rect(0, 0, 4, 2)), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var tiff bytes.Buffer
+	if order == binary.LittleEndian {
+		tiff.WriteString("II")
+	} else {
+		tiff.WriteString("MM")
+	}
+	binary.Write(&tiff, order, uint16(0x2a))
+	binary.Write(&tiff, order, uint32(8))
+	// IFD0: ImageWidth, Orientation
+	binary.Write(&tiff, order, uint16(2))
+	for _, entry := range [][2]uint16{{0x0100, 4}, {exifOrientationTag, orientation}} {
+		binary.Write(&tiff, order, entry[0])
+		binary.Write(&tiff, order, uint16(exifTypeShort))
+		binary.Write(&tiff, order, uint32(1))
+		binary.Write(&tiff, order, entry[1])
+		binary.Write(&tiff, order, uint16(0))
+	}
+	binary.Write(&tiff, order, uint32(0))
+
+	seg := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+
+	var b bytes.Buffer
+	b.Write(img.Bytes()[:2])
+	b.Write([]byte{0xff, 0xe1})
+	binary.Write(&b, binary.BigEndian, uint16(len(seg)+2))
+	b.Write(seg)
+	b.Write(img.Bytes()[2:])
+
+	return b.String()
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		for o := uint16(1); o <= 8; o++ {
+			data := exifJPEG(t, order, o)
+			if got := jpegOrientation([]byte(data)); got != int(o) {
+				t.Errorf("%s orientation %d: %d", order, o, got)
+			}
+			if _, err := jpeg.Decode(bytes.NewReader([]byte(data))); err != nil {
+				t.Fatalf("Invalid test image: %s", err)
+			}
+		}
+	}
+
+	var plain bytes.Buffer
+	jpeg.Encode(&plain, image.NewRGBA(image.Rect(0, 0, 4, 2)), nil)
+
+	for name, data := range map[string]string{
+		"no exif":   plain.String(),
+		"png":       "\x89PNG\r\n\x1a\n",
+		"truncated": exifJPEG(t, binary.BigEndian, 6)[:20],
+		"empty":     "",
+	} {
+		if got := jpegOrientation([]byte(data)); got != 1 {
+			t.Errorf("%s: %d", name, got)
+		}
+	}
+}
+
+func TestOrientedGraphFormat(t *testing.T) {
+	for orientation, expected := range map[int]string{0: "jpeg", 1: "jpeg", 6: "jpeg-o6", 9: "jpeg"} {
+		if f := orientedGraphFormat("jpeg", orientation); f != expected {
+			t.Errorf("orientedGraphFormat(jpeg, %d) = %s", orientation, f)
+		}
+	}
+}
+
+// orient graph와 같은 순서로 [height][width] 이미지에 transpose와 reverse 적용
+func orient(img [][]int, ops orientationOps) [][]int {
+	if ops.transpose {
+		t := make([][]int, len(img[0]))
+		for y := range t {
+			t[y] = make([]int, len(img))
+			for x := range t[y] {
+				t[y][x] = img[x][y]
+			}
+		}
+		img = t
+	}
+
+	for _, axis := range ops.reverse {
+		r := make([][]int, len(img))
+		for y := range r {
+			r[y] = make([]int, len(img[y]))
+			for x := range r[y] {
+				if axis == 0 {
+					r[y][x] = img[len(img)-1-y][x]
+				} else {
+					r[y][x] = img[y][len(img[y])-1-x]
+				}
+			}
+		}
+		img = r
+	}
+
+	return img
+}
+
+func TestExifOrientations(t *testing.T) {
+	// 바로 선 이미지
+	//	1 2 3
+	//	4 5 6
+	upright := [][]int{{1, 2, 3}, {4, 5, 6}}
+
+	// orientation별로 저장 된 이미지 (EXIF 명세의 0행/0열 위치)
+	stored := map[int][][]int{
+		2: {{3, 2, 1}, {6, 5, 4}},
+		3: {{6, 5, 4}, {3, 2, 1}},
+		4: {{4, 5, 6}, {1, 2, 3}},
+		5: {{1, 4}, {2, 5}, {3, 6}},
+		6: {{3, 6}, {2, 5}, {1, 4}},
+		7: {{6, 3}, {5, 2}, {4, 1}},
+		8: {{4, 1}, {5, 2}, {6, 3}},
+	}
+
+	for orientation, img := range stored {
+		if got := orient(img, exifOrientations[orientation]); fmt.Sprint(got) != fmt.Sprint(upright) {
+			t.Errorf("orientation %d: %v", orientation, got)
+		}
+	}
+}
+
+func TestOrientPixels(t *testing.T) {
+	// 2x3 이미지의 픽셀을 R 값(0~5)으로 구분
+	pix := make([]byte, 0, 18)
+	for v := byte(0); v < 6; v++ {
+		pix = append(pix, v, 0, 0)
+	}
+	red := func(pix []byte) []byte {
+		var values []byte
+		for idx := 0; idx < len(pix); idx += 3 {
+			values = append(values, pix[idx])
+		}
+		return values
+	}
+
+	tests := []struct {
+		orientation int
+		h, w        int
+		values      []byte
+	}{
+		{1, 2, 3, []byte{0, 1, 2, 3, 4, 5}},
+		{2, 2, 3, []byte{2, 1, 0, 5, 4, 3}},
+		{3, 2, 3, []byte{5, 4, 3, 2, 1, 0}},
+		{6, 3, 2, []byte{3, 0, 4, 1, 5, 2}},
+		{8, 3, 2, []byte{2, 5, 1, 4, 0, 3}},
+	}
+
+	for _, test := range tests {
+		oriented, h, w := orientPixels(pix, 2, 3, test.orientation)
+		if h != test.h || w != test.w || !bytes.Equal(red(oriented), test.values) {
+			t.Fatalf("Unexpected pixels of orientation %d: %dx%d %v", test.orientation, h, w, red(oriented))
+		}
+	}
+}
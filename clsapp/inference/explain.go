@@ -0,0 +1,437 @@
+package inference
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// heatmap 생성 방식
+const (
+	// 클래스 점수의 gradient 평균을 conv layer 출력의 채널 가중치로 사용 (camGradientOperationName)
+	ExplainGradCAM = "grad-cam"
+	// gradient 없이 conv layer 출력의 채널 평균 (camOperationName만 지정)
+	ExplainActivation = "activation"
+	// 이미지 일부를 가렸을 때 클래스 확률이 줄어드는 정도 (camOperationName이 없는 모델)
+	ExplainOcclusion = "occlusion"
+)
+
+const (
+	// overlay 이미지의 긴 변의 최대 크기
+	maxOverlaySize = 512
+	// overlay에서 heatmap 색의 비율
+	overlayAlpha = 0.5
+)
+
+// Explanation 추론 결과에 영향을 준 이미지 영역
+type Explanation struct {
+	Label       string  `json:"label"`
+	Probability float32 `json:"probability"`
+	// ExplainGradCAM, ExplainActivation 또는 ExplainOcclusion
+	Method string `json:"method"`
+	// conv layer 출력 크기(occlusion은 occlusionGrid)의 0~1 heatmap (row-major)
+	Height  int       `json:"height"`
+	Width   int       `json:"width"`
+	Heatmap []float32 `json:"heatmap"`
+	// 원본 이미지 위에 heatmap 색을 겹친 PNG (json에서는 base64)
+	Overlay []byte `json:"overlay"`
+}
+
+// auxOperations 분류 출력 외에 graph에 있어야 하는 operation 이름 (embedding, CAM)
+func (cfg *modelConfig) auxOperations() []string {
+	var ops []string
+	for _, name := range []string{
+		cfg.EmbeddingOperationName,
+		cfg.CAMOperationName,
+		cfg.CAMGradientOperationName,
+		cfg.CAMClassOperationName,
+	} {
+		if name != "" {
+			ops = append(ops, name)
+		}
+	}
+
+	return ops
+}
+
+// camOperations CAM 실행시 가져오는 conv layer 출력과 gradient(지정한 경우) operation 이름
+func (cfg *modelConfig) camOperations() []string {
+	ops := []string{cfg.CAMOperationName}
+	if cfg.CAMGradientOperationName != "" {
+		ops = append(ops, cfg.CAMGradientOperationName)
+	}
+
+	return ops
+}
+
+// validateCAM CAM operation과 함께 사용할 수 없는 설정 검사
+//
+// heatmap은 모델 입력 전체에 대응하므로 원본 이미지의 비율을 유지하는 전처리와 변형 이미지를 사용할 수 없음
+func (cfg *modelConfig) validateCAM() []string {
+	var violations []string
+
+	if cfg.CAMOperationName == "" {
+		if cfg.CAMGradientOperationName != "" || cfg.CAMClassOperationName != "" {
+			violations = append(violations, "`camGradientOperationName` and `camClassOperationName` require `camOperationName`")
+		}
+		return violations
+	}
+
+	if cfg.CAMClassOperationName != "" && cfg.CAMGradientOperationName == "" {
+		violations = append(violations, "`camClassOperationName` requires `camGradientOperationName`")
+	}
+	switch cfg.Classification {
+	case detectionClass, segmentationClass:
+		violations = append(violations, fmt.Sprintf("`camOperationName` must not be used with %s model", cfg.Classification))
+	}
+	if cfg.resizeMode() != resizeStretch {
+		violations = append(violations, fmt.Sprintf("`resizeMode` of model with `camOperationName` must be %s: %q", resizeStretch, cfg.ResizeMode))
+	}
+	if len(cfg.TTA) > 0 || cfg.MultiCrop > 0 {
+		violations = append(violations, "`tta` and `multiCrop` must not be used with `camOperationName`")
+	}
+	if cfg.Preprocessor != "" {
+		violations = append(violations, "`preprocessor` must not be used with `camOperationName`")
+	}
+
+	return violations
+}
+
+// parseFeatureMap [1, H, W, C] float32 출력의 첫 번째 feature map
+func parseFeatureMap(value interface{}) ([][][]float32, error) {
+	v, ok := value.([][][][]float32)
+	if !ok || len(v) == 0 || len(v[0]) == 0 || len(v[0][0]) == 0 || len(v[0][0][0]) == 0 {
+		return nil, fmt.Errorf("%w: conv layer output must be [1, H, W, C] float32: %T", ErrInvalidConfig, value)
+	}
+
+	return v[0], nil
+}
+
+// gradCAM feature map과 gradient로 0~1로 정규화 한 [H*W] heatmap 계산
+//
+// gradient가 nil이면 채널 평균(ExplainActivation)
+func gradCAM(features, gradients [][][]float32) ([]float32, int, int, error) {
+	h, w, c := len(features), len(features[0]), len(features[0][0])
+
+	weights := make([]float32, c)
+	if gradients == nil {
+		for idx := range weights {
+			weights[idx] = 1 / float32(c)
+		}
+	} else {
+		if len(gradients) != h || len(gradients[0]) != w || len(gradients[0][0]) != c {
+			return nil, 0, 0, fmt.Errorf("%w: the shapes of conv layer output and gradient do not match", ErrInvalidConfig)
+		}
+		for _, row := range gradients {
+			for _, grads := range row {
+				for idx, grad := range grads {
+					weights[idx] += grad / float32(h*w)
+				}
+			}
+		}
+	}
+
+	var (
+		heat    = make([]float32, h*w)
+		maxHeat float32
+	)
+	for y, row := range features {
+		if len(row) != w {
+			return nil, 0, 0, fmt.Errorf("%w: conv layer output rows have different widths", ErrInvalidConfig)
+		}
+		for x, acts := range row {
+			if len(acts) != c {
+				return nil, 0, 0, fmt.Errorf("%w: conv layer output has different channels", ErrInvalidConfig)
+			}
+
+			var v float32
+			for idx, act := range acts {
+				v += weights[idx] * act
+			}
+			// 클래스에 긍정적인 영향을 준 영역만 사용 (ReLU)
+			if v > 0 {
+				heat[y*w+x] = v
+				if v > maxHeat {
+					maxHeat = v
+				}
+			}
+		}
+	}
+
+	if maxHeat > 0 {
+		for idx := range heat {
+			heat[idx] /= maxHeat
+		}
+	}
+
+	return heat, h, w, nil
+}
+
+// heatColor 0~1 값의 jet colormap 색 (파랑 → 청록 → 노랑 → 빨강)
+func heatColor(v float32) color.RGBA {
+	channel := func(center float32) uint8 {
+		return uint8(clamp01(1.5-float32(math.Abs(float64(4*v-center))))*255 + 0.5)
+	}
+
+	return color.RGBA{R: channel(3), G: channel(2), B: channel(1), A: 0xff}
+}
+
+// sampleHeatmap 크기 (hh, hw) heatmap에서 0~1로 정규화 한 위치 (fy, fx)의 값을 bilinear로 계산
+func sampleHeatmap(heat []float32, hh, hw int, fy, fx float32) float32 {
+	// 픽셀 중심 기준 좌표
+	sy := clampFloat(fy*float32(hh)-0.5, 0, float32(hh-1))
+	sx := clampFloat(fx*float32(hw)-0.5, 0, float32(hw-1))
+
+	y0, x0 := int(sy), int(sx)
+	y1, x1 := y0+1, x0+1
+	if y1 >= hh {
+		y1 = hh - 1
+	}
+	if x1 >= hw {
+		x1 = hw - 1
+	}
+	dy, dx := sy-float32(y0), sx-float32(x0)
+
+	top := heat[y0*hw+x0]*(1-dx) + heat[y0*hw+x1]*dx
+	bottom := heat[y1*hw+x0]*(1-dx) + heat[y1*hw+x1]*dx
+
+	return top*(1-dy) + bottom*dy
+}
+
+func clampFloat(v, min, max float32) float32 {
+	if v < min {
+		return min
+	} else if v > max {
+		return max
+	}
+
+	return v
+}
+
+// overlayHeatmap [h, w, 3] RGB 픽셀 위에 heatmap 색을 겹친 PNG (pix가 nil이면 heatmap 색만)
+func overlayHeatmap(pix []byte, h, w int, heat []float32, hh, hw int) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := sampleHeatmap(heat, hh, hw, (float32(y)+0.5)/float32(h), (float32(x)+0.5)/float32(w))
+			c := heatColor(v)
+			if pix != nil {
+				offset := (y*w + x) * 3
+				blend := func(base, over uint8) uint8 {
+					return uint8(float32(base)*(1-overlayAlpha) + float32(over)*overlayAlpha + 0.5)
+				}
+				c.R = blend(pix[offset], c.R)
+				c.G = blend(pix[offset+1], c.G)
+				c.B = blend(pix[offset+2], c.B)
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// overlayPixels overlay의 바탕이 될 원본 이미지의 RGB 픽셀과 크기
+//
+// 긴 변이 maxOverlaySize보다 크면 줄이고 EXIF orientation을 적용하며,
+// Go에 디코더가 없는 형식이면 nil을 반환
+func (m *iModel) overlayPixels(input imageInput) ([]byte, int, int, error) {
+	pix, h, w := input.pix, input.height, input.width
+	if pix == nil {
+		img, _, err := decodeImage(input.image, input.format)
+		if errors.Is(err, ErrUnsupportedFormat) {
+			return nil, 0, 0, nil
+		} else if err != nil {
+			return nil, 0, 0, err
+		}
+
+		bounds := img.Bounds()
+		h, w = bounds.Dy(), bounds.Dx()
+		if scale := float64(maxInt(h, w)) / maxOverlaySize; scale > 1 {
+			h, w = maxInt(1, int(math.Round(float64(h)/scale))), maxInt(1, int(math.Round(float64(w)/scale)))
+		}
+		pix = imagePixels(img, m.cfg.alphaBackground(), h, w)
+	}
+
+	pix, h, w = orientPixels(pix, h, w, input.orientation)
+
+	return pix, h, w, nil
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+// explainClass heatmap을 계산할 클래스의 index와 확률
+//
+// label을 지정하지 않으면 추론 결과의 클래스(binary 모델은 threshold로 판단한 클래스)
+func (m *iModel) explainClass(outputs [][]float32, label string) (int, float32, error) {
+	probs, err := m.classProbs(outputs)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if label != "" {
+		for idx, l := range m.labels {
+			if l == label {
+				return idx, probs[idx], nil
+			}
+		}
+		return 0, 0, fmt.Errorf("Unknown label: %q", label)
+	}
+
+	if m.cfg.Classification == binaryClass {
+		if probs[1] >= m.cfg.threshold(0) {
+			return 1, probs[1], nil
+		}
+		return 0, probs[0], nil
+	}
+
+	top := 0
+	for idx, prob := range probs {
+		if prob > probs[top] {
+			top = idx
+		}
+	}
+
+	return top, probs[top], nil
+}
+
+// explain 이미지의 클래스 heatmap과 overlay 계산
+//
+// camOperationName이 없으면 occlusion 방식으로 계산
+func (m *iModel) explain(ctx context.Context, image []byte, format, label string) (*Explanation, error) {
+	if err := m.checkClassifier(); err != nil {
+		return nil, err
+	}
+	if m.preprocessor != nil {
+		return nil, fmt.Errorf("%w: %s uses preprocessor %s", ErrModelTypeMismatch, m.name, m.cfg.Preprocessor)
+	}
+
+	image, format, err := m.preProcess(ctx, image, format)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := m.prepareImage(ctx, image, format)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs, err := m.backend.runImages(ctx, []imageInput{input})
+	if err != nil {
+		return nil, err
+	}
+
+	class, prob, err := m.explainClass(outputs[0], label)
+	if err != nil {
+		return nil, err
+	}
+
+	pix, h, w, err := m.overlayPixels(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		heat   []float32
+		hh, hw int
+		method string
+	)
+	if m.cfg.CAMOperationName != "" {
+		features, gradients, err := m.backend.runCAM(ctx, input, class)
+		if err != nil {
+			return nil, err
+		}
+		if heat, hh, hw, err = gradCAM(features, gradients); err != nil {
+			return nil, err
+		}
+
+		method = ExplainGradCAM
+		if gradients == nil {
+			method = ExplainActivation
+		}
+	} else {
+		// 가린 이미지를 Go에서 만들어야 하므로 디코더가 있는 형식만 가능
+		if pix == nil {
+			return nil, fmt.Errorf("%w: %s can not be occluded without Go decoder", ErrUnsupportedFormat, input.format)
+		}
+		if heat, err = m.occlusionMap(ctx, pix, h, w, class, prob); err != nil {
+			return nil, err
+		}
+		hh, hw, method = occlusionGrid, occlusionGrid, ExplainOcclusion
+	}
+
+	if pix == nil {
+		h, w = int(m.cfg.InputShape[0]), int(m.cfg.InputShape[1])
+	}
+
+	overlay, err := overlayHeatmap(pix, h, w, heat, hh, hw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Explanation{
+		Label:       m.labels[class],
+		Probability: prob,
+		Method:      method,
+		Height:      hh,
+		Width:       hw,
+		Heatmap:     heat,
+		Overlay:     overlay,
+	}, nil
+}
+
+// Explain 추론 결과의 클래스(label을 지정하면 label)에 영향을 준 이미지 영역을 heatmap으로 반환
+//
+// camOperationName이 있는 모델은 Grad-CAM(camGradientOperationName이 없으면 conv layer 출력의 채널 평균)을,
+// 없는 모델은 이미지 일부를 가렸을 때의 확률 변화(occlusion)를 사용
+func (i *Inference) Explain(ctx context.Context, model string, image []byte, format, label string) (result *Explanation, err error) {
+	if err := i.authorize(ctx, ActionInfer, model); err != nil {
+		return nil, err
+	}
+
+	t0 := time.Now()
+	defer func() {
+		i.observeInference(model, 1, t0, err)
+	}()
+
+	if err := i.imageLimits.check(image); err != nil {
+		return nil, err
+	}
+
+	i.rwMutex.RLock()
+	m := i.getModel(model)
+	i.rwMutex.RUnlock()
+
+	if m == nil {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, model)
+	}
+	defer i.putModel(m)
+
+	if atomic.LoadInt32(&m.status) != modelStatusRun {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotReady, model)
+	}
+	if err := i.checkEnabled(m); err != nil {
+		return nil, err
+	}
+	recordServed(ctx, m)
+
+	return m.explain(ctx, image, format, label)
+}
@@ -0,0 +1,115 @@
+package inference
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"testing"
+)
+
+func TestGradCAM(t *testing.T) {
+	features := [][][]float32{
+		{{1, 0}, {0, 1}},
+		{{0.5, 0.5}, {0, 0}},
+	}
+
+	// 첫 번째 채널만 클래스에 긍정적인 영향
+	gradients := [][][]float32{
+		{{1, -1}, {1, -1}},
+		{{1, -1}, {1, -1}},
+	}
+	heat, h, w, err := gradCAM(features, gradients)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h != 2 || w != 2 || heat[0] != 1 || heat[1] != 0 || heat[2] != 0 || heat[3] != 0 {
+		t.Fatalf("Unexpected heatmap: %v", heat)
+	}
+
+	// gradient가 없으면 채널 평균
+	heat, _, _, err = gradCAM(features, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if heat[0] != 1 || heat[1] != 1 || heat[2] != 1 || heat[3] != 0 {
+		t.Fatalf("Unexpected activation heatmap: %v", heat)
+	}
+
+	if _, _, _, err := gradCAM(features, [][][]float32{{{1, -1}}}); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Mismatched gradient should fail: %v", err)
+	}
+}
+
+func TestOverlayHeatmap(t *testing.T) {
+	pix := bytes.Repeat([]byte{128, 128, 128}, 6*4)
+
+	overlay, err := overlayHeatmap(pix, 4, 6, []float32{0, 1}, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(overlay))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 6 || b.Dy() != 4 {
+		t.Fatalf("Unexpected overlay size: %v", b)
+	}
+
+	// heatmap의 왼쪽(0)은 파란색, 오른쪽(1)은 빨간색 쪽으로 섞임
+	lr, _, lb, _ := img.At(0, 0).RGBA()
+	rr, _, rb, _ := img.At(5, 0).RGBA()
+	if lb <= lr || rr <= rb {
+		t.Fatalf("Unexpected overlay colors: left(%d, %d), right(%d, %d)", lr, lb, rr, rb)
+	}
+}
+
+func TestExplainClass(t *testing.T) {
+	labels := []labelInfo{{Name: "cat"}, {Name: "dog"}, {Name: "bird"}}
+	m := &iModel{
+		cfg:        modelConfig{Classification: multiClass},
+		nrLables:   len(labels),
+		labels:     labelNames(labels),
+		labelInfos: labels,
+	}
+
+	class, prob, err := m.explainClass([][]float32{{0.2, 0.7, 0.1}}, "")
+	if err != nil || class != 1 || prob != 0.7 {
+		t.Fatalf("Unexpected predicted class: %d %f %v", class, prob, err)
+	}
+
+	class, prob, err = m.explainClass([][]float32{{0.2, 0.7, 0.1}}, "bird")
+	if err != nil || class != 2 || prob != 0.1 {
+		t.Fatalf("Unexpected requested class: %d %f %v", class, prob, err)
+	}
+
+	if _, _, err := m.explainClass([][]float32{{0.2, 0.7, 0.1}}, "fish"); err == nil {
+		t.Fatal("Unknown label should fail")
+	}
+
+	binary := &iModel{
+		cfg:        modelConfig{Classification: binaryClass, Threshold: 0.8},
+		nrLables:   2,
+		labels:     []string{"normal", "defect"},
+		labelInfos: []labelInfo{{Name: "normal"}, {Name: "defect"}},
+	}
+	if class, _, err := binary.explainClass([][]float32{{0.7}}, ""); err != nil || class != 0 {
+		t.Fatalf("Binary class under threshold should be negative: %d %v", class, err)
+	}
+}
+
+func TestOccludedPixels(t *testing.T) {
+	pix := bytes.Repeat([]byte{0, 0, 0}, 14*14)
+
+	occluded := occludedPixels(pix, 14, 14, 1, 2)
+	var gray int
+	for idx := 0; idx < len(occluded); idx += 3 {
+		if occluded[idx] == occlusionGray {
+			gray++
+		}
+	}
+	// 14x14 이미지의 7x7 칸은 2x2 픽셀
+	if gray != 4 || occluded[(2*14+4)*3] != occlusionGray || pix[(2*14+4)*3] != 0 {
+		t.Fatalf("Unexpected occluded pixels: %d", gray)
+	}
+}
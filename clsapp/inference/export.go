@@ -0,0 +1,110 @@
+package inference
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// ExportModel 모델(`<model>/<version>`이면 해당 버전)의 SavedModel, config, labels와 생성 정보를 tar.gz로 w에 씀
+//
+// 파일은 `<model>/` 디렉토리 아래에 담겨 ImportModel로 다시 등록 할 수 있으며, 내보내는 동안 모델은 삭제 할 수 없음.
+// 모델을 확인하는 단계의 에러는 w에 쓰기 전에 반환
+func (i *Inference) ExportModel(ctx context.Context, model string, w io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := i.authorize(ctx, ActionExportModel, model); err != nil {
+		return err
+	}
+
+	i.rwMutex.RLock()
+	m := i.getModel(model)
+	i.rwMutex.RUnlock()
+
+	if m == nil {
+		return fmt.Errorf("%w: %s", ErrModelNotFound, model)
+	}
+	defer i.putModel(m)
+
+	if atomic.LoadInt32(&m.status) != modelStatusRun {
+		return fmt.Errorf("%w: %s", ErrModelNotReady, model)
+	}
+
+	name, _ := splitModelName(m.name)
+	return tarModel(ctx, m.versionPath, name, w)
+}
+
+// tarModel dir의 파일을 prefix 디렉토리 아래에 담은 tar.gz를 w에 씀
+//
+// `.`으로 시작하는 파일과 디렉토리, 링크는 제외
+func tarModel(ctx context.Context, dir, prefix string, w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		if strings.HasPrefix(filepath.Base(rel), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = path.Join(prefix, filepath.ToSlash(rel))
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gz.Close()
+}
@@ -0,0 +1,102 @@
+package inference
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+type excludedLabelsKey struct{}
+
+// ContextWithExcludedLabels 추론 결과에서 제외할 클래스 이름을 지정한 ctx 반환
+//
+// 모델 config의 excludeLabels와 함께 적용하며, 제외한 클래스는 상위 k개에 포함되지 않음
+func ContextWithExcludedLabels(ctx context.Context, labels ...string) context.Context {
+	return context.WithValue(ctx, excludedLabelsKey{}, labels)
+}
+
+// contextExcludedLabels ctx에 지정된 제외할 클래스 이름 (정렬, 중복 제거)
+func contextExcludedLabels(ctx context.Context) []string {
+	labels, _ := ctx.Value(excludedLabelsKey{}).([]string)
+
+	var excluded []string
+	for _, label := range labels {
+		if label = strings.TrimSpace(label); label != "" {
+			excluded = append(excluded, label)
+		}
+	}
+	sort.Strings(excluded)
+
+	uniq := excluded[:0]
+	for idx, label := range excluded {
+		if idx == 0 || label != excluded[idx-1] {
+			uniq = append(uniq, label)
+		}
+	}
+
+	return uniq
+}
+
+// excludedLabels 모델 config와 ctx의 제외할 클래스 이름
+func (m *iModel) excludedLabels(ctx context.Context) map[string]bool {
+	labels := contextExcludedLabels(ctx)
+	if len(labels) == 0 && len(m.cfg.ExcludeLabels) == 0 {
+		return nil
+	}
+
+	excluded := make(map[string]bool, len(labels)+len(m.cfg.ExcludeLabels))
+	for _, label := range append(labels, m.cfg.ExcludeLabels...) {
+		excluded[label] = true
+	}
+
+	return excluded
+}
+
+// FilterStats 다중 카테고리, multi-label 분류에서 결과에 포함되지 않은 클래스 수
+type FilterStats struct {
+	// labels 파일의 숨김 클래스
+	Hidden int `json:"hidden"`
+	// 모델 또는 클래스별 최소 확률(multilabel 모델은 threshold) 미만
+	BelowMinimum int `json:"belowMinimum"`
+	// 모델 config의 excludeLabels 또는 요청에서 제외한 클래스 (labelMap을 적용한 이름)
+	Excluded int `json:"excluded"`
+	// 상위 k개에 포함되지 않은 클래스
+	BeyondTopK int `json:"beyondTopK"`
+}
+
+// filterLabels 숨김, 최소 확률 미만의 클래스를 제외한 결과를 만들고 labelMap과 제외할 클래스를 적용
+//
+// stats가 nil이 아니면 제외한 클래스 수를 기록
+func (m *iModel) filterLabels(probs []float32, minProbability float32, excluded map[string]bool, stats *FilterStats) []InferLabel {
+	if stats == nil {
+		stats = &FilterStats{}
+	}
+
+	var infers []InferLabel
+	for idx, prob := range probs {
+		switch {
+		case m.labelInfos[idx].Hidden:
+			stats.Hidden++
+		case !m.labelInfos[idx].visible(prob) || prob < minProbability:
+			stats.BelowMinimum++
+		default:
+			infers = append(infers, m.newInferLabel(idx, prob))
+		}
+	}
+	infers = m.remap(infers)
+
+	if len(excluded) > 0 {
+		filtered := infers[:0]
+		for _, infer := range infers {
+			if excluded[infer.Label] {
+				stats.Excluded++
+				continue
+			}
+			filtered = append(filtered, infer)
+		}
+		infers = filtered
+	}
+	sort.Sort(sortByProb(infers))
+
+	return infers
+}
@@ -0,0 +1,57 @@
+package inference
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestClassifyMultiFiltered(t *testing.T) {
+	labels := []labelInfo{
+		{Name: "tulips"},
+		{Name: "daisy"},
+		{Name: "roses", MinProbability: 0.3},
+		{Name: "background", Hidden: true},
+		{Name: "sunflowers"},
+	}
+	m := &iModel{
+		nrLables:   len(labels),
+		labels:     labelNames(labels),
+		labelInfos: labels,
+		cfg:        modelConfig{Classification: multiClass, ExcludeLabels: []string{"sunflowers"}},
+	}
+	probs := []float32{0.25, 0.25, 0.2, 0.1, 0.2}
+
+	// 확률이 같으면 클래스 이름 순서
+	for n := 0; n < 10; n++ {
+		infers, err := m.classifyMulti(probs, 2, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(infers) != 2 || infers[0].Label != "daisy" || infers[1].Label != "tulips" {
+			t.Fatalf("Unexpected tie order: %v", infers)
+		}
+	}
+
+	ctx := ContextWithExcludedLabels(context.Background(), " daisy", "", "daisy")
+	if excluded := contextExcludedLabels(ctx); !reflect.DeepEqual(excluded, []string{"daisy"}) {
+		t.Fatalf("Unexpected excluded labels: %v", excluded)
+	}
+
+	stats := &FilterStats{}
+	infers, err := m.classifyMulti(probs, 5, m.excludedLabels(ctx), stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infers) != 1 || infers[0].Label != "tulips" {
+		t.Fatalf("Unexpected filtered infers: %v", infers)
+	}
+	if *stats != (FilterStats{Hidden: 1, BelowMinimum: 1, Excluded: 2}) {
+		t.Fatalf("Unexpected stats: %+v", stats)
+	}
+
+	stats = &FilterStats{}
+	if _, err := m.classifyMulti(probs, 1, nil, stats); err != nil || stats.BeyondTopK != 2 || stats.Excluded != 0 {
+		t.Fatalf("Unexpected top-k stats: %+v, %v", stats, err)
+	}
+}
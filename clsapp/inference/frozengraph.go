@@ -0,0 +1,53 @@
+package inference
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// 모델 파일 형식
+const (
+	// tf.saved_model로 저장한 SavedModel (기본값)
+	formatSavedModel = "saved_model"
+	// 변수를 상수로 바꿔 하나의 GraphDef로 저장한 frozen graph (TF 1.x transfer learning 예제의 retrained graph 등)
+	formatFrozenGraph = "frozen_graph"
+)
+
+// frozenGraphFile 모델 버전 디렉토리에서 frozen graph를 읽는 파일
+const frozenGraphFile = "graph.pb"
+
+// modelFormat 모델 파일 형식
+func (cfg *modelConfig) modelFormat() string {
+	if cfg.Format == "" {
+		return formatSavedModel
+	}
+
+	return cfg.Format
+}
+
+// validateFormat 모델 파일 형식과 frozen graph 또는 TensorFlow Lite 모델 파일 검사 (등록 된 Backend의 형식 포함)
+func (cfg *modelConfig) validateFormat(modelPath string) []string {
+	switch cfg.modelFormat() {
+	case formatSavedModel:
+		return nil
+	case formatFrozenGraph:
+		if !isFile(filepath.Join(modelPath, frozenGraphFile)) {
+			return []string{fmt.Sprintf("`%s` does not exist for %s format", frozenGraphFile, formatFrozenGraph)}
+		}
+		return nil
+	case formatTFLite:
+		violations := cfg.validateRuntime()
+		if !isFile(filepath.Join(modelPath, tfliteModelFile)) {
+			violations = append(violations, fmt.Sprintf("`%s` does not exist for %s format", tfliteModelFile, formatTFLite))
+		}
+		return violations
+	case formatTFServing, formatTriton:
+		return cfg.validateRuntime()
+	default:
+		if _, ok := lookupBackend(cfg.Format); ok {
+			return cfg.validateRuntime()
+		}
+		return []string{fmt.Sprintf("`format` must be %s, %s, %s, %s, %s or a registered backend: %q",
+			formatSavedModel, formatFrozenGraph, formatTFLite, formatTFServing, formatTriton, cfg.Format)}
+	}
+}
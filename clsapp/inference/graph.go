@@ -0,0 +1,247 @@
+package inference
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// 학습 가능한 변수를 저장하는 operation 타입
+var variableOpTypes = map[string]bool{
+	"Variable":    true,
+	"VariableV2":  true,
+	"VarHandleOp": true,
+}
+
+// GraphTensor operation 출력 tensor의 타입과 shape
+//
+// shape이 nil이면 rank를 알 수 없고, -1인 차원은 크기를 알 수 없음
+type GraphTensor struct {
+	DataType string  `json:"dataType"`
+	Shape    []int64 `json:"shape"`
+}
+
+// GraphOp graph의 operation
+type GraphOp struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// 입력 tensor 이름 (`<operation>` 또는 `<operation>:<출력 index>`)
+	Inputs  []string      `json:"inputs,omitempty"`
+	Outputs []GraphTensor `json:"outputs,omitempty"`
+}
+
+// GraphVariable 학습 가능한 변수와 파라미터 수
+type GraphVariable struct {
+	Name       string  `json:"name"`
+	Shape      []int64 `json:"shape"`
+	Parameters int64   `json:"parameters"`
+}
+
+// GraphSummary 로드 된 모델 graph의 요약
+type GraphSummary struct {
+	Model         string          `json:"model"`
+	Version       int             `json:"version"`
+	NumOperations int             `json:"numberOfOperations"`
+	OpTypes       map[string]int  `json:"operationTypes"`
+	Parameters    int64           `json:"parameters"`
+	Variables     []GraphVariable `json:"variables"`
+	Input         *GraphOp        `json:"input,omitempty"`
+	Output        *GraphOp        `json:"output,omitempty"`
+	// verbose인 경우에만 포함
+	Operations []GraphOp `json:"operations,omitempty"`
+}
+
+// GetModelGraph 로드 된 모델의 graph 요약 반환
+//
+// verbose면 모든 operation과 입출력 연결을 포함
+func (i *Inference) GetModelGraph(ctx context.Context, model string, verbose bool) (*GraphSummary, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := i.authorize(ctx, ActionReadModel, model); err != nil {
+		return nil, err
+	}
+
+	i.rwMutex.RLock()
+	m := i.getModel(model)
+	i.rwMutex.RUnlock()
+
+	if m == nil {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, model)
+	}
+	defer i.putModel(m)
+
+	if atomic.LoadInt32(&m.status) != modelStatusRun {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotReady, model)
+	}
+
+	// detection 모델은 박스 출력을 모델의 출력으로 표시
+	inputOp, _ := inputOpName(m.cfg.InputOperationName)
+	outputOp, _ := inputOpName(m.cfg.outputOperations()[0])
+	s := summarizeGraph(m.backend.graph(), inputOp, outputOp)
+	s.Model = m.name
+	s.Version = m.version
+	if !verbose {
+		s.Operations = nil
+	}
+
+	return s, nil
+}
+
+// summarizeGraph operation 목록으로 타입별 개수와 변수의 파라미터 수 계산
+func summarizeGraph(ops []GraphOp, inputOp, outputOp string) *GraphSummary {
+	s := &GraphSummary{
+		NumOperations: len(ops),
+		OpTypes:       make(map[string]int),
+		Variables:     make([]GraphVariable, 0),
+		Operations:    ops,
+	}
+
+	// resource 변수(VarHandleOp)의 출력은 scalar handle이므로
+	// 변수를 읽는 ReadVariableOp의 출력 shape을 변수의 shape으로 사용
+	readShapes := make(map[string][]int64)
+	for _, op := range ops {
+		if op.Type == "ReadVariableOp" && len(op.Inputs) > 0 && len(op.Outputs) > 0 {
+			if _, ok := readShapes[op.Inputs[0]]; !ok {
+				readShapes[op.Inputs[0]] = op.Outputs[0].Shape
+			}
+		}
+	}
+
+	for idx := range ops {
+		op := &ops[idx]
+		s.OpTypes[op.Type]++
+
+		switch op.Name {
+		case inputOp:
+			s.Input = op
+		case outputOp:
+			s.Output = op
+		}
+
+		if !variableOpTypes[op.Type] {
+			continue
+		}
+
+		var shape []int64
+		if op.Type == "VarHandleOp" {
+			shape = readShapes[op.Name]
+		} else if len(op.Outputs) > 0 {
+			shape = op.Outputs[0].Shape
+		}
+
+		params := numElements(shape)
+		s.Variables = append(s.Variables, GraphVariable{
+			Name:       op.Name,
+			Shape:      shape,
+			Parameters: params,
+		})
+		if params > 0 {
+			s.Parameters += params
+		}
+	}
+
+	sort.Slice(s.Variables, func(i, j int) bool {
+		return s.Variables[i].Name < s.Variables[j].Name
+	})
+
+	return s
+}
+
+// numElements shape의 원소 수 (알 수 없는 차원이 있으면 -1)
+func numElements(shape []int64) int64 {
+	if shape == nil {
+		return -1
+	}
+
+	n := int64(1)
+	for _, d := range shape {
+		if d < 0 {
+			return -1
+		}
+		n *= d
+	}
+
+	return n
+}
+
+// inputOpName 입력 tensor 이름에서 operation 이름 반환
+func inputOpName(input string) (string, int) {
+	if idx := strings.LastIndex(input, ":"); idx >= 0 {
+		if n, err := strconv.Atoi(input[idx+1:]); err == nil {
+			return input[:idx], n
+		}
+	}
+
+	return input, 0
+}
+
+// shapeString shape을 `1x224x224x3` 형태로 변환 (알 수 없는 차원은 `?`)
+func shapeString(shape []int64) string {
+	if shape == nil {
+		return "?"
+	}
+	if len(shape) == 0 {
+		return "scalar"
+	}
+
+	dims := make([]string, len(shape))
+	for idx, d := range shape {
+		if d < 0 {
+			dims[idx] = "?"
+		} else {
+			dims[idx] = strconv.FormatInt(d, 10)
+		}
+	}
+
+	return strings.Join(dims, "x")
+}
+
+// WriteDOT graph를 Graphviz DOT 형식으로 출력
+//
+// 각 연결에는 입력 tensor의 shape을 표시
+func (s *GraphSummary) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	outputs := make(map[string][]GraphTensor, len(s.Operations))
+	for _, op := range s.Operations {
+		outputs[op.Name] = op.Outputs
+	}
+
+	fmt.Fprintf(bw, "digraph %s {\n", strconv.Quote(s.Model))
+	fmt.Fprintln(bw, "  node [shape=box, fontsize=10];")
+	fmt.Fprintln(bw, "  edge [fontsize=8];")
+
+	for _, op := range s.Operations {
+		attr := ""
+		if (s.Input != nil && op.Name == s.Input.Name) || (s.Output != nil && op.Name == s.Output.Name) {
+			attr = ", style=filled, fillcolor=lightblue"
+		} else if variableOpTypes[op.Type] {
+			attr = ", style=filled, fillcolor=lightyellow"
+		}
+		fmt.Fprintf(bw, "  %s [label=%s%s];\n",
+			strconv.Quote(op.Name), strconv.Quote(op.Name+"\n"+op.Type), attr)
+	}
+
+	for _, op := range s.Operations {
+		for _, input := range op.Inputs {
+			src, idx := inputOpName(input)
+
+			label := "?"
+			if outs := outputs[src]; idx < len(outs) {
+				label = shapeString(outs[idx].Shape)
+			}
+			fmt.Fprintf(bw, "  %s -> %s [label=%s];\n",
+				strconv.Quote(src), strconv.Quote(op.Name), strconv.Quote(label))
+		}
+	}
+
+	fmt.Fprintln(bw, "}")
+
+	return bw.Flush()
+}
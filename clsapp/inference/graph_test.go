@@ -0,0 +1,65 @@
+package inference
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func testGraphOps() []GraphOp {
+	return []GraphOp{
+		{Name: "input", Type: "Placeholder", Outputs: []GraphTensor{{DataType: "float32", Shape: []int64{-1, 224, 224, 3}}}},
+		{Name: "dense/kernel", Type: "VarHandleOp", Outputs: []GraphTensor{{DataType: "resource", Shape: []int64{}}}},
+		{Name: "dense/kernel/Read", Type: "ReadVariableOp", Inputs: []string{"dense/kernel"},
+			Outputs: []GraphTensor{{DataType: "float32", Shape: []int64{1280, 5}}}},
+		{Name: "dense/bias", Type: "VariableV2", Outputs: []GraphTensor{{DataType: "float32", Shape: []int64{5}}}},
+		{Name: "unknown", Type: "VariableV2", Outputs: []GraphTensor{{DataType: "float32", Shape: nil}}},
+		{Name: "split", Type: "Split", Inputs: []string{"input"},
+			Outputs: []GraphTensor{{Shape: []int64{-1, 1280}}, {Shape: []int64{-1, 1280}}}},
+		{Name: "output", Type: "MatMul", Inputs: []string{"split:1", "dense/kernel/Read"},
+			Outputs: []GraphTensor{{DataType: "float32", Shape: []int64{-1, 5}}}},
+	}
+}
+
+func TestSummarizeGraph(t *testing.T) {
+	s := summarizeGraph(testGraphOps(), "input", "output")
+
+	if s.NumOperations != 7 || s.OpTypes["VariableV2"] != 2 {
+		t.Fatalf("Unexpected operations: %d %v", s.NumOperations, s.OpTypes)
+	}
+	if s.Input == nil || s.Input.Name != "input" || s.Output == nil || s.Output.Name != "output" {
+		t.Fatalf("Unexpected input/output: %v %v", s.Input, s.Output)
+	}
+
+	// 알 수 없는 shape의 변수는 합계에서 제외
+	if s.Parameters != 1280*5+5 {
+		t.Fatalf("Unexpected parameters: %d", s.Parameters)
+	}
+	if len(s.Variables) != 3 || s.Variables[0].Name != "dense/bias" || s.Variables[1].Parameters != 1280*5 ||
+		s.Variables[2].Parameters != -1 {
+		t.Fatalf("Unexpected variables: %v", s.Variables)
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	s := summarizeGraph(testGraphOps(), "input", "output")
+	s.Model = "flowers"
+
+	var b bytes.Buffer
+	if err := s.WriteDOT(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	dot := b.String()
+	for _, want := range []string{
+		`digraph "flowers" {`,
+		`"input" [label="input\nPlaceholder", style=filled, fillcolor=lightblue];`,
+		`"input" -> "split" [label="?x224x224x3"];`,
+		`"split" -> "output" [label="?x1280"];`,
+		`"dense/kernel" -> "dense/kernel/Read" [label="scalar"];`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("Missing %q in\n%s", want, dot)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package inference
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// 전처리 graph 저장 구조
+//
+// 이미지 디코딩과 정규화 graph를 (이미지 형식, 입력 크기와 채널 수, 입력 layout, 정규화 방식, 크기 조정과 보간 방식)별 GraphDef로 저장하여,
+// 재시작하거나 다른 인스턴스에서도 같은 graph를 다시 만들지 않고 읽어서 사용
+//
+//	<modelsPath>/.graphs/
+//	  preprocess-v<버전>-<형식>-<height>x<width>x<channels>-<layout>-<정규화>-<크기 조정>-<보간>.pb
+//	  preprocess-v<버전>-<형식>-o<EXIF orientation>-<height>x<width>x<channels>-<layout>-<정규화>-<크기 조정>-<보간>.pb  회전/반전이 필요한 JPEG
+const (
+	graphsDir = ".graphs"
+
+	// 전처리 graph 구성이 바뀌면 증가시켜 이전에 저장 된 graph를 사용하지 않도록 함
+	preprocessGraphVersion = 2
+
+	// [0, 255]의 이미지값을 [-1, 1]로 조정
+	normalizeSymmetric = "symmetric"
+
+	// Go에서 디코딩 된 RGB 픽셀을 입력으로 받는 전처리 graph의 형식 이름
+	preprocessRaw = "raw"
+
+	// 저장 된 graph에서 입출력을 찾기 위한 operation 이름
+	preprocessInputOp  = "input/Placeholder"
+	preprocessOutputOp = "output/Identity"
+)
+
+// preprocessFormat 같은 디코더를 사용하는 이미지 형식을 하나의 이름으로 변환
+//
+// 지원하지 않는 형식은 ErrUnsupportedFormat 반환
+func preprocessFormat(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "jpg", "jpeg":
+		return "jpeg", nil
+	case "png":
+		return "png", nil
+	case "bmp":
+		return "bmp", nil
+	case "webp":
+		return "webp", nil
+	case "gif":
+		return "gif", nil
+	case "tif", "tiff":
+		return "tiff", nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+}
+
+// preprocessGraphFormat 이미지 형식이 사용하는 전처리 graph의 형식
+//
+// Go에서 디코딩하는 형식은 모두 preprocessRaw graph를 사용
+func preprocessGraphFormat(format string) string {
+	if goDecodedFormats[format] {
+		return preprocessRaw
+	}
+
+	return format
+}
+
+// preprocessGraphFile 모델 config에 맞는 전처리 graph의 graphCachePath 안의 저장 파일 경로 반환 (저장 경로가 없으면 빈 값)
+func preprocessGraphFile(graphCachePath, format string, cfg *modelConfig) string {
+	if graphCachePath == "" {
+		return ""
+	}
+
+	return filepath.Join(graphCachePath, fmt.Sprintf("preprocess-v%d-%s-%dx%dx%d-%s-%s-%s-%s-%s.pb",
+		preprocessGraphVersion, format, cfg.InputShape[0], cfg.InputShape[1], cfg.InputShape[2],
+		strings.ToLower(cfg.inputLayout()), cfg.normalizationName(), cfg.resizeName(), cfg.alphaName(), cfg.ttaName()))
+}
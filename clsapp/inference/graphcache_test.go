@@ -0,0 +1,61 @@
+package inference
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPreprocessFormat(t *testing.T) {
+	for format, expected := range map[string]string{"jpg": "jpeg", "JPEG": "jpeg", "png": "png", "BMP": "bmp", "webp": "webp", "GIF": "gif", "tif": "tiff"} {
+		if f, err := preprocessFormat(format); err != nil || f != expected {
+			t.Errorf("preprocessFormat(%q) = %q, %v", format, f, err)
+		}
+	}
+
+	if _, err := preprocessFormat("svg"); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestPreprocessGraphFormat(t *testing.T) {
+	for _, format := range []string{"webp", "gif", "tiff"} {
+		if f := preprocessGraphFormat(format); f != preprocessRaw {
+			t.Fatalf("Unexpected %s graph format: %s", format, f)
+		}
+	}
+	if f := preprocessGraphFormat("jpeg"); f != "jpeg" {
+		t.Fatalf("Unexpected jpeg graph format: %s", f)
+	}
+}
+
+func TestPreprocessGraphFile(t *testing.T) {
+	if file := preprocessGraphFile("", "jpeg", &modelConfig{InputShape: []int32{224, 224, 3}}); file != "" {
+		t.Fatalf("Graph should not be saved: %s", file)
+	}
+
+	graphCachePath := "/cls/models/.graphs"
+	file := preprocessGraphFile(graphCachePath, "jpeg", &modelConfig{InputShape: []int32{224, 160, 3}})
+	if file != "/cls/models/.graphs/preprocess-v2-jpeg-224x160x3-nhwc-symmetric-stretch-bilinear-strip-none.pb" {
+		t.Fatalf("Unexpected graph file: %s", file)
+	}
+
+	cfg := modelConfig{
+		InputShape:      []int32{224, 160, 1},
+		InputLayout:     layoutNCHW,
+		InputDType:      inputUint8,
+		ResizeMode:      resizeLetterbox,
+		ResizeMethod:    resizeNearest,
+		Alpha:           alphaComposite,
+		AlphaBackground: channelValues{0},
+		TTA:             []string{ttaFlip},
+	}
+	file = preprocessGraphFile(graphCachePath, "jpeg", &cfg)
+	if file != "/cls/models/.graphs/preprocess-v2-jpeg-224x160x1-nchw-uint8-letterbox-nearest-composite0_0_0-flip.pb" {
+		t.Fatalf("Unexpected graph file: %s", file)
+	}
+
+	file = preprocessGraphFile(graphCachePath, "png", &modelConfig{InputShape: []int32{224, 224, 3}, MultiCrop: multiCrop10})
+	if file != "/cls/models/.graphs/preprocess-v2-png-224x224x3-nhwc-symmetric-stretch-bilinear-strip-crop10.pb" {
+		t.Fatalf("Unexpected graph file: %s", file)
+	}
+}
@@ -0,0 +1,424 @@
+package inference
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// gRPC status code
+const (
+	grpcOK          = 0
+	grpcNotFound    = 5
+	grpcUnavailable = 14
+)
+
+// grpcError OK가 아닌 gRPC status 응답
+type grpcError struct {
+	code    int
+	message string
+}
+
+func (e *grpcError) Error() string {
+	return fmt.Sprintf("gRPC status %d: %s", e.code, e.message)
+}
+
+// grpcClient HTTP/2로 gRPC unary 요청을 보내는 client
+//
+// TensorFlow Serving과 Triton의 몇 가지 method만 사용하므로 요청마다 연결하는 최소한의 구현이며 (grpc 패키지는 사용하지 않음),
+// TLS를 사용하지 않으면 prior knowledge로 HTTP/2(h2c)를 사용
+type grpcClient struct {
+	host    string
+	timeout time.Duration
+	// TLS를 사용하지 않으면 nil
+	tlsConfig *tls.Config
+}
+
+func newGRPCClient(host string, timeoutSeconds int, useTLS bool) *grpcClient {
+	c := &grpcClient{
+		host:    host,
+		timeout: remoteTimeout(timeoutSeconds),
+	}
+	if useTLS {
+		c.tlsConfig = &tls.Config{}
+	}
+
+	return c
+}
+
+// invoke method에 message 하나를 요청하여 응답 message 반환
+func (c *grpcClient) invoke(ctx context.Context, method string, request []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	response, err := c.roundTrip(ctx, method, request)
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	return response, err
+}
+
+func (c *grpcClient) roundTrip(ctx context.Context, method string, request []byte) ([]byte, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", c.host)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// ctx가 종료되면 읽기와 쓰기 중단
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.SetDeadline(time.Unix(1, 0))
+		case <-stop:
+		}
+	}()
+
+	scheme := "http"
+	if c.tlsConfig != nil {
+		cfg := c.tlsConfig.Clone()
+		cfg.NextProtos = []string{"h2"}
+		if cfg.ServerName == "" {
+			cfg.ServerName, _, _ = net.SplitHostPort(c.host)
+		}
+		tc := tls.Client(conn, cfg)
+		if err := tc.Handshake(); err != nil {
+			return nil, err
+		}
+		if p := tc.ConnectionState().NegotiatedProtocol; p != "h2" {
+			return nil, fmt.Errorf("Server does not support HTTP/2: %q", p)
+		}
+		conn, scheme = tc, "https"
+	}
+
+	h := &h2Conn{
+		r:          bufio.NewReader(conn),
+		w:          bufio.NewWriter(conn),
+		connWindow: h2DefaultWindow,
+		window:     h2DefaultWindow,
+		initWindow: h2DefaultWindow,
+		maxFrame:   h2DefaultMaxFrame,
+		decoder:    newHPACKDecoder(),
+	}
+
+	headers := [][2]string{
+		{":method", "POST"},
+		{":scheme", scheme},
+		{":path", method},
+		{":authority", c.host},
+		{"content-type", "application/grpc"},
+		{"te", "trailers"},
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		headers = append(headers, [2]string{"grpc-timeout", grpcTimeout(time.Until(deadline))})
+	}
+
+	// 길이를 앞에 붙인 압축하지 않은 message 하나
+	body := make([]byte, 5, 5+len(request))
+	binary.BigEndian.PutUint32(body[1:], uint32(len(request)))
+	body = append(body, request...)
+
+	fields, data, err := h.roundTrip(headers, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if status := fields[":status"]; status != "200" {
+		return nil, fmt.Errorf("Unexpected gRPC HTTP status: %s", status)
+	}
+	status, ok := fields["grpc-status"]
+	if !ok {
+		return nil, errors.New("gRPC status is missing")
+	}
+	if code, err := strconv.Atoi(status); err != nil || code != grpcOK {
+		message, _ := url.PathUnescape(fields["grpc-message"])
+		return nil, &grpcError{code: code, message: message}
+	}
+
+	if len(data) < 5 {
+		return nil, errors.New("gRPC response message is missing")
+	}
+	if data[0] != 0 {
+		return nil, errors.New("Compressed gRPC response is not supported")
+	}
+	size := binary.BigEndian.Uint32(data[1:5])
+	if uint64(len(data)-5) < uint64(size) {
+		return nil, errors.New("gRPC response message is truncated")
+	}
+
+	return data[5 : 5+size], nil
+}
+
+// grpcTimeout grpc-timeout header 값 (millisecond)
+func grpcTimeout(d time.Duration) string {
+	ms := d.Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+
+	return strconv.FormatInt(ms, 10) + "m"
+}
+
+// HTTP/2 frame type, flag와 설정
+const (
+	h2FrameData         = 0x0
+	h2FrameHeaders      = 0x1
+	h2FrameRSTStream    = 0x3
+	h2FrameSettings     = 0x4
+	h2FramePushPromise  = 0x5
+	h2FramePing         = 0x6
+	h2FrameGoAway       = 0x7
+	h2FrameWindowUpdate = 0x8
+	h2FrameContinuation = 0x9
+
+	h2FlagEndStream  = 0x1
+	h2FlagAck        = 0x1
+	h2FlagEndHeaders = 0x4
+	h2FlagPadded     = 0x8
+	h2FlagPriority   = 0x20
+
+	h2SettingEnablePush        = 0x2
+	h2SettingInitialWindowSize = 0x4
+	h2SettingMaxFrameSize      = 0x5
+
+	h2Preface         = "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+	h2DefaultWindow   = 65535
+	h2DefaultMaxFrame = 16384
+	// h2ReceiveWindow 응답을 받는 window (응답 message 최대 크기)
+	h2ReceiveWindow = 1 << 26
+	// h2Stream 요청마다 연결하므로 하나의 stream만 사용
+	h2Stream = 1
+)
+
+// h2Conn 하나의 stream만 사용하는 HTTP/2 연결
+type h2Conn struct {
+	r *bufio.Reader
+	w *bufio.Writer
+
+	// 보낼 수 있는 연결과 stream의 flow control window, 서버가 지정한 stream의 초기 window
+	connWindow int64
+	window     int64
+	initWindow int64
+	// 서버가 받는 최대 frame 크기
+	maxFrame int
+
+	decoder *hpackDecoder
+}
+
+// roundTrip 요청 header와 body를 보내고 응답 header(trailer 포함)와 body 반환
+func (h *h2Conn) roundTrip(headers [][2]string, body []byte) (map[string]string, []byte, error) {
+	h.w.WriteString(h2Preface)
+
+	var settings []byte
+	settings = appendH2Setting(settings, h2SettingEnablePush, 0)
+	settings = appendH2Setting(settings, h2SettingInitialWindowSize, h2ReceiveWindow)
+	h.writeFrame(h2FrameSettings, 0, 0, settings)
+	h.writeFrame(h2FrameWindowUpdate, 0, 0, appendUint32(nil, h2ReceiveWindow-h2DefaultWindow))
+
+	var block []byte
+	for _, f := range headers {
+		block = appendHPACKField(block, f[0], f[1])
+	}
+	if len(block) > h2DefaultMaxFrame {
+		return nil, nil, errors.New("gRPC request headers are too large")
+	}
+	h.writeFrame(h2FrameHeaders, h2FlagEndHeaders, h2Stream, block)
+
+	fields := make(map[string]string)
+	var (
+		data     []byte
+		fragment []byte
+		// 헤더 블록이 끝나면 stream이 끝나는 HEADERS
+		endStream bool
+	)
+	for {
+		// 보낼 수 있는 만큼 body 전송
+		for len(body) > 0 && h.connWindow > 0 && h.window > 0 {
+			n := len(body)
+			if n > h.maxFrame {
+				n = h.maxFrame
+			}
+			if int64(n) > h.connWindow {
+				n = int(h.connWindow)
+			}
+			if int64(n) > h.window {
+				n = int(h.window)
+			}
+
+			var flags byte
+			if n == len(body) {
+				flags = h2FlagEndStream
+			}
+			h.writeFrame(h2FrameData, flags, h2Stream, body[:n])
+			body = body[n:]
+			h.connWindow -= int64(n)
+			h.window -= int64(n)
+		}
+		if err := h.w.Flush(); err != nil {
+			return nil, nil, err
+		}
+
+		typ, flags, stream, payload, err := h.readFrame()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch typ {
+		case h2FrameSettings:
+			if flags&h2FlagAck != 0 {
+				continue
+			}
+			if err := h.applySettings(payload); err != nil {
+				return nil, nil, err
+			}
+			h.writeFrame(h2FrameSettings, h2FlagAck, 0, nil)
+		case h2FramePing:
+			if flags&h2FlagAck == 0 {
+				h.writeFrame(h2FramePing, h2FlagAck, 0, payload)
+			}
+		case h2FrameWindowUpdate:
+			if len(payload) != 4 {
+				return nil, nil, errors.New("Invalid HTTP/2 WINDOW_UPDATE frame")
+			}
+			increment := int64(binary.BigEndian.Uint32(payload) & 0x7fffffff)
+			if stream == 0 {
+				h.connWindow += increment
+			} else if stream == h2Stream {
+				h.window += increment
+			}
+		case h2FrameHeaders, h2FrameContinuation:
+			if stream != h2Stream {
+				continue
+			}
+			if typ == h2FrameHeaders {
+				if payload, err = h2FramePayload(flags, payload, true); err != nil {
+					return nil, nil, err
+				}
+				endStream = flags&h2FlagEndStream != 0
+			}
+			fragment = append(fragment, payload...)
+			if flags&h2FlagEndHeaders == 0 {
+				continue
+			}
+			if err := h.decoder.decode(fragment, fields); err != nil {
+				return nil, nil, err
+			}
+			fragment = nil
+			if endStream {
+				return fields, data, nil
+			}
+		case h2FrameData:
+			if stream != h2Stream {
+				continue
+			}
+			if payload, err = h2FramePayload(flags, payload, false); err != nil {
+				return nil, nil, err
+			}
+			data = append(data, payload...)
+			if flags&h2FlagEndStream != 0 {
+				return fields, data, nil
+			}
+		case h2FrameRSTStream:
+			if stream == h2Stream && len(payload) == 4 {
+				return nil, nil, fmt.Errorf("HTTP/2 stream is reset: error code %d", binary.BigEndian.Uint32(payload))
+			}
+		case h2FrameGoAway:
+			// 마지막으로 처리하는 stream 이전에 연결을 닫으면 요청을 처리하지 않음
+			if len(payload) >= 8 && binary.BigEndian.Uint32(payload)&0x7fffffff < h2Stream {
+				return nil, nil, fmt.Errorf("HTTP/2 connection is closed: error code %d", binary.BigEndian.Uint32(payload[4:]))
+			}
+		case h2FramePushPromise:
+			return nil, nil, errors.New("Unexpected HTTP/2 PUSH_PROMISE frame")
+		}
+	}
+}
+
+// applySettings 서버의 SETTINGS 적용
+func (h *h2Conn) applySettings(payload []byte) error {
+	if len(payload)%6 != 0 {
+		return errors.New("Invalid HTTP/2 SETTINGS frame")
+	}
+
+	for idx := 0; idx < len(payload); idx += 6 {
+		value := binary.BigEndian.Uint32(payload[idx+2:])
+		switch binary.BigEndian.Uint16(payload[idx:]) {
+		case h2SettingInitialWindowSize:
+			// 초기 window가 바뀌면 stream의 window도 차이만큼 바뀜
+			h.window += int64(value) - h.initWindow
+			h.initWindow = int64(value)
+		case h2SettingMaxFrameSize:
+			h.maxFrame = int(value)
+		}
+	}
+
+	return nil
+}
+
+// h2FramePayload padding(과 HEADERS의 priority)을 제외한 payload
+func h2FramePayload(flags byte, payload []byte, headers bool) ([]byte, error) {
+	if flags&h2FlagPadded != 0 {
+		if len(payload) < 1 || int(payload[0]) >= len(payload) {
+			return nil, errors.New("Invalid HTTP/2 frame padding")
+		}
+		payload = payload[1 : len(payload)-int(payload[0])]
+	}
+	if headers && flags&h2FlagPriority != 0 {
+		if len(payload) < 5 {
+			return nil, errors.New("Invalid HTTP/2 HEADERS frame")
+		}
+		payload = payload[5:]
+	}
+
+	return payload, nil
+}
+
+// writeFrame frame을 버퍼에 씀 (에러는 Flush에서 반환)
+func (h *h2Conn) writeFrame(typ, flags byte, stream uint32, payload []byte) {
+	n := len(payload)
+	h.w.Write([]byte{byte(n >> 16), byte(n >> 8), byte(n), typ, flags})
+	h.w.Write(appendUint32(nil, stream))
+	h.w.Write(payload)
+}
+
+// readFrame frame 하나를 읽음
+func (h *h2Conn) readFrame() (byte, byte, uint32, []byte, error) {
+	var header [9]byte
+	if _, err := io.ReadFull(h.r, header[:]); err != nil {
+		return 0, 0, 0, nil, err
+	}
+
+	// 최대 frame 크기를 지정하지 않았으므로 기본값보다 큰 frame은 받지 않음
+	n := int(header[0])<<16 | int(header[1])<<8 | int(header[2])
+	if n > h2DefaultMaxFrame {
+		return 0, 0, 0, nil, fmt.Errorf("HTTP/2 frame is too large: %d", n)
+	}
+
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(h.r, payload); err != nil {
+		return 0, 0, 0, nil, err
+	}
+
+	return header[3], header[4], binary.BigEndian.Uint32(header[5:]) & 0x7fffffff, payload, nil
+}
+
+func appendH2Setting(b []byte, id uint16, value uint32) []byte {
+	return appendUint32(append(b, byte(id>>8), byte(id)), value)
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
@@ -0,0 +1,109 @@
+package inference
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newGRPCServer method별로 요청 message에 응답하는 HTTP/2(TLS) gRPC 서버
+//
+// handle이 OK가 아닌 status를 반환하면 message 없이 grpc-status와 grpc-message trailer만 응답
+func newGRPCServer(t *testing.T, handle func(method string, request []byte) ([]byte, int)) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 2 || r.Header.Get("Content-Type") != "application/grpc" || r.Header.Get("Te") != "trailers" {
+			t.Errorf("Unexpected gRPC request: %s %v", r.Proto, r.Header)
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil || len(body) < 5 || int(binary.BigEndian.Uint32(body[1:])) != len(body)-5 {
+			t.Errorf("Invalid gRPC request message: %d %v", len(body), err)
+			return
+		}
+
+		response, code := handle(r.URL.Path, body[5:])
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+		if code == grpcOK {
+			message := make([]byte, 5, 5+len(response))
+			binary.BigEndian.PutUint32(message[1:], uint32(len(response)))
+			w.Write(append(message, response...))
+		} else {
+			w.Header().Set("Grpc-Message", "model%20is%20not%20found")
+		}
+		w.Header().Set("Grpc-Status", strconv.Itoa(code))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+
+	return server
+}
+
+// grpcServerClient 테스트 서버의 인증서를 사용하는 client
+func grpcServerClient(server *httptest.Server, c *grpcClient) {
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	c.tlsConfig.RootCAs = pool
+}
+
+func TestGRPCClient(t *testing.T) {
+	server := newGRPCServer(t, func(method string, request []byte) ([]byte, int) {
+		if method != "/test.Echo/Echo" {
+			return nil, grpcNotFound
+		}
+		return request, grpcOK
+	})
+	defer server.Close()
+
+	c := newGRPCClient(strings.TrimPrefix(server.URL, "https://"), 0, true)
+	grpcServerClient(server, c)
+
+	// flow control window보다 큰 message
+	request := make([]byte, 1<<20)
+	for idx := range request {
+		request[idx] = byte(idx)
+	}
+	response, err := c.invoke(context.Background(), "/test.Echo/Echo", request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(response) != string(request) {
+		t.Fatalf("Unexpected response: %d bytes", len(response))
+	}
+
+	_, err = c.invoke(context.Background(), "/test.Echo/Missing", nil)
+	var ge *grpcError
+	if !errors.As(err, &ge) || ge.code != grpcNotFound || ge.message != "model is not found" {
+		t.Fatalf("Unexpected gRPC error: %v", err)
+	}
+}
+
+func TestHPACKDecoder(t *testing.T) {
+	// RFC 7541 C.4.1, C.4.2 (Huffman 코드와 dynamic table)
+	d := newHPACKDecoder()
+	fields := make(map[string]string)
+	if err := d.decode([]byte{0x82, 0x86, 0x84, 0x41, 0x8c, 0xf1, 0xe3, 0xc2, 0xe5, 0xf2, 0x3a, 0x6b, 0xa0, 0xab, 0x90, 0xf4, 0xff}, fields); err != nil {
+		t.Fatal(err)
+	}
+	if fields[":method"] != "GET" || fields[":path"] != "/" || fields[":authority"] != "www.example.com" {
+		t.Fatalf("Unexpected fields: %v", fields)
+	}
+
+	fields = make(map[string]string)
+	if err := d.decode([]byte{0x82, 0x86, 0x84, 0xbe, 0x58, 0x86, 0xa8, 0xeb, 0x10, 0x64, 0x9c, 0xbf}, fields); err != nil {
+		t.Fatal(err)
+	}
+	if fields[":authority"] != "www.example.com" || fields["cache-control"] != "no-cache" {
+		t.Fatalf("Unexpected fields: %v", fields)
+	}
+
+	if _, err := huffmanDecode([]byte{0xf1, 0xe3, 0xc2, 0xe5, 0xf2, 0x3a, 0x6b, 0xa0, 0xab, 0x90, 0xf4, 0x00}); err == nil {
+		t.Fatalf("Invalid padding should fail")
+	}
+}
@@ -0,0 +1,107 @@
+package inference
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// HEIC/HEIF 파일의 ftyp box에 있는 major brand
+//
+// mif1, msf1은 AVIF에서도 사용하지만 AVIF는 보통 avif brand를 사용
+var heicBrands = map[string]bool{
+	"heic": true, "heix": true, "hevc": true, "hevx": true,
+	"heim": true, "heis": true, "hevm": true, "hevs": true,
+	"mif1": true, "msf1": true,
+}
+
+// HEICConverter HEIC/HEIF 이미지를 JPEG로 변환 (예: 외부 프로그램, cgo libheif)
+//
+// 여러 goroutine에서 동시에 호출 됨
+type HEICConverter interface {
+	ConvertHEIC(ctx context.Context, image []byte) ([]byte, error)
+}
+
+// HEICConverterFunc 함수를 HEICConverter로 사용
+type HEICConverterFunc func(ctx context.Context, image []byte) ([]byte, error)
+
+// ConvertHEIC f(ctx, image) 호출
+func (f HEICConverterFunc) ConvertHEIC(ctx context.Context, image []byte) ([]byte, error) {
+	return f(ctx, image)
+}
+
+// CommandConverter 외부 프로그램으로 HEIC를 JPEG로 변환
+//
+// 프로그램은 stdin으로 HEIC 이미지를 받아 stdout으로 JPEG 이미지를 출력해야 함
+// (예: ImageMagick의 `magick heic:- jpeg:-`)
+type CommandConverter struct {
+	Path string
+	Args []string
+}
+
+// ConvertHEIC 프로그램을 실행하여 변환 된 이미지 반환
+func (c *CommandConverter) ConvertHEIC(ctx context.Context, image []byte) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, c.Path, c.Args...)
+	cmd.Stdin = bytes.NewReader(image)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %s: %s", c.Path, err, msg)
+		}
+		return nil, fmt.Errorf("%s: %s", c.Path, err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// isHEIC HEIC/HEIF 이미지인지 확인
+//
+// 이미지 내용으로 판단하며, 내용으로 형식을 알 수 없으면 요청한 형식을 사용
+func isHEIC(image []byte, format string) bool {
+	switch DetectFormat(image) {
+	case "heic":
+		return format == "" || heicFormat(format)
+	case "":
+		return heicFormat(format)
+	}
+
+	return false
+}
+
+func heicFormat(format string) bool {
+	switch strings.ToLower(format) {
+	case "heic", "heif":
+		return true
+	}
+
+	return false
+}
+
+// convertHEIC HEIC/HEIF 이미지를 HEICConverter로 JPEG로 변환 (다른 형식은 그대로 반환)
+func (m *iModel) convertHEIC(ctx context.Context, image []byte, format string) ([]byte, string, error) {
+	if !isHEIC(image, format) {
+		return image, format, nil
+	}
+	if m.heicConverter == nil {
+		return nil, "", fmt.Errorf("%w: heic (no converter)", ErrUnsupportedFormat)
+	}
+
+	converted, err := m.heicConverter.ConvertHEIC(ctx, image)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, "", ctx.Err()
+		}
+		return nil, "", fmt.Errorf("%w: heic: %s", ErrImageConversion, err)
+	}
+	if DetectFormat(converted) != "jpeg" {
+		return nil, "", fmt.Errorf("%w: heic: converted image is not JPEG", ErrImageConversion)
+	}
+
+	return converted, "jpeg", nil
+}
@@ -0,0 +1,59 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestConvertHEIC(t *testing.T) {
+	ctx := context.Background()
+	heic := []byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00")
+	jpeg := []byte("\xff\xd8\xff\xe0\x00\x10JFIF")
+
+	m := &iModel{}
+	if _, _, err := m.convertHEIC(ctx, heic, ""); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("HEIC without converter should be unsupported: %v", err)
+	}
+	if image, format, err := m.convertHEIC(ctx, jpeg, "jpg"); err != nil || format != "jpg" || string(image) != string(jpeg) {
+		t.Fatalf("Non-HEIC image should not be converted: %q %s %v", image, format, err)
+	}
+
+	m.heicConverter = HEICConverterFunc(func(ctx context.Context, image []byte) ([]byte, error) {
+		return jpeg, nil
+	})
+	for _, format := range []string{"", "heic", "HEIF"} {
+		image, f, err := m.convertHEIC(ctx, heic, format)
+		if err != nil || f != "jpeg" || string(image) != string(jpeg) {
+			t.Errorf("convertHEIC(%q) = %q, %s, %v", format, image, f, err)
+		}
+	}
+	// 형식이 다르면 resolveFormat에서 FormatMismatchError
+	if _, f, err := m.convertHEIC(ctx, heic, "png"); err != nil || f != "png" {
+		t.Fatalf("Mismatched format should not be converted: %s %v", f, err)
+	}
+
+	m.heicConverter = HEICConverterFunc(func(ctx context.Context, image []byte) ([]byte, error) {
+		return image, nil
+	})
+	if _, _, err := m.convertHEIC(ctx, heic, ""); !errors.Is(err, ErrImageConversion) {
+		t.Fatalf("Non-JPEG output should fail: %v", err)
+	}
+}
+
+func TestCommandConverter(t *testing.T) {
+	ctx := context.Background()
+
+	c := &CommandConverter{Path: "sh", Args: []string{"-c", `cat >/dev/null; printf '\377\330\377'`}}
+	image, err := c.ConvertHEIC(ctx, []byte("heic"))
+	if err != nil || DetectFormat(image) != "jpeg" {
+		t.Fatalf("Unexpected conversion: %q %v", image, err)
+	}
+
+	c = &CommandConverter{Path: "sh", Args: []string{"-c", "echo 'bad input' >&2; exit 1"}}
+	m := &iModel{heicConverter: c}
+	_, _, err = m.convertHEIC(ctx, []byte("heic"), "heic")
+	if !errors.Is(err, ErrImageConversion) {
+		t.Fatalf("Command failure should fail conversion: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// PreProcessFunc 추론 전에 이미지와 이미지 형식을 변환하는 hook (예: 도메인에 맞는 crop)
+type PreProcessFunc func(ctx context.Context, model string, image []byte, format string) ([]byte, string, error)
+
+// PostProcessFunc 추론 결과를 변환하는 hook (예: 업무 규칙에 따른 결과 필터링)
+type PostProcessFunc func(ctx context.Context, model string, infers []InferLabel) ([]InferLabel, error)
+
+// Hook 모델 config의 `hooks`에 이름으로 지정하는 전처리/후처리
+//
+// PreProcess와 PostProcess 중 필요한 것만 지정
+type Hook struct {
+	PreProcess  PreProcessFunc
+	PostProcess PostProcessFunc
+}
+
+var (
+	hooks      = make(map[string]Hook)
+	hooksMutex sync.RWMutex
+)
+
+// RegisterHook name으로 hook 등록
+//
+// 모델 로드 전에 등록해야 하며, 보통 배포 패키지의 init에서 호출.
+// 같은 이름으로 다시 등록하면 이후 로드 되는 모델부터 적용 됨
+func RegisterHook(name string, h Hook) {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+
+	hooks[name] = h
+}
+
+// lookupHooks config에 지정된 순서대로 hook 반환
+func lookupHooks(names []string) ([]Hook, []string) {
+	hooksMutex.RLock()
+	defer hooksMutex.RUnlock()
+
+	var (
+		found      []Hook
+		violations []string
+	)
+	for _, name := range names {
+		h, ok := hooks[name]
+		if !ok {
+			violations = append(violations, fmt.Sprintf("`hooks` has unregistered hook: %s", name))
+			continue
+		}
+		found = append(found, h)
+	}
+
+	return found, violations
+}
+
+func (m *iModel) preProcess(ctx context.Context, image []byte, format string) ([]byte, string, error) {
+	var err error
+
+	for _, h := range m.hooks {
+		if h.PreProcess == nil {
+			continue
+		}
+		if image, format, err = h.PreProcess(ctx, m.name, image, format); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return image, format, nil
+}
+
+func (m *iModel) postProcess(ctx context.Context, infers []InferLabel) ([]InferLabel, error) {
+	var err error
+
+	for _, h := range m.hooks {
+		if h.PostProcess == nil {
+			continue
+		}
+		if infers, err = h.PostProcess(ctx, m.name, infers); err != nil {
+			return nil, err
+		}
+	}
+
+	return infers, nil
+}
@@ -0,0 +1,92 @@
+package inference
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestHooks(t *testing.T) {
+	RegisterHook("test-upper", Hook{
+		PreProcess: func(ctx context.Context, model string, image []byte, format string) ([]byte, string, error) {
+			return bytes.ToUpper(image), "png", nil
+		},
+	})
+	RegisterHook("test-drop-first", Hook{
+		PostProcess: func(ctx context.Context, model string, infers []InferLabel) ([]InferLabel, error) {
+			return infers[1:], nil
+		},
+	})
+	RegisterHook("test-fail", Hook{
+		PostProcess: func(ctx context.Context, model string, infers []InferLabel) ([]InferLabel, error) {
+			return nil, errors.New("Rejected by business rule")
+		},
+	})
+
+	if _, violations := lookupHooks([]string{"test-upper", "unknown"}); len(violations) != 1 {
+		t.Fatalf("Unregistered hook should fail: %v", violations)
+	}
+
+	found, violations := lookupHooks([]string{"test-upper", "test-drop-first"})
+	if len(violations) != 0 {
+		t.Fatal(violations)
+	}
+	m := &iModel{name: "pets", hooks: found}
+
+	ctx := context.Background()
+	image, format, err := m.preProcess(ctx, []byte("image"), "jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(image) != "IMAGE" || format != "png" {
+		t.Fatalf("Unexpected preprocess: %s, %s", image, format)
+	}
+
+	infers, err := m.postProcess(ctx, []InferLabel{{Label: "cat"}, {Label: "dog"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infers) != 1 || infers[0].Label != "dog" {
+		t.Fatalf("Unexpected postprocess: %v", infers)
+	}
+
+	m.hooks, _ = lookupHooks([]string{"test-fail"})
+	if _, err := m.postProcess(ctx, infers); err == nil {
+		t.Fatal("Hook error should be returned")
+	}
+}
+
+func TestPreprocessor(t *testing.T) {
+	RegisterPreprocessor("test-constant", PreprocessorFunc(func(ctx context.Context, model string, image []byte, format string) (*Tensor, error) {
+		if format != "png" {
+			return nil, ErrUnsupportedFormat
+		}
+		return &Tensor{Shape: []int{1, 2, 1}, Data: []float32{0, 1}}, nil
+	}))
+
+	if _, violations := lookupPreprocessor("unknown"); len(violations) != 1 {
+		t.Fatalf("Unregistered preprocessor should fail: %v", violations)
+	}
+	if p, violations := lookupPreprocessor(""); p != nil || len(violations) != 0 {
+		t.Fatalf("Unexpected preprocessor: %v, %v", p, violations)
+	}
+
+	p, violations := lookupPreprocessor("test-constant")
+	if len(violations) != 0 {
+		t.Fatal(violations)
+	}
+
+	// 모델 입력과 맞지 않는 tensor는 Preprocessor의 문제
+	m := &iModel{
+		name:         "pets",
+		cfg:          modelConfig{InputShape: []int32{2, 1, 1}, Preprocessor: "test-constant"},
+		preprocessor: p,
+	}
+	if _, err := m.runPreprocessor(context.Background(), []byte("\x89PNG\r\n\x1a\n"), ""); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := m.runPreprocessor(context.Background(), []byte("image"), "jpeg"); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
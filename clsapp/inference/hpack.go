@@ -0,0 +1,307 @@
+package inference
+
+import (
+	"errors"
+	"fmt"
+)
+
+// HPACK(RFC 7541) 헤더 압축
+//
+// 요청 헤더는 인덱스와 Huffman 없이 literal로 보내고, 응답 헤더는 static/dynamic table과 Huffman 코드를 모두 해석
+
+// hpackMaxTableSize dynamic table의 기본 최대 크기 (SETTINGS_HEADER_TABLE_SIZE를 바꾸지 않음)
+const hpackMaxTableSize = 4096
+
+// hpackStaticTable static table (index 1부터)
+var hpackStaticTable = [...][2]string{
+	{":authority", ""}, {":method", "GET"}, {":method", "POST"}, {":path", "/"}, {":path", "/index.html"},
+	{":scheme", "http"}, {":scheme", "https"}, {":status", "200"}, {":status", "204"}, {":status", "206"},
+	{":status", "304"}, {":status", "400"}, {":status", "404"}, {":status", "500"}, {"accept-charset", ""},
+	{"accept-encoding", "gzip, deflate"}, {"accept-language", ""}, {"accept-ranges", ""}, {"accept", ""},
+	{"access-control-allow-origin", ""}, {"age", ""}, {"allow", ""}, {"authorization", ""}, {"cache-control", ""},
+	{"content-disposition", ""}, {"content-encoding", ""}, {"content-language", ""}, {"content-length", ""},
+	{"content-location", ""}, {"content-range", ""}, {"content-type", ""}, {"cookie", ""}, {"date", ""},
+	{"etag", ""}, {"expect", ""}, {"expires", ""}, {"from", ""}, {"host", ""}, {"if-match", ""},
+	{"if-modified-since", ""}, {"if-none-match", ""}, {"if-range", ""}, {"if-unmodified-since", ""},
+	{"last-modified", ""}, {"link", ""}, {"location", ""}, {"max-forwards", ""}, {"proxy-authenticate", ""},
+	{"proxy-authorization", ""}, {"range", ""}, {"referer", ""}, {"refresh", ""}, {"retry-after", ""},
+	{"server", ""}, {"set-cookie", ""}, {"strict-transport-security", ""}, {"transfer-encoding", ""},
+	{"user-agent", ""}, {"vary", ""}, {"via", ""}, {"www-authenticate", ""},
+}
+
+// appendHPACKField 인덱스하지 않는 literal 헤더 (새 이름)
+func appendHPACKField(b []byte, name, value string) []byte {
+	b = append(b, 0)
+	b = appendHPACKInt(b, 0, 7, uint64(len(name)))
+	b = append(b, name...)
+	b = appendHPACKInt(b, 0, 7, uint64(len(value)))
+
+	return append(b, value...)
+}
+
+// appendHPACKInt n bit prefix 정수
+func appendHPACKInt(b []byte, prefix byte, n uint, v uint64) []byte {
+	max := uint64(1)<<n - 1
+	if v < max {
+		return append(b, prefix|byte(v))
+	}
+
+	b = append(b, prefix|byte(max))
+	for v -= max; v >= 0x80; v >>= 7 {
+		b = append(b, byte(v)|0x80)
+	}
+
+	return append(b, byte(v))
+}
+
+// hpackDecoder 연결의 응답 헤더 블록을 해석 (dynamic table은 연결의 헤더 블록이 공유)
+type hpackDecoder struct {
+	// 최근에 추가한 항목이 앞
+	table   [][2]string
+	size    int
+	maxSize int
+}
+
+func newHPACKDecoder() *hpackDecoder {
+	return &hpackDecoder{maxSize: hpackMaxTableSize}
+}
+
+// decode 헤더 블록의 필드를 fields에 추가 (같은 이름은 먼저 나온 값 사용)
+func (d *hpackDecoder) decode(block []byte, fields map[string]string) error {
+	for len(block) > 0 {
+		var (
+			field [2]string
+			index uint64
+			err   error
+		)
+
+		b := block[0]
+		switch {
+		case b&0x80 != 0:
+			// indexed
+			if index, block, err = readHPACKInt(block, 7); err != nil {
+				return err
+			}
+			if field, err = d.at(index); err != nil {
+				return err
+			}
+		case b&0xe0 == 0x20:
+			// dynamic table 크기 변경
+			var size uint64
+			if size, block, err = readHPACKInt(block, 5); err != nil {
+				return err
+			}
+			if size > hpackMaxTableSize {
+				return fmt.Errorf("Invalid HPACK table size: %d", size)
+			}
+			d.maxSize = int(size)
+			d.evict()
+			continue
+		default:
+			// literal (0x40: dynamic table에 추가, 0x00, 0x10: 추가하지 않음)
+			indexing := b&0xc0 == 0x40
+			prefix := uint(4)
+			if indexing {
+				prefix = 6
+			}
+			if index, block, err = readHPACKInt(block, prefix); err != nil {
+				return err
+			}
+			if index == 0 {
+				if field[0], block, err = readHPACKString(block); err != nil {
+					return err
+				}
+			} else {
+				var named [2]string
+				if named, err = d.at(index); err != nil {
+					return err
+				}
+				field[0] = named[0]
+			}
+			if field[1], block, err = readHPACKString(block); err != nil {
+				return err
+			}
+			if indexing {
+				d.add(field)
+			}
+		}
+
+		if _, ok := fields[field[0]]; !ok {
+			fields[field[0]] = field[1]
+		}
+	}
+
+	return nil
+}
+
+// at static table 다음에 dynamic table이 이어지는 index의 필드
+func (d *hpackDecoder) at(index uint64) ([2]string, error) {
+	switch {
+	case index >= 1 && index <= uint64(len(hpackStaticTable)):
+		return hpackStaticTable[index-1], nil
+	case index > uint64(len(hpackStaticTable)) && index-uint64(len(hpackStaticTable)) <= uint64(len(d.table)):
+		return d.table[index-uint64(len(hpackStaticTable))-1], nil
+	default:
+		return [2]string{}, fmt.Errorf("Invalid HPACK index: %d", index)
+	}
+}
+
+// add dynamic table에 추가 (크기는 이름과 값의 길이 + 32)
+func (d *hpackDecoder) add(field [2]string) {
+	d.table = append([][2]string{field}, d.table...)
+	d.size += len(field[0]) + len(field[1]) + 32
+	d.evict()
+}
+
+// evict 최대 크기를 넘지 않도록 오래 된 항목부터 삭제
+func (d *hpackDecoder) evict() {
+	for d.size > d.maxSize && len(d.table) > 0 {
+		last := d.table[len(d.table)-1]
+		d.size -= len(last[0]) + len(last[1]) + 32
+		d.table = d.table[:len(d.table)-1]
+	}
+}
+
+var errHPACKTruncated = errors.New("Truncated HPACK header block")
+
+// readHPACKInt n bit prefix 정수
+func readHPACKInt(b []byte, n uint) (uint64, []byte, error) {
+	if len(b) == 0 {
+		return 0, nil, errHPACKTruncated
+	}
+
+	max := uint64(1)<<n - 1
+	v := uint64(b[0]) & max
+	b = b[1:]
+	if v < max {
+		return v, b, nil
+	}
+
+	for shift := uint(0); ; shift += 7 {
+		if len(b) == 0 {
+			return 0, nil, errHPACKTruncated
+		}
+		if shift > 28 {
+			return 0, nil, errors.New("HPACK integer overflow")
+		}
+		c := b[0]
+		b = b[1:]
+		v += uint64(c&0x7f) << shift
+		if c&0x80 == 0 {
+			return v, b, nil
+		}
+	}
+}
+
+// readHPACKString 문자열 (첫 bit가 1이면 Huffman 코드)
+func readHPACKString(b []byte) (string, []byte, error) {
+	if len(b) == 0 {
+		return "", nil, errHPACKTruncated
+	}
+
+	huffman := b[0]&0x80 != 0
+	n, b, err := readHPACKInt(b, 7)
+	if err != nil {
+		return "", nil, err
+	}
+	if uint64(len(b)) < n {
+		return "", nil, errHPACKTruncated
+	}
+
+	s := b[:n]
+	if !huffman {
+		return string(s), b[n:], nil
+	}
+
+	decoded, err := huffmanDecode(s)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return string(decoded), b[n:], nil
+}
+
+// huffmanCodeLen HPACK Huffman 코드(RFC 7541 Appendix B)의 symbol별 bit 수 (마지막은 EOS)
+//
+// canonical Huffman 코드이므로 bit 수로 코드를 만들 수 있음
+var huffmanCodeLen = [257]uint8{
+	13, 23, 28, 28, 28, 28, 28, 28, 28, 24, 30, 28, 28, 30, 28, 28,
+	28, 28, 28, 28, 28, 28, 30, 28, 28, 28, 28, 28, 28, 28, 28, 28,
+	6, 10, 10, 12, 13, 6, 8, 11, 10, 10, 8, 11, 8, 6, 6, 6,
+	5, 5, 5, 6, 6, 6, 6, 6, 6, 6, 7, 8, 15, 6, 12, 10,
+	13, 6, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7,
+	7, 7, 7, 7, 7, 7, 7, 7, 8, 7, 8, 13, 19, 13, 14, 6,
+	15, 5, 6, 5, 6, 5, 6, 6, 6, 5, 7, 7, 6, 6, 6, 5,
+	6, 7, 6, 5, 5, 6, 7, 7, 7, 7, 7, 15, 11, 14, 13, 28,
+	20, 22, 20, 20, 22, 22, 22, 23, 22, 23, 23, 23, 23, 23, 24, 23,
+	24, 24, 22, 23, 24, 23, 23, 23, 23, 21, 22, 23, 22, 23, 23, 24,
+	22, 21, 20, 22, 22, 23, 23, 21, 23, 22, 22, 24, 21, 22, 23, 23,
+	21, 21, 22, 21, 23, 22, 23, 23, 20, 22, 22, 22, 23, 22, 22, 23,
+	26, 26, 20, 19, 22, 23, 22, 25, 26, 26, 26, 27, 27, 26, 24, 25,
+	19, 21, 26, 27, 27, 26, 27, 24, 21, 21, 26, 26, 28, 27, 27, 27,
+	20, 24, 20, 21, 22, 21, 21, 23, 22, 22, 25, 25, 24, 24, 26, 23,
+	26, 27, 26, 26, 27, 27, 27, 27, 27, 28, 27, 27, 27, 27, 27, 26,
+	30,
+}
+
+const huffmanMaxLen = 30
+
+// huffmanFirst, huffmanCount, huffmanOffset bit 수별 첫 코드, 코드 수와 huffmanSymbols의 시작 위치
+var (
+	huffmanFirst   [huffmanMaxLen + 1]uint32
+	huffmanCount   [huffmanMaxLen + 1]uint32
+	huffmanOffset  [huffmanMaxLen + 1]uint32
+	huffmanSymbols []uint16
+)
+
+func init() {
+	for l := 1; l <= huffmanMaxLen; l++ {
+		for sym, n := range huffmanCodeLen {
+			if int(n) == l {
+				huffmanSymbols = append(huffmanSymbols, uint16(sym))
+				huffmanCount[l]++
+			}
+		}
+	}
+
+	var code, offset uint32
+	for l := 1; l <= huffmanMaxLen; l++ {
+		huffmanFirst[l] = code
+		huffmanOffset[l] = offset
+		offset += huffmanCount[l]
+		code = (code + huffmanCount[l]) << 1
+	}
+}
+
+// huffmanDecode Huffman 코드 문자열 해석
+func huffmanDecode(b []byte) ([]byte, error) {
+	var (
+		decoded []byte
+		code    uint32
+		n       int
+	)
+	for _, c := range b {
+		for bit := 7; bit >= 0; bit-- {
+			code = code<<1 | uint32(c>>uint(bit)&1)
+			n++
+
+			if idx := code - huffmanFirst[n]; code >= huffmanFirst[n] && idx < huffmanCount[n] {
+				sym := huffmanSymbols[huffmanOffset[n]+idx]
+				if sym == 256 {
+					return nil, errors.New("Invalid HPACK Huffman EOS")
+				}
+				decoded = append(decoded, byte(sym))
+				code, n = 0, 0
+			} else if n == huffmanMaxLen {
+				return nil, errors.New("Invalid HPACK Huffman code")
+			}
+		}
+	}
+
+	// 남은 bit는 EOS 코드의 앞부분(모두 1)인 padding
+	if n > 7 || code != 1<<uint(n)-1 {
+		return nil, errors.New("Invalid HPACK Huffman padding")
+	}
+
+	return decoded, nil
+}
@@ -0,0 +1,156 @@
+package inference
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+)
+
+// checkImage 디코딩 전에 이미지 구조를 검사하여 손상 된 이미지면 *CorruptImageError 반환
+//
+// format은 resolveFormat으로 확인한 형식이며, 구조를 검사하지 않는 형식은 디코딩 할 때 확인.
+// probe면 image.RegisterFormat으로 등록 된 Go 디코더로 전체를 디코딩하여 확인
+func checkImage(data []byte, format string, probe bool) error {
+	var err error
+	switch format {
+	case "jpeg":
+		err = checkJPEG(data)
+	case "png":
+		err = checkPNG(data)
+	case "gif":
+		if len(data) < 13 {
+			err = &CorruptImageError{Format: format, Offset: int64(len(data)), Reason: "truncated header"}
+		}
+	case "bmp":
+		err = checkBMP(data)
+	}
+	if err != nil || !probe || goDecodedFormats[format] {
+		return err
+	}
+
+	if _, _, err := image.Decode(bytes.NewReader(data)); err != nil && err != image.ErrFormat {
+		return &CorruptImageError{Format: format, Offset: -1, Reason: err.Error()}
+	}
+
+	return nil
+}
+
+// checkJPEG 이미지 데이터(SOS)까지의 segment 구조와 EOI marker 확인
+func checkJPEG(data []byte) error {
+	corrupt := func(offset int, reason string, args ...interface{}) error {
+		return &CorruptImageError{Format: "jpeg", Offset: int64(offset), Reason: fmt.Sprintf(reason, args...)}
+	}
+
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return corrupt(0, "missing SOI marker")
+	}
+
+	var frame bool
+	for pos := 2; ; {
+		if pos+2 > len(data) {
+			return corrupt(len(data), "truncated before image data")
+		}
+		if data[pos] != 0xff {
+			return corrupt(pos, "invalid marker 0x%02x", data[pos])
+		}
+
+		marker := data[pos+1]
+		switch {
+		case marker == 0xff:
+			// marker 앞의 0xff padding
+			pos++
+			continue
+		case marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7):
+			// 길이가 없는 marker
+			pos += 2
+			continue
+		case marker == 0xd9:
+			return corrupt(pos, "EOI marker before image data")
+		}
+
+		if pos+4 > len(data) {
+			return corrupt(len(data), "truncated segment 0x%02x", marker)
+		}
+		size := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		if size < 2 || pos+2+size > len(data) {
+			return corrupt(pos, "segment 0x%02x size %d exceeds file size", marker, size)
+		}
+
+		// SOF0 ~ SOF15 (DHT, JPG, DAC 제외)
+		if marker >= 0xc0 && marker <= 0xcf && marker != 0xc4 && marker != 0xc8 && marker != 0xcc {
+			frame = true
+		}
+
+		if marker == 0xda {
+			if !frame {
+				return corrupt(pos, "missing SOF marker before image data")
+			}
+			if !bytes.Contains(data[pos+2+size:], []byte{0xff, 0xd9}) {
+				return corrupt(len(data), "truncated image data: missing EOI marker")
+			}
+			return nil
+		}
+
+		pos += 2 + size
+	}
+}
+
+// checkPNG IHDR부터 IEND까지 chunk의 길이와 CRC 확인
+func checkPNG(data []byte) error {
+	corrupt := func(offset int, reason string, args ...interface{}) error {
+		return &CorruptImageError{Format: "png", Offset: int64(offset), Reason: fmt.Sprintf(reason, args...)}
+	}
+
+	if !bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")) {
+		return corrupt(0, "missing PNG signature")
+	}
+
+	for pos := 8; ; {
+		if pos == len(data) {
+			return corrupt(pos, "truncated: missing IEND chunk")
+		}
+		if pos+12 > len(data) {
+			return corrupt(pos, "truncated chunk")
+		}
+
+		length := int64(binary.BigEndian.Uint32(data[pos : pos+4]))
+		chunk := string(data[pos+4 : pos+8])
+		if int64(pos)+12+length > int64(len(data)) {
+			return corrupt(pos, "chunk %q length %d exceeds file size", chunk, length)
+		}
+		if pos == 8 && chunk != "IHDR" {
+			return corrupt(pos, "first chunk %q is not IHDR", chunk)
+		}
+
+		end := pos + 8 + int(length)
+		crc := binary.BigEndian.Uint32(data[end : end+4])
+		if crc32.ChecksumIEEE(data[pos+4:end]) != crc {
+			return corrupt(pos, "chunk %q CRC mismatch", chunk)
+		}
+
+		if chunk == "IEND" {
+			return nil
+		}
+		pos = end + 4
+	}
+}
+
+// checkBMP 파일 header의 픽셀 데이터 위치와 이미지 크기 확인
+func checkBMP(data []byte) error {
+	if len(data) < 26 {
+		return &CorruptImageError{Format: "bmp", Offset: int64(len(data)), Reason: "truncated header"}
+	}
+
+	offset := int64(binary.LittleEndian.Uint32(data[10:14]))
+	if offset >= int64(len(data)) {
+		return &CorruptImageError{Format: "bmp", Offset: 10, Reason: fmt.Sprintf("pixel data offset %d exceeds file size", offset)}
+	}
+
+	if _, _, ok := bmpDimensions(data); !ok {
+		return &CorruptImageError{Format: "bmp", Offset: 14, Reason: "invalid image size"}
+	}
+
+	return nil
+}
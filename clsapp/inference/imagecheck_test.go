@@ -0,0 +1,58 @@
+package inference
+
+import (
+	"errors"
+	"testing"
+)
+
+const (
+	validJPEG = "\xff\xd8" +
+		"\xff\xe0\x00\x04JF" +
+		"\xff\xc0\x00\x0b\x08\x00\x01\x00\x01\x01\x01\x11\x00" +
+		"\xff\xda\x00\x08\x01\x01\x00\x00\x3f\x00" +
+		"data\xff\xd9"
+	// IHDR(1x1 RGB)과 IEND
+	validPNG = "\x89PNG\r\n\x1a\n" +
+		"\x00\x00\x00\x0dIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x02\x00\x00\x00\x90\x77\x53\xde" +
+		"\x00\x00\x00\x00IEND\xae\x42\x60\x82"
+)
+
+func TestCheckImage(t *testing.T) {
+	tests := []struct {
+		data   string
+		format string
+		offset int64
+	}{
+		{validJPEG, "jpeg", -1},
+		{"image", "jpeg", 0},
+		// SOF 없이 SOS
+		{"\xff\xd8\xff\xda\x00\x08\x01\x01\x00\x00\x3f\x00data\xff\xd9", "jpeg", 2},
+		// segment 길이가 파일보다 김
+		{"\xff\xd8\xff\xe0\x00\x40JF", "jpeg", 2},
+		// EOI 없이 잘린 이미지
+		{validJPEG[:len(validJPEG)-2], "jpeg", int64(len(validJPEG) - 2)},
+		{validPNG, "png", -1},
+		{"\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR", "png", 8},
+		// IHDR의 CRC 불일치
+		{validPNG[:29] + "\x00\x00\x00\x00" + validPNG[33:], "png", 8},
+		// IEND 없이 잘린 이미지
+		{validPNG[:33], "png", 33},
+		{"BM\x00", "bmp", 3},
+		{"GIF89a", "gif", 6},
+	}
+
+	for _, tt := range tests {
+		err := checkImage([]byte(tt.data), tt.format, false)
+		if tt.offset < 0 {
+			if err != nil {
+				t.Errorf("checkImage(%q) = %v", tt.data, err)
+			}
+			continue
+		}
+
+		var corruptErr *CorruptImageError
+		if !errors.As(err, &corruptErr) || !errors.Is(err, ErrCorruptImage) || corruptErr.Offset != tt.offset {
+			t.Errorf("checkImage(%q) = %v, want offset %d", tt.data, err, tt.offset)
+		}
+	}
+}
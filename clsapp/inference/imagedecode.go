@@ -0,0 +1,134 @@
+package inference
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+)
+
+// Go에서 디코딩하여 RGB 픽셀을 전처리 graph(preprocessRaw)에 전달하는 이미지 형식
+//
+// TensorFlow에 디코더가 없거나(WebP, TIFF) 첫 프레임만 사용해야 하는(GIF) 형식이며,
+// goDecoders에 없는 형식은 image.RegisterFormat으로 등록 된 디코더를 사용.
+// 예를 들어 WebP와 TIFF는 golang.org/x/image/webp, golang.org/x/image/tiff를 import하면 디코딩 할 수 있음
+var goDecodedFormats = map[string]bool{
+	"webp": true,
+	"gif":  true,
+	"tiff": true,
+}
+
+// 형식별 디코더 (없으면 image.Decode 사용)
+var goDecoders = map[string]func(io.Reader) (image.Image, error){
+	"gif": decodeGIFFirstFrame,
+}
+
+// decodePixels 이미지를 디코딩하여 [height, width, 3] RGB 픽셀과 크기 반환
+//
+// background(RGB)가 있으면 alpha 채널로 배경색 위에 합성하며,
+// nil이면 TensorFlow 디코더의 3채널 변환과 같이 alpha 채널을 버림
+func decodePixels(data []byte, format string, background []float32) ([]byte, int, int, error) {
+	img, format, err := decodeImage(data, format)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w == 0 || h == 0 {
+		return nil, 0, 0, fmt.Errorf("Empty %s image", format)
+	}
+
+	return imagePixels(img, background, h, w), h, w, nil
+}
+
+// decodeImage 형식별 디코더 또는 image.RegisterFormat으로 등록 된 디코더로 이미지와 형식 반환
+func decodeImage(data []byte, format string) (image.Image, string, error) {
+	var (
+		img image.Image
+		err error
+	)
+	if decode, ok := goDecoders[format]; ok {
+		img, err = decode(bytes.NewReader(data))
+	} else {
+		img, format, err = image.Decode(bytes.NewReader(data))
+	}
+
+	if err == image.ErrFormat {
+		return nil, "", fmt.Errorf("%w: no registered decoder", ErrUnsupportedFormat)
+	} else if err != nil {
+		return nil, "", &CorruptImageError{Format: format, Offset: -1, Reason: err.Error()}
+	}
+
+	return img, format, nil
+}
+
+// imagePixels 이미지를 [h, w, 3] RGB 픽셀로 변환
+//
+// 이미지보다 작은 크기면 각 픽셀에 해당하는 영역의 평균(area)으로 줄이며,
+// alpha 채널은 decodePixels와 같이 합성하거나 버림
+func imagePixels(img image.Image, background []float32, h, w int) []byte {
+	bounds := img.Bounds()
+	srcH, srcW := bounds.Dy(), bounds.Dx()
+
+	pix := make([]byte, 0, w*h*3)
+	for y := 0; y < h; y++ {
+		y0, y1 := areaRange(y, srcH, h)
+		for x := 0; x < w; x++ {
+			x0, x1 := areaRange(x, srcW, w)
+
+			var r, g, b, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBAModel.Convert(img.At(bounds.Min.X+sx, bounds.Min.Y+sy)).(color.NRGBA)
+					if background != nil && c.A != 0xff {
+						c.R = compositeAlpha(c.R, c.A, background[0])
+						c.G = compositeAlpha(c.G, c.A, background[1])
+						c.B = compositeAlpha(c.B, c.A, background[2])
+					}
+					r, g, b, n = r+uint32(c.R), g+uint32(c.G), b+uint32(c.B), n+1
+				}
+			}
+			pix = append(pix, uint8((r+n/2)/n), uint8((g+n/2)/n), uint8((b+n/2)/n))
+		}
+	}
+
+	return pix
+}
+
+// areaRange 크기 dst로 줄였을 때 idx 번째 픽셀에 해당하는 원본(크기 src)의 범위 [start, end)
+func areaRange(idx, src, dst int) (int, int) {
+	start, end := idx*src/dst, (idx+1)*src/dst
+	if end <= start {
+		end = start + 1
+	}
+
+	return start, end
+}
+
+// decodeGIFFirstFrame 애니메이션 GIF의 첫 번째 프레임을 전체 화면 크기로 반환
+//
+// 프레임이 화면의 일부만 차지하면 나머지는 투명(alpha를 버리면 검은색)으로 채움
+func decodeGIFFirstFrame(r io.Reader) (image.Image, error) {
+	g, err := gif.DecodeAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(g.Image) == 0 {
+		return nil, fmt.Errorf("No frames in gif image")
+	}
+
+	frame := g.Image[0]
+	canvas := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	if canvas.Empty() || frame.Bounds() == canvas {
+		return frame, nil
+	}
+
+	img := image.NewNRGBA(canvas)
+	draw.Draw(img, frame.Bounds(), frame, frame.Bounds().Min, draw.Src)
+
+	return img, nil
+}
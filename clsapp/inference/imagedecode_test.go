@@ -0,0 +1,92 @@
+package inference
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/png"
+	"testing"
+)
+
+func TestDecodePixels(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.NRGBA{R: 10, G: 20, B: 30, A: 255})
+	img.Set(1, 0, color.NRGBA{R: 200, G: 100, B: 50, A: 128})
+
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		t.Fatal(err)
+	}
+
+	pix, h, w, err := decodePixels(b.Bytes(), "png", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h != 1 || w != 2 || !bytes.Equal(pix, []byte{10, 20, 30, 200, 100, 50}) {
+		t.Fatalf("Unexpected pixels: %dx%d %v", h, w, pix)
+	}
+
+	// 흰색 배경 위에 합성
+	pix, _, _, err = decodePixels(b.Bytes(), "png", []float32{255, 255, 255})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pix, []byte{10, 20, 30, 227, 177, 152}) {
+		t.Fatalf("Unexpected composited pixels: %v", pix)
+	}
+
+	// 등록 된 디코더가 없는 형식
+	if _, _, _, err := decodePixels([]byte("RIFF\x00\x00\x00\x00WEBPVP8 "), "webp", nil); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestDecodeGIFFirstFrame(t *testing.T) {
+	red := image.NewPaletted(image.Rect(0, 0, 2, 2), palette.Plan9)
+	blue := image.NewPaletted(image.Rect(0, 0, 2, 2), palette.Plan9)
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			red.Set(x, y, color.RGBA{R: 255, A: 255})
+			blue.Set(x, y, color.RGBA{B: 255, A: 255})
+		}
+	}
+
+	var b bytes.Buffer
+	if err := gif.EncodeAll(&b, &gif.GIF{
+		Image: []*image.Paletted{red, blue},
+		Delay: []int{10, 10},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	pix, h, w, err := decodePixels(b.Bytes(), "gif", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h != 2 || w != 2 || pix[0] != 255 || pix[2] != 0 {
+		t.Fatalf("Unexpected first frame: %dx%d %v", h, w, pix)
+	}
+
+	// 화면의 일부만 차지하는 첫 프레임은 화면 크기로 채움
+	small := image.NewPaletted(image.Rect(1, 1, 2, 2), palette.Plan9)
+	small.Set(1, 1, color.RGBA{G: 255, A: 255})
+	b.Reset()
+	if err := gif.EncodeAll(&b, &gif.GIF{
+		Image:  []*image.Paletted{small},
+		Delay:  []int{0},
+		Config: image.Config{Width: 3, Height: 2, ColorModel: color.Palette(palette.Plan9)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	pix, h, w, err = decodePixels(b.Bytes(), "gif", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h != 2 || w != 3 || !bytes.Equal(pix[12:15], []byte{0, 255, 0}) {
+		t.Fatalf("Unexpected canvas: %dx%d %v", h, w, pix)
+	}
+}
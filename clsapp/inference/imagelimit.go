@@ -0,0 +1,78 @@
+package inference
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+)
+
+// ImageLimits 추론할 이미지의 최대 크기 (0 이하면 제한 없음)
+//
+// 디코딩 전에 이미지 header로 확인하여, 큰 이미지가 디코더 안에서 메모리를 과도하게 사용하지 않도록 함
+type ImageLimits struct {
+	// 최대 bytes
+	MaxBytes int64
+	// 최대 너비, 높이 (pixel)
+	MaxWidth  int
+	MaxHeight int
+}
+
+// check 이미지가 최대 크기를 넘으면 *ImageTooLargeError 반환
+//
+// 크기를 읽을 수 없는 형식은 bytes만 확인
+func (l ImageLimits) check(data []byte) error {
+	if l.MaxBytes > 0 && int64(len(data)) > l.MaxBytes {
+		return &ImageTooLargeError{Bytes: int64(len(data)), Limits: l}
+	}
+
+	if l.MaxWidth <= 0 && l.MaxHeight <= 0 {
+		return nil
+	}
+
+	w, h, ok := imageDimensions(data)
+	if !ok {
+		return nil
+	}
+	if (l.MaxWidth > 0 && w > l.MaxWidth) || (l.MaxHeight > 0 && h > l.MaxHeight) {
+		return &ImageTooLargeError{Bytes: int64(len(data)), Width: w, Height: h, Limits: l}
+	}
+
+	return nil
+}
+
+// imageDimensions 디코딩 하지 않고 이미지 header에서 너비와 높이 반환
+//
+// image.RegisterFormat으로 등록 된 형식과 BMP를 지원
+func imageDimensions(data []byte) (int, int, bool) {
+	if DetectFormat(data) == "bmp" {
+		return bmpDimensions(data)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return cfg.Width, cfg.Height, true
+}
+
+// bmpDimensions BMP 파일 header(14 bytes) 다음의 DIB header에서 크기 반환
+func bmpDimensions(b []byte) (int, int, bool) {
+	if len(b) < 26 {
+		return 0, 0, false
+	}
+
+	// OS/2 BITMAPCOREHEADER는 16bit 크기
+	if binary.LittleEndian.Uint32(b[14:18]) == 12 {
+		return int(binary.LittleEndian.Uint16(b[18:20])), int(binary.LittleEndian.Uint16(b[20:22])), true
+	}
+
+	w := int32(binary.LittleEndian.Uint32(b[18:22]))
+	h := int32(binary.LittleEndian.Uint32(b[22:26]))
+	// 높이가 음수면 위에서 아래로 저장 된 이미지
+	if h < 0 {
+		h = -h
+	}
+
+	return int(w), int(h), w > 0
+}
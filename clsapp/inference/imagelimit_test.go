@@ -0,0 +1,77 @@
+package inference
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+)
+
+// bmpHeader 크기만 있는 BMP header (BITMAPINFOHEADER)
+func bmpHeader(w, h int32) string {
+	var b bytes.Buffer
+	b.WriteString("BM")
+	binary.Write(&b, binary.LittleEndian, uint32(0))
+	binary.Write(&b, binary.LittleEndian, uint32(0))
+	binary.Write(&b, binary.LittleEndian, uint32(54))
+	binary.Write(&b, binary.LittleEndian, uint32(40))
+	binary.Write(&b, binary.LittleEndian, w)
+	binary.Write(&b, binary.LittleEndian, h)
+
+	return b.String()
+}
+
+func TestImageLimits(t *testing.T) {
+	var img bytes.Buffer
+	if err := png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 40, 20))); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		limits ImageLimits
+		data   string
+		err    bool
+	}{
+		{ImageLimits{}, img.String(), false},
+		{ImageLimits{MaxBytes: int64(img.Len())}, img.String(), false},
+		{ImageLimits{MaxBytes: int64(img.Len() - 1)}, img.String(), true},
+		{ImageLimits{MaxWidth: 40, MaxHeight: 20}, img.String(), false},
+		{ImageLimits{MaxWidth: 39}, img.String(), true},
+		{ImageLimits{MaxHeight: 19}, img.String(), true},
+		{ImageLimits{MaxWidth: 100, MaxHeight: 100}, bmpHeader(200, -50), true},
+		{ImageLimits{MaxWidth: 100, MaxHeight: 100}, bmpHeader(50, -50), false},
+		// 크기를 알 수 없는 이미지는 bytes만 확인
+		{ImageLimits{MaxWidth: 1, MaxHeight: 1}, "image", false},
+	}
+
+	for idx, test := range tests {
+		err := test.limits.check([]byte(test.data))
+		if (err != nil) != test.err || (err != nil && !errors.Is(err, ErrImageTooLarge)) {
+			t.Errorf("%d: unexpected error: %v", idx, err)
+		}
+	}
+
+	var tooLarge *ImageTooLargeError
+	err := ImageLimits{MaxWidth: 39, MaxHeight: 39}.check(img.Bytes())
+	if !errors.As(err, &tooLarge) || tooLarge.Width != 40 || tooLarge.Height != 20 {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestInferImageLimits(t *testing.T) {
+	i := &Inference{
+		models:      make(map[string]*iModel),
+		imageLimits: ImageLimits{MaxBytes: 4},
+	}
+
+	// 모델을 찾기 전에 이미지 크기를 확인
+	if _, err := i.Infer(context.Background(), "none", []byte("image"), "", 1, 0); !errors.Is(err, ErrImageTooLarge) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := i.InferBatch(context.Background(), "none", [][]byte{[]byte("img"), []byte("image")}, "", 1, 0); !errors.Is(err, ErrImageTooLarge) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
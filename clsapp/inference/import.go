@@ -0,0 +1,200 @@
+package inference
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/constants"
+)
+
+// ImportModel learner 없이 외부에서 학습한 모델의 tar.gz 파일(SavedModel, config.yaml과 labels)을 등록
+//
+// 모델 디렉토리의 첫 버전에 풀어 로드하며, tar.gz의 최상위 디렉토리가 하나뿐이면 그 안의 파일을 사용.
+// config의 이름은 모델 이름(사용자 모델은 사용자를 뺀 이름도 가능)과 같아야 하며, 실패하면 풀어 놓은 파일을 삭제
+func (i *Inference) ImportModel(ctx context.Context, newModel string, archive io.Reader) (*CreateResult, error) {
+	if err := i.authorize(ctx, ActionCreateModel, newModel); err != nil {
+		return nil, err
+	}
+
+	m, err := i.reserveModel(newModel)
+	if err != nil {
+		return nil, err
+	}
+	defer i.putModel(m)
+
+	newM, err := i.importModel(ctx, m, archive)
+	if err != nil {
+		i.rwMutex.Lock()
+		i.delModelUncond(m)
+		i.rwMutex.Unlock()
+		return nil, err
+	}
+
+	if err := i.replaceReserved(m, newM); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Import model(%s): %s", newM.name, newM.versionPath)
+
+	return &CreateResult{
+		Model:   newM.name,
+		Type:    newM.cfg.Type,
+		Version: newM.version,
+	}, nil
+}
+
+// importModel archive를 m의 버전 디렉토리에 풀어 검사한 후 로드한 모델 반환
+func (i *Inference) importModel(ctx context.Context, m *iModel, archive io.Reader) (*iModel, error) {
+	if err := untarModel(archive, m.versionPath); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if m.tenant != "" {
+		i.rwMutex.RLock()
+		t, err := i.getTenant(m.tenant)
+		i.rwMutex.RUnlock()
+		if err == nil {
+			err = t.checkQuota()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cfg, err := loadConfig(m.versionPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+	}
+	if name, _ := splitModelName(m.name); cfg.Name != m.name && cfg.Name != name {
+		return nil, fmt.Errorf("%w: name[%s] in configuration does not match model[%s]", ErrInvalidArchive, cfg.Name, m.name)
+	}
+
+	newM := getNewModel("", m.modelPath)
+	newM.version = m.version
+	newM.versionPath = m.versionPath
+	if err := i.load(newM); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+	}
+	newM.name = m.name
+	newM.tenant = m.tenant
+
+	metadata := modelMetadata{
+		Name:      m.name,
+		Version:   m.version,
+		Source:    sourceImported,
+		CreatedAt: time.Now(),
+	}
+	if err := writeMetadata(m.versionPath, metadata); err != nil {
+		log.Printf("Fail to write metadata(%s): %s", m.versionPath, err)
+	}
+	if err := writeChecksums(m.versionPath, newM.cfg); err != nil {
+		log.Printf("Fail to write checksums(%s): %s", m.versionPath, err)
+	}
+
+	return newM, nil
+}
+
+// untarModel tar.gz 파일을 dir에 풀기
+//
+// 디렉토리 밖을 가리키는 경로와 링크는 허용하지 않으며, 최상위 디렉토리가 하나뿐이면 그 안의 파일을 dir로 옮김
+func untarModel(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+	}
+	defer gz.Close()
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	maxSize := int64(constants.MaxImportModelMB) << 20
+	var total int64
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+		}
+
+		name := path.Clean(hdr.Name)
+		if name == "." {
+			continue
+		}
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("%w: unsafe path: %s", ErrInvalidArchive, hdr.Name)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, os.ModePerm); err != nil {
+				return err
+			}
+		case tar.TypeReg, tar.TypeRegA:
+			if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+			if err != nil {
+				return err
+			}
+			n, err := io.Copy(f, io.LimitReader(tr, maxSize-total+1))
+			f.Close()
+			if err != nil {
+				return fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+			}
+			if total += n; total > maxSize {
+				return fmt.Errorf("%w: more than %d MB", ErrInvalidArchive, constants.MaxImportModelMB)
+			}
+		case tar.TypeSymlink, tar.TypeLink:
+			return fmt.Errorf("%w: link is not allowed: %s", ErrInvalidArchive, hdr.Name)
+		}
+	}
+
+	if isFile(filepath.Join(dir, configFile)) {
+		return nil
+	}
+
+	// 디렉토리를 통째로 압축한 경우
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	if len(entries) != 1 || !entries[0].IsDir() || !isFile(filepath.Join(dir, entries[0].Name(), configFile)) {
+		return fmt.Errorf("%w: no %s", ErrInvalidArchive, configFile)
+	}
+
+	// 최상위 디렉토리와 같은 이름의 파일이 있을 수 있으므로 먼저 이름을 바꿈
+	top := filepath.Join(dir, ".import")
+	if err := os.Rename(filepath.Join(dir, entries[0].Name()), top); err != nil {
+		return err
+	}
+	files, err := ioutil.ReadDir(top)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := os.Rename(filepath.Join(top, file.Name()), filepath.Join(dir, file.Name())); err != nil {
+			return err
+		}
+	}
+
+	return os.Remove(top)
+}
@@ -0,0 +1,84 @@
+package inference
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func tarGz(t *testing.T, headers []tar.Header) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, hdr := range headers {
+		content := hdr.Name
+		if hdr.Typeflag == tar.TypeReg {
+			hdr.Size = int64(len(content))
+		}
+		if err := tw.WriteHeader(&hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestUntarModel(t *testing.T) {
+	modelsPath := newModelsPath(t)
+	defer os.RemoveAll(modelsPath)
+
+	// 최상위 디렉토리 안의 파일을 사용
+	dir := filepath.Join(modelsPath, "nested")
+	archive := tarGz(t, []tar.Header{
+		{Name: "pets/", Typeflag: tar.TypeDir, Mode: 0755},
+		{Name: "pets/config.yaml", Typeflag: tar.TypeReg, Mode: 0644},
+		{Name: "pets/variables/variables.index", Typeflag: tar.TypeReg, Mode: 0644},
+		{Name: "pets/pets", Typeflag: tar.TypeReg, Mode: 0644},
+	})
+	if err := untarModel(bytes.NewReader(archive), dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{configFile, "variables/variables.index", "pets"} {
+		if !isFile(filepath.Join(dir, file)) {
+			t.Errorf("Missing file: %s", file)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		headers []tar.Header
+	}{
+		{"parent", []tar.Header{{Name: "../config.yaml", Typeflag: tar.TypeReg}}},
+		{"absolute", []tar.Header{{Name: "/etc/config.yaml", Typeflag: tar.TypeReg}}},
+		{"symlink", []tar.Header{{Name: "config.yaml", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}}},
+		{"noconfig", []tar.Header{{Name: "labels", Typeflag: tar.TypeReg}}},
+	}
+	for _, tt := range tests {
+		err := untarModel(bytes.NewReader(tarGz(t, tt.headers)), filepath.Join(modelsPath, tt.name))
+		if !errors.Is(err, ErrInvalidArchive) {
+			t.Errorf("Unexpected error of %s: %v", tt.name, err)
+		}
+	}
+	if isFile(filepath.Join(modelsPath, configFile)) {
+		t.Fatal("File should not be written outside the directory")
+	}
+
+	if err := untarModel(bytes.NewReader([]byte("not gzip")), filepath.Join(modelsPath, "invalid")); !errors.Is(err, ErrInvalidArchive) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// hasModelTags 모델 config의 modelTags에 tags가 모두 있는지 확인
+func (cfg *modelConfig) hasModelTags(tags []string) bool {
+	for _, tag := range tags {
+		found := false
+		for _, t := range cfg.ModelTags {
+			if t == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// InferAll 하나의 이미지를 로드 된 모든 분류 모델(tags를 지정하면 modelTags에 tags가 모두 있는 모델)로 동시에 추론
+//
+// 결과는 모델 이름 순서이며, 추론 권한이 없거나 준비되지 않은 모델과 detection 모델은 제외.
+// 일부 모델의 추론이 실패하면 해당 모델의 Error에 에러를 담고, 모든 모델이 실패하면 첫 번째 모델의 에러 반환
+func (i *Inference) InferAll(ctx context.Context, tags []string, image []byte, format string, k int, threshold float32) ([]ModelResult, error) {
+	if err := i.imageLimits.check(image); err != nil {
+		return nil, err
+	}
+
+	i.rwMutex.RLock()
+	var ms []*iModel
+	for name, m := range i.models {
+		// 추론을 중지한 모델은 제외
+		if atomic.LoadInt32(&m.status) != modelStatusRun || i.disabled[name] || m.checkClassifier() != nil || !m.cfg.hasModelTags(tags) {
+			continue
+		}
+		// 삭제를 기다리는 모델은 제외
+		if m := i.getModel(name); m != nil {
+			ms = append(ms, m)
+		}
+	}
+	i.rwMutex.RUnlock()
+
+	defer func() {
+		for _, m := range ms {
+			i.putModel(m)
+		}
+	}()
+
+	var allowed []*iModel
+	for _, m := range ms {
+		if i.authorize(ctx, ActionInfer, m.name) == nil {
+			allowed = append(allowed, m)
+		}
+	}
+	sort.Slice(allowed, func(a, b int) bool {
+		return allowed[a].name < allowed[b].name
+	})
+
+	var (
+		results = make([]ModelResult, len(allowed))
+		errs    = make([]error, len(allowed))
+		wg      sync.WaitGroup
+	)
+	for idx, m := range allowed {
+		wg.Add(1)
+		go func(idx int, m *iModel) {
+			defer wg.Done()
+
+			t0 := time.Now()
+			infers, err := m.infer(ctx, image, format, k, threshold)
+			i.observeInference(m.name, 1, t0, err)
+
+			results[idx] = ModelResult{
+				Model:     m.name,
+				Version:   m.version,
+				Inference: infers,
+			}
+			if err != nil {
+				results[idx].Error = err.Error()
+				errs[idx] = err
+			}
+		}(idx, m)
+	}
+	wg.Wait()
+
+	for idx, m := range allowed {
+		if errs[idx] == nil {
+			recordServed(ctx, m)
+		}
+	}
+
+	for idx, err := range errs {
+		if err == nil {
+			return results, nil
+		}
+		if idx == len(errs)-1 {
+			return nil, fmt.Errorf("%s: %w", results[0].Model, errs[0])
+		}
+	}
+
+	return results, nil
+}
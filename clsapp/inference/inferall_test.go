@@ -0,0 +1,27 @@
+package inference
+
+import "testing"
+
+func TestHasModelTags(t *testing.T) {
+	cfg := &modelConfig{ModelTags: []string{"plants", "outdoor"}}
+
+	tests := []struct {
+		tags []string
+		want bool
+	}{
+		{nil, true},
+		{[]string{"plants"}, true},
+		{[]string{"outdoor", "plants"}, true},
+		{[]string{"plants", "pets"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := cfg.hasModelTags(tt.tags); got != tt.want {
+			t.Errorf("hasModelTags(%v) = %v, want %v", tt.tags, got, tt.want)
+		}
+	}
+
+	if (&modelConfig{}).hasModelTags([]string{"plants"}) {
+		t.Fatal("Model without tags should not match")
+	}
+}
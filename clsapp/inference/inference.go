@@ -1,8 +1,8 @@
 package inference
 
 import (
-	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,31 +10,105 @@ import (
 	"log"
 	"net/http"
 	"os"
-	"path"
+	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/constants"
-	tf "github.com/tensorflow/tensorflow/tensorflow/go"
-	"github.com/tensorflow/tensorflow/tensorflow/go/op"
-	"gopkg.in/yaml.v2"
 )
 
-// Config 이미지 추론 모델 생성 설정정보
+// Config 이미지 추론 엔진 설정
+//
+// 각 항목은 With로 시작하는 Option으로 지정
 type Config struct {
-	UserModelPath string
-	LHost         string
+	// 모델 저장 경로 (기본값: constants.ModelsPath)
+	ModelsPath string
+	LHost      string
+	// 기본 모델을 생성할 수 없을 때 사용하는 모델 경로 (기본값: 사용 안함)
+	FallbackModelPath string
+	// 사용자 생성시 quota를 지정하지 않은 경우의 quota (byte, 0 이하면 제한 없음)
+	TenantQuota int64
+	// 새 모델의 디렉토리 이름 생성 방식 (기본값: DirNamingShortUUID)
+	DirNaming string
+	// 전처리 graph 저장 경로 (기본값: 모델 저장 경로의 .graphs, "-"이면 저장하지 않음)
+	GraphCachePath string
+	// 요청의 권한 확인 (기본값: 모두 허용)
+	AuthHook AuthHook
+	// 추론과 모델 로드 결과 수집 (기본값: 사용 안함)
+	Metrics Metrics
+	// 추론할 이미지의 최대 크기 (기본값: 제한 없음)
+	ImageLimits ImageLimits
+	// 추론 전에 Go 디코더로 이미지 전체를 디코딩하여 손상 여부 확인 (기본값: header와 구조만 확인)
+	ProbeDecode bool
+	// HEIC/HEIF 이미지를 JPEG로 변환 (기본값: 사용 안함, HEIC 이미지는 ErrUnsupportedFormat)
+	HEICConverter HEICConverter
+	// `format: tflite` 모델을 로드하는 TensorFlow Lite 실행 엔진
+	// (기본값: tflite build tag로 빌드하면 TensorFlow Lite C API, 아니면 사용 안함으로 tflite 모델은 config 에러)
+	TFLiteRuntime TFLiteRuntime
+	// 모델 입력 크기의 배수로, 이보다 큰 이미지는 Go에서 줄인 후 전처리 graph에 전달 (기본값: 0, 사용 안함)
+	PreDownscale float64
+	// gate 모델과 분류 모델을 순서대로 실행하는 pipeline 설정 파일 (기본값: 사용 안함)
+	PipelinesFile string
+	// 이미지 내용별 추론 결과 캐시의 최대 결과 수 (기본값: 0, 사용 안함)
+	ResultCacheSize int
+	// 캐시 된 추론 결과의 유효 시간 (기본값: 0, 만료되지 않음)
+	ResultCacheTTL time.Duration
+	// 동영상의 frame을 JPEG로 추출 (기본값: 사용 안함, 동영상 추론은 ErrUnsupportedFormat)
+	FrameSampler FrameSampler
+	// 모델 저장 경로를 감시하여 마지막 변경 후 이 시간 동안 변경이 없으면 추가 된 모델을 로드하고 삭제 된 모델을 해제
+	// (기본값: 0, 사용 안함)
+	WatchDebounce time.Duration
+	// 모델별로 보관할 최근 버전의 수로, 새 버전을 사용하면 이보다 오래된 버전을 삭제 (기본값: 0, 모두 보관)
+	KeepVersions int
+	// 로드 된 모델의 예상 메모리 합계 제한으로, 넘게 되는 모델은 로드하지 않음 (byte, 기본값: 0, 제한 없음)
+	MemoryBudget int64
+	// 모델을 로드한 후 사용하기 전에 합성 이미지로 실행하는 횟수 (기본값: 0, 사용 안함)
+	WarmupRuns int
 }
 
 // Inference 이미지 추론 모델 관리
 type Inference struct {
-	models        map[string]*iModel
-	rwMutex       sync.RWMutex
-	modelsPath    string
-	userModelPath string
+	models map[string]*iModel
+	// 모델 이름별 사용 중인 버전 외에 로드 된 버전 (생성 중인 버전 포함)
+	versions          map[string]map[int]*iModel
+	keepVersions      int
+	rwMutex           sync.RWMutex
+	modelsPath        string
+	fallbackModelPath string
+
+	tenants     map[string]*tenant
+	tenantQuota int64
+
+	// 별칭별 대상 모델 이름 (바꿀 때는 새 map으로 교체)
+	aliases map[string]string
+	// 추론을 중지한 모델 이름 (바꿀 때는 새 map으로 교체)
+	disabled map[string]bool
+
+	dirNamer DirNamer
+
+	authHook AuthHook
+	metrics  Metrics
+
+	imageLimits   ImageLimits
+	probeDecode   bool
+	heicConverter HEICConverter
+	tfliteRuntime TFLiteRuntime
+	// 전처리 graph 저장 경로 (빈 값이면 저장하지 않음)
+	graphCachePath string
+	preDownscale   float64
+	warmupRuns     int
+	frameSampler   FrameSampler
+	resultCache    *resultCache
+	memory         *memoryBudget
+
+	// pipeline 이름별 설정 (New 이후 바뀌지 않음)
+	pipelines map[string]pipelineConfig
+
+	// 모델 저장 경로 감시 (사용하지 않으면 nil)
+	watcher *modelsWatcher
 
 	lHost string
 }
@@ -42,60 +116,239 @@ type Inference struct {
 const (
 	binaryClass = "binary"
 	multiClass  = "multi"
+	// 여러 클래스를 포함하는 이미지를 분류하는 모델로, 클래스별 확률이 threshold 이상인 모든 클래스를 반환
+	multiLabelClass = "multilabel"
 )
 
-type trainingResult struct {
-	Epochs             int       `yaml:"epochs"`
-	InitLoss           float32   `yaml:"initLoss"`
-	InitAccuracy       float32   `yaml:"initAccuracy"`
-	TrainLoss          []float32 `yaml:"trainLoss"`
-	TrainAccuracy      []float32 `yaml:"trainAccuracy"`
-	ValidationLoss     []float32 `yaml:"validationLoss"`
-	ValidationAccuracy []float32 `yaml:"validationAccuracy"`
+// TrainingResult 모델 학습 결과 (learner가 config에 기록)
+type TrainingResult struct {
+	Epochs             int       `yaml:"epochs" json:"epochs"`
+	InitLoss           float32   `yaml:"initLoss" json:"initLoss"`
+	InitAccuracy       float32   `yaml:"initAccuracy" json:"initAccuracy"`
+	TrainLoss          []float32 `yaml:"trainLoss" json:"trainLoss"`
+	TrainAccuracy      []float32 `yaml:"trainAccuracy" json:"trainAccuracy"`
+	ValidationLoss     []float32 `yaml:"validationLoss" json:"validationLoss"`
+	ValidationAccuracy []float32 `yaml:"validationAccuracy" json:"validationAccuracy"`
 }
 
 type modelConfig struct {
-	Name                string         `yaml:"name"`
-	Type                string         `yaml:"type"`
-	Tags                []string       `yaml:"tags"`
-	Classification      string         `yaml:"classification"`
-	InputShape          []int32        `yaml:"inputShape"`
-	InputOperationName  string         `yaml:"inputOperationName"`
-	OutputOperationName string         `yaml:"outputOperationName"`
-	LabelsFile          string         `yaml:"labelsFile"`
-	TrainingResult      trainingResult `yaml:"trainingResult"`
-	Description         string         `yaml:"description"`
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+	// 모델 파일 형식 (saved_model, frozen_graph, tflite, tf_serving, triton 또는 등록 된 Backend, 기본값: saved_model)
+	Format              string   `yaml:"format"`
+	Tags                []string `yaml:"tags"`
+	Classification      string   `yaml:"classification"`
+	InputShape          []int32  `yaml:"inputShape"`
+	InputOperationName  string   `yaml:"inputOperationName"`
+	OutputOperationName string   `yaml:"outputOperationName"`
+	// outputOperationName의 출력 index (기본값: 0, `<operation>:<출력 index>` 이름과 함께 사용할 수 없음)
+	OutputIndex int `yaml:"outputIndex"`
+	// graph의 여러 출력에 붙인 이름 (이름: `<operation>` 또는 `<operation>:<출력 index>`)
+	// outputOperationName, embeddingOperationName 등 출력 operation 설정에 operation 대신 이름을 사용 할 수 있음
+	Outputs    map[string]string `yaml:"outputs"`
+	LabelsFile string            `yaml:"labelsFile"`
+	// 언어별 labels 파일 (예: ko: labels.ko.txt), 요청 언어가 있으면 결과의 label을 바꿈
+	LocalizedLabelsFiles map[string]string `yaml:"localizedLabelsFiles"`
+	TrainingResult       TrainingResult    `yaml:"trainingResult"`
+	Description          string            `yaml:"description"`
+	// binary 모델에서 positive 클래스로, multilabel 모델에서 각 클래스를 포함한 것으로 판단하는 확률 (기본값: 0.5)
+	Threshold float32 `yaml:"threshold"`
+	// multi 모델에서 요청에 k가 없을 때 반환할 상위 클래스 수 (기본값: constants.DefaultMultiClassMax)
+	DefaultTopK int `yaml:"defaultTopK"`
+	// multi 모델의 결과에 포함되기 위한 최소 확률 (기본값: 0, labels 파일의 클래스별 minProbability와 함께 적용)
+	MinProbability float32 `yaml:"minProbability"`
+	// 가장 높은 확률이 이 값 미만이면 결과를 unknownLabel 하나로 바꿈 (기본값: 0, 사용 안함)
+	UnknownThreshold float32 `yaml:"unknownThreshold"`
+	// unknownThreshold 미만인 결과의 라벨 (기본값: unknown)
+	UnknownLabel string `yaml:"unknownLabel"`
+	// 학습 없이 결과의 클래스 이름을 바꾸는 map (기존 이름: 새 이름), 여러 클래스를 같은 이름으로 바꾸면 하나로 합침
+	LabelMap map[string]string `yaml:"labelMap"`
+	// multi, multilabel 모델의 결과에서 항상 제외할 클래스 이름 (labelMap을 적용한 이름, 요청의 제외할 클래스와 함께 적용)
+	ExcludeLabels []string `yaml:"excludeLabels"`
+	// multi 모델에서 "/"로 구분 된 클래스 이름(예: dog/retriever/golden)의 확률을 상위 카테고리로 합산하여 결과에 함께 반환
+	LabelHierarchy bool `yaml:"labelHierarchy"`
+	// 모델 출력에 적용할 활성화 함수: none, softmax, sigmoid (기본값: none)
+	// binary 모델의 softmax는 두 클래스의 logits를 출력하는 경우에 사용
+	OutputActivation string `yaml:"outputActivation"`
+	// 활성화 함수를 적용하기 전에 모델 출력을 나누는 값으로, 검증 데이터셋으로 구한 confidence calibration 값
+	// (기본값: 0, 사용 안함, 1보다 크면 확률이 낮아짐)
+	Temperature float32 `yaml:"temperature"`
+	// 입력 이미지 채널 수: 1(grayscale), 3(RGB) (기본값: 3, inputShape의 channels와 같아야 함)
+	Channels int `yaml:"channels"`
+	// 이미지값 정규화 (image - mean) / std, scalar 또는 채널별 값 (기본값: 127.5, 127.5로 [-1, 1])
+	Mean channelValues `yaml:"mean"`
+	Std  channelValues `yaml:"std"`
+	// 모델 입력 tensor의 타입: float32, uint8 (기본값: float32, uint8은 정규화 하지 않음)
+	InputDType string `yaml:"inputDtype"`
+	// 모델 입력 tensor의 layout: NHWC, NCHW (기본값: NHWC)
+	InputLayout string `yaml:"inputLayout"`
+	// 이미지 크기 조정 방식: stretch, center-crop, letterbox (기본값: stretch)
+	ResizeMode string `yaml:"resizeMode"`
+	// 이미지 크기 조정의 보간 방식: bilinear, bicubic, nearest, area (기본값: bilinear)
+	ResizeMethod string `yaml:"resizeMethod"`
+	// 투명 이미지(PNG, WebP, GIF, TIFF)의 alpha 채널 처리 방식: strip, composite (기본값: strip)
+	Alpha string `yaml:"alpha"`
+	// composite에서 합성할 배경색, scalar 또는 RGB 값 (기본값: 255로 흰색)
+	AlphaBackground channelValues `yaml:"alphaBackground"`
+	// RegisterHook으로 등록 된 전처리/후처리 hook 이름 (지정한 순서대로 실행)
+	Hooks []string `yaml:"hooks"`
+	// test-time augmentation: flip, crop (지정한 변형 이미지와 원본의 확률을 평균)
+	TTA []string `yaml:"tta"`
+	// multi-crop 추론: 5(가운데와 네 모서리), 10(5-crop과 좌우 반전) (crop별 확률을 평균)
+	MultiCrop int `yaml:"multiCrop"`
+	// 내장 전처리 graph 대신 사용할 RegisterPreprocessor로 등록 된 Preprocessor 이름
+	Preprocessor string `yaml:"preprocessor"`
+	// InferAll에서 모델을 선택하는 태그 (예: 모델이 분류하는 대상)
+	ModelTags []string `yaml:"modelTags"`
+	// detection 모델의 박스([ymin, xmin, ymax, xmax], 0~1로 정규화), 점수, 클래스 출력 operation 이름
+	// (`<operation>:<출력 index>`로 operation의 출력을 지정 할 수 있음)
+	BoxesOperationName   string `yaml:"boxesOperationName"`
+	ScoresOperationName  string `yaml:"scoresOperationName"`
+	ClassesOperationName string `yaml:"classesOperationName"`
+	// detection 모델의 유효한 검출 수 출력 operation 이름 (기본값: 사용 안함, 모든 검출을 사용)
+	NumDetectionsOperationName string `yaml:"numDetectionsOperationName"`
+	// detection 모델의 클래스 출력에서 빼서 labels의 index로 사용하는 값 (예: 클래스가 1부터 시작하면 1)
+	ClassOffset int `yaml:"classOffset"`
+	// 유사 이미지 검색에 사용할 embedding([N, D] 또는 [N, 1, 1, D]) 출력 operation 이름 (기본값: 검색 사용 안함)
+	EmbeddingOperationName string `yaml:"embeddingOperationName"`
+	// Grad-CAM heatmap에 사용할 conv layer 출력([1, H, W, C]) operation 이름 (기본값: 사용 안함)
+	CAMOperationName string `yaml:"camOperationName"`
+	// 클래스 점수의 conv layer 출력에 대한 gradient([1, H, W, C]) operation 이름
+	// (기본값: 사용 안함, gradient 없이 conv layer 출력의 채널 평균을 heatmap으로 사용)
+	CAMGradientOperationName string `yaml:"camGradientOperationName"`
+	// gradient를 계산할 클래스의 labels index를 전달하는 int32 [1] 입력 operation 이름
+	// (기본값: 사용 안함, gradient operation이 예측 클래스의 gradient를 출력)
+	CAMClassOperationName string `yaml:"camClassOperationName"`
+	// 동시에 실행하는 모델 실행(Session.Run) 수 (기본값: 0, 제한 없음)
+	// 넘는 요청은 기다리며, 기다리는 중에 요청이 취소되면 실행하지 않음
+	MaxConcurrentRuns int `yaml:"maxConcurrentRuns"`
+	// `format: tf_serving` 모델이 추론을 요청할 TensorFlow Serving
+	TFServing *tfServingConfig `yaml:"tfServing"`
+	// `format: triton` 모델이 추론을 요청할 Triton Inference Server
+	Triton *tritonConfig `yaml:"triton"`
+}
+
+// channels 입력 이미지 채널 수
+func (cfg *modelConfig) channels() int {
+	if cfg.Channels == 0 {
+		return 3
+	}
+
+	return cfg.Channels
+}
+
+// threshold 요청의 threshold가 유효하지 않으면 모델의 threshold를 반환
+func (cfg *modelConfig) threshold(threshold float32) float32 {
+	if validThreshold(threshold) {
+		return threshold
+	}
+
+	if validThreshold(cfg.Threshold) {
+		return cfg.Threshold
+	}
+
+	return constants.DefaultBinaryThreshold
+}
+
+// topK 요청한 상위 클래스 수 (요청하지 않았으면 config의 defaultTopK 또는 기본값)
+func (cfg *modelConfig) topK(k int) int {
+	if k > 0 {
+		return k
+	}
+
+	if cfg.DefaultTopK > 0 {
+		return cfg.DefaultTopK
+	}
+
+	return constants.DefaultMultiClassMax
+}
+
+// unknownLabel unknownThreshold 미만인 결과의 라벨
+func (cfg *modelConfig) unknownLabel() string {
+	if cfg.UnknownLabel != "" {
+		return cfg.UnknownLabel
+	}
+
+	return constants.DefaultUnknownLabel
+}
+
+// unknown 가장 높은 확률이 unknownThreshold 미만이면 unknownLabel 하나로 바꿈
+//
+// 숨김 클래스 등으로 결과가 없으면 확률 0의 unknownLabel 반환
+func (cfg *modelConfig) unknown(infers []InferLabel) []InferLabel {
+	if cfg.UnknownThreshold <= 0 {
+		return infers
+	}
+
+	var prob float32
+	if len(infers) > 0 {
+		prob = infers[0].Prob
+	}
+	if prob >= cfg.UnknownThreshold {
+		return infers
+	}
+
+	return []InferLabel{{Prob: prob, Label: cfg.unknownLabel(), Index: -1, Unknown: true}}
+}
+
+func validThreshold(threshold float32) bool {
+	return threshold > 0 && threshold < 1
 }
 
 func (i *Inference) loadModels() error {
+	i.migrateModels()
+
 	dirs, _ := ioutil.ReadDir(i.modelsPath)
 
 	for _, dir := range dirs {
-		modelPath := path.Join(i.modelsPath, dir.Name())
+		// 모델 이름은 `.`으로 시작할 수 없으므로 모델 디렉토리가 아님 (사용자 모델 저장 경로 등)
+		if strings.HasPrefix(dir.Name(), ".") {
+			continue
+		}
+		// 변환을 마치지 못한 디렉토리는 다음 시작시 다시 변환
+		if strings.HasSuffix(dir.Name(), migratingSuffix) {
+			log.Printf("Skip model under migration: %s", dir.Name())
+			continue
+		}
+
+		modelPath := filepath.Join(i.modelsPath, dir.Name())
 
 		m := getNewModel("", modelPath)
-		if err := loadModel(m); err != nil {
+		if err := i.load(m); err != nil {
+			if keepFailedModel(err) {
+				log.Printf("Skip model(%s): %s", modelPath, err)
+				continue
+			}
 			log.Printf("Fail to load model(%s): %s", modelPath, err)
 			i.delModelUncond(m)
 		} else {
 			if err := i.addModel(m); err != nil {
 				log.Print(err)
+			} else {
+				i.loadVersions(m)
 			}
 		}
 	}
 
-	if i.userModelPath != "" {
-		m := getNewModel("", i.userModelPath)
-		if err := loadModel(m); err != nil {
-			log.Printf("Fail to load user model(%s): %s", i.userModelPath, err)
-		} else {
-			if err := i.addModel(m); err != nil {
-				log.Print(err)
-			}
+	i.loadTenants()
+	i.loadAliases()
+	i.loadDisabled()
+
+	return nil
+}
+
+// keepFailedModel 시작시 로드에 실패해도 모델 디렉토리를 삭제하지 않는 에러
+//
+// 모델 파일은 유효하지만 지금 로드할 수 없거나(메모리 제한, 원격 추론 서버 연결 실패) 원인을 확인해야 하는 경우(checksum 불일치, 잘못된 config)로,
+// 로드하지 않고 그대로 두어 원인을 해결한 후 다시 로드 할 수 있게 함
+func keepFailedModel(err error) bool {
+	for _, keep := range []error{ErrMemoryBudgetExceeded, ErrBackendUnavailable, ErrChecksumMismatch, ErrInvalidConfig} {
+		if errors.Is(err, keep) {
+			return true
 		}
 	}
 
-	return nil
+	return false
 }
 
 func (i *Inference) init() error {
@@ -105,33 +358,63 @@ func (i *Inference) init() error {
 
 	if len(i.models) == 0 {
 		// 아무런 추론 모델이 없는 경우 기본 모델을 생성
-		result, err := i.CreateModel(
+		result, err := i.createModel(
+			context.Background(),
 			constants.DefaultModelName,
 			"",
 			"Default Model",
 			constants.TrainEpochs,
 			false)
 		if err != nil {
-			return err
+			// learner에 연결할 수 없는 경우에도 추론할 수 있도록 fallback 모델을 기본 모델로 사용
+			if fbErr := i.loadFallbackModel(); fbErr != nil {
+				log.Printf("Fail to load fallback model: %s", fbErr)
+				return err
+			}
+			log.Printf("Fail to create default model, serve fallback model(%s): %s", i.fallbackModelPath, err)
+			return nil
 		}
-		log.Printf("Create default model: %v", result)
+		log.Printf("Create default model: %s (%s)", result.Model, result.Type)
 	}
 
 	return nil
 }
 
+func (i *Inference) loadFallbackModel() error {
+	if i.fallbackModelPath == "" {
+		return errors.New("Empty fallback model path")
+	}
+
+	m := getNewModel("", i.fallbackModelPath)
+	if err := i.load(m); err != nil {
+		return err
+	}
+
+	// fallback 모델은 공용이므로 모델 삭제시 파일을 지우지 않음
+	m.name = constants.DefaultModelName
+	m.readOnly = true
+
+	i.rwMutex.Lock()
+	defer i.rwMutex.Unlock()
+
+	return i.addModel(m)
+}
+
 func (i *Inference) addModel(newM *iModel) error {
 	var err error
 
 	if newM.name == "" {
-		return errors.New("Empty model name")
+		return fmt.Errorf("%w: empty model name", ErrInvalidName)
+	}
+	if _, ok := i.aliases[newM.name]; ok {
+		return fmt.Errorf("%w: alias %s", ErrDuplicateModel, newM.name)
 	}
 
 	for model, m := range i.models {
 		if model == newM.name || m.name == newM.name {
-			err = errors.New("Duplicated model")
+			err = fmt.Errorf("%w: %s", ErrDuplicateModel, newM.name)
 		} else if m.modelPath == newM.modelPath {
-			err = errors.New("Duplicated model path")
+			err = fmt.Errorf("%w path: %s", ErrDuplicateModel, newM.modelPath)
 		}
 
 		if atomic.LoadInt32(&m.status) != modelStatusRun {
@@ -151,35 +434,104 @@ func (i *Inference) addModel(newM *iModel) error {
 	return nil
 }
 
+// delModel 모델 삭제 (`<model>/<version>`이면 사용 중이 아닌 버전만 삭제)
+//
+// 별칭이 가리키는 모델 또는 버전은 삭제하지 않음
 func (i *Inference) delModel(model string) error {
-	m, ok := i.models[model]
-	if !ok {
-		return fmt.Errorf("No such model: %s", model)
+	m, err := i.deletableModel(model)
+	if err != nil {
+		return err
 	}
 
 	if m.refCount > 0 {
-		return fmt.Errorf("Currently in use: %s (%d)", m.name, m.refCount)
+		return fmt.Errorf("%w: %s (%d)", ErrModelInUse, m.name, m.refCount)
 	}
 
-	if err := os.RemoveAll(m.modelPath); err != nil {
-		return err
+	if i.models[m.name] != m {
+		if !m.readOnly {
+			if err := os.RemoveAll(m.versionPath); err != nil {
+				return err
+			}
+		}
+		delete(i.versions[m.name], m.version)
+		return nil
+	}
+
+	for _, v := range i.modelVersions(m.name) {
+		if v.refCount > 0 {
+			return fmt.Errorf("%w: %s/%d (%d)", ErrModelInUse, v.name, v.version, v.refCount)
+		}
+	}
+
+	if !m.readOnly {
+		if err := os.RemoveAll(m.modelPath); err != nil {
+			return err
+		}
 	}
 
 	delete(i.models, m.name)
+	i.delVersions(m.name)
+	i.dropDisabled(m.name)
 
 	return nil
 }
 
+// deletableModel 사용 여부를 제외하고 삭제 할 수 있는 model 반환
+//
+// 별칭, 별칭이 가리키는 모델 또는 버전과 버전을 지정한 사용 중인 버전이면 에러 반환. i.rwMutex를 잡은 상태에서 호출
+func (i *Inference) deletableModel(model string) (*iModel, error) {
+	if _, ok := i.aliases[model]; ok {
+		return nil, fmt.Errorf("%w: %s is an alias", ErrInvalidName, model)
+	}
+
+	m := i.lookupModel(model)
+	if m == nil {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, model)
+	}
+
+	version := 0
+	if i.models[m.name] != m {
+		version = m.version
+	}
+	if aliases := i.modelAliases(m.name, version); len(aliases) > 0 {
+		return nil, fmt.Errorf("%w: %s is used by alias %s", ErrModelInUse, model, strings.Join(aliases, ", "))
+	}
+
+	if version == 0 {
+		if _, version, _ := splitModelVersion(model); version > 0 {
+			return nil, fmt.Errorf("%w: %s is the current version", ErrModelInUse, model)
+		}
+	}
+
+	return m, nil
+}
+
 func (i *Inference) delModelUncond(delM *iModel) {
-	if err := os.RemoveAll(delM.modelPath); err != nil {
-		log.Print(err)
+	// 생성 중이거나 사용 중이 아닌 버전은 버전 디렉토리만 삭제
+	if i.versions[delM.name][delM.version] == delM {
+		if !delM.readOnly {
+			if err := os.RemoveAll(delM.versionPath); err != nil {
+				log.Print(err)
+			}
+		}
+		delete(i.versions[delM.name], delM.version)
+		return
 	}
 
+	if !delM.readOnly {
+		if err := os.RemoveAll(delM.modelPath); err != nil {
+			log.Print(err)
+		}
+	}
+
+	if i.models[delM.name] == delM {
+		i.delVersions(delM.name)
+	}
 	delete(i.models, delM.name)
 }
 
 func (i *Inference) getModel(model string) *iModel {
-	if m, ok := i.models[model]; ok {
+	if m := i.lookupModel(model); m != nil && atomic.LoadInt32(&m.draining) == 0 {
 		atomic.AddInt32(&m.refCount, 1)
 		return m
 	}
@@ -212,12 +564,70 @@ type CreateResponse struct {
 }
 
 // CreateModel 추론모델 생성
-func (i *Inference) CreateModel(newModel, subject, desc string, epochs int, trial bool) (map[string]interface{}, error) {
-	modelDir := fmt.Sprintf("%s-%s", newModel, uuid.New().String()[:8])
-	modelPath := path.Join(i.modelsPath, modelDir)
+//
+// newModel이 `<model>@<tenant>`이면 사용자의 저장 공간에 생성
+func (i *Inference) CreateModel(ctx context.Context, newModel, subject, desc string, epochs int, trial bool) (*CreateResult, error) {
+	if err := i.authorize(ctx, ActionCreateModel, newModel); err != nil {
+		return nil, err
+	}
+
+	return i.createModel(ctx, newModel, subject, desc, epochs, trial)
+}
+
+// createModel 권한 확인 없이 추론모델 생성
+func (i *Inference) createModel(ctx context.Context, newModel, subject, desc string, epochs int, trial bool) (*CreateResult, error) {
+	if subject != "" {
+		if err := ValidateName(subject); err != nil {
+			return nil, err
+		}
+	}
+
+	m, err := i.reserveModel(newModel)
+	if err != nil {
+		return nil, err
+	}
+	defer i.putModel(m)
+
+	return i.train(ctx, m, subject, desc, epochs, trial)
+}
+
+// reserveModel 새로운 모델의 슬롯을 선점하고 모델 디렉토리와 manifest를 생성
+//
+// 반환 된 모델은 사용 중으로 표시되므로 사용이 끝나면 putModel을 호출
+func (i *Inference) reserveModel(newModel string) (*iModel, error) {
+	model, tenantName := splitModelName(newModel)
+	if err := ValidateName(model); err != nil {
+		return nil, err
+	}
+	if strings.Contains(newModel, tenantSeparator) {
+		if err := ValidateName(tenantName); err != nil {
+			return nil, err
+		}
+	}
 
-	m := getNewModel(newModel, modelPath)
 	i.rwMutex.Lock()
+	modelsPath := i.modelsPath
+	if tenantName != "" {
+		t, err := i.getTenant(tenantName)
+		if err == nil {
+			err = t.checkQuota()
+		}
+		if err != nil {
+			i.rwMutex.Unlock()
+			return nil, err
+		}
+		modelsPath = t.path
+	}
+	modelPath, err := i.newModelPath(modelsPath, model)
+	if err != nil {
+		i.rwMutex.Unlock()
+		return nil, err
+	}
+
+	m := getNewModel(newModel, modelPath)
+	m.tenant = tenantName
+	m.version = 1
+	m.versionPath = versionPath(modelPath, m.version)
 	// 새로운 모델 생성 및 로드 전 슬롯 선점
 	if err := i.addModel(m); err != nil {
 		i.rwMutex.Unlock()
@@ -225,18 +635,67 @@ func (i *Inference) CreateModel(newModel, subject, desc string, epochs int, tria
 	}
 	i.getModel(newModel)
 	i.rwMutex.Unlock()
-	defer i.putModel(m)
 
-	configFile := path.Join(modelPath, "config.yaml")
+	// 모델은 버전 디렉토리에 저장
+	err = os.MkdirAll(modelPath, os.ModePerm)
+	if err == nil {
+		err = writeManifest(modelPath, modelManifest{
+			Layout:  layoutVersion,
+			Name:    newModel,
+			Current: m.version,
+		})
+	}
+	if err != nil {
+		i.putModel(m)
+		i.rwMutex.Lock()
+		i.delModelUncond(m)
+		i.rwMutex.Unlock()
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// replaceReserved reserveModel로 선점한 슬롯 m을 로드 된 모델 newM으로 교체
+//
+// 그 사이에 m이 삭제 되었으면 newM을 해제하고 ErrModelNotFound 반환
+func (i *Inference) replaceReserved(m, newM *iModel) error {
+	i.rwMutex.Lock()
+	defer i.rwMutex.Unlock()
+
+	if i.models[m.name] != m {
+		newM.destroy()
+		return fmt.Errorf("%w: %s", ErrModelNotFound, m.name)
+	}
+	i.models[m.name] = newM
+
+	return nil
+}
+
+// train learner에 m의 버전 디렉토리에 모델 학습을 요청
+//
+// 요청이 실패하면 m을 삭제
+func (i *Inference) train(ctx context.Context, m *iModel, subject, desc string, epochs int, trial bool) (*CreateResult, error) {
 	imagePath := ""
 	if subject != "" {
-		imagePath = path.Join(constants.ImagesPath, subject)
+		imagePath = filepath.Join(constants.ImagesPath, subject)
+	}
+
+	m.metadata = &modelMetadata{
+		Name:        m.name,
+		Version:     m.version,
+		Source:      sourceLearner,
+		CreatedAt:   time.Now(),
+		Subject:     subject,
+		Description: desc,
+		Epochs:      epochs,
+		Trial:       trial,
 	}
 
 	req := CreateRequest{
 		ImagePath:   imagePath,
-		ModelPath:   modelPath,
-		ConfigFile:  configFile,
+		ModelPath:   m.versionPath,
+		ConfigFile:  filepath.Join(m.versionPath, configFile),
 		Description: desc,
 		Epochs:      epochs,
 		Trial:       trial,
@@ -245,8 +704,17 @@ func (i *Inference) CreateModel(newModel, subject, desc string, epochs int, tria
 	j, _ := json.Marshal(req)
 	data := bytes.NewBuffer(j)
 
-	url := fmt.Sprintf("http://%s/models/%s", i.lHost, newModel)
-	res, err := http.Post(url, "application/json", data)
+	url := fmt.Sprintf("http://%s/models/%s", i.lHost, m.name)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, data)
+	if err != nil {
+		i.rwMutex.Lock()
+		i.delModelUncond(m)
+		i.rwMutex.Unlock()
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	res, err := http.DefaultClient.Do(httpReq)
 	if err != nil {
 		i.rwMutex.Lock()
 		i.delModelUncond(m)
@@ -255,95 +723,264 @@ func (i *Inference) CreateModel(newModel, subject, desc string, epochs int, tria
 	}
 	defer res.Body.Close()
 
-	var response map[string]interface{}
-	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+	var response struct {
+		CreateResult
+		// learner가 요청을 받지 않은 경우의 에러
+		Error string `json:"error"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&response)
+	if err == nil && res.StatusCode/100 != 2 {
+		err = fmt.Errorf("Fail to create model: %s (%s)", response.Error, res.Status)
+	}
+	if err != nil {
 		i.rwMutex.Lock()
 		i.delModelUncond(m)
 		i.rwMutex.Unlock()
 		return nil, err
 	}
 
-	atomic.StoreInt32(&m.status, modelStatusBuild)
-	m.statusUpdateTime = time.Now()
+	// 학습이 먼저 끝나 이미 로드 된 경우 상태를 되돌리지 않음
+	if atomic.CompareAndSwapInt32(&m.status, modelStatusReady, modelStatusBuild) {
+		m.statusUpdateTime = time.Now()
+	}
 
-	return response, nil
+	response.CreateResult.Version = m.version
+	return &response.CreateResult, nil
 }
 
 // OperateModel 생성 된 추론모델 로드
-func (i *Inference) OperateModel(model, modelPath string) error {
+func (i *Inference) OperateModel(ctx context.Context, model, modelPath string) error {
 	i.rwMutex.RLock()
 	m := i.getModel(model)
+	if m != nil {
+		// 사용 중인 버전으로 추론하면서 학습한 새 버전
+		if v := i.buildingVersion(m.name, modelPath); v != nil {
+			i.putModel(m)
+			m = v
+			atomic.AddInt32(&m.refCount, 1)
+		}
+	}
 	i.rwMutex.RUnlock()
 
 	if m == nil {
-		if err := os.RemoveAll(modelPath); err != nil {
-			log.Print(err)
+		// 요청으로 전달 된 경로이므로 모델 저장 경로 하위이고,
+		// 등록 된 모델이 사용하지 않는 모델 디렉토리인 경우에만 삭제
+		i.rwMutex.RLock()
+		root, ok := i.modelRoot(modelPath)
+		removable := ok && !i.ownedPath(root)
+		i.rwMutex.RUnlock()
+
+		if removable {
+			if err := os.RemoveAll(root); err != nil {
+				log.Print(err)
+			}
 		}
-		return fmt.Errorf("No such model for register: %s", model)
+		return fmt.Errorf("%w for register: %s", ErrModelNotFound, model)
 	}
 	defer i.putModel(m)
 
-	if m.modelPath != modelPath {
-		i.rwMutex.Lock()
-		i.delModelUncond(m)
-		i.rwMutex.Unlock()
-		return fmt.Errorf("Invalid model path: %s", model)
+	if filepath.Clean(m.versionPath) != filepath.Clean(modelPath) {
+		// 사용 중인 모델은 잘못된 요청으로 삭제하지 않음
+		if atomic.LoadInt32(&m.status) != modelStatusRun {
+			i.rwMutex.Lock()
+			i.delModelUncond(m)
+			i.rwMutex.Unlock()
+		}
+		return fmt.Errorf("%w: %s", ErrInvalidModelPath, model)
+	}
+
+	// 요청이 취소된 경우 학습 된 모델 파일은 유지하고 로드만 하지 않음
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if m.tenant != "" {
+		i.rwMutex.RLock()
+		t, err := i.getTenant(m.tenant)
+		i.rwMutex.RUnlock()
+		if err == nil {
+			err = t.checkQuota()
+		}
+		if err != nil {
+			i.rwMutex.Lock()
+			i.delModelUncond(m)
+			i.rwMutex.Unlock()
+			return err
+		}
 	}
 
-	if err := loadModel(m); err != nil {
+	if err := i.load(m); err != nil {
 		i.rwMutex.Lock()
 		i.delModelUncond(m)
 		i.rwMutex.Unlock()
 		return err
 	}
 
+	if m.metadata != nil {
+		if err := writeMetadata(m.versionPath, *m.metadata); err != nil {
+			log.Printf("Fail to write metadata(%s): %s", m.versionPath, err)
+		}
+	}
+	if err := writeChecksums(m.versionPath, m.cfg); err != nil {
+		log.Printf("Fail to write checksums(%s): %s", m.versionPath, err)
+	}
+
+	i.rwMutex.RLock()
+	current := i.models[m.name] == m
+	i.rwMutex.RUnlock()
+	if !current {
+		if err := i.promoteVersion(m); err != nil {
+			return err
+		}
+		i.pruneVersions(m.name)
+	}
+
 	return nil
 }
 
 // DeleteModel 모델 삭제
-func (i *Inference) DeleteModel(model string) error {
+func (i *Inference) DeleteModel(ctx context.Context, model string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := i.authorize(ctx, ActionDeleteModel, model); err != nil {
+		return err
+	}
+
 	i.rwMutex.Lock()
 	defer i.rwMutex.Unlock()
 
 	return i.delModel(model)
 }
 
-// GetModels 이미지 추론 모델 목록 반환
-func (i *Inference) GetModels() []string {
+// ReloadModel 모델의 config, labels와 SavedModel을 다시 로드
+//
+// model이 `<model>/<version>`이면 해당 버전을 다시 로드하며, 새 모델을 로드한 후 교체하므로 로드에 실패하면 기존 모델을 계속 사용하며,
+// 기존 모델은 사용이 끝난 후 해제 (embedding 색인은 다시 만들어야 함)
+func (i *Inference) ReloadModel(ctx context.Context, model string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := i.authorize(ctx, ActionReloadModel, model); err != nil {
+		return err
+	}
+
+	i.rwMutex.RLock()
+	m := i.getModel(model)
+	i.rwMutex.RUnlock()
+
+	if m == nil {
+		return fmt.Errorf("%w: %s", ErrModelNotFound, model)
+	}
+	defer i.putModel(m)
+
+	if atomic.LoadInt32(&m.status) != modelStatusRun {
+		return fmt.Errorf("%w: %s", ErrModelNotReady, model)
+	}
+
+	newM := getNewModel("", m.modelPath)
+	newM.version = m.version
+	newM.versionPath = m.versionPath
+	if err := i.load(newM); err != nil {
+		return err
+	}
+
+	// fallback 모델과 사용자 모델은 config의 이름과 모델 이름이 다름
+	if name, _ := splitModelName(m.name); !m.readOnly && newM.name != m.name && newM.name != name {
+		newM.destroy()
+		return fmt.Errorf("Not matched model name[%s] in configuration[%s]", m.name, newM.name)
+	}
+	newM.name = m.name
+	newM.readOnly = m.readOnly
+	newM.tenant = m.tenant
+
+	i.rwMutex.Lock()
+	switch {
+	case i.models[m.name] == m:
+		i.models[m.name] = newM
+	case i.versions[m.name][m.version] == m:
+		i.versions[m.name][m.version] = newM
+	default:
+		// 다시 로드하는 중에 삭제 되었거나 다른 요청으로 교체 된 경우
+		i.rwMutex.Unlock()
+		newM.destroy()
+		return fmt.Errorf("%w: %s", ErrModelNotFound, model)
+	}
+	i.rwMutex.Unlock()
+
+	log.Printf("Reload model(%s): version %d", m.name, newM.version)
+	go m.release()
+
+	return nil
+}
+
+// GetModels filter의 조건을 만족하는 이미지 추론 모델 목록을 filter의 정렬 기준으로 반환
+//
+// 사용 중인 버전 외에 로드 된 버전은 `<model>/<version>`으로 포함
+func (i *Inference) GetModels(ctx context.Context, filter ModelFilter) []string {
 	i.rwMutex.RLock()
-	defer i.rwMutex.RUnlock()
+	var listed []listedModel
+	add := func(name string, m *iModel) {
+		if filter.match(m) {
+			listed = append(listed, listedModel{
+				name:    name,
+				status:  atomic.LoadInt32(&m.status),
+				updated: m.statusUpdateTime,
+			})
+		}
+	}
+	for model, m := range i.models {
+		add(model, m)
+		for _, v := range i.modelVersions(model) {
+			add(fmt.Sprintf("%s%s%d", model, VersionSeparator, v.version), v)
+		}
+	}
+	i.rwMutex.RUnlock()
+
+	filter.sortModels(listed)
 
+	// 조회 권한이 없는 모델은 목록에서 제외
 	var models []string
-	for model := range i.models {
-		models = append(models, model)
+	for _, l := range listed {
+		if i.authorize(ctx, ActionReadModel, l.name) == nil {
+			models = append(models, l.name)
+		}
 	}
 
 	return models
 }
 
 // GetModel 이미지 추론 모델 정보 반환
-func (i *Inference) GetModel(model string, verbose bool) map[string]interface{} {
+func (i *Inference) GetModel(ctx context.Context, model string, verbose bool) (*ModelInfo, error) {
+	if err := i.authorize(ctx, ActionReadModel, model); err != nil {
+		return nil, err
+	}
+
 	i.rwMutex.RLock()
 	m := i.getModel(model)
+	var (
+		versions []int
+		disabled bool
+	)
+	if m != nil {
+		disabled = i.disabled[m.name]
+	}
+	if m != nil && m.version > 0 {
+		if current, ok := i.models[m.name]; ok {
+			versions = append(versions, current.version)
+		}
+		for _, v := range i.modelVersions(m.name) {
+			versions = append(versions, v.version)
+		}
+		sort.Ints(versions)
+	}
 	i.rwMutex.RUnlock()
 
 	if m == nil {
-		return nil
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, model)
 	}
 	defer i.putModel(m)
 
-	var status string
-	switch atomic.LoadInt32(&m.status) {
-	case modelStatusReady:
-		status = "ready"
-	case modelStatusBuild:
-		status = "build"
-	case modelStatusRun:
-		status = "run"
-	default:
-		status = "unknown"
-	}
-
 	var labels []string
 	if verbose {
 		labels = make([]string, len(m.labels))
@@ -360,62 +997,280 @@ func (i *Inference) GetModel(model string, verbose bool) map[string]interface{}
 		}
 	}
 
-	info := map[string]interface{}{
-		"model":          m.name,
-		"refCount":       m.refCount,
-		"inputShape":     m.inputShape,
-		"numberOfLables": m.nrLables,
-		"type":           m.cfg.Type,
-		"classification": m.cfg.Classification,
-		"inputOperator":  m.cfg.InputOperationName,
-		"outputOperator": m.cfg.OutputOperationName,
-		"description":    m.cfg.Description,
-		"status":         status,
-		"lables":         labels,
+	info := &ModelInfo{
+		Model:            m.name,
+		Version:          m.version,
+		Versions:         versions,
+		RefCount:         m.refCount,
+		Status:           statusName(atomic.LoadInt32(&m.status)),
+		Disabled:         disabled,
+		Tenant:           m.tenant,
+		InputShape:       m.inputShape,
+		Channels:         m.cfg.channels(),
+		InputDType:       m.cfg.inputDType(),
+		InputLayout:      m.cfg.inputLayout(),
+		Format:           m.cfg.modelFormat(),
+		Mean:             m.cfg.normalization().mean,
+		Std:              m.cfg.normalization().std,
+		ResizeMode:       m.cfg.resizeMode(),
+		ResizeMethod:     m.cfg.resizeMethod(),
+		Alpha:            m.cfg.alpha(),
+		AlphaBackground:  m.cfg.alphaBackground(),
+		TTA:              m.cfg.TTA,
+		MultiCrop:        m.cfg.MultiCrop,
+		Preprocessor:     m.cfg.Preprocessor,
+		ModelTags:        m.cfg.ModelTags,
+		Type:             m.cfg.Type,
+		Classification:   m.cfg.Classification,
+		InputOperator:    m.cfg.InputOperationName,
+		OutputOperator:   m.cfg.OutputOperationName,
+		Outputs:          m.cfg.Outputs,
+		Description:      m.cfg.Description,
+		Threshold:        m.cfg.threshold(0),
+		DefaultTopK:      m.cfg.topK(0),
+		MinProbability:   m.cfg.MinProbability,
+		UnknownThreshold: m.cfg.UnknownThreshold,
+		UnknownLabel:     m.cfg.unknownLabel(),
+		LabelHierarchy:   m.cfg.LabelHierarchy,
+		LabelMap:         m.cfg.LabelMap,
+		ExcludeLabels:    m.cfg.ExcludeLabels,
+		OutputActivation: m.cfg.OutputActivation,
+		Temperature:      m.cfg.Temperature,
+		NumberOfLabels:   m.nrLables,
+		Labels:           labels,
+		LabelsSource:     m.labelsSource,
+		Memory:           m.memory,
 	}
 
-	if verbose {
-		trainingInfo := map[string]interface{}{
-			"epochs":             m.cfg.TrainingResult.Epochs,
-			"initLoss":           m.cfg.TrainingResult.InitLoss,
-			"initAccuracy":       m.cfg.TrainingResult.InitAccuracy,
-			"trainLoss":          m.cfg.TrainingResult.TrainLoss,
-			"trainAccuracy":      m.cfg.TrainingResult.TrainAccuracy,
-			"validationLoss":     m.cfg.TrainingResult.ValidationLoss,
-			"validationAccuracy": m.cfg.TrainingResult.ValidationAccuracy,
+	if m.cfg.Classification == detectionClass {
+		classOffset := m.cfg.ClassOffset
+		info.DetectionOperators = m.cfg.outputOperations()
+		info.ClassOffset = &classOffset
+	}
+
+	if m.index != nil {
+		indexSize := m.index.len()
+		info.EmbeddingOperator = m.cfg.EmbeddingOperationName
+		info.IndexSize = &indexSize
+	}
+
+	if m.cfg.CAMOperationName != "" {
+		info.CAMOperators = m.cfg.camOperations()
+	}
+
+	if len(m.localizedLabels) > 0 {
+		languages := make([]string, 0, len(m.localizedLabels))
+		for language := range m.localizedLabels {
+			languages = append(languages, language)
 		}
+		sort.Strings(languages)
+		info.LabelLanguages = languages
+	}
 
-		info["trainingResult"] = trainingInfo
+	// 모델 파일 크기 (byte)
+	if size, err := dirSize(m.modelPath); err == nil {
+		info.Storage = size
+	}
 
+	if verbose {
+		trainingResult := m.cfg.TrainingResult
+		info.TrainingResult = &trainingResult
 	}
 
-	return info
+	return info, nil
 }
 
 // Infer 추론
-func (i *Inference) Infer(model, image, format string, k int) ([]InferLabel, error) {
+//
+// threshold는 binary 모델의 판단 기준이며, 0 이하 또는 1 이상이면 모델 설정값을 사용.
+// format이 빈 값이면 이미지 내용으로 형식을 판단하며, 지정한 형식과 내용이 다르면 *FormatMismatchError 반환.
+// 이미지가 ImageLimits를 넘으면 *ImageTooLargeError 반환
+func (i *Inference) Infer(ctx context.Context, model string, image []byte, format string, k int, threshold float32) ([]InferLabel, error) {
+	result, err := i.InferRaw(ctx, model, image, format, k, threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Inference, nil
+}
+
+// InferRaw 추론 결과와 함께 활성화 함수를 적용하기 전의 모델 출력 반환
+func (i *Inference) InferRaw(ctx context.Context, model string, image []byte, format string, k int, threshold float32) (result *RawInference, err error) {
+	if err := i.authorize(ctx, ActionInfer, model); err != nil {
+		return nil, err
+	}
+
+	t0 := time.Now()
+	defer func() {
+		i.observeInference(model, 1, t0, err)
+	}()
+
+	if err := i.imageLimits.check(image); err != nil {
+		return nil, err
+	}
+
 	i.rwMutex.RLock()
 	m := i.getModel(model)
 	i.rwMutex.RUnlock()
 
 	if m == nil {
-		return nil, fmt.Errorf("No such model: %s", model)
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, model)
 	}
 	defer i.putModel(m)
 
 	if atomic.LoadInt32(&m.status) != modelStatusRun {
-		return nil, fmt.Errorf("Not ready yet")
+		return nil, fmt.Errorf("%w: %s", ErrModelNotReady, model)
+	}
+	if err := i.checkEnabled(m); err != nil {
+		return nil, err
+	}
+	recordServed(ctx, m)
+
+	key := m.resultKey(ctx, image, format, k, threshold)
+	if cached, ok := i.resultCache.get(key); ok {
+		return cached, nil
+	}
+
+	if result, err = m.inferRaw(ctx, image, format, k, threshold); err != nil {
+		return nil, err
 	}
+	i.resultCache.put(key, result)
+
+	return result, nil
+}
 
-	return m.infer(image, format, k)
+// InferRequest 이미지 추론 요청
+type InferRequest struct {
+	Model string
+	// 0 이하이면 사용 중인 버전
+	Version int
+	Image   []byte
+	// 빈 값이면 이미지 내용으로 형식을 판단
+	Format string
+	// 0 이하이면 모델 설정값(defaultTopK)을 사용
+	K int
+	// binary 모델의 판단 기준 (0 이하 또는 1 이상이면 모델 설정값)
+	Threshold float32
+	// 결과의 label을 바꿀 언어 (선호 순서)
+	Languages []string
+	// 결과에서 제외할 클래스 이름
+	Exclude []string
+	// 지정하면 추론 단계별 소요 시간을 기록
+	Timings *Timings
+}
+
+// Run 요청의 언어, 제외할 클래스와 Timings를 ctx에 지정하여 InferRaw로 추론 (Version을 지정하면 해당 버전으로 추론)
+func (i *Inference) Run(ctx context.Context, req InferRequest) (*RawInference, error) {
+	if len(req.Languages) > 0 {
+		ctx = ContextWithLanguages(ctx, req.Languages...)
+	}
+	if len(req.Exclude) > 0 {
+		ctx = ContextWithExcludedLabels(ctx, req.Exclude...)
+	}
+	if req.Timings != nil {
+		ctx = ContextWithTimings(ctx, req.Timings)
+	}
+
+	model := req.Model
+	if req.Version > 0 {
+		model = fmt.Sprintf("%s%s%d", model, VersionSeparator, req.Version)
+	}
+
+	return i.InferRaw(ctx, model, req.Image, req.Format, req.K, req.Threshold)
+}
+
+// InferBatch 여러 이미지를 하나의 모델로 추론
+//
+// 결과는 images와 같은 순서이며, 하나라도 실패하면 에러를 반환
+func (i *Inference) InferBatch(ctx context.Context, model string, images [][]byte, format string, k int, threshold float32) (results [][]InferLabel, err error) {
+	if err := i.authorize(ctx, ActionInfer, model); err != nil {
+		return nil, err
+	}
+
+	t0 := time.Now()
+	defer func() {
+		i.observeInference(model, len(images), t0, err)
+	}()
+
+	for idx, image := range images {
+		if err := i.imageLimits.check(image); err != nil {
+			return nil, fmt.Errorf("Image %d: %w", idx, err)
+		}
+	}
+
+	i.rwMutex.RLock()
+	m := i.getModel(model)
+	i.rwMutex.RUnlock()
+
+	if m == nil {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, model)
+	}
+	defer i.putModel(m)
+
+	if atomic.LoadInt32(&m.status) != modelStatusRun {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotReady, model)
+	}
+	if err := i.checkEnabled(m); err != nil {
+		return nil, err
+	}
+	recordServed(ctx, m)
+
+	return m.inferBatch(ctx, images, format, k, threshold)
+}
+
+// InferTensor 이미지 디코딩과 전처리 없이, 전처리 된 입력으로 추론
+//
+// data는 batch를 제외한 모델 입력 tensor(inputLayout 순서, 예: NHWC는 [height, width, channels])를
+// 펼친 값이며, shape이나 값이 모델 입력과 맞지 않으면 ErrInvalidTensor 반환.
+// 이미지를 받는 Hook의 PreProcess와 TTA는 적용하지 않음
+func (i *Inference) InferTensor(ctx context.Context, model string, data []float32, shape []int, k int, threshold float32) (infers []InferLabel, err error) {
+	if err := i.authorize(ctx, ActionInfer, model); err != nil {
+		return nil, err
+	}
+
+	t0 := time.Now()
+	defer func() {
+		i.observeInference(model, 1, t0, err)
+	}()
+
+	i.rwMutex.RLock()
+	m := i.getModel(model)
+	i.rwMutex.RUnlock()
+
+	if m == nil {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, model)
+	}
+	defer i.putModel(m)
+
+	if atomic.LoadInt32(&m.status) != modelStatusRun {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotReady, model)
+	}
+	if err := i.checkEnabled(m); err != nil {
+		return nil, err
+	}
+	recordServed(ctx, m)
+
+	return m.inferTensor(ctx, data, shape, k, threshold)
 }
 
 // Destroy 추론 모델 해제
-func (i *Inference) Destroy() {
+//
+// 사용 중인 모델은 사용이 끝나거나 ctx가 종료될 때까지 기다린 후 해제
+func (i *Inference) Destroy(ctx context.Context) {
+	// 해제 중에 추가 된 모델을 로드하지 않도록 감시를 먼저 종료
+	if i.watcher != nil {
+		i.watcher.stop()
+	}
+
 	i.rwMutex.Lock()
 	defer i.rwMutex.Unlock()
 
 	for model, m := range i.models {
+		for _, v := range i.delVersions(model) {
+			v.wait(ctx)
+			v.destroy()
+		}
+		m.wait(ctx)
 		m.destroy()
 		delete(i.models, model)
 		log.Printf("%s model closed", model)
@@ -436,223 +1291,284 @@ type iModel struct {
 	status           int32
 	statusUpdateTime time.Time
 	refCount         int32
-
-	tfModel    *tf.SavedModel
+	// 0이 아니면 강제 삭제를 기다리는 중이므로 새로 사용하지 않음
+	draining int32
+	// 삭제시 모델 파일을 지우지 않음
+	readOnly bool
+	// 사용자 모델의 사용자 (공용 모델은 빈 값)
+	tenant string
+
+	// 사용 중인 버전과 경로 (v1 구조는 버전 0과 모델 경로)
+	version     int
+	versionPath string
+	// 로드마다 다른 값 (추론 결과 캐시에서 다시 로드 된 모델을 구분)
+	loadID uint64
+	// 생성 중인 모델의 metadata
+	metadata *modelMetadata
+
+	backend    modelBackend
 	inputShape []int32
 
-	imageDecoder map[string]imageDecode
-	mutex        sync.Mutex
-
-	nrLables int
-	labels   []string
+	nrLables   int
+	labels     []string
+	labelInfos []labelInfo
+	// 언어별 labels (labels와 같은 순서)
+	localizedLabels map[string][]string
+	// labels를 자동 생성한 경우의 출처
+	labelsSource *LabelsSource
+
+	hooks []Hook
+	// 지정하면 내장 전처리 graph 대신 사용
+	preprocessor Preprocessor
+	// 추론 전에 Go 디코더로 이미지 전체를 디코딩하여 손상 여부 확인
+	probeDecode bool
+	// HEIC/HEIF 이미지를 내장 디코더가 지원하는 JPEG로 변환
+	heicConverter HEICConverter
+	// `format: tflite` 모델을 로드하는 실행 엔진
+	tfliteRuntime TFLiteRuntime
+	// 전처리 graph 저장 경로 (빈 값이면 저장하지 않음)
+	graphCachePath string
+	// 모델 입력 크기의 배수로, 이보다 큰 이미지는 Go에서 줄여서 실행 (0 이하면 사용 안함)
+	preDownscale float64
+	// 로드 후 상태를 modelStatusRun으로 바꾸기 전에 합성 이미지로 실행하는 횟수
+	warmupRuns int
+	// embeddingOperationName이 있는 모델의 유사 이미지 검색 색인
+	index *embeddingIndex
+	// 로드시 예약한 예상 메모리와 예약한 memoryBudget (로드 되지 않았으면 nil)
+	memory int64
+	budget *memoryBudget
 }
 
-// 이미지 타입의 디코더
-type imageDecode struct {
-	graph   *tf.Graph
-	session *tf.Session
-	input   tf.Output
-	output  tf.Output
-}
+func (m *iModel) infer(ctx context.Context, image []byte, format string, k int, threshold float32) ([]InferLabel, error) {
+	outputs, err := m.runImage(ctx, image, format)
+	if err != nil {
+		return nil, err
+	}
 
-func (m *iModel) infer(image, format string, k int) ([]InferLabel, error) {
-	var (
-		inputImage *tf.Tensor
-		results    []*tf.Tensor
-		err        error
-	)
+	return m.classify(ctx, outputs, k, threshold)
+}
 
-	if inputImage, err = m.normInputImage(image, format); err != nil {
+// runImage 이미지(TTA를 사용하면 원본과 변형 이미지)별 모델 출력 반환
+func (m *iModel) runImage(ctx context.Context, image []byte, format string) ([][]float32, error) {
+	if err := m.checkClassifier(); err != nil {
 		return nil, err
 	}
 
-	if results, err = m.tfModel.Session.Run(
-		map[tf.Output]*tf.Tensor{
-			m.tfModel.Graph.Operation(m.cfg.InputOperationName).Output(0): inputImage,
-		},
-		[]tf.Output{
-			m.tfModel.Graph.Operation(m.cfg.OutputOperationName).Output(0),
-		},
-		nil,
-	); err != nil {
+	image, format, err := m.preProcess(ctx, image, format)
+	if err != nil {
 		return nil, err
 	}
 
-	probabilities := results[0].Value().([][]float32)[0]
-
-	if m.cfg.Classification == binaryClass {
-		return m.classifyBinary(probabilities[0])
-	} else if m.cfg.Classification == multiClass {
-		return m.classifyMulti(probabilities, k)
+	var outputs [][]float32
+	if m.preprocessor != nil {
+		// 내장 디코더가 지원하지 않는 형식도 Preprocessor가 처리 할 수 있도록 형식을 확인하지 않음
+		var output []float32
+		output, err = m.runPreprocessor(ctx, image, format)
+		outputs = [][]float32{output}
+	} else {
+		var (
+			input   imageInput
+			results [][][]float32
+		)
+		if input, err = m.prepareImage(ctx, image, format); err != nil {
+			return nil, err
+		}
+		if results, err = m.backend.runImages(ctx, []imageInput{input}); err == nil {
+			outputs = results[0]
+		}
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, fmt.Errorf("Unknown classification: %s", m.cfg.Classification)
+	return outputs, nil
 }
 
-func (m *iModel) normInputImage(image, format string) (*tf.Tensor, error) {
-	var (
-		decoder     imageDecode
-		imageTensor *tf.Tensor
-		norms       []*tf.Tensor
-		err         error
-	)
-
-	if decoder, err = m.getImageDecoder(format); err != nil {
+func (m *iModel) inferTensor(ctx context.Context, data []float32, shape []int, k int, threshold float32) ([]InferLabel, error) {
+	if err := m.checkClassifier(); err != nil {
 		return nil, err
 	}
-
-	if imageTensor, err = tf.NewTensor(image); err != nil {
+	if err := m.cfg.checkTensor(data, shape); err != nil {
 		return nil, err
 	}
 
-	if norms, err = decoder.session.Run(
-		map[tf.Output]*tf.Tensor{
-			decoder.input: imageTensor,
-		},
-		[]tf.Output{
-			decoder.output,
-		},
-		nil,
-	); err != nil {
+	output, err := m.backend.runTensor(ctx, data)
+	if err != nil {
 		return nil, err
 	}
 
-	return norms[0], nil
+	return m.classify(ctx, [][]float32{output}, k, threshold)
 }
 
-func (m *iModel) getImageDecoder(format string) (imageDecode, error) {
-	var (
-		decoder imageDecode
-		decode  tf.Output
-		session *tf.Session
-		graph   *tf.Graph
-		ok      bool
-		err     error
-	)
+// classify 이미지별 모델 출력을 확률로 변환하고 평균하여 라벨 결정
+func (m *iModel) classify(ctx context.Context, outputs [][]float32, k int, threshold float32) ([]InferLabel, error) {
+	return m.classifyFiltered(ctx, outputs, k, threshold, nil)
+}
 
-	// 생성 된 디코더는 공용으로 사용되기 때문에,
-	// 최초 생성시 lock을 잡도록 하고 이 후 사용할땐 lock 없이 접근
-	decoder, ok = m.imageDecoder[format]
-	if ok {
-		return decoder, nil
-	}
+// classifyFiltered classify와 같으며, stats가 nil이 아니면 결과에 포함되지 않은 클래스 수를 기록
+func (m *iModel) classifyFiltered(ctx context.Context, outputs [][]float32, k int, threshold float32, stats *FilterStats) ([]InferLabel, error) {
+	defer timeStage(ctx, stagePostprocess)()
 
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
+	probabilities := m.cfg.probabilities(outputs)
 
-	decoder, ok = m.imageDecoder[format]
-	if ok {
-		return decoder, nil
+	var (
+		infers []InferLabel
+		err    error
+	)
+	if m.cfg.Classification == binaryClass {
+		var prob float32
+		if prob, err = positiveProb(m.cfg.OutputActivation, probabilities); err == nil {
+			infers, err = m.classifyBinary(prob, m.cfg.threshold(threshold))
+		}
+	} else if m.cfg.Classification == multiClass {
+		infers, err = m.classifyMulti(probabilities, k, m.excludedLabels(ctx), stats)
+	} else if m.cfg.Classification == multiLabelClass {
+		infers, err = m.classifyMultiLabel(probabilities, k, m.cfg.threshold(threshold), m.excludedLabels(ctx), stats)
+	} else {
+		err = fmt.Errorf("%w: unknown classification %s", ErrInvalidConfig, m.cfg.Classification)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	scope := op.NewScope()
-	input := op.Placeholder(scope, tf.String)
-
-	if format == "jpg" || format == "jpeg" {
-		decode = op.DecodeJpeg(scope, input, op.DecodeJpegChannels(3))
-	} else if format == "png" {
-		decode = op.DecodePng(scope, input, op.DecodePngChannels(3))
-	} else {
-		return decoder, fmt.Errorf("Unsupported image format: %s", format)
+	if infers, err = m.postProcess(ctx, m.cfg.unknown(infers)); err != nil {
+		return nil, err
 	}
 
-	// TODO 모델에 따라 이미지값 범위 조정
-	// [0, 255]의 이미지값을 [-1, 1]로 조정: (image / 127.5) - 1
-	normalizer := op.Sub(scope,
-		op.Div(scope, op.Cast(scope, decode, tf.Float), op.Const(scope.SubScope("scale"), float32(127.5))),
-		op.Const(scope.SubScope("offset"), float32(1)))
+	return m.localize(ctx, infers), nil
+}
 
-	// 임의의 크기(height, width) 이미지를 입력 크기(inputShape,)로 조정
-	output := op.ResizeBilinear(scope,
-		op.ExpandDims(scope, normalizer, op.Const(scope.SubScope("batch"), int32(0))),
-		op.Const(scope.SubScope("resize"), m.inputShape))
+func (m *iModel) classifyBinary(prob, threshold float32) ([]InferLabel, error) {
+	if len(m.labels) != 2 {
+		return nil, fmt.Errorf("%w: the number of binary labels(%d) is not 2", ErrInvalidConfig, len(m.labels))
+	}
 
-	if graph, err = scope.Finalize(); err != nil {
-		return decoder, err
+	// prob는 positive 클래스(labels[1])의 확률이며, threshold 이상이면 positive로 판단
+	probs := []float32{1 - prob, prob}
+	order := []int{0, 1}
+	if prob >= threshold {
+		order = []int{1, 0}
 	}
 
-	if session, err = tf.NewSession(graph, nil); err != nil {
-		return decoder, err
+	// 판단 된 클래스가 숨김 또는 최소 확률 미만이면 다른 클래스로 바꾸지 않고 빈 결과를 반환
+	infers := []InferLabel{}
+	if !m.labelInfos[order[0]].visible(probs[order[0]]) {
+		return infers, nil
 	}
 
-	decoder = imageDecode{
-		graph:   graph,
-		input:   input,
-		output:  output,
-		session: session,
+	for _, idx := range order {
+		if m.labelInfos[idx].visible(probs[idx]) {
+			infers = append(infers, m.newInferLabel(idx, probs[idx]))
+		}
 	}
-	m.imageDecoder[format] = decoder
 
-	return decoder, nil
+	return m.remap(infers), nil
 }
 
-func (m *iModel) classifyBinary(prob float32) ([]InferLabel, error) {
-	var (
-		idx    int
-		infers []InferLabel
-	)
+// classifyMulti 확률 순서(확률이 같으면 클래스 이름 순서)로 상위 k개의 클래스 반환
+//
+// 숨김 클래스, 최소 확률 미만의 클래스와 excluded의 클래스는 제외
+func (m *iModel) classifyMulti(probs []float32, k int, excluded map[string]bool, stats *FilterStats) ([]InferLabel, error) {
+	if len(probs) != m.nrLables {
+		return nil, fmt.Errorf(
+			"%w: the number of correct(%d) and predicted(%d) labels does not match",
+			ErrInvalidConfig,
+			m.nrLables,
+			len(probs),
+		)
+	}
 
-	idx = 0
-	if prob >= 0.5 {
-		idx = 1
-	} else {
-		prob = 1 - prob
+	infers := m.filterLabels(probs, m.cfg.MinProbability, excluded, stats)
+
+	k = m.cfg.topK(k)
+	if k > len(infers) {
+		k = len(infers)
+	}
+	if stats != nil {
+		stats.BeyondTopK = len(infers) - k
 	}
 
-	infers = make([]InferLabel, 1)
-	infers[0].Prob = prob
-	infers[0].Label = m.labels[idx]
+	if m.cfg.LabelHierarchy {
+		return withParents(infers[:k], hierarchyProbs(m.labels, probs)), nil
+	}
 
-	return infers, nil
+	return infers[:k], nil
 }
 
-func (m *iModel) classifyMulti(probs []float32, k int) ([]InferLabel, error) {
+// classifyMultiLabel 클래스별 확률이 threshold 이상인 클래스를 확률 순서로 반환
+//
+// k를 요청한 경우에만 상위 k개로 제한
+func (m *iModel) classifyMultiLabel(probs []float32, k int, threshold float32, excluded map[string]bool, stats *FilterStats) ([]InferLabel, error) {
 	if len(probs) != m.nrLables {
 		return nil, fmt.Errorf(
-			"The number of correct(%d) and predicted(%d) labels does not match",
+			"%w: the number of correct(%d) and predicted(%d) labels does not match",
+			ErrInvalidConfig,
 			m.nrLables,
 			len(probs),
 		)
 	}
 
-	var infers []InferLabel
-	for idx, prob := range probs {
-		infers = append(infers, InferLabel{
-			Prob:  prob,
-			Label: m.labels[idx],
-		})
+	infers := m.filterLabels(probs, threshold, excluded, stats)
+	if infers == nil {
+		infers = []InferLabel{}
 	}
-	sort.Sort(sortByProb(infers))
 
-	if k <= 0 {
-		k = constants.DefaultMultiClassMax
+	if k > 0 && k < len(infers) {
+		if stats != nil {
+			stats.BeyondTopK = len(infers) - k
+		}
+		infers = infers[:k]
 	}
 
-	if k > len(infers) {
-		k = len(infers)
+	return infers, nil
+}
+
+func (m *iModel) newInferLabel(idx int, prob float32) InferLabel {
+	return InferLabel{
+		Prob:        prob,
+		Label:       m.labels[idx],
+		Index:       idx,
+		Group:       m.labelInfos[idx].Group,
+		DisplayName: m.labelInfos[idx].DisplayName,
+		Description: m.labelInfos[idx].Description,
 	}
+}
 
-	return infers[:k], nil
+func (m *iModel) wait(ctx context.Context) {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for atomic.LoadInt32(&m.refCount) > 0 {
+		select {
+		case <-ctx.Done():
+			log.Printf("%s model is still in use(%d): %s", m.name, atomic.LoadInt32(&m.refCount), ctx.Err())
+			return
+		case <-ticker.C:
+		}
+	}
 }
 
 func (m *iModel) destroy() {
-	m.mutex.Lock()
-	for format, decoder := range m.imageDecoder {
-		if err := decoder.session.Close(); err != nil {
-			log.Printf("%s image decoder session close failed: %s", format, err)
-		} else {
-			log.Printf("%s image decoder session successfully closed", format)
-		}
+	if m.backend == nil {
+		return
 	}
-	m.mutex.Unlock()
 
-	if err := m.tfModel.Session.Close(); err != nil {
-		log.Printf("%s model session close failed: %s", m.name, err)
-	} else {
-		log.Printf("%s model session successfully closed", m.name)
+	m.backend.close(m.name)
+	if m.budget != nil {
+		m.budget.free(m.memory)
+		m.budget = nil
 	}
 }
 
+// release 교체 또는 해제 된 모델을 사용이 끝난 후 해제
+func (m *iModel) release() {
+	m.wait(context.Background())
+	m.destroy()
+}
+
+// modelLoads 모델을 로드한 횟수
+var modelLoads uint64
+
 func getNewModel(model, modelPath string) *iModel {
 	return &iModel{
 		name:             model,
@@ -664,21 +1580,30 @@ func getNewModel(model, modelPath string) *iModel {
 
 func loadModel(m *iModel) error {
 	var (
-		cfgBytes []byte
-		cfg      modelConfig
-		tfModel  *tf.SavedModel
-		labelsFp *os.File
-		labels   []string
-		err      error
+		cfg    modelConfig
+		b      modelBackend
+		labels []labelInfo
+		err    error
 	)
 
-	// config 로드
-	cfgFile := path.Join(m.modelPath, "config.yaml")
-	if cfgBytes, err = ioutil.ReadFile(cfgFile); err != nil {
+	// 버전을 지정하지 않은 경우 manifest의 사용 중인 버전
+	vPath, version := m.versionPath, m.version
+	if vPath == "" {
+		if vPath, version, err = resolveVersion(m.modelPath); err != nil {
+			return err
+		}
+	}
+
+	// 가져온 모델에 labels 파일이 없으면 assets 또는 dataset 디렉토리로 생성
+	generateLabels(m.modelPath, vPath)
+
+	// 생성시 저장한 checksum과 다른 파일이 있으면 로드하지 않음
+	if err := verifyChecksums(vPath); err != nil {
 		return err
 	}
 
-	if err := yaml.Unmarshal(cfgBytes, &cfg); err != nil {
+	// config 로드
+	if cfg, err = loadConfig(vPath); err != nil {
 		return err
 	}
 
@@ -686,33 +1611,54 @@ func loadModel(m *iModel) error {
 		return fmt.Errorf("Not matched model name[%s] in configuration[%s]", m.name, cfg.Name)
 	}
 
-	// model 로드
-	if tfModel, err = tf.LoadSavedModel(m.modelPath, cfg.Tags, nil); err != nil {
-		return err
+	modelHooks, violations := lookupHooks(cfg.Hooks)
+	preprocessor, preprocessorViolations := lookupPreprocessor(cfg.Preprocessor)
+	violations = append(violations, preprocessorViolations...)
+	if cfg.modelFormat() == formatTFLite && m.tfliteRuntime == nil {
+		violations = append(violations, "`format: tflite` requires a TFLite runtime (tflite build tag or inference.WithTFLiteRuntime)")
+	}
+	if len(violations) > 0 {
+		return &ConfigError{File: filepath.Join(vPath, configFile), Violations: violations}
 	}
 
-	// labels 로드
-	labelsFile := path.Join(m.modelPath, cfg.LabelsFile)
-	if labelsFp, err = os.Open(labelsFile); err != nil {
+	// labels 로드 (잘못된 labels로 실행 엔진(TF session)을 열어 두지 않도록 모델보다 먼저 로드하고 검사)
+	if labels, err = loadLabels(filepath.Join(vPath, cfg.LabelsFile)); err != nil {
 		return err
 	}
-	defer labelsFp.Close()
-
-	scanner := bufio.NewScanner(labelsFp)
-	for scanner.Scan() {
-		labels = append(labels, scanner.Text())
+	if violations := cfg.validateLabels(labels); len(violations) > 0 {
+		return &ConfigError{File: filepath.Join(vPath, cfg.LabelsFile), Violations: violations}
 	}
-	if err := scanner.Err(); err != nil {
+	localizedLabels, err := loadLocalizedLabels(vPath, cfg, len(labels))
+	if err != nil {
 		return err
 	}
 
+	// model 로드
+	if b, err = openModelBackend(vPath, cfg, m.tfliteRuntime, m.graphCachePath); err != nil {
+		return err
+	}
+
+	m.version = version
+	m.versionPath = vPath
+	m.loadID = atomic.AddUint64(&modelLoads, 1)
 	m.cfg = cfg
 	m.name = cfg.Name
-	m.tfModel = tfModel
+	m.backend = b
 	m.inputShape = cfg.InputShape[:2]
-	m.imageDecoder = make(map[string]imageDecode)
 	m.nrLables = len(labels)
-	m.labels = labels
+	m.labels = labelNames(labels)
+	m.labelInfos = labels
+	m.localizedLabels = localizedLabels
+	m.labelsSource = readLabelsSource(vPath)
+	m.hooks = modelHooks
+	m.preprocessor = preprocessor
+	if cfg.EmbeddingOperationName != "" {
+		m.index = newEmbeddingIndex()
+	}
+	// 실패해도 실제 요청으로 초기화 되므로 로드는 계속함
+	if err := m.warmup(context.Background()); err != nil {
+		log.Printf("Fail to warmup model(%s): %s", m.name, err)
+	}
 	// Setting status should always be last
 	atomic.StoreInt32(&m.status, modelStatusRun)
 	m.statusUpdateTime = time.Now()
@@ -724,6 +1670,16 @@ func loadModel(m *iModel) error {
 type InferLabel struct {
 	Prob  float32 `json:"probability"`
 	Label string  `json:"label"`
+	// 모델 출력(labels 파일)에서 클래스의 index (Unknown이면 -1)
+	Index int    `json:"index"`
+	Group string `json:"group,omitempty"`
+	// json labels 파일의 클래스별 표시 이름과 설명
+	DisplayName string `json:"displayName,omitempty"`
+	Description string `json:"description,omitempty"`
+	// 가장 높은 확률이 모델의 unknownThreshold 미만이라 판단하지 않은 결과 (Prob는 가장 높은 확률)
+	Unknown bool `json:"unknown,omitempty"`
+	// labelHierarchy 모델에서 최상위부터의 상위 카테고리와 하위 클래스의 확률을 합한 확률 (Index는 -1)
+	Parents []InferLabel `json:"parents,omitempty"`
 }
 
 type sortByProb []InferLabel
@@ -736,19 +1692,87 @@ func (s sortByProb) Swap(i, j int) {
 	s[i], s[j] = s[j], s[i]
 }
 
+// Less 확률이 같으면 클래스 이름 순서로 정렬하여 결과 순서를 항상 같게 함
 func (s sortByProb) Less(i, j int) bool {
-	return s[i].Prob > s[j].Prob
+	if s[i].Prob != s[j].Prob {
+		return s[i].Prob > s[j].Prob
+	}
+
+	return s[i].Label < s[j].Label
 }
 
-// New 이미지 추론 모델 생성
-func New(c Config) (i *Inference, err error) {
+// New 이미지 추론 엔진 생성
+//
+// 모델 저장 경로의 모델을 로드하며, 모델이 없으면 learner에 기본 모델 생성을 요청
+func New(opts ...Option) (i *Inference, err error) {
+	var c Config
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	modelsPath := c.ModelsPath
+	if modelsPath == "" {
+		modelsPath = constants.ModelsPath
+	}
+
+	dirNamer, err := lookupDirNamer(c.DirNaming)
+	if err != nil {
+		return nil, err
+	}
+
+	var pipelines map[string]pipelineConfig
+	if c.PipelinesFile != "" {
+		if pipelines, err = loadPipelines(c.PipelinesFile); err != nil {
+			return nil, err
+		}
+	}
+
+	graphCachePath := c.GraphCachePath
+	switch graphCachePath {
+	case "":
+		graphCachePath = filepath.Join(modelsPath, graphsDir)
+	case "-":
+		graphCachePath = ""
+	}
+
+	// tflite build tag로 빌드하면 TensorFlow Lite C API를 기본값으로 사용
+	tfliteRuntime := c.TFLiteRuntime
+	if tfliteRuntime == nil {
+		tfliteRuntime = defaultTFLiteRuntime
+	}
+
 	i = &Inference{
-		models:        make(map[string]*iModel),
-		modelsPath:    constants.ModelsPath,
-		userModelPath: c.UserModelPath,
-		lHost:         c.LHost,
+		models:            make(map[string]*iModel),
+		modelsPath:        modelsPath,
+		fallbackModelPath: c.FallbackModelPath,
+		tenants:           make(map[string]*tenant),
+		tenantQuota:       c.TenantQuota,
+		dirNamer:          dirNamer,
+		authHook:          c.AuthHook,
+		metrics:           c.Metrics,
+		imageLimits:       c.ImageLimits,
+		probeDecode:       c.ProbeDecode,
+		heicConverter:     c.HEICConverter,
+		tfliteRuntime:     tfliteRuntime,
+		graphCachePath:    graphCachePath,
+		preDownscale:      c.PreDownscale,
+		warmupRuns:        c.WarmupRuns,
+		pipelines:         pipelines,
+		frameSampler:      c.FrameSampler,
+		resultCache:       newResultCache(c.ResultCacheSize, c.ResultCacheTTL),
+		keepVersions:      c.KeepVersions,
+		memory:            &memoryBudget{limit: c.MemoryBudget},
+		lHost:             c.LHost,
+	}
+	if err = i.init(); err != nil {
+		return
+	}
+
+	if c.WatchDebounce > 0 {
+		if i.watcher, err = i.watchModels(c.WatchDebounce); err != nil {
+			err = fmt.Errorf("Fail to watch models path(%s): %w", modelsPath, err)
+		}
 	}
-	err = i.init()
 
 	return
 }
@@ -0,0 +1,91 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOperateModelCanceled(t *testing.T) {
+	modelsPath, err := ioutil.TempDir("", "clsapp-models-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(modelsPath)
+
+	modelPath := filepath.Join(modelsPath, "flowers-1234")
+	if err := os.MkdirAll(modelPath, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	i := &Inference{
+		models:     make(map[string]*iModel),
+		modelsPath: modelsPath,
+	}
+	m := getNewModel("flowers", modelPath)
+	m.version = 1
+	m.versionPath = versionPath(modelPath, m.version)
+	if err := i.addModel(m); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := i.OperateModel(ctx, "flowers", m.versionPath); err != context.Canceled {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// 취소된 요청은 모델을 로드하지 않지만 학습 된 파일과 모델 슬롯은 유지
+	if _, err := os.Stat(modelPath); err != nil {
+		t.Fatalf("Model files should remain: %s", err)
+	}
+	if m, ok := i.models["flowers"]; !ok || m.status == modelStatusRun {
+		t.Fatalf("Model should remain unloaded: %v", m)
+	}
+}
+
+func TestOperateModelUnknown(t *testing.T) {
+	modelsPath, err := ioutil.TempDir("", "clsapp-models-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(modelsPath)
+
+	defaultPath := filepath.Join(modelsPath, "default-golden")
+	orphanPath := filepath.Join(modelsPath, "flowers-1234")
+	for _, p := range []string{versionPath(defaultPath, 1), versionPath(orphanPath, 1)} {
+		if err := os.MkdirAll(p, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	i := &Inference{
+		models:     make(map[string]*iModel),
+		modelsPath: modelsPath,
+	}
+	if err := i.addModel(getNewModel("default", defaultPath)); err != nil {
+		t.Fatal(err)
+	}
+
+	// 등록 된 모델의 경로와 그 하위 경로는 삭제하지 않음
+	for _, p := range []string{defaultPath, versionPath(defaultPath, 1), modelsPath} {
+		if err := i.OperateModel(context.Background(), "bogus", p); !errors.Is(err, ErrModelNotFound) {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := os.Stat(p); err != nil {
+			t.Fatalf("Registered model path %s should remain: %s", p, err)
+		}
+	}
+
+	// 등록 된 모델이 없는 경로는 모델 디렉토리 전체를 삭제
+	if err := i.OperateModel(context.Background(), "flowers", versionPath(orphanPath, 1)); !errors.Is(err, ErrModelNotFound) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := os.Stat(orphanPath); !os.IsNotExist(err) {
+		t.Fatalf("Orphan model path should be removed: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package inference
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Inferencer 이미지 추론 모델 관리 인터페이스
+//
+// HTTP 핸들러와 다른 패키지는 *Inference 대신 이 인터페이스에 의존하여,
+// 실제 모델 없이 mock 구현으로 테스트 할 수 있음.
+// ctx의 취소와 deadline은 learner 요청과 이미지 전처리, 추론 단계에 전달 됨
+type Inferencer interface {
+	// CreateModel 추론모델 생성
+	CreateModel(ctx context.Context, newModel, subject, desc string, epochs int, trial bool) (*CreateResult, error)
+	// CreateVersion 사용 중인 버전으로 계속 추론하면서 모델의 새 버전을 학습
+	CreateVersion(ctx context.Context, model, subject, desc string, epochs int, trial bool) (*CreateResult, error)
+	// ImportModel learner 없이 외부에서 학습한 모델의 tar.gz 파일을 등록
+	ImportModel(ctx context.Context, model string, archive io.Reader) (*CreateResult, error)
+	// ExportModel 모델의 SavedModel, config, labels와 생성 정보를 tar.gz로 w에 씀
+	ExportModel(ctx context.Context, model string, w io.Writer) error
+	// CloneModel src 모델의 파일을 복사해 dst 모델로 등록
+	CloneModel(ctx context.Context, src, dst string) (*CreateResult, error)
+	// OperateModel 생성 된 추론모델 로드
+	OperateModel(ctx context.Context, model, modelPath string) error
+	// DeleteModel 모델 삭제
+	DeleteModel(ctx context.Context, model string) error
+	// ForceDeleteModel 새로 사용하지 않도록 한 후 진행 중인 추론이 끝나기를 최대 timeout 동안 기다려 모델 삭제
+	ForceDeleteModel(ctx context.Context, model string, timeout time.Duration) error
+	// ReloadModel 모델의 config, labels와 SavedModel을 다시 로드
+	ReloadModel(ctx context.Context, model string) error
+	// UpdateModel 모델을 다시 로드하지 않고 설명, modelTags와 기본 threshold 변경
+	UpdateModel(ctx context.Context, model string, update ModelUpdate) error
+	// DisableModel 모델을 로드 된 상태로 두고 추론을 중지
+	DisableModel(ctx context.Context, model string) error
+	// EnableModel 추론을 중지한 모델의 추론을 다시 시작
+	EnableModel(ctx context.Context, model string) error
+	// RollbackModel 모델의 사용 중인 버전을 이전 버전으로 되돌리고 되돌린 버전을 반환
+	RollbackModel(ctx context.Context, model string) (int, error)
+	// GetModels 조건을 만족하는 이미지 추론 모델 목록 반환
+	GetModels(ctx context.Context, filter ModelFilter) []string
+	// GetModelInfos 조건을 만족하는 모델 목록의 한 page에 해당하는 모델 정보 반환
+	GetModelInfos(ctx context.Context, filter ModelFilter, page, size int) *ModelInfoPage
+	// GetModel 이미지 추론 모델 정보 반환
+	GetModel(ctx context.Context, model string, verbose bool) (*ModelInfo, error)
+	// GetModelGraph 로드 된 모델의 graph 요약 반환
+	GetModelGraph(ctx context.Context, model string, verbose bool) (*GraphSummary, error)
+	// Infer 추론
+	Infer(ctx context.Context, model string, image []byte, format string, k int, threshold float32) ([]InferLabel, error)
+	// InferRaw 추론 결과와 활성화 함수를 적용하기 전의 모델 출력
+	InferRaw(ctx context.Context, model string, image []byte, format string, k int, threshold float32) (*RawInference, error)
+	// Run InferRequest로 추론
+	Run(ctx context.Context, req InferRequest) (*RawInference, error)
+	// InferBatch 여러 이미지를 하나의 모델로 추론
+	InferBatch(ctx context.Context, model string, images [][]byte, format string, k int, threshold float32) ([][]InferLabel, error)
+	// InferTensor 이미지 디코딩과 전처리 없이, 전처리 된 입력으로 추론
+	InferTensor(ctx context.Context, model string, data []float32, shape []int, k int, threshold float32) ([]InferLabel, error)
+	// Compare 하나의 이미지를 여러 모델로 추론하여 라벨별로 비교
+	Compare(ctx context.Context, models []string, image []byte, format string) (*Comparison, error)
+	// InferAll 하나의 이미지를 로드 된 모든 모델(tags로 선택)로 추론
+	InferAll(ctx context.Context, tags []string, image []byte, format string, k int, threshold float32) ([]ModelResult, error)
+	// InferPipeline pipeline의 gate 모델을 통과한 이미지만 다음 단계의 모델로 추론
+	InferPipeline(ctx context.Context, pipeline string, image []byte, format string, k int, threshold float32) (*PipelineResult, error)
+	// CacheStats 추론 결과 캐시 사용 현황
+	CacheStats() CacheStats
+	// InferVideo 동영상에서 추출한 frame별 추론 결과와 다수결, 평균 반환
+	InferVideo(ctx context.Context, model string, video []byte, fps float64, k int, threshold float32) (*VideoResult, error)
+	// Detect detection 모델로 이미지의 대상과 위치를 검출
+	Detect(ctx context.Context, model string, image []byte, format string, k int, threshold float32) ([]DetectionResult, error)
+	// Segment segmentation 모델로 이미지의 픽셀별 클래스를 mask로 반환
+	Segment(ctx context.Context, model string, image []byte, format string, threshold float32, encoding string) (*SegmentationResult, error)
+	// Explain 추론 결과의 클래스에 영향을 준 이미지 영역을 Grad-CAM heatmap으로 반환
+	Explain(ctx context.Context, model string, image []byte, format, label string) (*Explanation, error)
+	// IndexImage 이미지의 embedding을 id로 모델의 검색 색인에 추가
+	IndexImage(ctx context.Context, model, id string, image []byte, format string) error
+	// RemoveIndexedImage id를 모델의 검색 색인에서 제거
+	RemoveIndexedImage(ctx context.Context, model, id string) error
+	// ClearIndex 모델의 검색 색인을 비움
+	ClearIndex(ctx context.Context, model string) error
+	// Search 모델의 검색 색인에서 이미지와 embedding이 가까운 순서로 n개의 이미지 반환
+	Search(ctx context.Context, model string, image []byte, format string, n int) ([]SearchResult, error)
+	// CreateTenant 사용자 모델 저장 공간 생성
+	CreateTenant(ctx context.Context, tenant string, quota int64) error
+	// DeleteTenant 사용자와 사용자의 모든 모델 삭제
+	DeleteTenant(ctx context.Context, tenant string) error
+	// GetTenants 사용자 목록 반환
+	GetTenants(ctx context.Context) []string
+	// GetTenant 사용자의 quota, 사용량과 모델 목록 반환
+	GetTenant(ctx context.Context, tenant string) (map[string]interface{}, error)
+	// SetAlias 별칭이 target 모델을 가리키도록 지정하고 이전 대상을 반환
+	SetAlias(ctx context.Context, alias, target string) (string, error)
+	// DeleteAlias 별칭 삭제
+	DeleteAlias(ctx context.Context, alias string) error
+	// GetAliases 별칭과 대상 모델 반환
+	GetAliases(ctx context.Context) map[string]string
+	// Destroy 추론 모델 해제
+	Destroy(ctx context.Context)
+}
+
+var _ Inferencer = (*Inference)(nil)
@@ -0,0 +1,240 @@
+package inference
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// labels 자동 생성
+//
+// 사전 학습 된 모델을 가져올 때 config의 labelsFile이 없으면 아래 순서로 클래스 이름을 찾아
+// labels 파일을 만들고, 어디서 만들었는지 labelsSourceFile에 기록
+//
+//	<version>/assets/, <version>/assets.extra/   SavedModel assets의 class_names 또는 labels 파일
+//	<version>/dataset/, <model>/dataset/          클래스별 하위 디렉토리 (이름 순서)
+const (
+	labelsSourceFile = "labels.source.json"
+
+	labelsFromAssets  = "assets"
+	labelsFromDataset = "dataset"
+)
+
+// SavedModel assets에서 클래스 이름을 찾을 파일 이름 (확장자 제외)
+var labelAssetNames = map[string]bool{
+	"class_names": true,
+	"classnames":  true,
+	"labels":      true,
+}
+
+// LabelsSource 자동 생성 된 labels 파일의 출처
+type LabelsSource struct {
+	LabelsFile string `json:"labelsFile"`
+	// assets 또는 dataset
+	Source string `json:"source"`
+	// 모델 버전 디렉토리 기준 상대 경로
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// generateLabels config의 labelsFile이 없으면 assets 또는 dataset 디렉토리로 생성
+//
+// 생성할 수 없으면 아무것도 하지 않으며, 이후 config 검사에서 labelsFile 누락으로 처리 됨
+func generateLabels(modelPath, vPath string) {
+	b, err := ioutil.ReadFile(filepath.Join(vPath, configFile))
+	if err != nil {
+		return
+	}
+
+	var cfg struct {
+		LabelsFile string `yaml:"labelsFile"`
+	}
+	if err := yaml.Unmarshal(b, &cfg); err != nil || cfg.LabelsFile == "" {
+		return
+	}
+
+	labelsFile := filepath.Join(vPath, cfg.LabelsFile)
+	if !withinPath(vPath, labelsFile) || isFile(labelsFile) {
+		return
+	}
+
+	labels, source, path := findLabels(modelPath, vPath)
+	if len(labels) == 0 {
+		return
+	}
+
+	if err := writeLabels(labelsFile, labels); err != nil {
+		log.Printf("Fail to generate labels(%s): %s", labelsFile, err)
+		return
+	}
+
+	rel, _ := filepath.Rel(vPath, path)
+	s, _ := json.MarshalIndent(LabelsSource{
+		LabelsFile: cfg.LabelsFile,
+		Source:     source,
+		Path:       filepath.ToSlash(rel),
+		CreatedAt:  time.Now(),
+	}, "", "  ")
+	if err := writeFileAtomic(filepath.Join(vPath, labelsSourceFile), s); err != nil {
+		log.Printf("Fail to write labels source(%s): %s", vPath, err)
+	}
+
+	log.Printf("Generate labels(%s) from %s: %d labels", labelsFile, path, len(labels))
+}
+
+// readLabelsSource labels가 자동 생성 되었으면 출처 반환
+func readLabelsSource(vPath string) *LabelsSource {
+	b, err := ioutil.ReadFile(filepath.Join(vPath, labelsSourceFile))
+	if err != nil {
+		return nil
+	}
+
+	var s LabelsSource
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil
+	}
+
+	return &s
+}
+
+// findLabels 클래스 이름과 출처, 찾은 경로 반환
+func findLabels(modelPath, vPath string) ([]string, string, string) {
+	for _, dir := range []string{"assets", "assets.extra"} {
+		files, _ := ioutil.ReadDir(filepath.Join(vPath, dir))
+		for _, file := range files {
+			name := strings.ToLower(file.Name())
+			if file.IsDir() || !labelAssetNames[strings.TrimSuffix(name, filepath.Ext(name))] {
+				continue
+			}
+
+			path := filepath.Join(vPath, dir, file.Name())
+			labels, err := readLabelAsset(path)
+			if err != nil {
+				log.Printf("Fail to read labels from %s: %s", path, err)
+				continue
+			}
+			if len(labels) > 0 {
+				return labels, labelsFromAssets, path
+			}
+		}
+	}
+
+	for _, path := range []string{
+		filepath.Join(vPath, "dataset"),
+		filepath.Join(modelPath, "dataset"),
+	} {
+		if labels := datasetLabels(path); len(labels) > 0 {
+			return labels, labelsFromDataset, path
+		}
+	}
+
+	return nil, "", ""
+}
+
+// readLabelAsset 텍스트 또는 json 형식의 클래스 이름 파일 읽기
+//
+// json은 클래스 이름 배열, 이름과 index의 객체(Keras class_indices) 또는 index와 이름의 객체를 지원
+func readLabelAsset(path string) ([]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.ToLower(filepath.Ext(path)) != ".json" {
+		var labels []string
+		for _, line := range strings.Split(string(b), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				labels = append(labels, line)
+			}
+		}
+		return labels, nil
+	}
+
+	var names []string
+	if err := json.Unmarshal(b, &names); err == nil {
+		return names, nil
+	}
+
+	var indices map[string]int
+	if err := json.Unmarshal(b, &indices); err == nil {
+		return indexedLabels(indices)
+	}
+
+	var byIndex map[string]string
+	if err := json.Unmarshal(b, &byIndex); err != nil {
+		return nil, err
+	}
+
+	indices = make(map[string]int, len(byIndex))
+	for k, name := range byIndex {
+		idx, err := strconv.Atoi(k)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid label index: %s", k)
+		}
+		indices[name] = idx
+	}
+
+	return indexedLabels(indices)
+}
+
+// indexedLabels 이름과 index로 labels 구성 (index는 0부터 빠짐없이 있어야 함)
+func indexedLabels(indices map[string]int) ([]string, error) {
+	labels := make([]string, len(indices))
+	for name, idx := range indices {
+		if idx < 0 || idx >= len(labels) || labels[idx] != "" {
+			return nil, fmt.Errorf("Invalid label index: %s(%d)", name, idx)
+		}
+		labels[idx] = name
+	}
+
+	return labels, nil
+}
+
+// datasetLabels 데이터셋 디렉토리의 클래스별 하위 디렉토리 이름
+//
+// Keras image_dataset_from_directory와 같이 이름 순서로 정렬하며, train 디렉토리가 있으면 사용
+func datasetLabels(path string) []string {
+	if info, err := ioutil.ReadDir(filepath.Join(path, "train")); err == nil && len(info) > 0 {
+		path = filepath.Join(path, "train")
+	}
+
+	dirs, _ := ioutil.ReadDir(path)
+
+	var labels []string
+	for _, dir := range dirs {
+		if dir.IsDir() && !strings.HasPrefix(dir.Name(), ".") {
+			labels = append(labels, dir.Name())
+		}
+	}
+	sort.Strings(labels)
+
+	return labels
+}
+
+// writeLabels labels 파일 확장자에 맞는 형식으로 저장
+func writeLabels(labelsFile string, labels []string) error {
+	var b []byte
+	if strings.ToLower(filepath.Ext(labelsFile)) == ".json" {
+		manifest := labelManifest{Labels: make([]labelInfo, len(labels))}
+		for idx, label := range labels {
+			manifest.Labels[idx] = labelInfo{Name: label}
+		}
+
+		var err error
+		if b, err = json.MarshalIndent(manifest, "", "  "); err != nil {
+			return err
+		}
+	} else {
+		b = []byte(strings.Join(labels, "\n") + "\n")
+	}
+
+	return writeFileAtomic(labelsFile, b)
+}
@@ -0,0 +1,138 @@
+package inference
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateLabelsFromAssets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "labelgen-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	vPath := versionPath(dir, 1)
+	if err := os.MkdirAll(filepath.Join(vPath, "assets"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		configFile:                          "name: imported\nlabelsFile: labels.txt\n",
+		"assets/class_names.json":           `{"roses": 1, "daisy": 0, "tulips": 2}`,
+		"dataset/cat/.keep":                 "",
+		"../../dataset/ignored/placeholder": "",
+	}
+	for name, content := range files {
+		file := filepath.Join(vPath, name)
+		os.MkdirAll(filepath.Dir(file), os.ModePerm)
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	generateLabels(dir, vPath)
+
+	labels, err := loadLabels(filepath.Join(vPath, "labels.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := labelNames(labels); !reflect.DeepEqual(names, []string{"daisy", "roses", "tulips"}) {
+		t.Fatalf("Unexpected labels: %v", names)
+	}
+
+	source := readLabelsSource(vPath)
+	if source == nil || source.Source != labelsFromAssets || source.Path != "assets/class_names.json" {
+		t.Fatalf("Unexpected source: %+v", source)
+	}
+}
+
+func TestGenerateLabelsFromDataset(t *testing.T) {
+	dir, err := ioutil.TempDir("", "labelgen-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	vPath := versionPath(dir, 1)
+	for _, d := range []string{"dataset/train/roses", "dataset/train/daisy", "dataset/train/.cache", "dataset/validation/roses"} {
+		if err := os.MkdirAll(filepath.Join(dir, d), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(vPath, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(vPath, configFile), []byte("labelsFile: labels.json\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	generateLabels(dir, vPath)
+
+	labels, err := loadLabels(filepath.Join(vPath, "labels.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := labelNames(labels); !reflect.DeepEqual(names, []string{"daisy", "roses"}) {
+		t.Fatalf("Unexpected labels: %v", names)
+	}
+	if source := readLabelsSource(vPath); source == nil || source.Source != labelsFromDataset {
+		t.Fatalf("Unexpected source: %+v", source)
+	}
+}
+
+func TestGenerateLabelsKeepExisting(t *testing.T) {
+	dir, err := ioutil.TempDir("", "labelgen-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	os.MkdirAll(filepath.Join(dir, "dataset", "cat"), os.ModePerm)
+	ioutil.WriteFile(filepath.Join(dir, configFile), []byte("labelsFile: labels.txt\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "labels.txt"), []byte("dog\n"), 0644)
+
+	generateLabels(dir, dir)
+
+	if b, _ := ioutil.ReadFile(filepath.Join(dir, "labels.txt")); string(b) != "dog\n" {
+		t.Fatalf("Unexpected labels: %q", b)
+	}
+	if readLabelsSource(dir) != nil {
+		t.Fatal("Unexpected labels source")
+	}
+}
+
+func TestReadLabelAsset(t *testing.T) {
+	dir, err := ioutil.TempDir("", "labelgen-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name    string
+		content string
+		labels  []string
+		err     bool
+	}{
+		{"labels.txt", "daisy\n\nroses\n", []string{"daisy", "roses"}, false},
+		{"a.json", `["daisy", "roses"]`, []string{"daisy", "roses"}, false},
+		{"b.json", `{"1": "roses", "0": "daisy"}`, []string{"daisy", "roses"}, false},
+		{"c.json", `{"daisy": 0, "roses": 2}`, nil, true},
+		{"d.json", `{"x": "daisy"}`, nil, true},
+	}
+
+	for _, tt := range tests {
+		file := filepath.Join(dir, tt.name)
+		if err := ioutil.WriteFile(file, []byte(tt.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		labels, err := readLabelAsset(file)
+		if (err != nil) != tt.err || !reflect.DeepEqual(labels, tt.labels) {
+			t.Errorf("readLabelAsset(%s) = %v, %v", tt.name, labels, err)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package inference
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// labelInfo 클래스별 후처리 정보
+type labelInfo struct {
+	Name string `json:"name"`
+	// 결과에 포함되기 위한 최소 확률
+	MinProbability float32 `json:"minProbability"`
+	// 결과에서 제외되는 클래스
+	Hidden bool `json:"hidden"`
+	// 클래스가 속한 그룹
+	Group string `json:"group"`
+	// 사용자에게 보여줄 클래스 이름 (예: 번역 된 이름)
+	DisplayName string `json:"displayName"`
+	// 클래스 설명
+	Description string `json:"description"`
+}
+
+// labelManifest json 형식의 labels 파일
+//
+//	{"labels": [{"name": "roses", "minProbability": 0.1, "group": "flowers", "displayName": "장미", "description": "..."}, ...]}
+type labelManifest struct {
+	Labels []labelInfo `json:"labels"`
+}
+
+func (l *labelInfo) visible(prob float32) bool {
+	return !l.Hidden && prob >= l.MinProbability
+}
+
+// loadLabels labels 파일 로드
+//
+// 확장자가 .json인 경우 labelManifest로 읽으며, 그 외에는 한 줄에 하나의 클래스 이름을 읽음
+func loadLabels(labelsFile string) ([]labelInfo, error) {
+	if strings.ToLower(filepath.Ext(labelsFile)) == ".json" {
+		return loadLabelManifest(labelsFile)
+	}
+
+	labelsFp, err := os.Open(labelsFile)
+	if err != nil {
+		return nil, err
+	}
+	defer labelsFp.Close()
+
+	var labels []labelInfo
+	scanner := bufio.NewScanner(labelsFp)
+	for scanner.Scan() {
+		labels = append(labels, labelInfo{Name: scanner.Text()})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return labels, nil
+}
+
+func loadLabelManifest(labelsFile string) ([]labelInfo, error) {
+	b, err := ioutil.ReadFile(labelsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest labelManifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return nil, err
+	}
+
+	if len(manifest.Labels) == 0 {
+		return nil, fmt.Errorf("Empty labels in manifest: %s", labelsFile)
+	}
+
+	names := make(map[string]bool, len(manifest.Labels))
+	for _, label := range manifest.Labels {
+		if label.Name == "" {
+			return nil, errors.New("Empty label name in manifest")
+		}
+		if names[label.Name] {
+			return nil, fmt.Errorf("Duplicated label name in manifest: %s", label.Name)
+		}
+		names[label.Name] = true
+	}
+
+	return manifest.Labels, nil
+}
+
+// labelSeparator 계층 labels에서 클래스 이름의 상위 카테고리 구분자 (예: dog/retriever/golden)
+const labelSeparator = "/"
+
+// labelAncestors 클래스 이름의 상위 카테고리를 최상위부터 반환 (dog/retriever/golden → dog, dog/retriever)
+func labelAncestors(name string) []string {
+	parts := strings.Split(name, labelSeparator)
+
+	ancestors := make([]string, 0, len(parts)-1)
+	for idx := 1; idx < len(parts); idx++ {
+		ancestors = append(ancestors, strings.Join(parts[:idx], labelSeparator))
+	}
+
+	return ancestors
+}
+
+// validLabelPath 계층 클래스 이름에 비어 있는 카테고리가 없는지 검사
+func validLabelPath(name string) bool {
+	for _, part := range strings.Split(name, labelSeparator) {
+		if part == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hierarchyProbs 카테고리별 확률로, 카테고리 자신과 하위 클래스의 확률을 합함
+//
+// 숨김 클래스의 확률도 상위 카테고리에 포함
+func hierarchyProbs(labels []string, probs []float32) map[string]float32 {
+	sums := make(map[string]float32)
+	for idx, name := range labels {
+		for _, ancestor := range labelAncestors(name) {
+			sums[ancestor] += probs[idx]
+		}
+		sums[name] += probs[idx]
+	}
+
+	return sums
+}
+
+// withParents 클래스별 결과에 상위 카테고리의 합산 확률을 최상위부터 추가
+func withParents(infers []InferLabel, sums map[string]float32) []InferLabel {
+	for idx := range infers {
+		ancestors := labelAncestors(infers[idx].Label)
+		if len(ancestors) == 0 {
+			continue
+		}
+
+		parents := make([]InferLabel, 0, len(ancestors))
+		for _, ancestor := range ancestors {
+			parents = append(parents, InferLabel{Prob: sums[ancestor], Label: ancestor, Index: -1})
+		}
+		infers[idx].Parents = parents
+	}
+
+	return infers
+}
+
+// remap labelMap으로 클래스 이름을 바꾸고, 같은 이름이 된 클래스들을 처음 나온 위치에 하나로 합침
+//
+// 합친 클래스의 확률은 multilabel 모델은 최대값, 그 외에는 합이며, index와 group은 확률이 가장 높은 클래스를 따름
+// 이름을 바꾼 클래스의 displayName과 description은 원래 클래스의 것이므로 제외
+func (m *iModel) remap(infers []InferLabel) []InferLabel {
+	if len(m.cfg.LabelMap) == 0 {
+		return infers
+	}
+
+	var (
+		remapped = make([]InferLabel, 0, len(infers))
+		// 합친 클래스들 중 가장 높은 확률
+		maxProbs []float32
+		merged   = make(map[string]int)
+	)
+	for _, infer := range infers {
+		if name, ok := m.cfg.LabelMap[infer.Label]; ok {
+			infer.Label = name
+			infer.DisplayName = ""
+			infer.Description = ""
+		}
+
+		pos, ok := merged[infer.Label]
+		if !ok {
+			merged[infer.Label] = len(remapped)
+			remapped = append(remapped, infer)
+			maxProbs = append(maxProbs, infer.Prob)
+			continue
+		}
+
+		r := &remapped[pos]
+		prob := r.Prob + infer.Prob
+		if m.cfg.Classification == multiLabelClass {
+			prob = r.Prob
+		}
+		if infer.Prob > maxProbs[pos] {
+			maxProbs[pos] = infer.Prob
+			*r = infer
+			if m.cfg.Classification == multiLabelClass {
+				prob = infer.Prob
+			}
+		}
+		r.Prob = prob
+	}
+
+	return remapped
+}
+
+func labelNames(labels []labelInfo) []string {
+	names := make([]string, len(labels))
+	for idx, label := range labels {
+		names[idx] = label.Name
+	}
+
+	return names
+}
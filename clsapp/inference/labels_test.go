@@ -0,0 +1,296 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLabelManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "labels-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	manifest := `{"labels": [
+		{"name": "daisy", "group": "flowers", "displayName": "데이지", "description": "국화과 식물"},
+		{"name": "roses", "group": "flowers", "minProbability": 0.3},
+		{"name": "background", "hidden": true},
+		{"name": "cat", "group": "animals"}
+	]}`
+	labelsFile := filepath.Join(dir, "labels.json")
+	if err := ioutil.WriteFile(labelsFile, []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	labels, err := loadLabels(labelsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &iModel{
+		nrLables:   len(labels),
+		labels:     labelNames(labels),
+		labelInfos: labels,
+	}
+
+	infers, err := m.classifyMulti([]float32{0.2, 0.25, 0.4, 0.15}, 5, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// background는 숨김, roses는 최소 확률 미만으로 제외
+	if len(infers) != 2 {
+		t.Fatalf("Unexpected infers: %v", infers)
+	}
+	if infers[0].Label != "daisy" || infers[0].Index != 0 || infers[0].Group != "flowers" ||
+		infers[0].DisplayName != "데이지" || infers[0].Description != "국화과 식물" {
+		t.Fatalf("Unexpected first infer: %v", infers[0])
+	}
+	if infers[1].Label != "cat" || infers[1].Index != 3 || infers[1].Group != "animals" || infers[1].DisplayName != "" {
+		t.Fatalf("Unexpected second infer: %v", infers[1])
+	}
+
+	// k를 지정하지 않으면 defaultTopK, 모든 클래스에 minProbability 적용
+	m.cfg = modelConfig{DefaultTopK: 1}
+	if infers, err := m.classifyMulti([]float32{0.2, 0.25, 0.4, 0.15}, 0, nil, nil); err != nil || len(infers) != 1 || infers[0].Label != "daisy" {
+		t.Fatalf("Unexpected default top-k infers: %v, %v", infers, err)
+	}
+	m.cfg = modelConfig{MinProbability: 0.18}
+	if infers, err := m.classifyMulti([]float32{0.2, 0.25, 0.4, 0.15}, 0, nil, nil); err != nil || len(infers) != 1 {
+		t.Fatalf("Unexpected min probability infers: %v, %v", infers, err)
+	}
+}
+
+func TestClassifyMultiLabel(t *testing.T) {
+	labels := []labelInfo{
+		{Name: "person"},
+		{Name: "dog", MinProbability: 0.9},
+		{Name: "background", Hidden: true},
+		{Name: "car"},
+	}
+	m := &iModel{
+		nrLables:   len(labels),
+		labels:     labelNames(labels),
+		labelInfos: labels,
+	}
+
+	// 확률의 합과 관계없이 threshold 이상인 클래스를 모두 반환 (dog는 최소 확률 미만, background는 숨김)
+	probs := []float32{0.7, 0.8, 0.95, 0.9}
+	infers, err := m.classifyMultiLabel(probs, 0, 0.5, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infers) != 2 || infers[0].Label != "car" || infers[1].Label != "person" {
+		t.Fatalf("Unexpected infers: %v", infers)
+	}
+
+	if infers, err := m.classifyMultiLabel(probs, 1, 0.5, nil, nil); err != nil || len(infers) != 1 || infers[0].Label != "car" {
+		t.Fatalf("Unexpected top-1 infers: %v, %v", infers, err)
+	}
+	if infers, err := m.classifyMultiLabel(probs, 0, 0.95, nil, nil); err != nil || len(infers) != 0 {
+		t.Fatalf("No label should be over threshold: %v, %v", infers, err)
+	}
+	if _, err := m.classifyMultiLabel(probs[:2], 0, 0.5, nil, nil); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Mismatched outputs should fail: %v", err)
+	}
+}
+
+func TestUnknown(t *testing.T) {
+	infers := []InferLabel{{Prob: 0.4, Label: "daisy"}, {Prob: 0.3, Label: "roses"}}
+
+	cfg := modelConfig{}
+	if got := cfg.unknown(infers); len(got) != 2 {
+		t.Fatalf("Disabled unknown should keep infers: %v", got)
+	}
+
+	cfg = modelConfig{UnknownThreshold: 0.4}
+	if got := cfg.unknown(infers); len(got) != 2 || got[0].Unknown {
+		t.Fatalf("Top probability at threshold should keep infers: %v", got)
+	}
+
+	cfg = modelConfig{UnknownThreshold: 0.5}
+	got := cfg.unknown(infers)
+	if len(got) != 1 || got[0].Label != "unknown" || !got[0].Unknown || got[0].Prob != 0.4 || got[0].Index != -1 {
+		t.Fatalf("Unexpected unknown infers: %v", got)
+	}
+
+	cfg = modelConfig{UnknownThreshold: 0.5, UnknownLabel: "other"}
+	if got := cfg.unknown(nil); len(got) != 1 || got[0].Label != "other" || got[0].Prob != 0 {
+		t.Fatalf("Unexpected unknown infers for empty result: %v", got)
+	}
+}
+
+func TestLabelHierarchy(t *testing.T) {
+	labels := []labelInfo{
+		{Name: "dog/retriever/golden"},
+		{Name: "dog/retriever/labrador"},
+		{Name: "dog/poodle"},
+		{Name: "cat", Hidden: true},
+	}
+	m := &iModel{
+		cfg:        modelConfig{Classification: multiClass, LabelHierarchy: true},
+		nrLables:   len(labels),
+		labels:     labelNames(labels),
+		labelInfos: labels,
+	}
+
+	infers, err := m.classifyMulti([]float32{0.3, 0.25, 0.35, 0.1}, 2, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infers) != 2 || infers[0].Label != "dog/poodle" || infers[1].Label != "dog/retriever/golden" {
+		t.Fatalf("Unexpected infers: %v", infers)
+	}
+
+	// 상위 카테고리는 top-k나 숨김과 관계 없이 모든 하위 클래스의 확률을 합함
+	parents := infers[1].Parents
+	if len(parents) != 2 || parents[0].Label != "dog" || parents[1].Label != "dog/retriever" || parents[1].Index != -1 {
+		t.Fatalf("Unexpected parents: %v", parents)
+	}
+	if math.Abs(float64(parents[0].Prob-0.9)) > 1e-6 || math.Abs(float64(parents[1].Prob-0.55)) > 1e-6 {
+		t.Fatalf("Unexpected parent probabilities: %v", parents)
+	}
+
+	if got := labelAncestors("cat"); len(got) != 0 {
+		t.Fatalf("Top level label should have no ancestors: %v", got)
+	}
+	if violations := m.cfg.validateLabels([]labelInfo{{Name: "dog/"}, {Name: "/cat"}, {Name: "dog//poodle"}}); len(violations) != 3 {
+		t.Fatalf("Empty categories should fail: %v", violations)
+	}
+}
+
+func TestRemap(t *testing.T) {
+	labels := []labelInfo{
+		{Name: "daisy", DisplayName: "데이지"},
+		{Name: "roses"},
+		{Name: "tulips"},
+		{Name: "sunflowers"},
+	}
+	m := &iModel{
+		cfg: modelConfig{
+			Classification: multiClass,
+			LabelMap:       map[string]string{"daisy": "flower", "tulips": "flower", "roses": "rose"},
+		},
+		nrLables:   len(labels),
+		labels:     labelNames(labels),
+		labelInfos: labels,
+	}
+
+	// daisy와 tulips를 합친 flower가 가장 높은 확률이 되며, 합치기 전에 top-k로 제외되지 않음
+	infers, err := m.classifyMulti([]float32{0.2, 0.3, 0.25, 0.25}, 2, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infers) != 2 || infers[0].Label != "flower" || infers[1].Label != "rose" {
+		t.Fatalf("Unexpected remapped infers: %v", infers)
+	}
+	if math.Abs(float64(infers[0].Prob-0.45)) > 1e-6 || infers[0].Index != 2 || infers[0].DisplayName != "" {
+		t.Fatalf("Unexpected merged infer: %v", infers[0])
+	}
+
+	// multilabel 모델은 합친 클래스 중 가장 높은 확률
+	m.cfg.Classification = multiLabelClass
+	infers, err = m.classifyMultiLabel([]float32{0.6, 0.1, 0.7, 0.2}, 0, 0.5, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infers) != 1 || infers[0].Label != "flower" || infers[0].Prob != 0.7 || infers[0].Index != 2 {
+		t.Fatalf("Unexpected remapped multilabel infers: %v", infers)
+	}
+
+	// 이름을 바꾼 클래스는 지역화 하지 않음
+	m.localizedLabels = map[string][]string{"ko": {"데이지", "장미", "튤립", "해바라기"}}
+	ctx := ContextWithLanguages(context.Background(), "ko")
+	infers = m.localize(ctx, []InferLabel{{Label: "flower", Index: 2}, {Label: "sunflowers", Index: 3}})
+	if infers[0].Label != "flower" || infers[1].Label != "해바라기" {
+		t.Fatalf("Unexpected localized infers: %v", infers)
+	}
+
+	if violations := m.cfg.validateLabels([]labelInfo{{Name: "daisy"}, {Name: "roses"}}); len(violations) != 1 {
+		t.Fatalf("Unknown label in labelMap should fail: %v", violations)
+	}
+}
+
+func TestBinaryLabelManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "labels-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	manifest := `{"labels": [
+		{"name": "cat"},
+		{"name": "dog", "minProbability": 0.9}
+	]}`
+	labelsFile := filepath.Join(dir, "labels.json")
+	if err := ioutil.WriteFile(labelsFile, []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	labels, err := loadLabels(labelsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &iModel{
+		nrLables:   len(labels),
+		labels:     labelNames(labels),
+		labelInfos: labels,
+	}
+
+	// dog로 판단했지만 최소 확률 미만이므로 cat으로 바뀌지 않고 빈 결과
+	infers, err := m.classifyBinary(0.8, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infers) != 0 {
+		t.Fatalf("Rejected decision should be empty: %v", infers)
+	}
+
+	infers, err = m.classifyBinary(0.95, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infers) != 2 || infers[0].Label != "dog" {
+		t.Fatalf("Unexpected infers: %v", infers)
+	}
+
+	// cat으로 판단한 경우 dog는 최소 확률 미만으로 제외
+	infers, err = m.classifyBinary(0.3, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infers) != 1 || infers[0].Label != "cat" {
+		t.Fatalf("Unexpected infers: %v", infers)
+	}
+}
+
+func TestInvalidLabelManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "labels-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, manifest := range []string{
+		`{"labels": []}`,
+		`{}`,
+		`{"labels": [{"name": "cat"}, {"name": ""}]}`,
+		`{"labels": [{"name": "cat"}, {"name": "cat"}]}`,
+	} {
+		labelsFile := filepath.Join(dir, "labels.json")
+		if err := ioutil.WriteFile(labelsFile, []byte(manifest), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := loadLabels(labelsFile); err == nil {
+			t.Fatalf("Invalid manifest should fail: %s", manifest)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package inference
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// 모델 디렉토리 구조
+//
+// v1: 모델 디렉토리에 SavedModel, config.yaml, labels 파일이 바로 위치
+//
+// v2: 버전별 하위 디렉토리에 v1 구조의 모델을 저장
+//
+//	<model>-<uuid>/
+//	  manifest.yaml      구조 버전, 모델 이름, 사용 중인 버전
+//	  versions/
+//	    1/
+//	      metadata.json  모델 생성 정보
+//	      config.yaml
+//	      ...
+const (
+	layoutVersion = 2
+
+	manifestFile = "manifest.yaml"
+	metadataFile = "metadata.json"
+	configFile   = "config.yaml"
+	versionsDir  = "versions"
+
+	// v1에서 v2로 변환 중인 모델 디렉토리의 접미사
+	migratingSuffix = ".migrating"
+)
+
+const (
+	sourceLearner  = "learner"
+	sourceMigrated = "migrated"
+	sourceImported = "imported"
+	sourceCloned   = "cloned"
+)
+
+// modelManifest v2 모델 디렉토리 정보
+type modelManifest struct {
+	Layout  int    `yaml:"layout"`
+	Name    string `yaml:"name"`
+	Current int    `yaml:"current"`
+}
+
+// modelMetadata 모델 버전의 생성 정보
+type modelMetadata struct {
+	Name        string    `json:"name"`
+	Version     int       `json:"version"`
+	Source      string    `json:"source"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Subject     string    `json:"subject,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Epochs      int       `json:"epochs,omitempty"`
+	Trial       bool      `json:"trial,omitempty"`
+}
+
+func versionPath(modelPath string, version int) string {
+	return filepath.Join(modelPath, versionsDir, strconv.Itoa(version))
+}
+
+// readManifest manifest 파일이 없는 v1 디렉토리는 nil을 반환
+func readManifest(modelPath string) (*modelManifest, error) {
+	b, err := ioutil.ReadFile(filepath.Join(modelPath, manifestFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var manifest modelManifest
+	if err := yaml.Unmarshal(b, &manifest); err != nil {
+		return nil, err
+	}
+
+	if manifest.Layout != layoutVersion {
+		return nil, fmt.Errorf("Unknown model layout(%d): %s", manifest.Layout, modelPath)
+	}
+
+	return &manifest, nil
+}
+
+func writeManifest(modelPath string, manifest modelManifest) error {
+	b, err := yaml.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(filepath.Join(modelPath, manifestFile), b)
+}
+
+func writeMetadata(versionPath string, metadata modelMetadata) error {
+	b, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(filepath.Join(versionPath, metadataFile), b)
+}
+
+func readMetadata(versionPath string) (*modelMetadata, error) {
+	b, err := ioutil.ReadFile(filepath.Join(versionPath, metadataFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata modelMetadata
+	if err := json.Unmarshal(b, &metadata); err != nil {
+		return nil, err
+	}
+
+	return &metadata, nil
+}
+
+// writeFileAtomic 임시 파일에 쓴 후 rename하여 중간에 실패해도 이전 파일이 유지되도록 함
+func writeFileAtomic(file string, b []byte) error {
+	tmpFile := file + ".tmp"
+	if err := ioutil.WriteFile(tmpFile, b, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, file)
+}
+
+// resolveVersion 모델 디렉토리에서 사용할 버전의 경로 반환
+//
+// v1 디렉토리는 모델 디렉토리와 버전 0을 반환
+func resolveVersion(modelPath string) (string, int, error) {
+	manifest, err := readManifest(modelPath)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if manifest == nil {
+		return modelPath, 0, nil
+	}
+
+	return versionPath(modelPath, manifest.Current), manifest.Current, nil
+}
+
+func isFile(file string) bool {
+	info, err := os.Stat(file)
+	return err == nil && !info.IsDir()
+}
+
+// migrateModels 모델 저장 경로의 v1 모델 디렉토리를 v2로 변환
+//
+// 변환에 실패한 디렉토리는 그대로 두며 v1 구조로 로드 됨
+func (i *Inference) migrateModels() {
+	dirs, _ := ioutil.ReadDir(i.modelsPath)
+
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+
+		var (
+			modelPath = filepath.Join(i.modelsPath, dir.Name())
+			err       error
+		)
+
+		if strings.HasSuffix(dir.Name(), migratingSuffix) {
+			// 이전 변환이 디렉토리 이동 중에 중단 된 경우
+			err = finishMigration(strings.TrimSuffix(modelPath, migratingSuffix), modelPath)
+		} else if isFile(filepath.Join(modelPath, manifestFile)) {
+			continue
+		} else if isFile(filepath.Join(versionPath(modelPath, 1), configFile)) {
+			// manifest 작성 전에 중단 된 경우
+			err = finishMigration(modelPath, "")
+		} else if isFile(filepath.Join(modelPath, configFile)) {
+			err = migrateModel(modelPath)
+		} else {
+			continue
+		}
+
+		if err != nil {
+			log.Printf("Fail to migrate model(%s): %s", modelPath, err)
+		} else {
+			log.Printf("Migrate model(%s) to layout v%d", modelPath, layoutVersion)
+		}
+	}
+}
+
+func migrateModel(modelPath string) error {
+	// 디렉토리 이동은 rename으로만 수행하여 중간에 실패해도 파일이 유실되지 않도록 함
+	tmpPath := modelPath + migratingSuffix
+	if err := os.Rename(modelPath, tmpPath); err != nil {
+		return err
+	}
+
+	return finishMigration(modelPath, tmpPath)
+}
+
+// finishMigration tmpPath가 있으면 v1 모델을 versions/1로 옮긴 후 metadata와 manifest 작성
+func finishMigration(modelPath, tmpPath string) error {
+	vPath := versionPath(modelPath, 1)
+
+	if tmpPath != "" {
+		if err := os.MkdirAll(filepath.Dir(vPath), os.ModePerm); err != nil {
+			return err
+		}
+		if err := os.Rename(tmpPath, vPath); err != nil {
+			return err
+		}
+	}
+
+	cfgBytes, err := ioutil.ReadFile(filepath.Join(vPath, configFile))
+	if err != nil {
+		return err
+	}
+
+	var cfg modelConfig
+	if err := yaml.Unmarshal(cfgBytes, &cfg); err != nil {
+		return err
+	}
+
+	if !isFile(filepath.Join(vPath, metadataFile)) {
+		createdAt := time.Now()
+		if info, err := os.Stat(filepath.Join(vPath, configFile)); err == nil {
+			createdAt = info.ModTime()
+		}
+
+		if err := writeMetadata(vPath, modelMetadata{
+			Name:        cfg.Name,
+			Version:     1,
+			Source:      sourceMigrated,
+			CreatedAt:   createdAt,
+			Description: cfg.Description,
+			Epochs:      cfg.TrainingResult.Epochs,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// manifest는 변환의 완료를 나타내므로 마지막에 작성
+	return writeManifest(modelPath, modelManifest{
+		Layout:  layoutVersion,
+		Name:    cfg.Name,
+		Current: 1,
+	})
+}
@@ -0,0 +1,196 @@
+package inference
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newModelsPath(t *testing.T) string {
+	modelsPath, err := ioutil.TempDir("", "clsapp-models-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return modelsPath
+}
+
+// writeV1Model v1 구조의 모델 파일 생성
+func writeV1Model(t *testing.T, modelPath, model string) {
+	if err := os.MkdirAll(filepath.Join(modelPath, "variables"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		configFile:                  "name: " + model + "\nlabelsFile: lables\ntrainingResult:\n  epochs: 3\n",
+		"lables":                    "cat\ndog\n",
+		"saved_model.pb":            "pb",
+		"variables/variables.index": "index",
+	}
+	for file, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(modelPath, file), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// checkV2Model modelPath가 변환이 완료 된 v2 구조인지 확인
+func checkV2Model(t *testing.T, modelPath, model string) {
+	manifest, err := readManifest(modelPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest == nil || manifest.Name != model || manifest.Current != 1 {
+		t.Fatalf("Unexpected manifest: %+v", manifest)
+	}
+
+	vPath, version, err := resolveVersion(modelPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vPath != versionPath(modelPath, 1) || version != 1 {
+		t.Fatalf("Unexpected version: %s, %d", vPath, version)
+	}
+
+	for _, file := range []string{configFile, "lables", "saved_model.pb", "variables/variables.index"} {
+		if !isFile(filepath.Join(vPath, file)) {
+			t.Fatalf("%s is not migrated", file)
+		}
+	}
+
+	metadata, err := readMetadata(vPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Name != model || metadata.Version != 1 || metadata.Source != sourceMigrated || metadata.Epochs != 3 {
+		t.Fatalf("Unexpected metadata: %+v", metadata)
+	}
+}
+
+func TestMigrateModels(t *testing.T) {
+	modelsPath := newModelsPath(t)
+	defer os.RemoveAll(modelsPath)
+
+	modelPath := filepath.Join(modelsPath, "pets-1234")
+	writeV1Model(t, modelPath, "pets")
+
+	i := &Inference{modelsPath: modelsPath}
+	i.migrateModels()
+
+	checkV2Model(t, modelPath, "pets")
+
+	// 이미 변환 된 모델은 그대로 유지
+	i.migrateModels()
+
+	checkV2Model(t, modelPath, "pets")
+}
+
+func TestMigrateModelsResume(t *testing.T) {
+	modelsPath := newModelsPath(t)
+	defer os.RemoveAll(modelsPath)
+
+	// 디렉토리 이동 중에 중단 된 경우
+	renamedPath := filepath.Join(modelsPath, "pets-1234")
+	writeV1Model(t, renamedPath+migratingSuffix, "pets")
+
+	// versions/1로 이동 후 manifest 작성 전에 중단 된 경우
+	movedPath := filepath.Join(modelsPath, "flowers-5678")
+	writeV1Model(t, versionPath(movedPath, 1), "flowers")
+
+	i := &Inference{modelsPath: modelsPath}
+	i.migrateModels()
+
+	checkV2Model(t, renamedPath, "pets")
+	checkV2Model(t, movedPath, "flowers")
+
+	if _, err := os.Stat(renamedPath + migratingSuffix); !os.IsNotExist(err) {
+		t.Fatalf("Migrating directory remains: %v", err)
+	}
+}
+
+func TestLoadModelsSkipMigrating(t *testing.T) {
+	modelsPath := newModelsPath(t)
+	defer os.RemoveAll(modelsPath)
+
+	// 이동할 버전 디렉토리가 이미 있어 변환을 마칠 수 없는 경우
+	modelPath := filepath.Join(modelsPath, "pets-1234")
+	writeV1Model(t, modelPath+migratingSuffix, "pets")
+	if err := ioutil.WriteFile(filepath.Join(modelPath+migratingSuffix, "keep"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(versionPath(modelPath, 1), "variables"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	i := &Inference{
+		models:     make(map[string]*iModel),
+		modelsPath: modelsPath,
+	}
+	if err := i.loadModels(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 변환 중인 디렉토리는 v1 모델로 로드하지 않으며 파일을 유지
+	if len(i.models) != 0 {
+		t.Fatalf("Unexpected models: %v", i.models)
+	}
+	if !isFile(filepath.Join(modelPath+migratingSuffix, configFile)) {
+		t.Fatal("Migrating directory should remain")
+	}
+}
+
+func TestResolveVersion(t *testing.T) {
+	modelsPath := newModelsPath(t)
+	defer os.RemoveAll(modelsPath)
+
+	// v1 구조는 모델 디렉토리를 그대로 사용
+	v1Path := filepath.Join(modelsPath, "pets-1234")
+	writeV1Model(t, v1Path, "pets")
+
+	vPath, version, err := resolveVersion(v1Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vPath != v1Path || version != 0 {
+		t.Fatalf("Unexpected v1 version: %s, %d", vPath, version)
+	}
+
+	// v2 구조는 manifest의 사용 중인 버전을 사용
+	v2Path := filepath.Join(modelsPath, "flowers-5678")
+	writeV1Model(t, versionPath(v2Path, 2), "flowers")
+	if err := writeManifest(v2Path, modelManifest{Layout: layoutVersion, Name: "flowers", Current: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	vPath, version, err = resolveVersion(v2Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vPath != versionPath(v2Path, 2) || version != 2 {
+		t.Fatalf("Unexpected v2 version: %s, %d", vPath, version)
+	}
+}
+
+func TestUnknownLayout(t *testing.T) {
+	modelsPath := newModelsPath(t)
+	defer os.RemoveAll(modelsPath)
+
+	modelPath := filepath.Join(modelsPath, "pets-1234")
+	writeV1Model(t, modelPath, "pets")
+	if err := writeManifest(modelPath, modelManifest{Layout: layoutVersion + 1, Name: "pets", Current: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := resolveVersion(modelPath); err == nil {
+		t.Fatal("Unknown layout should fail")
+	}
+
+	// 알 수 없는 구조는 변환하지 않음
+	i := &Inference{modelsPath: modelsPath}
+	i.migrateModels()
+
+	if !isFile(filepath.Join(modelPath, configFile)) {
+		t.Fatal("Unknown layout should not be migrated")
+	}
+}
@@ -0,0 +1,120 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+type languagesKey struct{}
+
+// ContextWithLanguages 추론 결과의 label을 바꿀 언어를 선호 순서로 지정한 ctx 반환
+//
+// 언어는 Accept-Language와 같은 언어 태그(예: ko-KR, en)이며, 기본 언어(ko)로 비교
+func ContextWithLanguages(ctx context.Context, languages ...string) context.Context {
+	return context.WithValue(ctx, languagesKey{}, languages)
+}
+
+// contextLanguages ctx에 지정된 언어들의 기본 언어 (소문자)
+func contextLanguages(ctx context.Context) []string {
+	languages, _ := ctx.Value(languagesKey{}).([]string)
+
+	primaries := make([]string, 0, len(languages))
+	for _, language := range languages {
+		primary := primaryLanguage(language)
+		if primary != "" {
+			primaries = append(primaries, primary)
+		}
+	}
+
+	return primaries
+}
+
+func primaryLanguage(language string) string {
+	return strings.ToLower(strings.TrimSpace(strings.SplitN(language, "-", 2)[0]))
+}
+
+// validateLocalizedLabels localizedLabelsFiles의 언어와 파일 검사
+func (cfg *modelConfig) validateLocalizedLabels(modelPath string) []string {
+	var violations []string
+	for _, language := range sortedKeys(cfg.LocalizedLabelsFiles) {
+		file := cfg.LocalizedLabelsFiles[language]
+		if language == "" || primaryLanguage(language) != language {
+			violations = append(violations, fmt.Sprintf("`localizedLabelsFiles` language must be a lowercase primary language tag (e.g. ko, en): %q", language))
+		}
+
+		labelsFile := filepath.Join(modelPath, file)
+		if !withinPath(modelPath, labelsFile) {
+			violations = append(violations, fmt.Sprintf("`localizedLabelsFiles` must be in the model directory: %s", file))
+		} else if !isFile(labelsFile) {
+			violations = append(violations, fmt.Sprintf("`localizedLabelsFiles` does not exist: %s", file))
+		}
+	}
+
+	return violations
+}
+
+// loadLocalizedLabels 언어별 labels 파일 로드 (labels 파일과 같은 순서, 같은 수의 클래스)
+func loadLocalizedLabels(modelPath string, cfg modelConfig, nrLabels int) (map[string][]string, error) {
+	if len(cfg.LocalizedLabelsFiles) == 0 {
+		return nil, nil
+	}
+
+	localized := make(map[string][]string, len(cfg.LocalizedLabelsFiles))
+	for language, file := range cfg.LocalizedLabelsFiles {
+		labels, err := loadLabels(filepath.Join(modelPath, file))
+		if err != nil {
+			return nil, err
+		}
+		if len(labels) != nrLabels {
+			return nil, &ConfigError{
+				File:       filepath.Join(modelPath, file),
+				Violations: []string{fmt.Sprintf("The number of %s labels(%d) is different from labels(%d)", language, len(labels), nrLabels)},
+			}
+		}
+		localized[language] = labelNames(labels)
+	}
+
+	return localized, nil
+}
+
+// localLabels ctx의 언어 중 지역화 된 labels가 있는 첫번째 언어의 labels (없으면 nil)
+func (m *iModel) localLabels(ctx context.Context) []string {
+	for _, language := range contextLanguages(ctx) {
+		if labels, ok := m.localizedLabels[language]; ok {
+			return labels
+		}
+	}
+
+	return nil
+}
+
+// localize 클래스별 결과의 label을 ctx의 언어로 바꿈
+//
+// 상위 카테고리는 지역화 된 클래스 이름의 같은 깊이의 카테고리로 바꾸며, unknown 결과와 labelMap으로 바꾼 이름은 바꾸지 않음
+func (m *iModel) localize(ctx context.Context, infers []InferLabel) []InferLabel {
+	labels := m.localLabels(ctx)
+	if labels == nil {
+		return infers
+	}
+
+	for idx := range infers {
+		infer := &infers[idx]
+		// labelMap으로 이름을 바꾼 클래스는 지역화 된 이름이 없음
+		if infer.Index < 0 || infer.Index >= len(labels) || infer.Label != m.labels[infer.Index] {
+			continue
+		}
+		infer.Label = labels[infer.Index]
+
+		ancestors := labelAncestors(infer.Label)
+		if len(ancestors) != len(infer.Parents) {
+			continue
+		}
+		for pidx := range infer.Parents {
+			infer.Parents[pidx].Label = ancestors[pidx]
+		}
+	}
+
+	return infers
+}
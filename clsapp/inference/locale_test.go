@@ -0,0 +1,64 @@
+package inference
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "labels-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "labels.ko.txt"), []byte("개/리트리버/골든\n고양이\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := modelConfig{LocalizedLabelsFiles: map[string]string{"ko": "labels.ko.txt"}}
+	localized, err := loadLocalizedLabels(dir, cfg, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadLocalizedLabels(dir, cfg, 3); err == nil {
+		t.Fatal("Different number of labels should fail")
+	}
+
+	m := &iModel{
+		labels:          []string{"dog/retriever/golden", "cat"},
+		localizedLabels: localized,
+	}
+	newInfers := func() []InferLabel {
+		return []InferLabel{
+			{Prob: 0.6, Label: "dog/retriever/golden", Index: 0, Parents: []InferLabel{
+				{Prob: 0.7, Label: "dog", Index: -1},
+				{Prob: 0.6, Label: "dog/retriever", Index: -1},
+			}},
+			{Prob: 0.3, Label: "cat", Index: 1},
+			{Prob: 0.1, Label: "unknown", Index: -1, Unknown: true},
+		}
+	}
+
+	// 지원하지 않는 언어는 건너뛰고, 기본 언어로 비교
+	ctx := ContextWithLanguages(context.Background(), "fr", "ko-KR", "en")
+	infers := m.localize(ctx, newInfers())
+	if infers[0].Label != "개/리트리버/골든" || infers[1].Label != "고양이" || infers[2].Label != "unknown" {
+		t.Fatalf("Unexpected localized infers: %v", infers)
+	}
+	if infers[0].Parents[0].Label != "개" || infers[0].Parents[1].Label != "개/리트리버" {
+		t.Fatalf("Unexpected localized parents: %v", infers[0].Parents)
+	}
+
+	for _, ctx := range []context.Context{
+		context.Background(),
+		ContextWithLanguages(context.Background(), "en"),
+	} {
+		if infers := m.localize(ctx, newInfers()); infers[0].Label != "dog/retriever/golden" {
+			t.Fatalf("Labels should not be localized: %v", infers)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package inference
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// SavedModel, frozen graph와 TensorFlow Lite 모델 중 로드시 메모리에 올라가는 파일
+var modelFiles = []string{"saved_model.pb", "saved_model.pbtxt", "variables", frozenGraphFile, tfliteModelFile}
+
+// modelMemory 모델 버전 디렉토리의 graph와 variables 크기로 구한 로드시 예상 메모리 (byte)
+//
+// session이 실행 중에 사용하는 메모리는 포함하지 않으므로 실제 사용량보다 작음
+func modelMemory(vPath string) int64 {
+	var size int64
+	for _, file := range modelFiles {
+		if n, err := dirSize(filepath.Join(vPath, file)); err == nil {
+			size += n
+		}
+	}
+
+	return size
+}
+
+// memoryBudget 로드 된 모델의 예상 메모리 합계와 제한
+//
+// 모델을 로드하기 전에 예상 메모리를 예약하고 모델을 해제하면 반환
+type memoryBudget struct {
+	// byte (0 이하면 제한 없음)
+	limit int64
+	used  int64
+}
+
+// reserve size만큼 예약하며, 제한을 넘으면 예약하지 않고 ErrMemoryBudgetExceeded 반환
+//
+// b가 nil이면 제한 없음
+func (b *memoryBudget) reserve(size int64) error {
+	if b == nil {
+		return nil
+	}
+
+	for {
+		used := atomic.LoadInt64(&b.used)
+		if b.limit > 0 && used+size > b.limit {
+			return fmt.Errorf("%w: %d MB required, %d/%d MB used",
+				ErrMemoryBudgetExceeded, mb(size), mb(used), mb(b.limit))
+		}
+		if atomic.CompareAndSwapInt64(&b.used, used, used+size) {
+			return nil
+		}
+	}
+}
+
+// free 예약한 size 반환
+func (b *memoryBudget) free(size int64) {
+	if b == nil {
+		return
+	}
+
+	atomic.AddInt64(&b.used, -size)
+}
+
+func mb(size int64) int64 {
+	return (size + 1<<20 - 1) >> 20
+}
@@ -0,0 +1,86 @@
+package inference
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMemoryBudget(t *testing.T) {
+	b := &memoryBudget{limit: 10}
+	if err := b.reserve(6); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.reserve(6); !errors.Is(err, ErrMemoryBudgetExceeded) {
+		t.Fatalf("Reservation over budget should fail: %v", err)
+	}
+	b.free(6)
+	if err := b.reserve(10); err != nil {
+		t.Fatal(err)
+	}
+	if b.used != 10 {
+		t.Fatalf("Unexpected used memory: %d", b.used)
+	}
+
+	// 제한 없음
+	if err := (&memoryBudget{}).reserve(1 << 40); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadOverMemoryBudget(t *testing.T) {
+	modelsPath := newModelsPath(t)
+	defer os.RemoveAll(modelsPath)
+
+	modelPath := filepath.Join(modelsPath, "pets")
+	writeV1Model(t, modelPath, "pets")
+
+	// saved_model.pb와 variables 파일 크기
+	if memory := modelMemory(modelPath); memory != int64(len("pb")+len("index")) {
+		t.Fatalf("Unexpected model memory: %d", memory)
+	}
+
+	i := &Inference{memory: &memoryBudget{limit: 5}}
+	m := getNewModel("pets", modelPath)
+	if err := i.load(m); !errors.Is(err, ErrMemoryBudgetExceeded) {
+		t.Fatalf("Load over budget should fail: %v", err)
+	}
+	if i.memory.used != 0 || m.budget != nil {
+		t.Fatalf("Memory should not be reserved: %d", i.memory.used)
+	}
+
+	// 로드에 실패하면 예약한 메모리를 반환
+	i.memory.limit = 0
+	if err := i.load(m); err == nil {
+		t.Fatal("Invalid model should not be loaded")
+	}
+	if i.memory.used != 0 {
+		t.Fatalf("Memory should be freed: %d", i.memory.used)
+	}
+}
+
+func TestLoadModelsOverMemoryBudget(t *testing.T) {
+	modelsPath := newModelsPath(t)
+	defer os.RemoveAll(modelsPath)
+
+	modelPath := filepath.Join(modelsPath, "pets")
+	writeV1Model(t, modelPath, "pets")
+
+	// 시작시 제한을 넘는 모델은 로드하지 않지만 삭제하지도 않음
+	i := &Inference{
+		modelsPath: modelsPath,
+		models:     make(map[string]*iModel),
+		versions:   make(map[string]map[int]*iModel),
+		memory:     &memoryBudget{limit: 5},
+	}
+	if err := i.loadModels(); err != nil {
+		t.Fatal(err)
+	}
+	if len(i.models) != 0 {
+		t.Fatalf("Model over budget should not be loaded: %v", i.models)
+	}
+	if _, err := os.Stat(modelPath); err != nil {
+		t.Fatalf("Model over budget should not be removed: %v", err)
+	}
+}
@@ -0,0 +1,499 @@
+// Package mock 테스트를 위한 inference.Inferencer mock 구현
+package mock
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/inference"
+)
+
+// ErrNotImplemented 동작이 지정되지 않은 메소드 호출
+var ErrNotImplemented = errors.New("Not implemented in mock")
+
+// Inference 함수 필드로 동작을 지정하는 Inferencer mock
+//
+// 지정되지 않은 메소드는 빈 값 또는 ErrNotImplemented를 반환
+type Inference struct {
+	CreateModelFunc        func(ctx context.Context, newModel, subject, desc string, epochs int, trial bool) (*inference.CreateResult, error)
+	CreateVersionFunc      func(ctx context.Context, model, subject, desc string, epochs int, trial bool) (*inference.CreateResult, error)
+	ImportModelFunc        func(ctx context.Context, model string, archive io.Reader) (*inference.CreateResult, error)
+	ExportModelFunc        func(ctx context.Context, model string, w io.Writer) error
+	CloneModelFunc         func(ctx context.Context, src, dst string) (*inference.CreateResult, error)
+	OperateModelFunc       func(ctx context.Context, model, modelPath string) error
+	DeleteModelFunc        func(ctx context.Context, model string) error
+	ForceDeleteModelFunc   func(ctx context.Context, model string, timeout time.Duration) error
+	ReloadModelFunc        func(ctx context.Context, model string) error
+	UpdateModelFunc        func(ctx context.Context, model string, update inference.ModelUpdate) error
+	DisableModelFunc       func(ctx context.Context, model string) error
+	EnableModelFunc        func(ctx context.Context, model string) error
+	RollbackModelFunc      func(ctx context.Context, model string) (int, error)
+	GetModelsFunc          func(ctx context.Context, filter inference.ModelFilter) []string
+	GetModelInfosFunc      func(ctx context.Context, filter inference.ModelFilter, page, size int) *inference.ModelInfoPage
+	GetModelFunc           func(ctx context.Context, model string, verbose bool) (*inference.ModelInfo, error)
+	GetModelGraphFunc      func(ctx context.Context, model string, verbose bool) (*inference.GraphSummary, error)
+	InferFunc              func(ctx context.Context, model string, image []byte, format string, k int, threshold float32) ([]inference.InferLabel, error)
+	InferRawFunc           func(ctx context.Context, model string, image []byte, format string, k int, threshold float32) (*inference.RawInference, error)
+	RunFunc                func(ctx context.Context, req inference.InferRequest) (*inference.RawInference, error)
+	InferBatchFunc         func(ctx context.Context, model string, images [][]byte, format string, k int, threshold float32) ([][]inference.InferLabel, error)
+	InferTensorFunc        func(ctx context.Context, model string, data []float32, shape []int, k int, threshold float32) ([]inference.InferLabel, error)
+	CompareFunc            func(ctx context.Context, models []string, image []byte, format string) (*inference.Comparison, error)
+	InferAllFunc           func(ctx context.Context, tags []string, image []byte, format string, k int, threshold float32) ([]inference.ModelResult, error)
+	InferPipelineFunc      func(ctx context.Context, pipeline string, image []byte, format string, k int, threshold float32) (*inference.PipelineResult, error)
+	CacheStatsFunc         func() inference.CacheStats
+	InferVideoFunc         func(ctx context.Context, model string, video []byte, fps float64, k int, threshold float32) (*inference.VideoResult, error)
+	DetectFunc             func(ctx context.Context, model string, image []byte, format string, k int, threshold float32) ([]inference.DetectionResult, error)
+	SegmentFunc            func(ctx context.Context, model string, image []byte, format string, threshold float32, encoding string) (*inference.SegmentationResult, error)
+	ExplainFunc            func(ctx context.Context, model string, image []byte, format, label string) (*inference.Explanation, error)
+	IndexImageFunc         func(ctx context.Context, model, id string, image []byte, format string) error
+	RemoveIndexedImageFunc func(ctx context.Context, model, id string) error
+	ClearIndexFunc         func(ctx context.Context, model string) error
+	SearchFunc             func(ctx context.Context, model string, image []byte, format string, n int) ([]inference.SearchResult, error)
+	CreateTenantFunc       func(ctx context.Context, tenant string, quota int64) error
+	DeleteTenantFunc       func(ctx context.Context, tenant string) error
+	GetTenantsFunc         func(ctx context.Context) []string
+	GetTenantFunc          func(ctx context.Context, tenant string) (map[string]interface{}, error)
+	SetAliasFunc           func(ctx context.Context, alias, target string) (string, error)
+	DeleteAliasFunc        func(ctx context.Context, alias string) error
+	GetAliasesFunc         func(ctx context.Context) map[string]string
+
+	mutex sync.Mutex
+	calls []string
+}
+
+var _ inference.Inferencer = (*Inference)(nil)
+
+func (i *Inference) called(method string) {
+	i.mutex.Lock()
+	i.calls = append(i.calls, method)
+	i.mutex.Unlock()
+}
+
+// Calls 호출 된 메소드 이름 목록 반환
+func (i *Inference) Calls() []string {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	calls := make([]string, len(i.calls))
+	copy(calls, i.calls)
+
+	return calls
+}
+
+// CreateModel 추론모델 생성
+func (i *Inference) CreateModel(ctx context.Context, newModel, subject, desc string, epochs int, trial bool) (*inference.CreateResult, error) {
+	i.called("CreateModel")
+	if i.CreateModelFunc == nil {
+		return nil, ErrNotImplemented
+	}
+
+	return i.CreateModelFunc(ctx, newModel, subject, desc, epochs, trial)
+}
+
+// CreateVersion 사용 중인 버전으로 계속 추론하면서 모델의 새 버전을 학습
+func (i *Inference) CreateVersion(ctx context.Context, model, subject, desc string, epochs int, trial bool) (*inference.CreateResult, error) {
+	i.called("CreateVersion")
+	if i.CreateVersionFunc == nil {
+		return nil, ErrNotImplemented
+	}
+
+	return i.CreateVersionFunc(ctx, model, subject, desc, epochs, trial)
+}
+
+// ImportModel learner 없이 외부에서 학습한 모델의 tar.gz 파일을 등록
+func (i *Inference) ImportModel(ctx context.Context, model string, archive io.Reader) (*inference.CreateResult, error) {
+	i.called("ImportModel")
+	if i.ImportModelFunc == nil {
+		return nil, ErrNotImplemented
+	}
+
+	return i.ImportModelFunc(ctx, model, archive)
+}
+
+// ExportModel 모델의 SavedModel, config, labels와 생성 정보를 tar.gz로 w에 씀
+func (i *Inference) ExportModel(ctx context.Context, model string, w io.Writer) error {
+	i.called("ExportModel")
+	if i.ExportModelFunc == nil {
+		return ErrNotImplemented
+	}
+
+	return i.ExportModelFunc(ctx, model, w)
+}
+
+// CloneModel src 모델의 파일을 복사해 dst 모델로 등록
+func (i *Inference) CloneModel(ctx context.Context, src, dst string) (*inference.CreateResult, error) {
+	i.called("CloneModel")
+	if i.CloneModelFunc == nil {
+		return nil, ErrNotImplemented
+	}
+
+	return i.CloneModelFunc(ctx, src, dst)
+}
+
+// OperateModel 생성 된 추론모델 로드
+func (i *Inference) OperateModel(ctx context.Context, model, modelPath string) error {
+	i.called("OperateModel")
+	if i.OperateModelFunc == nil {
+		return ErrNotImplemented
+	}
+
+	return i.OperateModelFunc(ctx, model, modelPath)
+}
+
+// DeleteModel 모델 삭제
+func (i *Inference) DeleteModel(ctx context.Context, model string) error {
+	i.called("DeleteModel")
+	if i.DeleteModelFunc == nil {
+		return ErrNotImplemented
+	}
+
+	return i.DeleteModelFunc(ctx, model)
+}
+
+// ForceDeleteModel 진행 중인 추론이 끝나기를 기다려 모델 삭제
+func (i *Inference) ForceDeleteModel(ctx context.Context, model string, timeout time.Duration) error {
+	i.called("ForceDeleteModel")
+	if i.ForceDeleteModelFunc == nil {
+		return ErrNotImplemented
+	}
+
+	return i.ForceDeleteModelFunc(ctx, model, timeout)
+}
+
+// ReloadModel 모델의 config, labels와 SavedModel을 다시 로드
+func (i *Inference) ReloadModel(ctx context.Context, model string) error {
+	i.called("ReloadModel")
+	if i.ReloadModelFunc == nil {
+		return ErrNotImplemented
+	}
+
+	return i.ReloadModelFunc(ctx, model)
+}
+
+// UpdateModel 모델을 다시 로드하지 않고 설명, modelTags와 기본 threshold 변경
+func (i *Inference) UpdateModel(ctx context.Context, model string, update inference.ModelUpdate) error {
+	i.called("UpdateModel")
+	if i.UpdateModelFunc == nil {
+		return ErrNotImplemented
+	}
+
+	return i.UpdateModelFunc(ctx, model, update)
+}
+
+// DisableModel 모델을 로드 된 상태로 두고 추론을 중지
+func (i *Inference) DisableModel(ctx context.Context, model string) error {
+	i.called("DisableModel")
+	if i.DisableModelFunc == nil {
+		return ErrNotImplemented
+	}
+
+	return i.DisableModelFunc(ctx, model)
+}
+
+// EnableModel 추론을 중지한 모델의 추론을 다시 시작
+func (i *Inference) EnableModel(ctx context.Context, model string) error {
+	i.called("EnableModel")
+	if i.EnableModelFunc == nil {
+		return ErrNotImplemented
+	}
+
+	return i.EnableModelFunc(ctx, model)
+}
+
+// RollbackModel 모델의 사용 중인 버전을 이전 버전으로 되돌리고 되돌린 버전을 반환
+func (i *Inference) RollbackModel(ctx context.Context, model string) (int, error) {
+	i.called("RollbackModel")
+	if i.RollbackModelFunc == nil {
+		return 0, ErrNotImplemented
+	}
+
+	return i.RollbackModelFunc(ctx, model)
+}
+
+// GetModels 조건을 만족하는 이미지 추론 모델 목록 반환
+func (i *Inference) GetModels(ctx context.Context, filter inference.ModelFilter) []string {
+	i.called("GetModels")
+	if i.GetModelsFunc == nil {
+		return nil
+	}
+
+	return i.GetModelsFunc(ctx, filter)
+}
+
+// GetModelInfos 조건을 만족하는 모델 목록의 한 page에 해당하는 모델 정보 반환
+func (i *Inference) GetModelInfos(ctx context.Context, filter inference.ModelFilter, page, size int) *inference.ModelInfoPage {
+	i.called("GetModelInfos")
+	if i.GetModelInfosFunc == nil {
+		return nil
+	}
+
+	return i.GetModelInfosFunc(ctx, filter, page, size)
+}
+
+// GetModel 이미지 추론 모델 정보 반환
+func (i *Inference) GetModel(ctx context.Context, model string, verbose bool) (*inference.ModelInfo, error) {
+	i.called("GetModel")
+	if i.GetModelFunc == nil {
+		return nil, ErrNotImplemented
+	}
+
+	return i.GetModelFunc(ctx, model, verbose)
+}
+
+// GetModelGraph 로드 된 모델의 graph 요약 반환
+func (i *Inference) GetModelGraph(ctx context.Context, model string, verbose bool) (*inference.GraphSummary, error) {
+	i.called("GetModelGraph")
+	if i.GetModelGraphFunc == nil {
+		return nil, ErrNotImplemented
+	}
+
+	return i.GetModelGraphFunc(ctx, model, verbose)
+}
+
+// Infer 추론
+func (i *Inference) Infer(ctx context.Context, model string, image []byte, format string, k int, threshold float32) ([]inference.InferLabel, error) {
+	i.called("Infer")
+	if i.InferFunc == nil {
+		return nil, ErrNotImplemented
+	}
+
+	return i.InferFunc(ctx, model, image, format, k, threshold)
+}
+
+// InferBatch 여러 이미지를 하나의 모델로 추론
+func (i *Inference) InferBatch(ctx context.Context, model string, images [][]byte, format string, k int, threshold float32) ([][]inference.InferLabel, error) {
+	i.called("InferBatch")
+	if i.InferBatchFunc == nil {
+		return nil, ErrNotImplemented
+	}
+
+	return i.InferBatchFunc(ctx, model, images, format, k, threshold)
+}
+
+// InferTensor 이미지 디코딩과 전처리 없이, 전처리 된 입력으로 추론
+func (i *Inference) InferTensor(ctx context.Context, model string, data []float32, shape []int, k int, threshold float32) ([]inference.InferLabel, error) {
+	i.called("InferTensor")
+	if i.InferTensorFunc == nil {
+		return nil, ErrNotImplemented
+	}
+
+	return i.InferTensorFunc(ctx, model, data, shape, k, threshold)
+}
+
+// Compare 하나의 이미지를 여러 모델로 추론하여 라벨별로 비교
+func (i *Inference) Compare(ctx context.Context, models []string, image []byte, format string) (*inference.Comparison, error) {
+	i.called("Compare")
+	if i.CompareFunc == nil {
+		return nil, ErrNotImplemented
+	}
+
+	return i.CompareFunc(ctx, models, image, format)
+}
+
+// InferRaw 추론 결과와 활성화 함수를 적용하기 전의 모델 출력
+func (i *Inference) InferRaw(ctx context.Context, model string, image []byte, format string, k int, threshold float32) (*inference.RawInference, error) {
+	i.called("InferRaw")
+	if i.InferRawFunc == nil {
+		return nil, ErrNotImplemented
+	}
+
+	return i.InferRawFunc(ctx, model, image, format, k, threshold)
+}
+
+// Run InferRequest로 추론
+func (i *Inference) Run(ctx context.Context, req inference.InferRequest) (*inference.RawInference, error) {
+	i.called("Run")
+	if i.RunFunc == nil {
+		return nil, ErrNotImplemented
+	}
+
+	return i.RunFunc(ctx, req)
+}
+
+// InferAll 하나의 이미지를 로드 된 모든 모델(tags로 선택)로 추론
+func (i *Inference) InferAll(ctx context.Context, tags []string, image []byte, format string, k int, threshold float32) ([]inference.ModelResult, error) {
+	i.called("InferAll")
+	if i.InferAllFunc == nil {
+		return nil, ErrNotImplemented
+	}
+
+	return i.InferAllFunc(ctx, tags, image, format, k, threshold)
+}
+
+// InferPipeline pipeline의 gate 모델을 통과한 이미지만 다음 단계의 모델로 추론
+func (i *Inference) InferPipeline(ctx context.Context, pipeline string, image []byte, format string, k int, threshold float32) (*inference.PipelineResult, error) {
+	i.called("InferPipeline")
+	if i.InferPipelineFunc == nil {
+		return nil, ErrNotImplemented
+	}
+
+	return i.InferPipelineFunc(ctx, pipeline, image, format, k, threshold)
+}
+
+// CacheStats 추론 결과 캐시 사용 현황
+func (i *Inference) CacheStats() inference.CacheStats {
+	i.called("CacheStats")
+	if i.CacheStatsFunc == nil {
+		return inference.CacheStats{}
+	}
+
+	return i.CacheStatsFunc()
+}
+
+// InferVideo 동영상에서 추출한 frame별 추론 결과와 다수결, 평균 반환
+func (i *Inference) InferVideo(ctx context.Context, model string, video []byte, fps float64, k int, threshold float32) (*inference.VideoResult, error) {
+	i.called("InferVideo")
+	if i.InferVideoFunc == nil {
+		return nil, ErrNotImplemented
+	}
+
+	return i.InferVideoFunc(ctx, model, video, fps, k, threshold)
+}
+
+// Detect detection 모델로 이미지의 대상과 위치를 검출
+func (i *Inference) Detect(ctx context.Context, model string, image []byte, format string, k int, threshold float32) ([]inference.DetectionResult, error) {
+	i.called("Detect")
+	if i.DetectFunc == nil {
+		return nil, ErrNotImplemented
+	}
+
+	return i.DetectFunc(ctx, model, image, format, k, threshold)
+}
+
+// Segment segmentation 모델로 이미지의 픽셀별 클래스를 mask로 반환
+func (i *Inference) Segment(ctx context.Context, model string, image []byte, format string, threshold float32, encoding string) (*inference.SegmentationResult, error) {
+	i.called("Segment")
+	if i.SegmentFunc == nil {
+		return nil, ErrNotImplemented
+	}
+
+	return i.SegmentFunc(ctx, model, image, format, threshold, encoding)
+}
+
+// Explain 추론 결과의 클래스에 영향을 준 이미지 영역을 Grad-CAM heatmap으로 반환
+func (i *Inference) Explain(ctx context.Context, model string, image []byte, format, label string) (*inference.Explanation, error) {
+	i.called("Explain")
+	if i.ExplainFunc == nil {
+		return nil, ErrNotImplemented
+	}
+
+	return i.ExplainFunc(ctx, model, image, format, label)
+}
+
+// IndexImage 이미지의 embedding을 id로 모델의 검색 색인에 추가
+func (i *Inference) IndexImage(ctx context.Context, model, id string, image []byte, format string) error {
+	i.called("IndexImage")
+	if i.IndexImageFunc == nil {
+		return ErrNotImplemented
+	}
+
+	return i.IndexImageFunc(ctx, model, id, image, format)
+}
+
+// RemoveIndexedImage id를 모델의 검색 색인에서 제거
+func (i *Inference) RemoveIndexedImage(ctx context.Context, model, id string) error {
+	i.called("RemoveIndexedImage")
+	if i.RemoveIndexedImageFunc == nil {
+		return ErrNotImplemented
+	}
+
+	return i.RemoveIndexedImageFunc(ctx, model, id)
+}
+
+// ClearIndex 모델의 검색 색인을 비움
+func (i *Inference) ClearIndex(ctx context.Context, model string) error {
+	i.called("ClearIndex")
+	if i.ClearIndexFunc == nil {
+		return ErrNotImplemented
+	}
+
+	return i.ClearIndexFunc(ctx, model)
+}
+
+// Search 모델의 검색 색인에서 이미지와 embedding이 가까운 순서로 n개의 이미지 반환
+func (i *Inference) Search(ctx context.Context, model string, image []byte, format string, n int) ([]inference.SearchResult, error) {
+	i.called("Search")
+	if i.SearchFunc == nil {
+		return nil, ErrNotImplemented
+	}
+
+	return i.SearchFunc(ctx, model, image, format, n)
+}
+
+// CreateTenant 사용자 모델 저장 공간 생성
+func (i *Inference) CreateTenant(ctx context.Context, tenant string, quota int64) error {
+	i.called("CreateTenant")
+	if i.CreateTenantFunc == nil {
+		return ErrNotImplemented
+	}
+
+	return i.CreateTenantFunc(ctx, tenant, quota)
+}
+
+// DeleteTenant 사용자와 사용자의 모든 모델 삭제
+func (i *Inference) DeleteTenant(ctx context.Context, tenant string) error {
+	i.called("DeleteTenant")
+	if i.DeleteTenantFunc == nil {
+		return ErrNotImplemented
+	}
+
+	return i.DeleteTenantFunc(ctx, tenant)
+}
+
+// GetTenants 사용자 목록 반환
+func (i *Inference) GetTenants(ctx context.Context) []string {
+	i.called("GetTenants")
+	if i.GetTenantsFunc == nil {
+		return nil
+	}
+
+	return i.GetTenantsFunc(ctx)
+}
+
+// GetTenant 사용자의 quota, 사용량과 모델 목록 반환
+func (i *Inference) GetTenant(ctx context.Context, tenant string) (map[string]interface{}, error) {
+	i.called("GetTenant")
+	if i.GetTenantFunc == nil {
+		return nil, ErrNotImplemented
+	}
+
+	return i.GetTenantFunc(ctx, tenant)
+}
+
+// SetAlias 별칭이 target 모델을 가리키도록 지정하고 이전 대상을 반환
+func (i *Inference) SetAlias(ctx context.Context, alias, target string) (string, error) {
+	i.called("SetAlias")
+	if i.SetAliasFunc == nil {
+		return "", ErrNotImplemented
+	}
+
+	return i.SetAliasFunc(ctx, alias, target)
+}
+
+// DeleteAlias 별칭 삭제
+func (i *Inference) DeleteAlias(ctx context.Context, alias string) error {
+	i.called("DeleteAlias")
+	if i.DeleteAliasFunc == nil {
+		return ErrNotImplemented
+	}
+
+	return i.DeleteAliasFunc(ctx, alias)
+}
+
+// GetAliases 별칭과 대상 모델 반환
+func (i *Inference) GetAliases(ctx context.Context) map[string]string {
+	i.called("GetAliases")
+	if i.GetAliasesFunc == nil {
+		return nil
+	}
+
+	return i.GetAliasesFunc(ctx)
+}
+
+// Destroy 추론 모델 해제
+func (i *Inference) Destroy(ctx context.Context) {
+	i.called("Destroy")
+}
@@ -0,0 +1,154 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/constants"
+)
+
+// 모델 목록 정렬 기준 (`-`를 붙이면 역순)
+const (
+	// SortByName 모델 이름 순서 (기본값)
+	SortByName = "name"
+	// SortByStatus 상태(ready, build, run) 순서, 같은 상태는 이름 순서
+	SortByStatus = "status"
+	// SortByUpdated 상태가 바뀐(로드 또는 생성 시작) 시각이 오래된 순서
+	SortByUpdated = "updated"
+)
+
+// ModelFilter 모델 목록에서 선택할 모델과 정렬 기준
+//
+// 빈 값인 조건은 사용하지 않으며, 모든 조건을 만족하는 모델만 선택
+type ModelFilter struct {
+	// modelTags에 모두 있는 모델
+	Tags []string
+	// config의 classification (binary, multi, multilabel, detection, segmentation)
+	Classification string
+	// ready, build, run
+	Status string
+	// SortByName, SortByStatus 또는 SortByUpdated (`-`를 붙이면 역순)
+	Sort string
+}
+
+// Validate 지원하지 않는 정렬 기준이면 에러 반환
+func (f ModelFilter) Validate() error {
+	switch strings.TrimPrefix(f.Sort, "-") {
+	case "", SortByName, SortByStatus, SortByUpdated:
+		return nil
+	default:
+		return fmt.Errorf("Unknown sort order: %s", f.Sort)
+	}
+}
+
+// match m이 조건을 모두 만족하는지 확인
+//
+// 로드 되지 않은 모델은 config가 없으므로 tags나 classification 조건을 만족하지 않음
+func (f ModelFilter) match(m *iModel) bool {
+	status := atomic.LoadInt32(&m.status)
+	if f.Status != "" && statusName(status) != f.Status {
+		return false
+	}
+	if len(f.Tags) == 0 && f.Classification == "" {
+		return true
+	}
+	if status != modelStatusRun {
+		return false
+	}
+
+	return m.cfg.hasModelTags(f.Tags) && (f.Classification == "" || m.cfg.Classification == f.Classification)
+}
+
+// listedModel 모델 목록의 이름과 정렬에 사용하는 값
+type listedModel struct {
+	name    string
+	status  int32
+	updated time.Time
+}
+
+// sortModels f의 정렬 기준으로 models를 정렬
+func (f ModelFilter) sortModels(models []listedModel) {
+	less := func(a, b listedModel) bool {
+		return a.name < b.name
+	}
+	switch strings.TrimPrefix(f.Sort, "-") {
+	case SortByStatus:
+		less = func(a, b listedModel) bool {
+			if a.status != b.status {
+				return a.status < b.status
+			}
+			return a.name < b.name
+		}
+	case SortByUpdated:
+		less = func(a, b listedModel) bool {
+			if !a.updated.Equal(b.updated) {
+				return a.updated.Before(b.updated)
+			}
+			return a.name < b.name
+		}
+	}
+
+	reverse := strings.HasPrefix(f.Sort, "-")
+	sort.SliceStable(models, func(a, b int) bool {
+		if reverse {
+			return less(models[b], models[a])
+		}
+		return less(models[a], models[b])
+	})
+}
+
+// GetModelInfos filter의 조건을 만족하는 모델 목록에서 page(1부터 시작) 번째의 모델 정보를 최대 size개 반환
+//
+// 목록을 만든 후 삭제 된 모델은 결과에서 제외
+func (i *Inference) GetModelInfos(ctx context.Context, filter ModelFilter, page, size int) *ModelInfoPage {
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = constants.DefaultPageSize
+	}
+
+	models := i.GetModels(ctx, filter)
+
+	result := &ModelInfoPage{
+		Page:   page,
+		Size:   size,
+		Total:  len(models),
+		Models: []ModelInfo{},
+	}
+
+	start := (page - 1) * size
+	if start >= len(models) {
+		return result
+	}
+	end := start + size
+	if end > len(models) {
+		end = len(models)
+	}
+
+	for _, model := range models[start:end] {
+		if info, err := i.GetModel(ctx, model, false); err == nil {
+			result.Models = append(result.Models, *info)
+		}
+	}
+
+	return result
+}
+
+// statusName 모델 상태의 이름 (ready, build, run 또는 unknown)
+func statusName(status int32) string {
+	switch status {
+	case modelStatusReady:
+		return "ready"
+	case modelStatusBuild:
+		return "build"
+	case modelStatusRun:
+		return "run"
+	default:
+		return "unknown"
+	}
+}
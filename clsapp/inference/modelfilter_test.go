@@ -0,0 +1,59 @@
+package inference
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetModelsFilter(t *testing.T) {
+	now := time.Now()
+	i := &Inference{
+		models: map[string]*iModel{
+			"pets": {
+				name: "pets", status: modelStatusRun, statusUpdateTime: now,
+				cfg: modelConfig{Classification: multiClass, ModelTags: []string{"animal", "v2"}},
+			},
+			"cats": {
+				name: "cats", status: modelStatusRun, statusUpdateTime: now.Add(-time.Hour),
+				cfg: modelConfig{Classification: binaryClass, ModelTags: []string{"animal"}},
+			},
+			"flowers": {
+				name: "flowers", status: modelStatusBuild, statusUpdateTime: now.Add(-time.Minute),
+			},
+		},
+	}
+	i.putVersion(&iModel{
+		name: "pets", version: 1, status: modelStatusRun, statusUpdateTime: now.Add(-2 * time.Hour),
+		cfg: modelConfig{Classification: multiClass, ModelTags: []string{"animal"}},
+	})
+
+	tests := []struct {
+		filter ModelFilter
+		models []string
+	}{
+		{ModelFilter{}, []string{"cats", "flowers", "pets", "pets/1"}},
+		{ModelFilter{Sort: "-name"}, []string{"pets/1", "pets", "flowers", "cats"}},
+		{ModelFilter{Tags: []string{"animal"}}, []string{"cats", "pets", "pets/1"}},
+		{ModelFilter{Tags: []string{"animal", "v2"}}, []string{"pets"}},
+		{ModelFilter{Classification: multiClass}, []string{"pets", "pets/1"}},
+		{ModelFilter{Status: "build"}, []string{"flowers"}},
+		{ModelFilter{Status: "run", Sort: SortByUpdated}, []string{"pets/1", "cats", "pets"}},
+		{ModelFilter{Sort: SortByStatus}, []string{"flowers", "cats", "pets", "pets/1"}},
+		{ModelFilter{Tags: []string{"none"}}, nil},
+	}
+
+	for _, tt := range tests {
+		if models := i.GetModels(context.Background(), tt.filter); !reflect.DeepEqual(models, tt.models) {
+			t.Errorf("GetModels(%+v) = %v, expected %v", tt.filter, models, tt.models)
+		}
+	}
+
+	if err := (ModelFilter{Sort: "-updated"}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := (ModelFilter{Sort: "size"}).Validate(); err == nil {
+		t.Fatal("Unknown sort order should fail")
+	}
+}
@@ -0,0 +1,96 @@
+package inference
+
+// ModelInfo 이미지 추론 모델 정보
+//
+// json key는 HTTP API의 모델 정보 응답과 같음 (numberOfLables, lables는 기존 응답과의 호환을 위한 이름)
+type ModelInfo struct {
+	Model   string `json:"model"`
+	Version int    `json:"version"`
+	// 로드 된 모든 버전 (`<model>/<version>`으로 추론)
+	Versions []int `json:"versions,omitempty"`
+	RefCount int32 `json:"refCount"`
+	// ready, build, run 또는 unknown
+	Status string `json:"status"`
+	// 추론을 중지한 모델
+	Disabled bool `json:"disabled,omitempty"`
+	// 사용자 모델의 사용자 (공용 모델은 빈 값)
+	Tenant string `json:"tenant,omitempty"`
+
+	InputShape      []int32   `json:"inputShape"`
+	Channels        int       `json:"channels"`
+	InputDType      string    `json:"inputDtype"`
+	InputLayout     string    `json:"inputLayout"`
+	Format          string    `json:"format"`
+	Mean            []float32 `json:"mean"`
+	Std             []float32 `json:"std"`
+	ResizeMode      string    `json:"resizeMode"`
+	ResizeMethod    string    `json:"resizeMethod"`
+	Alpha           string    `json:"alpha"`
+	AlphaBackground []float32 `json:"alphaBackground"`
+	TTA             []string  `json:"tta"`
+	MultiCrop       int       `json:"multiCrop"`
+	Preprocessor    string    `json:"preprocessor"`
+	ModelTags       []string  `json:"modelTags"`
+
+	Type           string `json:"type"`
+	Classification string `json:"classification"`
+	InputOperator  string `json:"inputOperator"`
+	OutputOperator string `json:"outputOperator"`
+	// 이름을 붙인 graph 출력
+	Outputs     map[string]string `json:"outputs,omitempty"`
+	Description string            `json:"description"`
+
+	Threshold        float32           `json:"threshold"`
+	DefaultTopK      int               `json:"defaultTopK"`
+	MinProbability   float32           `json:"minProbability"`
+	UnknownThreshold float32           `json:"unknownThreshold"`
+	UnknownLabel     string            `json:"unknownLabel"`
+	LabelHierarchy   bool              `json:"labelHierarchy"`
+	LabelMap         map[string]string `json:"labelMap"`
+	ExcludeLabels    []string          `json:"excludeLabels,omitempty"`
+	OutputActivation string            `json:"outputActivation"`
+	Temperature      float32           `json:"temperature"`
+
+	NumberOfLabels int `json:"numberOfLables"`
+	// verbose가 아니면 처음 10개의 클래스와 "..."
+	Labels []string `json:"lables"`
+	// labels가 자동 생성 된 경우의 출처
+	LabelsSource *LabelsSource `json:"labelsSource,omitempty"`
+	// 지역화 된 labels의 언어
+	LabelLanguages []string `json:"labelLanguages,omitempty"`
+
+	// detection 모델의 출력 operation과 class offset
+	DetectionOperators []string `json:"detectionOperators,omitempty"`
+	ClassOffset        *int     `json:"classOffset,omitempty"`
+	// 유사 이미지 검색 모델의 embedding operation과 색인 크기
+	EmbeddingOperator string `json:"embeddingOperator,omitempty"`
+	IndexSize         *int   `json:"indexSize,omitempty"`
+	// Grad-CAM operation
+	CAMOperators []string `json:"camOperators,omitempty"`
+
+	// 모델 파일 크기 (byte)
+	Storage int64 `json:"storage,omitempty"`
+	// 로드 된 모델의 예상 메모리 (byte, SavedModel graph와 variables 크기)
+	Memory int64 `json:"memory,omitempty"`
+	// 학습 결과 (verbose인 경우)
+	TrainingResult *TrainingResult `json:"trainingResult,omitempty"`
+}
+
+// CreateResult 모델 생성 요청 결과 (learner 응답)
+type CreateResult struct {
+	Model string `json:"model"`
+	// base, practical 또는 trial
+	Type string `json:"type"`
+	// 학습 중인 버전
+	Version int `json:"version"`
+}
+
+// ModelInfoPage 모델 목록의 한 page에 해당하는 모델 정보
+type ModelInfoPage struct {
+	// 1부터 시작하는 page 번호와 page 크기
+	Page int `json:"page"`
+	Size int `json:"size"`
+	// 조건을 만족하는 전체 모델 수
+	Total  int         `json:"total"`
+	Models []ModelInfo `json:"models"`
+}
@@ -0,0 +1,81 @@
+package inference
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestGetModelInfo(t *testing.T) {
+	m := &iModel{
+		name:       "flowers",
+		version:    2,
+		status:     modelStatusRun,
+		modelPath:  "/nonexistent/flowers",
+		labels:     []string{"daisy", "roses"},
+		nrLables:   2,
+		inputShape: []int32{224, 224},
+		cfg: modelConfig{
+			Classification: detectionClass,
+			TrainingResult: TrainingResult{Epochs: 3},
+		},
+	}
+	i := &Inference{
+		models: map[string]*iModel{"flowers": m},
+	}
+
+	info, err := i.GetModel(context.Background(), "flowers", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Status != "run" || info.NumberOfLabels != 2 || info.ClassOffset == nil || info.TrainingResult.Epochs != 3 {
+		t.Fatalf("Unexpected info: %+v", info)
+	}
+
+	// HTTP API 응답의 key는 map으로 반환하던 때와 같음
+	b, err := json.Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res map[string]interface{}
+	if err := json.Unmarshal(b, &res); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"numberOfLables", "lables", "inputDtype", "classOffset", "detectionOperators", "trainingResult"} {
+		if _, ok := res[key]; !ok {
+			t.Fatalf("Missing key %s: %s", key, b)
+		}
+	}
+	for _, key := range []string{"tenant", "storage", "indexSize", "labelsSource"} {
+		if _, ok := res[key]; ok {
+			t.Fatalf("Unexpected key %s: %s", key, b)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	// backend 없는 모델이라 캐시 된 결과로 요청의 언어가 ctx에 지정 되었는지 확인
+	m := &iModel{name: "flowers", version: 1, loadID: 1, status: modelStatusRun}
+	i := &Inference{
+		models:      map[string]*iModel{"flowers": m},
+		resultCache: newResultCache(10, 0),
+	}
+
+	ctx := ContextWithLanguages(context.Background(), "ko")
+	i.resultCache.put(m.resultKey(ctx, []byte("roses"), "jpeg", 3, 0), &RawInference{Inference: []InferLabel{{Label: "장미"}}})
+
+	result, err := i.Run(context.Background(), InferRequest{
+		Model:     "flowers",
+		Image:     []byte("roses"),
+		Format:    "jpeg",
+		K:         3,
+		Languages: []string{"ko-KR"},
+	})
+	if err != nil || result.Inference[0].Label != "장미" {
+		t.Fatalf("Unexpected result: %v %v", result, err)
+	}
+
+	if _, err := i.Run(context.Background(), InferRequest{Model: "none"}); err == nil {
+		t.Fatal("Unknown model should fail")
+	}
+}
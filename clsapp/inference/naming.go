@@ -0,0 +1,132 @@
+package inference
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DirNamer 모델 이름으로 새 모델 디렉토리 이름을 생성
+//
+// attempt는 0부터 시작하며, 생성한 이름이 이미 사용 중이면 1씩 증가시켜 다시 호출.
+// 더 이상 만들 수 있는 이름이 없으면 빈 값을 반환
+type DirNamer func(model string, attempt int) string
+
+// 기본 제공 모델 디렉토리 이름 생성 방식
+const (
+	// <model>-<uuid 앞 8자리> (기본값)
+	DirNamingShortUUID = "shortuuid"
+	// <model>-<uuid>
+	DirNamingUUID = "uuid"
+	// <model>-<생성 시각(YYYYMMDDhhmmss)>
+	DirNamingTimestamp = "timestamp"
+	// <model>-v<major>.0.0, 사용 중이지 않은 가장 작은 major
+	DirNamingSemver = "semver"
+	// <model>
+	DirNamingPlain = "plain"
+)
+
+// 이름이 충돌할 때 다시 생성하는 최대 횟수
+const maxDirNamingAttempts = 1000
+
+var (
+	dirNamers = map[string]DirNamer{
+		DirNamingShortUUID: func(model string, attempt int) string {
+			return fmt.Sprintf("%s-%s", model, uuid.New().String()[:8])
+		},
+		DirNamingUUID: func(model string, attempt int) string {
+			return fmt.Sprintf("%s-%s", model, uuid.New().String())
+		},
+		DirNamingTimestamp: func(model string, attempt int) string {
+			name := fmt.Sprintf("%s-%s", model, time.Now().Format("20060102150405"))
+			if attempt > 0 {
+				name = fmt.Sprintf("%s-%d", name, attempt)
+			}
+			return name
+		},
+		DirNamingSemver: func(model string, attempt int) string {
+			return fmt.Sprintf("%s-v%d.0.0", model, attempt+1)
+		},
+		DirNamingPlain: func(model string, attempt int) string {
+			if attempt > 0 {
+				return ""
+			}
+			return model
+		},
+	}
+	dirNamersMutex sync.RWMutex
+)
+
+// RegisterDirNamer name으로 모델 디렉토리 이름 생성 방식 등록
+//
+// 기본 제공 방식과 같은 이름으로 등록하면 기본 제공 방식을 대체
+func RegisterDirNamer(name string, namer DirNamer) {
+	dirNamersMutex.Lock()
+	defer dirNamersMutex.Unlock()
+
+	dirNamers[name] = namer
+}
+
+func lookupDirNamer(name string) (DirNamer, error) {
+	if name == "" {
+		name = DirNamingShortUUID
+	}
+
+	dirNamersMutex.RLock()
+	defer dirNamersMutex.RUnlock()
+
+	namer, ok := dirNamers[name]
+	if !ok {
+		var names []string
+		for n := range dirNamers {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("Unknown model directory naming: %s %v", name, names)
+	}
+
+	return namer, nil
+}
+
+// newModelPath modelsPath 하위에 사용 중이지 않은 새 모델 디렉토리 경로 반환
+//
+// 디렉토리가 이미 있거나 등록 된 모델이 사용 중인 경로는 사용하지 않음.
+// i.rwMutex를 잡은 상태에서 호출
+func (i *Inference) newModelPath(modelsPath, model string) (string, error) {
+	namer := i.dirNamer
+	if namer == nil {
+		var err error
+		if namer, err = lookupDirNamer(""); err != nil {
+			return "", err
+		}
+	}
+
+	for attempt := 0; attempt < maxDirNamingAttempts; attempt++ {
+		dir := namer(model, attempt)
+		if dir == "" {
+			break
+		}
+
+		// 등록 된 방식이 잘못된 이름을 만들어도 모델 저장 경로를 벗어나지 않도록 함
+		if err := ValidateName(dir); err != nil {
+			return "", fmt.Errorf("Invalid model directory %q: %w", dir, err)
+		}
+
+		modelPath := filepath.Join(modelsPath, dir)
+		if _, err := os.Stat(modelPath); !os.IsNotExist(err) {
+			continue
+		}
+		if i.ownedPath(modelPath) {
+			continue
+		}
+
+		return modelPath, nil
+	}
+
+	return "", fmt.Errorf("%w directory: %s", ErrDuplicateModel, model)
+}
@@ -0,0 +1,80 @@
+package inference
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestDirNamers(t *testing.T) {
+	tests := []struct {
+		naming  string
+		pattern string
+	}{
+		{DirNamingShortUUID, `^pets-[0-9a-f]{8}$`},
+		{DirNamingUUID, `^pets-[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`},
+		{DirNamingTimestamp, `^pets-[0-9]{14}$`},
+		{DirNamingSemver, `^pets-v1\.0\.0$`},
+		{DirNamingPlain, `^pets$`},
+	}
+
+	for _, tt := range tests {
+		namer, err := lookupDirNamer(tt.naming)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dir := namer("pets", 0); !regexp.MustCompile(tt.pattern).MatchString(dir) {
+			t.Errorf("%s: unexpected directory %q", tt.naming, dir)
+		}
+	}
+
+	if _, err := lookupDirNamer("bogus"); err == nil {
+		t.Fatal("Unknown naming should fail")
+	}
+}
+
+func TestNewModelPathCollision(t *testing.T) {
+	modelsPath := newModelsPath(t)
+	defer os.RemoveAll(modelsPath)
+
+	i := &Inference{
+		models:     make(map[string]*iModel),
+		modelsPath: modelsPath,
+	}
+
+	// 디렉토리가 있거나 등록 된 모델이 사용 중인 버전은 건너뜀
+	i.dirNamer, _ = lookupDirNamer(DirNamingSemver)
+	if err := os.MkdirAll(filepath.Join(modelsPath, "pets-v1.0.0"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.addModel(getNewModel("pets", filepath.Join(modelsPath, "pets-v2.0.0"))); err != nil {
+		t.Fatal(err)
+	}
+
+	modelPath, err := i.newModelPath(modelsPath, "pets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modelPath != filepath.Join(modelsPath, "pets-v3.0.0") {
+		t.Fatalf("Unexpected model path: %s", modelPath)
+	}
+
+	// plain 방식은 다른 이름을 만들 수 없으므로 충돌하면 실패
+	i.dirNamer, _ = lookupDirNamer(DirNamingPlain)
+	if err := os.MkdirAll(filepath.Join(modelsPath, "flowers"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i.newModelPath(modelsPath, "flowers"); !errors.Is(err, ErrDuplicateModel) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// 모델 저장 경로를 벗어나는 이름은 사용하지 않음
+	i.dirNamer = func(model string, attempt int) string {
+		return "../" + model
+	}
+	if _, err := i.newModelPath(modelsPath, "flowers"); !errors.Is(err, ErrInvalidName) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
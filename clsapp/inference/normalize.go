@@ -0,0 +1,172 @@
+package inference
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// 기본 정규화 값: [0, 255]의 이미지값을 [-1, 1]로 조정 (MobileNet 등)
+const (
+	defaultNormalizeMean = 127.5
+	defaultNormalizeStd  = 127.5
+)
+
+// 모델 입력 tensor의 타입
+const (
+	// 정규화 된 float32 (기본값)
+	inputFloat32 = "float32"
+	// 정규화 하지 않은 [0, 255]의 uint8 (quantized, TF-Hub 모델 등)
+	inputUint8 = "uint8"
+)
+
+// 모델 입력 tensor의 layout (inputShape은 layout과 관계없이 [height, width, channels])
+const (
+	// [batch, height, width, channels] (기본값, TensorFlow)
+	layoutNHWC = "NHWC"
+	// [batch, channels, height, width] (PyTorch에서 변환 된 모델)
+	layoutNCHW = "NCHW"
+)
+
+// channelValues 모든 채널에 같은 값(scalar) 또는 채널별 값(list)
+//
+//	mean: 127.5
+//	mean: [123.675, 116.28, 103.53]
+type channelValues []float32
+
+// UnmarshalYAML scalar와 list를 모두 허용
+func (v *channelValues) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var scalar float32
+	if err := unmarshal(&scalar); err == nil {
+		*v = channelValues{scalar}
+		return nil
+	}
+
+	var values []float32
+	if err := unmarshal(&values); err != nil {
+		// TypeError는 config 검사의 위반 사항으로 모아서 반환 됨
+		return &yaml.TypeError{Errors: []string{fmt.Sprintf("must be a number or a list of numbers: %s", err)}}
+	}
+	*v = values
+
+	return nil
+}
+
+// normalization 이미지값 정규화: (image - mean) / std
+type normalization struct {
+	mean channelValues
+	std  channelValues
+}
+
+// normalization config의 mean, std (지정하지 않으면 기본값)
+func (cfg *modelConfig) normalization() normalization {
+	n := normalization{mean: cfg.Mean, std: cfg.Std}
+	if len(n.mean) == 0 {
+		n.mean = channelValues{defaultNormalizeMean}
+	}
+	if len(n.std) == 0 {
+		n.std = channelValues{defaultNormalizeStd}
+	}
+
+	return n
+}
+
+// inputDType 모델 입력 tensor의 타입
+func (cfg *modelConfig) inputDType() string {
+	if cfg.InputDType == "" {
+		return inputFloat32
+	}
+
+	return cfg.InputDType
+}
+
+// inputLayout 모델 입력 tensor의 layout
+func (cfg *modelConfig) inputLayout() string {
+	if cfg.InputLayout == "" {
+		return layoutNHWC
+	}
+
+	return cfg.InputLayout
+}
+
+// normalizationName 전처리 graph 저장 파일에 사용하는 정규화 방식 이름
+//
+// uint8 입력은 정규화 하지 않으므로 inputUint8
+func (cfg *modelConfig) normalizationName() string {
+	if cfg.inputDType() == inputUint8 {
+		return inputUint8
+	}
+
+	return cfg.normalization().name()
+}
+
+// validateNormalization 입력 타입과 mean, std의 값 수와 std 값 검사
+func (cfg *modelConfig) validateNormalization() []string {
+	var violations []string
+
+	switch cfg.inputDType() {
+	case inputFloat32:
+	case inputUint8:
+		if len(cfg.Mean) > 0 || len(cfg.Std) > 0 {
+			violations = append(violations, "`mean` and `std` must not be set for uint8 input")
+		}
+	default:
+		violations = append(violations, fmt.Sprintf("`inputDtype` must be %s or %s: %q", inputFloat32, inputUint8, cfg.InputDType))
+	}
+
+	if l := cfg.inputLayout(); l != layoutNHWC && l != layoutNCHW {
+		violations = append(violations, fmt.Sprintf("`inputLayout` must be %s or %s: %q", layoutNHWC, layoutNCHW, cfg.InputLayout))
+	}
+
+	for _, f := range []struct {
+		field  string
+		values channelValues
+	}{
+		{"mean", cfg.Mean},
+		{"std", cfg.Std},
+	} {
+		if len(f.values) > 1 && len(f.values) != cfg.channels() {
+			violations = append(violations, fmt.Sprintf("`%s` must have 1 or %d(channels) values: %v", f.field, cfg.channels(), f.values))
+		}
+	}
+
+	for _, s := range cfg.Std {
+		if s <= 0 {
+			violations = append(violations, fmt.Sprintf("`std` must be positive: %v", cfg.Std))
+			break
+		}
+	}
+
+	return violations
+}
+
+// name 전처리 graph 저장 파일에 사용하는 정규화 방식 이름
+//
+// 기본값은 normalizeSymmetric, 그 외에는 값으로 구성 (예: mean123.675_116.28_103.53-std58.395_57.12_57.375)
+func (n normalization) name() string {
+	if len(n.mean) == 1 && n.mean[0] == defaultNormalizeMean && len(n.std) == 1 && n.std[0] == defaultNormalizeStd {
+		return normalizeSymmetric
+	}
+
+	return "mean" + n.mean.join() + "-std" + n.std.join()
+}
+
+// value c 번째 채널의 값 (모든 채널에 같은 값이면 그 값)
+func (v channelValues) value(c int) float32 {
+	if len(v) == 1 {
+		return v[0]
+	}
+
+	return v[c]
+}
+
+func (v channelValues) join() string {
+	s := make([]string, len(v))
+	for idx, f := range v {
+		s[idx] = strconv.FormatFloat(float64(f), 'g', -1, 32)
+	}
+
+	return strings.Join(s, "_")
+}
@@ -0,0 +1,50 @@
+package inference
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestChannelValues(t *testing.T) {
+	var cfg struct {
+		Mean channelValues `yaml:"mean"`
+		Std  channelValues `yaml:"std"`
+	}
+
+	if err := yaml.Unmarshal([]byte("mean: 127.5\nstd: [58.395, 57.12, 57.375]\n"), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Mean) != 1 || cfg.Mean[0] != 127.5 || len(cfg.Std) != 3 || cfg.Std[2] != 57.375 {
+		t.Fatalf("Unexpected values: %v, %v", cfg.Mean, cfg.Std)
+	}
+
+	if err := yaml.Unmarshal([]byte("mean: [a, b]\n"), &cfg); err == nil {
+		t.Fatal("Invalid values should fail")
+	}
+}
+
+func TestNormalizationName(t *testing.T) {
+	tests := []struct {
+		cfg  modelConfig
+		name string
+	}{
+		{modelConfig{}, normalizeSymmetric},
+		{modelConfig{Mean: channelValues{127.5}, Std: channelValues{127.5}}, normalizeSymmetric},
+		{modelConfig{Mean: channelValues{0}, Std: channelValues{255}}, "mean0-std255"},
+		{
+			modelConfig{Mean: channelValues{123.675, 116.28, 103.53}, Std: channelValues{58.395, 57.12, 57.375}},
+			"mean123.675_116.28_103.53-std58.395_57.12_57.375",
+		},
+	}
+
+	for _, test := range tests {
+		if name := test.cfg.normalizationName(); name != test.name {
+			t.Errorf("Unexpected name: %s, %s", name, test.name)
+		}
+	}
+
+	if name := (&modelConfig{InputDType: inputUint8}).normalizationName(); name != inputUint8 {
+		t.Errorf("Unexpected uint8 name: %s", name)
+	}
+}
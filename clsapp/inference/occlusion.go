@@ -0,0 +1,105 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	// 이미지를 가로와 세로로 나누는 수 (heatmap 크기)
+	occlusionGrid = 7
+	// 한번에 실행하는 가린 이미지 수
+	occlusionBatchSize = 16
+	// 가리는 색 (RGB 모두 같은 값의 회색)
+	occlusionGray = 128
+)
+
+// classProbs 모델 출력을 labels 순서의 클래스별 확률로 변환 (binary 모델은 두 클래스의 확률)
+func (m *iModel) classProbs(outputs [][]float32) ([]float32, error) {
+	probs := m.cfg.probabilities(outputs)
+	if m.cfg.Classification == binaryClass {
+		prob, err := positiveProb(m.cfg.OutputActivation, probs)
+		if err != nil {
+			return nil, err
+		}
+		probs = []float32{1 - prob, prob}
+	}
+	if len(probs) != m.nrLables {
+		return nil, fmt.Errorf("%w: the number of correct(%d) and predicted(%d) labels does not match", ErrInvalidConfig, m.nrLables, len(probs))
+	}
+
+	return probs, nil
+}
+
+// occludedPixels (row, col) 칸을 회색으로 가린 [h, w, 3] 픽셀
+func occludedPixels(pix []byte, h, w, row, col int) []byte {
+	occluded := make([]byte, len(pix))
+	copy(occluded, pix)
+
+	y0, y1 := areaRange(row, h, occlusionGrid)
+	x0, x1 := areaRange(col, w, occlusionGrid)
+	for y := y0; y < y1 && y < h; y++ {
+		for x := x0; x < x1 && x < w; x++ {
+			offset := (y*w + x) * 3
+			occluded[offset], occluded[offset+1], occluded[offset+2] = occlusionGray, occlusionGray, occlusionGray
+		}
+	}
+
+	return occluded
+}
+
+// occlusionMap 이미지를 occlusionGrid x occlusionGrid 칸으로 나눠 칸별로 가린 이미지를 batch로 실행하고,
+// class 확률이 원본의 확률(prob)보다 줄어든 정도를 0~1로 정규화 한 heatmap 반환
+//
+// 가린 이미지는 overlay와 같이 줄이고 바로 세운 원본 픽셀로 만들며, 모델의 resizeMode로 입력 크기에 맞춤
+func (m *iModel) occlusionMap(ctx context.Context, pix []byte, h, w, class int, prob float32) ([]float32, error) {
+	cells := occlusionGrid * occlusionGrid
+	heat := make([]float32, cells)
+
+	var maxHeat float32
+	for start := 0; start < cells; start += occlusionBatchSize {
+		end := start + occlusionBatchSize
+		if end > cells {
+			end = cells
+		}
+
+		inputs := make([]imageInput, 0, end-start)
+		for cell := start; cell < end; cell++ {
+			inputs = append(inputs, imageInput{
+				format:      preprocessRaw,
+				orientation: 1,
+				pix:         occludedPixels(pix, h, w, cell/occlusionGrid, cell%occlusionGrid),
+				height:      h,
+				width:       w,
+			})
+		}
+
+		outputs, err := m.backend.runImages(ctx, inputs)
+		if err != nil {
+			return nil, err
+		}
+
+		for idx, output := range outputs {
+			probs, err := m.classProbs(output)
+			if err != nil {
+				return nil, err
+			}
+
+			// 가렸을 때 확률이 오른 영역은 클래스에 중요하지 않은 영역
+			if drop := prob - probs[class]; drop > 0 {
+				heat[start+idx] = drop
+				if drop > maxHeat {
+					maxHeat = drop
+				}
+			}
+		}
+	}
+
+	if maxHeat > 0 {
+		for idx := range heat {
+			heat[idx] /= maxHeat
+		}
+	}
+
+	return heat, nil
+}
@@ -0,0 +1,275 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Option New에 전달하는 추론 엔진 설정
+type Option func(*Config)
+
+// WithConfig 설정 전체를 c로 지정
+//
+// 이후에 전달 된 Option은 c의 값을 바꿈
+func WithConfig(c Config) Option {
+	return func(cfg *Config) {
+		*cfg = c
+	}
+}
+
+// WithModelsPath 모델 저장 경로 (기본값: constants.ModelsPath)
+func WithModelsPath(p string) Option {
+	return func(cfg *Config) {
+		cfg.ModelsPath = p
+	}
+}
+
+// WithLearner 모델을 생성하는 learner의 주소 (host:port)
+func WithLearner(host string) Option {
+	return func(cfg *Config) {
+		cfg.LHost = host
+	}
+}
+
+// WithFallbackModel 기본 모델을 생성할 수 없을 때 사용하는 모델 경로
+func WithFallbackModel(p string) Option {
+	return func(cfg *Config) {
+		cfg.FallbackModelPath = p
+	}
+}
+
+// WithTenantQuota 사용자 생성시 quota를 지정하지 않은 경우의 quota (byte, 0 이하면 제한 없음)
+func WithTenantQuota(quota int64) Option {
+	return func(cfg *Config) {
+		cfg.TenantQuota = quota
+	}
+}
+
+// WithDirNaming 새 모델의 디렉토리 이름 생성 방식 (기본값: DirNamingShortUUID)
+func WithDirNaming(naming string) Option {
+	return func(cfg *Config) {
+		cfg.DirNaming = naming
+	}
+}
+
+// WithCache 전처리 graph 저장 경로 (빈 값이면 모델 저장 경로의 .graphs, "-"이면 저장하지 않음)
+func WithCache(p string) Option {
+	return func(cfg *Config) {
+		cfg.GraphCachePath = p
+	}
+}
+
+// WithAuthHook 모델과 사용자 요청의 권한 확인 hook
+func WithAuthHook(hook AuthHook) Option {
+	return func(cfg *Config) {
+		cfg.AuthHook = hook
+	}
+}
+
+// WithMetrics 추론과 모델 로드 결과를 전달 받는 Metrics
+func WithMetrics(m Metrics) Option {
+	return func(cfg *Config) {
+		cfg.Metrics = m
+	}
+}
+
+// WithImageLimits 추론할 이미지의 최대 bytes와 너비, 높이 (0 이하면 제한 없음)
+func WithImageLimits(maxBytes int64, maxWidth, maxHeight int) Option {
+	return func(cfg *Config) {
+		cfg.ImageLimits = ImageLimits{MaxBytes: maxBytes, MaxWidth: maxWidth, MaxHeight: maxHeight}
+	}
+}
+
+// WithDecodeProbe 추론 전에 Go 디코더로 이미지 전체를 디코딩하여 손상 된 이미지를 확인
+//
+// 이미지 header와 구조는 항상 확인하며, probe는 추론 시간이 늘어나는 대신 픽셀 데이터의 손상도 구체적인 에러로 반환
+func WithDecodeProbe(probe bool) Option {
+	return func(cfg *Config) {
+		cfg.ProbeDecode = probe
+	}
+}
+
+// WithHEICConverter HEIC/HEIF 이미지를 JPEG로 변환하여 추론 (예: &CommandConverter{Path: "magick", Args: []string{"heic:-", "jpeg:-"}})
+func WithHEICConverter(c HEICConverter) Option {
+	return func(cfg *Config) {
+		cfg.HEICConverter = c
+	}
+}
+
+// WithTFLiteRuntime config에 `format: tflite`를 지정한 모델을 r로 로드하여 Go에서 전처리한 입력으로 실행
+func WithTFLiteRuntime(r TFLiteRuntime) Option {
+	return func(cfg *Config) {
+		cfg.TFLiteRuntime = r
+	}
+}
+
+// WithPreDownscale 모델 입력 크기의 factor 배보다 큰 이미지는 Go에서 줄인 후 전처리 (0 이하면 사용 안함)
+//
+// 큰 사진(예: DSLR 원본)을 TensorFlow 디코더로 디코딩하고 크기를 조정할 때의 memory 사용을 줄임
+func WithPreDownscale(factor float64) Option {
+	return func(cfg *Config) {
+		cfg.PreDownscale = factor
+	}
+}
+
+// WithWarmup 모델을 로드한 후 추론 요청을 받기 전에 모델 입력 크기의 합성 이미지로 n번 실행 (0 이하면 사용 안함)
+//
+// 첫 요청이 디코더와 graph 초기화 지연을 겪지 않도록 하며, 그만큼 모델 로드 시간이 늘어남
+func WithWarmup(n int) Option {
+	return func(cfg *Config) {
+		cfg.WarmupRuns = n
+	}
+}
+
+// WithPipelines gate 모델을 통과한 이미지만 다음 모델로 추론하는 pipeline 설정 파일
+func WithPipelines(file string) Option {
+	return func(cfg *Config) {
+		cfg.PipelinesFile = file
+	}
+}
+
+// WithResultCache 같은 이미지(SHA-256)의 추론 결과를 최대 size개까지 ttl 동안 캐시 (size가 0 이하면 사용 안함, ttl이 0이면 만료되지 않음)
+//
+// 모델 이름, 버전, k, threshold, 형식과 요청 언어가 같으면 디코딩과 모델 실행 없이 캐시 된 결과를 반환
+func WithResultCache(size int, ttl time.Duration) Option {
+	return func(cfg *Config) {
+		cfg.ResultCacheSize = size
+		cfg.ResultCacheTTL = ttl
+	}
+}
+
+// WithFrameSampler 동영상의 frame을 추출하여 추론 (예: &CommandSampler{Path: "ffmpeg", Args: []string{"-i", "{input}", "-vf", "fps={fps}", "-f", "image2pipe", "-c:v", "mjpeg", "-"}})
+func WithFrameSampler(s FrameSampler) Option {
+	return func(cfg *Config) {
+		cfg.FrameSampler = s
+	}
+}
+
+// WithWatch 모델 저장 경로를 감시하여 마지막 변경 후 debounce 동안 변경이 없으면 모델을 다시 확인 (0 이하면 사용 안함)
+//
+// 추가 된 모델 디렉토리는 READY 파일이 있어야 로드하므로, 모델 파일을 모두 복사한 후 READY 파일을 만들어야 함.
+// 디렉토리가 삭제 된 모델은 사용이 끝난 후 해제
+func WithWatch(debounce time.Duration) Option {
+	return func(cfg *Config) {
+		cfg.WatchDebounce = debounce
+	}
+}
+
+// WithKeepVersions 모델별로 최근 n개의 버전만 보관 (0 이하면 모두 보관)
+//
+// 사용 중인 버전과 별칭이 가리키는 버전은 n개를 넘어도 삭제하지 않음
+func WithKeepVersions(n int) Option {
+	return func(cfg *Config) {
+		cfg.KeepVersions = n
+	}
+}
+
+// WithMemoryBudget 로드 된 모델의 예상 메모리 합계를 size(byte) 이하로 제한 (0 이하면 제한 없음)
+//
+// 모델의 예상 메모리는 SavedModel graph와 variables 크기이며, 제한을 넘게 되는 모델의 생성, 가져오기,
+// 다시 로드 등은 ErrMemoryBudgetExceeded를 반환. 다시 로드하는 동안에는 이전 모델과 새 모델이 함께 예약 됨
+func WithMemoryBudget(size int64) Option {
+	return func(cfg *Config) {
+		cfg.MemoryBudget = size
+	}
+}
+
+// Action 권한을 확인하는 요청의 종류
+type Action string
+
+// 권한 확인 요청
+const (
+	ActionInfer         Action = "infer"
+	ActionReadModel     Action = "readModel"
+	ActionCreateModel   Action = "createModel"
+	ActionDeleteModel   Action = "deleteModel"
+	ActionReloadModel   Action = "reloadModel"
+	ActionUpdateModel   Action = "updateModel"
+	ActionDisableModel  Action = "disableModel"
+	ActionRollbackModel Action = "rollbackModel"
+	ActionExportModel   Action = "exportModel"
+	ActionIndexModel    Action = "indexModel"
+	ActionManageTenant  Action = "manageTenant"
+	ActionManageAlias   Action = "manageAlias"
+)
+
+// AuthHook 요청의 권한 확인
+//
+// target은 모델 또는 사용자 이름이며, 요청자 정보는 ctx로 전달.
+// 에러를 반환하면 요청은 ErrPermissionDenied로 감싼 에러로 실패
+type AuthHook func(ctx context.Context, action Action, target string) error
+
+// Metrics 추론 엔진의 동작 결과를 수집
+//
+// 여러 goroutine에서 동시에 호출 됨
+type Metrics interface {
+	// ObserveInference 모델의 추론 결과 (images: 추론한 이미지 수)
+	ObserveInference(model string, images int, elapsed time.Duration, err error)
+	// ObserveModelLoad 모델 로드 결과
+	ObserveModelLoad(model string, elapsed time.Duration, err error)
+}
+
+// authorize AuthHook으로 권한 확인 (hook이 없으면 모두 허용)
+//
+// 버전을 지정한 모델 이름(`<model>/<version>`)은 모델 이름으로 확인
+func (i *Inference) authorize(ctx context.Context, action Action, target string) error {
+	if i.authHook == nil {
+		return nil
+	}
+	if model, _, err := splitModelVersion(target); err == nil {
+		target = model
+	}
+
+	if err := i.authHook(ctx, action, target); err != nil {
+		return fmt.Errorf("%w: %s %s: %s", ErrPermissionDenied, action, target, err)
+	}
+
+	return nil
+}
+
+func (i *Inference) observeInference(model string, images int, t0 time.Time, err error) {
+	if i.metrics != nil {
+		i.metrics.ObserveInference(model, images, time.Since(t0), err)
+	}
+}
+
+// load 모델을 로드하고 결과를 Metrics에 전달
+//
+// 로드 된 모델의 예상 메모리 합계가 제한을 넘게 되면 로드하지 않고 ErrMemoryBudgetExceeded 반환
+func (i *Inference) load(m *iModel) error {
+	t0 := time.Now()
+	m.probeDecode = i.probeDecode
+	m.heicConverter = i.heicConverter
+	m.tfliteRuntime = i.tfliteRuntime
+	m.graphCachePath = i.graphCachePath
+	m.preDownscale = i.preDownscale
+	m.warmupRuns = i.warmupRuns
+
+	// 버전을 지정하지 않은 경우 loadModel과 같이 manifest의 사용 중인 버전
+	vPath := m.versionPath
+	if vPath == "" {
+		vPath, _, _ = resolveVersion(m.modelPath)
+	}
+	memory := modelMemory(vPath)
+
+	err := i.memory.reserve(memory)
+	if err == nil {
+		if err = loadModel(m); err != nil {
+			i.memory.free(memory)
+		} else {
+			m.memory = memory
+			m.budget = i.memory
+		}
+	}
+
+	if i.metrics != nil {
+		name := m.name
+		if name == "" {
+			name = m.modelPath
+		}
+		i.metrics.ObserveModelLoad(name, time.Since(t0), err)
+	}
+
+	return err
+}
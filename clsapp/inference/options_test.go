@@ -0,0 +1,89 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestOptions(t *testing.T) {
+	var c Config
+	for _, opt := range []Option{
+		WithConfig(Config{ModelsPath: "/models", LHost: "learner:1", TenantQuota: 1}),
+		WithLearner("learner:2"),
+		WithCache("-"),
+		WithDirNaming(DirNamingPlain),
+	} {
+		opt(&c)
+	}
+
+	want := Config{
+		ModelsPath:     "/models",
+		LHost:          "learner:2",
+		TenantQuota:    1,
+		GraphCachePath: "-",
+		DirNaming:      DirNamingPlain,
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Fatalf("Unexpected config: %+v", c)
+	}
+}
+
+type testMetrics struct {
+	loads []string
+}
+
+func (m *testMetrics) ObserveInference(model string, images int, elapsed time.Duration, err error) {}
+
+func (m *testMetrics) ObserveModelLoad(model string, elapsed time.Duration, err error) {
+	m.loads = append(m.loads, model)
+}
+
+func TestAuthHook(t *testing.T) {
+	i := &Inference{
+		models: map[string]*iModel{
+			"flowers": {name: "flowers"},
+			"pets":    {name: "pets"},
+		},
+		authHook: func(ctx context.Context, action Action, target string) error {
+			if target == "pets" {
+				return errors.New("not allowed")
+			}
+			return nil
+		},
+	}
+	ctx := context.Background()
+
+	models := i.GetModels(ctx, ModelFilter{})
+	sort.Strings(models)
+	if !reflect.DeepEqual(models, []string{"flowers"}) {
+		t.Fatalf("Unexpected models: %v", models)
+	}
+
+	if _, err := i.Infer(ctx, "pets", []byte("image"), "jpg", 1, 0); !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := i.DeleteModel(ctx, "pets"); !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// 허용 된 요청은 이후 단계에서 처리
+	if _, err := i.Infer(ctx, "roses", []byte("image"), "jpg", 1, 0); !errors.Is(err, ErrModelNotFound) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestMetricsModelLoad(t *testing.T) {
+	metrics := &testMetrics{}
+	i := &Inference{metrics: metrics}
+
+	if err := i.load(getNewModel("", "/nonexistent/model")); err == nil {
+		t.Fatal("Expected load error")
+	}
+	if !reflect.DeepEqual(metrics.loads, []string{"/nonexistent/model"}) {
+		t.Fatalf("Unexpected loads: %v", metrics.loads)
+	}
+}
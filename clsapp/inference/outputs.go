@@ -0,0 +1,60 @@
+package inference
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// resolveOutputs config의 출력 operation 이름 중 outputs에 정의된 이름을 operation으로 바꾸고,
+// outputOperationName에 outputIndex를 적용한 후 위반 사항 반환
+//
+// 여러 출력(예: 확률과 embedding)이 있는 graph는 outputs에 이름을 붙이고 각 설정에서 이름으로 참조
+func (cfg *modelConfig) resolveOutputs() []string {
+	var violations []string
+
+	names := make([]string, 0, len(cfg.Outputs))
+	for name := range cfg.Outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name == "" || strings.Contains(name, ":") {
+			violations = append(violations, fmt.Sprintf("`outputs` name must not be empty or contain `:`: %q", name))
+		}
+		if cfg.Outputs[name] == "" {
+			violations = append(violations, fmt.Sprintf("`outputs` operation of %q is required", name))
+		}
+	}
+
+	for _, field := range []*string{
+		&cfg.OutputOperationName,
+		&cfg.EmbeddingOperationName,
+		&cfg.BoxesOperationName,
+		&cfg.ScoresOperationName,
+		&cfg.ClassesOperationName,
+		&cfg.NumDetectionsOperationName,
+		&cfg.CAMOperationName,
+		&cfg.CAMGradientOperationName,
+	} {
+		if op, ok := cfg.Outputs[*field]; ok && op != "" {
+			*field = op
+		}
+	}
+
+	if cfg.OutputIndex < 0 {
+		violations = append(violations, fmt.Sprintf("`outputIndex` must not be negative: %d", cfg.OutputIndex))
+	} else if cfg.OutputIndex > 0 {
+		switch {
+		case cfg.Classification == detectionClass:
+			violations = append(violations, "`outputIndex` must not be used with detection model")
+		case strings.Contains(cfg.OutputOperationName, ":"):
+			violations = append(violations, fmt.Sprintf("`outputIndex` must not be used with `<operation>:<index>` name: %s", cfg.OutputOperationName))
+		case cfg.OutputOperationName != "":
+			cfg.OutputOperationName += ":" + strconv.Itoa(cfg.OutputIndex)
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,78 @@
+package inference
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ValidateName 모델 또는 이미지 그룹 이름 검사
+//
+// 이름은 파일 경로로 사용되기 때문에 빈 이름, 경로 구분자, 상위 디렉토리(`..`),
+// `.`으로 시작하는 이름과 NUL 문자를 허용하지 않음.
+// `@`는 사용자 모델 이름의 구분자로 사용되므로 허용하지 않음
+func ValidateName(name string) error {
+	if name == "" ||
+		strings.HasPrefix(name, ".") ||
+		strings.Contains(name, "..") ||
+		strings.ContainsAny(name, "/\\\x00"+tenantSeparator) {
+		return fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+
+	return nil
+}
+
+// inModelsPath p가 모델 저장 경로의 하위 경로인지 확인
+func (i *Inference) inModelsPath(p string) bool {
+	_, ok := i.modelRoot(p)
+	return ok
+}
+
+// modelRoot 모델 저장 경로 하위의 p가 속한 모델 디렉토리 반환
+//
+// `.`으로 시작하는 디렉토리(사용자 모델 저장 경로 등)는 모델 디렉토리가 아님
+func (i *Inference) modelRoot(p string) (string, bool) {
+	rel, err := filepath.Rel(filepath.Clean(i.modelsPath), filepath.Clean(p))
+	if err != nil || rel == "." || strings.HasPrefix(rel, ".") {
+		return "", false
+	}
+
+	return filepath.Join(i.modelsPath, strings.Split(rel, string(filepath.Separator))[0]), true
+}
+
+// ownedPath p가 등록 된 모델의 경로이거나 그 상위 또는 하위 경로인지 확인
+//
+// i.rwMutex를 잡은 상태에서 호출
+func (i *Inference) ownedPath(p string) bool {
+	for _, m := range i.models {
+		if withinPath(m.modelPath, p) || withinPath(p, m.modelPath) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// withinPath p가 parent 자신이거나 하위 경로인지 확인
+func withinPath(parent, p string) bool {
+	rel, err := filepath.Rel(filepath.Clean(parent), filepath.Clean(p))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// dirSize p 하위 모든 파일 크기의 합
+func dirSize(p string) (int64, error) {
+	var size int64
+
+	err := filepath.Walk(p, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+
+	return size, err
+}
@@ -0,0 +1,33 @@
+package inference
+
+import (
+	"testing"
+)
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"default", "flowers-v2", "my_model.1", "꽃", "장미 사진"} {
+		if err := ValidateName(name); err != nil {
+			t.Fatalf("Valid name %q: %s", name, err)
+		}
+	}
+
+	for _, name := range []string{"", "..", "../models", "a/b", `a\b`, ".hidden", "a..b", "a\x00b", "pets@alice"} {
+		if err := ValidateName(name); err == nil {
+			t.Fatalf("Invalid name %q should fail", name)
+		}
+	}
+}
+
+func TestInModelsPath(t *testing.T) {
+	i := &Inference{modelsPath: "/cls/models"}
+
+	if !i.inModelsPath("/cls/models/default-1234") {
+		t.Fatal("Sub directory should be in models path")
+	}
+
+	for _, p := range []string{"/cls/models", "/cls/models/..", "/cls/models/../images", "/cls/models/.tenants/alice", "/", "relative"} {
+		if i.inModelsPath(p) {
+			t.Fatalf("%q should not be in models path", p)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// pipelineFile 추론 pipeline 설정 파일
+//
+//	pipelines:
+//	  - name: products
+//	    stages:
+//	      - model: product-gate
+//	        passLabel: product
+//	        threshold: 0.7
+//	      - model: products
+type pipelineFile struct {
+	Pipelines []pipelineConfig `yaml:"pipelines"`
+}
+
+// pipelineConfig 앞 단계의 binary gate 모델을 통과한 이미지만 다음 단계의 모델로 추론하는 pipeline
+type pipelineConfig struct {
+	Name   string          `yaml:"name"`
+	Stages []pipelineStage `yaml:"stages"`
+}
+
+type pipelineStage struct {
+	Model string `yaml:"model"`
+	// gate 모델에서 다음 단계로 진행하는 클래스 (기본값: positive 클래스, labels의 두번째 클래스)
+	PassLabel string `yaml:"passLabel"`
+	// gate 모델의 threshold (기본값: 모델 config의 threshold)
+	Threshold float32 `yaml:"threshold"`
+}
+
+// StageResult pipeline 단계별 추론 결과
+type StageResult struct {
+	Model     string       `json:"model"`
+	Version   int          `json:"version"`
+	Inference []InferLabel `json:"inference"`
+	// gate 모델의 판단 된 클래스가 passLabel이라 다음 단계로 진행하면 true (마지막 단계는 항상 true)
+	Passed bool `json:"passed"`
+}
+
+// PipelineResult pipeline 추론 결과
+type PipelineResult struct {
+	Pipeline string `json:"pipeline"`
+	// 실행한 단계의 결과 (통과하지 못한 gate까지)
+	Stages []StageResult `json:"stages"`
+	// 모든 gate를 통과하여 마지막 단계의 모델까지 추론하면 true
+	Passed bool `json:"passed"`
+	// 마지막으로 실행한 단계의 결과 (통과하지 못하면 gate 모델의 결과)
+	Inference []InferLabel `json:"inference"`
+}
+
+// loadPipelines pipeline 설정 파일을 읽고 검사
+func loadPipelines(file string) (map[string]pipelineConfig, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var pf pipelineFile
+	if err := yaml.UnmarshalStrict(b, &pf); err != nil {
+		return nil, &ConfigError{File: file, Violations: []string{err.Error()}}
+	}
+
+	var (
+		pipelines  = make(map[string]pipelineConfig, len(pf.Pipelines))
+		violations []string
+	)
+	for _, p := range pf.Pipelines {
+		if p.Name == "" {
+			violations = append(violations, "`name` of pipeline is required")
+			continue
+		}
+		if _, ok := pipelines[p.Name]; ok {
+			violations = append(violations, fmt.Sprintf("Duplicated pipeline: %s", p.Name))
+			continue
+		}
+		violations = append(violations, p.validate()...)
+		pipelines[p.Name] = p
+	}
+	if len(violations) > 0 {
+		return nil, &ConfigError{File: file, Violations: violations}
+	}
+
+	return pipelines, nil
+}
+
+// validate pipeline 단계 검사
+func (p *pipelineConfig) validate() []string {
+	if len(p.Stages) < 2 {
+		return []string{fmt.Sprintf("Pipeline %s must have gate and final stages", p.Name)}
+	}
+
+	var violations []string
+	for idx, stage := range p.Stages {
+		if stage.Model == "" {
+			violations = append(violations, fmt.Sprintf("`model` of pipeline %s stage %d is required", p.Name, idx))
+		}
+		if idx == len(p.Stages)-1 {
+			// 마지막 단계의 threshold는 요청의 threshold를 사용
+			if stage.PassLabel != "" || stage.Threshold != 0 {
+				violations = append(violations, fmt.Sprintf("Final stage of pipeline %s must not have `passLabel` or `threshold`", p.Name))
+			}
+		} else if stage.Threshold != 0 && !validThreshold(stage.Threshold) {
+			violations = append(violations, fmt.Sprintf("`threshold` of pipeline %s stage %d must be between 0 and 1: %v", p.Name, idx, stage.Threshold))
+		}
+	}
+
+	return violations
+}
+
+// passGate gate 모델의 판단 된 클래스가 passLabel(기본값: positive 클래스)인지 확인
+//
+// labelMap과 지역화로 결과의 label이 바뀔 수 있으므로 labels 파일의 index로 비교
+func (m *iModel) passGate(passLabel string, infers []InferLabel) (bool, error) {
+	pass := 1
+	if passLabel != "" {
+		pass = -1
+		for idx, label := range m.labels {
+			if label == passLabel {
+				pass = idx
+				break
+			}
+		}
+		if pass < 0 {
+			return false, fmt.Errorf("%w: no such pass label %q in %s", ErrInvalidConfig, passLabel, m.name)
+		}
+	}
+
+	return len(infers) > 0 && !infers[0].Unknown && infers[0].Index == pass, nil
+}
+
+// runStage pipeline 단계의 모델로 추론 (gate 단계는 binary 모델만 사용할 수 있음)
+func (i *Inference) runStage(ctx context.Context, stage pipelineStage, gate bool, image []byte, format string, k int, threshold float32) (result *StageResult, err error) {
+	if err := i.authorize(ctx, ActionInfer, stage.Model); err != nil {
+		return nil, err
+	}
+
+	t0 := time.Now()
+	defer func() {
+		i.observeInference(stage.Model, 1, t0, err)
+	}()
+
+	i.rwMutex.RLock()
+	m := i.getModel(stage.Model)
+	i.rwMutex.RUnlock()
+
+	if m == nil {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, stage.Model)
+	}
+	defer i.putModel(m)
+
+	if atomic.LoadInt32(&m.status) != modelStatusRun {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotReady, stage.Model)
+	}
+	if err := i.checkEnabled(m); err != nil {
+		return nil, err
+	}
+	recordServed(ctx, m)
+
+	if gate {
+		if m.cfg.Classification != binaryClass {
+			return nil, fmt.Errorf("%w: gate model %s must be %s", ErrModelTypeMismatch, m.name, binaryClass)
+		}
+		k, threshold = 0, stage.Threshold
+	}
+
+	infers, err := m.infer(ctx, image, format, k, threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	result = &StageResult{
+		Model:     m.name,
+		Version:   m.version,
+		Inference: infers,
+		Passed:    true,
+	}
+	if gate {
+		if result.Passed, err = m.passGate(stage.PassLabel, infers); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// InferPipeline pipeline의 단계 순서로 이미지를 추론하며, gate 모델을 통과하지 못하면 다음 단계를 실행하지 않음
+//
+// k와 threshold는 마지막 단계의 모델에 적용
+func (i *Inference) InferPipeline(ctx context.Context, pipeline string, image []byte, format string, k int, threshold float32) (*PipelineResult, error) {
+	p, ok := i.pipelines[pipeline]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPipelineNotFound, pipeline)
+	}
+
+	if err := i.imageLimits.check(image); err != nil {
+		return nil, err
+	}
+
+	result := &PipelineResult{
+		Pipeline:  pipeline,
+		Stages:    []StageResult{},
+		Inference: []InferLabel{},
+	}
+	for idx, stage := range p.Stages {
+		gate := idx < len(p.Stages)-1
+
+		stageResult, err := i.runStage(ctx, stage, gate, image, format, k, threshold)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", stage.Model, err)
+		}
+
+		result.Stages = append(result.Stages, *stageResult)
+		result.Inference = stageResult.Inference
+		if !stageResult.Passed {
+			return result, nil
+		}
+	}
+	result.Passed = true
+
+	return result, nil
+}
@@ -0,0 +1,120 @@
+package inference
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadPipelines(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pipelines-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		config     string
+		violations []string
+	}{
+		{
+			`
+pipelines:
+  - name: products
+    stages:
+      - model: product-gate
+        passLabel: product
+        threshold: 0.7
+      - model: products
+`,
+			nil,
+		},
+		{
+			`
+pipelines:
+  - name: products
+    stages:
+      - model: products
+  - name: products
+    stages:
+      - model: product-gate
+      - model: products
+  - stages:
+      - model: product-gate
+      - model: products
+`,
+			[]string{"must have gate and final stages", "Duplicated pipeline", "`name` of pipeline is required"},
+		},
+		{
+			`
+pipelines:
+  - name: products
+    stages:
+      - model: product-gate
+        threshold: 1.5
+      - model: ""
+        passLabel: product
+`,
+			[]string{"`threshold` of pipeline products stage 0", "`model` of pipeline products stage 1", "Final stage"},
+		},
+	}
+
+	for _, test := range tests {
+		file := filepath.Join(dir, "pipelines.yaml")
+		if err := ioutil.WriteFile(file, []byte(test.config), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		pipelines, err := loadPipelines(file)
+		if test.violations == nil {
+			if err != nil || len(pipelines["products"].Stages) != 2 {
+				t.Fatalf("Unexpected pipelines: %v, %v", pipelines, err)
+			}
+			continue
+		}
+
+		var cfgErr *ConfigError
+		if !errors.As(err, &cfgErr) || len(cfgErr.Violations) != len(test.violations) {
+			t.Fatalf("Unexpected violations: %v\n%s", err, test.config)
+		}
+		for idx, violation := range test.violations {
+			if !strings.Contains(cfgErr.Violations[idx], violation) {
+				t.Fatalf("Unexpected violation: %s, %s", cfgErr.Violations[idx], violation)
+			}
+		}
+	}
+}
+
+func TestPassGate(t *testing.T) {
+	m := &iModel{
+		name:   "product-gate",
+		labels: []string{"other", "product"},
+	}
+
+	tests := []struct {
+		passLabel string
+		infers    []InferLabel
+		passed    bool
+	}{
+		{"", []InferLabel{{Label: "product", Index: 1}, {Label: "other", Index: 0}}, true},
+		{"", []InferLabel{{Label: "other", Index: 0}, {Label: "product", Index: 1}}, false},
+		{"other", []InferLabel{{Label: "other", Index: 0}}, true},
+		// labelMap 또는 지역화로 바뀐 label도 index로 판단
+		{"product", []InferLabel{{Label: "상품", Index: 1}}, true},
+		{"", []InferLabel{{Label: "unknown", Index: -1, Unknown: true}}, false},
+		{"", []InferLabel{}, false},
+	}
+	for _, test := range tests {
+		passed, err := m.passGate(test.passLabel, test.infers)
+		if err != nil || passed != test.passed {
+			t.Fatalf("passGate(%q, %v) = %v, %v", test.passLabel, test.infers, passed, err)
+		}
+	}
+
+	if _, err := m.passGate("dog", nil); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Unknown pass label should fail: %v", err)
+	}
+}
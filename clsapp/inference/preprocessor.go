@@ -0,0 +1,87 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Tensor batch를 제외한 모델 입력
+//
+// Data는 Shape(모델의 inputLayout 순서, 예: NHWC는 [height, width, channels]) 순서로 펼친 값
+type Tensor struct {
+	Shape []int
+	Data  []float32
+}
+
+// Preprocessor 내장 이미지 디코딩/전처리 graph를 대체하여 이미지를 모델 입력으로 변환 (예: 얼굴 crop, 배경 제거)
+//
+// 모델 config의 `preprocessor`에 이름으로 지정하며, 여러 goroutine에서 동시에 호출 됨.
+// 반환한 Tensor는 InferTensor와 같이 모델 입력과 맞는지 검사한 후 그대로 모델에 전달 됨
+type Preprocessor interface {
+	// Preprocess 이미지를 모델 입력으로 변환
+	//
+	// format은 요청한 형식이며, 지정하지 않았으면 이미지 내용으로 판단한 형식 (알 수 없으면 빈 값)
+	Preprocess(ctx context.Context, model string, image []byte, format string) (*Tensor, error)
+}
+
+// PreprocessorFunc 함수를 Preprocessor로 사용
+type PreprocessorFunc func(ctx context.Context, model string, image []byte, format string) (*Tensor, error)
+
+// Preprocess f(ctx, model, image, format) 호출
+func (f PreprocessorFunc) Preprocess(ctx context.Context, model string, image []byte, format string) (*Tensor, error) {
+	return f(ctx, model, image, format)
+}
+
+var (
+	preprocessors      = make(map[string]Preprocessor)
+	preprocessorsMutex sync.RWMutex
+)
+
+// RegisterPreprocessor name으로 Preprocessor 등록
+//
+// RegisterHook과 같이 모델 로드 전에 등록해야 하며, 같은 이름으로 다시 등록하면 이후 로드 되는 모델부터 적용 됨
+func RegisterPreprocessor(name string, p Preprocessor) {
+	preprocessorsMutex.Lock()
+	defer preprocessorsMutex.Unlock()
+
+	preprocessors[name] = p
+}
+
+// lookupPreprocessor config에 지정된 Preprocessor 반환 (지정하지 않았으면 nil)
+func lookupPreprocessor(name string) (Preprocessor, []string) {
+	if name == "" {
+		return nil, nil
+	}
+
+	preprocessorsMutex.RLock()
+	defer preprocessorsMutex.RUnlock()
+
+	p, ok := preprocessors[name]
+	if !ok {
+		return nil, []string{fmt.Sprintf("`preprocessor` is not registered: %s", name)}
+	}
+
+	return p, nil
+}
+
+// runPreprocessor 등록 된 Preprocessor로 이미지를 변환하여 추론 결과 반환
+func (m *iModel) runPreprocessor(ctx context.Context, image []byte, format string) ([]float32, error) {
+	if format == "" {
+		format = DetectFormat(image)
+	}
+
+	t, err := m.preprocessor.Preprocess(ctx, m.name, image, format)
+	if err != nil {
+		return nil, err
+	}
+	// 잘못된 입력은 요청이 아닌 Preprocessor의 문제이므로 ErrInvalidConfig
+	if t == nil {
+		return nil, fmt.Errorf("%w: no tensor from preprocessor %s", ErrInvalidConfig, m.cfg.Preprocessor)
+	}
+	if err := m.cfg.checkTensor(t.Data, t.Shape); err != nil {
+		return nil, fmt.Errorf("%w: preprocessor %s: %s", ErrInvalidConfig, m.cfg.Preprocessor, err)
+	}
+
+	return m.backend.runTensor(ctx, t.Data)
+}
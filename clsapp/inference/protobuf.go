@@ -0,0 +1,154 @@
+package inference
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// protobuf wire format
+//
+// TensorFlow Serving과 Triton의 gRPC 요청과 응답 message 중 사용하는 필드만 직접 인코딩
+const (
+	protoVarint  = 0
+	protoFixed64 = 1
+	protoBytes   = 2
+	protoFixed32 = 5
+)
+
+// protoField message의 필드 하나
+type protoField struct {
+	num  int
+	wire int
+	// varint, fixed64, fixed32 값
+	value uint64
+	// length-delimited 값 (message, string, packed repeated)
+	bytes []byte
+}
+
+func appendProtoVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+
+	return append(b, byte(v))
+}
+
+func appendProtoTag(b []byte, num, wire int) []byte {
+	return appendProtoVarint(b, uint64(num)<<3|uint64(wire))
+}
+
+// appendProtoUint varint 필드 (0이면 생략)
+func appendProtoUint(b []byte, num int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+
+	return appendProtoVarint(appendProtoTag(b, num, protoVarint), v)
+}
+
+// appendProtoBytes length-delimited 필드
+func appendProtoBytes(b []byte, num int, v []byte) []byte {
+	b = appendProtoVarint(appendProtoTag(b, num, protoBytes), uint64(len(v)))
+	return append(b, v...)
+}
+
+// appendProtoString string 필드 (빈 문자열이면 생략)
+func appendProtoString(b []byte, num int, s string) []byte {
+	if s == "" {
+		return b
+	}
+
+	return appendProtoBytes(b, num, []byte(s))
+}
+
+// parseProto message의 필드 목록
+func parseProto(b []byte) ([]protoField, error) {
+	var fields []protoField
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, errors.New("Invalid protobuf tag")
+		}
+		b = b[n:]
+
+		f := protoField{num: int(tag >> 3), wire: int(tag & 7)}
+		switch f.wire {
+		case protoVarint:
+			if f.value, n = binary.Uvarint(b); n <= 0 {
+				return nil, errors.New("Invalid protobuf varint")
+			}
+			b = b[n:]
+		case protoFixed64:
+			if len(b) < 8 {
+				return nil, errors.New("Truncated protobuf fixed64")
+			}
+			f.value, b = binary.LittleEndian.Uint64(b), b[8:]
+		case protoBytes:
+			size, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < size {
+				return nil, errors.New("Truncated protobuf bytes")
+			}
+			f.bytes, b = b[n:n+int(size)], b[n+int(size):]
+		case protoFixed32:
+			if len(b) < 4 {
+				return nil, errors.New("Truncated protobuf fixed32")
+			}
+			f.value, b = uint64(binary.LittleEndian.Uint32(b)), b[4:]
+		default:
+			return nil, fmt.Errorf("Unsupported protobuf wire type: %d", f.wire)
+		}
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+// appendFloat32s little-endian float32 값 (tensor content, packed float)
+func appendFloat32s(b []byte, values []float32) []byte {
+	for _, v := range values {
+		bits := math.Float32bits(v)
+		b = append(b, byte(bits), byte(bits>>8), byte(bits>>16), byte(bits>>24))
+	}
+
+	return b
+}
+
+// appendUint8s uint8 입력 값
+func appendUint8s(b []byte, values []float32) []byte {
+	for _, v := range values {
+		b = append(b, byte(v))
+	}
+
+	return b
+}
+
+// decodeFloat32s little-endian float32 값
+func decodeFloat32s(b []byte) ([]float32, error) {
+	if len(b)%4 != 0 {
+		return nil, fmt.Errorf("Invalid float32 content size: %d", len(b))
+	}
+
+	values := make([]float32, len(b)/4)
+	for idx := range values {
+		values[idx] = math.Float32frombits(binary.LittleEndian.Uint32(b[idx*4:]))
+	}
+
+	return values, nil
+}
+
+// decodeFloat64s little-endian float64 값을 float32로 변환
+func decodeFloat64s(b []byte) ([]float32, error) {
+	if len(b)%8 != 0 {
+		return nil, fmt.Errorf("Invalid float64 content size: %d", len(b))
+	}
+
+	values := make([]float32, len(b)/8)
+	for idx := range values {
+		values[idx] = float32(math.Float64frombits(binary.LittleEndian.Uint64(b[idx*8:])))
+	}
+
+	return values, nil
+}
@@ -0,0 +1,54 @@
+package inference
+
+import (
+	"context"
+)
+
+// RawInference 추론 결과와 활성화 함수를 적용하기 전의 모델 출력
+//
+// 클라이언트에서 calibration이나 ensemble을 직접 적용 할 수 있도록 사용
+type RawInference struct {
+	Inference []InferLabel `json:"inference"`
+	// 이미지(TTA, multi-crop을 사용하면 원본과 변형 이미지)별 모델 출력
+	Outputs [][]float32 `json:"outputs"`
+	// 출력 순서의 클래스 이름 (binary 모델의 출력이 하나면 labels[1]의 값)
+	Labels []string `json:"labels"`
+	// 모델 출력을 확률로 변환하는 활성화 함수
+	Activation string `json:"activation"`
+	// 활성화 함수를 적용하기 전에 모델 출력을 나누는 값 (0이면 사용 안함)
+	Temperature float32 `json:"temperature,omitempty"`
+	// 결과에 포함되지 않은 클래스 수 (multi, multilabel 모델)
+	Filtered *FilterStats `json:"filtered,omitempty"`
+}
+
+// inferRaw 이미지를 추론하여 모델 출력과 함께 반환
+func (m *iModel) inferRaw(ctx context.Context, image []byte, format string, k int, threshold float32) (*RawInference, error) {
+	outputs, err := m.runImage(ctx, image, format)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats *FilterStats
+	if m.cfg.Classification == multiClass || m.cfg.Classification == multiLabelClass {
+		stats = &FilterStats{}
+	}
+
+	infers, err := m.classifyFiltered(ctx, outputs, k, threshold, stats)
+	if err != nil {
+		return nil, err
+	}
+
+	activation := m.cfg.OutputActivation
+	if activation == "" {
+		activation = activationNone
+	}
+
+	return &RawInference{
+		Inference:   infers,
+		Outputs:     outputs,
+		Labels:      m.labels,
+		Activation:  activation,
+		Temperature: m.cfg.Temperature,
+		Filtered:    stats,
+	}, nil
+}
@@ -0,0 +1,50 @@
+package inference
+
+// 이미지를 모델 입력 크기(height, width)로 조정하는 방식
+const (
+	// 비율과 관계없이 입력 크기로 늘리거나 줄임 (기본값)
+	resizeStretch = "stretch"
+	// 비율을 유지하여 입력 크기를 채운 후 넘치는 부분을 가운데 기준으로 잘라냄
+	resizeCenterCrop = "center-crop"
+	// 비율을 유지하여 입력 크기 안에 넣은 후 남는 부분을 mean 값(정규화 후 0)으로 채움
+	resizeLetterbox = "letterbox"
+)
+
+// 이미지 크기 조정의 보간 방식
+const (
+	// 기본값
+	resizeBilinear = "bilinear"
+	// Keras 전처리와 같은 보간 (일부 backbone)
+	resizeBicubic = "bicubic"
+	resizeNearest = "nearest"
+	resizeArea    = "area"
+)
+
+// cropResizeMethods center-crop, letterbox에서 사용할 수 있는 보간 방식 (CropAndResize가 지원하는 방식)
+var cropResizeMethods = map[string]bool{
+	resizeBilinear: true,
+	resizeNearest:  true,
+}
+
+// resizeMode 이미지 크기 조정 방식
+func (cfg *modelConfig) resizeMode() string {
+	if cfg.ResizeMode == "" {
+		return resizeStretch
+	}
+
+	return cfg.ResizeMode
+}
+
+// resizeMethod 이미지 크기 조정의 보간 방식
+func (cfg *modelConfig) resizeMethod() string {
+	if cfg.ResizeMethod == "" {
+		return resizeBilinear
+	}
+
+	return cfg.ResizeMethod
+}
+
+// resizeName 전처리 graph 저장 파일에 사용하는 크기 조정 방식 이름 (예: center-crop-bilinear)
+func (cfg *modelConfig) resizeName() string {
+	return cfg.resizeMode() + "-" + cfg.resizeMethod()
+}
@@ -0,0 +1,195 @@
+package inference
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"strings"
+	"sync"
+	"time"
+)
+
+// resultKey 추론 결과 캐시의 key
+//
+// 같은 이름과 버전이라도 다시 로드 된 모델(config, labels 변경)의 결과는 loadID로 구분.
+// 삭제 된 모델의 결과는 다시 사용되지 않으므로 LRU로 제거 됨
+type resultKey struct {
+	model     string
+	version   int
+	loadID    uint64
+	sum       [sha256.Size]byte
+	format    string
+	k         int
+	threshold float32
+	// 결과의 label을 바꾸는 요청 언어
+	languages string
+	// 요청에서 제외한 클래스
+	excluded string
+}
+
+type resultEntry struct {
+	key     resultKey
+	result  *RawInference
+	expires time.Time
+}
+
+// CacheStats 추론 결과 캐시 사용 현황
+type CacheStats struct {
+	// 캐시 된 결과 수와 최대 수
+	Entries  int `json:"entries"`
+	Capacity int `json:"capacity"`
+	// 결과를 캐시에서 찾은 요청 수와 찾지 못한 요청 수 (만료 된 결과 포함)
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	// 최대 수를 넘어 제거 된 결과 수
+	Evictions uint64 `json:"evictions"`
+	TTL       string `json:"ttl"`
+}
+
+// resultCache 이미지 내용(SHA-256)별 추론 결과 LRU 캐시
+//
+// 같은 이미지를 다시 추론하면(thumbnail, 재시도) 디코딩과 Session.Run 없이 결과를 반환. nil이면 사용 안함
+type resultCache struct {
+	mutex    sync.Mutex
+	capacity int
+	// 0이면 만료되지 않음
+	ttl     time.Duration
+	entries map[resultKey]*list.Element
+	// 최근에 사용한 결과가 앞
+	lru *list.List
+
+	hits, misses, evictions uint64
+}
+
+func newResultCache(capacity int, ttl time.Duration) *resultCache {
+	if capacity <= 0 {
+		return nil
+	}
+
+	return &resultCache{
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  make(map[resultKey]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// resultKey 모델과 요청으로 캐시 key 생성
+func (m *iModel) resultKey(ctx context.Context, image []byte, format string, k int, threshold float32) resultKey {
+	return resultKey{
+		model:     m.name,
+		version:   m.version,
+		loadID:    m.loadID,
+		sum:       sha256.Sum256(image),
+		format:    format,
+		k:         k,
+		threshold: threshold,
+		languages: strings.Join(contextLanguages(ctx), ","),
+		excluded:  strings.Join(contextExcludedLabels(ctx), "\n"),
+	}
+}
+
+// copyRawInference 캐시 된 결과를 요청마다 바꿀 수 있도록 InferLabel을 복사 (Outputs, Labels는 바꾸지 않음)
+func copyRawInference(result *RawInference) *RawInference {
+	copied := *result
+	copied.Inference = copyInferLabels(result.Inference)
+
+	return &copied
+}
+
+func copyInferLabels(infers []InferLabel) []InferLabel {
+	if infers == nil {
+		return nil
+	}
+
+	copied := make([]InferLabel, len(infers))
+	for idx, infer := range infers {
+		copied[idx] = infer
+		copied[idx].Parents = copyInferLabels(infer.Parents)
+	}
+
+	return copied
+}
+
+// get 만료되지 않은 결과의 복사본 반환
+func (c *resultCache) get(key resultKey) (*RawInference, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem, ok := c.entries[key]
+	if ok {
+		entry := elem.Value.(*resultEntry)
+		if c.ttl <= 0 || time.Now().Before(entry.expires) {
+			c.lru.MoveToFront(elem)
+			c.hits++
+			return copyRawInference(entry.result), true
+		}
+
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+	}
+	c.misses++
+
+	return nil, false
+}
+
+// put 결과의 복사본을 저장하고, 최대 수를 넘으면 가장 오래 사용하지 않은 결과를 제거
+func (c *resultCache) put(key resultKey, result *RawInference) {
+	if c == nil {
+		return
+	}
+
+	entry := &resultEntry{
+		key:     key,
+		result:  copyRawInference(result),
+		expires: time.Now().Add(c.ttl),
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.capacity {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*resultEntry).key)
+		c.evictions++
+	}
+}
+
+func (c *resultCache) stats() CacheStats {
+	if c == nil {
+		return CacheStats{}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats := CacheStats{
+		Entries:   c.lru.Len(),
+		Capacity:  c.capacity,
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+	if c.ttl > 0 {
+		stats.TTL = c.ttl.String()
+	}
+
+	return stats
+}
+
+// CacheStats 추론 결과 캐시 사용 현황 (캐시를 사용하지 않으면 빈 값)
+func (i *Inference) CacheStats() CacheStats {
+	return i.resultCache.stats()
+}
@@ -0,0 +1,97 @@
+package inference
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestResultCache(t *testing.T) {
+	c := newResultCache(2, 0)
+	m := &iModel{name: "flowers", version: 1, loadID: 1}
+	ctx := context.Background()
+
+	key := func(image string) resultKey {
+		return m.resultKey(ctx, []byte(image), "jpeg", 3, 0)
+	}
+	result := func(label string) *RawInference {
+		return &RawInference{Inference: []InferLabel{{Label: label, Prob: 0.9}}}
+	}
+
+	if _, ok := c.get(key("roses")); ok {
+		t.Fatal("Empty cache should miss")
+	}
+	c.put(key("roses"), result("roses"))
+	c.put(key("tulips"), result("tulips"))
+
+	cached, ok := c.get(key("roses"))
+	if !ok || cached.Inference[0].Label != "roses" {
+		t.Fatalf("Unexpected cached result: %v %v", cached, ok)
+	}
+	// 반환 된 결과를 바꿔도 캐시 된 결과는 바뀌지 않음
+	cached.Inference[0].Label = "changed"
+	if cached, _ := c.get(key("roses")); cached.Inference[0].Label != "roses" {
+		t.Fatalf("Cached result should not be changed: %v", cached)
+	}
+
+	// 가장 오래 사용하지 않은 tulips 제거
+	c.put(key("daisy"), result("daisy"))
+	if _, ok := c.get(key("tulips")); ok {
+		t.Fatal("Least recently used result should be evicted")
+	}
+
+	// 다시 로드 된 모델, 다른 k와 언어는 다른 결과
+	reloaded := &iModel{name: "flowers", version: 1, loadID: 2}
+	if _, ok := c.get(reloaded.resultKey(ctx, []byte("roses"), "jpeg", 3, 0)); ok {
+		t.Fatal("Reloaded model should miss")
+	}
+	if _, ok := c.get(m.resultKey(ctx, []byte("roses"), "jpeg", 5, 0)); ok {
+		t.Fatal("Different k should miss")
+	}
+	if _, ok := c.get(m.resultKey(ContextWithLanguages(ctx, "en"), []byte("roses"), "jpeg", 3, 0)); ok {
+		t.Fatal("Different language should miss")
+	}
+
+	stats := c.stats()
+	if stats.Entries != 2 || stats.Hits != 2 || stats.Misses != 5 || stats.Evictions != 1 {
+		t.Fatalf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestResultCacheTTL(t *testing.T) {
+	c := newResultCache(2, time.Millisecond)
+	k := (&iModel{name: "flowers"}).resultKey(context.Background(), []byte("roses"), "jpeg", 3, 0)
+
+	c.put(k, &RawInference{})
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := c.get(k); ok {
+		t.Fatal("Expired result should miss")
+	}
+	if stats := c.stats(); stats.Entries != 0 || stats.TTL != "1ms" {
+		t.Fatalf("Unexpected stats: %+v", stats)
+	}
+
+	if newResultCache(0, time.Minute) != nil {
+		t.Fatal("Cache should be disabled if size is 0")
+	}
+}
+
+func TestInferRawCached(t *testing.T) {
+	// backend 없는 모델이라 캐시 된 결과가 없으면 실행할 수 없음
+	m := &iModel{name: "flowers", version: 1, loadID: 1, status: modelStatusRun}
+	i := &Inference{
+		models:      map[string]*iModel{"flowers": m},
+		resultCache: newResultCache(10, 0),
+	}
+
+	ctx := context.Background()
+	i.resultCache.put(m.resultKey(ctx, []byte("roses"), "jpeg", 3, 0), &RawInference{Inference: []InferLabel{{Label: "roses"}}})
+
+	result, err := i.InferRaw(ctx, "flowers", []byte("roses"), "jpeg", 3, 0)
+	if err != nil || result.Inference[0].Label != "roses" {
+		t.Fatalf("Unexpected cached result: %v %v", result, err)
+	}
+	if stats := i.CacheStats(); stats.Hits != 1 {
+		t.Fatalf("Unexpected stats: %+v", stats)
+	}
+}
@@ -0,0 +1,34 @@
+package inference
+
+import "context"
+
+// runLimiter 모델의 동시 실행(Session.Run) 수 제한 (nil이면 제한 없음)
+//
+// TF session 실행은 중단할 수 없으므로, 실행 중인 요청이 많으면 새 요청은 실행 전에 기다리고
+// 기다리는 동안 요청이 취소되거나 deadline이 지나면 실행하지 않음
+type runLimiter chan struct{}
+
+func newRunLimiter(n int) runLimiter {
+	if n <= 0 {
+		return nil
+	}
+
+	return make(runLimiter, n)
+}
+
+// acquire 실행 순서를 기다린 후 실행이 끝나면 호출할 함수 반환 (기다리는 중에 ctx가 끝나면 ctx.Err())
+func (l runLimiter) acquire(ctx context.Context) (func(), error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if l == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l <- struct{}{}:
+		return func() { <-l }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
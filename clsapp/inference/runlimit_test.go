@@ -0,0 +1,41 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunLimiter(t *testing.T) {
+	l := newRunLimiter(1)
+
+	release, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 실행 중인 요청이 끝나기 전에 deadline이 지나면 실행하지 않음
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Waiting should be aborted: %v", err)
+	}
+
+	release()
+	release, err = l.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	var unlimited runLimiter
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := unlimited.acquire(canceled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Canceled request should not run: %v", err)
+	}
+	if newRunLimiter(0) != nil {
+		t.Fatal("Limiter should be disabled if n is 0")
+	}
+}
@@ -0,0 +1,300 @@
+package inference
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// 이미지의 픽셀별 클래스를 분류하는 모델로, 출력을 mask로 반환
+	segmentationClass = "segmentation"
+	// mask는 8bit 이미지이므로 클래스 index는 255 이하
+	maxSegmentationLabels = 256
+)
+
+// segmentation 결과의 mask 인코딩 방식
+const (
+	// 픽셀값이 클래스 index인 grayscale PNG
+	MaskPNG = "png"
+	// row-major 순서의 (클래스 index, 연속 된 픽셀 수) 쌍
+	MaskRLE = "rle"
+)
+
+// SegmentLabel mask에 있는 클래스
+type SegmentLabel struct {
+	// mask의 픽셀값 (labels의 index)
+	Index int    `json:"index"`
+	Label string `json:"label"`
+	Group string `json:"group,omitempty"`
+	// mask에서 클래스가 차지하는 비율
+	Ratio float32 `json:"ratio"`
+}
+
+// SegmentationResult segmentation 모델의 픽셀별 클래스
+//
+// mask는 모델 출력 크기이며 이미지 전체에 대응하므로, 원본 이미지 크기로 늘려서 사용
+type SegmentationResult struct {
+	Height   int    `json:"height"`
+	Width    int    `json:"width"`
+	Encoding string `json:"encoding"`
+	// MaskPNG로 인코딩 한 mask (json에서는 base64)
+	Mask []byte `json:"mask,omitempty"`
+	// MaskRLE로 인코딩 한 mask
+	RLE []int `json:"rle,omitempty"`
+	// 숨김 클래스를 제외한 mask의 클래스 (비율 순서)
+	Labels []SegmentLabel `json:"labels"`
+}
+
+// segmentMap row-major 순서의 픽셀별 클래스 index
+type segmentMap struct {
+	height, width int
+	classes       []uint8
+}
+
+// parseSegmentation 모델 출력을 픽셀별 클래스로 변환
+//
+// 출력은 [1, H, W, C]의 클래스별 점수(가장 큰 클래스 선택) 또는 [1, H, W]의 클래스 index.
+// C가 1이면 foreground(labels[1]) 확률이며, threshold 이상인 픽셀을 foreground로 판단
+func parseSegmentation(value interface{}, activation string, threshold float32) (*segmentMap, error) {
+	var (
+		seg *segmentMap
+		err error
+	)
+
+	newMap := func(h, w int) (*segmentMap, error) {
+		if h == 0 || w == 0 {
+			return nil, fmt.Errorf("%w: empty segmentation output", ErrInvalidConfig)
+		}
+		return &segmentMap{height: h, width: w, classes: make([]uint8, h*w)}, nil
+	}
+	setClass := func(y, x int, class int64) error {
+		if class < 0 || class >= maxSegmentationLabels {
+			return fmt.Errorf("%w: segmentation class %d is out of [0, %d)", ErrInvalidConfig, class, maxSegmentationLabels)
+		}
+		seg.classes[y*seg.width+x] = uint8(class)
+		return nil
+	}
+
+	switch v := value.(type) {
+	case [][][][]float32:
+		if len(v) == 0 || len(v[0]) == 0 {
+			return nil, fmt.Errorf("%w: empty segmentation output", ErrInvalidConfig)
+		}
+		if seg, err = newMap(len(v[0]), len(v[0][0])); err != nil {
+			return nil, err
+		}
+		for y, row := range v[0] {
+			if len(row) != seg.width {
+				return nil, fmt.Errorf("%w: segmentation output rows have different widths", ErrInvalidConfig)
+			}
+			for x, scores := range row {
+				var class int64
+				switch len(scores) {
+				case 0:
+					return nil, fmt.Errorf("%w: segmentation output has no class", ErrInvalidConfig)
+				case 1:
+					if activate(activation, scores)[0] >= threshold {
+						class = 1
+					}
+				default:
+					for c, score := range scores {
+						if score > scores[class] {
+							class = int64(c)
+						}
+					}
+				}
+				if err := setClass(y, x, class); err != nil {
+					return nil, err
+				}
+			}
+		}
+	case [][][]int64, [][][]int32:
+		var rows [][]int64
+		if v64, ok := v.([][][]int64); ok && len(v64) > 0 {
+			rows = v64[0]
+		} else if v32, ok := v.([][][]int32); ok && len(v32) > 0 {
+			for _, row32 := range v32[0] {
+				row := make([]int64, len(row32))
+				for x, c := range row32 {
+					row[x] = int64(c)
+				}
+				rows = append(rows, row)
+			}
+		}
+		if len(rows) == 0 {
+			return nil, fmt.Errorf("%w: empty segmentation output", ErrInvalidConfig)
+		}
+		if seg, err = newMap(len(rows), len(rows[0])); err != nil {
+			return nil, err
+		}
+		for y, row := range rows {
+			if len(row) != seg.width {
+				return nil, fmt.Errorf("%w: segmentation output rows have different widths", ErrInvalidConfig)
+			}
+			for x, class := range row {
+				if err := setClass(y, x, class); err != nil {
+					return nil, err
+				}
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w: segmentation output must be [1, H, W, C] float32 or [1, H, W] integer: %T", ErrInvalidConfig, value)
+	}
+
+	return seg, nil
+}
+
+// encodeMaskPNG 픽셀값이 클래스 index인 grayscale PNG로 인코딩
+func encodeMaskPNG(seg *segmentMap) ([]byte, error) {
+	img := &image.Gray{
+		Pix:    seg.classes,
+		Stride: seg.width,
+		Rect:   image.Rect(0, 0, seg.width, seg.height),
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encodeMaskRLE row-major 순서의 (클래스 index, 연속 된 픽셀 수) 쌍으로 인코딩
+func encodeMaskRLE(seg *segmentMap) []int {
+	var rle []int
+	for idx, class := range seg.classes {
+		if idx > 0 && seg.classes[idx-1] == class {
+			rle[len(rle)-1]++
+			continue
+		}
+		rle = append(rle, int(class), 1)
+	}
+
+	return rle
+}
+
+// segmentResult mask를 encoding으로 인코딩하고 클래스별 비율 계산
+func (m *iModel) segmentResult(seg *segmentMap, encoding string) (*SegmentationResult, error) {
+	counts := make([]int, maxSegmentationLabels)
+	for _, class := range seg.classes {
+		counts[class]++
+	}
+
+	result := &SegmentationResult{
+		Height:   seg.height,
+		Width:    seg.width,
+		Encoding: encoding,
+		Labels:   []SegmentLabel{},
+	}
+	for idx, count := range counts {
+		if count == 0 {
+			continue
+		}
+		if idx >= m.nrLables {
+			return nil, fmt.Errorf("%w: segmentation class %d is out of labels(%d)", ErrInvalidConfig, idx, m.nrLables)
+		}
+		if m.labelInfos[idx].Hidden {
+			continue
+		}
+
+		result.Labels = append(result.Labels, SegmentLabel{
+			Index: idx,
+			Label: m.labels[idx],
+			Group: m.labelInfos[idx].Group,
+			Ratio: float32(count) / float32(len(seg.classes)),
+		})
+	}
+	sort.SliceStable(result.Labels, func(a, b int) bool {
+		return result.Labels[a].Ratio > result.Labels[b].Ratio
+	})
+
+	switch encoding {
+	case MaskPNG:
+		mask, err := encodeMaskPNG(seg)
+		if err != nil {
+			return nil, err
+		}
+		result.Mask = mask
+	case MaskRLE:
+		result.RLE = encodeMaskRLE(seg)
+	}
+
+	return result, nil
+}
+
+// segment 이미지의 픽셀별 클래스를 encoding으로 인코딩 한 mask로 반환
+func (m *iModel) segment(ctx context.Context, image []byte, format string, threshold float32, encoding string) (*SegmentationResult, error) {
+	if m.cfg.Classification != segmentationClass {
+		return nil, fmt.Errorf("%w: %s is not a segmentation model", ErrModelTypeMismatch, m.name)
+	}
+
+	image, format, err := m.preProcess(ctx, image, format)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := m.prepareImage(ctx, image, format)
+	if err != nil {
+		return nil, err
+	}
+
+	seg, err := m.backend.runSegmentation(ctx, input, m.cfg.threshold(threshold))
+	if err != nil {
+		return nil, err
+	}
+
+	return m.segmentResult(seg, encoding)
+}
+
+// Segment segmentation 모델로 이미지의 픽셀별 클래스를 mask로 반환
+//
+// encoding은 MaskPNG(기본값) 또는 MaskRLE이며, threshold는 출력이 foreground 확률 하나인 모델의 기준
+// (기본값: 모델 config의 threshold 또는 0.5)
+func (i *Inference) Segment(ctx context.Context, model string, image []byte, format string, threshold float32, encoding string) (result *SegmentationResult, err error) {
+	switch encoding {
+	case "":
+		encoding = MaskPNG
+	case MaskPNG, MaskRLE:
+	default:
+		return nil, fmt.Errorf("Invalid mask encoding: %q (%s, %s)", encoding, MaskPNG, MaskRLE)
+	}
+
+	if err := i.authorize(ctx, ActionInfer, model); err != nil {
+		return nil, err
+	}
+
+	t0 := time.Now()
+	defer func() {
+		i.observeInference(model, 1, t0, err)
+	}()
+
+	if err := i.imageLimits.check(image); err != nil {
+		return nil, err
+	}
+
+	i.rwMutex.RLock()
+	m := i.getModel(model)
+	i.rwMutex.RUnlock()
+
+	if m == nil {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, model)
+	}
+	defer i.putModel(m)
+
+	if atomic.LoadInt32(&m.status) != modelStatusRun {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotReady, model)
+	}
+	if err := i.checkEnabled(m); err != nil {
+		return nil, err
+	}
+	recordServed(ctx, m)
+
+	return m.segment(ctx, image, format, threshold, encoding)
+}
@@ -0,0 +1,99 @@
+package inference
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func TestParseSegmentation(t *testing.T) {
+	// [1, 2, 2, 3] 클래스별 점수
+	scores := [][][][]float32{{
+		{{0.1, 0.8, 0.1}, {0.7, 0.2, 0.1}},
+		{{0.2, 0.2, 0.6}, {0.1, 0.8, 0.1}},
+	}}
+	seg, err := parseSegmentation(scores, activationNone, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seg.height != 2 || seg.width != 2 || !bytes.Equal(seg.classes, []uint8{1, 0, 2, 1}) {
+		t.Fatalf("Unexpected segment map: %+v", seg)
+	}
+
+	// foreground logit 하나는 활성화 함수를 적용한 확률을 threshold와 비교
+	logits := [][][][]float32{{{{2}, {-2}}}}
+	if seg, err := parseSegmentation(logits, activationSigmoid, 0.5); err != nil || !bytes.Equal(seg.classes, []uint8{1, 0}) {
+		t.Fatalf("Unexpected foreground segment map: %+v, %v", seg, err)
+	}
+
+	// [1, H, W] 클래스 index
+	if seg, err := parseSegmentation([][][]int64{{{0, 3}, {3, 1}}}, activationNone, 0.5); err != nil || !bytes.Equal(seg.classes, []uint8{0, 3, 3, 1}) {
+		t.Fatalf("Unexpected class index segment map: %+v, %v", seg, err)
+	}
+
+	if _, err := parseSegmentation([][][]int32{{{0, 300}}}, activationNone, 0.5); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Class over 255 should fail: %v", err)
+	}
+	if _, err := parseSegmentation([][]float32{{0.1}}, activationNone, 0.5); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Unexpected output shape should fail: %v", err)
+	}
+}
+
+func TestSegmentResult(t *testing.T) {
+	labels := []labelInfo{
+		{Name: "background", Hidden: true},
+		{Name: "person"},
+		{Name: "car", Group: "vehicles"},
+	}
+	m := &iModel{
+		cfg:        modelConfig{Classification: segmentationClass},
+		nrLables:   len(labels),
+		labels:     labelNames(labels),
+		labelInfos: labels,
+	}
+	seg := &segmentMap{height: 2, width: 3, classes: []uint8{0, 0, 1, 2, 2, 2}}
+
+	result, err := m.segmentResult(seg, MaskPNG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Labels) != 2 || result.Labels[0].Label != "car" || result.Labels[0].Ratio != 0.5 || result.Labels[1].Index != 1 {
+		t.Fatalf("Unexpected labels: %+v", result.Labels)
+	}
+
+	mask, err := png.Decode(bytes.NewReader(result.Mask))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gray, ok := mask.(*image.Gray)
+	if !ok || gray.Bounds().Dx() != 3 || gray.Bounds().Dy() != 2 || gray.GrayAt(0, 1).Y != 2 {
+		t.Fatalf("Unexpected mask: %T %v", mask, mask.Bounds())
+	}
+
+	result, err = m.segmentResult(seg, MaskRLE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Mask != nil || len(result.RLE) != 6 || result.RLE[0] != 0 || result.RLE[1] != 2 || result.RLE[5] != 3 {
+		t.Fatalf("Unexpected RLE: %v", result.RLE)
+	}
+
+	if _, err := m.segmentResult(&segmentMap{height: 1, width: 1, classes: []uint8{5}}, MaskPNG); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Class out of labels should fail: %v", err)
+	}
+}
+
+func TestSegmentModelTypeMismatch(t *testing.T) {
+	m := &iModel{name: "pets", cfg: modelConfig{Classification: binaryClass}}
+	if _, err := m.segment(context.Background(), nil, "", 0, MaskPNG); !errors.Is(err, ErrModelTypeMismatch) {
+		t.Fatalf("Classification model should not segment: %v", err)
+	}
+
+	m = &iModel{name: "roads", cfg: modelConfig{Classification: segmentationClass}}
+	if err := m.checkClassifier(); !errors.Is(err, ErrModelTypeMismatch) {
+		t.Fatalf("Segmentation model should not classify: %v", err)
+	}
+}
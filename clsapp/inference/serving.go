@@ -0,0 +1,61 @@
+package inference
+
+import (
+	"context"
+	"sync"
+)
+
+// ServedModel 추론 요청을 처리한 모델
+type ServedModel struct {
+	Model   string `json:"model"`
+	Version int    `json:"version"`
+}
+
+// Serving 추론 요청을 처리한 모델과 버전
+//
+// ContextWithServing으로 지정한 경우에만 기록하며, 여러 모델로 추론하면(compare, pipeline 등) 처리한 순서로 기록
+type Serving struct {
+	mutex  sync.Mutex
+	models []ServedModel
+}
+
+type servingKey struct{}
+
+// ContextWithServing 추론 요청을 처리한 모델을 s에 기록하도록 지정한 ctx 반환
+func ContextWithServing(ctx context.Context, s *Serving) context.Context {
+	return context.WithValue(ctx, servingKey{}, s)
+}
+
+// recordServed ctx에 Serving이 있으면 요청을 처리한 모델을 기록 (같은 모델과 버전은 한번만 기록)
+func recordServed(ctx context.Context, m *iModel) {
+	s, ok := ctx.Value(servingKey{}).(*Serving)
+	if !ok || s == nil {
+		return
+	}
+
+	served := ServedModel{
+		Model:   m.name,
+		Version: m.version,
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, model := range s.models {
+		if model == served {
+			return
+		}
+	}
+	s.models = append(s.models, served)
+}
+
+// Models 기록 된 모델 (기록 된 모델이 없으면 빈 slice)
+func (s *Serving) Models() []ServedModel {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	models := make([]ServedModel, len(s.models))
+	copy(models, s.models)
+
+	return models
+}
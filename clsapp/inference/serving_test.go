@@ -0,0 +1,40 @@
+package inference
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestServing(t *testing.T) {
+	// Serving이 없으면 기록하지 않음
+	recordServed(context.Background(), &iModel{name: "flowers", version: 1})
+
+	// backend 없는 모델이라 캐시 된 결과로 추론
+	m := &iModel{name: "flowers", version: 3, loadID: 1, status: modelStatusRun}
+	i := &Inference{
+		models:      map[string]*iModel{"flowers": m},
+		resultCache: newResultCache(10, 0),
+	}
+	i.resultCache.put(m.resultKey(context.Background(), []byte("roses"), "jpeg", 3, 0), &RawInference{})
+
+	s := &Serving{}
+	ctx := ContextWithServing(context.Background(), s)
+	for n := 0; n < 2; n++ {
+		if _, err := i.InferRaw(ctx, "flowers", []byte("roses"), "jpeg", 3, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recordServed(ctx, &iModel{name: "pets", version: 1})
+
+	if models := s.Models(); !reflect.DeepEqual(models, []ServedModel{{"flowers", 3}, {"pets", 1}}) {
+		t.Fatalf("Unexpected served models: %v", models)
+	}
+
+	if _, err := i.InferRaw(ContextWithServing(context.Background(), &Serving{}), "none", nil, "", 0, 0); err == nil {
+		t.Fatal("Unknown model should fail")
+	}
+	if models := (&Serving{}).Models(); models == nil || len(models) != 0 {
+		t.Fatalf("Unexpected empty served models: %v", models)
+	}
+}
@@ -0,0 +1,80 @@
+package inference
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 이미지 형식별 파일 시작 bytes
+var formatSignatures = []struct {
+	format string
+	match  func(string) bool
+}{
+	{"jpeg", prefixMatcher("\xff\xd8\xff")},
+	{"png", prefixMatcher("\x89PNG\r\n\x1a\n")},
+	{"gif", func(b string) bool {
+		return strings.HasPrefix(b, "GIF87a") || strings.HasPrefix(b, "GIF89a")
+	}},
+	{"webp", func(b string) bool {
+		return len(b) >= 12 && b[:4] == "RIFF" && b[8:12] == "WEBP"
+	}},
+	{"bmp", prefixMatcher("BM")},
+	{"tiff", func(b string) bool {
+		return strings.HasPrefix(b, "II*\x00") || strings.HasPrefix(b, "MM\x00*")
+	}},
+	{"heic", func(b string) bool {
+		return len(b) >= 12 && b[4:8] == "ftyp" && heicBrands[b[8:12]]
+	}},
+}
+
+func prefixMatcher(prefix string) func(string) bool {
+	return func(b string) bool {
+		return strings.HasPrefix(b, prefix)
+	}
+}
+
+// DetectFormat 이미지의 시작 bytes로 형식 반환 (알 수 없으면 빈 값)
+//
+// 반환 값은 jpeg, png, gif, webp, bmp, tiff, heic 중 하나
+func DetectFormat(image []byte) string {
+	// 형식 판단에는 시작 12 bytes만 필요
+	if len(image) > 12 {
+		image = image[:12]
+	}
+	head := string(image)
+
+	for _, sig := range formatSignatures {
+		if sig.match(head) {
+			return sig.format
+		}
+	}
+
+	return ""
+}
+
+// resolveFormat 요청한 형식을 이미지 내용과 비교하여 사용할 형식 반환
+//
+// 형식을 지정하지 않으면 이미지 내용으로 판단하며,
+// 지정한 형식과 이미지 내용이 다르면 *FormatMismatchError 반환.
+// 내용으로 형식을 알 수 없으면 지정한 형식을 그대로 사용
+func resolveFormat(image []byte, format string) (string, error) {
+	detected := DetectFormat(image)
+
+	if format == "" {
+		if detected == "" {
+			return "", fmt.Errorf("%w: unknown image content", ErrUnsupportedFormat)
+		}
+		return detected, nil
+	}
+
+	declared, err := preprocessFormat(format)
+	if err != nil {
+		return "", err
+	}
+
+	if detected != "" && detected != declared {
+		return "", &FormatMismatchError{Declared: format, Detected: detected}
+	}
+
+	return declared, nil
+}
@@ -0,0 +1,55 @@
+package inference
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := map[string]string{
+		"\xff\xd8\xff\xe0\x00\x10JFIF": "jpeg",
+		"\x89PNG\r\n\x1a\n\x00\x00":    "png",
+		"GIF89a\x01\x00":               "gif",
+		"RIFF\x24\x00\x00\x00WEBPVP8 ": "webp",
+		"BM\x36\x00":                   "bmp",
+		"II*\x00\x08\x00":              "tiff",
+		"\x00\x00\x00\x18ftypheic\x00": "heic",
+		"\x00\x00\x00\x18ftypavif\x00": "",
+		"RIFF\x24\x00\x00\x00WAVEfmt ": "",
+		"image":                        "",
+	}
+
+	for image, format := range tests {
+		if f := DetectFormat([]byte(image)); f != format {
+			t.Errorf("DetectFormat(%q) = %q, want %q", image, f, format)
+		}
+	}
+}
+
+func TestResolveFormat(t *testing.T) {
+	png := "\x89PNG\r\n\x1a\n"
+
+	if f, err := resolveFormat([]byte(png), ""); err != nil || f != "png" {
+		t.Fatalf("Unexpected detected format: %q, %v", f, err)
+	}
+	if f, err := resolveFormat([]byte(png), "PNG"); err != nil || f != "png" {
+		t.Fatalf("Unexpected declared format: %q, %v", f, err)
+	}
+
+	// 내용으로 알 수 없으면 지정한 형식을 사용
+	if f, err := resolveFormat([]byte("image"), "jpg"); err != nil || f != "jpeg" {
+		t.Fatalf("Unexpected declared format: %q, %v", f, err)
+	}
+	if _, err := resolveFormat([]byte("image"), ""); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, err := resolveFormat([]byte(png), "jpg")
+	var mismatch *FormatMismatchError
+	if !errors.As(err, &mismatch) || !errors.Is(err, ErrFormatMismatch) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if mismatch.Declared != "jpg" || mismatch.Detected != "png" {
+		t.Fatalf("Unexpected mismatch: %+v", mismatch)
+	}
+}
@@ -0,0 +1,314 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// 사용자(tenant) 모델 저장 구조
+//
+// learner가 접근할 수 있도록 모델 저장 경로 하위에 두며,
+// 모델 이름은 `.`으로 시작할 수 없으므로 모델 디렉토리와 충돌하지 않음
+//
+//	<modelsPath>/.tenants/
+//	  <tenant>/
+//	    tenant.yaml      사용자 정보와 quota
+//	    <model>-<uuid>/  모델 저장 경로와 같은 구조의 모델 디렉토리
+const (
+	tenantsDir = ".tenants"
+	tenantFile = "tenant.yaml"
+
+	// 사용자 모델 이름의 구분자: <model>@<tenant>
+	tenantSeparator = "@"
+)
+
+// tenant 사용자별 모델 저장 공간
+type tenant struct {
+	Name string `yaml:"name"`
+	// 모델 파일의 최대 크기 (byte, 0 이하면 제한 없음)
+	Quota     int64     `yaml:"quota"`
+	CreatedAt time.Time `yaml:"createdAt"`
+
+	path string
+}
+
+// tenantModelName 사용자 모델의 이름 반환
+func tenantModelName(model, tenant string) string {
+	return model + tenantSeparator + tenant
+}
+
+// splitModelName 모델 이름을 모델과 사용자로 분리 (공용 모델은 빈 사용자)
+func splitModelName(name string) (string, string) {
+	if idx := strings.LastIndex(name, tenantSeparator); idx >= 0 {
+		return name[:idx], name[idx+1:]
+	}
+
+	return name, ""
+}
+
+// usage 사용자 디렉토리의 파일 크기 합
+func (t *tenant) usage() (int64, error) {
+	return dirSize(t.path)
+}
+
+// checkQuota 사용량이 quota를 넘으면 ErrQuotaExceeded 반환
+func (t *tenant) checkQuota() error {
+	if t.Quota <= 0 {
+		return nil
+	}
+
+	usage, err := t.usage()
+	if err != nil {
+		return err
+	}
+	if usage > t.Quota {
+		return fmt.Errorf("%w: %s (%d > %d bytes)", ErrQuotaExceeded, t.Name, usage, t.Quota)
+	}
+
+	return nil
+}
+
+func (i *Inference) tenantsPath() string {
+	return filepath.Join(i.modelsPath, tenantsDir)
+}
+
+// getTenant 이름으로 사용자 반환
+//
+// i.rwMutex를 잡은 상태에서 호출
+func (i *Inference) getTenant(name string) (*tenant, error) {
+	t, ok := i.tenants[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTenantNotFound, name)
+	}
+
+	return t, nil
+}
+
+// tenantModels 사용자의 모델 목록 반환
+//
+// i.rwMutex를 잡은 상태에서 호출
+func (i *Inference) tenantModels(name string) []*iModel {
+	var models []*iModel
+	for _, m := range i.models {
+		if m.tenant == name {
+			models = append(models, m)
+		}
+	}
+
+	return models
+}
+
+// loadTenants 사용자와 사용자 모델 로드
+//
+// 사용자 모델은 사용자가 직접 관리하므로 로드에 실패해도 파일을 지우지 않음
+func (i *Inference) loadTenants() {
+	dirs, _ := ioutil.ReadDir(i.tenantsPath())
+
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+
+		t, err := readTenant(filepath.Join(i.tenantsPath(), dir.Name()))
+		if err != nil {
+			log.Printf("Fail to load tenant(%s): %s", dir.Name(), err)
+			continue
+		}
+		i.tenants[t.Name] = t
+
+		modelDirs, _ := ioutil.ReadDir(t.path)
+		for _, modelDir := range modelDirs {
+			if !modelDir.IsDir() {
+				continue
+			}
+
+			modelPath := filepath.Join(t.path, modelDir.Name())
+			m := getNewModel("", modelPath)
+			if err := i.load(m); err != nil {
+				log.Printf("Fail to load tenant model(%s): %s", modelPath, err)
+				continue
+			}
+
+			// 사용자가 직접 저장한 모델은 config의 이름에 사용자를 붙여 등록
+			if _, owner := splitModelName(m.name); owner != t.Name {
+				m.name = tenantModelName(m.name, t.Name)
+			}
+			m.tenant = t.Name
+
+			if err := i.addModel(m); err != nil {
+				log.Print(err)
+			} else {
+				i.loadVersions(m)
+			}
+		}
+	}
+}
+
+func readTenant(tenantPath string) (*tenant, error) {
+	b, err := ioutil.ReadFile(filepath.Join(tenantPath, tenantFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var t tenant
+	if err := yaml.Unmarshal(b, &t); err != nil {
+		return nil, err
+	}
+	if t.Name != filepath.Base(tenantPath) {
+		return nil, fmt.Errorf("Not matched tenant name[%s] in %s", t.Name, tenantFile)
+	}
+	t.path = tenantPath
+
+	return &t, nil
+}
+
+// CreateTenant 사용자 모델 저장 공간 생성
+//
+// quota가 0 이하면 기본 quota를 사용
+func (i *Inference) CreateTenant(ctx context.Context, name string, quota int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := i.authorize(ctx, ActionManageTenant, name); err != nil {
+		return err
+	}
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+
+	if quota <= 0 {
+		quota = i.tenantQuota
+	}
+
+	i.rwMutex.Lock()
+	defer i.rwMutex.Unlock()
+
+	if _, ok := i.tenants[name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateTenant, name)
+	}
+
+	t := &tenant{
+		Name:      name,
+		Quota:     quota,
+		CreatedAt: time.Now(),
+		path:      filepath.Join(i.tenantsPath(), name),
+	}
+
+	b, err := yaml.Marshal(t)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(t.path, os.ModePerm); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(t.path, tenantFile), b); err != nil {
+		os.RemoveAll(t.path)
+		return err
+	}
+
+	i.tenants[name] = t
+
+	return nil
+}
+
+// DeleteTenant 사용자와 사용자의 모든 모델 삭제
+//
+// 사용 중이거나 생성 중인 모델이 있으면 삭제하지 않음
+func (i *Inference) DeleteTenant(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := i.authorize(ctx, ActionManageTenant, name); err != nil {
+		return err
+	}
+
+	i.rwMutex.Lock()
+	t, err := i.getTenant(name)
+	if err != nil {
+		i.rwMutex.Unlock()
+		return err
+	}
+
+	var models []*iModel
+	for _, m := range i.tenantModels(name) {
+		models = append(append(models, m), i.modelVersions(m.name)...)
+	}
+	for _, m := range models {
+		// 생성 중인 모델은 학습이 끝나면 사용자 모델 경로에 로드 됨
+		if atomic.LoadInt32(&m.status) != modelStatusRun {
+			i.rwMutex.Unlock()
+			return fmt.Errorf("%w: %s is being created", ErrModelInUse, m.name)
+		}
+		if refCount := atomic.LoadInt32(&m.refCount); refCount > 0 {
+			i.rwMutex.Unlock()
+			return fmt.Errorf("%w: %s (%d)", ErrModelInUse, m.name, refCount)
+		}
+	}
+
+	for _, m := range models {
+		m.destroy()
+		delete(i.models, m.name)
+		delete(i.versions, m.name)
+	}
+
+	delete(i.tenants, name)
+	i.rwMutex.Unlock()
+
+	// 사용자 모델 파일 삭제는 다른 요청을 막지 않도록 lock 밖에서 처리
+	return os.RemoveAll(t.path)
+}
+
+// GetTenants 사용자 목록 반환
+func (i *Inference) GetTenants(ctx context.Context) []string {
+	i.rwMutex.RLock()
+	defer i.rwMutex.RUnlock()
+
+	var tenants []string
+	for name := range i.tenants {
+		tenants = append(tenants, name)
+	}
+
+	return tenants
+}
+
+// GetTenant 사용자의 quota, 사용량과 모델 목록 반환
+func (i *Inference) GetTenant(ctx context.Context, name string) (map[string]interface{}, error) {
+	if err := i.authorize(ctx, ActionManageTenant, name); err != nil {
+		return nil, err
+	}
+
+	i.rwMutex.RLock()
+	defer i.rwMutex.RUnlock()
+
+	t, err := i.getTenant(name)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := t.usage()
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]string, 0)
+	for _, m := range i.tenantModels(name) {
+		models = append(models, m.name)
+	}
+
+	return map[string]interface{}{
+		"tenant":    t.Name,
+		"quota":     t.Quota,
+		"usage":     usage,
+		"createdAt": t.CreatedAt,
+		"models":    models,
+	}, nil
+}
@@ -0,0 +1,125 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTenantInference(t *testing.T) *Inference {
+	return &Inference{
+		models:     make(map[string]*iModel),
+		modelsPath: newModelsPath(t),
+		tenants:    make(map[string]*tenant),
+	}
+}
+
+func TestSplitModelName(t *testing.T) {
+	tests := []struct {
+		name   string
+		model  string
+		tenant string
+	}{
+		{"pets", "pets", ""},
+		{"pets@alice", "pets", "alice"},
+		{"pets@", "pets", ""},
+	}
+
+	for _, tt := range tests {
+		if model, tenant := splitModelName(tt.name); model != tt.model || tenant != tt.tenant {
+			t.Errorf("splitModelName(%q) = %q, %q", tt.name, model, tenant)
+		}
+	}
+}
+
+func TestTenant(t *testing.T) {
+	i := newTenantInference(t)
+	defer os.RemoveAll(i.modelsPath)
+
+	ctx := context.Background()
+	if err := i.CreateTenant(ctx, "alice", 16); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.CreateTenant(ctx, "alice", 16); !errors.Is(err, ErrDuplicateTenant) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := i.CreateTenant(ctx, "../alice", 16); !errors.Is(err, ErrInvalidName) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// 재시작 후에도 사용자 정보 유지
+	loaded := &Inference{
+		models:     make(map[string]*iModel),
+		modelsPath: i.modelsPath,
+		tenants:    make(map[string]*tenant),
+	}
+	if err := loaded.loadModels(); err != nil {
+		t.Fatal(err)
+	}
+	info, err := loaded.GetTenant(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info["quota"] != int64(16) {
+		t.Fatalf("Unexpected tenant: %v", info)
+	}
+
+	// quota를 넘으면 모델을 생성하지 않음
+	tenantPath := filepath.Join(i.tenantsPath(), "alice")
+	if err := ioutil.WriteFile(filepath.Join(tenantPath, "large"), make([]byte, 32), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i.CreateModel(ctx, "pets@alice", "", "", 1, false); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := i.CreateModel(ctx, "pets@bob", "", "", 1, false); !errors.Is(err, ErrTenantNotFound) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestDeleteTenant(t *testing.T) {
+	i := newTenantInference(t)
+	defer os.RemoveAll(i.modelsPath)
+
+	ctx := context.Background()
+	if err := i.CreateTenant(ctx, "alice", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	tenantPath := filepath.Join(i.tenantsPath(), "alice")
+	m := getNewModel(tenantModelName("pets", "alice"), filepath.Join(tenantPath, "pets-1234"))
+	m.tenant = "alice"
+	if err := i.addModel(m); err != nil {
+		t.Fatal(err)
+	}
+
+	// 생성 중인 모델이 있으면 삭제하지 않음
+	m.status = modelStatusBuild
+	if err := i.DeleteTenant(ctx, "alice"); !errors.Is(err, ErrModelInUse) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	m.status = modelStatusRun
+
+	// 사용 중인 모델이 있으면 삭제하지 않음
+	i.getModel(m.name)
+	if err := i.DeleteTenant(ctx, "alice"); !errors.Is(err, ErrModelInUse) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	i.putModel(m)
+
+	if err := i.DeleteTenant(ctx, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if len(i.models) != 0 || len(i.tenants) != 0 {
+		t.Fatalf("Tenant remains: %v, %v", i.models, i.tenants)
+	}
+	if _, err := os.Stat(tenantPath); !os.IsNotExist(err) {
+		t.Fatalf("Tenant path should be removed: %v", err)
+	}
+	if err := i.DeleteTenant(ctx, "alice"); !errors.Is(err, ErrTenantNotFound) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
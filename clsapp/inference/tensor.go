@@ -0,0 +1,58 @@
+package inference
+
+import (
+	"fmt"
+	"math"
+)
+
+// tensorShape batch를 제외한 모델 입력 tensor의 shape (inputLayout 순서)
+func (cfg *modelConfig) tensorShape() []int {
+	h, w, c := int(cfg.InputShape[0]), int(cfg.InputShape[1]), int(cfg.InputShape[2])
+	if cfg.inputLayout() == layoutNCHW {
+		return []int{c, h, w}
+	}
+
+	return []int{h, w, c}
+}
+
+// checkTensor 전처리 된 입력이 모델 입력 tensor와 맞는지 검사
+//
+// shape은 batch를 제외한 tensorShape과 같아야 하며, uint8 모델의 값은 [0, 255]의 정수
+func (cfg *modelConfig) checkTensor(data []float32, shape []int) error {
+	expected := cfg.tensorShape()
+	if !equalShape(shape, expected) {
+		return fmt.Errorf("%w: shape %v, expected %v (%s)", ErrInvalidTensor, shape, expected, cfg.inputLayout())
+	}
+
+	size := 1
+	for _, d := range expected {
+		size *= d
+	}
+	if len(data) != size {
+		return fmt.Errorf("%w: %d values, expected %d", ErrInvalidTensor, len(data), size)
+	}
+
+	for idx, v := range data {
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return fmt.Errorf("%w: value %d is not finite", ErrInvalidTensor, idx)
+		}
+		if cfg.inputDType() == inputUint8 && (v < 0 || v > 255 || v != float32(math.Trunc(float64(v)))) {
+			return fmt.Errorf("%w: value %d(%v) must be an integer in [0, 255] for uint8 input", ErrInvalidTensor, idx, v)
+		}
+	}
+
+	return nil
+}
+
+func equalShape(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for idx := range a {
+		if a[idx] != b[idx] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,36 @@
+package inference
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckTensor(t *testing.T) {
+	nhwc := &modelConfig{InputShape: []int32{2, 3, 1}}
+	nchw := &modelConfig{InputShape: []int32{2, 3, 3}, InputLayout: layoutNCHW}
+	quantized := &modelConfig{InputShape: []int32{1, 2, 1}, InputDType: inputUint8}
+
+	tests := []struct {
+		cfg   *modelConfig
+		data  []float32
+		shape []int
+		valid bool
+	}{
+		{nhwc, make([]float32, 6), []int{2, 3, 1}, true},
+		{nhwc, make([]float32, 6), []int{3, 2, 1}, false},
+		{nhwc, make([]float32, 5), []int{2, 3, 1}, false},
+		{nhwc, make([]float32, 6), []int{1, 2, 3, 1}, false},
+		{nchw, make([]float32, 18), []int{3, 2, 3}, true},
+		{nchw, make([]float32, 18), []int{2, 3, 3}, false},
+		{quantized, []float32{0, 255}, []int{1, 2, 1}, true},
+		{quantized, []float32{0, 256}, []int{1, 2, 1}, false},
+		{quantized, []float32{0, 1.5}, []int{1, 2, 1}, false},
+	}
+
+	for idx, tt := range tests {
+		err := tt.cfg.checkTensor(tt.data, tt.shape)
+		if (err == nil) != tt.valid || (err != nil && !errors.Is(err, ErrInvalidTensor)) {
+			t.Errorf("%d: checkTensor(%v) = %v", idx, tt.shape, err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+)
+
+// formatTFLite TensorFlow Lite 모델 (quantized 모델 등 SavedModel보다 메모리를 적게 사용)
+const formatTFLite = "tflite"
+
+// tfliteModelFile 모델 버전 디렉토리에서 TensorFlow Lite 모델을 읽는 파일
+const tfliteModelFile = "model.tflite"
+
+// TFLiteModel 로드 된 TensorFlow Lite 모델
+//
+// 모델의 maxConcurrentRuns 이내에서 여러 goroutine이 동시에 호출 할 수 있음
+type TFLiteModel interface {
+	// Run 모델 입력 하나(batch 제외, inputLayout 순서로 펼친 값)를 실행하여 첫 번째 출력 반환
+	//
+	// uint8 입력 모델의 값은 [0, 255]의 정수이며, quantized 출력은 dequantize 하여 반환
+	Run(ctx context.Context, input []float32) ([]float32, error)
+	// Close 모델 해제
+	Close() error
+}
+
+// TFLiteRuntime .tflite 파일을 로드하는 TensorFlow Lite 실행 엔진 (예: cgo TensorFlow Lite C API binding)
+//
+// tflite build tag로 빌드하면 TensorFlow Lite C API(libtensorflowlite_c)를 사용하며, 다른 binding은 WithTFLiteRuntime으로 지정
+type TFLiteRuntime interface {
+	Load(modelFile string) (TFLiteModel, error)
+}
+
+// TFLiteRuntimeFunc 함수를 TFLiteRuntime으로 사용
+type TFLiteRuntimeFunc func(modelFile string) (TFLiteModel, error)
+
+// Load f(modelFile) 호출
+func (f TFLiteRuntimeFunc) Load(modelFile string) (TFLiteModel, error) {
+	return f(modelFile)
+}
+
+// errNoTFLiteRuntime TFLiteRuntime 없이 TensorFlow Lite 모델을 로드
+var errNoTFLiteRuntime = errors.New("TFLite runtime is not set")
+
+// defaultTFLiteRuntime WithTFLiteRuntime을 지정하지 않은 경우 사용하는 실행 엔진 (tflite build tag로 빌드하면 TensorFlow Lite C API)
+var defaultTFLiteRuntime TFLiteRuntime
+
+// tfliteBackend TFLiteRuntime으로 model.tflite를 로드하는 Backend
+type tfliteBackend struct {
+	runtime TFLiteRuntime
+}
+
+func (b tfliteBackend) LoadModel(modelPath string, cfg BackendConfig) (BackendModel, error) {
+	lite, err := b.runtime.Load(filepath.Join(modelPath, tfliteModelFile))
+	if err != nil {
+		return nil, err
+	}
+
+	return tfliteModel{lite}, nil
+}
+
+// tfliteModel TFLiteModel을 BackendModel로 사용
+type tfliteModel struct {
+	TFLiteModel
+}
+
+func (m tfliteModel) Infer(ctx context.Context, input []float32) ([]float32, error) {
+	return m.Run(ctx, input)
+}
@@ -0,0 +1,176 @@
+//go:build tflite
+// +build tflite
+
+package inference
+
+/*
+#cgo LDFLAGS: -ltensorflowlite_c
+#include <stdlib.h>
+#include "tensorflow/lite/c/c_api.h"
+*/
+import "C"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+func init() {
+	defaultTFLiteRuntime = cTFLiteRuntime{}
+}
+
+// cTFLiteRuntime TensorFlow Lite C API(libtensorflowlite_c)로 모델을 로드하는 실행 엔진
+type cTFLiteRuntime struct{}
+
+func (cTFLiteRuntime) Load(modelFile string) (TFLiteModel, error) {
+	cFile := C.CString(modelFile)
+	defer C.free(unsafe.Pointer(cFile))
+
+	m := &cTFLiteModel{}
+	if m.model = C.TfLiteModelCreateFromFile(cFile); m.model == nil {
+		return nil, fmt.Errorf("Fail to read TFLite model: %s", modelFile)
+	}
+
+	m.options = C.TfLiteInterpreterOptionsCreate()
+	C.TfLiteInterpreterOptionsSetNumThreads(m.options, C.int32_t(runtime.NumCPU()))
+
+	if m.interpreter = C.TfLiteInterpreterCreate(m.model, m.options); m.interpreter == nil {
+		m.Close()
+		return nil, fmt.Errorf("Fail to create TFLite interpreter: %s", modelFile)
+	}
+	if C.TfLiteInterpreterAllocateTensors(m.interpreter) != C.kTfLiteOk {
+		m.Close()
+		return nil, fmt.Errorf("Fail to allocate TFLite tensors: %s", modelFile)
+	}
+
+	return m, nil
+}
+
+// cTFLiteModel TensorFlow Lite interpreter
+//
+// interpreter는 동시에 실행할 수 없으므로 Run은 순서대로 실행 됨
+type cTFLiteModel struct {
+	mutex       sync.Mutex
+	model       *C.TfLiteModel
+	options     *C.TfLiteInterpreterOptions
+	interpreter *C.TfLiteInterpreter
+}
+
+func (m *cTFLiteModel) Run(ctx context.Context, input []float32) ([]float32, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.interpreter == nil {
+		return nil, errors.New("TFLite model is closed")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	in := C.TfLiteInterpreterGetInputTensor(m.interpreter, 0)
+	if err := copyTFLiteInput(in, input); err != nil {
+		return nil, err
+	}
+
+	if C.TfLiteInterpreterInvoke(m.interpreter) != C.kTfLiteOk {
+		return nil, errors.New("Fail to invoke TFLite interpreter")
+	}
+
+	return copyTFLiteOutput(C.TfLiteInterpreterGetOutputTensor(m.interpreter, 0))
+}
+
+// copyTFLiteInput 입력 tensor의 타입으로 변환하여 복사 (int8은 quantize)
+func copyTFLiteInput(t *C.TfLiteTensor, input []float32) error {
+	size := int(C.TfLiteTensorByteSize(t))
+
+	var (
+		buf unsafe.Pointer
+		n   int
+	)
+	switch typ := C.TfLiteTensorType(t); typ {
+	case C.kTfLiteFloat32:
+		buf, n = unsafe.Pointer(&input[0]), len(input)*4
+	case C.kTfLiteUInt8:
+		data := make([]uint8, len(input))
+		for idx, v := range input {
+			data[idx] = uint8(v)
+		}
+		buf, n = unsafe.Pointer(&data[0]), len(data)
+	case C.kTfLiteInt8:
+		q := C.TfLiteTensorQuantizationParams(t)
+		data := make([]int8, len(input))
+		for idx, v := range input {
+			data[idx] = int8(math.Max(-128, math.Min(127, math.Round(float64(v)/float64(q.scale))+float64(q.zero_point))))
+		}
+		buf, n = unsafe.Pointer(&data[0]), len(data)
+	default:
+		return fmt.Errorf("Unsupported TFLite input type: %d", typ)
+	}
+
+	if n != size {
+		return fmt.Errorf("Unexpected TFLite input size: %d, expected %d", n, size)
+	}
+	if C.TfLiteTensorCopyFromBuffer(t, buf, C.size_t(n)) != C.kTfLiteOk {
+		return errors.New("Fail to copy TFLite input")
+	}
+
+	return nil
+}
+
+// copyTFLiteOutput 출력 tensor를 float32로 복사 (uint8, int8은 dequantize)
+func copyTFLiteOutput(t *C.TfLiteTensor) ([]float32, error) {
+	size := int(C.TfLiteTensorByteSize(t))
+	if size == 0 {
+		return nil, errors.New("Empty TFLite output")
+	}
+
+	buf := make([]byte, size)
+	if C.TfLiteTensorCopyToBuffer(t, unsafe.Pointer(&buf[0]), C.size_t(size)) != C.kTfLiteOk {
+		return nil, errors.New("Fail to copy TFLite output")
+	}
+
+	switch typ := C.TfLiteTensorType(t); typ {
+	case C.kTfLiteFloat32:
+		output := make([]float32, size/4)
+		copy(output, (*[1 << 28]float32)(unsafe.Pointer(&buf[0]))[:size/4:size/4])
+		return output, nil
+	case C.kTfLiteUInt8, C.kTfLiteInt8:
+		q := C.TfLiteTensorQuantizationParams(t)
+		output := make([]float32, size)
+		for idx, b := range buf {
+			v := float32(b)
+			if typ == C.kTfLiteInt8 {
+				v = float32(int8(b))
+			}
+			output[idx] = (v - float32(q.zero_point)) * float32(q.scale)
+		}
+		return output, nil
+	default:
+		return nil, fmt.Errorf("Unsupported TFLite output type: %d", typ)
+	}
+}
+
+func (m *cTFLiteModel) Close() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.interpreter != nil {
+		C.TfLiteInterpreterDelete(m.interpreter)
+		m.interpreter = nil
+	}
+	if m.options != nil {
+		C.TfLiteInterpreterOptionsDelete(m.options)
+		m.options = nil
+	}
+	if m.model != nil {
+		C.TfLiteModelDelete(m.model)
+		m.model = nil
+	}
+
+	return nil
+}
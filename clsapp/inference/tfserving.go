@@ -0,0 +1,555 @@
+package inference
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// formatTFServing 모델 파일 없이 원격 TensorFlow Serving에서 실행하는 모델
+const formatTFServing = "tf_serving"
+
+const (
+	// DefaultRemoteTimeoutSeconds TensorFlow Serving, Triton 요청의 기본 제한 시간
+	DefaultRemoteTimeoutSeconds = 10
+	defaultTFServingSignature   = "serving_default"
+)
+
+// 원격 추론 서버에 요청하는 protocol
+const (
+	protocolGRPC = "grpc"
+	protocolHTTP = "http"
+)
+
+// TensorFlow Serving gRPC method와 값
+const (
+	tfServingPredict        = "/tensorflow.serving.PredictionService/Predict"
+	tfServingGetModelStatus = "/tensorflow.serving.ModelService/GetModelStatus"
+	// tfServingAvailable ModelVersionStatus.State의 AVAILABLE
+	tfServingAvailable = 30
+	// TensorProto의 DataType
+	tfDTypeFloat  = 1
+	tfDTypeDouble = 2
+	tfDTypeUint8  = 4
+)
+
+// remoteTimeout 원격 추론 서버 요청 제한 시간 (timeoutSeconds가 0이면 기본값)
+func remoteTimeout(timeoutSeconds int) time.Duration {
+	if timeoutSeconds == 0 {
+		return DefaultRemoteTimeoutSeconds * time.Second
+	}
+
+	return time.Duration(timeoutSeconds) * time.Second
+}
+
+// remoteClient 원격 추론 서버에 HTTP로 요청하는 client
+func remoteClient(timeoutSeconds int) *http.Client {
+	return &http.Client{Timeout: remoteTimeout(timeoutSeconds)}
+}
+
+// remoteError 원격 추론 서버 요청 에러
+//
+// ctx가 종료되지 않았는데 요청하지 못했으면 ErrBackendUnavailable
+func remoteError(ctx context.Context, model string, err error) error {
+	var ge *grpcError
+	switch {
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case errors.As(err, &ge) && ge.code != grpcUnavailable, errors.Is(err, ErrBackendUnavailable):
+		return err
+	default:
+		return fmt.Errorf("%w(%s): %s", ErrBackendUnavailable, model, err)
+	}
+}
+
+// tfServingConfig `format: tf_serving` 모델이 추론을 요청할 TensorFlow Serving
+type tfServingConfig struct {
+	// TensorFlow Serving 주소 (host:port, 예: gRPC는 gpu-box:8500, REST는 gpu-box:8501)
+	Host string `yaml:"host"`
+	// 요청 protocol (grpc 또는 http, 기본값: grpc)
+	Protocol string `yaml:"protocol"`
+	// TLS 사용 (기본값: false)
+	TLS bool `yaml:"tls"`
+	// TensorFlow Serving의 모델 이름
+	ModelName string `yaml:"modelName"`
+	// 모델 버전 (기본값: 0, TensorFlow Serving이 사용하는 최신 버전)
+	Version int64 `yaml:"version"`
+	// signature 이름 (기본값: serving_default)
+	SignatureName string `yaml:"signatureName"`
+	// 요청 제한 시간 (기본값: 10)
+	TimeoutSeconds int `yaml:"timeoutSeconds"`
+}
+
+func (s *tfServingConfig) protocol() string {
+	if s.Protocol == "" {
+		return protocolGRPC
+	}
+
+	return s.Protocol
+}
+
+func (s *tfServingConfig) signature() string {
+	if s.SignatureName == "" {
+		return defaultTFServingSignature
+	}
+
+	return s.SignatureName
+}
+
+// validateTFServing TensorFlow Serving 설정 검사
+//
+// gRPC Predict는 입력을 이름으로 지정하므로 inputOperationName(signature의 입력 이름)이 필요
+func (cfg *modelConfig) validateTFServing() []string {
+	s := cfg.TFServing
+	if cfg.modelFormat() != formatTFServing {
+		if s != nil {
+			return []string{fmt.Sprintf("`tfServing` must be used with %s format", formatTFServing)}
+		}
+		return nil
+	}
+	if s == nil {
+		return []string{fmt.Sprintf("`tfServing` is required for %s format", formatTFServing)}
+	}
+
+	var violations []string
+	if s.Host == "" {
+		violations = append(violations, "`tfServing.host` is required")
+	}
+	switch s.protocol() {
+	case protocolGRPC:
+		if cfg.InputOperationName == "" {
+			violations = append(violations, fmt.Sprintf("`inputOperationName` is required for %s %s protocol", formatTFServing, protocolGRPC))
+		}
+	case protocolHTTP:
+	default:
+		violations = append(violations, fmt.Sprintf("`tfServing.protocol` must be %s or %s: %q", protocolGRPC, protocolHTTP, s.Protocol))
+	}
+	if s.ModelName == "" {
+		violations = append(violations, "`tfServing.modelName` is required")
+	}
+	if s.Version < 0 {
+		violations = append(violations, fmt.Sprintf("`tfServing.version` must not be negative: %d", s.Version))
+	}
+	if s.TimeoutSeconds < 0 {
+		violations = append(violations, fmt.Sprintf("`tfServing.timeoutSeconds` must not be negative: %d", s.TimeoutSeconds))
+	}
+
+	return violations
+}
+
+// tfServingBackend TensorFlow Serving의 Predict API(gRPC 또는 REST)로 추론을 요청하는 Backend
+//
+// 이미지 전처리, labels와 모델 관리는 이 서버에서 하고 모델 실행만 GPU 서버 등의 TensorFlow Serving에서 처리
+type tfServingBackend struct {
+	cfg tfServingConfig
+}
+
+func (b tfServingBackend) LoadModel(modelPath string, cfg BackendConfig) (BackendModel, error) {
+	m := newTFServingModel(b.cfg, cfg)
+
+	// 모델을 사용할 수 없으면 로드 실패 (ErrBackendUnavailable)
+	if err := m.checkAvailable(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// tfServingModel TensorFlow Serving의 모델
+type tfServingModel struct {
+	cfg    tfServingConfig
+	client *http.Client
+	grpc   *grpcClient
+	// Predict 입력 이름과 instance의 shape (batch 제외, inputLayout 순서)
+	input string
+	shape []int
+	uint8 bool
+	// 출력이 여러 개인 경우 사용할 출력 이름
+	output string
+}
+
+func newTFServingModel(s tfServingConfig, cfg BackendConfig) *tfServingModel {
+	m := &tfServingModel{
+		cfg:    s,
+		input:  cfg.InputOperationName,
+		shape:  instanceShape(cfg),
+		uint8:  cfg.InputDType == inputUint8,
+		output: cfg.OutputOperationName,
+	}
+	if s.protocol() == protocolGRPC {
+		m.grpc = newGRPCClient(s.Host, s.TimeoutSeconds, s.TLS)
+	} else {
+		m.client = remoteClient(s.TimeoutSeconds)
+	}
+
+	return m
+}
+
+// url TensorFlow Serving 모델(버전)의 REST API 주소
+func (m *tfServingModel) url() string {
+	scheme := "http"
+	if m.cfg.TLS {
+		scheme = "https"
+	}
+
+	url := fmt.Sprintf("%s://%s/v1/models/%s", scheme, m.cfg.Host, m.cfg.ModelName)
+	if m.cfg.Version > 0 {
+		url += fmt.Sprintf("/versions/%d", m.cfg.Version)
+	}
+
+	return url
+}
+
+// checkAvailable 모델 상태 API로 사용할 수 있는 버전이 있는지 확인
+//
+// 연결하지 못했거나 사용할 수 있는 버전이 없으면 ErrBackendUnavailable
+func (m *tfServingModel) checkAvailable() error {
+	var (
+		available bool
+		err       error
+	)
+	if m.grpc != nil {
+		available, err = m.grpcAvailable()
+	} else {
+		available, err = m.restAvailable()
+	}
+	if err != nil {
+		return fmt.Errorf("%w: Fail to get TF Serving model status(%s): %s", ErrBackendUnavailable, m.cfg.ModelName, err)
+	}
+	if !available {
+		return fmt.Errorf("%w: TF Serving model is not available: %s", ErrBackendUnavailable, m.cfg.ModelName)
+	}
+
+	return nil
+}
+
+func (m *tfServingModel) restAvailable() (bool, error) {
+	res, err := m.client.Get(m.url())
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+
+	var status struct {
+		ModelVersionStatus []struct {
+			Version string `json:"version"`
+			State   string `json:"state"`
+		} `json:"model_version_status"`
+		Error string `json:"error"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&status)
+	if err == nil && res.StatusCode/100 != 2 {
+		err = fmt.Errorf("%s (%s)", status.Error, res.Status)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	for _, s := range status.ModelVersionStatus {
+		if s.State == "AVAILABLE" {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// grpcAvailable GetModelStatus의 model_version_status 중 AVAILABLE 상태가 있는지 확인
+func (m *tfServingModel) grpcAvailable() (bool, error) {
+	response, err := m.grpc.invoke(context.Background(), tfServingGetModelStatus, appendProtoBytes(nil, 1, m.modelSpec(false)))
+	if err != nil {
+		return false, err
+	}
+
+	fields, err := parseProto(response)
+	if err != nil {
+		return false, err
+	}
+	for _, f := range fields {
+		if f.num != 1 || f.wire != protoBytes {
+			continue
+		}
+		status, err := parseProto(f.bytes)
+		if err != nil {
+			return false, err
+		}
+		for _, s := range status {
+			if s.num == 2 && s.wire == protoVarint && s.value == tfServingAvailable {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// modelSpec ModelSpec message (name, version, signature_name)
+func (m *tfServingModel) modelSpec(signature bool) []byte {
+	spec := appendProtoString(nil, 1, m.cfg.ModelName)
+	if m.cfg.Version > 0 {
+		// google.protobuf.Int64Value
+		spec = appendProtoBytes(spec, 2, appendProtoUint(nil, 1, uint64(m.cfg.Version)))
+	}
+	if signature {
+		spec = appendProtoString(spec, 3, m.cfg.signature())
+	}
+
+	return spec
+}
+
+// Infer 입력 하나를 batch 1로 Predict 요청하여 출력 반환
+func (m *tfServingModel) Infer(ctx context.Context, input []float32) ([]float32, error) {
+	if m.grpc != nil {
+		return m.grpcPredict(ctx, input)
+	}
+
+	return m.restPredict(ctx, input)
+}
+
+// grpcPredict PredictionService/Predict로 입력을 TensorProto로 요청
+func (m *tfServingModel) grpcPredict(ctx context.Context, input []float32) ([]float32, error) {
+	var shape []byte
+	for _, d := range append([]int{1}, m.shape...) {
+		shape = appendProtoBytes(shape, 2, appendProtoUint(nil, 1, uint64(d)))
+	}
+
+	var tensor []byte
+	if m.uint8 {
+		tensor = appendProtoUint(tensor, 1, tfDTypeUint8)
+		tensor = appendProtoBytes(tensor, 2, shape)
+		tensor = appendProtoBytes(tensor, 4, appendUint8s(nil, input))
+	} else {
+		tensor = appendProtoUint(tensor, 1, tfDTypeFloat)
+		tensor = appendProtoBytes(tensor, 2, shape)
+		tensor = appendProtoBytes(tensor, 4, appendFloat32s(nil, input))
+	}
+
+	request := appendProtoBytes(nil, 1, m.modelSpec(true))
+	request = appendProtoBytes(request, 2, appendProtoBytes(appendProtoString(nil, 1, m.input), 2, tensor))
+	if m.output != "" {
+		request = appendProtoString(request, 3, m.output)
+	}
+
+	response, err := m.grpc.invoke(ctx, tfServingPredict, request)
+	if err != nil {
+		return nil, fmt.Errorf("Fail to predict with TF Serving model(%s): %w", m.cfg.ModelName, remoteError(ctx, m.cfg.ModelName, err))
+	}
+
+	// PredictResponse의 outputs(map<string, TensorProto>)
+	fields, err := parseProto(response)
+	if err != nil {
+		return nil, err
+	}
+	outputs := make(map[string][]byte)
+	for _, f := range fields {
+		if f.num != 1 || f.wire != protoBytes {
+			continue
+		}
+		entry, err := parseProto(f.bytes)
+		if err != nil {
+			return nil, err
+		}
+		var (
+			key   string
+			value []byte
+		)
+		for _, e := range entry {
+			switch e.num {
+			case 1:
+				key = string(e.bytes)
+			case 2:
+				value = e.bytes
+			}
+		}
+		outputs[key] = value
+	}
+
+	var tensorProto []byte
+	switch {
+	case m.output != "":
+		var ok bool
+		if tensorProto, ok = outputs[m.output]; !ok {
+			return nil, fmt.Errorf("Output is not in TF Serving predictions: %s", m.output)
+		}
+	case len(outputs) == 1:
+		for _, output := range outputs {
+			tensorProto = output
+		}
+	default:
+		return nil, fmt.Errorf("`outputOperationName` is required for %d TF Serving outputs", len(outputs))
+	}
+
+	return decodeTensorProto(tensorProto)
+}
+
+// decodeTensorProto float 또는 double TensorProto의 값 (tensor_content, float_val, double_val)
+func decodeTensorProto(b []byte) ([]float32, error) {
+	fields, err := parseProto(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		dtype   uint64
+		content []byte
+		values  []float32
+	)
+	for _, f := range fields {
+		var v []float32
+		switch {
+		case f.num == 1 && f.wire == protoVarint:
+			dtype = f.value
+		case f.num == 4 && f.wire == protoBytes:
+			content = f.bytes
+		case f.num == 5 && f.wire == protoBytes:
+			v, err = decodeFloat32s(f.bytes)
+		case f.num == 5 && f.wire == protoFixed32:
+			v = []float32{math.Float32frombits(uint32(f.value))}
+		case f.num == 6 && f.wire == protoBytes:
+			v, err = decodeFloat64s(f.bytes)
+		case f.num == 6 && f.wire == protoFixed64:
+			v = []float32{float32(math.Float64frombits(f.value))}
+		}
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v...)
+	}
+	if content == nil {
+		return values, nil
+	}
+
+	switch dtype {
+	case tfDTypeFloat:
+		return decodeFloat32s(content)
+	case tfDTypeDouble:
+		return decodeFloat64s(content)
+	default:
+		return nil, fmt.Errorf("Unsupported TF Serving output dtype: %d", dtype)
+	}
+}
+
+// restPredict REST Predict API로 입력 하나를 instance로 요청
+func (m *tfServingModel) restPredict(ctx context.Context, input []float32) ([]float32, error) {
+	body := []byte(`{"signature_name":`)
+	body = strconv.AppendQuote(body, m.cfg.signature())
+	body = append(body, `,"instances":[`...)
+	body = appendNested(body, input, m.shape)
+	body = append(body, "]}"...)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.url()+":predict", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := m.client.Do(req)
+	if err != nil {
+		return nil, remoteError(ctx, m.cfg.ModelName, err)
+	}
+	defer res.Body.Close()
+
+	var response struct {
+		Predictions []json.RawMessage `json:"predictions"`
+		Error       string            `json:"error"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&response)
+	if err == nil && res.StatusCode/100 != 2 {
+		err = fmt.Errorf("%s (%s)", response.Error, res.Status)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Fail to predict with TF Serving model(%s): %s", m.cfg.ModelName, err)
+	}
+	if len(response.Predictions) != 1 {
+		return nil, fmt.Errorf("Unexpected TF Serving predictions(%s): %d", m.cfg.ModelName, len(response.Predictions))
+	}
+
+	return m.prediction(response.Predictions[0])
+}
+
+// prediction instance 하나의 예측을 펼친 값
+//
+// 출력이 여러 개인 signature는 출력 이름별 예측이므로 config의 outputOperationName(하나뿐이면 그 출력) 사용
+func (m *tfServingModel) prediction(raw json.RawMessage) ([]float32, error) {
+	var outputs map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &outputs); err == nil {
+		switch {
+		case m.output != "":
+			if raw = outputs[m.output]; raw == nil {
+				return nil, fmt.Errorf("Output is not in TF Serving predictions: %s", m.output)
+			}
+		case len(outputs) == 1:
+			for _, output := range outputs {
+				raw = output
+			}
+		default:
+			return nil, fmt.Errorf("`outputOperationName` is required for %d TF Serving outputs", len(outputs))
+		}
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+
+	return flattenPrediction(value, nil)
+}
+
+// Close TensorFlow Serving의 모델은 해제하지 않음 (gRPC는 요청마다 연결)
+func (m *tfServingModel) Close() error {
+	if m.client != nil {
+		m.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+// instanceShape batch를 제외한 모델 입력의 shape (inputLayout 순서)
+func instanceShape(cfg BackendConfig) []int {
+	if cfg.InputLayout == layoutNCHW {
+		return []int{int(cfg.InputShape[2]), int(cfg.InputShape[0]), int(cfg.InputShape[1])}
+	}
+
+	return []int{int(cfg.InputShape[0]), int(cfg.InputShape[1]), int(cfg.InputShape[2])}
+}
+
+// appendNested 펼친 data를 shape의 중첩 JSON 배열로 추가
+func appendNested(b []byte, data []float32, shape []int) []byte {
+	if len(shape) == 0 {
+		return strconv.AppendFloat(b, float64(data[0]), 'g', -1, 32)
+	}
+
+	size := len(data) / shape[0]
+	b = append(b, '[')
+	for idx := 0; idx < shape[0]; idx++ {
+		if idx > 0 {
+			b = append(b, ',')
+		}
+		b = appendNested(b, data[idx*size:(idx+1)*size], shape[1:])
+	}
+
+	return append(b, ']')
+}
+
+// flattenPrediction 중첩 된 JSON 배열의 숫자를 순서대로 펼침
+func flattenPrediction(value interface{}, values []float32) ([]float32, error) {
+	switch v := value.(type) {
+	case float64:
+		return append(values, float32(v)), nil
+	case []interface{}:
+		var err error
+		for _, e := range v {
+			if values, err = flattenPrediction(e, values); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("Unexpected TF Serving prediction value: %v", value)
+	}
+}
@@ -0,0 +1,187 @@
+package inference
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTFServingBackend(t *testing.T) {
+	var (
+		predictions string
+		instances   []interface{}
+		signature   string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/models/flowers/versions/2":
+			w.Write([]byte(`{"model_version_status": [{"version": "2", "state": "AVAILABLE"}]}`))
+		case "/v1/models/flowers/versions/2:predict":
+			var req struct {
+				SignatureName string        `json:"signature_name"`
+				Instances     []interface{} `json:"instances"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Error(err)
+			}
+			signature, instances = req.SignatureName, req.Instances
+			w.Write([]byte(predictions))
+		case "/v1/models/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "Could not find any versions of model missing"}`))
+		default:
+			t.Errorf("Unexpected request: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	cfg := modelConfig{
+		Format:         formatTFServing,
+		Classification: multiClass,
+		InputShape:     []int32{1, 2, 3},
+		TFServing:      &tfServingConfig{Host: host, Protocol: protocolHTTP, ModelName: "missing"},
+	}
+	if _, err := openModelBackend("model", cfg, nil, ""); !errors.Is(err, ErrBackendUnavailable) || !strings.Contains(err.Error(), "Could not find") {
+		t.Fatalf("Missing model should fail to load: %v", err)
+	}
+
+	cfg.TFServing = &tfServingConfig{Host: host, Protocol: protocolHTTP, ModelName: "flowers", Version: 2}
+	b, err := openModelBackend("model", cfg, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.close("flowers")
+
+	predictions = `{"predictions": [[0.1, 0.9]]}`
+	output, err := b.runTensor(context.Background(), []float32{1, 2, 3, 4, 5, 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(output, []float32{0.1, 0.9}) {
+		t.Fatalf("Unexpected output: %v", output)
+	}
+	if signature != defaultTFServingSignature {
+		t.Fatalf("Unexpected signature: %s", signature)
+	}
+	// [1, 2, 3] instance 하나
+	instance := []interface{}{
+		[]interface{}{[]interface{}{1.0, 2.0, 3.0}, []interface{}{4.0, 5.0, 6.0}},
+	}
+	if !reflect.DeepEqual(instances, []interface{}{instance}) {
+		t.Fatalf("Unexpected instances: %v", instances)
+	}
+
+	// 출력이 여러 개이면 outputOperationName 필요
+	predictions = `{"predictions": [{"probabilities": [0.3, 0.7], "logits": [1, 2]}]}`
+	if _, err := b.runTensor(context.Background(), make([]float32, 6)); err == nil {
+		t.Fatalf("Multiple outputs without name should fail")
+	}
+	cfg.OutputOperationName = "probabilities"
+	if b, err = openModelBackend("model", cfg, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if output, err = b.runTensor(context.Background(), make([]float32, 6)); err != nil || !reflect.DeepEqual(output, []float32{0.3, 0.7}) {
+		t.Fatalf("Unexpected named output: %v %v", output, err)
+	}
+}
+
+func TestTFServingGRPC(t *testing.T) {
+	var predict []byte
+	server := newGRPCServer(t, func(method string, request []byte) ([]byte, int) {
+		if bytes.Contains(request, []byte("missing")) {
+			return nil, grpcNotFound
+		}
+
+		switch method {
+		case tfServingGetModelStatus:
+			// model_version_status { version: 2, state: AVAILABLE }
+			status := appendProtoUint(appendProtoUint(nil, 1, 2), 2, tfServingAvailable)
+			return appendProtoBytes(nil, 1, status), grpcOK
+		case tfServingPredict:
+			predict = request
+			tensor := appendProtoUint(nil, 1, tfDTypeFloat)
+			tensor = appendProtoBytes(tensor, 5, appendFloat32s(nil, []float32{0.1, 0.9}))
+			return appendProtoBytes(nil, 1, appendProtoBytes(appendProtoString(nil, 1, "probabilities"), 2, tensor)), grpcOK
+		default:
+			return nil, grpcNotFound
+		}
+	})
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "https://")
+	cfg := BackendConfig{InputShape: []int32{224, 224, 3}, InputDType: inputFloat32, InputOperationName: "image"}
+
+	m := newTFServingModel(tfServingConfig{Host: host, TLS: true, ModelName: "missing"}, cfg)
+	grpcServerClient(server, m.grpc)
+	if err := m.checkAvailable(); !errors.Is(err, ErrBackendUnavailable) || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("Missing model should be unavailable: %v", err)
+	}
+
+	m = newTFServingModel(tfServingConfig{Host: host, TLS: true, ModelName: "flowers", Version: 2}, cfg)
+	grpcServerClient(server, m.grpc)
+	if err := m.checkAvailable(); err != nil {
+		t.Fatal(err)
+	}
+	output, err := m.Infer(context.Background(), make([]float32, 224*224*3))
+	if err != nil || !reflect.DeepEqual(output, []float32{0.1, 0.9}) {
+		t.Fatalf("Unexpected output: %v %v", output, err)
+	}
+
+	// PredictRequest의 inputs { key: "image", value: TensorProto }
+	fields, err := parseProto(predict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry []protoField
+	for _, f := range fields {
+		if f.num == 2 {
+			entry, _ = parseProto(f.bytes)
+		}
+	}
+	if len(entry) != 2 || string(entry[0].bytes) != "image" {
+		t.Fatalf("Unexpected inputs: %v", entry)
+	}
+	tensor, _ := parseProto(entry[1].bytes)
+	if tensor[0].value != tfDTypeFloat || len(tensor[2].bytes) != 224*224*3*4 {
+		t.Fatalf("Unexpected input tensor: dtype %d, %d bytes", tensor[0].value, len(tensor[2].bytes))
+	}
+}
+
+func TestTFServingUnavailable(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := l.Addr().String()
+	l.Close()
+
+	// 연결할 수 없는 서버는 ErrBackendUnavailable로 로드 실패 (시작시 모델을 삭제하지 않음)
+	cfg := modelConfig{
+		Format:             formatTFServing,
+		Classification:     multiClass,
+		InputShape:         []int32{1, 2, 3},
+		InputOperationName: "image",
+		TFServing:          &tfServingConfig{Host: host, ModelName: "flowers"},
+	}
+	if _, err = openModelBackend("model", cfg, nil, ""); !errors.Is(err, ErrBackendUnavailable) {
+		t.Fatalf("Unreachable server should be unavailable: %v", err)
+	}
+	if !keepFailedModel(err) {
+		t.Fatalf("Model of unavailable backend should be kept")
+	}
+}
+
+func TestAppendNested(t *testing.T) {
+	b := appendNested(nil, []float32{0, 1.5, 2, 3, 4, 255}, []int{3, 1, 2})
+	if string(b) != "[[[0,1.5]],[[2,3]],[[4,255]]]" {
+		t.Fatalf("Unexpected nested array: %s", b)
+	}
+}
@@ -0,0 +1,82 @@
+package inference
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// timingStage 소요 시간을 측정하는 추론 단계
+type timingStage int
+
+const (
+	// 이미지 형식 확인, 디코딩, 크기 조정과 정규화 (전처리 graph 실행 포함)
+	stagePreprocess timingStage = iota
+	// 모델 실행 (Session.Run)
+	stageRun
+	// 활성화 함수, 클래스 결정과 후처리 hook
+	stagePostprocess
+)
+
+// Timings 추론 단계별 소요 시간
+//
+// ContextWithTimings로 지정한 경우에만 측정하며, TTA나 batch 추론은 단계별로 합산
+type Timings struct {
+	mutex       sync.Mutex
+	Preprocess  time.Duration
+	Run         time.Duration
+	Postprocess time.Duration
+}
+
+type timingsKey struct{}
+
+// ContextWithTimings 추론 단계별 소요 시간을 t에 기록하도록 지정한 ctx 반환
+func ContextWithTimings(ctx context.Context, t *Timings) context.Context {
+	return context.WithValue(ctx, timingsKey{}, t)
+}
+
+// timeStage ctx에 Timings가 있으면 stage의 측정을 시작하고, 반환 된 함수를 호출하면 소요 시간을 기록
+//
+//	defer timeStage(ctx, stageRun)()
+func timeStage(ctx context.Context, stage timingStage) func() {
+	t, ok := ctx.Value(timingsKey{}).(*Timings)
+	if !ok || t == nil {
+		return func() {}
+	}
+
+	t0 := time.Now()
+	return func() {
+		t.add(stage, time.Since(t0))
+	}
+}
+
+func (t *Timings) add(stage timingStage, d time.Duration) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	switch stage {
+	case stagePreprocess:
+		t.Preprocess += d
+	case stageRun:
+		t.Run += d
+	case stagePostprocess:
+		t.Postprocess += d
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// MarshalJSON 단계별 소요 시간을 ms 단위로 변환
+func (t *Timings) MarshalJSON() ([]byte, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return json.Marshal(map[string]float64{
+		"preprocess(ms)":  milliseconds(t.Preprocess),
+		"run(ms)":         milliseconds(t.Run),
+		"postprocess(ms)": milliseconds(t.Postprocess),
+	})
+}
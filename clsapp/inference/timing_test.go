@@ -0,0 +1,39 @@
+package inference
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimings(t *testing.T) {
+	// Timings가 없으면 측정하지 않음
+	timeStage(context.Background(), stageRun)()
+
+	timings := &Timings{}
+	ctx := ContextWithTimings(context.Background(), timings)
+
+	for i := 0; i < 2; i++ {
+		stop := timeStage(ctx, stageRun)
+		time.Sleep(time.Millisecond)
+		stop()
+	}
+	timings.add(stagePreprocess, 1500*time.Microsecond)
+
+	if timings.Run < 2*time.Millisecond || timings.Postprocess != 0 {
+		t.Fatalf("Unexpected timings: %+v", timings)
+	}
+
+	b, err := json.Marshal(timings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res map[string]float64
+	if err := json.Unmarshal(b, &res); err != nil {
+		t.Fatal(err)
+	}
+	if res["preprocess(ms)"] != 1.5 || res["run(ms)"] < 2 || len(res) != 3 {
+		t.Fatalf("Unexpected json timings: %s", b)
+	}
+}
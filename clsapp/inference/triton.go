@@ -0,0 +1,391 @@
+package inference
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// formatTriton 모델 파일 없이 원격 Triton Inference Server에서 실행하는 모델
+const formatTriton = "triton"
+
+// Triton gRPC method
+const (
+	tritonModelReady = "/inference.GRPCInferenceService/ModelReady"
+	tritonModelInfer = "/inference.GRPCInferenceService/ModelInfer"
+)
+
+// tritonConfig `format: triton` 모델이 추론을 요청할 Triton Inference Server
+type tritonConfig struct {
+	// Triton 주소 (host:port, 예: HTTP는 gpu-box:8000, gRPC는 gpu-box:8001)
+	Host string `yaml:"host"`
+	// 요청 protocol (http 또는 grpc, 기본값: http)
+	Protocol string `yaml:"protocol"`
+	// TLS 사용 (기본값: false)
+	TLS bool `yaml:"tls"`
+	// Triton의 모델 이름
+	ModelName string `yaml:"modelName"`
+	// 모델 버전 (기본값: 빈 값, Triton의 version policy에 따른 버전)
+	Version string `yaml:"version"`
+	// batch 차원 없이 입력하는 모델 (Triton 모델 설정의 max_batch_size가 0)
+	Unbatched bool `yaml:"unbatched"`
+	// 요청 제한 시간 (기본값: 10)
+	TimeoutSeconds int `yaml:"timeoutSeconds"`
+}
+
+func (s *tritonConfig) protocol() string {
+	if s.Protocol == "" {
+		return protocolHTTP
+	}
+
+	return s.Protocol
+}
+
+// validateTriton Triton 설정 검사
+//
+// Triton의 입력은 이름으로 지정하므로 inputOperationName이 필요
+func (cfg *modelConfig) validateTriton() []string {
+	s := cfg.Triton
+	if cfg.modelFormat() != formatTriton {
+		if s != nil {
+			return []string{fmt.Sprintf("`triton` must be used with %s format", formatTriton)}
+		}
+		return nil
+	}
+	if s == nil {
+		return []string{fmt.Sprintf("`triton` is required for %s format", formatTriton)}
+	}
+
+	var violations []string
+	if s.Host == "" {
+		violations = append(violations, "`triton.host` is required")
+	}
+	if p := s.protocol(); p != protocolHTTP && p != protocolGRPC {
+		violations = append(violations, fmt.Sprintf("`triton.protocol` must be %s or %s: %q", protocolHTTP, protocolGRPC, s.Protocol))
+	}
+	if s.ModelName == "" {
+		violations = append(violations, "`triton.modelName` is required")
+	}
+	if s.TimeoutSeconds < 0 {
+		violations = append(violations, fmt.Sprintf("`triton.timeoutSeconds` must not be negative: %d", s.TimeoutSeconds))
+	}
+	if cfg.InputOperationName == "" {
+		violations = append(violations, fmt.Sprintf("`inputOperationName` is required for %s format", formatTriton))
+	}
+
+	return violations
+}
+
+// tritonBackend Triton의 v2 inference protocol(HTTP/REST 또는 gRPC)로 추론을 요청하는 Backend
+//
+// Triton은 모델의 raw 출력만 반환하며, activation, labels와 분류 결과는 이 서버에서 처리
+type tritonBackend struct {
+	cfg tritonConfig
+}
+
+func (b tritonBackend) LoadModel(modelPath string, cfg BackendConfig) (BackendModel, error) {
+	m := newTritonModel(b.cfg, cfg)
+
+	// 모델이 준비되지 않았으면 로드 실패 (ErrBackendUnavailable)
+	if err := m.checkReady(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// tritonModel Triton의 모델
+type tritonModel struct {
+	cfg    tritonConfig
+	client *http.Client
+	grpc   *grpcClient
+	// 입력 이름과 출력 이름 (출력 이름이 없으면 첫 번째 출력)
+	input  string
+	output string
+	// 입력 tensor의 shape과 datatype
+	shape    []int
+	datatype string
+}
+
+func newTritonModel(s tritonConfig, cfg BackendConfig) *tritonModel {
+	shape := instanceShape(cfg)
+	if !s.Unbatched {
+		shape = append([]int{1}, shape...)
+	}
+
+	datatype := "FP32"
+	if cfg.InputDType == inputUint8 {
+		datatype = "UINT8"
+	}
+
+	m := &tritonModel{
+		cfg:      s,
+		input:    cfg.InputOperationName,
+		output:   cfg.OutputOperationName,
+		shape:    shape,
+		datatype: datatype,
+	}
+	if s.protocol() == protocolGRPC {
+		m.grpc = newGRPCClient(s.Host, s.TimeoutSeconds, s.TLS)
+	} else {
+		m.client = remoteClient(s.TimeoutSeconds)
+	}
+
+	return m
+}
+
+// url Triton 모델(버전)의 v2 API 주소
+func (m *tritonModel) url() string {
+	scheme := "http"
+	if m.cfg.TLS {
+		scheme = "https"
+	}
+
+	url := fmt.Sprintf("%s://%s/v2/models/%s", scheme, m.cfg.Host, m.cfg.ModelName)
+	if m.cfg.Version != "" {
+		url += "/versions/" + m.cfg.Version
+	}
+
+	return url
+}
+
+// checkReady 모델 ready API로 추론할 수 있는지 확인
+//
+// 연결하지 못했거나 모델이 준비되지 않았으면 ErrBackendUnavailable
+func (m *tritonModel) checkReady() error {
+	var err error
+	if m.grpc != nil {
+		err = m.grpcReady()
+	} else {
+		err = m.httpReady()
+	}
+	if err != nil {
+		return fmt.Errorf("%w: Triton model is not ready(%s): %s", ErrBackendUnavailable, m.cfg.ModelName, err)
+	}
+
+	return nil
+}
+
+func (m *tritonModel) httpReady() error {
+	res, err := m.client.Get(m.url() + "/ready")
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+		b, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("%s %s", res.Status, bytes.TrimSpace(b))
+	}
+
+	return nil
+}
+
+// grpcReady ModelReady 응답의 ready 확인
+func (m *tritonModel) grpcReady() error {
+	request := appendProtoString(nil, 1, m.cfg.ModelName)
+	request = appendProtoString(request, 2, m.cfg.Version)
+
+	response, err := m.grpc.invoke(context.Background(), tritonModelReady, request)
+	if err != nil {
+		return err
+	}
+
+	fields, err := parseProto(response)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if f.num == 1 && f.wire == protoVarint && f.value != 0 {
+			return nil
+		}
+	}
+
+	return errors.New("ready is false")
+}
+
+// tritonTensor v2 inference protocol의 입력과 출력 tensor
+type tritonTensor struct {
+	Name     string    `json:"name"`
+	Shape    []int     `json:"shape,omitempty"`
+	Datatype string    `json:"datatype,omitempty"`
+	Data     []float32 `json:"data,omitempty"`
+}
+
+// Infer 입력 하나를 batch 1로 infer 요청하여 출력 반환
+func (m *tritonModel) Infer(ctx context.Context, input []float32) ([]float32, error) {
+	if m.grpc != nil {
+		return m.grpcInfer(ctx, input)
+	}
+
+	return m.httpInfer(ctx, input)
+}
+
+func (m *tritonModel) httpInfer(ctx context.Context, input []float32) ([]float32, error) {
+	request := struct {
+		Inputs  []tritonTensor `json:"inputs"`
+		Outputs []tritonTensor `json:"outputs,omitempty"`
+	}{
+		Inputs: []tritonTensor{{Name: m.input, Shape: m.shape, Datatype: m.datatype, Data: input}},
+	}
+	if m.output != "" {
+		request.Outputs = []tritonTensor{{Name: m.output}}
+	}
+
+	j, _ := json.Marshal(request)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.url()+"/infer", bytes.NewReader(j))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := m.client.Do(req)
+	if err != nil {
+		return nil, remoteError(ctx, m.cfg.ModelName, err)
+	}
+	defer res.Body.Close()
+
+	var response struct {
+		Outputs []tritonTensor `json:"outputs"`
+		Error   string         `json:"error"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&response)
+	if err == nil && res.StatusCode/100 != 2 {
+		err = fmt.Errorf("%s (%s)", response.Error, res.Status)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Fail to infer with Triton model(%s): %s", m.cfg.ModelName, err)
+	}
+
+	for _, output := range response.Outputs {
+		if m.output == "" || output.Name == m.output {
+			return output.Data, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Output is not in Triton response(%s): %s", m.cfg.ModelName, m.output)
+}
+
+// grpcInfer ModelInfer로 입력을 raw_input_contents로 요청
+func (m *tritonModel) grpcInfer(ctx context.Context, input []float32) ([]float32, error) {
+	var shape, content []byte
+	for _, d := range m.shape {
+		shape = appendProtoVarint(shape, uint64(d))
+	}
+	if m.datatype == "UINT8" {
+		content = appendUint8s(nil, input)
+	} else {
+		content = appendFloat32s(nil, input)
+	}
+
+	tensor := appendProtoString(nil, 1, m.input)
+	tensor = appendProtoString(tensor, 2, m.datatype)
+	tensor = appendProtoBytes(tensor, 3, shape)
+
+	request := appendProtoString(nil, 1, m.cfg.ModelName)
+	request = appendProtoString(request, 2, m.cfg.Version)
+	request = appendProtoBytes(request, 5, tensor)
+	if m.output != "" {
+		request = appendProtoBytes(request, 6, appendProtoString(nil, 1, m.output))
+	}
+	request = appendProtoBytes(request, 7, content)
+
+	response, err := m.grpc.invoke(ctx, tritonModelInfer, request)
+	if err != nil {
+		return nil, fmt.Errorf("Fail to infer with Triton model(%s): %w", m.cfg.ModelName, remoteError(ctx, m.cfg.ModelName, err))
+	}
+
+	// outputs와 raw_output_contents는 같은 순서
+	fields, err := parseProto(response)
+	if err != nil {
+		return nil, err
+	}
+	var outputs, raws [][]byte
+	for _, f := range fields {
+		switch {
+		case f.num == 5 && f.wire == protoBytes:
+			outputs = append(outputs, f.bytes)
+		case f.num == 6 && f.wire == protoBytes:
+			raws = append(raws, f.bytes)
+		}
+	}
+
+	for idx, output := range outputs {
+		tensor, err := parseProto(output)
+		if err != nil {
+			return nil, err
+		}
+
+		var (
+			name, datatype string
+			contents       []byte
+		)
+		for _, f := range tensor {
+			switch f.num {
+			case 1:
+				name = string(f.bytes)
+			case 2:
+				datatype = string(f.bytes)
+			case 5:
+				contents = f.bytes
+			}
+		}
+		if m.output != "" && name != m.output {
+			continue
+		}
+
+		if idx < len(raws) {
+			return decodeTritonRaw(datatype, raws[idx])
+		}
+		return decodeTritonContents(datatype, contents)
+	}
+
+	return nil, fmt.Errorf("Output is not in Triton response(%s): %s", m.cfg.ModelName, m.output)
+}
+
+// decodeTritonRaw raw_output_contents의 little-endian 값
+func decodeTritonRaw(datatype string, raw []byte) ([]float32, error) {
+	switch datatype {
+	case "FP32":
+		return decodeFloat32s(raw)
+	case "FP64":
+		return decodeFloat64s(raw)
+	default:
+		return nil, fmt.Errorf("Unsupported Triton output datatype: %s", datatype)
+	}
+}
+
+// decodeTritonContents InferTensorContents의 fp32_contents 또는 fp64_contents
+func decodeTritonContents(datatype string, contents []byte) ([]float32, error) {
+	fields, err := parseProto(contents)
+	if err != nil {
+		return nil, err
+	}
+
+	num := 6
+	if datatype == "FP64" {
+		num = 7
+	} else if datatype != "FP32" {
+		return nil, fmt.Errorf("Unsupported Triton output datatype: %s", datatype)
+	}
+
+	for _, f := range fields {
+		if f.num == num && f.wire == protoBytes {
+			return decodeTritonRaw(datatype, f.bytes)
+		}
+	}
+
+	return nil, nil
+}
+
+// Close Triton의 모델은 해제하지 않음 (gRPC는 요청마다 연결)
+func (m *tritonModel) Close() error {
+	if m.client != nil {
+		m.client.CloseIdleConnections()
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package inference
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTritonBackend(t *testing.T) {
+	var request struct {
+		Inputs  []tritonTensor `json:"inputs"`
+		Outputs []tritonTensor `json:"outputs"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/models/flowers/versions/3/ready":
+		case "/v2/models/flowers/versions/3/infer":
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				t.Error(err)
+			}
+			w.Write([]byte(`{"model_name": "flowers", "outputs": [
+				{"name": "logits", "shape": [1, 2], "datatype": "FP32", "data": [1, 2]},
+				{"name": "probabilities", "shape": [1, 2], "datatype": "FP32", "data": [0.2, 0.8]}
+			]}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "Request for unknown model: 'missing' is not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	cfg := modelConfig{
+		Format:             formatTriton,
+		Classification:     multiClass,
+		InputShape:         []int32{1, 2, 3},
+		InputDType:         inputUint8,
+		InputOperationName: "image",
+		Triton:             &tritonConfig{Host: host, ModelName: "missing"},
+	}
+	if _, err := openModelBackend("model", cfg, nil, ""); !errors.Is(err, ErrBackendUnavailable) || !strings.Contains(err.Error(), "not ready") {
+		t.Fatalf("Missing model should fail to load: %v", err)
+	}
+
+	cfg.Triton = &tritonConfig{Host: host, ModelName: "flowers", Version: "3"}
+	b, err := openModelBackend("model", cfg, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.close("flowers")
+
+	// 출력 이름이 없으면 첫 번째 출력
+	output, err := b.runTensor(context.Background(), []float32{1, 2, 3, 4, 5, 6})
+	if err != nil || !reflect.DeepEqual(output, []float32{1, 2}) {
+		t.Fatalf("Unexpected output: %v %v", output, err)
+	}
+	input := request.Inputs[0]
+	if input.Name != "image" || input.Datatype != "UINT8" || !reflect.DeepEqual(input.Shape, []int{1, 1, 2, 3}) || len(input.Data) != 6 {
+		t.Fatalf("Unexpected input: %+v", input)
+	}
+	if len(request.Outputs) != 0 {
+		t.Fatalf("Unexpected requested outputs: %+v", request.Outputs)
+	}
+
+	cfg.OutputOperationName = "probabilities"
+	cfg.Triton.Unbatched = true
+	if b, err = openModelBackend("model", cfg, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if output, err = b.runTensor(context.Background(), make([]float32, 6)); err != nil || !reflect.DeepEqual(output, []float32{0.2, 0.8}) {
+		t.Fatalf("Unexpected named output: %v %v", output, err)
+	}
+	if !reflect.DeepEqual(request.Inputs[0].Shape, []int{1, 2, 3}) || request.Outputs[0].Name != "probabilities" {
+		t.Fatalf("Unexpected unbatched request: %+v", request)
+	}
+}
+
+func TestTritonGRPC(t *testing.T) {
+	var infer []byte
+	server := newGRPCServer(t, func(method string, request []byte) ([]byte, int) {
+		switch {
+		case bytes.Contains(request, []byte("missing")):
+			return nil, grpcNotFound
+		case method == tritonModelReady:
+			return appendProtoUint(nil, 1, 1), grpcOK
+		case method == tritonModelInfer:
+			infer = request
+			// outputs와 같은 순서의 raw_output_contents
+			response := appendProtoBytes(nil, 5, appendProtoString(appendProtoString(nil, 1, "logits"), 2, "FP32"))
+			response = appendProtoBytes(response, 5, appendProtoString(appendProtoString(nil, 1, "probabilities"), 2, "FP64"))
+			response = appendProtoBytes(response, 6, appendFloat32s(nil, []float32{1, 2}))
+			raw := make([]byte, 16)
+			binary.LittleEndian.PutUint64(raw, math.Float64bits(0.25))
+			binary.LittleEndian.PutUint64(raw[8:], math.Float64bits(0.75))
+			return appendProtoBytes(response, 6, raw), grpcOK
+		default:
+			return nil, grpcNotFound
+		}
+	})
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "https://")
+	cfg := BackendConfig{InputShape: []int32{1, 2, 3}, InputDType: inputUint8, InputOperationName: "image"}
+
+	m := newTritonModel(tritonConfig{Host: host, Protocol: protocolGRPC, TLS: true, ModelName: "missing"}, cfg)
+	grpcServerClient(server, m.grpc)
+	if err := m.checkReady(); !errors.Is(err, ErrBackendUnavailable) {
+		t.Fatalf("Missing model should be unavailable: %v", err)
+	}
+
+	m = newTritonModel(tritonConfig{Host: host, Protocol: protocolGRPC, TLS: true, ModelName: "flowers", Version: "3"}, cfg)
+	grpcServerClient(server, m.grpc)
+	if err := m.checkReady(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 출력 이름이 없으면 첫 번째 출력
+	output, err := m.Infer(context.Background(), []float32{1, 2, 3, 4, 5, 6})
+	if err != nil || !reflect.DeepEqual(output, []float32{1, 2}) {
+		t.Fatalf("Unexpected output: %v %v", output, err)
+	}
+	fields, err := parseProto(infer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// model_name, model_version, inputs, raw_input_contents
+	if len(fields) != 4 || string(fields[1].bytes) != "3" || string(fields[3].bytes) != "\x01\x02\x03\x04\x05\x06" {
+		t.Fatalf("Unexpected request: %v", fields)
+	}
+	input, _ := parseProto(fields[2].bytes)
+	if string(input[0].bytes) != "image" || string(input[1].bytes) != "UINT8" || string(input[2].bytes) != "\x01\x01\x02\x03" {
+		t.Fatalf("Unexpected input: %v", input)
+	}
+
+	m.output = "probabilities"
+	if output, err = m.Infer(context.Background(), make([]float32, 6)); err != nil || !reflect.DeepEqual(output, []float32{0.25, 0.75}) {
+		t.Fatalf("Unexpected named output: %v %v", output, err)
+	}
+}
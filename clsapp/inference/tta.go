@@ -0,0 +1,107 @@
+package inference
+
+import (
+	"fmt"
+	"strings"
+)
+
+// test-time augmentation(TTA)의 변형 방식
+//
+// 원본과 변형 이미지를 하나의 batch로 추론한 후 확률을 평균
+const (
+	// 좌우 반전 (1개)
+	ttaFlip = "flip"
+	// 네 모서리 기준으로 90% 크기를 잘라 입력 크기로 조정 (4개)
+	ttaCrop = "crop"
+)
+
+// ttaCropBoxes ttaCrop의 잘라낼 영역 [y1, x1, y2, x2] (이미지 크기 기준 0 ~ 1)
+var ttaCropBoxes = [][]float32{
+	{0, 0, 0.9, 0.9},
+	{0, 0.1, 0.9, 1},
+	{0.1, 0, 1, 0.9},
+	{0.1, 0.1, 1, 1},
+}
+
+// multi-crop 추론의 crop 수
+const (
+	// 가운데와 네 모서리
+	multiCrop5 = 5
+	// multiCrop5와 좌우 반전
+	multiCrop10 = 10
+)
+
+// multiCropBoxes multi-crop의 잘라낼 영역 [y1, x1, y2, x2] (가운데와 네 모서리, 이미지 크기의 87.5% = 224/256)
+var multiCropBoxes = [][]float32{
+	{0.0625, 0.0625, 0.9375, 0.9375},
+	{0, 0, 0.875, 0.875},
+	{0, 0.125, 0.875, 1},
+	{0.125, 0, 1, 0.875},
+	{0.125, 0.125, 1, 1},
+}
+
+// ttaVariants 원본(multi-crop이면 crop)을 포함한 추론할 이미지 수
+func (cfg *modelConfig) ttaVariants() int {
+	if cfg.MultiCrop > 0 {
+		return cfg.MultiCrop
+	}
+
+	n := 1
+	for _, a := range cfg.TTA {
+		switch a {
+		case ttaFlip:
+			n++
+		case ttaCrop:
+			n += len(ttaCropBoxes)
+		}
+	}
+
+	return n
+}
+
+// ttaName 전처리 graph 저장 파일에 사용하는 TTA 이름 (예: flip_crop, crop10, 사용하지 않으면 none)
+func (cfg *modelConfig) ttaName() string {
+	if cfg.MultiCrop > 0 {
+		return fmt.Sprintf("crop%d", cfg.MultiCrop)
+	}
+	if len(cfg.TTA) == 0 {
+		return "none"
+	}
+
+	return strings.Join(cfg.TTA, "_")
+}
+
+// validateTTA TTA 변형 방식과 multi-crop 검사
+func (cfg *modelConfig) validateTTA() []string {
+	var violations []string
+
+	switch cfg.MultiCrop {
+	case 0:
+	case multiCrop5, multiCrop10:
+		// crop 영역은 이미지 전체 기준이므로 다른 크기 조정 방식, TTA와 함께 사용할 수 없음
+		if cfg.resizeMode() != resizeStretch {
+			violations = append(violations, fmt.Sprintf("`multiCrop` must not be used with `resizeMode` %s", cfg.resizeMode()))
+		}
+		if len(cfg.TTA) > 0 {
+			violations = append(violations, "`multiCrop` must not be used with `tta`")
+		}
+		if !cropResizeMethods[cfg.resizeMethod()] {
+			violations = append(violations, fmt.Sprintf("`resizeMethod` of multiCrop must be %s or %s: %q", resizeBilinear, resizeNearest, cfg.ResizeMethod))
+		}
+	default:
+		violations = append(violations, fmt.Sprintf("`multiCrop` must be %d or %d: %d", multiCrop5, multiCrop10, cfg.MultiCrop))
+	}
+
+	seen := make(map[string]bool)
+	for _, a := range cfg.TTA {
+		switch {
+		case a != ttaFlip && a != ttaCrop:
+			violations = append(violations, fmt.Sprintf("`tta` must be %s or %s: %q", ttaFlip, ttaCrop, a))
+		case seen[a]:
+			violations = append(violations, fmt.Sprintf("`tta` has duplicated augmentation: %s", a))
+		}
+		seen[a] = true
+	}
+
+	return violations
+}
@@ -0,0 +1,148 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ModelUpdate 모델을 다시 로드하지 않고 바꿀 수 있는 config 항목
+//
+// nil인 항목은 바꾸지 않으며, modelTags는 빈 목록으로 지울 수 있음
+type ModelUpdate struct {
+	Description      *string   `json:"description"`
+	ModelTags        *[]string `json:"modelTags"`
+	Threshold        *float32  `json:"threshold"`
+	DefaultTopK      *int      `json:"defaultTopK"`
+	MinProbability   *float32  `json:"minProbability"`
+	UnknownThreshold *float32  `json:"unknownThreshold"`
+}
+
+// apply cfg에 update를 적용하고 config 파일에 쓸 항목 반환
+func (update ModelUpdate) apply(cfg *modelConfig) yaml.MapSlice {
+	var values yaml.MapSlice
+	if update.Description != nil {
+		cfg.Description = *update.Description
+		values = append(values, yaml.MapItem{Key: "description", Value: cfg.Description})
+	}
+	if update.ModelTags != nil {
+		cfg.ModelTags = *update.ModelTags
+		values = append(values, yaml.MapItem{Key: "modelTags", Value: cfg.ModelTags})
+	}
+	if update.Threshold != nil {
+		cfg.Threshold = *update.Threshold
+		values = append(values, yaml.MapItem{Key: "threshold", Value: cfg.Threshold})
+	}
+	if update.DefaultTopK != nil {
+		cfg.DefaultTopK = *update.DefaultTopK
+		values = append(values, yaml.MapItem{Key: "defaultTopK", Value: cfg.DefaultTopK})
+	}
+	if update.MinProbability != nil {
+		cfg.MinProbability = *update.MinProbability
+		values = append(values, yaml.MapItem{Key: "minProbability", Value: cfg.MinProbability})
+	}
+	if update.UnknownThreshold != nil {
+		cfg.UnknownThreshold = *update.UnknownThreshold
+		values = append(values, yaml.MapItem{Key: "unknownThreshold", Value: cfg.UnknownThreshold})
+	}
+
+	return values
+}
+
+// UpdateModel 모델(`<model>/<version>`이면 해당 버전)의 설명, modelTags와 기본 threshold를 config 파일과 로드 된 모델에 반영
+//
+// SavedModel을 다시 로드하지 않고 바뀐 config를 가진 모델로 교체하므로, 진행 중인 추론은 이전 값으로 처리 됨
+func (i *Inference) UpdateModel(ctx context.Context, model string, update ModelUpdate) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := i.authorize(ctx, ActionUpdateModel, model); err != nil {
+		return err
+	}
+
+	// 동시에 바꾸는 요청이 서로의 값을 덮어쓰지 않도록 교체할 때까지 잡음
+	i.rwMutex.Lock()
+	defer i.rwMutex.Unlock()
+
+	m := i.lookupModel(model)
+	if m == nil || atomic.LoadInt32(&m.draining) != 0 {
+		return fmt.Errorf("%w: %s", ErrModelNotFound, model)
+	}
+	if atomic.LoadInt32(&m.status) != modelStatusRun {
+		return fmt.Errorf("%w: %s", ErrModelNotReady, model)
+	}
+
+	cfg := m.cfg
+	values := update.apply(&cfg)
+	if len(values) == 0 {
+		return nil
+	}
+	if violations := cfg.validate(m.versionPath); len(violations) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidUpdate, strings.Join(violations, "; "))
+	}
+
+	if err := updateConfig(m.versionPath, values); err != nil {
+		return err
+	}
+
+	newM := m.withConfig(cfg)
+	switch {
+	case i.models[m.name] == m:
+		i.models[m.name] = newM
+	case i.versions[m.name][m.version] == m:
+		i.versions[m.name][m.version] = newM
+	}
+
+	log.Printf("Update model(%s): version %d", m.name, m.version)
+
+	// backend는 newM이 계속 사용하므로 이전 모델은 해제하지 않고,
+	// 이전 모델로 진행 중인 추론이 끝날 때까지 newM이 삭제 되지 않도록 참조를 유지
+	atomic.AddInt32(&newM.refCount, 1)
+	go func() {
+		m.wait(context.Background())
+		i.putModel(newM)
+	}()
+
+	return nil
+}
+
+// withConfig m과 같은 backend를 사용하면서 config만 cfg로 바꾼 모델
+//
+// 추론 결과 캐시가 이전 값의 결과를 사용하지 않도록 loadID는 새로 할당
+func (m *iModel) withConfig(cfg modelConfig) *iModel {
+	return &iModel{
+		name:             m.name,
+		modelPath:        m.modelPath,
+		cfg:              cfg,
+		status:           atomic.LoadInt32(&m.status),
+		statusUpdateTime: m.statusUpdateTime,
+		readOnly:         m.readOnly,
+		tenant:           m.tenant,
+		version:          m.version,
+		versionPath:      m.versionPath,
+		loadID:           atomic.AddUint64(&modelLoads, 1),
+		metadata:         m.metadata,
+		backend:          m.backend,
+		inputShape:       m.inputShape,
+		nrLables:         m.nrLables,
+		labels:           m.labels,
+		labelInfos:       m.labelInfos,
+		localizedLabels:  m.localizedLabels,
+		labelsSource:     m.labelsSource,
+		hooks:            m.hooks,
+		preprocessor:     m.preprocessor,
+		probeDecode:      m.probeDecode,
+		heicConverter:    m.heicConverter,
+		tfliteRuntime:    m.tfliteRuntime,
+		graphCachePath:   m.graphCachePath,
+		preDownscale:     m.preDownscale,
+		warmupRuns:       m.warmupRuns,
+		index:            m.index,
+		memory:           m.memory,
+		budget:           m.budget,
+	}
+}
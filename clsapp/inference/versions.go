@@ -0,0 +1,351 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// VersionSeparator 특정 버전의 모델 이름 구분자: <model>/<version>
+//
+// 버전이 없는 이름은 사용 중인 버전(manifest의 current)을 사용
+const VersionSeparator = "/"
+
+// splitModelVersion 모델 이름을 모델과 버전으로 분리 (버전이 없으면 0)
+func splitModelVersion(name string) (string, int, error) {
+	idx := strings.LastIndex(name, VersionSeparator)
+	if idx < 0 {
+		return name, 0, nil
+	}
+
+	version, err := strconv.Atoi(name[idx+1:])
+	if err != nil || version <= 0 {
+		return "", 0, fmt.Errorf("%w: invalid version: %q", ErrInvalidName, name)
+	}
+
+	return name[:idx], version, nil
+}
+
+// lookupModel 이름(`<model>/<version>`이면 해당 버전, 별칭이면 대상 모델)으로 모델 반환
+//
+// i.rwMutex를 잡은 상태에서 호출
+func (i *Inference) lookupModel(name string) *iModel {
+	model, version, err := splitModelVersion(i.resolveAlias(name))
+	if err != nil {
+		return nil
+	}
+
+	m, ok := i.models[model]
+	if version == 0 || (ok && m.version == version) {
+		return m
+	}
+
+	return i.versions[model][version]
+}
+
+// modelVersions 사용 중인 버전 외에 로드 된(생성 중인 버전 포함) 모델의 버전 (버전 순서)
+//
+// i.rwMutex를 잡은 상태에서 호출
+func (i *Inference) modelVersions(model string) []*iModel {
+	var ms []*iModel
+	for _, m := range i.versions[model] {
+		ms = append(ms, m)
+	}
+	sort.Slice(ms, func(a, b int) bool {
+		return ms[a].version < ms[b].version
+	})
+
+	return ms
+}
+
+// putVersion 사용 중인 버전 외의 버전 등록
+//
+// i.rwMutex를 잡은 상태에서 호출
+func (i *Inference) putVersion(m *iModel) {
+	if i.versions == nil {
+		i.versions = make(map[string]map[int]*iModel)
+	}
+	if i.versions[m.name] == nil {
+		i.versions[m.name] = make(map[int]*iModel)
+	}
+	i.versions[m.name][m.version] = m
+}
+
+// delVersions 모델의 모든 버전 등록 해제
+//
+// i.rwMutex를 잡은 상태에서 호출
+func (i *Inference) delVersions(model string) []*iModel {
+	ms := i.modelVersions(model)
+	delete(i.versions, model)
+
+	return ms
+}
+
+// loadVersions v2 모델 디렉토리에서 사용 중인 버전 외의 버전을 로드
+//
+// 버전을 지정한 추론(`<model>/<version>`)에 사용하며, 로드에 실패한 버전은 파일을 지우지 않음
+func (i *Inference) loadVersions(m *iModel) {
+	if m.version == 0 {
+		return
+	}
+
+	dirs, _ := ioutil.ReadDir(filepath.Join(m.modelPath, versionsDir))
+	for _, dir := range dirs {
+		version, err := strconv.Atoi(dir.Name())
+		if err != nil || version <= 0 || version == m.version || !dir.IsDir() {
+			continue
+		}
+
+		v := getNewModel("", m.modelPath)
+		v.version = version
+		v.versionPath = versionPath(m.modelPath, version)
+		if err := i.load(v); err != nil {
+			log.Printf("Fail to load model version(%s): %s", v.versionPath, err)
+			continue
+		}
+		v.name = m.name
+		v.readOnly = m.readOnly
+		v.tenant = m.tenant
+
+		i.rwMutex.Lock()
+		i.putVersion(v)
+		i.rwMutex.Unlock()
+	}
+}
+
+// newVersion 학습 중에도 사용 중인 버전으로 추론하도록 모델의 새 버전 슬롯을 선점
+//
+// i.rwMutex를 잡은 상태에서 호출
+func (i *Inference) newVersion(model string) (*iModel, error) {
+	if _, version, err := splitModelVersion(model); err != nil {
+		return nil, err
+	} else if version > 0 {
+		return nil, fmt.Errorf("%w: version of new model: %s", ErrInvalidName, model)
+	}
+
+	m, ok := i.models[model]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, model)
+	}
+	if atomic.LoadInt32(&m.status) != modelStatusRun {
+		return nil, fmt.Errorf("%w: %s", ErrModelNotReady, model)
+	}
+	if m.readOnly || m.version == 0 {
+		return nil, fmt.Errorf("%w: %s does not have versions", ErrInvalidModelPath, model)
+	}
+
+	// 로드 되지 않은 버전의 디렉토리도 있을 수 있으므로 가장 큰 버전 디렉토리 다음 버전
+	version := m.version
+	for _, v := range i.versions[model] {
+		if v.version > version {
+			version = v.version
+		}
+	}
+	dirs, _ := ioutil.ReadDir(filepath.Join(m.modelPath, versionsDir))
+	for _, dir := range dirs {
+		if n, err := strconv.Atoi(dir.Name()); err == nil && n > version {
+			version = n
+		}
+	}
+	version++
+
+	v := getNewModel(model, m.modelPath)
+	v.tenant = m.tenant
+	v.version = version
+	v.versionPath = versionPath(m.modelPath, version)
+	i.putVersion(v)
+
+	return v, nil
+}
+
+// buildingVersion 모델의 버전 중 versionPath가 p인 생성 중인 버전
+//
+// i.rwMutex를 잡은 상태에서 호출
+func (i *Inference) buildingVersion(model, p string) *iModel {
+	for _, v := range i.versions[model] {
+		if atomic.LoadInt32(&v.status) != modelStatusRun && filepath.Clean(v.versionPath) == filepath.Clean(p) {
+			return v
+		}
+	}
+
+	return nil
+}
+
+// promoteVersion 로드 된 새 버전을 사용 중인 버전으로 바꿈
+//
+// 이전 버전은 버전을 지정한 추론에 계속 사용
+func (i *Inference) promoteVersion(v *iModel) error {
+	manifest, err := readManifest(v.modelPath)
+	if err != nil {
+		return err
+	}
+	if manifest == nil {
+		return fmt.Errorf("%w: %s does not have versions", ErrInvalidModelPath, v.name)
+	}
+
+	manifest.Current = v.version
+	if err := writeManifest(v.modelPath, *manifest); err != nil {
+		return err
+	}
+
+	i.rwMutex.Lock()
+	defer i.rwMutex.Unlock()
+
+	if current, ok := i.models[v.name]; ok {
+		i.putVersion(current)
+	}
+	delete(i.versions[v.name], v.version)
+	i.models[v.name] = v
+
+	log.Printf("Serve model(%s): version %d", v.name, v.version)
+	return nil
+}
+
+// pruneVersions 모델의 버전 디렉토리 중 최근 i.keepVersions개를 넘는 오래된 버전 삭제
+//
+// 사용 중인 버전, 별칭이 가리키는 버전, 생성 중이거나 추론 중인 버전은 삭제하지 않음
+func (i *Inference) pruneVersions(model string) {
+	if i.keepVersions <= 0 {
+		return
+	}
+
+	i.rwMutex.Lock()
+	defer i.rwMutex.Unlock()
+
+	m, ok := i.models[model]
+	if !ok || m.readOnly || m.version == 0 {
+		return
+	}
+
+	var versions []int
+	dirs, _ := ioutil.ReadDir(filepath.Join(m.modelPath, versionsDir))
+	for _, dir := range dirs {
+		if version, err := strconv.Atoi(dir.Name()); err == nil && version > 0 && dir.IsDir() {
+			versions = append(versions, version)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+	for n, version := range versions {
+		if n < i.keepVersions || version == m.version || len(i.modelAliases(model, version)) > 0 {
+			continue
+		}
+
+		if v, ok := i.versions[model][version]; ok {
+			if atomic.LoadInt32(&v.status) != modelStatusRun || atomic.LoadInt32(&v.refCount) > 0 {
+				continue
+			}
+			delete(i.versions[model], version)
+			go v.release()
+		}
+
+		if err := os.RemoveAll(versionPath(m.modelPath, version)); err != nil {
+			log.Print(err)
+			continue
+		}
+		log.Printf("Prune model version(%s): version %d", model, version)
+	}
+}
+
+// RollbackModel 모델의 사용 중인 버전을 이전 버전으로 되돌리고 되돌린 버전을 반환
+//
+// model이 `<model>/<version>`이면 해당 버전으로 되돌리며, 되돌리기 전 버전은 버전을 지정한 추론에 계속 사용
+func (i *Inference) RollbackModel(ctx context.Context, model string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if err := i.authorize(ctx, ActionRollbackModel, model); err != nil {
+		return 0, err
+	}
+
+	name, version, err := splitModelVersion(model)
+	if err != nil {
+		return 0, err
+	}
+
+	i.rwMutex.RLock()
+	current, ok := i.models[name]
+	versioned := ok && !current.readOnly && current.version > 0
+	var v *iModel
+	if versioned {
+		if version > 0 {
+			v = i.versions[name][version]
+		} else {
+			// 사용 중인 버전보다 낮은 버전 중 가장 최근 버전
+			for _, prev := range i.modelVersions(name) {
+				if prev.version < current.version && atomic.LoadInt32(&prev.status) == modelStatusRun {
+					v = prev
+				}
+			}
+		}
+		if v != nil && atomic.LoadInt32(&v.status) == modelStatusRun && atomic.LoadInt32(&v.draining) == 0 {
+			// 되돌리는 중에 삭제 되지 않도록 사용 중으로 표시
+			atomic.AddInt32(&v.refCount, 1)
+		} else {
+			v = nil
+		}
+	}
+	i.rwMutex.RUnlock()
+
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrModelNotFound, name)
+	}
+	if !versioned {
+		return 0, fmt.Errorf("%w: %s does not have versions", ErrInvalidModelPath, name)
+	}
+	if version == current.version {
+		return version, nil
+	}
+	if v == nil {
+		return 0, fmt.Errorf("%w: no version to roll back: %s", ErrModelNotFound, model)
+	}
+	defer i.putModel(v)
+
+	if err := i.promoteVersion(v); err != nil {
+		return 0, err
+	}
+
+	return v.version, nil
+}
+
+// CreateVersion 사용 중인 버전으로 계속 추론하면서 모델의 새 버전을 학습
+//
+// 학습한 버전을 로드하면 사용 중인 버전으로 바뀌며, 이전 버전은 `<model>/<version>`으로 추론 할 수 있음
+func (i *Inference) CreateVersion(ctx context.Context, model, subject, desc string, epochs int, trial bool) (*CreateResult, error) {
+	if err := i.authorize(ctx, ActionCreateModel, model); err != nil {
+		return nil, err
+	}
+	if subject != "" {
+		if err := ValidateName(subject); err != nil {
+			return nil, err
+		}
+	}
+
+	i.rwMutex.Lock()
+	v, err := i.newVersion(model)
+	if err == nil && v.tenant != "" {
+		var t *tenant
+		if t, err = i.getTenant(v.tenant); err == nil {
+			err = t.checkQuota()
+		}
+		if err != nil {
+			delete(i.versions[model], v.version)
+		}
+	}
+	if err != nil {
+		i.rwMutex.Unlock()
+		return nil, err
+	}
+	atomic.AddInt32(&v.refCount, 1)
+	i.rwMutex.Unlock()
+	defer i.putModel(v)
+
+	return i.train(ctx, v, subject, desc, epochs, trial)
+}
@@ -0,0 +1,143 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func TestSplitModelVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   string
+		version int
+		err     bool
+	}{
+		{"pets", "pets", 0, false},
+		{"pets/2", "pets", 2, false},
+		{"pets@alice/1", "pets@alice", 1, false},
+		{"pets/0", "", 0, true},
+		{"pets/latest", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		model, version, err := splitModelVersion(tt.name)
+		if model != tt.model || version != tt.version || (err != nil) != tt.err {
+			t.Errorf("splitModelVersion(%q) = %q, %d, %v", tt.name, model, version, err)
+		}
+	}
+}
+
+func TestModelVersions(t *testing.T) {
+	modelsPath := newModelsPath(t)
+	defer os.RemoveAll(modelsPath)
+
+	modelPath := filepath.Join(modelsPath, "pets-1234")
+	for _, version := range []int{1, 2, 4} {
+		if err := os.MkdirAll(versionPath(modelPath, version), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	current := &iModel{name: "pets", modelPath: modelPath, version: 2, versionPath: versionPath(modelPath, 2), status: modelStatusRun}
+	previous := &iModel{name: "pets", modelPath: modelPath, version: 1, versionPath: versionPath(modelPath, 1), status: modelStatusRun}
+	i := &Inference{
+		models:     map[string]*iModel{"pets": current},
+		modelsPath: modelsPath,
+	}
+	i.putVersion(previous)
+
+	for name, m := range map[string]*iModel{"pets": current, "pets/2": current, "pets/1": previous, "pets/3": nil, "pets/x": nil} {
+		if found := i.lookupModel(name); found != m {
+			t.Fatalf("Unexpected model of %s: %v", name, found)
+		}
+	}
+
+	models := i.GetModels(context.Background(), ModelFilter{})
+	sort.Strings(models)
+	if len(models) != 2 || models[0] != "pets" || models[1] != "pets/1" {
+		t.Fatalf("Unexpected models: %v", models)
+	}
+
+	// 디렉토리가 남아 있는 버전은 건너뜀
+	v, err := i.newVersion("pets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.version != 5 || i.buildingVersion("pets", versionPath(modelPath, 5)) != v {
+		t.Fatalf("Unexpected new version: %d", v.version)
+	}
+	if _, err := i.newVersion("none"); !errors.Is(err, ErrModelNotFound) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// 사용 중인 버전은 버전을 지정해 삭제 할 수 없음
+	if err := i.delModel("pets/2"); !errors.Is(err, ErrModelInUse) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := i.delModel("pets/1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(versionPath(modelPath, 1)); !os.IsNotExist(err) {
+		t.Fatalf("Version directory remains: %v", err)
+	}
+	if i.lookupModel("pets/1") != nil || i.lookupModel("pets") != current {
+		t.Fatal("Only the version should be deleted")
+	}
+}
+
+func TestRollbackModel(t *testing.T) {
+	modelsPath := newModelsPath(t)
+	defer os.RemoveAll(modelsPath)
+
+	modelPath := filepath.Join(modelsPath, "pets-1234")
+	ms := make(map[int]*iModel)
+	for version := 1; version <= 4; version++ {
+		if err := os.MkdirAll(versionPath(modelPath, version), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		ms[version] = &iModel{name: "pets", modelPath: modelPath, version: version, versionPath: versionPath(modelPath, version), status: modelStatusRun}
+	}
+	if err := writeManifest(modelPath, modelManifest{Layout: layoutVersion, Name: "pets", Current: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	i := &Inference{
+		models:       map[string]*iModel{"pets": ms[3]},
+		modelsPath:   modelsPath,
+		keepVersions: 2,
+	}
+	for _, version := range []int{1, 2, 4} {
+		i.putVersion(ms[version])
+	}
+
+	ctx := context.Background()
+	if version, err := i.RollbackModel(ctx, "pets"); err != nil || version != 2 {
+		t.Fatalf("Unexpected rollback: %d, %v", version, err)
+	}
+	if manifest, err := readManifest(modelPath); err != nil || manifest.Current != 2 || i.lookupModel("pets") != ms[2] {
+		t.Fatalf("Unexpected current version: %v, %v", manifest, err)
+	}
+	if version, err := i.RollbackModel(ctx, "pets/4"); err != nil || version != 4 || i.lookupModel("pets") != ms[4] {
+		t.Fatalf("Unexpected rollback: %d, %v", version, err)
+	}
+	if _, err := i.RollbackModel(ctx, "none"); !errors.Is(err, ErrModelNotFound) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// 최근 2개의 버전 외에 별칭이 가리키는 버전만 보관
+	i.aliases = map[string]string{"stable": "pets/1"}
+	i.pruneVersions("pets")
+	for version, kept := range map[int]bool{1: true, 2: false, 3: true, 4: true} {
+		if _, err := os.Stat(versionPath(modelPath, version)); os.IsNotExist(err) == kept {
+			t.Errorf("Unexpected version directory %d: %v", version, err)
+		}
+		if (i.lookupModel("pets/"+strconv.Itoa(version)) != nil) != kept {
+			t.Errorf("Unexpected version %d", version)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package inference
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// 한번에 추론하는 frame 수
+	videoBatchSize = 16
+	// 추론하는 최대 frame 수 (fps에 따라 추론하는 동영상 길이가 달라짐)
+	maxVideoFrames = 300
+)
+
+// FrameSampler 동영상에서 초당 fps개의 frame을 JPEG 이미지로 추출 (예: 외부 프로그램)
+//
+// 여러 goroutine에서 동시에 호출 됨
+type FrameSampler interface {
+	SampleFrames(ctx context.Context, video []byte, fps float64) ([][]byte, error)
+}
+
+// FrameSamplerFunc 함수를 FrameSampler로 사용
+type FrameSamplerFunc func(ctx context.Context, video []byte, fps float64) ([][]byte, error)
+
+// SampleFrames f(ctx, video, fps) 호출
+func (f FrameSamplerFunc) SampleFrames(ctx context.Context, video []byte, fps float64) ([][]byte, error) {
+	return f(ctx, video, fps)
+}
+
+// CommandSampler 외부 프로그램으로 동영상의 frame 추출
+//
+// Args의 {input}은 동영상을 저장한 임시 파일 경로, {fps}는 초당 frame 수로 바꿔서 실행하며,
+// 프로그램은 추출한 JPEG 이미지들을 이어서 stdout으로 출력해야 함
+// (예: ffmpeg의 `-loglevel error -i {input} -vf fps={fps} -f image2pipe -c:v mjpeg -`)
+//
+// mp4는 파일 끝에 index(moov)가 있을 수 있어 stdin 대신 임시 파일로 전달
+type CommandSampler struct {
+	Path string
+	Args []string
+}
+
+// SampleFrames 프로그램을 실행하여 추출 된 frame 반환
+func (c *CommandSampler) SampleFrames(ctx context.Context, video []byte, fps float64) ([][]byte, error) {
+	tmp, err := ioutil.TempFile("", "video-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(video)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	replacer := strings.NewReplacer("{input}", tmp.Name(), "{fps}", strconv.FormatFloat(fps, 'f', -1, 64))
+	args := make([]string, len(c.Args))
+	for idx, arg := range c.Args {
+		args[idx] = replacer.Replace(arg)
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, c.Path, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %s: %s", c.Path, err, msg)
+		}
+		return nil, fmt.Errorf("%s: %s", c.Path, err)
+	}
+
+	return splitJPEGs(stdout.Bytes())
+}
+
+// splitJPEGs 이어진 JPEG 이미지들(Motion JPEG stream)을 이미지별로 나눔
+func splitJPEGs(data []byte) ([][]byte, error) {
+	var frames [][]byte
+	for len(data) > 0 {
+		size, err := jpegSize(data)
+		if err != nil {
+			return nil, fmt.Errorf("Frame %d: %s", len(frames), err)
+		}
+		frames = append(frames, data[:size])
+		data = data[size:]
+	}
+
+	return frames, nil
+}
+
+// jpegSize data의 처음부터 EOI marker까지인 JPEG 이미지의 크기
+//
+// segment는 길이로 건너뛰고, 이미지 데이터(SOS 이후)는 0xff00(stuffing)과 RST가 아닌 marker까지 건너뜀
+func jpegSize(data []byte) (int, error) {
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return 0, fmt.Errorf("missing SOI marker")
+	}
+
+	for pos := 2; pos+2 <= len(data); {
+		if data[pos] != 0xff {
+			return 0, fmt.Errorf("invalid marker 0x%02x at %d", data[pos], pos)
+		}
+
+		marker := data[pos+1]
+		switch {
+		case marker == 0xff:
+			pos++
+			continue
+		case marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7):
+			pos += 2
+			continue
+		case marker == 0xd9:
+			return pos + 2, nil
+		}
+
+		if pos+4 > len(data) {
+			break
+		}
+		pos += 2 + int(binary.BigEndian.Uint16(data[pos+2:pos+4]))
+
+		if marker == 0xda {
+			for pos+1 < len(data) && (data[pos] != 0xff || data[pos+1] == 0x00 || (data[pos+1] >= 0xd0 && data[pos+1] <= 0xd7)) {
+				pos++
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("missing EOI marker")
+}
+
+// VideoFrame 동영상 frame별 추론 결과
+type VideoFrame struct {
+	// 추출한 순서
+	Index int `json:"index"`
+	// 동영상 시작부터의 시간 (초)
+	Time      float64      `json:"time"`
+	Inference []InferLabel `json:"inference"`
+}
+
+// VideoVote frame별 1순위 클래스의 다수결 결과
+type VideoVote struct {
+	Label string `json:"label"`
+	// 1순위로 판단 된 frame 수와 비율
+	Frames int     `json:"frames"`
+	Ratio  float32 `json:"ratio"`
+}
+
+// VideoResult 동영상 추론 결과
+type VideoResult struct {
+	FPS    float64      `json:"fps"`
+	Frames []VideoFrame `json:"frames"`
+	// frame 수가 많은 순서의 1순위 클래스 (unknown 포함)
+	Majority []VideoVote `json:"majority"`
+	// 클래스별 확률의 frame 평균 (결과에 없는 frame은 0으로 계산, k개)
+	Average []InferLabel `json:"average"`
+}
+
+// aggregateFrames frame별 결과로 다수결과 평균 계산
+func aggregateFrames(frames []VideoFrame, k int) ([]VideoVote, []InferLabel) {
+	var (
+		votes    []VideoVote
+		voteIdx  = make(map[string]int)
+		average  []InferLabel
+		labelIdx = make(map[string]int)
+	)
+	for _, frame := range frames {
+		if len(frame.Inference) > 0 {
+			label := frame.Inference[0].Label
+			if idx, ok := voteIdx[label]; ok {
+				votes[idx].Frames++
+			} else {
+				voteIdx[label] = len(votes)
+				votes = append(votes, VideoVote{Label: label, Frames: 1})
+			}
+		}
+
+		for _, infer := range frame.Inference {
+			if infer.Unknown {
+				continue
+			}
+			if idx, ok := labelIdx[infer.Label]; ok {
+				average[idx].Prob += infer.Prob
+			} else {
+				labelIdx[infer.Label] = len(average)
+				average = append(average, InferLabel{
+					Label:       infer.Label,
+					Prob:        infer.Prob,
+					Index:       infer.Index,
+					DisplayName: infer.DisplayName,
+					Description: infer.Description,
+				})
+			}
+		}
+	}
+
+	for idx := range votes {
+		votes[idx].Ratio = float32(votes[idx].Frames) / float32(len(frames))
+	}
+	sort.SliceStable(votes, func(i, j int) bool {
+		return votes[i].Frames > votes[j].Frames
+	})
+
+	for idx := range average {
+		average[idx].Prob /= float32(len(frames))
+	}
+	sort.SliceStable(average, func(i, j int) bool {
+		return average[i].Prob > average[j].Prob
+	})
+	if k > 0 && len(average) > k {
+		average = average[:k]
+	}
+
+	return votes, average
+}
+
+// InferVideo 동영상에서 초당 fps개의 frame을 추출하여 batch로 추론하고, frame별 결과와 다수결, 평균 반환
+//
+// frame 추출은 FrameSampler를 사용하며, 지정하지 않았으면 ErrUnsupportedFormat 반환
+func (i *Inference) InferVideo(ctx context.Context, model string, video []byte, fps float64, k int, threshold float32) (*VideoResult, error) {
+	if i.frameSampler == nil {
+		return nil, fmt.Errorf("%w: video (no frame sampler)", ErrUnsupportedFormat)
+	}
+	if err := i.authorize(ctx, ActionInfer, model); err != nil {
+		return nil, err
+	}
+
+	images, err := i.frameSampler.SampleFrames(ctx, video, fps)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w: video: %s", ErrImageConversion, err)
+	}
+	if len(images) == 0 {
+		return nil, fmt.Errorf("%w: video: no frames", ErrImageConversion)
+	}
+	if len(images) > maxVideoFrames {
+		images = images[:maxVideoFrames]
+	}
+
+	result := &VideoResult{
+		FPS:    fps,
+		Frames: make([]VideoFrame, 0, len(images)),
+	}
+	for start := 0; start < len(images); start += videoBatchSize {
+		end := start + videoBatchSize
+		if end > len(images) {
+			end = len(images)
+		}
+
+		infers, err := i.InferBatch(ctx, model, images[start:end], "jpeg", k, threshold)
+		if err != nil {
+			return nil, fmt.Errorf("Frame %d ~ %d: %w", start, end-1, err)
+		}
+		for idx, infer := range infers {
+			result.Frames = append(result.Frames, VideoFrame{
+				Index:     start + idx,
+				Time:      float64(start+idx) / fps,
+				Inference: infer,
+			})
+		}
+	}
+	result.Majority, result.Average = aggregateFrames(result.Frames, k)
+
+	return result, nil
+}
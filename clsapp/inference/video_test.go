@@ -0,0 +1,93 @@
+package inference
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testJPEG content를 이미지 데이터로 담은 JPEG (APP1 segment에 EOI, SOI marker 포함)
+func testJPEG(content string) []byte {
+	return []byte("\xff\xd8" +
+		"\xff\xe1\x00\x06\xff\xd9\xff\xd8" +
+		"\xff\xc0\x00\x0b\x08\x00\x01\x00\x01\x01\x01\x11\x00" +
+		"\xff\xda\x00\x08\x01\x01\x00\x00\x3f\x00" +
+		content + "\xff\x00\xff\xd0" + content + "\xff\xd9")
+}
+
+func TestSplitJPEGs(t *testing.T) {
+	frames := [][]byte{testJPEG("roses"), testJPEG("tulips"), testJPEG("daisy")}
+
+	split, err := splitJPEGs(bytes.Join(frames, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(split) != len(frames) {
+		t.Fatalf("Unexpected number of frames: %d", len(split))
+	}
+	for idx := range frames {
+		if !bytes.Equal(split[idx], frames[idx]) {
+			t.Fatalf("Unexpected frame %d: %q", idx, split[idx])
+		}
+	}
+
+	truncated := bytes.Join(frames, nil)
+	if _, err := splitJPEGs(truncated[:len(truncated)-2]); err == nil {
+		t.Fatal("Truncated frame should fail")
+	}
+	if _, err := splitJPEGs([]byte("video")); err == nil {
+		t.Fatal("Non-JPEG output should fail")
+	}
+}
+
+func TestCommandSampler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "frames")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	frames := filepath.Join(dir, "frames")
+	ioutil.WriteFile(frames, append(testJPEG("roses"), testJPEG("tulips")...), 0644)
+
+	// {input}은 임시 파일로 전달하고 {fps}는 인자로 바꿈
+	s := &CommandSampler{Path: "sh", Args: []string{"-c", `test "$(cat "$1")" = video && test "$2" = 0.5 && cat "$3"`, "sh", "{input}", "{fps}", frames}}
+	images, err := s.SampleFrames(context.Background(), []byte("video"), 0.5)
+	if err != nil || len(images) != 2 || !bytes.Equal(images[1], testJPEG("tulips")) {
+		t.Fatalf("Unexpected frames: %d %v", len(images), err)
+	}
+
+	s = &CommandSampler{Path: "sh", Args: []string{"-c", "echo 'bad input' >&2; exit 1"}}
+	if _, err := s.SampleFrames(context.Background(), []byte("video"), 1); err == nil {
+		t.Fatal("Command failure should fail")
+	}
+}
+
+func TestAggregateFrames(t *testing.T) {
+	frames := []VideoFrame{
+		{Inference: []InferLabel{{Label: "roses", Prob: 0.6, Index: 2}, {Label: "tulips", Prob: 0.3, Index: 4}}},
+		{Inference: []InferLabel{{Label: "tulips", Prob: 0.9, Index: 4}, {Label: "roses", Prob: 0.1, Index: 2}}},
+		{Inference: []InferLabel{{Label: "roses", Prob: 0.4, Index: 2}}},
+		{Inference: []InferLabel{{Label: "unknown", Prob: 0.4, Index: -1, Unknown: true}}},
+	}
+
+	votes, average := aggregateFrames(frames, 1)
+	if len(votes) != 3 || votes[0].Label != "roses" || votes[0].Frames != 2 || votes[0].Ratio != 0.5 {
+		t.Fatalf("Unexpected votes: %+v", votes)
+	}
+	if len(average) != 1 || average[0].Label != "tulips" || average[0].Index != 4 || math.Abs(float64(average[0].Prob)-0.3) > 1e-6 {
+		t.Fatalf("Unexpected average: %+v", average)
+	}
+}
+
+func TestInferVideoWithoutSampler(t *testing.T) {
+	i := &Inference{}
+	if _, err := i.InferVideo(context.Background(), "default", []byte("video"), 1, 0, 0); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("Video without frame sampler should fail: %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package inference
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/jpeg"
+	"log"
+	"time"
+)
+
+// warmupImage 모델 입력 크기([height, width])의 회색 JPEG 이미지
+func warmupImage(inputShape []int32) ([]byte, error) {
+	height, width := 1, 1
+	if len(inputShape) >= 2 && inputShape[0] > 0 && inputShape[1] > 0 {
+		height, width = int(inputShape[0]), int(inputShape[1])
+	}
+
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for idx := range img.Pix {
+		img.Pix[idx] = 128
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// warmup 로드 된 모델을 합성 이미지로 m.warmupRuns번 실행
+//
+// 첫 요청이 디코더와 graph 초기화 지연을 겪지 않도록 상태를 modelStatusRun으로 바꾸기 전에 실행하며,
+// Hook은 실행하지 않음
+func (m *iModel) warmup(ctx context.Context) error {
+	if m.warmupRuns <= 0 {
+		return nil
+	}
+
+	t0 := time.Now()
+	b, err := warmupImage(m.inputShape)
+	if err != nil {
+		return err
+	}
+
+	for n := 0; n < m.warmupRuns; n++ {
+		if m.preprocessor != nil && m.checkClassifier() == nil {
+			if _, err := m.runPreprocessor(ctx, b, "jpeg"); err != nil {
+				return err
+			}
+			continue
+		}
+
+		input, err := m.prepareImage(ctx, b, "jpeg")
+		if err != nil {
+			return err
+		}
+
+		switch m.cfg.Classification {
+		case detectionClass:
+			_, err = m.backend.runDetection(ctx, input)
+		case segmentationClass:
+			_, err = m.backend.runSegmentation(ctx, input, m.cfg.threshold(0))
+		default:
+			_, err = m.backend.runImages(ctx, []imageInput{input})
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Warmup model(%s): %d runs in %s", m.name, m.warmupRuns, time.Since(t0))
+	return nil
+}
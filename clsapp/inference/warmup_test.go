@@ -0,0 +1,29 @@
+package inference
+
+import (
+	"bytes"
+	"image/jpeg"
+	"testing"
+)
+
+func TestWarmupImage(t *testing.T) {
+	for _, tt := range []struct {
+		shape         []int32
+		height, width int
+	}{
+		{[]int32{224, 160}, 224, 160},
+		{nil, 1, 1},
+	} {
+		b, err := warmupImage(tt.shape)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Height != tt.height || cfg.Width != tt.width {
+			t.Fatalf("Unexpected size of %v: %dx%d", tt.shape, cfg.Height, cfg.Width)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package inference
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// readyFile 모델 저장 경로에 복사한 모델 디렉토리의 파일을 모두 쓴 후 만드는 파일
+//
+// 감시 중에 추가 된 디렉토리는 이 파일이 있어야 로드하여, 복사 중인 모델을 로드하지 않음
+const readyFile = "READY"
+
+// modelsWatcher 모델 저장 경로를 감시하여 추가 된 모델을 로드하고 삭제 된 모델을 해제
+type modelsWatcher struct {
+	i        *Inference
+	watcher  *fsnotify.Watcher
+	debounce time.Duration
+
+	// 로드에 실패한 디렉토리와 READY 파일의 수정 시간 (READY 파일이 바뀌어야 다시 로드)
+	failed map[string]time.Time
+
+	done     chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+// watchModels 모델 저장 경로의 감시 시작
+func (i *Inference) watchModels(debounce time.Duration) (*modelsWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := watcher.Add(i.modelsPath); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	w := &modelsWatcher{
+		i:        i,
+		watcher:  watcher,
+		debounce: debounce,
+		failed:   make(map[string]time.Time),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	// 감시 시작 전에 복사 중이던 디렉토리도 READY 파일을 감시
+	w.watchPending(i.syncModels(w.failed))
+
+	go w.run()
+
+	return w, nil
+}
+
+// run 마지막 변경 후 debounce 동안 변경이 없으면 모델 저장 경로와 로드 된 모델을 맞춤
+func (w *modelsWatcher) run() {
+	defer close(w.stopped)
+
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			// 전처리 graph, 사용자 모델 저장 경로 등은 감시 대상이 아님
+			if strings.HasPrefix(filepath.Base(event.Name), ".") {
+				continue
+			}
+			timer.Reset(w.debounce)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("Fail to watch models path(%s): %s", w.i.modelsPath, err)
+		case <-timer.C:
+			w.watchPending(w.i.syncModels(w.failed))
+		}
+	}
+}
+
+// watchPending READY 파일을 기다리는 디렉토리 감시
+func (w *modelsWatcher) watchPending(pending []string) {
+	for _, modelPath := range pending {
+		if err := w.watcher.Add(modelPath); err != nil {
+			log.Printf("Fail to watch model path(%s): %s", modelPath, err)
+		}
+	}
+}
+
+// stop 감시 종료
+func (w *modelsWatcher) stop() {
+	w.stopOnce.Do(func() {
+		close(w.done)
+		<-w.stopped
+		w.watcher.Close()
+	})
+}
+
+// syncModels 모델 저장 경로에 추가 된 모델을 로드하고 디렉토리가 삭제 된 모델을 해제
+//
+// 로드 된 모델이 없는 디렉토리는 READY 파일이 있는 경우에만 로드하며, 실패해도 파일을 지우지 않음.
+// READY 파일을 기다리는 디렉토리를 반환
+func (i *Inference) syncModels(failed map[string]time.Time) []string {
+	dirs, err := ioutil.ReadDir(i.modelsPath)
+	if err != nil {
+		log.Printf("Fail to read models path(%s): %s", i.modelsPath, err)
+		return nil
+	}
+
+	var pending []string
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		if !dir.IsDir() || strings.HasPrefix(dir.Name(), ".") || strings.HasSuffix(dir.Name(), migratingSuffix) {
+			continue
+		}
+
+		modelPath := filepath.Join(i.modelsPath, dir.Name())
+		seen[modelPath] = true
+
+		i.rwMutex.RLock()
+		owned := i.ownedPath(modelPath)
+		i.rwMutex.RUnlock()
+		if owned {
+			continue
+		}
+
+		ready, err := os.Stat(filepath.Join(modelPath, readyFile))
+		if err != nil {
+			pending = append(pending, modelPath)
+			continue
+		}
+		if t, ok := failed[modelPath]; ok && t.Equal(ready.ModTime()) {
+			continue
+		}
+
+		if err := i.loadWatchedModel(modelPath); err != nil {
+			log.Printf("Fail to load model(%s): %s", modelPath, err)
+			failed[modelPath] = ready.ModTime()
+			continue
+		}
+		delete(failed, modelPath)
+	}
+
+	for modelPath := range failed {
+		if !seen[modelPath] {
+			delete(failed, modelPath)
+		}
+	}
+
+	i.unloadRemovedModels()
+
+	return pending
+}
+
+// loadWatchedModel 모델 저장 경로에 추가 된 모델 디렉토리를 로드
+func (i *Inference) loadWatchedModel(modelPath string) error {
+	m := getNewModel("", modelPath)
+	if err := i.load(m); err != nil {
+		return err
+	}
+
+	i.rwMutex.Lock()
+	err := i.addModel(m)
+	i.rwMutex.Unlock()
+	if err != nil {
+		m.destroy()
+		return err
+	}
+
+	log.Printf("Load model(%s): %s", m.name, modelPath)
+	i.loadVersions(m)
+
+	return nil
+}
+
+// unloadRemovedModels 모델 디렉토리가 삭제 된 모델 해제
+//
+// fallback 모델, 사용자 모델과 생성 중인 모델은 해제하지 않으며, 사용 중인 모델은 사용이 끝난 후 해제
+func (i *Inference) unloadRemovedModels() {
+	var removed []*iModel
+
+	i.rwMutex.Lock()
+	for model, m := range i.models {
+		if m.readOnly || m.tenant != "" || atomic.LoadInt32(&m.status) != modelStatusRun {
+			continue
+		}
+		if root, ok := i.modelRoot(m.modelPath); !ok || root != filepath.Clean(m.modelPath) {
+			continue
+		}
+		if _, err := os.Stat(m.modelPath); !os.IsNotExist(err) {
+			continue
+		}
+
+		delete(i.models, model)
+		removed = append(append(removed, m), i.delVersions(model)...)
+	}
+	i.rwMutex.Unlock()
+
+	for _, m := range removed {
+		log.Printf("Unload removed model(%s): %s", m.name, m.modelPath)
+		go m.release()
+	}
+}
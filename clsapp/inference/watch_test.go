@@ -0,0 +1,87 @@
+package inference
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSyncModels(t *testing.T) {
+	modelsPath := newModelsPath(t)
+	defer os.RemoveAll(modelsPath)
+
+	loaded := &iModel{name: "pets", modelPath: filepath.Join(modelsPath, "pets-1234"), status: modelStatusRun}
+	fallback := &iModel{name: "default", modelPath: filepath.Join(modelsPath, "default"), status: modelStatusRun, readOnly: true}
+	building := &iModel{name: "birds", modelPath: filepath.Join(modelsPath, "birds-5678"), status: modelStatusBuild}
+	i := &Inference{
+		models:     map[string]*iModel{"pets": loaded, "default": fallback, "birds": building},
+		modelsPath: modelsPath,
+	}
+
+	// 복사 중인 디렉토리는 READY 파일을 기다림
+	copying := filepath.Join(modelsPath, "flowers-1234")
+	writeV1Model(t, copying, "flowers")
+	// 로드 할 수 없는 모델도 파일을 지우지 않음
+	invalid := filepath.Join(modelsPath, "cars-1234")
+	writeV1Model(t, invalid, "cars")
+	if err := ioutil.WriteFile(filepath.Join(invalid, readyFile), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	failed := make(map[string]time.Time)
+	if pending := i.syncModels(failed); !reflect.DeepEqual(pending, []string{copying}) {
+		t.Fatalf("Unexpected pending: %v", pending)
+	}
+	if _, ok := failed[invalid]; !ok || !isFile(filepath.Join(invalid, configFile)) {
+		t.Fatalf("Invalid model should remain: %v", failed)
+	}
+
+	// 디렉토리가 삭제 된 모델만 해제
+	if len(i.models) != 2 || i.models["default"] != fallback || i.models["birds"] != building {
+		t.Fatalf("Unexpected models: %v", i.models)
+	}
+
+	// 실패한 디렉토리는 삭제되면 다시 기록하지 않음
+	os.RemoveAll(invalid)
+	i.syncModels(failed)
+	if len(failed) != 0 {
+		t.Fatalf("Unexpected failed: %v", failed)
+	}
+}
+
+func TestWatchModels(t *testing.T) {
+	modelsPath := newModelsPath(t)
+	defer os.RemoveAll(modelsPath)
+
+	modelPath := filepath.Join(modelsPath, "pets-1234")
+	if err := os.Mkdir(modelPath, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	i := &Inference{
+		models:     map[string]*iModel{"pets": {name: "pets", modelPath: modelPath, status: modelStatusRun}},
+		modelsPath: modelsPath,
+	}
+
+	w, err := i.watchModels(10 * time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.stop()
+
+	if err := os.RemoveAll(modelPath); err != nil {
+		t.Fatal(err)
+	}
+
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		i.rwMutex.RLock()
+		n := len(i.models)
+		i.rwMutex.RUnlock()
+		if n == 0 {
+			return
+		}
+	}
+	t.Fatal("Removed model should be unloaded")
+}
@@ -1,65 +1,93 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/gin-gonic/gin"
 	"github.com/harrison-roh/cleanuphttp"
 	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/api"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/constants"
 	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/data"
 	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/inference"
 )
 
 func main() {
-	userModelPath := flag.String("usermodel", "", "Path for user inference model")
 	learnHost := flag.String("learnhost", "learnapp:18090", "Model learning host")
+	fallbackModelPath := flag.String("fallbackmodel", constants.FallbackModelPath, "Path for fallback default model (disabled if empty)")
+	dirNaming := flag.String("dirnaming", inference.DirNamingShortUUID, "Directory naming of new models (shortuuid, uuid, timestamp, semver, plain)")
+	graphCachePath := flag.String("graphcache", "", "Path for prebuilt preprocessing graphs (default: <models>/.graphs, disabled if \"-\")")
+	tenantQuota := flag.Int64("tenantquota", 1024, "Default model storage quota of tenant in MB (unlimited if 0)")
+	thumbnailSizes := flag.String("thumbnailsizes", "128,256", "Comma separated thumbnail sizes of stored images")
+	maxImageSize := flag.Int64("maximagesize", 20, "Max size of images to infer in MB (unlimited if 0)")
+	maxImageWidth := flag.Int("maximagewidth", 8192, "Max width of images to infer (unlimited if 0)")
+	maxImageHeight := flag.Int("maximageheight", 8192, "Max height of images to infer (unlimited if 0)")
+	probeDecode := flag.Bool("probedecode", false, "Fully decode images in Go before inference to detect corrupt images")
+	heicConverter := flag.String("heicconverter", "", "Command converting HEIC from stdin to JPEG on stdout, e.g. \"magick heic:- jpeg:-\" (disabled if empty)")
+	videoSampler := flag.String("videosampler", "", "Command extracting JPEG frames of video {input} at {fps} to stdout, e.g. \"ffmpeg -loglevel error -i {input} -vf fps={fps} -f image2pipe -c:v mjpeg -\" (disabled if empty)")
+	pipelinesFile := flag.String("pipelines", "", "Path for pipelines config of gate and classification models (disabled if empty)")
+	warmup := flag.Int("warmup", 1, "Number of warmup runs with a synthetic image after loading a model (disabled if 0)")
+	preDownscale := flag.Float64("predownscale", 0, "Downscale images larger than this multiple of the model input size in Go before decoding (disabled if 0)")
+	cacheSize := flag.Int("cachesize", 0, "Max number of cached inference results by image content (disabled if 0)")
+	cacheTTL := flag.Duration("cachettl", 10*time.Minute, "TTL of cached inference results (no expiration if 0)")
+	watchDebounce := flag.Duration("watch", 0, "Debounce for watching models directory to load added and unload removed models (disabled if 0)")
+	keepVersions := flag.Int("keepversions", 5, "Number of recent versions to keep per model (keep all if 0)")
+	memoryBudget := flag.Int64("memorybudget", 0, "Max estimated memory of loaded models in MB (no limit if 0)")
+	fetchMaxSize := flag.Int64("fetchmaxsize", 10, "Max size of images fetched by URL in MB")
+	fetchTimeout := flag.Duration("fetchtimeout", 10*time.Second, "Timeout for fetching images by URL")
+	fetchPrivate := flag.Bool("fetchprivate", false, "Allow fetching images from private network addresses")
+	bulkRoot := flag.String("bulkroot", "", "Path for server directories of bulk inference (disabled if empty)")
 	flag.Parse()
 
-	i, err := inference.New(inference.Config{
-		UserModelPath: *userModelPath,
-		LHost:         *learnHost,
-	})
+	sizes, err := parseSizes(*thumbnailSizes)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	m, err := data.New()
+	i, err := inference.New(
+		inference.WithLearner(*learnHost),
+		inference.WithFallbackModel(*fallbackModelPath),
+		inference.WithTenantQuota(*tenantQuota<<20),
+		inference.WithDirNaming(*dirNaming),
+		inference.WithCache(*graphCachePath),
+		inference.WithImageLimits(*maxImageSize<<20, *maxImageWidth, *maxImageHeight),
+		inference.WithDecodeProbe(*probeDecode),
+		inference.WithHEICConverter(newHEICConverter(*heicConverter)),
+		inference.WithPreDownscale(*preDownscale),
+		inference.WithWarmup(*warmup),
+		inference.WithPipelines(*pipelinesFile),
+		inference.WithFrameSampler(newFrameSampler(*videoSampler)),
+		inference.WithResultCache(*cacheSize, *cacheTTL),
+		inference.WithWatch(*watchDebounce),
+		inference.WithKeepVersions(*keepVersions),
+		inference.WithMemoryBudget(*memoryBudget<<20),
+	)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	r := gin.Default()
-	r.MaxMultipartMemory = 8 << 20
+	m, err := data.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	m.ThumbnailSizes = sizes
 
-	a := api.APIs{
+	r := api.NewRouter(&api.APIs{
 		I: i,
 		M: m,
-	}
-
-	inferenceGroup := r.Group("/inference")
-	{
-		inferenceGroup.POST("", a.InferDefault)
-		inferenceGroup.POST(":model", a.InferWithModel)
-	}
-
-	modelsGroup := r.Group("/models")
-	{
-		modelsGroup.GET("", a.ListModels)
-		modelsGroup.GET(":model", a.ShowModel)
-		modelsGroup.POST(":model", a.CreateModel)
-		modelsGroup.PUT(":model", a.OperateModel)
-		modelsGroup.DELETE(":model", a.DeleteModel)
-	}
-
-	imagesGroup := r.Group("/images")
-	{
-		imagesGroup.GET("", a.ListImages)
-		imagesGroup.POST("", a.UploadImages)
-		imagesGroup.DELETE("", a.DeleteImages)
-	}
+		F: &api.ImageFetcher{
+			MaxSize:      *fetchMaxSize << 20,
+			Timeout:      *fetchTimeout,
+			AllowPrivate: *fetchPrivate,
+		},
+		BulkRoot:     *bulkRoot,
+		MaxImageSize: *maxImageSize << 20,
+	})
 
 	server := &http.Server{
 		Addr:    ":18080",
@@ -71,9 +99,46 @@ func main() {
 	cleanuphttp.Serve(server, 5*time.Second)
 }
 
+func parseSizes(s string) ([]int, error) {
+	var sizes []int
+	for _, field := range strings.Split(s, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("Invalid size: %q", field)
+		}
+		sizes = append(sizes, size)
+	}
+
+	return sizes, nil
+}
+
+// newHEICConverter 공백으로 구분 된 명령으로 HEICConverter 생성 (빈 값이면 nil)
+func newHEICConverter(command string) inference.HEICConverter {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return &inference.CommandConverter{Path: fields[0], Args: fields[1:]}
+}
+
+// newFrameSampler 공백으로 구분 된 명령으로 FrameSampler 생성 (빈 값이면 nil)
+func newFrameSampler(command string) inference.FrameSampler {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return &inference.CommandSampler{Path: fields[0], Args: fields[1:]}
+}
+
 func cleanupInference(arg interface{}) {
 	i := arg.(*inference.Inference)
-	i.Destroy()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	i.Destroy(ctx)
 }
 
 func cleanupData(arg interface{}) {
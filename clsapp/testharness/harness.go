@@ -0,0 +1,281 @@
+// Package testharness 임시 모델 디렉토리와 가짜 learner를 이용해 clsapp 서비스를 띄우는 테스트 도구
+//
+// golden 모델은 SavedModel 없이 config와 labels만 포함하기 때문에 `-tags fake`로 빌드 된 추론 엔진에서만 동작함.
+// 실제 TensorFlow 추론 엔진의 end-to-end 테스트는 지원하지 않음.
+//
+// 이미지 데이터는 MySQL이 필요하므로 이미지 API(`/images`)는 등록하지 않음
+package testharness
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/api"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/constants"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/inference"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// GoldenMulti 다중 분류 golden 모델 경로
+	GoldenMulti = goldenPath("multi")
+	// GoldenBinary 이진 분류 golden 모델 경로
+	GoldenBinary = goldenPath("binary")
+)
+
+func goldenPath(name string) string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "testdata", name)
+}
+
+// InstallModel golden 모델을 modelPath에 복사하고 config의 모델 정보를 변경
+func InstallModel(golden, modelPath, model, modelType, desc string) error {
+	if err := os.MkdirAll(modelPath, os.ModePerm); err != nil {
+		return err
+	}
+
+	files, err := ioutil.ReadDir(golden)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if file.IsDir() || file.Name() == "config.yaml" {
+			continue
+		}
+
+		b, err := ioutil.ReadFile(filepath.Join(golden, file.Name()))
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(modelPath, file.Name()), b, 0644); err != nil {
+			return err
+		}
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(golden, "config.yaml"))
+	if err != nil {
+		return err
+	}
+
+	cfg := make(map[string]interface{})
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return err
+	}
+
+	cfg["name"] = model
+	if modelType != "" {
+		cfg["type"] = modelType
+	}
+	if desc != "" {
+		cfg["description"] = desc
+	}
+
+	if b, err = yaml.Marshal(cfg); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(modelPath, "config.yaml"), b, 0644)
+}
+
+// Harness 테스트를 위해 실행 된 clsapp 서비스
+type Harness struct {
+	URL        string
+	ModelsPath string
+	Learner    *Learner
+	Inference  *inference.Inference
+
+	server *httptest.Server
+}
+
+// Start 임시 모델 디렉토리에 기본 모델을 설치하고 가짜 learner와 연결 된 서비스 시작
+//
+// 생성 된 자원은 테스트 종료시 모두 정리 됨
+func Start(t testing.TB) *Harness {
+	t.Helper()
+
+	modelsPath, err := ioutil.TempDir("", "clsapp-models-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defaultPath := filepath.Join(modelsPath, constants.DefaultModelName+"-golden")
+	if err := InstallModel(GoldenMulti, defaultPath, constants.DefaultModelName, modelTypeBase, ""); err != nil {
+		os.RemoveAll(modelsPath)
+		t.Fatal(err)
+	}
+
+	// learner가 모델 생성 완료를 알릴 수 있도록 서비스 주소를 먼저 할당
+	server := httptest.NewUnstartedServer(nil)
+	learner := NewLearner(server.Listener.Addr().String())
+
+	i, err := inference.New(
+		inference.WithModelsPath(modelsPath),
+		inference.WithLearner(learner.Host),
+	)
+	if err != nil {
+		learner.Close()
+		server.Close()
+		os.RemoveAll(modelsPath)
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	server.Config.Handler = api.NewRouter(&api.APIs{
+		I: i,
+	})
+	server.Start()
+
+	h := &Harness{
+		URL:        server.URL,
+		ModelsPath: modelsPath,
+		Learner:    learner,
+		Inference:  i,
+		server:     server,
+	}
+
+	t.Cleanup(h.close)
+
+	return h
+}
+
+func (h *Harness) close() {
+	h.Learner.Close()
+	h.server.Close()
+	h.Inference.Destroy(context.Background())
+	os.RemoveAll(h.ModelsPath)
+}
+
+// Do 서비스에 요청을 보내고 json 응답을 v에 저장
+func (h *Harness) Do(method, path string, body io.Reader, contentType string, v interface{}) (int, error) {
+	req, err := http.NewRequest(method, h.URL+path, body)
+	if err != nil {
+		return 0, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if v != nil {
+		if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+			return res.StatusCode, err
+		}
+	}
+
+	return res.StatusCode, nil
+}
+
+// Infer multipart 형식으로 이미지 추론 요청
+//
+// model은 querystring을 포함할 수 있음 (예: "mymodel?k=3")
+func (h *Harness) Infer(model, fileName string, image []byte, v interface{}) (int, error) {
+	var body bytes.Buffer
+
+	w := multipart.NewWriter(&body)
+	fw, err := w.CreateFormFile("image", fileName)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := fw.Write(image); err != nil {
+		return 0, err
+	}
+	if err := w.Close(); err != nil {
+		return 0, err
+	}
+
+	path := "/inference"
+	if model != "" {
+		path = fmt.Sprintf("/inference/%s", model)
+	}
+
+	return h.Do(http.MethodPost, path, &body, w.FormDataContentType(), v)
+}
+
+// FakeJPEG content를 이미지 데이터로 담은 1x1 JPEG
+//
+// 가짜 추론 엔진은 디코딩하지 않으므로, 손상 된 이미지 검사를 통과하는 구조만 갖춤
+func FakeJPEG(content string) []byte {
+	return []byte("\xff\xd8" +
+		// SOF0: 8bit, 1x1, 1 component
+		"\xff\xc0\x00\x0b\x08\x00\x01\x00\x01\x01\x01\x11\x00" +
+		// SOS: 1 component
+		"\xff\xda\x00\x08\x01\x01\x00\x00\x3f\x00" +
+		content + "\xff\xd9")
+}
+
+// FakePNG content를 IDAT chunk로 담은 1x1 PNG
+//
+// FakeJPEG와 같이 손상 된 이미지 검사를 통과하는 구조만 갖춤
+func FakePNG(content string) []byte {
+	chunk := func(name, data string) string {
+		var b bytes.Buffer
+		binary.Write(&b, binary.BigEndian, uint32(len(data)))
+		b.WriteString(name + data)
+		binary.Write(&b, binary.BigEndian, crc32.ChecksumIEEE([]byte(name+data)))
+		return b.String()
+	}
+
+	return []byte("\x89PNG\r\n\x1a\n" +
+		// 1x1, 8bit RGB
+		chunk("IHDR", "\x00\x00\x00\x01\x00\x00\x00\x01\x08\x02\x00\x00\x00") +
+		chunk("IDAT", content) +
+		chunk("IEND", ""))
+}
+
+// WaitModel 모델이 run 상태가 될 때까지 대기
+func (h *Harness) WaitModel(model string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		var info map[string]interface{}
+		status, err := h.Do(http.MethodGet, "/models/"+model, nil, "", &info)
+		if err == nil && status == http.StatusOK && info["status"] == "run" {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			if err == nil {
+				err = fmt.Errorf("Model %s not running: (%d) %v", model, status, info)
+			}
+			return err
+		}
+
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// ModelDirs 모델 저장 경로의 디렉토리 목록 반환
+func (h *Harness) ModelDirs(prefix string) []string {
+	var dirs []string
+
+	files, _ := ioutil.ReadDir(h.ModelsPath)
+	for _, file := range files {
+		if file.IsDir() && strings.HasPrefix(file.Name(), prefix) {
+			dirs = append(dirs, file.Name())
+		}
+	}
+
+	return dirs
+}
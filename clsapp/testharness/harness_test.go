@@ -0,0 +1,846 @@
+//go:build fake
+// +build fake
+
+package testharness
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/constants"
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/inference"
+)
+
+type inferResponse struct {
+	File      string `json:"file"`
+	Format    string `json:"format"`
+	Inference []struct {
+		Prob  float32 `json:"probability"`
+		Label string  `json:"label"`
+	} `json:"inference"`
+}
+
+func TestInferDefault(t *testing.T) {
+	h := Start(t)
+
+	var res inferResponse
+	status, err := h.Infer("", "roses.jpg", FakeJPEG("roses"), &res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != http.StatusOK {
+		t.Fatalf("Unexpected status: %d", status)
+	}
+	if len(res.Inference) != 5 {
+		t.Fatalf("Unexpected number of labels: %d", len(res.Inference))
+	}
+
+	var again inferResponse
+	if _, err := h.Infer("", "roses.jpg", FakeJPEG("roses"), &again); err != nil {
+		t.Fatal(err)
+	}
+	if again.Inference[0] != res.Inference[0] {
+		t.Fatalf("Not deterministic: %v, %v", res.Inference[0], again.Inference[0])
+	}
+
+	if status, _ := h.Infer("", "roses.tiff", []byte("roses"), nil); status != http.StatusUnsupportedMediaType {
+		t.Fatalf("Unsupported format should fail: %d", status)
+	}
+}
+
+func TestInferTiming(t *testing.T) {
+	h := Start(t)
+
+	var res struct {
+		Timing map[string]float64 `json:"timing"`
+	}
+	status, err := h.Infer(constants.DefaultModelName+"?timing", "roses.jpg", FakeJPEG("roses"), &res)
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("Unexpected response: %d, %v", status, err)
+	}
+	for _, stage := range []string{"preprocess(ms)", "run(ms)", "postprocess(ms)"} {
+		if v, ok := res.Timing[stage]; !ok || v < 0 {
+			t.Fatalf("Unexpected timing: %v", res.Timing)
+		}
+	}
+
+	res.Timing = nil
+	if _, err := h.Infer("", "roses.jpg", FakeJPEG("roses"), &res); err != nil || res.Timing != nil {
+		t.Fatalf("Timing should be opt-in: %v, %v", res.Timing, err)
+	}
+}
+
+func TestInferRaw(t *testing.T) {
+	h := Start(t)
+
+	ctx := context.Background()
+	result, err := h.Inference.InferRaw(ctx, constants.DefaultModelName, FakeJPEG("roses"), "jpg", 3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Outputs) != 1 || len(result.Outputs[0]) != len(result.Labels) || result.Activation != "none" {
+		t.Fatalf("Unexpected raw result: %+v", result)
+	}
+
+	infers, err := h.Inference.Infer(ctx, constants.DefaultModelName, FakeJPEG("roses"), "jpg", 3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Inference) != 3 || !reflect.DeepEqual(result.Inference[0], infers[0]) {
+		t.Fatalf("Unexpected inference: %v, %v", result.Inference, infers)
+	}
+}
+
+func TestInferBatch(t *testing.T) {
+	h := Start(t)
+
+	ctx := context.Background()
+	images := [][]byte{FakeJPEG("roses"), FakeJPEG("tulips"), FakeJPEG("daisy")}
+
+	results, err := h.Inference.InferBatch(ctx, constants.DefaultModelName, images, "jpg", 3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(images) {
+		t.Fatalf("Unexpected number of results: %d", len(results))
+	}
+
+	// 각 이미지를 따로 추론한 결과와 같아야 함
+	for idx, image := range images {
+		infers, err := h.Inference.Infer(ctx, constants.DefaultModelName, image, "jpg", 3, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(results[idx][0], infers[0]) {
+			t.Fatalf("Unexpected result of image %d: %v, %v", idx, results[idx], infers)
+		}
+	}
+
+	if _, err := h.Inference.InferBatch(ctx, constants.DefaultModelName, images, "tiff", 3, 0); err == nil {
+		t.Fatal("Unsupported format should fail")
+	}
+}
+
+func TestInferAll(t *testing.T) {
+	h := Start(t)
+
+	if status, err := h.Do(http.MethodPost, "/models/flowers?desc=flowers&epochs=1", nil, "", nil); err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to create: (%d) %v", status, err)
+	}
+	if err := h.WaitModel("flowers", 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	results, err := h.Inference.InferAll(ctx, nil, FakeJPEG("roses"), "jpg", 3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Model != constants.DefaultModelName || results[1].Model != "flowers" {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+	for _, result := range results {
+		if result.Error != "" || len(result.Inference) != 3 {
+			t.Fatalf("Unexpected result: %+v", result)
+		}
+	}
+
+	if results, err := h.Inference.InferAll(ctx, []string{"unknown"}, FakeJPEG("roses"), "jpg", 3, 0); err != nil || len(results) != 0 {
+		t.Fatalf("No model should match the tag: %v, %v", results, err)
+	}
+
+	if _, err := h.Inference.InferAll(ctx, nil, FakeJPEG("roses"), "tiff", 3, 0); err == nil {
+		t.Fatal("Unsupported format should fail")
+	}
+}
+
+func TestImagesUnsupported(t *testing.T) {
+	h := Start(t)
+
+	// 이미지 데이터 관리자가 없으면 이미지 API는 등록되지 않음
+	if status, err := h.Do(http.MethodGet, "/images", nil, "", nil); err != nil || status != http.StatusNotFound {
+		t.Fatalf("Unexpected images response: (%d) %v", status, err)
+	}
+}
+
+func TestCreateInferDelete(t *testing.T) {
+	h := Start(t)
+
+	status, err := h.Do(http.MethodPost, "/models/flowers?desc=flowers&epochs=1", nil, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != http.StatusOK {
+		t.Fatalf("Unexpected status: %d", status)
+	}
+
+	if err := h.WaitModel("flowers", 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	req, ok := h.Learner.Requests()["flowers"]
+	if !ok {
+		t.Fatal("Learner did not receive the request")
+	}
+	if req.Epochs != 1 || req.Description != "flowers" {
+		t.Fatalf("Unexpected request: %+v", req)
+	}
+
+	var res inferResponse
+	if status, err := h.Infer("flowers", "tulips.png", FakePNG("tulips"), &res); err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to infer: (%d) %v", status, err)
+	}
+	if len(res.Inference) != 5 {
+		t.Fatalf("Unexpected number of labels: %d", len(res.Inference))
+	}
+
+	var models struct {
+		Models []string `json:"models"`
+	}
+	if _, err := h.Do(http.MethodGet, "/models", nil, "", &models); err != nil {
+		t.Fatal(err)
+	}
+	if len(models.Models) != 2 {
+		t.Fatalf("Unexpected models: %v", models.Models)
+	}
+
+	if status, err := h.Do(http.MethodDelete, "/models/flowers", nil, "", nil); err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to delete: (%d) %v", status, err)
+	}
+	if dirs := h.ModelDirs("flowers-"); len(dirs) != 0 {
+		t.Fatalf("Model directory remains: %v", dirs)
+	}
+
+	if status, _ := h.Infer("flowers", "tulips.png", FakePNG("tulips"), nil); status == http.StatusOK {
+		t.Fatal("Deleted model should not infer")
+	}
+}
+
+func TestCreateTrialModel(t *testing.T) {
+	h := Start(t)
+
+	if status, err := h.Do(http.MethodPost, "/models/pets?trial", nil, "", nil); err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to create: (%d) %v", status, err)
+	}
+
+	if err := h.WaitModel("pets", 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	var res inferResponse
+	if status, err := h.Infer("pets", "cat.jpg", FakeJPEG("cat"), &res); err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to infer: (%d) %v", status, err)
+	}
+	if len(res.Inference) != 2 {
+		t.Fatalf("Unexpected number of labels: %d", len(res.Inference))
+	}
+	if sum := res.Inference[0].Prob + res.Inference[1].Prob; sum < 0.999 || sum > 1.001 {
+		t.Fatalf("Invalid sum of probabilities: %f", sum)
+	}
+
+	// threshold를 극단적으로 지정하면 판단 결과가 고정 됨
+	for threshold, label := range map[string]string{"0.0001": "dog", "0.9999": "cat"} {
+		var res inferResponse
+		if _, err := h.Infer("pets?threshold="+threshold, "cat.jpg", FakeJPEG("cat"), &res); err != nil {
+			t.Fatal(err)
+		}
+		if res.Inference[0].Label != label {
+			t.Fatalf("Unexpected label with threshold %s: %v", threshold, res.Inference)
+		}
+	}
+}
+
+func TestFallbackModel(t *testing.T) {
+	modelsPath, err := ioutil.TempDir("", "clsapp-models-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(modelsPath)
+
+	fallbackPath, err := ioutil.TempDir("", "clsapp-fallback-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(fallbackPath)
+
+	if err := InstallModel(GoldenMulti, fallbackPath, "fallback", "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// 모델이 없고 learner에 연결할 수 없는 경우 fallback 모델을 기본 모델로 사용
+	i, err := inference.New(
+		inference.WithModelsPath(modelsPath),
+		inference.WithLearner("127.0.0.1:1"),
+		inference.WithFallbackModel(fallbackPath),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer i.Destroy(context.Background())
+
+	ctx := context.Background()
+	if _, err := i.Infer(ctx, constants.DefaultModelName, FakeJPEG("image"), "jpg", 1, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := i.DeleteModel(ctx, constants.DefaultModelName); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(fallbackPath); err != nil {
+		t.Fatalf("Fallback model should not be removed: %s", err)
+	}
+}
+
+func TestReloadModel(t *testing.T) {
+	h := Start(t)
+
+	// 시작시 v2 구조로 변환 된 기본 모델의 labels 파일
+	labelsFiles, err := filepath.Glob(filepath.Join(h.ModelsPath, constants.DefaultModelName+"-golden", "versions", "*", "lables"))
+	if err != nil || len(labelsFiles) != 1 {
+		t.Fatalf("Unexpected labels files: %v, %v", labelsFiles, err)
+	}
+	if err := ioutil.WriteFile(labelsFiles[0], []byte("Daisy\nDandelion\nRoses\nSunflowers\nTulips\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if status, err := h.Do(http.MethodPost, "/models/default/reload", nil, "", nil); err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to reload: (%d) %v", status, err)
+	}
+
+	var res inferResponse
+	if _, err := h.Infer("", "roses.jpg", FakeJPEG("roses"), &res); err != nil {
+		t.Fatal(err)
+	}
+	for _, infer := range res.Inference {
+		if strings.ToUpper(infer.Label[:1]) != infer.Label[:1] {
+			t.Fatalf("Labels should be reloaded: %v", res.Inference)
+		}
+	}
+
+	// 로드에 실패하면 기존 모델을 계속 사용
+	if err := ioutil.WriteFile(labelsFiles[0], nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := h.Do(http.MethodPost, "/models/default/reload", nil, "", nil); status == http.StatusOK {
+		t.Fatal("Invalid labels should fail")
+	}
+	if status, err := h.Infer("", "roses.jpg", FakeJPEG("roses"), nil); err != nil || status != http.StatusOK {
+		t.Fatalf("Previous model should be used: (%d) %v", status, err)
+	}
+
+	if status, _ := h.Do(http.MethodPost, "/models/none/reload", nil, "", nil); status != http.StatusNotFound {
+		t.Fatalf("Unknown model should fail: %d", status)
+	}
+}
+
+func TestCreateVersion(t *testing.T) {
+	h := Start(t)
+
+	var res inference.CreateResult
+	if status, err := h.Do(http.MethodPost, "/models/default/versions?epochs=1", nil, "", &res); err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to create version: (%d) %v", status, err)
+	}
+	if res.Version != 2 {
+		t.Fatalf("Unexpected version: %+v", res)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var info inference.ModelInfo
+		if _, err := h.Do(http.MethodGet, "/models/default", nil, "", &info); err != nil {
+			t.Fatal(err)
+		}
+		if info.Version == 2 && reflect.DeepEqual(info.Versions, []int{1, 2}) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("New version not serving: %+v", info)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// 이전 버전도 버전을 지정하여 추론
+	var served struct {
+		Served []inference.ServedModel `json:"served"`
+	}
+	if status, err := h.Infer("default?version=1", "roses.jpg", FakeJPEG("roses"), &served); err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to infer previous version: (%d) %v", status, err)
+	}
+	if len(served.Served) != 1 || served.Served[0].Version != 1 {
+		t.Fatalf("Unexpected served models: %v", served.Served)
+	}
+
+	var models struct {
+		Models []string `json:"models"`
+	}
+	if _, err := h.Do(http.MethodGet, "/models", nil, "", &models); err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(models.Models)
+	if !reflect.DeepEqual(models.Models, []string{"default", "default/1"}) {
+		t.Fatalf("Unexpected models: %v", models.Models)
+	}
+
+	if status, _ := h.Do(http.MethodDelete, "/models/default?version=1", nil, "", nil); status != http.StatusOK {
+		t.Fatalf("Fail to delete previous version: %d", status)
+	}
+	if status, _ := h.Infer("default?version=1", "roses.jpg", FakeJPEG("roses"), nil); status != http.StatusNotFound {
+		t.Fatalf("Deleted version should not infer: %d", status)
+	}
+}
+
+func TestModelAlias(t *testing.T) {
+	h := Start(t)
+
+	var res inference.CreateResult
+	if status, err := h.Do(http.MethodPost, "/models/default/versions?epochs=1", nil, "", &res); err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to create version: (%d) %v", status, err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(20 * time.Millisecond) {
+		var info inference.ModelInfo
+		if _, err := h.Do(http.MethodGet, "/models/default", nil, "", &info); err != nil {
+			t.Fatal(err)
+		}
+		if info.Version == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("New version not serving: %+v", info)
+		}
+	}
+
+	// 별칭의 대상을 바꾸면 같은 별칭으로 다른 버전이 추론
+	for _, version := range []int{1, 2} {
+		path := "/aliases/production?model=default&version=" + strconv.Itoa(version)
+		if status, err := h.Do(http.MethodPut, path, nil, "", nil); err != nil || status != http.StatusOK {
+			t.Fatalf("Fail to set alias: (%d) %v", status, err)
+		}
+
+		var served struct {
+			Served []inference.ServedModel `json:"served"`
+		}
+		if status, err := h.Infer("production", "roses.jpg", FakeJPEG("roses"), &served); err != nil || status != http.StatusOK {
+			t.Fatalf("Fail to infer alias: (%d) %v", status, err)
+		}
+		if len(served.Served) != 1 || served.Served[0].Model != "default" || served.Served[0].Version != version {
+			t.Fatalf("Unexpected served models: %v", served.Served)
+		}
+	}
+
+	if status, _ := h.Do(http.MethodDelete, "/models/default", nil, "", nil); status != http.StatusConflict {
+		t.Fatalf("Aliased model should not be deleted: %d", status)
+	}
+
+	var aliases struct {
+		Aliases map[string]string `json:"aliases"`
+	}
+	if _, err := h.Do(http.MethodGet, "/aliases", nil, "", &aliases); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(aliases.Aliases, map[string]string{"production": "default/2"}) {
+		t.Fatalf("Unexpected aliases: %v", aliases.Aliases)
+	}
+
+	if status, _ := h.Do(http.MethodDelete, "/aliases/production", nil, "", nil); status != http.StatusOK {
+		t.Fatalf("Fail to delete alias: %d", status)
+	}
+	if status, _ := h.Infer("production", "roses.jpg", FakeJPEG("roses"), nil); status != http.StatusNotFound {
+		t.Fatalf("Deleted alias should not infer: %d", status)
+	}
+}
+
+func TestRollbackModel(t *testing.T) {
+	h := Start(t)
+
+	if status, _ := h.Do(http.MethodPost, "/models/default/rollback", nil, "", nil); status != http.StatusNotFound {
+		t.Fatalf("Model without previous version should not roll back: %d", status)
+	}
+
+	if status, err := h.Do(http.MethodPost, "/models/default/versions?epochs=1", nil, "", nil); err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to create version: (%d) %v", status, err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(20 * time.Millisecond) {
+		var info inference.ModelInfo
+		if _, err := h.Do(http.MethodGet, "/models/default", nil, "", &info); err != nil {
+			t.Fatal(err)
+		}
+		if info.Version == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("New version not serving: %+v", info)
+		}
+	}
+
+	var res struct {
+		Version int `json:"version"`
+	}
+	if status, err := h.Do(http.MethodPost, "/models/default/rollback", nil, "", &res); err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to roll back: (%d) %v", status, err)
+	}
+	if res.Version != 1 {
+		t.Fatalf("Unexpected version: %d", res.Version)
+	}
+
+	var served struct {
+		Served []inference.ServedModel `json:"served"`
+	}
+	if status, err := h.Infer("default", "roses.jpg", FakeJPEG("roses"), &served); err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to infer: (%d) %v", status, err)
+	}
+	if len(served.Served) != 1 || served.Served[0].Version != 1 {
+		t.Fatalf("Unexpected served models: %v", served.Served)
+	}
+}
+
+// modelArchive golden 모델을 model 이름으로 `<model>/` 디렉토리에 담은 tar.gz
+func modelArchive(t *testing.T, model string) []byte {
+	dir, err := ioutil.TempDir("", "import")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := InstallModel(goldenPath("multi"), dir, model, "", "notebook"); err != nil {
+		t.Fatal(err)
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, file := range files {
+		b, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		hdr := &tar.Header{Name: model + "/" + file.Name(), Mode: 0644, Size: int64(len(b)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestImportModel(t *testing.T) {
+	h := Start(t)
+
+	var res inference.CreateResult
+	status, err := h.Do(http.MethodPost, "/models/notebook/import", bytes.NewReader(modelArchive(t, "notebook")), "application/gzip", &res)
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to import model: (%d) %v", status, err)
+	}
+	if res.Model != "notebook" || res.Version != 1 {
+		t.Fatalf("Unexpected result: %+v", res)
+	}
+	if len(h.Learner.Requests()) != 0 {
+		t.Fatalf("Learner should not be requested: %v", h.Learner.Requests())
+	}
+
+	if status, err := h.Infer("notebook", "roses.jpg", FakeJPEG("roses"), nil); err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to infer imported model: (%d) %v", status, err)
+	}
+
+	// config의 이름이 다른 모델은 파일을 남기지 않음
+	status, err = h.Do(http.MethodPost, "/models/other/import", bytes.NewReader(modelArchive(t, "notebook")), "application/gzip", nil)
+	if err != nil || status != http.StatusBadRequest {
+		t.Fatalf("Mismatched name should fail: (%d) %v", status, err)
+	}
+	if dirs := h.ModelDirs("other"); len(dirs) != 0 {
+		t.Fatalf("Unexpected model directories: %v", dirs)
+	}
+}
+
+func TestExportModel(t *testing.T) {
+	h := Start(t)
+
+	if status, err := h.Do(http.MethodPost, "/models/notebook/import", bytes.NewReader(modelArchive(t, "notebook")), "application/gzip", nil); err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to import model: (%d) %v", status, err)
+	}
+
+	res, err := http.Get(h.URL + "/models/notebook/export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != "application/gzip" {
+		t.Fatalf("Fail to export model: (%d) %s", res.StatusCode, res.Header.Get("Content-Type"))
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]bool)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		files[hdr.Name] = true
+	}
+	for _, file := range []string{"notebook/config.yaml", "notebook/metadata.json"} {
+		if !files[file] {
+			t.Fatalf("Missing %s: %v", file, files)
+		}
+	}
+
+	// 삭제 후 내보낸 파일로 다시 등록
+	if status, _ := h.Do(http.MethodDelete, "/models/notebook", nil, "", nil); status != http.StatusOK {
+		t.Fatalf("Fail to delete model: %d", status)
+	}
+	if status, err := h.Do(http.MethodPost, "/models/notebook/import", bytes.NewReader(archive), "application/gzip", nil); err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to import exported model: (%d) %v", status, err)
+	}
+
+	if status, _ := h.Do(http.MethodGet, "/models/none/export", nil, "", nil); status != http.StatusNotFound {
+		t.Fatalf("Unknown model should not be exported: %d", status)
+	}
+}
+
+func TestCloneModel(t *testing.T) {
+	h := Start(t)
+
+	var res inference.CreateResult
+	status, err := h.Do(http.MethodPost, "/models/default/clone?name=sandbox", nil, "", &res)
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to clone model: (%d) %v", status, err)
+	}
+	if res.Model != "sandbox" || res.Version != 1 {
+		t.Fatalf("Unexpected result: %+v", res)
+	}
+	if len(h.Learner.Requests()) != 0 {
+		t.Fatalf("Learner should not be requested: %v", h.Learner.Requests())
+	}
+
+	// 복제한 모델의 config 이름은 새 모델 이름
+	dirs := h.ModelDirs("sandbox")
+	if len(dirs) != 1 {
+		t.Fatalf("Unexpected model directories: %v", dirs)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(h.ModelsPath, dirs[0], "versions", "1", "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "name: sandbox") {
+		t.Fatalf("Unexpected configuration: %s", b)
+	}
+
+	if status, err := h.Infer("sandbox", "roses.jpg", FakeJPEG("roses"), nil); err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to infer cloned model: (%d) %v", status, err)
+	}
+
+	// 복제한 모델을 삭제해도 원본은 그대로 사용
+	if status, _ := h.Do(http.MethodDelete, "/models/sandbox", nil, "", nil); status != http.StatusOK {
+		t.Fatalf("Fail to delete model: %d", status)
+	}
+	if status, err := h.Infer("default", "roses.jpg", FakeJPEG("roses"), nil); err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to infer source model: (%d) %v", status, err)
+	}
+
+	if status, _ := h.Do(http.MethodPost, "/models/default/clone?name=default", nil, "", nil); status != http.StatusConflict {
+		t.Fatalf("Existing model should not be overwritten: %d", status)
+	}
+	if status, _ := h.Do(http.MethodPost, "/models/none/clone?name=other", nil, "", nil); status != http.StatusNotFound {
+		t.Fatalf("Unknown model should not be cloned: %d", status)
+	}
+	if dirs := h.ModelDirs("other"); len(dirs) != 0 {
+		t.Fatalf("Unexpected model directories: %v", dirs)
+	}
+}
+
+func TestUpdateModel(t *testing.T) {
+	h := Start(t)
+
+	update := strings.NewReader(`{"description": "updated", "modelTags": ["flowers"], "threshold": 0.8}`)
+	if status, err := h.Do(http.MethodPatch, "/models/default", update, "application/json", nil); err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to update model: (%d) %v", status, err)
+	}
+
+	var info inference.ModelInfo
+	if _, err := h.Do(http.MethodGet, "/models/default", nil, "", &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Description != "updated" || !reflect.DeepEqual(info.ModelTags, []string{"flowers"}) || info.Threshold != 0.8 {
+		t.Fatalf("Model is not updated: %+v", info)
+	}
+	if info.Version != 1 {
+		t.Fatalf("Unexpected version: %d", info.Version)
+	}
+	if len(h.Learner.Requests()) != 0 {
+		t.Fatalf("Learner should not be requested: %v", h.Learner.Requests())
+	}
+
+	// 다시 로드해도 바뀐 값을 사용
+	if status, _ := h.Do(http.MethodPost, "/models/default/reload", nil, "", nil); status != http.StatusOK {
+		t.Fatalf("Fail to reload model: %d", status)
+	}
+	info = inference.ModelInfo{}
+	if _, err := h.Do(http.MethodGet, "/models/default", nil, "", &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Description != "updated" || info.Threshold != 0.8 {
+		t.Fatalf("Updated configuration is not saved: %+v", info)
+	}
+
+	if status, err := h.Infer("default", "roses.jpg", FakeJPEG("roses"), nil); err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to infer updated model: (%d) %v", status, err)
+	}
+
+	if status, _ := h.Do(http.MethodPatch, "/models/default", strings.NewReader(`{"threshold": 2}`), "application/json", nil); status != http.StatusBadRequest {
+		t.Fatalf("Invalid threshold should fail: %d", status)
+	}
+	if status, _ := h.Do(http.MethodPatch, "/models/none", strings.NewReader(`{"description": "none"}`), "application/json", nil); status != http.StatusNotFound {
+		t.Fatalf("Unknown model should not be updated: %d", status)
+	}
+}
+
+func TestListModelInfos(t *testing.T) {
+	h := Start(t)
+
+	for _, model := range []string{"pets", "cats"} {
+		if status, _ := h.Do(http.MethodPost, "/models/default/clone?name="+model, nil, "", nil); status != http.StatusOK {
+			t.Fatalf("Fail to clone model: %d", status)
+		}
+	}
+
+	var page inference.ModelInfoPage
+	if status, err := h.Do(http.MethodGet, "/models?detail&size=2&page=2", nil, "", &page); err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to list models: (%d) %v", status, err)
+	}
+	if page.Total != 3 || page.Page != 2 || page.Size != 2 || len(page.Models) != 1 {
+		t.Fatalf("Unexpected page: %+v", page)
+	}
+	if info := page.Models[0]; info.Model != "pets" || info.Status != "run" || info.NumberOfLabels == 0 {
+		t.Fatalf("Unexpected model info: %+v", info)
+	}
+
+	page = inference.ModelInfoPage{}
+	if _, err := h.Do(http.MethodGet, "/models?detail&page=3&size=2", nil, "", &page); err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 3 || page.Models == nil || len(page.Models) != 0 {
+		t.Fatalf("Unexpected page: %+v", page)
+	}
+
+	if status, _ := h.Do(http.MethodGet, "/models?detail&size=0", nil, "", nil); status != http.StatusBadRequest {
+		t.Fatalf("Invalid size should fail: %d", status)
+	}
+}
+
+func TestDisableModel(t *testing.T) {
+	h := Start(t)
+
+	if status, err := h.Do(http.MethodPost, "/models/default/disable", nil, "", nil); err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to disable model: (%d) %v", status, err)
+	}
+	if status, _ := h.Infer("default", "roses.jpg", FakeJPEG("roses"), nil); status != http.StatusServiceUnavailable {
+		t.Fatalf("Disabled model should not infer: %d", status)
+	}
+
+	var info inference.ModelInfo
+	if _, err := h.Do(http.MethodGet, "/models/default", nil, "", &info); err != nil {
+		t.Fatal(err)
+	}
+	if !info.Disabled || info.Status != "run" {
+		t.Fatalf("Unexpected model info: %+v", info)
+	}
+
+	// 전체 모델 추론에서 제외
+	results, err := h.Inference.InferAll(context.Background(), nil, FakeJPEG("roses"), "jpg", 3, 0)
+	if err != nil || len(results) != 0 {
+		t.Fatalf("Disabled model should be skipped: %+v, %v", results, err)
+	}
+
+	if _, err := os.Stat(filepath.Join(h.ModelsPath, ".disabled.yaml")); err != nil {
+		t.Fatalf("Disabled models are not saved: %v", err)
+	}
+
+	if status, err := h.Do(http.MethodPost, "/models/default/enable", nil, "", nil); err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to enable model: (%d) %v", status, err)
+	}
+	if status, err := h.Infer("default", "roses.jpg", FakeJPEG("roses"), nil); err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to infer enabled model: (%d) %v", status, err)
+	}
+
+	if status, _ := h.Do(http.MethodPost, "/models/none/disable", nil, "", nil); status != http.StatusNotFound {
+		t.Fatalf("Unknown model should not be disabled: %d", status)
+	}
+}
+
+func TestModelChecksums(t *testing.T) {
+	h := Start(t)
+
+	if status, _ := h.Do(http.MethodPost, "/models/default/clone?name=sandbox", nil, "", nil); status != http.StatusOK {
+		t.Fatalf("Fail to clone model: %d", status)
+	}
+	dirs := h.ModelDirs("sandbox")
+	if len(dirs) != 1 {
+		t.Fatalf("Unexpected model directories: %v", dirs)
+	}
+	vPath := filepath.Join(h.ModelsPath, dirs[0], "versions", "1")
+	if _, err := os.Stat(filepath.Join(vPath, "checksums.json")); err != nil {
+		t.Fatalf("Checksums are not written: %v", err)
+	}
+
+	// 서버가 바꾼 config는 다시 로드 할 수 있음
+	update := strings.NewReader(`{"description": "sandbox"}`)
+	if status, _ := h.Do(http.MethodPatch, "/models/sandbox", update, "application/json", nil); status != http.StatusOK {
+		t.Fatalf("Fail to update model: %d", status)
+	}
+	if status, _ := h.Do(http.MethodPost, "/models/sandbox/reload", nil, "", nil); status != http.StatusOK {
+		t.Fatalf("Fail to reload model: %d", status)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(vPath, "lables"), []byte("tampered\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var res struct {
+		Code string `json:"code"`
+		File string `json:"file"`
+	}
+	if status, _ := h.Do(http.MethodPost, "/models/sandbox/reload", nil, "", &res); status != http.StatusInternalServerError {
+		t.Fatalf("Tampered model should not be loaded: %d", status)
+	}
+	if res.Code != "MODEL_CHECKSUM_MISMATCH" || res.File != "lables" {
+		t.Fatalf("Unexpected error: %+v", res)
+	}
+
+	// 로드에 실패하면 기존 모델을 계속 사용
+	if status, err := h.Infer("sandbox", "roses.jpg", FakeJPEG("roses"), nil); err != nil || status != http.StatusOK {
+		t.Fatalf("Fail to infer model: (%d) %v", status, err)
+	}
+}
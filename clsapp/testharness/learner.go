@@ -0,0 +1,151 @@
+package testharness
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/harrison-roh/image-classification-with-transfer-learning/clsapp/inference"
+)
+
+const (
+	modelTypeBase      = "base"
+	modelTypePractical = "practical"
+	modelTypeTrial     = "trial"
+)
+
+// Learner learnapp을 대신하는 가짜 학습 서버
+//
+// 모델 생성 요청을 받으면 즉시 응답한 후, golden 모델을 요청된 경로에 설치하고
+// learnapp과 동일하게 `PUT /models/:model`로 서비스에 모델 로드를 요청
+type Learner struct {
+	URL  string
+	Host string
+
+	clsHost  string
+	server   *httptest.Server
+	mutex    sync.Mutex
+	requests map[string]inference.CreateRequest
+	wg       sync.WaitGroup
+}
+
+// NewLearner 가짜 학습 서버 시작
+func NewLearner(clsHost string) *Learner {
+	l := &Learner{
+		clsHost:  clsHost,
+		requests: make(map[string]inference.CreateRequest),
+	}
+
+	l.server = httptest.NewServer(http.HandlerFunc(l.serveHTTP))
+	l.URL = l.server.URL
+	l.Host = strings.TrimPrefix(l.server.URL, "http://")
+
+	return l
+}
+
+// Requests 모델별로 마지막에 받은 생성 요청 반환
+func (l *Learner) Requests() map[string]inference.CreateRequest {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	requests := make(map[string]inference.CreateRequest, len(l.requests))
+	for model, req := range l.requests {
+		requests[model] = req
+	}
+
+	return requests
+}
+
+// Close 진행 중인 모델 생성을 기다린 후 서버 종료
+func (l *Learner) Close() {
+	l.wg.Wait()
+	l.server.Close()
+}
+
+func (l *Learner) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	model := strings.TrimPrefix(r.URL.Path, "/models/")
+	if r.Method != http.MethodPost || model == "" || model == r.URL.Path {
+		http.NotFound(w, r)
+		return
+	}
+
+	var req inference.CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	if req.ModelPath == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "Invalid path for model"})
+		return
+	}
+	if req.ConfigFile == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "Invalid config file name"})
+		return
+	}
+
+	modelType := modelTypeBase
+	if req.Trial {
+		modelType = modelTypeTrial
+	} else if req.ImagePath != "" {
+		modelType = modelTypePractical
+	}
+
+	l.mutex.Lock()
+	l.requests[model] = req
+	l.mutex.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"model": model,
+		"type":  modelType,
+	})
+
+	// 응답 이후에 모델을 설치해야 서비스의 모델 상태가 build에서 run으로 전환 됨
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		l.build(model, modelType, req)
+	}()
+}
+
+func (l *Learner) build(model, modelType string, req inference.CreateRequest) {
+	golden := GoldenMulti
+	if modelType == modelTypeTrial {
+		golden = GoldenBinary
+	}
+
+	if err := InstallModel(golden, req.ModelPath, model, modelType, req.Description); err != nil {
+		log.Printf("Fail to install golden model(%s): %s", req.ModelPath, err)
+		return
+	}
+
+	j, _ := json.Marshal(inference.CreateResponse{
+		ModelPath: req.ModelPath,
+	})
+
+	url := fmt.Sprintf("http://%s/models/%s", l.clsHost, model)
+	httpReq, err := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(j))
+	if err != nil {
+		log.Print(err)
+		return
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	res, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		log.Printf("Fail to operate %s: %s", model, err)
+		return
+	}
+	res.Body.Close()
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
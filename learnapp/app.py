@@ -7,6 +7,8 @@ import errno
 import threading
 import multiprocessing as mp
 
+import numpy as np
+
 from flask import Flask
 from flask import request, jsonify
 
@@ -235,15 +237,19 @@ def create_transfer_learned_model(model_name, params):
     base_model = get_base_model(True)
     if trial:
         model_type = MODEL_TYPE_TRIAL
-        model, classification, labels, result = trial_trasnfer_learned_model(
+        model, classification, labels, result, temperature = trial_trasnfer_learned_model(
             base_model, epochs
         )
     else:
         model_type = MODEL_TYPE_PRACTICAL
         image_path = params.get("imagePath", "")
-        model, classification, labels, result = practical_trasnfer_learned_model(
-            base_model, image_path, epochs
-        )
+        (
+            model,
+            classification,
+            labels,
+            result,
+            temperature,
+        ) = practical_trasnfer_learned_model(base_model, image_path, epochs)
 
     model_path = params.get("modelPath")
     if os.path.isdir(model_path):
@@ -275,6 +281,7 @@ def create_transfer_learned_model(model_name, params):
         "labelsFile": LABELS_FILE,
         "description": desc,
         "trainingResult": result,  # 학습결과 저장
+        "temperature": temperature,  # 검증 데이터셋으로 구한 confidence calibration 값
     }
 
     cfg_file = params.get("configFile")
@@ -324,8 +331,9 @@ def practical_trasnfer_learned_model(base_model, image_path, epochs):
     model, classification = build_and_compile_model(base_model, train, len(labels))
 
     result = train_and_evaluate_model(model, train, validation, epochs)
+    temperature = fit_temperature(model, validation, classification)
 
-    return model, classification, labels, result
+    return model, classification, labels, result, temperature
 
 
 def trial_trasnfer_learned_model(base_model, epochs):
@@ -354,8 +362,9 @@ def trial_trasnfer_learned_model(base_model, epochs):
     )
 
     result = train_and_evaluate_model(model, train_batches, validation_batches, epochs)
+    temperature = fit_temperature(model, validation_batches, classification)
 
-    return model, classification, labels, result
+    return model, classification, labels, result, temperature
 
 
 def build_and_compile_model(
@@ -423,6 +432,42 @@ def train_and_evaluate_model(model, train_batches, validation_batches, epochs):
     return result
 
 
+def fit_temperature(model, validation_batches, classification, steps=20):
+    # 학습 된 모델의 확률은 실제 정확도보다 높게 나오므로(overconfident),
+    # 검증 데이터셋의 negative log likelihood가 가장 작은 temperature를 찾아 clsapp에서 확률에 적용
+    probs, labels = [], []
+    for image_batch, label_batch in validation_batches.take(steps):
+        probs.append(model.predict(image_batch))
+        labels.append(label_batch.numpy())
+    probs = np.clip(np.concatenate(probs), 1e-7, 1 - 1e-7)
+    labels = np.concatenate(labels)
+
+    if classification == BINARY_CLASS:
+        logits = np.log(probs / (1 - probs)).reshape(-1)
+        labels = labels.reshape(-1)
+    else:
+        logits = np.log(probs)
+        if labels.ndim > 1:  # one-hot (categorical)
+            labels = np.argmax(labels, axis=1)
+        labels = labels.astype(int)
+
+    def nll(temperature):
+        if classification == BINARY_CLASS:
+            p = np.clip(1 / (1 + np.exp(-logits / temperature)), 1e-7, 1 - 1e-7)
+            return -np.mean(labels * np.log(p) + (1 - labels) * np.log(1 - p))
+
+        scaled = logits / temperature
+        scaled = scaled - scaled.max(axis=1, keepdims=True)
+        log_probs = scaled - np.log(np.exp(scaled).sum(axis=1, keepdims=True))
+        return -np.mean(log_probs[np.arange(len(labels)), labels])
+
+    # 0.25 ~ 10 범위에서 log scale로 탐색
+    candidates = np.exp(np.linspace(np.log(0.25), np.log(10), 200))
+    temperature = min(candidates, key=nll)
+
+    return round(float(temperature), 4)
+
+
 def normalize_image(image, label):
     image = tf.cast(image, tf.float32)
     image = (image / 127.5) - 1